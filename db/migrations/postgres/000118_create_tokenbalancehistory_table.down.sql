@@ -0,0 +1,5 @@
+BEGIN;
+DROP INDEX IF EXISTS tokenbalancehistory_account;
+DROP INDEX IF EXISTS tokenbalancehistory_timestamp;
+DROP TABLE IF EXISTS tokenbalancehistory;
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+CREATE TABLE tokenbalancehistory (
+  seq              SERIAL          PRIMARY KEY,
+  namespace        VARCHAR(64)     NOT NULL,
+  pool_id          UUID            NOT NULL,
+  token_index      VARCHAR(1024),
+  uri              VARCHAR(1024),
+  connector        VARCHAR(64)     NOT NULL,
+  key              VARCHAR(1024)   NOT NULL,
+  amount           VARCHAR(65),
+  debit            BOOLEAN,
+  balance          VARCHAR(65),
+  transfer_id      UUID,
+  protocol_id      VARCHAR(1024),
+  blockchain_event UUID,
+  created          BIGINT          NOT NULL,
+  timestamp        BIGINT          NOT NULL
+);
+
+CREATE INDEX tokenbalancehistory_account ON tokenbalancehistory(namespace,key,pool_id,token_index);
+CREATE INDEX tokenbalancehistory_timestamp ON tokenbalancehistory(namespace,timestamp);
+
+-- History starts with the balances at the time of the migration, as there is no record of earlier changes
+INSERT INTO tokenbalancehistory (namespace, pool_id, token_index, uri, connector, key, amount, debit, balance, created, timestamp)
+  SELECT namespace, pool_id, token_index, uri, connector, key, balance, false, balance, updated, updated FROM tokenbalance;
+COMMIT;
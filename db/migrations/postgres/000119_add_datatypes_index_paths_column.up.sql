@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE datatypes ADD COLUMN index_paths VARCHAR(1024);
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE datatypes ADD COLUMN compatibility VARCHAR(64) NOT NULL DEFAULT '';
+COMMIT;
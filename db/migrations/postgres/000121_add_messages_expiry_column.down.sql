@@ -0,0 +1,4 @@
+BEGIN;
+DROP INDEX messages_expiry;
+ALTER TABLE messages DROP COLUMN expiry;
+COMMIT;
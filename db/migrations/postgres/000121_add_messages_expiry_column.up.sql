@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE messages ADD COLUMN expiry BIGINT;
+CREATE INDEX messages_expiry ON messages(namespace_local,state,expiry);
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+DROP INDEX messages_scheduled;
+ALTER TABLE messages DROP COLUMN scheduled;
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE messages ADD COLUMN scheduled BIGINT;
+CREATE INDEX messages_scheduled ON messages(namespace_local,state,scheduled);
+COMMIT;
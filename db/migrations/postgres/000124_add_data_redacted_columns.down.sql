@@ -0,0 +1,6 @@
+BEGIN;
+ALTER TABLE data DROP COLUMN redacted;
+ALTER TABLE data DROP COLUMN redacted_by;
+ALTER TABLE data DROP COLUMN redacted_author;
+ALTER TABLE data DROP COLUMN redacted_reason;
+COMMIT;
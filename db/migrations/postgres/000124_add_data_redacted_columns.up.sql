@@ -0,0 +1,6 @@
+BEGIN;
+ALTER TABLE data ADD COLUMN redacted BIGINT;
+ALTER TABLE data ADD COLUMN redacted_by VARCHAR(1024) DEFAULT '';
+ALTER TABLE data ADD COLUMN redacted_author VARCHAR(1024) DEFAULT '';
+ALTER TABLE data ADD COLUMN redacted_reason TEXT DEFAULT '';
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE data ADD COLUMN value_ref VARCHAR(1024) DEFAULT '';
+COMMIT;
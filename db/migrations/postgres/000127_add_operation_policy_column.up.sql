@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE operations ADD COLUMN policy TEXT;
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+CREATE TABLE txblockchainids (
+  seq              SERIAL          PRIMARY KEY,
+  namespace        VARCHAR(64)     NOT NULL,
+  tx_id            UUID            NOT NULL,
+  blockchain_id    VARCHAR(1024)   NOT NULL
+);
+
+CREATE UNIQUE INDEX txblockchainids_blockchainid ON txblockchainids(namespace,blockchain_id,tx_id);
+CREATE INDEX txblockchainids_txid ON txblockchainids(namespace,tx_id);
+
+INSERT INTO txblockchainids (namespace, tx_id, blockchain_id)
+  SELECT DISTINCT t.namespace, t.id, LOWER(b.blockchain_id)
+  FROM transactions AS t, UNNEST(STRING_TO_ARRAY(t.blockchain_ids, ',')) AS b(blockchain_id)
+  WHERE t.blockchain_ids IS NOT NULL AND b.blockchain_id <> '';
+COMMIT;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS tokenbalancehistory_account;
+DROP INDEX IF EXISTS tokenbalancehistory_timestamp;
+DROP TABLE IF EXISTS tokenbalancehistory;
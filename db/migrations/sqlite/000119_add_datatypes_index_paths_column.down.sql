@@ -0,0 +1 @@
+ALTER TABLE datatypes DROP COLUMN index_paths;
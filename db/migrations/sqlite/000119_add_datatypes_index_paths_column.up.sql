@@ -0,0 +1 @@
+ALTER TABLE datatypes ADD COLUMN index_paths VARCHAR(1024);
@@ -0,0 +1,2 @@
+DROP INDEX messages_expiry;
+ALTER TABLE messages DROP COLUMN expiry;
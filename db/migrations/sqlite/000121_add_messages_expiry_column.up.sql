@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN expiry BIGINT;
+CREATE INDEX messages_expiry ON messages(namespace_local,state,expiry);
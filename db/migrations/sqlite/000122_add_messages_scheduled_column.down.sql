@@ -0,0 +1,2 @@
+DROP INDEX messages_scheduled;
+ALTER TABLE messages DROP COLUMN scheduled;
@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN scheduled BIGINT;
+CREATE INDEX messages_scheduled ON messages(namespace_local,state,scheduled);
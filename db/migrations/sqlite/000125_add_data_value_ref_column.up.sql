@@ -0,0 +1 @@
+ALTER TABLE data ADD COLUMN value_ref VARCHAR(1024) DEFAULT '';
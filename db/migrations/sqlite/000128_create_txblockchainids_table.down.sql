@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS txblockchainids_blockchainid;
+DROP INDEX IF EXISTS txblockchainids_txid;
+DROP TABLE IF EXISTS txblockchainids;
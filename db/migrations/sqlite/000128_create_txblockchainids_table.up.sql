@@ -0,0 +1,18 @@
+CREATE TABLE txblockchainids (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  namespace        VARCHAR(64)     NOT NULL,
+  tx_id            UUID            NOT NULL,
+  blockchain_id    VARCHAR(1024)   NOT NULL
+);
+
+CREATE UNIQUE INDEX txblockchainids_blockchainid ON txblockchainids(namespace,blockchain_id,tx_id);
+CREATE INDEX txblockchainids_txid ON txblockchainids(namespace,tx_id);
+
+WITH RECURSIVE split(namespace, tx_id, blockchain_id, remaining) AS (
+  SELECT namespace, id, '', blockchain_ids || ',' FROM transactions WHERE blockchain_ids IS NOT NULL
+  UNION ALL
+  SELECT namespace, tx_id, SUBSTR(remaining, 1, INSTR(remaining, ',') - 1), SUBSTR(remaining, INSTR(remaining, ',') + 1)
+  FROM split WHERE remaining <> ''
+)
+INSERT INTO txblockchainids (namespace, tx_id, blockchain_id)
+  SELECT DISTINCT namespace, tx_id, LOWER(blockchain_id) FROM split WHERE blockchain_id <> '';
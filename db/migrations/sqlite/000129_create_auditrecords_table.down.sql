@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS auditrecords_id;
+DROP INDEX IF EXISTS auditrecords_created;
+DROP INDEX IF EXISTS auditrecords_principal;
+DROP TABLE IF EXISTS auditrecords;
@@ -0,0 +1,25 @@
+CREATE TABLE auditrecords (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  principal        VARCHAR(1024),
+  client           VARCHAR(1024),
+  method           VARCHAR(16)     NOT NULL,
+  route            VARCHAR(256),
+  path             VARCHAR(2048)   NOT NULL,
+  params           TEXT,
+  input_hash       CHAR(64),
+  request_id       VARCHAR(256),
+  idempotency_key  VARCHAR(256),
+  tx_id            UUID,
+  op_id            UUID,
+  status           INTEGER         NOT NULL,
+  error            TEXT,
+  created          BIGINT          NOT NULL,
+  previous_hash    CHAR(64),
+  hash             CHAR(64)        NOT NULL
+);
+
+CREATE UNIQUE INDEX auditrecords_id ON auditrecords(id);
+CREATE INDEX auditrecords_created ON auditrecords(namespace,created);
+CREATE INDEX auditrecords_principal ON auditrecords(namespace,principal);
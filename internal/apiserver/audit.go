@@ -0,0 +1,180 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/audit"
+	"github.com/hyperledger/firefly/internal/namespace"
+	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// The fields of a request input, and of a response output, that are captured in an audit record
+type auditInput struct {
+	IdempotencyKey core.IdempotencyKey `json:"idempotencyKey"`
+}
+
+type auditOutput struct {
+	TxID *fftypes.UUID   `json:"txid"`
+	TX   json.RawMessage `json:"tx"`
+}
+
+func isAuditedMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// auditRequest appends a record of a mutating request to a namespace to the audit log of that namespace,
+// whether or not the request succeeded. Global admin requests, which have no orchestrator, are recorded
+// in the node-level audit log. A failure to write the record is logged, but does not affect the response
+// to the request, which has already been processed.
+func (as *apiServer) auditRequest(r *ffapi.APIRequest, mgr namespace.Manager, or orchestrator.Orchestrator, route *ffapi.Route, info *core.AuthRequestInfo, output interface{}, err error) {
+	if !as.auditEnabled || !isAuditedMethod(r.Req.Method) {
+		return
+	}
+	if ce, ok := route.Extensions.(*coreExtensions); ok && ce.ReadOnly {
+		return
+	}
+	ctx := r.Req.Context()
+	record := &core.AuditRecord{
+		Client:  clientAddress(r.Req),
+		Method:  r.Req.Method,
+		Route:   route.Name,
+		Path:    r.Req.URL.Path,
+		Params:  auditParams(r),
+		Created: fftypes.Now(),
+	}
+	if info != nil {
+		record.Principal = info.Principal
+	}
+	if requestID, ok := ctx.Value(ffapi.CtxFFRequestIDKey{}).(string); ok {
+		record.RequestID = requestID
+	}
+	if r.Input != nil {
+		record.InputHash, record.IdempotencyKey = auditInputDetails(r.Input)
+	}
+	if err != nil {
+		record.Status = http.StatusInternalServerError
+		if ffe, ok := err.(i18n.FFError); ok && ffe.HTTPStatus() >= 300 {
+			record.Status = ffe.HTTPStatus()
+		}
+		record.Error = err.Error()
+	} else {
+		record.Status = r.SuccessStatus
+		record.Transaction, record.Operation = auditOutputDetails(output)
+	}
+
+	var am audit.Manager
+	var auditErr error
+	if or != nil {
+		am = or.Audit()
+	} else {
+		am, auditErr = mgr.NodeAudit(ctx)
+	}
+
+	// The record is written even if the request context has timed out
+	if auditErr == nil {
+		auditErr = am.RecordRequest(context.WithoutCancel(ctx), record)
+	}
+	if auditErr != nil {
+		log.L(ctx).Errorf("Failed to write audit record for %s %s: %s", record.Method, record.Path, auditErr)
+	}
+}
+
+// getAuditManager returns the audit manager for the namespace of an admin request, or the node-level
+// audit manager for the reserved system namespace name
+func getAuditManager(cr *coreRequest, r *ffapi.APIRequest) (audit.Manager, error) {
+	if r.PP["ns"] == core.LegacySystemNamespace {
+		return cr.mgr.NodeAudit(cr.ctx)
+	}
+	or, err := getOrchestrator(cr.ctx, cr.mgr, routeTagNonDefaultNamespace, r)
+	if err != nil {
+		return nil, err
+	}
+	return or.Audit(), nil
+}
+
+func auditParams(r *ffapi.APIRequest) fftypes.JSONObject {
+	params := fftypes.JSONObject{}
+	for name, values := range map[string]map[string]string{"path": r.PP, "query": r.QP, "form": r.FP} {
+		if len(values) > 0 {
+			obj := fftypes.JSONObject{}
+			for k, v := range values {
+				obj[k] = v
+			}
+			params[name] = obj
+		}
+	}
+	return params
+}
+
+// auditInputDetails returns the hash of the JSON input of a request, and any idempotency key within it.
+// The input itself is not stored, as it might contain sensitive business data.
+func auditInputDetails(input interface{}) (*fftypes.Bytes32, core.IdempotencyKey) {
+	b, err := json.Marshal(input)
+	if err != nil {
+		return nil, ""
+	}
+	var hash fftypes.Bytes32 = sha256.Sum256(b)
+	var details auditInput
+	_ = json.Unmarshal(b, &details)
+	return &hash, details.IdempotencyKey
+}
+
+// auditOutputDetails extracts the transaction and operation that resulted from a request from its output,
+// which might be a message (txid), a token transfer, approval or pool (tx as a reference), or another type with a tx ID.
+func auditOutputDetails(output interface{}) (txID *fftypes.UUID, opID *fftypes.UUID) {
+	switch o := output.(type) {
+	case nil, io.Reader:
+		return nil, nil
+	case *core.Operation:
+		return o.Transaction, o.ID
+	case *core.OperationWithDetail:
+		return o.Transaction, o.ID
+	}
+	b, err := json.Marshal(output)
+	if err != nil {
+		return nil, nil
+	}
+	var details auditOutput
+	if json.Unmarshal(b, &details) != nil {
+		return nil, nil
+	}
+	if details.TxID != nil {
+		return details.TxID, nil
+	}
+	var txRef core.TransactionRef
+	if json.Unmarshal(details.TX, &txRef) == nil {
+		return txRef.ID, nil
+	}
+	_ = json.Unmarshal(details.TX, &txID)
+	return txID, nil
+}
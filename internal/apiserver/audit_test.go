@@ -0,0 +1,246 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/mocks/auditmocks"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/multipartymocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuditMutatingRequests(t *testing.T) {
+	mgr, o, as := newTestServer()
+	as.auditEnabled = true
+	mau := &auditmocks.Manager{}
+	o.On("Audit").Return(mau)
+	o.On("Authorize", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		core.GetAuthRequestInfo(args[0].(context.Context)).Principal = "basic:alice"
+	}).Return(nil)
+	o.On("MultiParty").Return(&multipartymocks.Manager{})
+	mbm := &broadcastmocks.Manager{}
+	o.On("Broadcast").Return(mbm)
+	txID := fftypes.NewUUID()
+	mbm.On("BroadcastMessage", mock.Anything, mock.Anything, false).Return(&core.Message{TransactionID: txID}, nil).Once()
+	mbm.On("BroadcastMessage", mock.Anything, mock.Anything, false).Return(nil, i18n.NewError(context.Background(), coremsgs.MsgDataNotFound, "msg1")).Once()
+	o.On("GetStatus", mock.Anything).Return(&core.NamespaceStatus{}, nil)
+	var records []*core.AuditRecord
+	mau.On("RecordRequest", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		records = append(records, args[1].(*core.AuditRecord))
+	}).Return(nil)
+	r := as.createMuxRouter(context.Background(), mgr)
+	serve := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("X-FireFly-Request-ID", "req1")
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		return res.Code
+	}
+
+	assert.Equal(t, 202, serve(http.MethodPost, "/api/v1/namespaces/ns1/messages/broadcast", `{"idempotencyKey":"idem1"}`))
+	assert.Equal(t, 400, serve(http.MethodPost, "/api/v1/namespaces/ns1/messages/broadcast", `{}`))
+	// Queries are not audited
+	assert.Equal(t, 200, serve(http.MethodGet, "/api/v1/namespaces/ns1/status", ""))
+	assert.Equal(t, 200, serve(http.MethodPost, "/api/v1/namespaces/ns1/graphql", `{"query":"{ status { node { name } } }"}`))
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, "basic:alice", records[0].Principal)
+	assert.Equal(t, "192.0.2.1", records[0].Client)
+	assert.Equal(t, http.MethodPost, records[0].Method)
+	assert.Equal(t, "postNewMessageBroadcastNamespace", records[0].Route)
+	assert.Equal(t, "/api/v1/namespaces/ns1/messages/broadcast", records[0].Path)
+	assert.Equal(t, "ns1", records[0].Params.GetObject("path").GetString("ns"))
+	assert.NotNil(t, records[0].InputHash)
+	assert.Equal(t, "req1", records[0].RequestID)
+	assert.Equal(t, core.IdempotencyKey("idem1"), records[0].IdempotencyKey)
+	assert.Equal(t, txID, records[0].Transaction)
+	assert.Equal(t, 202, records[0].Status)
+	assert.Empty(t, records[0].Error)
+	assert.Equal(t, 400, records[1].Status)
+	assert.Regexp(t, "FF10133", records[1].Error)
+	assert.Nil(t, records[1].Transaction)
+
+	mau.AssertExpectations(t)
+}
+
+func TestAuditFailedAuthorization(t *testing.T) {
+	mgr, o, as := newTestServer()
+	as.auditEnabled = true
+	mau := &auditmocks.Manager{}
+	o.On("Audit").Return(mau)
+	o.On("Authorize", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mau.On("RecordRequest", mock.Anything, mock.MatchedBy(func(record *core.AuditRecord) bool {
+		return record.Status == 500 && record.Error == "pop" && record.Principal == ""
+	})).Return(fmt.Errorf("audit failed"))
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/namespaces/ns1/subscriptions/sub1", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Code)
+	mau.AssertExpectations(t)
+}
+
+func TestAuditFormUpload(t *testing.T) {
+	mgr, o, as := newTestServer()
+	as.auditEnabled = true
+	mau := &auditmocks.Manager{}
+	o.On("Audit").Return(mau)
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mdm := &datamocks.Manager{}
+	mdm.On("BlobsEnabled").Return(true)
+	o.On("MultiParty").Return(&multipartymocks.Manager{})
+	o.On("Data").Return(mdm)
+	mdm.On("UploadBlob", mock.Anything, mock.Anything, mock.Anything, true).Return(&core.Data{}, nil)
+	mau.On("RecordRequest", mock.Anything, mock.MatchedBy(func(record *core.AuditRecord) bool {
+		return record.Status == 201 && record.Route == "postDataNamespace" && record.Params.GetObject("form").GetString("autometa") == "true"
+	})).Return(nil)
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	_ = w.WriteField("autometa", "true")
+	writer, err := w.CreateFormFile("file", "filename.ext")
+	assert.NoError(t, err)
+	writer.Write([]byte(`some data`))
+	w.Close()
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/data", &b)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 201, res.Code)
+	mau.AssertExpectations(t)
+}
+
+func TestAuditGlobalRequests(t *testing.T) {
+	mgr, _, as := newTestServer()
+	as.auditEnabled = true
+	mau := &auditmocks.Manager{}
+	mgr.On("NodeAudit", mock.Anything).Return(mau, nil)
+	mgr.On("StopNamespace", mock.Anything, "ns2").Return(&core.Namespace{Name: "ns2"}, nil)
+	mgr.On("GetNamespaces", mock.Anything, false).Return([]*core.NamespaceWithInitStatus{}, nil)
+	mau.On("RecordRequest", mock.Anything, mock.MatchedBy(func(record *core.AuditRecord) bool {
+		return record.Status == 200 && record.Route == "spiPostNamespaceStop" && record.Path == "/spi/v1/namespaces/ns2/stop"
+	})).Return(nil).Once()
+	r := as.createAdminMuxRouter(mgr)
+
+	req := httptest.NewRequest(http.MethodPost, "/spi/v1/namespaces/ns2/stop", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Code)
+
+	// Queries are not audited
+	req = httptest.NewRequest(http.MethodGet, "/spi/v1/namespaces", nil)
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Code)
+
+	mau.AssertExpectations(t)
+}
+
+func TestAuditGlobalRequestsNodeAuditFail(t *testing.T) {
+	mgr, _, as := newTestServer()
+	as.auditEnabled = true
+	mgr.On("NodeAudit", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	mgr.On("StopNamespace", mock.Anything, "ns2").Return(&core.Namespace{Name: "ns2"}, nil)
+	r := as.createAdminMuxRouter(mgr)
+
+	req := httptest.NewRequest(http.MethodPost, "/spi/v1/namespaces/ns2/stop", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	mgr.AssertCalled(t, "NodeAudit", mock.Anything)
+}
+
+func TestAuditDisabled(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/namespaces/ns1/subscriptions/sub1", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Code)
+	o.AssertNotCalled(t, "Audit")
+}
+
+func TestAuditInputDetails(t *testing.T) {
+	hash, idempotencyKey := auditInputDetails(map[string]interface{}{"idempotencyKey": "idem1"})
+	assert.NotNil(t, hash)
+	assert.Equal(t, core.IdempotencyKey("idem1"), idempotencyKey)
+
+	hash, idempotencyKey = auditInputDetails([]string{"not an object"})
+	assert.NotNil(t, hash)
+	assert.Empty(t, idempotencyKey)
+
+	hash, _ = auditInputDetails(map[string]interface{}{"bad": make(chan struct{})})
+	assert.Nil(t, hash)
+}
+
+func TestAuditOutputDetails(t *testing.T) {
+	txID := fftypes.NewUUID()
+	opID := fftypes.NewUUID()
+
+	tx, op := auditOutputDetails(&core.Operation{ID: opID, Transaction: txID})
+	assert.Equal(t, txID, tx)
+	assert.Equal(t, opID, op)
+
+	tx, op = auditOutputDetails(&core.OperationWithDetail{Operation: core.Operation{ID: opID, Transaction: txID}})
+	assert.Equal(t, txID, tx)
+	assert.Equal(t, opID, op)
+
+	tx, op = auditOutputDetails(&core.TokenTransfer{TX: core.TransactionRef{ID: txID}})
+	assert.Equal(t, txID, tx)
+	assert.Nil(t, op)
+
+	tx, _ = auditOutputDetails(&core.Event{Transaction: txID})
+	assert.Equal(t, txID, tx)
+
+	tx, _ = auditOutputDetails(&core.Data{})
+	assert.Nil(t, tx)
+
+	tx, _ = auditOutputDetails([]*core.Data{})
+	assert.Nil(t, tx)
+
+	tx, _ = auditOutputDetails(strings.NewReader("streamed"))
+	assert.Nil(t, tx)
+
+	tx, _ = auditOutputDetails(map[string]interface{}{"bad": make(chan struct{})})
+	assert.Nil(t, tx)
+}
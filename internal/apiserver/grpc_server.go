@@ -0,0 +1,286 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/auth"
+	"github.com/hyperledger/firefly-common/pkg/auth/authfactory"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftls"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/httpserver"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/namespace"
+	"github.com/hyperledger/firefly/pkg/grpcapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	_ "google.golang.org/grpc/encoding/gzip" // registers gzip, so clients can compress messages
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var grpcConfig = config.RootSection("grpc")
+
+// grpcStatusCodes maps the HTTP status of an error, as returned by the REST API, to the equivalent gRPC status
+var grpcStatusCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusRequestTimeout:        codes.DeadlineExceeded,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusPreconditionFailed:    codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusNotImplemented:        codes.Unimplemented,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+	http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+}
+
+var ffErrorCode = regexp.MustCompile(`^(FF\d+):`)
+
+// initGRPCConfig registers the settings of the gRPC server, which are those of the HTTP servers that apply to gRPC
+func initGRPCConfig(conf config.Section) {
+	conf.AddKnownKey(httpserver.HTTPConfAddress, "127.0.0.1")
+	conf.AddKnownKey(httpserver.HTTPConfPort, 5002)
+	conf.AddKnownKey(httpserver.HTTPConfReadTimeout, "15s")
+	conf.AddKnownKey(httpserver.HTTPConfShutdownTimeout, "10s")
+	conf.AddKnownKey(httpserver.HTTPAuthType)
+	authfactory.InitConfig(conf.SubSection("auth"))
+	fftls.InitTLSConfig(conf.SubSection("tls"))
+}
+
+// grpcInterceptor wraps every call to the gRPC server, to authenticate it with the auth plugin of the server
+// (if one is set), give it a request ID and timeout as for a REST request, and log it
+type grpcInterceptor struct {
+	as   *apiServer
+	auth auth.Plugin
+}
+
+// grpcServerStream replaces the context of a stream with the one set by the interceptor
+type grpcServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *grpcServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// newGRPCServer returns a gRPC server with the FireFly service registered on it
+func (as *apiServer) newGRPCServer(ctx context.Context, mgr namespace.Manager) (*grpc.Server, error) {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(int(config.GetByteSize(coreconfig.GRPCMaxMessageSize))),
+		grpc.ConnectionTimeout(grpcConfig.GetDuration(httpserver.HTTPConfReadTimeout)),
+	}
+	tlsConfig, err := fftls.ConstructTLSConfig(ctx, grpcConfig.SubSection("tls"), fftls.ServerType)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	gi := &grpcInterceptor{as: as}
+	if pluginName := grpcConfig.GetString(httpserver.HTTPAuthType); pluginName != "" {
+		if gi.auth, err = authfactory.GetPlugin(ctx, pluginName); err != nil {
+			return nil, err
+		}
+		if err := gi.auth.Init(ctx, "", grpcConfig.SubSection("auth").SubSection(gi.auth.Name())); err != nil {
+			return nil, err
+		}
+	}
+	opts = append(opts, grpc.UnaryInterceptor(gi.unary), grpc.StreamInterceptor(gi.stream))
+
+	s := grpc.NewServer(opts...)
+	grpcapi.RegisterFireFlyServer(s, &grpcService{as: as, mgr: mgr})
+	return s, nil
+}
+
+func createGRPCListener(ctx context.Context) (net.Listener, error) {
+	listenAddr := fmt.Sprintf("%s:%d", grpcConfig.GetString(httpserver.HTTPConfAddress), grpcConfig.GetUint(httpserver.HTTPConfPort))
+	l, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgAPIServerStartFailed, listenAddr)
+	}
+	log.L(ctx).Infof("grpc listening on %s", l.Addr())
+	return l, nil
+}
+
+// serveGRPC serves calls on the listener until the context is cancelled. Calls in flight, including event
+// streams, are then given the shutdown timeout to complete before their connections are closed.
+func serveGRPC(ctx context.Context, s *grpc.Server, l net.Listener, onClose chan error) {
+	serverEnded := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			log.L(ctx).Infof("gRPC server context canceled - shutting down")
+			stopped := make(chan struct{})
+			go func() {
+				s.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-time.After(grpcConfig.GetDuration(httpserver.HTTPConfShutdownTimeout)):
+				s.Stop()
+			}
+		case <-serverEnded:
+		}
+	}()
+
+	err := s.Serve(l)
+	close(serverEnded)
+	log.L(ctx).Infof("gRPC server complete")
+	onClose <- err
+}
+
+func (gi *grpcInterceptor) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	ctx, cancel := gi.as.grpcContext(ctx, gi.as.apiTimeout)
+	defer cancel()
+	err = gi.call(ctx, info.FullMethod, func(ctx context.Context) (err error) {
+		resp, err = handler(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (gi *grpcInterceptor) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	// Streams last until they are cancelled, unless the client sets a deadline
+	ctx, cancel := gi.as.grpcContext(ss.Context(), 0)
+	defer cancel()
+	return gi.call(ctx, info.FullMethod, func(ctx context.Context) error {
+		return handler(srv, &grpcServerStream{ServerStream: ss, ctx: ctx})
+	})
+}
+
+// call authenticates a call before running it, and logs it with the gRPC status of its result
+func (gi *grpcInterceptor) call(ctx context.Context, method string, run func(ctx context.Context) error) error {
+	l := log.L(ctx)
+	l.Infof("--> gRPC %s", method)
+	startTime := time.Now()
+
+	err := gi.authenticate(ctx, method)
+	if err == nil {
+		err = run(ctx)
+	}
+	err = grpcError(ctx, err)
+
+	durationMS := float64(time.Since(startTime)) / float64(time.Millisecond)
+	if err != nil {
+		l.Infof("<-- gRPC %s [%s] (%.2fms): %s", method, status.Code(err), durationMS, status.Convert(err).Message())
+	} else {
+		l.Infof("<-- gRPC %s [%s] (%.2fms)", method, codes.OK, durationMS)
+	}
+	return err
+}
+
+// authenticate checks a call with the auth plugin of the server, as a POST to the path of the method,
+// in the same way as the auth plugin of an HTTP server checks every request
+func (gi *grpcInterceptor) authenticate(ctx context.Context, method string) error {
+	if gi.auth == nil {
+		return nil
+	}
+	if err := gi.auth.Authorize(ctx, &fftypes.AuthReq{
+		Method: http.MethodPost,
+		URL:    &url.URL{Path: method},
+		Header: grpcHeader(ctx),
+	}); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
+
+// grpcContext sets the request ID of a call, from the x-firefly-request-id metadata if it is set, and its
+// timeout. A deadline set by the client is limited to the maximum request timeout, and calls without one
+// are given the default timeout, unless it is zero.
+func (as *apiServer) grpcContext(ctx context.Context, defaultTimeout time.Duration) (context.Context, context.CancelFunc) {
+	requestID := ""
+	if values := metadata.ValueFromIncomingContext(ctx, ffapi.FFRequestIDHeader); len(values) > 0 {
+		requestID = values[0]
+	}
+	if requestID == "" {
+		requestID = fftypes.ShortID()
+	}
+	ctx = context.WithValue(ctx, ffapi.CtxFFRequestIDKey{}, requestID)
+	ctx = log.WithLogField(ctx, "grpcreq", requestID)
+
+	timeout := defaultTimeout
+	if _, ok := ctx.Deadline(); ok {
+		// The earlier deadline of the client still applies, if it is within the maximum
+		timeout = as.apiMaxTimeout
+	}
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// grpcHeader returns the metadata of a call as the HTTP headers of the equivalent REST request
+func grpcHeader(ctx context.Context) http.Header {
+	header := http.Header{}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		if !strings.HasPrefix(key, ":") {
+			for _, v := range values {
+				header.Add(key, v)
+			}
+		}
+	}
+	return header
+}
+
+// grpcError returns the gRPC status for the error of a call, mapping errors in the same way as the REST API
+func grpcError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case context.Canceled:
+		return status.Error(codes.Canceled, err.Error())
+	}
+	httpStatus := http.StatusInternalServerError
+	if ffe, ok := err.(i18n.FFError); ok {
+		httpStatus = ffe.HTTPStatus()
+	} else if code := ffErrorCode.FindStringSubmatch(err.Error()); code != nil {
+		if statusHint, ok := i18n.GetStatusHint(code[1]); ok {
+			httpStatus = statusHint
+		}
+	}
+	code, ok := grpcStatusCodes[httpStatus]
+	if !ok {
+		code = codes.Unknown
+	}
+	return status.Error(code, err.Error())
+}
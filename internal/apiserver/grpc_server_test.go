@@ -0,0 +1,343 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftls"
+	"github.com/hyperledger/firefly-common/pkg/httpserver"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/namespace"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/multipartymocks"
+	"github.com/hyperledger/firefly/mocks/orchestratormocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/grpcapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestGRPCClient serves the gRPC API on an in-memory listener, and returns a client connected to it
+func newTestGRPCClient(t *testing.T, mgr namespace.Manager, as *apiServer) grpcapi.FireFlyClient {
+	s, err := as.newGRPCServer(context.Background(), mgr)
+	assert.NoError(t, err)
+	l := bufconn.Listen(1024 * 1024)
+	go func() { _ = s.Serve(l) }()
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+		s.Stop()
+	})
+	return grpcapi.NewFireFlyClient(conn)
+}
+
+// newTestCertificate returns a self-signed certificate and key for 127.0.0.1, in PEM format
+func newTestCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func mockTestBroadcast(o *orchestratormocks.Orchestrator) *broadcastmocks.Manager {
+	o.On("MultiParty").Return(&multipartymocks.Manager{})
+	mbm := &broadcastmocks.Manager{}
+	o.On("Broadcast").Return(mbm)
+	return mbm
+}
+
+func TestGRPCCompressedMessage(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mbm := mockTestBroadcast(o)
+	mbm.On("BroadcastMessage", mock.Anything, mock.MatchedBy(func(in *core.MessageInOut) bool {
+		return in.Header.Tag == "tag1"
+	}), false).Return(&core.Message{}, nil)
+
+	_, err := newTestGRPCClient(t, mgr, as).BroadcastMessage(context.Background(), &grpcapi.BroadcastMessageRequest{
+		Message: &grpcapi.MessageInput{Header: &grpcapi.MessageHeaderInput{Tag: "tag1"}},
+	}, grpc.UseCompressor(gzip.Name))
+
+	assert.NoError(t, err)
+	mbm.AssertExpectations(t)
+}
+
+func TestGRPCMessageTooLarge(t *testing.T) {
+	mgr, _, as := newTestServer()
+	config.Set(coreconfig.GRPCMaxMessageSize, "10")
+
+	_, err := newTestGRPCClient(t, mgr, as).BroadcastMessage(context.Background(), &grpcapi.BroadcastMessageRequest{
+		Namespace: "a-namespace-name-longer-than-ten-bytes",
+	})
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestGRPCDeadlineExceeded(t *testing.T) {
+	mgr, o, as := newTestServer()
+	as.apiMaxTimeout = 10 * time.Millisecond
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mbm := mockTestBroadcast(o)
+	mbm.On("BroadcastMessage", mock.Anything, mock.Anything, false).Return(func(ctx context.Context, in *core.MessageInOut, waitConfirm bool) (*core.Message, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	// The deadline of the client is limited to the maximum request timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err := newTestGRPCClient(t, mgr, as).BroadcastMessage(ctx, &grpcapi.BroadcastMessageRequest{})
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Regexp(t, "deadline exceeded", status.Convert(err).Message())
+}
+
+func TestGRPCServerAuth(t *testing.T) {
+	mgr, o, as := newTestServer()
+	passwordFile := filepath.Join(t.TempDir(), "users")
+	err := os.WriteFile(passwordFile, []byte("user1:$2a$04$HI6bxNpfM4RKTkI.Sy9MtuLkr9ZvkTwAjl.a1/CXflXxL5qUrxXB."), 0600)
+	assert.NoError(t, err)
+	grpcConfig.Set(httpserver.HTTPAuthType, "basic")
+	grpcConfig.SubSection("auth").SubSection("basic").Set("passwordfile", passwordFile)
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mbm := mockTestBroadcast(o)
+	mbm.On("BroadcastMessage", mock.Anything, mock.Anything, false).Return(&core.Message{}, nil)
+	client := newTestGRPCClient(t, mgr, as)
+
+	_, err = client.BroadcastMessage(context.Background(), &grpcapi.BroadcastMessageRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Regexp(t, "FF00169", status.Convert(err).Message())
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("user1:pass1")))
+	_, err = client.BroadcastMessage(ctx, &grpcapi.BroadcastMessageRequest{})
+	assert.NoError(t, err)
+	mbm.AssertExpectations(t)
+}
+
+func TestGRPCTLS(t *testing.T) {
+	mgr, o, as := newTestServer()
+	cert, key := newTestCertificate(t)
+	tlsConfig := grpcConfig.SubSection("tls")
+	tlsConfig.Set(fftls.HTTPConfTLSEnabled, true)
+	tlsConfig.Set(fftls.HTTPConfTLSCert, cert)
+	tlsConfig.Set(fftls.HTTPConfTLSKey, key)
+	o.On("Authorize", mock.MatchedBy(func(ctx context.Context) bool {
+		return core.GetAuthRequestInfo(ctx).TLS.HandshakeComplete
+	}), mock.Anything).Return(nil)
+	mbm := mockTestBroadcast(o)
+	mbm.On("BroadcastMessage", mock.Anything, mock.Anything, false).Return(&core.Message{}, nil)
+
+	s, err := as.newGRPCServer(context.Background(), mgr)
+	assert.NoError(t, err)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() { _ = s.Serve(l) }()
+	defer s.Stop()
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM([]byte(cert))
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		RootCAs:    roots,
+		MinVersion: tls.VersionTLS12,
+	})))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_, err = grpcapi.NewFireFlyClient(conn).BroadcastMessage(context.Background(), &grpcapi.BroadcastMessageRequest{})
+	assert.NoError(t, err)
+	o.AssertExpectations(t)
+}
+
+func TestNewGRPCServerBadTLS(t *testing.T) {
+	mgr, _, as := newTestServer()
+	tlsConfig := grpcConfig.SubSection("tls")
+	tlsConfig.Set(fftls.HTTPConfTLSEnabled, true)
+	tlsConfig.Set(fftls.HTTPConfTLSCert, "bad")
+	tlsConfig.Set(fftls.HTTPConfTLSKey, "bad")
+
+	_, err := as.newGRPCServer(context.Background(), mgr)
+	assert.Error(t, err)
+}
+
+func TestNewGRPCServerBadAuthPlugin(t *testing.T) {
+	mgr, _, as := newTestServer()
+	grpcConfig.Set(httpserver.HTTPAuthType, "wrong")
+
+	_, err := as.newGRPCServer(context.Background(), mgr)
+	assert.Regexp(t, "FF00168", err)
+}
+
+func TestNewGRPCServerAuthInitFail(t *testing.T) {
+	mgr, _, as := newTestServer()
+	grpcConfig.Set(httpserver.HTTPAuthType, "basic")
+
+	_, err := as.newGRPCServer(context.Background(), mgr)
+	assert.Error(t, err)
+}
+
+func TestServeGRPCListenerClosed(t *testing.T) {
+	mgr, _, as := newTestServer()
+	s, err := as.newGRPCServer(context.Background(), mgr)
+	assert.NoError(t, err)
+	l, err := createGRPCListener(context.Background())
+	assert.NoError(t, err)
+	l.Close()
+
+	onClose := make(chan error, 1)
+	serveGRPC(context.Background(), s, l, onClose)
+	assert.Error(t, <-onClose)
+}
+
+func TestServeGRPCShutdownTimeout(t *testing.T) {
+	mgr, o, as := newTestServer()
+	grpcConfig.Set(httpserver.HTTPConfShutdownTimeout, "1ms")
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	cbs, started := newTestGRPCStreams(t)
+	closed := make(chan struct{})
+	cbs.On("ConnectionClosed", mock.Anything).Run(func(args mock.Arguments) { close(closed) }).Return()
+	s, err := as.newGRPCServer(context.Background(), mgr)
+	assert.NoError(t, err)
+	l := bufconn.Listen(1024 * 1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	onClose := make(chan error, 1)
+	go serveGRPC(ctx, s, l, onClose)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return l.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	// The open event stream does not end by itself, so the server is stopped after the shutdown timeout
+	_, err = grpcapi.NewFireFlyClient(conn).SubscribeEvents(context.Background(), &grpcapi.SubscribeEventsRequest{Namespace: "ns1", Ephemeral: true})
+	assert.NoError(t, err)
+	<-started
+	cancel()
+	assert.NoError(t, <-onClose)
+	<-closed
+}
+
+func TestCreateGRPCListenerFail(t *testing.T) {
+	coreconfig.Reset()
+	InitConfig()
+	grpcConfig.Set(httpserver.HTTPConfAddress, "...://")
+
+	_, err := createGRPCListener(context.Background())
+	assert.Regexp(t, "FF00151", err)
+}
+
+func TestGRPCContext(t *testing.T) {
+	_, _, as := newTestServer()
+	as.apiMaxTimeout = time.Minute
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ffapi.FFRequestIDHeader, "req1"))
+	ctx, cancel := as.grpcContext(ctx, 30*time.Second)
+	defer cancel()
+	assert.Equal(t, "req1", ctx.Value(ffapi.CtxFFRequestIDKey{}))
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), deadline, 5*time.Second)
+
+	// Streams have no timeout, unless the client sets a deadline
+	ctx, cancel = as.grpcContext(context.Background(), 0)
+	defer cancel()
+	assert.NotEmpty(t, ctx.Value(ffapi.CtxFFRequestIDKey{}))
+	_, ok = ctx.Deadline()
+	assert.False(t, ok)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	ctx, cancel = as.grpcContext(ctx, 0)
+	defer cancel()
+	deadline, ok = ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+}
+
+func TestGRPCHeader(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{
+		":authority":    {"localhost"},
+		"authorization": {"Bearer token1"},
+	})
+	header := grpcHeader(ctx)
+	assert.Equal(t, "Bearer token1", header.Get("Authorization"))
+	assert.Len(t, header, 1)
+
+	assert.Empty(t, grpcHeader(context.Background()))
+}
+
+func TestGRPCError(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, grpcError(ctx, nil))
+	assert.Equal(t, codes.Aborted, status.Code(grpcError(ctx, status.Error(codes.Aborted, "pop"))))
+	assert.Equal(t, codes.NotFound, status.Code(grpcError(ctx, i18n.NewError(ctx, coremsgs.MsgUnknownNamespace, "ns2"))))
+	assert.Equal(t, codes.InvalidArgument, status.Code(grpcError(ctx, fmt.Errorf("FF10555: wrapped"))))
+	assert.Equal(t, codes.Unknown, status.Code(grpcError(ctx, fmt.Errorf("FF99999: unknown code"))))
+	assert.Equal(t, codes.Unknown, status.Code(grpcError(ctx, fmt.Errorf("pop"))))
+	assert.Equal(t, "pop", status.Convert(grpcError(ctx, fmt.Errorf("pop"))).Message())
+
+	cancelledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Equal(t, codes.Canceled, status.Code(grpcError(cancelledCtx, fmt.Errorf("pop"))))
+	expiredCtx, cancel := context.WithTimeout(ctx, 0)
+	defer cancel()
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(grpcError(expiredCtx, fmt.Errorf("pop"))))
+}
@@ -0,0 +1,294 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/events/eifactory"
+	"github.com/hyperledger/firefly/internal/events/grpcstreams"
+	"github.com/hyperledger/firefly/internal/namespace"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/grpcapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// grpcService implements the gRPC API. Each unary method is served by the core handler of a route,
+// in the same way as the equivalent REST request. The fields of each protobuf message have the
+// same JSON names as the REST API, so messages are converted to and from the core types as JSON.
+type grpcService struct {
+	grpcapi.UnimplementedFireFlyServer
+	as  *apiServer
+	mgr namespace.Manager
+}
+
+type grpcSubscription struct {
+	start *core.WSStart
+	send  grpcstreams.SendFunc
+}
+
+type grpcAck struct {
+	connID string
+	ack    *core.WSAck
+}
+
+var (
+	grpcBroadcastMessageRoute   = grpcRoute("grpcBroadcastMessage", postNewMessageBroadcast)
+	grpcSendPrivateMessageRoute = grpcRoute("grpcSendPrivateMessage", postNewMessagePrivate)
+	grpcMintTokensRoute         = grpcRoute("grpcMintTokens", postTokenMint)
+	grpcTransferTokensRoute     = grpcRoute("grpcTransferTokens", postTokenTransfer)
+	grpcInvokeContractRoute     = grpcRoute("grpcInvokeContract", postContractInvoke)
+	grpcQueryContractRoute      = grpcRoute("grpcQueryContract", postContractQuery)
+)
+
+// Event streams are authorized, and rate limited, as a query of the events of the namespace
+var grpcSubscribeEventsRoute = &ffapi.Route{
+	Name:   "grpcSubscribeEvents",
+	Path:   "namespaces/{ns}/events",
+	Method: http.MethodGet,
+	Tag:    routeTagNonDefaultNamespace,
+	Extensions: &coreExtensions{
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			sub := r.Input.(*grpcSubscription)
+			sub.start.Namespace = r.PP["ns"]
+			return nil, getGRPCStreams(cr.ctx).ServeStream(cr.ctx, sub.start, sub.send)
+		},
+	},
+}
+
+var grpcAckEventRoute = &ffapi.Route{
+	Name:   "grpcAckEvent",
+	Path:   "namespaces/{ns}/events",
+	Method: http.MethodGet,
+	Tag:    routeTagNonDefaultNamespace,
+	Extensions: &coreExtensions{
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			ack := r.Input.(*grpcAck)
+			return nil, getGRPCStreams(cr.ctx).Ack(cr.ctx, r.PP["ns"], ack.connID, ack.ack)
+		},
+	},
+}
+
+func getGRPCStreams(ctx context.Context) *grpcstreams.GRPCStreams {
+	gs, _ := eifactory.GetPlugin(ctx, "grpc")
+	return gs.(*grpcstreams.GRPCStreams)
+}
+
+// grpcRoute returns a copy of a namespaced REST route, for a gRPC method that is served in the same way
+func grpcRoute(name string, route *ffapi.Route) *ffapi.Route {
+	grpcRoute := *route
+	grpcRoute.Name = name
+	grpcRoute.Path = "namespaces/{ns}/" + route.Path
+	grpcRoute.Tag = routeTagNonDefaultNamespace
+	return &grpcRoute
+}
+
+func (gs *grpcService) BroadcastMessage(ctx context.Context, req *grpcapi.BroadcastMessageRequest) (*grpcapi.Message, error) {
+	msg := &grpcapi.Message{}
+	return msg, gs.call(ctx, grpcBroadcastMessageRoute, req.GetNamespace(), req.GetMessage(), &core.MessageInOut{}, req.GetConfirm(), msg)
+}
+
+func (gs *grpcService) SendPrivateMessage(ctx context.Context, req *grpcapi.SendPrivateMessageRequest) (*grpcapi.Message, error) {
+	msg := &grpcapi.Message{}
+	return msg, gs.call(ctx, grpcSendPrivateMessageRoute, req.GetNamespace(), req.GetMessage(), &core.MessageInOut{}, req.GetConfirm(), msg)
+}
+
+func (gs *grpcService) MintTokens(ctx context.Context, req *grpcapi.MintTokensRequest) (*grpcapi.TokenTransfer, error) {
+	transfer := &grpcapi.TokenTransfer{}
+	return transfer, gs.call(ctx, grpcMintTokensRoute, req.GetNamespace(), req.GetTransfer(), &core.TokenTransferInput{}, req.GetConfirm(), transfer)
+}
+
+func (gs *grpcService) TransferTokens(ctx context.Context, req *grpcapi.TransferTokensRequest) (*grpcapi.TokenTransfer, error) {
+	transfer := &grpcapi.TokenTransfer{}
+	return transfer, gs.call(ctx, grpcTransferTokensRoute, req.GetNamespace(), req.GetTransfer(), &core.TokenTransferInput{}, req.GetConfirm(), transfer)
+}
+
+func (gs *grpcService) InvokeContract(ctx context.Context, req *grpcapi.InvokeContractRequest) (*grpcapi.ContractCallResponse, error) {
+	result := &structpb.Value{}
+	if err := gs.call(ctx, grpcInvokeContractRoute, req.GetNamespace(), req.GetCall(), &core.ContractCallRequest{}, req.GetConfirm(), result); err != nil {
+		return nil, err
+	}
+	return &grpcapi.ContractCallResponse{Result: result}, nil
+}
+
+func (gs *grpcService) QueryContract(ctx context.Context, req *grpcapi.QueryContractRequest) (*grpcapi.ContractCallResponse, error) {
+	result := &structpb.Value{}
+	if err := gs.call(ctx, grpcQueryContractRoute, req.GetNamespace(), req.GetCall(), &core.ContractCallRequest{}, false, result); err != nil {
+		return nil, err
+	}
+	return &grpcapi.ContractCallResponse{Result: result}, nil
+}
+
+func (gs *grpcService) AckEvent(ctx context.Context, req *grpcapi.AckEventRequest) (*grpcapi.AckEventResponse, error) {
+	ack, err := grpcAckInput(ctx, req)
+	if err == nil {
+		_, err = gs.serveRequest(ctx, grpcAckEventRoute, req.GetNamespace(), ack, map[string]string{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &grpcapi.AckEventResponse{}, nil
+}
+
+func (gs *grpcService) SubscribeEvents(req *grpcapi.SubscribeEventsRequest, stream grpc.ServerStreamingServer[grpcapi.EventDelivery]) error {
+	ctx := stream.Context()
+	autoAck := req.GetAutoAck()
+	start := &core.WSStart{
+		AutoAck:   &autoAck,
+		Name:      req.GetName(),
+		Ephemeral: req.GetEphemeral(),
+	}
+	if err := fromProto(ctx, req.GetFilter(), &start.Filter); err != nil {
+		return err
+	}
+	if err := fromProto(ctx, req.GetOptions(), &start.Options); err != nil {
+		return err
+	}
+
+	// The headers are sent straight away, so the client knows the stream is open before the first event
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	send := func(connID string, event *core.EventDelivery) error {
+		delivery := &grpcapi.EventDelivery{}
+		if err := toProto(ctx, event, delivery); err != nil {
+			return err
+		}
+		delivery.ConnectionId = connID
+		return stream.Send(delivery)
+	}
+	_, err := gs.serveRequest(ctx, grpcSubscribeEventsRoute, req.GetNamespace(), &grpcSubscription{start: start, send: send}, map[string]string{})
+	return err
+}
+
+// call serves a unary method with the core handler of a route, converting the body of the request to the
+// input of the route, and its output to the response
+func (gs *grpcService) call(ctx context.Context, route *ffapi.Route, ns string, body proto.Message, input interface{}, confirm bool, out proto.Message) error {
+	if err := fromProto(ctx, body, input); err != nil {
+		return err
+	}
+	output, err := gs.serveRequest(ctx, route, ns, input, map[string]string{"confirm": strconv.FormatBool(confirm)})
+	if err != nil {
+		return err
+	}
+	return toProto(ctx, output, out)
+}
+
+func grpcAckInput(ctx context.Context, req *grpcapi.AckEventRequest) (*grpcAck, error) {
+	ack := &core.WSAck{}
+	if req.GetId() != "" {
+		id, err := fftypes.ParseUUID(ctx, req.GetId())
+		if err != nil {
+			return nil, err
+		}
+		ack.ID = id
+	}
+	if req.GetSubscription() != nil {
+		ack.Subscription = &core.SubscriptionRef{}
+		if err := fromProto(ctx, req.GetSubscription(), ack.Subscription); err != nil {
+			return nil, err
+		}
+	}
+	return &grpcAck{connID: req.GetConnectionId(), ack: ack}, nil
+}
+
+// fromProto converts a protobuf message to the core type with the same JSON representation
+func fromProto(ctx context.Context, msg proto.Message, v interface{}) error {
+	b, err := protojson.Marshal(msg)
+	if err == nil {
+		err = json.Unmarshal(b, v)
+	}
+	if err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgGRPCInvalidMessage)
+	}
+	return nil
+}
+
+// toProto converts a core type to the protobuf message with the same JSON representation,
+// ignoring any fields that the protobuf message does not have
+func toProto(ctx context.Context, v interface{}, msg proto.Message) error {
+	b, err := json.Marshal(v)
+	if err == nil {
+		err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, msg)
+	}
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgResponseMarshalError)
+	}
+	return nil
+}
+
+// serveRequest authorizes, limits and audits a gRPC call as the equivalent REST request to the
+// namespace, with the metadata of the call as its headers, before passing it to the core handler of the route
+func (gs *grpcService) serveRequest(ctx context.Context, route *ffapi.Route, ns string, input interface{}, qp map[string]string) (output interface{}, err error) {
+	as, mgr := gs.as, gs.mgr
+	if ns == "" {
+		ns = as.defaultNamespace
+	}
+	req := (&http.Request{
+		Method: route.Method,
+		URL:    &url.URL{Path: "/api/v1/" + strings.Replace(route.Path, "{ns}", url.PathEscape(ns), 1)},
+		Header: grpcHeader(ctx),
+	}).WithContext(ctx)
+	if p, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = p.Addr.String()
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			req.TLS = &tlsInfo.State
+		}
+	}
+	req = mux.SetURLVars(req, map[string]string{"ns": ns})
+	r := &ffapi.APIRequest{
+		Req:             req,
+		QP:              qp,
+		PP:              map[string]string{"ns": ns},
+		Input:           input,
+		SuccessStatus:   http.StatusOK,
+		ResponseHeaders: http.Header{},
+	}
+
+	or, err := getOrchestrator(ctx, mgr, route.Tag, r)
+	if err != nil {
+		return nil, err
+	}
+	var info *core.AuthRequestInfo
+	defer func() { as.auditRequest(r, mgr, or, route, info, output, err) }()
+	info, err = authorizeRequest(r, mgr, or)
+	if err != nil {
+		return nil, err
+	}
+	var settle func(output interface{}, err error)
+	if settle, err = as.limitRequest(r, or, info, route.Extensions.(*coreExtensions).ChainWrite); err != nil {
+		return nil, err
+	}
+	defer func() { settle(output, err) }()
+	return as.handleCoreRequest(mgr, or, "", route, r, info)
+}
@@ -0,0 +1,463 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/mocks/assetmocks"
+	"github.com/hyperledger/firefly/mocks/auditmocks"
+	"github.com/hyperledger/firefly/mocks/contractmocks"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/mocks/multipartymocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/grpcapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// newTestGRPCStreams initializes the gRPC event transport, with a handler for the ns1 namespace
+func newTestGRPCStreams(t *testing.T) (*eventsmocks.Callbacks, chan string) {
+	gs := getGRPCStreams(context.Background())
+	err := gs.Init(context.Background(), config.RootSection("ut.grpc"))
+	assert.NoError(t, err)
+	cbs := &eventsmocks.Callbacks{}
+	gs.SetHandler("ns1", cbs)
+	t.Cleanup(func() { gs.SetHandler("ns1", nil) })
+	started := make(chan string, 1)
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { started <- args[0].(string) }).Return(nil).Maybe()
+	return cbs, started
+}
+
+func newTestStruct(t *testing.T, v map[string]interface{}) *structpb.Struct {
+	s, err := structpb.NewStruct(v)
+	assert.NoError(t, err)
+	return s
+}
+
+// failingHeaderStream is an event stream on which the headers cannot be sent
+type failingHeaderStream struct {
+	grpc.ServerStreamingServer[grpcapi.EventDelivery]
+}
+
+func (s *failingHeaderStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *failingHeaderStream) SendHeader(metadata.MD) error {
+	return fmt.Errorf("pop")
+}
+
+func TestGRPCBroadcastMessage(t *testing.T) {
+	mgr, o, as := newTestServer()
+	as.auditEnabled = true
+	mau := &auditmocks.Manager{}
+	o.On("Audit").Return(mau)
+	var record *core.AuditRecord
+	mau.On("RecordRequest", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		record = args[1].(*core.AuditRecord)
+	}).Return(nil)
+	o.On("Authorize", mock.Anything, mock.MatchedBy(func(req *fftypes.AuthReq) bool {
+		return req.Method == http.MethodPost && req.URL.Path == "/api/v1/namespaces/default/messages/broadcast"
+	})).Return(nil)
+	mbm := mockTestBroadcast(o)
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID:      fftypes.NewUUID(),
+			Tag:     "tag1",
+			Topics:  fftypes.FFStringArray{"topic1"},
+			Created: fftypes.Now(),
+		},
+		Data:  core.DataRefs{{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()}},
+		State: core.MessageStateConfirmed,
+	}
+	mbm.On("BroadcastMessage", mock.Anything, mock.MatchedBy(func(in *core.MessageInOut) bool {
+		return in.Header.Tag == "tag1" &&
+			in.Header.Topics.String() == "topic1" &&
+			in.InlineData[0].Value.JSONObject().GetString("foo") == "bar" &&
+			in.IdempotencyKey == "idem1"
+	}), true).Return(msg, nil)
+
+	value, err := structpb.NewValue(map[string]interface{}{"foo": "bar"})
+	assert.NoError(t, err)
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "X-FireFly-Request-ID", "req1")
+	out, err := newTestGRPCClient(t, mgr, as).BroadcastMessage(ctx, &grpcapi.BroadcastMessageRequest{
+		Confirm: true,
+		Message: &grpcapi.MessageInput{
+			Header:         &grpcapi.MessageHeaderInput{Tag: "tag1", Topics: []string{"topic1"}},
+			Data:           []*grpcapi.DataInput{{Value: value}},
+			IdempotencyKey: "idem1",
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, msg.Header.ID.String(), out.Header.Id)
+	assert.Equal(t, []string{"topic1"}, out.Header.Topics)
+	assert.Equal(t, msg.Header.Created.Time().UnixNano(), out.Header.Created.AsTime().UnixNano())
+	assert.Equal(t, msg.Data[0].Hash.String(), out.Data[0].Hash)
+	assert.Equal(t, "confirmed", out.State)
+	assert.Equal(t, "grpcBroadcastMessage", record.Route)
+	assert.Equal(t, "/api/v1/namespaces/default/messages/broadcast", record.Path)
+	assert.Equal(t, "req1", record.RequestID)
+	assert.Equal(t, core.IdempotencyKey("idem1"), record.IdempotencyKey)
+	mbm.AssertExpectations(t)
+}
+
+func TestGRPCSendPrivateMessage(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	o.On("MultiParty").Return(&multipartymocks.Manager{})
+	mpm := &privatemessagingmocks.Manager{}
+	o.On("PrivateMessaging").Return(mpm)
+	msgID := fftypes.NewUUID()
+	mpm.On("SendMessage", mock.Anything, mock.MatchedBy(func(in *core.MessageInOut) bool {
+		return in.Group.Members[0].Identity == "org1"
+	}), false).Return(&core.Message{Header: core.MessageHeader{ID: msgID}}, nil)
+
+	out, err := newTestGRPCClient(t, mgr, as).SendPrivateMessage(context.Background(), &grpcapi.SendPrivateMessageRequest{
+		Namespace: "ns1",
+		Message: &grpcapi.MessageInput{
+			Group: &grpcapi.GroupInput{Members: []*grpcapi.MemberInput{{Identity: "org1"}}},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, msgID.String(), out.Header.Id)
+	mpm.AssertExpectations(t)
+}
+
+func TestGRPCMintTokens(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mam := &assetmocks.Manager{}
+	o.On("Assets").Return(mam)
+	localID := fftypes.NewUUID()
+	mam.On("MintTokens", mock.Anything, mock.MatchedBy(func(in *core.TokenTransferInput) bool {
+		return in.Pool == "pool1" && in.Amount.Int64() == 1000
+	}), true).Return(&core.TokenTransfer{LocalID: localID, Type: core.TokenTransferTypeMint, Amount: *fftypes.NewFFBigInt(1000)}, nil)
+
+	out, err := newTestGRPCClient(t, mgr, as).MintTokens(context.Background(), &grpcapi.MintTokensRequest{
+		Namespace: "ns1",
+		Confirm:   true,
+		Transfer:  &grpcapi.TokenTransferInput{Pool: "pool1", Amount: "1000"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, localID.String(), out.LocalId)
+	assert.Equal(t, "mint", out.Type)
+	assert.Equal(t, "1000", out.Amount)
+	mam.AssertExpectations(t)
+}
+
+func TestGRPCTransferTokens(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mam := &assetmocks.Manager{}
+	o.On("Assets").Return(mam)
+	mam.On("TransferTokens", mock.Anything, mock.MatchedBy(func(in *core.TokenTransferInput) bool {
+		return in.To == "0x1234" && in.Config.GetString("foo") == "bar"
+	}), false).Return(&core.TokenTransfer{To: "0x1234", Amount: *fftypes.NewFFBigInt(1)}, nil)
+
+	out, err := newTestGRPCClient(t, mgr, as).TransferTokens(context.Background(), &grpcapi.TransferTokensRequest{
+		Namespace: "ns1",
+		Transfer: &grpcapi.TokenTransferInput{
+			To:     "0x1234",
+			Amount: "1",
+			Config: newTestStruct(t, map[string]interface{}{"foo": "bar"}),
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1234", out.To)
+	mam.AssertExpectations(t)
+}
+
+func TestGRPCInvokeContract(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mcm := &contractmocks.Manager{}
+	o.On("Contracts").Return(mcm)
+	opID := fftypes.NewUUID()
+	mcm.On("InvokeContract", mock.Anything, mock.MatchedBy(func(in *core.ContractCallRequest) bool {
+		return in.Type == core.CallTypeInvoke &&
+			in.Location.JSONObject().GetString("address") == "0x1234" &&
+			in.Input["x"] == float64(1)
+	}), true).Return(&core.Operation{ID: opID}, nil)
+
+	location, err := structpb.NewValue(map[string]interface{}{"address": "0x1234"})
+	assert.NoError(t, err)
+	out, err := newTestGRPCClient(t, mgr, as).InvokeContract(context.Background(), &grpcapi.InvokeContractRequest{
+		Namespace: "ns1",
+		Confirm:   true,
+		Call: &grpcapi.ContractCallRequest{
+			Location: location,
+			Input:    newTestStruct(t, map[string]interface{}{"x": 1}),
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, opID.String(), out.Result.GetStructValue().Fields["id"].GetStringValue())
+	mcm.AssertExpectations(t)
+}
+
+func TestGRPCQueryContract(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mcm := &contractmocks.Manager{}
+	o.On("Contracts").Return(mcm)
+	mcm.On("InvokeContract", mock.Anything, mock.MatchedBy(func(in *core.ContractCallRequest) bool {
+		return in.Type == core.CallTypeQuery
+	}), true).Return(map[string]interface{}{"output": "42"}, nil)
+
+	out, err := newTestGRPCClient(t, mgr, as).QueryContract(context.Background(), &grpcapi.QueryContractRequest{
+		Namespace: "ns1",
+		Call:      &grpcapi.ContractCallRequest{},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "42", out.Result.GetStructValue().Fields["output"].GetStringValue())
+	mcm.AssertExpectations(t)
+}
+
+func TestGRPCInputError(t *testing.T) {
+	mgr, _, as := newTestServer()
+
+	_, err := newTestGRPCClient(t, mgr, as).BroadcastMessage(context.Background(), &grpcapi.BroadcastMessageRequest{
+		Message: &grpcapi.MessageInput{Data: []*grpcapi.DataInput{{Id: "bad"}}},
+	})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Regexp(t, "FF10555", status.Convert(err).Message())
+}
+
+func TestGRPCResponseError(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mcm := &contractmocks.Manager{}
+	o.On("Contracts").Return(mcm)
+	mcm.On("InvokeContract", mock.Anything, mock.Anything, true).Return(map[string]interface{}{"output": make(chan int)}, nil)
+	client := newTestGRPCClient(t, mgr, as)
+
+	_, err := client.QueryContract(context.Background(), &grpcapi.QueryContractRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Regexp(t, "FF00165", status.Convert(err).Message())
+
+	_, err = client.InvokeContract(context.Background(), &grpcapi.InvokeContractRequest{Confirm: true})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Regexp(t, "FF00165", status.Convert(err).Message())
+}
+
+func TestGRPCRequestErrors(t *testing.T) {
+	mgr, o, as := newTestServer()
+	mgr.On("Orchestrator", mock.Anything, "ns2", false).Return(nil, i18n.NewError(context.Background(), coremsgs.MsgUnknownNamespace, "ns2"))
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil).Once()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(i18n.NewError(context.Background(), i18n.MsgUnauthorized))
+	mbm := mockTestBroadcast(o)
+	mbm.On("BroadcastMessage", mock.Anything, mock.Anything, false).Return(nil, i18n.NewError(context.Background(), coremsgs.Msg404NoResult))
+	client := newTestGRPCClient(t, mgr, as)
+
+	// Errors from the core are mapped in the same way as the REST API
+	_, err := client.BroadcastMessage(context.Background(), &grpcapi.BroadcastMessageRequest{Namespace: "ns1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Regexp(t, "FF10143", status.Convert(err).Message())
+
+	_, err = client.BroadcastMessage(context.Background(), &grpcapi.BroadcastMessageRequest{Namespace: "ns1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Regexp(t, "FF00169", status.Convert(err).Message())
+
+	_, err = client.BroadcastMessage(context.Background(), &grpcapi.BroadcastMessageRequest{Namespace: "ns2"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Regexp(t, "FF10436.*ns2", status.Convert(err).Message())
+}
+
+func TestGRPCRateLimited(t *testing.T) {
+	mgr, o, as := newTestServer()
+	as.rateLimiter = &rateLimiter{
+		namespace: &bucketLimit{rate: 1, burst: 1},
+		buckets:   map[bucketKey]*tokenBucket{{limit: limitNamespace, namespace: "ns1"}: {limit: &bucketLimit{rate: 1, burst: 1}, last: time.Now()}},
+		quotaDay:  time.Now().UTC().Truncate(24 * time.Hour),
+		quotaUsed: map[quotaKey]int64{},
+		now:       time.Now,
+	}
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+
+	_, err := newTestGRPCClient(t, mgr, as).MintTokens(context.Background(), &grpcapi.MintTokensRequest{Namespace: "ns1"})
+
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Regexp(t, "FF10533", status.Convert(err).Message())
+}
+
+func TestGRPCSubscribeEvents(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.MatchedBy(func(req *fftypes.AuthReq) bool {
+		return req.Method == http.MethodGet && req.URL.Path == "/api/v1/namespaces/ns1/events"
+	})).Return(nil)
+	cbs, started := newTestGRPCStreams(t)
+	closed := make(chan struct{})
+	cbs.On("ConnectionClosed", mock.Anything).Run(func(args mock.Arguments) { close(closed) }).Return()
+	acked := make(chan *core.EventDeliveryResponse, 1)
+	cbs.On("DeliveryResponse", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		acked <- args[1].(*core.EventDeliveryResponse)
+	}).Return()
+	client := newTestGRPCClient(t, mgr, as)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.SubscribeEvents(ctx, &grpcapi.SubscribeEventsRequest{
+		Namespace: "ns1",
+		Ephemeral: true,
+		Filter:    newTestStruct(t, map[string]interface{}{"events": "message_confirmed"}),
+		Options:   newTestStruct(t, map[string]interface{}{"readAhead": 5}),
+	})
+	assert.NoError(t, err)
+	_, err = stream.Header()
+	assert.NoError(t, err)
+	connID := <-started
+	filter := cbs.Calls[0].Arguments[2].(*core.SubscriptionFilter)
+	assert.Equal(t, "message_confirmed", filter.Events)
+	options := cbs.Calls[0].Arguments[3].(*core.SubscriptionOptions)
+	assert.Equal(t, uint16(5), *options.ReadAhead)
+
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{
+				ID:        fftypes.NewUUID(),
+				Type:      core.EventTypeMessageConfirmed,
+				Namespace: "ns1",
+				Created:   fftypes.Now(),
+			},
+			Message: &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}},
+		},
+		Subscription: core.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "ephemeral"},
+	}
+	err = getGRPCStreams(ctx).DeliveryRequest(ctx, connID, nil, event, nil)
+	assert.NoError(t, err)
+	delivery, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, connID, delivery.ConnectionId)
+	assert.Equal(t, event.ID.String(), delivery.Id)
+	assert.Equal(t, "message_confirmed", delivery.Type)
+	assert.Equal(t, event.Message.Header.ID.String(), delivery.Message.Header.Id)
+	assert.Equal(t, event.Subscription.ID.String(), delivery.Subscription.Id)
+
+	_, err = client.AckEvent(context.Background(), &grpcapi.AckEventRequest{
+		Namespace:    "ns1",
+		ConnectionId: connID,
+		Id:           delivery.Id,
+		Subscription: &grpcapi.SubscriptionRef{Id: delivery.Subscription.Id},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, event.ID, (<-acked).ID)
+
+	cancel()
+	<-closed
+}
+
+func TestGRPCSubscribeEventsSendError(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	cbs, started := newTestGRPCStreams(t)
+	cbs.On("ConnectionClosed", mock.Anything).Return()
+
+	stream, err := newTestGRPCClient(t, mgr, as).SubscribeEvents(context.Background(), &grpcapi.SubscribeEventsRequest{
+		Namespace: "ns1",
+		Ephemeral: true,
+		AutoAck:   true,
+	})
+	assert.NoError(t, err)
+	connID := <-started
+	event := &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{ID: fftypes.NewUUID()},
+			Data:  &core.Data{Value: fftypes.JSONAnyPtr("!json")},
+		},
+	}
+	_ = getGRPCStreams(context.Background()).DeliveryRequest(context.Background(), connID, nil, event, nil)
+	_, err = stream.Recv()
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Regexp(t, "FF00165", status.Convert(err).Message())
+}
+
+func TestGRPCSubscribeEventsBadRequest(t *testing.T) {
+	mgr, o, as := newTestServer()
+	mgr.On("Orchestrator", mock.Anything, "ns2", false).Return(nil, i18n.NewError(context.Background(), coremsgs.MsgUnknownNamespace, "ns2"))
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	newTestGRPCStreams(t)
+	client := newTestGRPCClient(t, mgr, as)
+
+	for _, in := range []*grpcapi.SubscribeEventsRequest{
+		{Ephemeral: true, Filter: newTestStruct(t, map[string]interface{}{"events": 1})},
+		{Ephemeral: true, Options: newTestStruct(t, map[string]interface{}{"readAhead": "many"})},
+		{Namespace: "ns1"},
+	} {
+		stream, err := client.SubscribeEvents(context.Background(), in)
+		assert.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Regexp(t, "FF10555|FF10559", status.Convert(err).Message())
+	}
+
+	stream, err := client.SubscribeEvents(context.Background(), &grpcapi.SubscribeEventsRequest{Namespace: "ns2", Ephemeral: true})
+	assert.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Regexp(t, "FF10436", status.Convert(err).Message())
+
+	gs := &grpcService{as: as, mgr: mgr}
+	err = gs.SubscribeEvents(&grpcapi.SubscribeEventsRequest{Ephemeral: true}, &failingHeaderStream{})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGRPCAckEventErrors(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	newTestGRPCStreams(t)
+	client := newTestGRPCClient(t, mgr, as)
+
+	_, err := client.AckEvent(context.Background(), &grpcapi.AckEventRequest{Id: "bad"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Regexp(t, "FF00138", status.Convert(err).Message())
+
+	_, err = client.AckEvent(context.Background(), &grpcapi.AckEventRequest{Subscription: &grpcapi.SubscriptionRef{Id: "bad"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Regexp(t, "FF10555", status.Convert(err).Message())
+
+	_, err = client.AckEvent(context.Background(), &grpcapi.AckEventRequest{Namespace: "ns1", ConnectionId: "conn1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Regexp(t, "FF10557.*conn1", status.Convert(err).Message())
+}
+
+func TestToProtoError(t *testing.T) {
+	err := toProto(context.Background(), map[string]interface{}{"hash": 1}, &grpcapi.Message{})
+	assert.Regexp(t, "FF00165", err)
+}
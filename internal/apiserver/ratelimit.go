@@ -0,0 +1,271 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/auth/rbac"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/metrics"
+)
+
+// rateLimitGroupsConfig has the limits on requests from each principal to each route group
+var rateLimitGroupsConfig = config.RootArray("api.rateLimit.groups")
+
+const (
+	RateLimitGroupName              = "name"
+	RateLimitGroupRequestsPerSecond = "requestsPerSecond"
+	RateLimitGroupBurst             = "burst"
+)
+
+const (
+	limitNamespace = "namespace"
+	limitPrincipal = "principal"
+	limitGroup     = "group"
+)
+
+func initRateLimitConfig(conf config.ArraySection) {
+	conf.AddKnownKey(RateLimitGroupName)
+	conf.AddKnownKey(RateLimitGroupRequestsPerSecond)
+	conf.AddKnownKey(RateLimitGroupBurst)
+}
+
+// bucketLimit is the configuration of a token bucket - it refills at rate tokens per second, up to burst tokens
+type bucketLimit struct {
+	rate  float64
+	burst float64
+}
+
+// newBucketLimit returns nil when the rate is not set, for no limit. The burst defaults to one second of requests.
+func newBucketLimit(rate float64, burst int) *bucketLimit {
+	if rate <= 0 {
+		return nil
+	}
+	l := &bucketLimit{rate: rate, burst: float64(burst)}
+	if burst <= 0 {
+		l.burst = math.Ceil(rate)
+	}
+	return l
+}
+
+type tokenBucket struct {
+	limit  *bucketLimit
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.limit.burst, b.tokens+now.Sub(b.last).Seconds()*b.limit.rate)
+	b.last = now
+}
+
+// bucketKey identifies a bucket - principal and group are only set for the limits that apply to them
+type bucketKey struct {
+	limit     string
+	namespace string
+	principal string
+	group     string
+}
+
+type quotaKey struct {
+	namespace string
+	principal string
+}
+
+// quotaReservation is the quota taken by a chain-writing request before it is handled, which is settled
+// against the number of writes that were accepted once the handler returns
+type quotaReservation struct {
+	day       time.Time
+	namespace string
+	principal string
+	count     int64
+}
+
+// rateLimiter applies token bucket rate limits to the requests to each namespace, from each principal in a
+// namespace, and from each principal to each route group. It also applies daily quotas to chain-writing requests.
+// All the state is in memory, so a restart resets the buckets and the quotas used so far that day.
+type rateLimiter struct {
+	mux            sync.Mutex
+	metricsEnabled bool
+	namespace      *bucketLimit
+	principal      *bucketLimit
+	groups         map[string]*bucketLimit
+	namespaceQuota int64
+	principalQuota int64
+	buckets        map[bucketKey]*tokenBucket
+	quotaDay       time.Time
+	quotaUsed      map[quotaKey]int64
+	now            func() time.Time
+}
+
+// newRateLimiter returns nil when no limits or quotas are configured
+func newRateLimiter(ctx context.Context, metricsEnabled bool) (*rateLimiter, error) {
+	rl := &rateLimiter{
+		metricsEnabled: metricsEnabled,
+		namespace:      newBucketLimit(config.GetFloat64(coreconfig.APIRateLimitNamespaceRequestsPerSecond), config.GetInt(coreconfig.APIRateLimitNamespaceBurst)),
+		principal:      newBucketLimit(config.GetFloat64(coreconfig.APIRateLimitPrincipalRequestsPerSecond), config.GetInt(coreconfig.APIRateLimitPrincipalBurst)),
+		groups:         make(map[string]*bucketLimit),
+		namespaceQuota: config.GetInt64(coreconfig.APIQuotaNamespaceDailyChainWrites),
+		principalQuota: config.GetInt64(coreconfig.APIQuotaPrincipalDailyChainWrites),
+		buckets:        make(map[bucketKey]*tokenBucket),
+		quotaUsed:      make(map[quotaKey]int64),
+		now:            time.Now,
+	}
+	groupCount := rateLimitGroupsConfig.ArraySize()
+	for i := 0; i < groupCount; i++ {
+		conf := rateLimitGroupsConfig.ArrayEntry(i)
+		name := conf.GetString(RateLimitGroupName)
+		if !rbac.IsRouteGroup(name) {
+			return nil, i18n.NewError(ctx, coremsgs.MsgRateLimitInvalidGroup, name, i)
+		}
+		if limit := newBucketLimit(conf.GetFloat64(RateLimitGroupRequestsPerSecond), conf.GetInt(RateLimitGroupBurst)); limit != nil {
+			rl.groups[name] = limit
+		}
+	}
+	if rl.namespace == nil && rl.principal == nil && len(rl.groups) == 0 && rl.namespaceQuota <= 0 && rl.principalQuota <= 0 {
+		return nil, nil
+	}
+	return rl, nil
+}
+
+// limitRequest checks every limit that applies to a request, and only takes tokens from each of them
+// if they all allow it. If the request is rejected, it returns how long until it would be allowed.
+// A request that counts as more than one, such as a bulk request, takes a token for each. When that is
+// more than the burst it is allowed with a full bucket, which then waits to refill from below zero.
+// A chain-writing request reserves its quota, so concurrent requests cannot overrun it, and the
+// reservation must be settled with settleQuota after the request is handled.
+func (rl *rateLimiter) limitRequest(ctx context.Context, ns, principal, group string, chainWrite bool, count int) (*quotaReservation, time.Duration, error) {
+	rl.mux.Lock()
+	defer rl.mux.Unlock()
+	now := rl.now()
+	if day := now.UTC().Truncate(24 * time.Hour); !day.Equal(rl.quotaDay) {
+		// Quotas are for each UTC day, and the buckets of principals that have gone quiet are dropped daily
+		rl.quotaDay = day
+		rl.quotaUsed = make(map[quotaKey]int64)
+		rl.pruneBuckets(now)
+	}
+
+	var retryAfter time.Duration
+	limited := ""
+	buckets := make([]*tokenBucket, 0, 3)
+	for _, check := range []struct {
+		key   bucketKey
+		limit *bucketLimit
+	}{
+		{bucketKey{limit: limitNamespace, namespace: ns}, rl.namespace},
+		{bucketKey{limit: limitPrincipal, namespace: ns, principal: principal}, rl.principal},
+		{bucketKey{limit: limitGroup, namespace: ns, principal: principal, group: group}, rl.groups[group]},
+	} {
+		if check.limit == nil {
+			continue
+		}
+		b := rl.bucket(check.key, check.limit, now)
+		if needed := math.Min(float64(count), check.limit.burst); b.tokens < needed {
+			if wait := time.Duration((needed - b.tokens) / check.limit.rate * float64(time.Second)); wait > retryAfter {
+				retryAfter, limited = wait, check.key.limit
+			}
+		}
+		buckets = append(buckets, b)
+	}
+	if limited != "" {
+		rl.countRejected(ns, limited)
+		return nil, retryAfter, i18n.NewError(ctx, coremsgs.MsgRateLimited, limited, ns)
+	}
+
+	var res *quotaReservation
+	if chainWrite {
+		var err error
+		if res, err = rl.reserveQuota(ctx, ns, principal, int64(count)); err != nil {
+			return nil, rl.quotaDay.Add(24 * time.Hour).Sub(now), err
+		}
+	}
+	for _, b := range buckets {
+		b.tokens -= float64(count)
+	}
+	return res, 0, nil
+}
+
+func (rl *rateLimiter) bucket(key bucketKey, limit *bucketLimit, now time.Time) *tokenBucket {
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &tokenBucket{limit: limit, tokens: limit.burst, last: now}
+		rl.buckets[key] = b
+	}
+	b.refill(now)
+	return b
+}
+
+// reserveQuota takes the quota for a chain-writing request from the quotas for the current UTC day
+func (rl *rateLimiter) reserveQuota(ctx context.Context, ns, principal string, count int64) (*quotaReservation, error) {
+	nsKey := quotaKey{namespace: ns}
+	principalKey := quotaKey{namespace: ns, principal: principal}
+	if rl.namespaceQuota > 0 && rl.quotaUsed[nsKey]+count > rl.namespaceQuota {
+		rl.countRejected(ns, "namespaceQuota")
+		return nil, i18n.NewError(ctx, coremsgs.MsgChainWriteQuotaExceeded, rl.namespaceQuota, limitNamespace, ns)
+	}
+	if rl.principalQuota > 0 && rl.quotaUsed[principalKey]+count > rl.principalQuota {
+		rl.countRejected(ns, "principalQuota")
+		return nil, i18n.NewError(ctx, coremsgs.MsgChainWriteQuotaExceeded, rl.principalQuota, limitPrincipal, ns)
+	}
+	rl.quotaUsed[nsKey] += count
+	rl.quotaUsed[principalKey] += count
+	rl.updateQuotaRemaining(ns)
+	return &quotaReservation{day: rl.quotaDay, namespace: ns, principal: principal, count: count}, nil
+}
+
+// settleQuota counts the writes of a request that were accepted, and returns the rest of its reservation
+// to the quotas. Nothing is returned once the quotas have been reset for a new day.
+func (rl *rateLimiter) settleQuota(res *quotaReservation, accepted int64) {
+	rl.mux.Lock()
+	defer rl.mux.Unlock()
+	if rl.metricsEnabled && accepted > 0 {
+		metrics.APIChainWritesCounter.WithLabelValues(res.namespace).Add(float64(accepted))
+	}
+	if unused := res.count - accepted; unused > 0 && res.day.Equal(rl.quotaDay) {
+		rl.quotaUsed[quotaKey{namespace: res.namespace}] -= unused
+		rl.quotaUsed[quotaKey{namespace: res.namespace, principal: res.principal}] -= unused
+		rl.updateQuotaRemaining(res.namespace)
+	}
+}
+
+func (rl *rateLimiter) updateQuotaRemaining(ns string) {
+	if rl.metricsEnabled && rl.namespaceQuota > 0 {
+		metrics.APIChainWriteQuotaRemainingGauge.WithLabelValues(ns).Set(float64(rl.namespaceQuota - rl.quotaUsed[quotaKey{namespace: ns}]))
+	}
+}
+
+// pruneBuckets removes the buckets that have refilled, as they are the same as a new bucket
+func (rl *rateLimiter) pruneBuckets(now time.Time) {
+	for key, b := range rl.buckets {
+		if b.refill(now); b.tokens >= b.limit.burst {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+func (rl *rateLimiter) countRejected(ns, limit string) {
+	if rl.metricsEnabled {
+		metrics.APIRateLimitedCounter.WithLabelValues(ns, limit).Inc()
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/multipartymocks"
+	"github.com/hyperledger/firefly/mocks/namespacemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRateLimiter(t *testing.T, yaml string) (*rateLimiter, error) {
+	coreconfig.Reset()
+	InitConfig()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(yaml))
+	assert.NoError(t, err)
+	metrics.Clear()
+	metrics.Registry()
+	return newRateLimiter(context.Background(), true)
+}
+
+func TestNewRateLimiterNotConfigured(t *testing.T) {
+	rl, err := newTestRateLimiter(t, "")
+	assert.NoError(t, err)
+	assert.Nil(t, rl)
+}
+
+func TestNewRateLimiterBadGroup(t *testing.T) {
+	_, err := newTestRateLimiter(t, `
+api:
+  rateLimit:
+    groups:
+    - name: wrong
+      requestsPerSecond: 1
+`)
+	assert.Regexp(t, "FF10535.*wrong", err)
+}
+
+func TestNewRateLimiterDefaultBurst(t *testing.T) {
+	rl, err := newTestRateLimiter(t, `
+api:
+  rateLimit:
+    namespace:
+      requestsPerSecond: 2.5
+    groups:
+    - name: messages
+      requestsPerSecond: 0
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, &bucketLimit{rate: 2.5, burst: 3}, rl.namespace)
+	assert.Nil(t, rl.principal)
+	assert.Empty(t, rl.groups)
+}
+
+func TestRateLimits(t *testing.T) {
+	rl, err := newTestRateLimiter(t, `
+api:
+  rateLimit:
+    namespace:
+      requestsPerSecond: 1
+      burst: 3
+    principal:
+      requestsPerSecond: 1
+      burst: 2
+    groups:
+    - name: messages
+      requestsPerSecond: 0.5
+      burst: 1
+`)
+	assert.NoError(t, err)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// The group limit stops alice sending a second message
+	_, _, err = rl.limitRequest(ctx, "ns1", "alice", "messages", false, 1)
+	assert.NoError(t, err)
+	_, retryAfter, err := rl.limitRequest(ctx, "ns1", "alice", "messages", false, 1)
+	assert.Regexp(t, "FF10533.*group.*ns1", err)
+	assert.Equal(t, 2*time.Second, retryAfter)
+
+	// ... and the principal limit stops her doing anything else
+	_, _, err = rl.limitRequest(ctx, "ns1", "alice", "tokens", false, 1)
+	assert.NoError(t, err)
+	_, retryAfter, err = rl.limitRequest(ctx, "ns1", "alice", "tokens", false, 1)
+	assert.Regexp(t, "FF10533.*principal", err)
+	assert.Equal(t, time.Second, retryAfter)
+
+	// ... until her bucket refills
+	now = now.Add(time.Second)
+	_, _, err = rl.limitRequest(ctx, "ns1", "alice", "tokens", false, 1)
+	assert.NoError(t, err)
+
+	// The namespace limit is shared by every principal, and a rejected request does not use a token
+	_, _, err = rl.limitRequest(ctx, "ns1", "bob", "tokens", false, 1)
+	assert.NoError(t, err)
+	_, _, err = rl.limitRequest(ctx, "ns1", "carol", "tokens", false, 1)
+	assert.Regexp(t, "FF10533.*namespace", err)
+	_, _, err = rl.limitRequest(ctx, "ns2", "carol", "tokens", false, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), rl.buckets[bucketKey{limit: limitPrincipal, namespace: "ns1", principal: "bob"}].tokens)
+}
+
+func TestChainWriteQuotas(t *testing.T) {
+	rl, err := newTestRateLimiter(t, `
+api:
+  quota:
+    namespaceDailyChainWrites: 3
+    principalDailyChainWrites: 2
+`)
+	assert.NoError(t, err)
+	now := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, _, err = rl.limitRequest(ctx, "ns1", "alice", "messages", true, 1)
+		assert.NoError(t, err)
+	}
+	_, retryAfter, err := rl.limitRequest(ctx, "ns1", "alice", "messages", true, 1)
+	assert.Regexp(t, "FF10534.*2.*principal.*ns1", err)
+	assert.Equal(t, 6*time.Hour, retryAfter)
+	// Other requests are not counted
+	_, _, err = rl.limitRequest(ctx, "ns1", "alice", "messages", false, 1)
+	assert.NoError(t, err)
+
+	_, _, err = rl.limitRequest(ctx, "ns1", "bob", "messages", true, 1)
+	assert.NoError(t, err)
+	_, _, err = rl.limitRequest(ctx, "ns1", "bob", "messages", true, 1)
+	assert.Regexp(t, "FF10534.*3.*namespace.*ns1", err)
+
+	// The quotas reset each day
+	now = now.Add(6 * time.Hour)
+	_, _, err = rl.limitRequest(ctx, "ns1", "alice", "messages", true, 1)
+	assert.NoError(t, err)
+}
+
+func TestRateLimitsBulk(t *testing.T) {
+	rl, err := newTestRateLimiter(t, `
+api:
+  rateLimit:
+    principal:
+      requestsPerSecond: 1
+      burst: 5
+  quota:
+    principalDailyChainWrites: 20
+`)
+	assert.NoError(t, err)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Each message in a bulk request takes a token
+	_, _, err = rl.limitRequest(ctx, "ns1", "alice", "messages", true, 3)
+	assert.NoError(t, err)
+	_, retryAfter, err := rl.limitRequest(ctx, "ns1", "alice", "messages", true, 3)
+	assert.Regexp(t, "FF10533.*principal", err)
+	assert.Equal(t, time.Second, retryAfter)
+
+	// ... and a request larger than the burst needs a full bucket, which it then overdraws
+	now = now.Add(2 * time.Second)
+	_, retryAfter, err = rl.limitRequest(ctx, "ns1", "alice", "messages", true, 10)
+	assert.Regexp(t, "FF10533.*principal", err)
+	assert.Equal(t, time.Second, retryAfter)
+	now = now.Add(time.Second)
+	_, _, err = rl.limitRequest(ctx, "ns1", "alice", "messages", true, 10)
+	assert.NoError(t, err)
+	_, retryAfter, err = rl.limitRequest(ctx, "ns1", "alice", "messages", true, 1)
+	assert.Regexp(t, "FF10533.*principal", err)
+	assert.Equal(t, 6*time.Second, retryAfter)
+
+	// Each message also counts against the quota
+	assert.Equal(t, int64(13), rl.quotaUsed[quotaKey{namespace: "ns1", principal: "alice"}])
+	now = now.Add(time.Minute)
+	_, _, err = rl.limitRequest(ctx, "ns1", "alice", "messages", true, 8)
+	assert.Regexp(t, "FF10534.*20.*principal", err)
+	_, _, err = rl.limitRequest(ctx, "ns1", "alice", "messages", true, 5)
+	assert.NoError(t, err)
+}
+
+func TestSettleQuota(t *testing.T) {
+	rl, err := newTestRateLimiter(t, `
+api:
+  quota:
+    namespaceDailyChainWrites: 10
+`)
+	assert.NoError(t, err)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	ctx := context.Background()
+	nsKey := quotaKey{namespace: "ns1"}
+	principalKey := quotaKey{namespace: "ns1", principal: "alice"}
+
+	// The quota is reserved before the request is handled, and what was not accepted is returned
+	res, _, err := rl.limitRequest(ctx, "ns1", "alice", "messages", true, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), rl.quotaUsed[nsKey])
+	rl.settleQuota(res, 2)
+	assert.Equal(t, int64(2), rl.quotaUsed[nsKey])
+	assert.Equal(t, int64(2), rl.quotaUsed[principalKey])
+
+	// A reservation from before the daily reset is not returned
+	res, _, err = rl.limitRequest(ctx, "ns1", "alice", "messages", true, 5)
+	assert.NoError(t, err)
+	now = now.Add(24 * time.Hour)
+	_, _, err = rl.limitRequest(ctx, "ns1", "alice", "messages", true, 1)
+	assert.NoError(t, err)
+	rl.settleQuota(res, 0)
+	assert.Equal(t, int64(1), rl.quotaUsed[nsKey])
+
+	// Requests that are not chain writes do not reserve anything
+	res, _, err = rl.limitRequest(ctx, "ns1", "alice", "messages", false, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestAcceptedCount(t *testing.T) {
+	assert.Equal(t, int64(0), acceptedCount(&core.Message{}, fmt.Errorf("pop"), 1))
+	assert.Equal(t, int64(1), acceptedCount(&core.Message{}, nil, 1))
+	assert.Equal(t, int64(2), acceptedCount([]*core.MessageBulkResult{
+		{Message: &core.Message{}},
+		{Error: "pop"},
+		{Message: &core.Message{}},
+	}, nil, 3))
+}
+
+func TestRequestCount(t *testing.T) {
+	assert.Equal(t, 1, requestCount(nil))
+	assert.Equal(t, 1, requestCount(&core.MessageInOut{}))
+	assert.Equal(t, 1, requestCount(&[]*core.MessageInOut{}))
+	assert.Equal(t, 3, requestCount(&[]*core.MessageInOut{{}, {}, {}}))
+}
+
+func TestRateLimitPruneBuckets(t *testing.T) {
+	rl := &rateLimiter{
+		principal: &bucketLimit{rate: 1, burst: 1},
+		groups:    map[string]*bucketLimit{},
+		buckets:   map[bucketKey]*tokenBucket{},
+		quotaUsed: map[quotaKey]int64{},
+	}
+	now := time.Date(2024, 1, 1, 23, 59, 59, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, _, err := rl.limitRequest(ctx, "ns1", "alice", "messages", false, 1)
+	assert.NoError(t, err)
+	assert.Len(t, rl.buckets, 1)
+
+	// A new day drops the buckets that have refilled, but not the ones in use
+	now = now.Add(time.Second)
+	_, _, err = rl.limitRequest(ctx, "ns1", "bob", "messages", false, 1)
+	assert.NoError(t, err)
+	assert.Len(t, rl.buckets, 1)
+	assert.NotNil(t, rl.buckets[bucketKey{limit: limitPrincipal, namespace: "ns1", principal: "bob"}])
+}
+
+func TestStartBadRateLimitConfig(t *testing.T) {
+	_, err := newTestRateLimiter(t, `
+api:
+  rateLimit:
+    groups:
+    - name: wrong
+`)
+	assert.Error(t, err)
+	as := NewAPIServer()
+	err = as.Serve(context.Background(), &namespacemocks.Manager{})
+	assert.Regexp(t, "FF10535", err)
+}
+
+func TestRouteRateLimited(t *testing.T) {
+	mgr, o, as := newTestServer()
+	rl, err := newTestRateLimiter(t, `
+api:
+  rateLimit:
+    principal:
+      requestsPerSecond: 0.1
+      burst: 1
+  quota:
+    namespaceDailyChainWrites: 1
+`)
+	assert.NoError(t, err)
+	as.rateLimiter = rl
+	o.On("Authorize", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		ctx := args[0].(context.Context)
+		if args[1].(*fftypes.AuthReq).Header.Get("X-Test-Principal") != "" {
+			core.GetAuthRequestInfo(ctx).Principal = "basic:alice"
+		}
+	}).Return(nil)
+	o.On("GetStatus", mock.Anything).Return(&core.NamespaceStatus{}, nil)
+	o.On("MultiParty").Return(&multipartymocks.Manager{})
+	mbm := &broadcastmocks.Manager{}
+	o.On("Broadcast").Return(mbm)
+	mbm.On("BroadcastMessage", mock.Anything, mock.Anything, false).Return(nil, fmt.Errorf("pop")).Once()
+	mbm.On("BroadcastMessage", mock.Anything, mock.Anything, false).Return(&core.Message{}, nil)
+	r := as.createMuxRouter(context.Background(), mgr)
+	serve := func(method, path, principal string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(`{}`)))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("X-Test-Principal", principal)
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		return res
+	}
+
+	// Requests without a principal are limited by client address
+	assert.Equal(t, 200, serve(http.MethodGet, "/api/v1/status", "").Code)
+	res := serve(http.MethodGet, "/api/v1/namespaces/default/status", "")
+	assert.Equal(t, 429, res.Code)
+	assert.Equal(t, "10", res.Header().Get("Retry-After"))
+	assert.Contains(t, rl.buckets, bucketKey{limit: limitPrincipal, namespace: "default", principal: "addr:192.0.2.1"})
+
+	// ... and separately from the principal from the auth plugin, with the chain-writing quota,
+	// which is only used by the requests that succeed
+	assert.Equal(t, 500, serve(http.MethodPost, "/api/v1/namespaces/ns1/messages/broadcast", "alice").Code)
+	rl.buckets = map[bucketKey]*tokenBucket{}
+	assert.Equal(t, 202, serve(http.MethodPost, "/api/v1/namespaces/ns1/messages/broadcast", "alice").Code)
+	rl.buckets = map[bucketKey]*tokenBucket{}
+	res = serve(http.MethodPost, "/api/v1/namespaces/ns1/messages/broadcast", "alice")
+	assert.Equal(t, 429, res.Code)
+	assert.Regexp(t, "FF10534", res.Body.String())
+	assert.NotEmpty(t, res.Header().Get("Retry-After"))
+
+	// Routes outside of a namespace are not limited
+	mgr.On("GetNamespaces", mock.Anything, mock.Anything).Return([]*core.NamespaceWithInitStatus{}, nil)
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, 200, serve(http.MethodGet, "/api/v1/namespaces", "").Code)
+	}
+}
+
+func TestFormDataRateLimited(t *testing.T) {
+	mgr, o, as := newTestServer()
+	as.rateLimiter = &rateLimiter{
+		namespace: &bucketLimit{rate: 1, burst: 1},
+		buckets:   map[bucketKey]*tokenBucket{{limit: limitNamespace, namespace: "ns1"}: {limit: &bucketLimit{rate: 1, burst: 1}, last: time.Now()}},
+		quotaDay:  time.Now().UTC().Truncate(24 * time.Hour),
+		quotaUsed: map[quotaKey]int64{},
+		now:       time.Now,
+	}
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	writer, err := w.CreateFormFile("file", "filename.ext")
+	assert.NoError(t, err)
+	writer.Write([]byte(`some data`))
+	w.Close()
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/data", &b)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 429, res.Result().StatusCode)
+	assert.Equal(t, "1", res.Header().Get("Retry-After"))
+}
+
+func TestClientAddress(t *testing.T) {
+	assert.Equal(t, "192.0.2.1", clientAddress(&http.Request{RemoteAddr: "192.0.2.1:1234"}))
+	assert.Equal(t, "pipe", clientAddress(&http.Request{RemoteAddr: "pipe"}))
+}
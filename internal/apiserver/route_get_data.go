@@ -26,11 +26,13 @@ import (
 )
 
 var getData = &ffapi.Route{
-	Name:            "getData",
-	Path:            "data",
-	Method:          http.MethodGet,
-	PathParams:      nil,
-	QueryParams:     nil,
+	Name:       "getData",
+	Path:       "data",
+	Method:     http.MethodGet,
+	PathParams: nil,
+	QueryParams: []*ffapi.QueryParam{
+		{Name: "jsonpath", IsArray: true, Description: coremsgs.APIJSONPathQueryParam},
+	},
 	FilterFactory:   database.DataQueryFactory,
 	Description:     coremsgs.APIEndpointsGetData,
 	JSONInputValue:  nil,
@@ -38,7 +40,26 @@ var getData = &ffapi.Route{
 	JSONOutputCodes: []int{http.StatusOK},
 	Extensions: &coreExtensions{
 		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			matches, err := getJSONPathMatches(r)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) > 0 {
+				return r.FilterResult(cr.or.GetDataByValue(cr.ctx, matches, r.Filter))
+			}
 			return r.FilterResult(cr.or.GetData(cr.ctx, r.Filter))
 		},
 	},
 }
+
+func getJSONPathMatches(r *ffapi.APIRequest) ([]*core.JSONPathMatch, error) {
+	matches := make([]*core.JSONPathMatch, 0, len(r.QAP["jsonpath"]))
+	for _, s := range r.QAP["jsonpath"] {
+		m, err := core.ParseJSONPathMatch(r.Req.Context(), s)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, nil
+}
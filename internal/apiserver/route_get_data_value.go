@@ -43,6 +43,11 @@ var getDataValue = &ffapi.Route{
 			if err != nil {
 				return nil, err
 			}
+			if d.ValueRef != nil {
+				// Large values held in the blob store are streamed, rather than loaded into memory
+				r.ResponseHeaders.Set("Content-Type", "application/json")
+				return cr.or.Data().DownloadLargeValue(cr.ctx, d)
+			}
 			return d.Value, err
 		},
 	},
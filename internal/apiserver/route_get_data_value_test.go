@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -51,6 +52,31 @@ func TestGetDataValue(t *testing.T) {
 	assert.JSONEq(t, `{"some":"data"}`, string(resData))
 }
 
+func TestGetDataValueLarge(t *testing.T) {
+	o, r := newTestAPIServer()
+	mdm := &datamocks.Manager{}
+	o.On("Data").Return(mdm)
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/data/abcd12345/value", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	d := &core.Data{
+		ValueRef: &core.DataValueRef{Size: 15},
+	}
+	o.On("GetDataByID", mock.Anything, "abcd12345").Return(d, nil)
+	mdm.On("DownloadLargeValue", mock.Anything, d).
+		Return(ioutil.NopCloser(strings.NewReader(`{"some":"data"}`)), nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "application/json", res.Result().Header.Get("Content-Type"))
+
+	resData, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"some":"data"}`, string(resData))
+}
+
 func TestGetDataValueFail(t *testing.T) {
 	o, r := newTestAPIServer()
 	mdm := &datamocks.Manager{}
@@ -33,6 +33,7 @@ var getMsgs = &ffapi.Route{
 	PathParams: nil,
 	QueryParams: []*ffapi.QueryParam{
 		{Name: "fetchdata", IsBool: true, Description: coremsgs.APIFetchDataDesc},
+		{Name: "jsonpath", IsArray: true, Description: coremsgs.APIJSONPathQueryParam},
 	},
 	FilterFactory:   database.MessageQueryFactory,
 	Description:     coremsgs.APIEndpointsGetMsgs,
@@ -41,7 +42,18 @@ var getMsgs = &ffapi.Route{
 	JSONOutputCodes: []int{http.StatusOK},
 	Extensions: &coreExtensions{
 		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
-			if strings.EqualFold(r.QP["fetchdata"], "true") {
+			matches, err := getJSONPathMatches(r)
+			if err != nil {
+				return nil, err
+			}
+			fetchData := strings.EqualFold(r.QP["fetchdata"], "true")
+			if len(matches) > 0 {
+				if fetchData {
+					return r.FilterResult(cr.or.GetMessagesByDataValueWithData(cr.ctx, matches, r.Filter))
+				}
+				return r.FilterResult(cr.or.GetMessagesByDataValue(cr.ctx, matches, r.Filter))
+			}
+			if fetchData {
 				return r.FilterResult(cr.or.GetMessagesWithData(cr.ctx, r.Filter))
 			}
 			return r.FilterResult(cr.or.GetMessages(cr.ctx, r.Filter))
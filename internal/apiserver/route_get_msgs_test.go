@@ -86,3 +86,46 @@ func TestGetMessagesWithCountAndData(t *testing.T) {
 	assert.Equal(t, int64(0), resWithCount.Count)
 	assert.Equal(t, int64(10), *resWithCount.Total)
 }
+
+func TestGetMessagesByDataValue(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?jsonpath=order.id%3D123&jsonpath=status%3Dopen", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetMessagesByDataValue", mock.Anything, []*core.JSONPathMatch{
+		{Path: "order.id", Value: "123"},
+		{Path: "status", Value: "open"},
+	}, mock.Anything).Return([]*core.Message{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetMessagesByDataValueWithData(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?fetchdata&jsonpath=order.id%3D123", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetMessagesByDataValueWithData", mock.Anything, []*core.JSONPathMatch{
+		{Path: "order.id", Value: "123"},
+	}, mock.Anything).Return([]*core.MessageInOut{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetMessagesByDataValueBadMatch(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages?jsonpath=order.id", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+}
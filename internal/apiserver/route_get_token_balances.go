@@ -20,17 +20,22 @@ import (
 	"net/http"
 
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 )
 
 var getTokenBalances = &ffapi.Route{
-	Name:            "getTokenBalances",
-	Path:            "tokens/balances",
-	Method:          http.MethodGet,
-	PathParams:      nil,
-	QueryParams:     nil,
+	Name:       "getTokenBalances",
+	Path:       "tokens/balances",
+	Method:     http.MethodGet,
+	PathParams: nil,
+	QueryParams: []*ffapi.QueryParam{
+		{Name: "asof", Description: coremsgs.APIParamsTokenBalanceAsOf},
+		{Name: "asofprotocolid", Description: coremsgs.APIParamsTokenBalanceAsOfProtocolID},
+	},
 	FilterFactory:   database.TokenBalanceQueryFactory,
 	Description:     coremsgs.APIEndpointsGetTokenBalances,
 	JSONInputValue:  nil,
@@ -38,6 +43,15 @@ var getTokenBalances = &ffapi.Route{
 	JSONOutputCodes: []int{http.StatusOK},
 	Extensions: &coreExtensions{
 		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			asOf := &core.TokenBalancePointInTime{ProtocolID: r.QP["asofprotocolid"]}
+			if r.QP["asof"] != "" {
+				if asOf.Timestamp, err = fftypes.ParseTimeString(r.QP["asof"]); err != nil {
+					return nil, i18n.NewError(cr.ctx, coremsgs.MsgInvalidTimestampParam, "asof")
+				}
+			}
+			if asOf.Timestamp != nil || asOf.ProtocolID != "" {
+				return r.FilterResult(cr.or.Assets().GetTokenBalancesAsOf(cr.ctx, asOf, r.Filter))
+			}
 			return r.FilterResult(cr.or.Assets().GetTokenBalances(cr.ctx, r.Filter))
 		},
 	},
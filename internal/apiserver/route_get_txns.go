@@ -26,11 +26,13 @@ import (
 )
 
 var getTxns = &ffapi.Route{
-	Name:            "getTxns",
-	Path:            "transactions",
-	Method:          http.MethodGet,
-	PathParams:      nil,
-	QueryParams:     nil,
+	Name:       "getTxns",
+	Path:       "transactions",
+	Method:     http.MethodGet,
+	PathParams: nil,
+	QueryParams: []*ffapi.QueryParam{
+		{Name: "blockchainid", Description: coremsgs.APIBlockchainIDQueryParam},
+	},
 	FilterFactory:   database.TransactionQueryFactory,
 	Description:     coremsgs.APIEndpointsGetTxns,
 	JSONInputValue:  nil,
@@ -38,6 +40,9 @@ var getTxns = &ffapi.Route{
 	JSONOutputCodes: []int{http.StatusOK},
 	Extensions: &coreExtensions{
 		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			if blockchainID := r.QP["blockchainid"]; blockchainID != "" {
+				return r.FilterResult(cr.or.GetTransactionsByBlockchainID(cr.ctx, blockchainID, r.Filter))
+			}
 			return r.FilterResult(cr.or.GetTransactions(cr.ctx, r.Filter))
 		},
 	},
@@ -42,6 +42,7 @@ var postContractAPIInvoke = &ffapi.Route{
 	JSONOutputValue: func() interface{} { return &core.Operation{} },
 	JSONOutputCodes: []int{http.StatusOK, http.StatusAccepted},
 	Extensions: &coreExtensions{
+		ChainWrite: true,
 		EnabledIf: func(or orchestrator.Orchestrator) bool {
 			return or.Contracts() != nil
 		},
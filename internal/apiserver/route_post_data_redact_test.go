@@ -0,0 +1,55 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostDataRedact(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		core.GetAuthRequestInfo(args[0].(context.Context)).Principal = "jwt:dpo"
+	}).Return(nil)
+	mdm := &datamocks.Manager{}
+	o.On("Data").Return(mdm)
+	input := core.DataRedactInput{Author: "dpo@example.com", Reason: "erasure request 12345"}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/data/id1/redact", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	// The principal authorized for the request is passed through to record as the redactor
+	mdm.On("RedactData", mock.MatchedBy(func(ctx context.Context) bool {
+		return core.GetAuthRequestInfo(ctx).Principal == "jwt:dpo"
+	}), "id1", &input).
+		Return(&core.Data{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	mdm.AssertExpectations(t)
+}
@@ -0,0 +1,66 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/graphql"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+var postGraphQL = &ffapi.Route{
+	Name:            "postGraphQL",
+	Path:            "graphql",
+	Method:          http.MethodPost,
+	PathParams:      nil,
+	QueryParams:     nil,
+	Description:     coremsgs.APIEndpointsPostGraphQL,
+	JSONInputValue:  func() interface{} { return &core.GraphQLRequest{} },
+	JSONOutputValue: func() interface{} { return &core.GraphQLResponse{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	Extensions: &coreExtensions{
+		ReadOnly: true,
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			return graphql.Execute(cr.ctx, &graphql.Env{
+				Orchestrator: cr.or,
+				Authorize: func(ctx context.Context, path string) error {
+					// Each field is authorized as a GET of the REST API route that returns the same resources
+					return cr.or.Authorize(core.WithAuthRequestInfo(ctx, &core.AuthRequestInfo{TLS: r.Req.TLS}), &fftypes.AuthReq{
+						Method: http.MethodGet,
+						URL:    &url.URL{Path: strings.TrimSuffix(r.Req.URL.Path, "graphql") + path},
+						Header: r.Req.Header,
+					})
+				},
+				DefaultLimit: uint64(config.GetUint(coreconfig.APIDefaultFilterLimit)),
+				MaxLimit:     uint64(config.GetUint(coreconfig.APIMaxFilterLimit)),
+				MaxSkip:      uint64(config.GetUint(coreconfig.APIMaxFilterSkip)),
+				MaxDepth:     uint64(config.GetUint(coreconfig.APIGraphQLMaxDepth)),
+				MaxNodes:     uint64(config.GetUint(coreconfig.APIGraphQLMaxNodes)),
+				MaxTokens:    uint64(config.GetUint(coreconfig.APIGraphQLMaxTokens)),
+			}, r.Input.(*core.GraphQLRequest))
+		},
+	},
+}
@@ -0,0 +1,64 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostGraphQL(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.MatchedBy(func(req *fftypes.AuthReq) bool {
+		return req.Method == http.MethodPost && req.URL.Path == "/api/v1/namespaces/ns1/graphql"
+	})).Return(nil)
+	o.On("Authorize", mock.Anything, mock.MatchedBy(func(req *fftypes.AuthReq) bool {
+		return req.Method == http.MethodGet && req.URL.Path == "/api/v1/namespaces/ns1/status" && req.Header.Get("Authorization") == "Bearer token1"
+	})).Return(nil)
+	o.On("GetStatus", mock.Anything).Return(&core.NamespaceStatus{
+		Node: &core.NamespaceStatusNode{Name: "node1"},
+	}, nil)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/graphql", strings.NewReader(`{"query":"{ status { node { name } } }"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer token1")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.JSONEq(t, `{"data":{"status":{"node":{"name":"node1"}}}}`, res.Body.String())
+}
+
+func TestPostGraphQLInvalid(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/graphql", strings.NewReader(`{"query":"{ nope }"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10542", res.Body.String())
+}
@@ -0,0 +1,79 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/multipartymocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostNewMessageBroadcastBulk(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mmp := &multipartymocks.Manager{}
+	o.On("MultiParty").Return(mmp)
+	mgr := &broadcastmocks.Manager{}
+	o.On("Broadcast").Return(mgr)
+	input := []*core.MessageInOut{{}, {}}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/broadcast/bulk", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mgr.On("BroadcastMessages", mock.Anything, mock.MatchedBy(func(in []*core.MessageInOut) bool {
+		return len(in) == 2
+	})).Return([]*core.MessageBulkResult{
+		{Message: &core.Message{}},
+		{Error: "pop"},
+	}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 202, res.Result().StatusCode)
+	var results []*core.MessageBulkResult
+	json.NewDecoder(res.Body).Decode(&results)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "pop", results[1].Error)
+}
+
+func TestPostNewMessageBroadcastBulkFail(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	mmp := &multipartymocks.Manager{}
+	o.On("MultiParty").Return(mmp)
+	mgr := &broadcastmocks.Manager{}
+	o.On("Broadcast").Return(mgr)
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode([]*core.MessageInOut{})
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/broadcast/bulk", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mgr.On("BroadcastMessages", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
@@ -39,6 +39,7 @@ var postNewMessagePrivate = &ffapi.Route{
 	JSONOutputValue: func() interface{} { return &core.Message{} },
 	JSONOutputCodes: []int{http.StatusAccepted, http.StatusOK},
 	Extensions: &coreExtensions{
+		ChainWrite: true,
 		EnabledIf: func(or orchestrator.Orchestrator) bool {
 			return or.MultiParty() != nil
 		},
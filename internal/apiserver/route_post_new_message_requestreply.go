@@ -36,6 +36,7 @@ var postNewMessageRequestReply = &ffapi.Route{
 	JSONOutputValue: func() interface{} { return &core.MessageInOut{} },
 	JSONOutputCodes: []int{http.StatusOK}, // Sync operation
 	Extensions: &coreExtensions{
+		ChainWrite: true,
 		EnabledIf: func(or orchestrator.Orchestrator) bool {
 			return or.MultiParty() != nil
 		},
@@ -0,0 +1,54 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+var spiGetAuditRecordsExport = &ffapi.Route{
+	Name:   "spiGetAuditRecordsExport",
+	Path:   "namespaces/{ns}/auditrecords/export",
+	Method: http.MethodGet,
+	PathParams: []*ffapi.PathParam{
+		{Name: "ns", Description: coremsgs.APIParamsNamespace},
+	},
+	QueryParams:     nil,
+	Description:     coremsgs.APIEndpointsAdminGetAuditRecordsExport,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []byte{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	Extensions: &coreExtensions{
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			am, err := getAuditManager(cr, r)
+			if err != nil {
+				return nil, err
+			}
+			ctx := r.Req.Context()
+			reader, writer := io.Pipe()
+			go func() {
+				_ = writer.CloseWithError(am.ExportAuditRecords(ctx, writer))
+			}()
+			r.ResponseHeaders.Set("Content-Type", "application/x-ndjson")
+			return reader, nil
+		},
+	},
+}
@@ -0,0 +1,60 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/auditmocks"
+	"github.com/hyperledger/firefly/mocks/spieventsmocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSPIGetAuditRecordsExport(t *testing.T) {
+	o, r := newTestSPIServer()
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/ns1/auditrecords/export", nil)
+	res := httptest.NewRecorder()
+
+	mau := &auditmocks.Manager{}
+	o.On("Audit").Return(mau)
+	mau.On("ExportAuditRecords", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			_, _ = args[1].(io.Writer).Write([]byte(`{"sequence":1}`))
+		}).
+		Return(nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "application/x-ndjson", res.Result().Header.Get("Content-Type"))
+	assert.Equal(t, `{"sequence":1}`, res.Body.String())
+}
+
+func TestSPIGetAuditRecordsExportBadNamespace(t *testing.T) {
+	mgr, _, as := newTestServer()
+	mgr.On("SPIEvents").Return(&spieventsmocks.Manager{})
+	mgr.On("Orchestrator", mock.Anything, "bad", false).Return(nil, fmt.Errorf("pop"))
+	r := as.createAdminMuxRouter(mgr)
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/bad/auditrecords/export", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
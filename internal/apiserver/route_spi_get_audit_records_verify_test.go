@@ -0,0 +1,61 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/auditmocks"
+	"github.com/hyperledger/firefly/mocks/spieventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSPIGetAuditRecordsVerify(t *testing.T) {
+	o, r := newTestSPIServer()
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/ns1/auditrecords/verify", nil)
+	res := httptest.NewRecorder()
+
+	mau := &auditmocks.Manager{}
+	o.On("Audit").Return(mau)
+	mau.On("VerifyAuditRecords", mock.Anything).
+		Return(&core.AuditVerification{Records: 2, Valid: true}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var result core.AuditVerification
+	err := json.NewDecoder(res.Body).Decode(&result)
+	assert.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(2), result.Records)
+}
+
+func TestSPIGetAuditRecordsVerifyBadNamespace(t *testing.T) {
+	mgr, _, as := newTestServer()
+	mgr.On("SPIEvents").Return(&spieventsmocks.Manager{})
+	mgr.On("Orchestrator", mock.Anything, "bad", false).Return(nil, fmt.Errorf("pop"))
+	r := as.createAdminMuxRouter(mgr)
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/bad/auditrecords/verify", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
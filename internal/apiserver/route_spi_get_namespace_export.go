@@ -0,0 +1,61 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+var spiGetNamespaceExport = &ffapi.Route{
+	Name:   "spiGetNamespaceExport",
+	Path:   "namespaces/{ns}/export",
+	Method: http.MethodGet,
+	PathParams: []*ffapi.PathParam{
+		{Name: "ns", Description: coremsgs.APIParamsNamespace},
+	},
+	QueryParams:     nil,
+	Description:     coremsgs.APIEndpointsAdminGetNamespaceExport,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []byte{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	Extensions: &coreExtensions{
+		CoreJSONHandler: func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error) {
+			or, err := getOrchestrator(cr.ctx, cr.mgr, routeTagNonDefaultNamespace, r)
+			if err != nil {
+				return nil, err
+			}
+			// The export is read from the primary database (not a read replica) so that it is complete
+			ctx := r.Req.Context()
+			reader, writer := io.Pipe()
+			go func() {
+				_ = writer.CloseWithError(or.ExportNamespace(ctx, writer))
+			}()
+			go func() {
+				// The request context is cancelled when the client disconnects, as well as once the response
+				// is complete - closing the reader here ensures the export does not block writing to the pipe
+				<-ctx.Done()
+				_ = reader.CloseWithError(ctx.Err())
+			}()
+			r.ResponseHeaders.Set("Content-Type", "application/x-ndjson")
+			return reader, nil
+		},
+	},
+}
@@ -0,0 +1,66 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSPIGetNamespaceExport(t *testing.T) {
+	o, r := newTestSPIServer()
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/ns1/export", nil)
+	res := httptest.NewRecorder()
+
+	o.On("ExportNamespace", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			_, _ = args[1].(io.Writer).Write([]byte(`{"header":{}}`))
+		}).
+		Return(nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "application/x-ndjson", res.Result().Header.Get("Content-Type"))
+	assert.Equal(t, `{"header":{}}`, res.Body.String())
+}
+
+func TestSPIGetNamespaceExportClientDisconnect(t *testing.T) {
+	o, r := newTestSPIServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("GET", "/spi/v1/namespaces/ns1/export", nil).WithContext(ctx)
+	res := httptest.NewRecorder()
+
+	exportErr := make(chan error, 1)
+	o.On("ExportNamespace", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			cancel()
+			var err error
+			for err == nil {
+				_, err = args[1].(io.Writer).Write([]byte(`{"collection":"events"}`))
+			}
+			exportErr <- err
+		}).
+		Return(nil)
+	r.ServeHTTP(res, req)
+
+	assert.Regexp(t, "canceled", <-exportErr)
+}
@@ -37,6 +37,8 @@ type coreExtensions struct {
 	EnabledIf             func(or orchestrator.Orchestrator) bool
 	CoreJSONHandler       func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error)
 	CoreFormUploadHandler func(r *ffapi.APIRequest, cr *coreRequest) (output interface{}, err error)
+	ChainWrite            bool // the route always submits a blockchain transaction, so counts against the daily quotas
+	ReadOnly              bool // the route is posted to, but does not change any state, so is served like a GET
 }
 
 const (
@@ -63,6 +65,7 @@ var routes = append(
 		getBatches,
 		getBlockchainEventByID,
 		getBlockchainEvents,
+		getBlockchainTxnByID,
 		getChartHistogram,
 		getContractAPIByName,
 		getContractAPIInterface,
@@ -80,6 +83,7 @@ var routes = append(
 		getDataByID,
 		getDataMsgs,
 		getDatatypeByName,
+		getDatatypeDiff,
 		getDatatypes,
 		getEventByID,
 		getEvents,
@@ -94,6 +98,7 @@ var routes = append(
 		getMsgData,
 		getMsgEvents,
 		getMsgs,
+		getMsgThread,
 		getMsgTxn,
 		getNetworkDIDDocByDID,
 		getNetworkIdentities,
@@ -115,6 +120,7 @@ var routes = append(
 		getTokenAccountPools,
 		getTokenAccounts,
 		getTokenApprovals,
+		getTokenBalanceHistory,
 		getTokenBalances,
 		getTokenConnectors,
 		getTokenPoolByNameOrID,
@@ -142,15 +148,21 @@ var routes = append(
 		postContractQuery,
 		postData,
 		postDataBlobPublish,
+		postDataRedact,
 		postDataValuePublish,
+		postDatatypeValidate,
+		postGraphQL,
 		postNetworkAction,
 		postNewContractAPI,
 		postNewContractInterface,
 		postNewContractListener,
 		postNewDatatype,
 		postNewIdentity,
+		postMsgCancel,
 		postNewMessageBroadcast,
+		postNewMessageBroadcastBulk,
 		postNewMessagePrivate,
+		postNewMessagePrivateBulk,
 		postNewMessageRequestReply,
 		postNewSubscription,
 		postNewOrganization,
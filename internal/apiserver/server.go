@@ -18,8 +18,12 @@ package apiserver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,6 +35,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/httpserver"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/auth/rbac"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/events/eifactory"
@@ -38,6 +43,8 @@ import (
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/namespace"
 	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -62,14 +69,18 @@ type apiServer struct {
 	apiPublicURL           string
 	dynamicPublicURLHeader string
 	defaultNamespace       string
+	rateLimiter            *rateLimiter
+	auditEnabled           bool
 }
 
 func InitConfig() {
 	httpserver.InitHTTPConfig(apiConfig, 5000)
 	httpserver.InitHTTPConfig(spiConfig, 5001)
 	httpserver.InitHTTPConfig(metricsConfig, 6000)
+	initGRPCConfig(grpcConfig)
 	httpserver.InitCORSConfig(corsConfig)
 	initMetricsConfig(metricsConfig)
+	initRateLimitConfig(rateLimitGroupsConfig)
 }
 
 func NewAPIServer() Server {
@@ -79,6 +90,7 @@ func NewAPIServer() Server {
 		dynamicPublicURLHeader: config.GetString(coreconfig.APIDynamicPublicURLHeader),
 		defaultNamespace:       config.GetString(coreconfig.NamespacesDefault),
 		metricsEnabled:         config.GetBool(coreconfig.MetricsEnabled),
+		auditEnabled:           config.GetBool(coreconfig.APIAuditEnabled),
 		ffiSwaggerGen:          &ffiSwaggerGen{},
 	}
 	as.apiPublicURL = as.getPublicURL(apiConfig, "")
@@ -90,6 +102,11 @@ func (as *apiServer) Serve(ctx context.Context, mgr namespace.Manager) (err erro
 	httpErrChan := make(chan error)
 	spiErrChan := make(chan error)
 	metricsErrChan := make(chan error)
+	grpcErrChan := make(chan error)
+
+	if as.rateLimiter, err = newRateLimiter(ctx, as.metricsEnabled); err != nil {
+		return err
+	}
 
 	apiHTTPServer, err := httpserver.NewHTTPServer(ctx, "api", as.createMuxRouter(ctx, mgr), httpErrChan, apiConfig, corsConfig, &httpserver.ServerOptions{
 		MaximumRequestTimeout: as.apiMaxTimeout,
@@ -121,10 +138,22 @@ func (as *apiServer) Serve(ctx context.Context, mgr namespace.Manager) (err erro
 		go metricsHTTPServer.ServeHTTP(ctx)
 	}
 
-	return as.waitForServerStop(httpErrChan, spiErrChan, metricsErrChan)
+	if config.GetBool(coreconfig.GRPCEnabled) {
+		grpcServer, err := as.newGRPCServer(ctx, mgr)
+		if err != nil {
+			return err
+		}
+		grpcListener, err := createGRPCListener(ctx)
+		if err != nil {
+			return err
+		}
+		go serveGRPC(ctx, grpcServer, grpcListener, grpcErrChan)
+	}
+
+	return as.waitForServerStop(httpErrChan, spiErrChan, metricsErrChan, grpcErrChan)
 }
 
-func (as *apiServer) waitForServerStop(httpErrChan, spiErrChan, metricsErrChan chan error) error {
+func (as *apiServer) waitForServerStop(httpErrChan, spiErrChan, metricsErrChan, grpcErrChan chan error) error {
 	select {
 	case err := <-httpErrChan:
 		return err
@@ -132,6 +161,8 @@ func (as *apiServer) waitForServerStop(httpErrChan, spiErrChan, metricsErrChan c
 		return err
 	case err := <-metricsErrChan:
 		return err
+	case err := <-grpcErrChan:
+		return err
 	}
 }
 
@@ -198,6 +229,91 @@ func (as *apiServer) getBaseURL(req *http.Request) string {
 	return baseURL
 }
 
+// authorizeRequest passes the request to the auth plugin of the namespace, if any, along with the
+// TLS state of the connection and the parsed input for plugins that make decisions based on them.
+// Requests that are not in a namespace go to the global auth plugin of the admin API.
+// The returned info has the principal, for plugins that identify one.
+func authorizeRequest(r *ffapi.APIRequest, mgr namespace.Manager, or orchestrator.Orchestrator) (*core.AuthRequestInfo, error) {
+	info := &core.AuthRequestInfo{
+		TLS:   r.Req.TLS,
+		Input: r.Input,
+	}
+	ctx := core.WithAuthRequestInfo(r.Req.Context(), info)
+	authReq := &fftypes.AuthReq{
+		Method: r.Req.Method,
+		URL:    r.Req.URL,
+		Header: r.Req.Header,
+	}
+	if or == nil {
+		return info, mgr.AuthorizeGlobal(ctx, authReq)
+	}
+	return info, or.Authorize(ctx, authReq)
+}
+
+// limitRequest applies the configured rate limits and quotas to an authorized request to a namespace.
+// Requests without a principal from the auth plugin are limited by the address of the client.
+// The returned function must be called with the result of the handler, so that only the writes
+// that were accepted count against the chain-writing quotas.
+func (as *apiServer) limitRequest(r *ffapi.APIRequest, or orchestrator.Orchestrator, info *core.AuthRequestInfo, chainWrite bool) (settle func(output interface{}, err error), err error) {
+	settle = func(interface{}, error) {}
+	if as.rateLimiter == nil || or == nil {
+		return settle, nil
+	}
+	ns := mux.Vars(r.Req)["ns"]
+	if ns == "" {
+		ns = as.defaultNamespace
+	}
+	principal := info.Principal
+	if principal == "" {
+		principal = "addr:" + clientAddress(r.Req)
+	}
+	res, retryAfter, err := as.rateLimiter.limitRequest(r.Req.Context(), ns, principal, rbac.RouteGroup(r.Req.URL.Path), chainWrite, requestCount(r.Input))
+	if err != nil {
+		r.ResponseHeaders.Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+		return settle, err
+	}
+	if res != nil {
+		settle = func(output interface{}, err error) {
+			as.rateLimiter.settleQuota(res, acceptedCount(output, err, res.count))
+		}
+	}
+	return settle, nil
+}
+
+// requestCount is the number of requests a request counts as for rate limits and quotas,
+// which is one for each message in a bulk request
+func requestCount(input interface{}) int {
+	if msgs, ok := input.(*[]*core.MessageInOut); ok && len(*msgs) > 1 {
+		return len(*msgs)
+	}
+	return 1
+}
+
+// acceptedCount is the number of writes that were accepted from a request that counted as count, which
+// is none for a failed request, and the messages without an error for a bulk request
+func acceptedCount(output interface{}, err error, count int64) int64 {
+	if err != nil {
+		return 0
+	}
+	if results, ok := output.([]*core.MessageBulkResult); ok {
+		accepted := int64(0)
+		for _, result := range results {
+			if result.Error == "" {
+				accepted++
+			}
+		}
+		return accepted
+	}
+	return count
+}
+
+func clientAddress(req *http.Request) string {
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		return host
+	}
+	return req.RemoteAddr
+}
+
 func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manager, fixedBaseURL string, route *ffapi.Route) http.HandlerFunc {
 	// We extend the base ffapi functionality, with standardized DB filter support for all core resources.
 	// We also pass the Orchestrator context through
@@ -208,33 +324,18 @@ func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manage
 			return nil, err
 		}
 
-		// Authorize the request
-		authReq := &fftypes.AuthReq{
-			Method: r.Req.Method,
-			URL:    r.Req.URL,
-			Header: r.Req.Header,
-		}
-		if or != nil {
-			if err := or.Authorize(r.Req.Context(), authReq); err != nil {
-				return nil, err
-			}
-		}
-
-		if ce.EnabledIf != nil && !ce.EnabledIf(or) {
-			return nil, i18n.NewError(r.Req.Context(), coremsgs.MsgActionNotSupported)
-		}
-
-		apiBaseURL := fixedBaseURL // for SPI
-		if apiBaseURL == "" {
-			apiBaseURL = as.getBaseURL(r.Req)
+		var info *core.AuthRequestInfo
+		defer func() { as.auditRequest(r, mgr, or, route, info, output, err) }()
+		info, err = authorizeRequest(r, mgr, or)
+		if err != nil {
+			return nil, err
 		}
-		cr := &coreRequest{
-			mgr:        mgr,
-			or:         or,
-			ctx:        r.Req.Context(),
-			apiBaseURL: apiBaseURL,
+		var settle func(output interface{}, err error)
+		if settle, err = as.limitRequest(r, or, info, ce.ChainWrite); err != nil {
+			return nil, err
 		}
-		return ce.CoreJSONHandler(r, cr)
+		defer func() { settle(output, err) }()
+		return as.handleCoreRequest(mgr, or, fixedBaseURL, route, r, info)
 	}
 	if ce.CoreFormUploadHandler != nil {
 		route.FormUploadHandler = func(r *ffapi.APIRequest) (output interface{}, err error) {
@@ -242,6 +343,17 @@ func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manage
 			if err != nil {
 				return nil, err
 			}
+			var info *core.AuthRequestInfo
+			defer func() { as.auditRequest(r, mgr, or, route, info, output, err) }()
+			info, err = authorizeRequest(r, mgr, or)
+			if err != nil {
+				return nil, err
+			}
+			var settle func(output interface{}, err error)
+			if settle, err = as.limitRequest(r, or, info, ce.ChainWrite); err != nil {
+				return nil, err
+			}
+			defer func() { settle(output, err) }()
 			if ce.EnabledIf != nil && !ce.EnabledIf(or) {
 				return nil, i18n.NewError(r.Req.Context(), coremsgs.MsgActionNotSupported)
 			}
@@ -250,10 +362,14 @@ func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manage
 			if apiBaseURL == "" {
 				apiBaseURL = as.getBaseURL(r.Req)
 			}
+			ctx := r.Req.Context()
+			if info != nil {
+				ctx = core.WithAuthRequestInfo(ctx, info)
+			}
 			cr := &coreRequest{
 				mgr:        mgr,
 				or:         or,
-				ctx:        r.Req.Context(),
+				ctx:        ctx,
 				apiBaseURL: apiBaseURL,
 			}
 			return ce.CoreFormUploadHandler(r, cr)
@@ -262,6 +378,36 @@ func (as *apiServer) routeHandler(hf *ffapi.HandlerFactory, mgr namespace.Manage
 	return hf.RouteHandler(route)
 }
 
+// handleCoreRequest passes an authorized request to the core handler of the route.
+// This is shared by the REST API, and the gRPC API that mirrors some of its routes.
+func (as *apiServer) handleCoreRequest(mgr namespace.Manager, or orchestrator.Orchestrator, fixedBaseURL string, route *ffapi.Route, r *ffapi.APIRequest, info *core.AuthRequestInfo) (output interface{}, err error) {
+	ce := route.Extensions.(*coreExtensions)
+	if ce.EnabledIf != nil && !ce.EnabledIf(or) {
+		return nil, i18n.NewError(r.Req.Context(), coremsgs.MsgActionNotSupported)
+	}
+
+	apiBaseURL := fixedBaseURL // for SPI
+	if apiBaseURL == "" {
+		apiBaseURL = as.getBaseURL(r.Req)
+	}
+	ctx := r.Req.Context()
+	if info != nil {
+		// Make the authenticated principal available to the managers, such as to record who made a change
+		ctx = core.WithAuthRequestInfo(ctx, info)
+	}
+	if route.Method == http.MethodGet || ce.ReadOnly {
+		// Queries can be served from a read replica of the database (if configured)
+		ctx = database.WithReadReplica(ctx)
+	}
+	cr := &coreRequest{
+		mgr:        mgr,
+		or:         or,
+		ctx:        ctx,
+		apiBaseURL: apiBaseURL,
+	}
+	return ce.CoreJSONHandler(r, cr)
+}
+
 func (as *apiServer) handlerFactory() *ffapi.HandlerFactory {
 	return &ffapi.HandlerFactory{
 		DefaultFilterLimit:    uint64(config.GetUint(coreconfig.APIDefaultFilterLimit)),
@@ -427,6 +573,17 @@ func (as *apiServer) notFoundHandler(res http.ResponseWriter, req *http.Request)
 func (as *apiServer) spiWSHandler(mgr namespace.Manager) http.HandlerFunc {
 	// The SPI events listener will be initialized when we start, so we access it it from Orchestrator on demand
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := core.WithAuthRequestInfo(r.Context(), &core.AuthRequestInfo{TLS: r.TLS})
+		if err := mgr.AuthorizeGlobal(ctx, &fftypes.AuthReq{Method: r.Method, URL: r.URL, Header: r.Header}); err != nil {
+			status := http.StatusForbidden
+			if ffe, ok := err.(i18n.FFError); ok {
+				status = ffe.HTTPStatus()
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(&fftypes.RESTError{Error: err.Error()})
+			return
+		}
 		mgr.SPIEvents().ServeHTTPWebSocketListener(w, r)
 	}
 }
@@ -60,6 +60,7 @@ func newTestServer() (*namespacemocks.Manager, *orchestratormocks.Orchestrator,
 	mgr.On("Orchestrator", mock.Anything, "default", false).Return(o, nil).Maybe()
 	mgr.On("Orchestrator", mock.Anything, "mynamespace", false).Return(o, nil).Maybe()
 	mgr.On("Orchestrator", mock.Anything, "ns1", false).Return(o, nil).Maybe()
+	mgr.On("AuthorizeGlobal", mock.Anything, mock.Anything).Return(nil).Maybe()
 	config.Set(coreconfig.APIMaxFilterLimit, 100)
 	as := NewAPIServer().(*apiServer)
 	return mgr, o, as
@@ -87,8 +88,10 @@ func TestStartStopServer(t *testing.T) {
 	apiConfig.Set(httpserver.HTTPConfPort, 0)
 	spiConfig.Set(httpserver.HTTPConfPort, 0)
 	metricsConfig.Set(httpserver.HTTPConfPort, 0)
+	grpcConfig.Set(httpserver.HTTPConfPort, 0)
 	config.Set(coreconfig.UIPath, "test")
 	config.Set(coreconfig.SPIEnabled, true)
+	config.Set(coreconfig.GRPCEnabled, true)
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // server will immediately shut down
 	as := NewAPIServer()
@@ -156,6 +159,7 @@ func TestStartAdminWSHandler(t *testing.T) {
 	as := NewAPIServer().(*apiServer)
 	mgr := &namespacemocks.Manager{}
 	mae := &spieventsmocks.Manager{}
+	mgr.On("AuthorizeGlobal", mock.Anything, mock.Anything).Return(nil)
 	mgr.On("SPIEvents").Return(mae)
 	mae.On("ServeHTTPWebSocketListener", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
 		res := args[0].(http.ResponseWriter)
@@ -166,6 +170,45 @@ func TestStartAdminWSHandler(t *testing.T) {
 	assert.Equal(t, 200, res.Result().StatusCode)
 }
 
+func TestAdminWSHandlerUnauthorized(t *testing.T) {
+	coreconfig.Reset()
+	InitConfig()
+	as := NewAPIServer().(*apiServer)
+	mgr := &namespacemocks.Manager{}
+	mgr.On("AuthorizeGlobal", mock.Anything, mock.Anything).Return(i18n.NewError(context.Background(), i18n.MsgUnauthorized))
+	res := httptest.NewRecorder()
+	as.spiWSHandler(mgr).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 401, res.Result().StatusCode)
+	assert.Regexp(t, "FF00169", res.Body.String())
+	mgr.AssertExpectations(t)
+}
+
+func TestAdminWSHandlerAuthFail(t *testing.T) {
+	coreconfig.Reset()
+	InitConfig()
+	as := NewAPIServer().(*apiServer)
+	mgr := &namespacemocks.Manager{}
+	mgr.On("AuthorizeGlobal", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	res := httptest.NewRecorder()
+	as.spiWSHandler(mgr).ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 403, res.Result().StatusCode)
+	mgr.AssertExpectations(t)
+}
+
+func TestGlobalRouteAuthorized(t *testing.T) {
+	mgr, _, as := newTestServer()
+	mgr.ExpectedCalls = nil
+	mgr.On("AuthorizeGlobal", mock.Anything, mock.MatchedBy(func(authReq *fftypes.AuthReq) bool {
+		return authReq.Namespace == "" && authReq.URL.Path == "/spi/v1/namespaces"
+	})).Return(i18n.NewError(context.Background(), i18n.MsgForbidden))
+	r := as.createAdminMuxRouter(mgr)
+	req := httptest.NewRequest(http.MethodGet, "/spi/v1/namespaces", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 403, res.Code)
+	mgr.AssertExpectations(t)
+}
+
 func TestStartMetricsFail(t *testing.T) {
 	coreconfig.Reset()
 	metrics.Clear()
@@ -182,6 +225,38 @@ func TestStartMetricsFail(t *testing.T) {
 	assert.Regexp(t, "FF00151", err)
 }
 
+func TestStartGRPCFail(t *testing.T) {
+	coreconfig.Reset()
+	metrics.Clear()
+	InitConfig()
+	apiConfig.Set(httpserver.HTTPConfPort, 0)
+	metricsConfig.Set(httpserver.HTTPConfPort, 0)
+	grpcConfig.Set(httpserver.HTTPConfAddress, "...://")
+	config.Set(coreconfig.GRPCEnabled, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // server will immediately shut down
+	as := NewAPIServer()
+	mgr := &namespacemocks.Manager{}
+	err := as.Serve(ctx, mgr)
+	assert.Regexp(t, "FF00151", err)
+}
+
+func TestStartGRPCBadAuthPlugin(t *testing.T) {
+	coreconfig.Reset()
+	metrics.Clear()
+	InitConfig()
+	apiConfig.Set(httpserver.HTTPConfPort, 0)
+	metricsConfig.Set(httpserver.HTTPConfPort, 0)
+	grpcConfig.Set(httpserver.HTTPAuthType, "wrong")
+	config.Set(coreconfig.GRPCEnabled, true)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // server will immediately shut down
+	as := NewAPIServer()
+	mgr := &namespacemocks.Manager{}
+	err := as.Serve(ctx, mgr)
+	assert.Regexp(t, "FF00168", err)
+}
+
 func TestNotFound(t *testing.T) {
 	_, _, as := newTestServer()
 	handler := as.handlerFactory().APIWrapper(as.notFoundHandler)
@@ -304,19 +379,24 @@ func TestWaitForServerStop(t *testing.T) {
 	chl1 := make(chan error, 1)
 	chl2 := make(chan error, 1)
 	chl3 := make(chan error, 1)
+	chl4 := make(chan error, 1)
 	chl1 <- fmt.Errorf("pop1")
 
 	as := &apiServer{}
-	err := as.waitForServerStop(chl1, chl2, chl3)
+	err := as.waitForServerStop(chl1, chl2, chl3, chl4)
 	assert.EqualError(t, err, "pop1")
 
 	chl2 <- fmt.Errorf("pop2")
-	err = as.waitForServerStop(chl1, chl2, chl3)
+	err = as.waitForServerStop(chl1, chl2, chl3, chl4)
 	assert.EqualError(t, err, "pop2")
 
 	chl3 <- fmt.Errorf("pop3")
-	err = as.waitForServerStop(chl1, chl2, chl3)
+	err = as.waitForServerStop(chl1, chl2, chl3, chl4)
 	assert.EqualError(t, err, "pop3")
+
+	chl4 <- fmt.Errorf("pop4")
+	err = as.waitForServerStop(chl1, chl2, chl3, chl4)
+	assert.EqualError(t, err, "pop4")
 }
 
 func TestContractAPISwaggerJSON(t *testing.T) {
@@ -511,6 +591,45 @@ func TestFormDataDisabledRoute(t *testing.T) {
 	assert.Equal(t, 400, res.Result().StatusCode)
 }
 
+func TestFormDataUnauthorized(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.Anything, mock.Anything).Return(i18n.NewError(context.Background(), i18n.MsgUnauthorized))
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	writer, err := w.CreateFormFile("file", "filename.ext")
+	assert.NoError(t, err)
+	writer.Write([]byte(`some data`))
+	w.Close()
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/data", &b)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 401, res.Result().StatusCode)
+}
+
+func TestAuthorizeRequestInfo(t *testing.T) {
+	mgr, o, as := newTestServer()
+	o.On("Authorize", mock.MatchedBy(func(ctx context.Context) bool {
+		_, ok := core.GetAuthRequestInfo(ctx).Input.(*core.MessageInOut)
+		return ok
+	}), mock.MatchedBy(func(authReq *fftypes.AuthReq) bool {
+		return authReq.Method == http.MethodPost && authReq.URL.Path == "/api/v1/namespaces/ns1/messages/broadcast"
+	})).Return(i18n.NewError(context.Background(), i18n.MsgForbidden))
+	r := as.createMuxRouter(context.Background(), mgr)
+
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/broadcast", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+}
+
 func TestGetOrchestratorMissingTag(t *testing.T) {
 	_, err := getOrchestrator(context.Background(), &namespacemocks.Manager{}, "", nil)
 	assert.Regexp(t, "FF10437", err)
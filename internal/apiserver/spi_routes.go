@@ -25,11 +25,22 @@ import (
 // The Service Provider Interface (SPI) allows external microservices (such as the FireFly Transaction Manager)
 // to act as augmented components to the core.
 var spiRoutes = append(globalRoutes([]*ffapi.Route{
+	spiDeleteNamespace,
+	spiGetAuditRecords,
+	spiGetAuditRecordsExport,
+	spiGetAuditRecordsVerify,
 	spiGetNamespaceByName,
+	spiGetNamespaceExport,
+	spiGetLivez,
 	spiGetNamespaces,
 	spiGetOpByID,
+	spiGetReadyz,
 	spiPatchOpByID,
+	spiPostNamespace,
+	spiPostNamespaceImport,
+	spiPostNamespaceStop,
 	spiPostReset,
+	spiPutNamespace,
 }),
 	namespacedSPIRoutes([]*ffapi.Route{
 		spiGetOps,
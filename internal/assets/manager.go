@@ -51,6 +51,8 @@ type Manager interface {
 	DeleteTokenPool(ctx context.Context, poolNameOrID string) error
 
 	GetTokenBalances(ctx context.Context, filter ffapi.AndFilter) ([]*core.TokenBalance, *ffapi.FilterResult, error)
+	GetTokenBalancesAsOf(ctx context.Context, asOf *core.TokenBalancePointInTime, filter ffapi.AndFilter) ([]*core.TokenBalance, *ffapi.FilterResult, error)
+	GetTokenBalanceHistory(ctx context.Context, filter ffapi.AndFilter) ([]*core.TokenBalanceChange, *ffapi.FilterResult, error)
 	GetTokenAccounts(ctx context.Context, filter ffapi.AndFilter) ([]*core.TokenAccount, *ffapi.FilterResult, error)
 	GetTokenAccountPools(ctx context.Context, key string, filter ffapi.AndFilter) ([]*core.TokenAccountPool, *ffapi.FilterResult, error)
 
@@ -152,6 +154,14 @@ func (am *assetManager) GetTokenBalances(ctx context.Context, filter ffapi.AndFi
 	return am.database.GetTokenBalances(ctx, am.namespace, filter)
 }
 
+func (am *assetManager) GetTokenBalancesAsOf(ctx context.Context, asOf *core.TokenBalancePointInTime, filter ffapi.AndFilter) ([]*core.TokenBalance, *ffapi.FilterResult, error) {
+	return am.database.GetTokenBalancesAsOf(ctx, am.namespace, asOf, filter)
+}
+
+func (am *assetManager) GetTokenBalanceHistory(ctx context.Context, filter ffapi.AndFilter) ([]*core.TokenBalanceChange, *ffapi.FilterResult, error) {
+	return am.database.GetTokenBalanceHistory(ctx, am.namespace, filter)
+}
+
 func (am *assetManager) GetTokenAccounts(ctx context.Context, filter ffapi.AndFilter) ([]*core.TokenAccount, *ffapi.FilterResult, error) {
 	return am.database.GetTokenAccounts(ctx, am.namespace, filter)
 }
@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/txcommon"
@@ -129,6 +130,31 @@ func TestGetTokenBalances(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetTokenBalancesAsOf(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	fb := database.TokenBalanceQueryFactory.NewFilter(context.Background())
+	f := fb.And()
+	asOf := &core.TokenBalancePointInTime{Timestamp: fftypes.Now()}
+	mdi.On("GetTokenBalancesAsOf", context.Background(), "ns1", asOf, f).Return([]*core.TokenBalance{}, nil, nil)
+	_, _, err := am.GetTokenBalancesAsOf(context.Background(), asOf, f)
+	assert.NoError(t, err)
+}
+
+func TestGetTokenBalanceHistory(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	fb := database.TokenBalanceChangeQueryFactory.NewFilter(context.Background())
+	f := fb.And()
+	mdi.On("GetTokenBalanceHistory", context.Background(), "ns1", f).Return([]*core.TokenBalanceChange{}, nil, nil)
+	_, _, err := am.GetTokenBalanceHistory(context.Background(), f)
+	assert.NoError(t, err)
+}
+
 func TestGetTokenAccounts(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
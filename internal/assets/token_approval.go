@@ -234,6 +234,10 @@ func (am *assetManager) validateApproval(ctx context.Context, approval *core.Tok
 }
 
 func (s *approveSender) buildApprovalMessage(ctx context.Context, in *core.MessageInOut) (syncasync.Sender, error) {
+	if in.Scheduled != nil {
+		// The message is released by the token event, so cannot also wait for a scheduled time
+		return nil, i18n.NewError(ctx, coremsgs.MsgScheduledMessageNotSupported)
+	}
 	allowedTypes := []fftypes.FFEnum{
 		core.MessageTypeBroadcast,
 		core.MessageTypePrivate,
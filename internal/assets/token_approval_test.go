@@ -900,6 +900,36 @@ func TestApprovalWithInvalidMessage(t *testing.T) {
 	mth.AssertExpectations(t)
 }
 
+func TestApprovalWithScheduledMessage(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	approval := &core.TokenApprovalInput{
+		TokenApproval: core.TokenApproval{
+			Operator: "B",
+			Approved: true,
+		},
+		Pool:           "pool1",
+		IdempotencyKey: "idem1",
+		Message: &core.MessageInOut{
+			Message: core.Message{
+				Header: core.MessageHeader{
+					Type: core.MessageTypeBroadcast,
+				},
+				Scheduled: fftypes.Now(),
+			},
+		},
+	}
+
+	mth := am.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", context.Background(), core.TransactionTypeTokenApproval, core.IdempotencyKey("idem1")).Return(fftypes.NewUUID(), nil)
+
+	_, err := am.TokenApproval(context.Background(), approval, false)
+	assert.Regexp(t, "FF10502", err)
+
+	mth.AssertExpectations(t)
+}
+
 func TestApprovalOperationsFail(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
@@ -308,6 +308,10 @@ func (s *transferSender) sendInternal(ctx context.Context, method sendMethod) (e
 }
 
 func (s *transferSender) buildTransferMessage(ctx context.Context, in *core.MessageInOut) (syncasync.Sender, error) {
+	if in.Scheduled != nil {
+		// The message is released by the token event, so cannot also wait for a scheduled time
+		return nil, i18n.NewError(ctx, coremsgs.MsgScheduledMessageNotSupported)
+	}
 	allowedTypes := []fftypes.FFEnum{
 		core.MessageTypeBroadcast,
 		core.MessageTypePrivate,
@@ -1198,6 +1198,37 @@ func TestTransferTokensWithInvalidMessage(t *testing.T) {
 	mth.AssertExpectations(t)
 }
 
+func TestTransferTokensWithScheduledMessage(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	transfer := &core.TokenTransferInput{
+		TokenTransfer: core.TokenTransfer{
+			From:   "A",
+			To:     "B",
+			Amount: *fftypes.NewFFBigInt(5),
+		},
+		Pool:           "pool1",
+		IdempotencyKey: "idem1",
+		Message: &core.MessageInOut{
+			Message: core.Message{
+				Header: core.MessageHeader{
+					Type: core.MessageTypeBroadcast,
+				},
+				Scheduled: fftypes.Now(),
+			},
+		},
+	}
+
+	mth := am.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", context.Background(), core.TransactionTypeTokenTransfer, core.IdempotencyKey("idem1")).Return(fftypes.NewUUID(), nil)
+
+	_, err := am.TransferTokens(context.Background(), transfer, false)
+	assert.Regexp(t, "FF10502", err)
+
+	mth.AssertExpectations(t)
+}
+
 func TestTransferTokensConfirm(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
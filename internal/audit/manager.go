@@ -0,0 +1,161 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+const pageSize = 100
+
+// Manager maintains the append-only audit log of the mutating API requests made against a namespace.
+// Each record is chained to the one before it by hash, so the log can be verified as a whole.
+type Manager interface {
+	RecordRequest(ctx context.Context, record *core.AuditRecord) error
+	GetAuditRecords(ctx context.Context, filter ffapi.AndFilter) ([]*core.AuditRecord, *ffapi.FilterResult, error)
+	ExportAuditRecords(ctx context.Context, w io.Writer) error
+	VerifyAuditRecords(ctx context.Context) (*core.AuditVerification, error)
+}
+
+type auditManager struct {
+	namespace string
+	database  database.Plugin
+	mux       sync.Mutex
+}
+
+func NewAuditManager(ctx context.Context, ns string, di database.Plugin) (Manager, error) {
+	if di == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "AuditManager")
+	}
+	return &auditManager{
+		namespace: ns,
+		database:  di,
+	}, nil
+}
+
+// RecordRequest appends a record to the chain. Records are serialized, so that each one links
+// to the hash of the record that was stored immediately before it.
+//
+// This has a throughput cost: every mutating request to the namespace waits for the mutex, and then
+// for a database transaction that reads the head of the chain and inserts the new record. Requests
+// are processed in parallel, but their audit writes are not, so the time of that transaction bounds
+// the rate of mutating requests the namespace can accept while auditing is enabled.
+func (am *auditManager) RecordRequest(ctx context.Context, record *core.AuditRecord) error {
+	am.mux.Lock()
+	defer am.mux.Unlock()
+
+	record.ID = fftypes.NewUUID()
+	record.Namespace = am.namespace
+	if record.Created == nil {
+		record.Created = fftypes.Now()
+	}
+	return am.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		last, err := am.database.GetLastAuditRecord(ctx, am.namespace)
+		if err != nil {
+			return err
+		}
+		var previousHash *fftypes.Bytes32
+		if last != nil {
+			previousHash = last.Hash
+		}
+		record.Seal(previousHash)
+		return am.database.InsertAuditRecord(ctx, record)
+	})
+}
+
+func (am *auditManager) GetAuditRecords(ctx context.Context, filter ffapi.AndFilter) ([]*core.AuditRecord, *ffapi.FilterResult, error) {
+	return am.database.GetAuditRecords(ctx, am.namespace, filter)
+}
+
+// page iterates through every audit record in the namespace, in chain order
+func (am *auditManager) page(ctx context.Context, fn func(record *core.AuditRecord) error) error {
+	var after int64
+	for {
+		fb := database.AuditRecordQueryFactory.NewFilter(ctx)
+		records, _, err := am.database.GetAuditRecords(ctx, am.namespace,
+			fb.And(fb.Gt("sequence", after)).Sort("sequence").Limit(pageSize))
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return err
+			}
+			after = record.Sequence
+		}
+		if len(records) < pageSize {
+			return nil
+		}
+	}
+}
+
+// ExportAuditRecords writes every audit record in the namespace as newline delimited JSON, in chain order
+func (am *auditManager) ExportAuditRecords(ctx context.Context, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	count := 0
+	err := am.page(ctx, func(record *core.AuditRecord) error {
+		count++
+		return encoder.Encode(record)
+	})
+	if err == nil {
+		log.L(ctx).Infof("Exported %d audit records from namespace '%s'", count, am.namespace)
+	}
+	return err
+}
+
+// VerifyAuditRecords walks the whole chain, checking each record hash against the record content,
+// and against the previous hash of the record that follows it. Verification stops at the first
+// record that fails either check.
+func (am *auditManager) VerifyAuditRecords(ctx context.Context) (*core.AuditVerification, error) {
+	result := &core.AuditVerification{Valid: true}
+	err := am.page(ctx, func(record *core.AuditRecord) error {
+		if !result.Valid {
+			return nil
+		}
+		result.Records++
+		var invalid error
+		switch {
+		case !record.PreviousHash.Equals(result.LastHash):
+			invalid = i18n.NewError(ctx, coremsgs.MsgAuditRecordChainBroken, record.Sequence)
+		case !record.CalcHash().Equals(record.Hash):
+			invalid = i18n.NewError(ctx, coremsgs.MsgAuditRecordHashMismatch, record.Sequence)
+		}
+		if invalid != nil {
+			result.Valid = false
+			result.FirstInvalid = record.Sequence
+			result.Error = invalid.Error()
+			return nil
+		}
+		result.LastHash = record.Hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,240 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestAuditManager(t *testing.T) (*auditManager, *databasemocks.Plugin) {
+	mdi := &databasemocks.Plugin{}
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	am, err := NewAuditManager(context.Background(), "ns1", mdi)
+	assert.NoError(t, err)
+	return am.(*auditManager), mdi
+}
+
+func testChain(n int) []*core.AuditRecord {
+	records := make([]*core.AuditRecord, n)
+	var previousHash *fftypes.Bytes32
+	for i := range records {
+		records[i] = &core.AuditRecord{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Method:    "POST",
+			Path:      fmt.Sprintf("/api/v1/namespaces/ns1/messages/%d", i),
+			Status:    202,
+			Created:   fftypes.Now(),
+		}
+		records[i].Seal(previousHash)
+		records[i].Sequence = int64(i + 1)
+		previousHash = records[i].Hash
+	}
+	return records
+}
+
+func TestNewAuditManagerMissingDeps(t *testing.T) {
+	_, err := NewAuditManager(context.Background(), "ns1", nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestRecordRequestFirst(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	mdi.On("GetLastAuditRecord", mock.Anything, "ns1").Return(nil, nil)
+	mdi.On("InsertAuditRecord", mock.Anything, mock.Anything).Return(nil)
+
+	record := &core.AuditRecord{Method: "POST", Path: "/api/v1/namespaces/ns1/messages/broadcast", Status: 202}
+	err := am.RecordRequest(context.Background(), record)
+	assert.NoError(t, err)
+	assert.NotNil(t, record.ID)
+	assert.Equal(t, "ns1", record.Namespace)
+	assert.NotNil(t, record.Created)
+	assert.Nil(t, record.PreviousHash)
+	assert.Equal(t, *record.CalcHash(), *record.Hash)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRecordRequestChained(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	last := testChain(1)[0]
+	mdi.On("GetLastAuditRecord", mock.Anything, "ns1").Return(last, nil)
+	mdi.On("InsertAuditRecord", mock.Anything, mock.Anything).Return(nil)
+
+	created := fftypes.Now()
+	record := &core.AuditRecord{Method: "DELETE", Path: "/api/v1/namespaces/ns1/subscriptions/sub1", Status: 204, Created: created}
+	err := am.RecordRequest(context.Background(), record)
+	assert.NoError(t, err)
+	assert.Equal(t, created, record.Created)
+	assert.Equal(t, *last.Hash, *record.PreviousHash)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRecordRequestGetLastFail(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	mdi.On("GetLastAuditRecord", mock.Anything, "ns1").Return(nil, fmt.Errorf("pop"))
+
+	err := am.RecordRequest(context.Background(), &core.AuditRecord{})
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetAuditRecords(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	mdi.On("GetAuditRecords", mock.Anything, "ns1", mock.Anything).Return([]*core.AuditRecord{}, nil, nil)
+
+	fb := database.AuditRecordQueryFactory.NewFilter(context.Background())
+	_, _, err := am.GetAuditRecords(context.Background(), fb.And(fb.Eq("principal", "user:alice")))
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestExportAuditRecordsPaged(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	chain := testChain(pageSize + 1)
+	mdi.On("GetAuditRecords", mock.Anything, "ns1", mock.Anything).Return(chain[:pageSize], nil, nil).Once()
+	mdi.On("GetAuditRecords", mock.Anything, "ns1", mock.Anything).Return(chain[pageSize:], nil, nil).Once()
+
+	var buff bytes.Buffer
+	err := am.ExportAuditRecords(context.Background(), &buff)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	assert.Len(t, lines, pageSize+1)
+	var last core.AuditRecord
+	err = json.Unmarshal([]byte(lines[pageSize]), &last)
+	assert.NoError(t, err)
+	assert.Equal(t, *chain[pageSize].Hash, *last.Hash)
+
+	// The second page starts after the last sequence of the first
+	fi, err := mdi.Calls[1].Arguments[2].(ffapi.Filter).Finalize()
+	assert.NoError(t, err)
+	assert.Contains(t, fi.String(), fmt.Sprintf("sequence >> %d", pageSize))
+
+	mdi.AssertExpectations(t)
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) { return 0, fmt.Errorf("pop") }
+
+func TestExportAuditRecordsWriteFail(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	mdi.On("GetAuditRecords", mock.Anything, "ns1", mock.Anything).Return(testChain(1), nil, nil)
+
+	err := am.ExportAuditRecords(context.Background(), errWriter{})
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestExportAuditRecordsQueryFail(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	mdi.On("GetAuditRecords", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	var buff bytes.Buffer
+	err := am.ExportAuditRecords(context.Background(), &buff)
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestVerifyAuditRecordsValid(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	chain := testChain(3)
+	mdi.On("GetAuditRecords", mock.Anything, "ns1", mock.Anything).Return(chain, nil, nil)
+
+	result, err := am.VerifyAuditRecords(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Equal(t, int64(3), result.Records)
+	assert.Equal(t, *chain[2].Hash, *result.LastHash)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestVerifyAuditRecordsEmpty(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	mdi.On("GetAuditRecords", mock.Anything, "ns1", mock.Anything).Return([]*core.AuditRecord{}, nil, nil)
+
+	result, err := am.VerifyAuditRecords(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Zero(t, result.Records)
+	assert.Nil(t, result.LastHash)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestVerifyAuditRecordsModified(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	chain := testChain(3)
+	chain[1].Status = 200
+	mdi.On("GetAuditRecords", mock.Anything, "ns1", mock.Anything).Return(chain, nil, nil)
+
+	result, err := am.VerifyAuditRecords(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(2), result.Records)
+	assert.Equal(t, int64(2), result.FirstInvalid)
+	assert.Equal(t, *chain[0].Hash, *result.LastHash)
+	assert.Regexp(t, "FF10536", result.Error)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestVerifyAuditRecordsRemoved(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	chain := testChain(3)
+	mdi.On("GetAuditRecords", mock.Anything, "ns1", mock.Anything).Return([]*core.AuditRecord{chain[0], chain[2]}, nil, nil)
+
+	result, err := am.VerifyAuditRecords(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(3), result.FirstInvalid)
+	assert.Regexp(t, "FF10537", result.Error)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestVerifyAuditRecordsQueryFail(t *testing.T) {
+	am, mdi := newTestAuditManager(t)
+	mdi.On("GetAuditRecords", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := am.VerifyAuditRecords(context.Background())
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+}
@@ -0,0 +1,72 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+)
+
+const (
+	// JWTKeyFile is a PEM file containing the public key or certificate used to verify JWT signatures
+	JWTKeyFile = "keyFile"
+	// JWTClaim is the claim used as the name of the principal
+	JWTClaim = "claim"
+	// JWTIssuer is the issuer that tokens must have in their iss claim, if set
+	JWTIssuer = "issuer"
+	// JWTAudience is the audience that tokens must include in their aud claim, if set
+	JWTAudience = "audience"
+	// JWTClockSkew is the tolerance allowed when checking the exp and nbf claims
+	JWTClockSkew = "clockSkew"
+	// JWTJWKS is the sub-section configuring a JSON Web Key Set, from a file or an HTTP endpoint
+	JWTJWKS = "jwks"
+	// JWTJWKSFile is a file containing a JSON Web Key Set
+	JWTJWKSFile = "file"
+	// JWTJWKSMinRefreshInterval is the minimum time between fetches of the JWKS URL, when a token is signed by an unknown key
+	JWTJWKSMinRefreshInterval = "minRefreshInterval"
+	// JWTJWKSMaxAge is the maximum time keys fetched from the JWKS URL are used, before they are fetched again
+	JWTJWKSMaxAge = "maxAge"
+
+	// JWTSigningKeysClaim is a claim listing the signing keys the principal can use in requests
+	JWTSigningKeysClaim = "signingKeysClaim"
+
+	defaultClaim                  = "sub"
+	defaultClockSkew              = "30s"
+	defaultJWKSMinRefreshInterval = "1m"
+	defaultJWKSMaxAge             = "15m"
+)
+
+// InitVerifierConfig adds the configuration for verifying tokens to a section,
+// for use by this plugin and other auth plugins that accept JWT bearer tokens
+func InitVerifierConfig(conf config.Section) {
+	conf.AddKnownKey(JWTKeyFile)
+	conf.AddKnownKey(JWTClaim, defaultClaim)
+	conf.AddKnownKey(JWTIssuer)
+	conf.AddKnownKey(JWTAudience)
+	conf.AddKnownKey(JWTClockSkew, defaultClockSkew)
+
+	jwksConf := conf.SubSection(JWTJWKS)
+	ffresty.InitConfig(jwksConf)
+	jwksConf.AddKnownKey(JWTJWKSFile)
+	jwksConf.AddKnownKey(JWTJWKSMinRefreshInterval, defaultJWKSMinRefreshInterval)
+	jwksConf.AddKnownKey(JWTJWKSMaxAge, defaultJWKSMaxAge)
+}
+
+func (a *Auth) InitConfig(conf config.Section) {
+	InitVerifierConfig(conf)
+	conf.AddKnownKey(JWTSigningKeysClaim)
+}
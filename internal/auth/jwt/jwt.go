@@ -0,0 +1,163 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const (
+	authHeaderName    = "Authorization"
+	bearerTokenPrefix = "Bearer "
+
+	// AccessTokenQueryParam is the query parameter that can carry the token on a websocket upgrade,
+	// for clients that cannot set headers on websocket connections
+	AccessTokenQueryParam = "access_token"
+)
+
+// Auth is an auth plugin that allows requests carrying a valid JWT bearer token, such as one
+// issued by an OpenID Connect identity provider
+type Auth struct {
+	name             string
+	verifier         *Verifier
+	signingKeysClaim string
+}
+
+func Name() string {
+	return "jwt"
+}
+
+func (a *Auth) Name() string {
+	return Name()
+}
+
+func (a *Auth) Init(ctx context.Context, name string, conf config.Section) (err error) {
+	a.name = name
+	a.signingKeysClaim = conf.GetString(JWTSigningKeysClaim)
+	if a.verifier, err = NewVerifier(ctx, conf); err != nil {
+		return err
+	}
+	if a.verifier == nil {
+		return i18n.NewError(ctx, coremsgs.MsgJWTNoKeySource, name)
+	}
+	log.L(ctx).Infof("jwt auth plugin enabled (name=%s)", name)
+	return nil
+}
+
+func (a *Auth) Authorize(ctx context.Context, req *fftypes.AuthReq) error {
+	token := BearerToken(req)
+	if token == "" {
+		return i18n.NewError(ctx, i18n.MsgUnauthorized)
+	}
+	claims, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		return err
+	}
+	principal := a.verifier.Principal(claims)
+	if principal == "" {
+		log.L(ctx).Warnf("JWT has no string '%s' claim to identify the principal", a.verifier.claim)
+		return i18n.NewError(ctx, i18n.MsgUnauthorized)
+	}
+	if a.signingKeysClaim != "" {
+		if err := a.checkSigningKeys(ctx, principal, claims[a.signingKeysClaim]); err != nil {
+			return err
+		}
+	}
+	core.GetAuthRequestInfo(ctx).Principal = "jwt:" + principal
+	log.L(ctx).Debugf("JWT principal '%s' authorized for namespace '%s'", principal, req.Namespace)
+	return nil
+}
+
+// BearerToken returns the bearer token from the Authorization header of the request, or for
+// a websocket upgrade from the access_token query parameter
+func BearerToken(req *fftypes.AuthReq) string {
+	if authHeader := req.Header.Get(authHeaderName); strings.HasPrefix(authHeader, bearerTokenPrefix) {
+		return strings.TrimPrefix(authHeader, bearerTokenPrefix)
+	}
+	if req.URL != nil && strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return req.URL.Query().Get(AccessTokenQueryParam)
+	}
+	return ""
+}
+
+// checkSigningKeys restricts a principal whose token has the signing keys claim to only the keys
+// listed in it. Requests from such a principal must set the key explicitly, rather than use a default.
+func (a *Auth) checkSigningKeys(ctx context.Context, principal string, claim interface{}) error {
+	var allowed []string
+	switch claim := claim.(type) {
+	case nil:
+		return nil
+	case string:
+		allowed = []string{claim}
+	case []interface{}:
+		for _, k := range claim {
+			if s, ok := k.(string); ok {
+				allowed = append(allowed, s)
+			}
+		}
+	}
+	for _, key := range requestSigningKeys(core.GetAuthRequestInfo(ctx).Input) {
+		if !containsKey(allowed, key) {
+			return i18n.NewError(ctx, coremsgs.MsgJWTSigningKeyNotAllowed, principal, key)
+		}
+	}
+	return nil
+}
+
+func containsKey(allowed []string, key string) bool {
+	for _, k := range allowed {
+		if key != "" && strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestSigningKeys returns the key from each part of a request input that can be signed with a specific key
+func requestSigningKeys(input interface{}) []string {
+	switch input := input.(type) {
+	case *core.MessageInOut:
+		return []string{input.Header.Key}
+	case *[]*core.MessageInOut:
+		keys := make([]string, len(*input))
+		for i, msg := range *input {
+			keys[i] = msg.Header.Key
+		}
+		return keys
+	case *core.TokenTransferInput:
+		return []string{input.Key}
+	case *core.TokenApprovalInput:
+		return []string{input.Key}
+	case *core.TokenPoolInput:
+		return []string{input.Key}
+	case *core.ContractCallRequest:
+		return []string{input.Key}
+	case *core.ContractDeployRequest:
+		return []string{input.Key}
+	case *core.IdentityCreateDTO:
+		return []string{input.Key}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestJWTAuth(t *testing.T) (*Auth, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	conf := newTestVerifierConfig()
+	conf.Set(JWTKeyFile, writeTestPublicKey(t, &key.PublicKey))
+	conf.Set(JWTSigningKeysClaim, "keys")
+	a := &Auth{}
+	err = a.Init(context.Background(), "jwt", conf)
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt", a.Name())
+	return a, key
+}
+
+func bearerReq(token string) *fftypes.AuthReq {
+	return &fftypes.AuthReq{
+		Method:    http.MethodPost,
+		URL:       &url.URL{Path: "/api/v1/namespaces/ns1/messages/broadcast"},
+		Header:    http.Header{"Authorization": []string{"Bearer " + token}},
+		Namespace: "ns1",
+	}
+}
+
+func TestInitNoKeySource(t *testing.T) {
+	a := &Auth{}
+	err := a.Init(context.Background(), "jwt", newTestVerifierConfig())
+	assert.Regexp(t, "FF10530", err)
+}
+
+func TestInitBadKeySource(t *testing.T) {
+	conf := newTestVerifierConfig()
+	conf.Set(JWTKeyFile, "/does/not/exist")
+	a := &Auth{}
+	err := a.Init(context.Background(), "jwt", conf)
+	assert.Regexp(t, "FF10529", err)
+}
+
+func TestAuthorizeBearerToken(t *testing.T) {
+	a, key := newTestJWTAuth(t)
+	ctx := context.Background()
+
+	info := &core.AuthRequestInfo{}
+	err := a.Authorize(core.WithAuthRequestInfo(ctx, info), bearerReq(signTestJWT(t, "ES256", "", key, validClaims("alice"))))
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt:alice", info.Principal)
+
+	err = a.Authorize(ctx, bearerReq("bad"))
+	assert.Regexp(t, "FF00169", err)
+
+	err = a.Authorize(ctx, bearerReq(signTestJWT(t, "ES256", "", key, map[string]interface{}{"exp": time.Now().Add(time.Hour).Unix()})))
+	assert.Regexp(t, "FF00169", err)
+
+	err = a.Authorize(ctx, &fftypes.AuthReq{Header: http.Header{}})
+	assert.Regexp(t, "FF00169", err)
+}
+
+func TestAuthorizeWebSocketQueryToken(t *testing.T) {
+	a, key := newTestJWTAuth(t)
+	token := signTestJWT(t, "ES256", "", key, validClaims("alice"))
+	req := &fftypes.AuthReq{
+		Method: http.MethodGet,
+		URL:    &url.URL{Path: "/ws", RawQuery: AccessTokenQueryParam + "=" + token},
+		Header: http.Header{"Upgrade": []string{"websocket"}},
+	}
+	err := a.Authorize(context.Background(), req)
+	assert.NoError(t, err)
+
+	// The query parameter is only honored for websocket upgrades
+	req.Header = http.Header{}
+	err = a.Authorize(context.Background(), req)
+	assert.Regexp(t, "FF00169", err)
+}
+
+func TestAuthorizeSigningKeys(t *testing.T) {
+	a, key := newTestJWTAuth(t)
+	claims := validClaims("alice")
+	claims["keys"] = []interface{}{"0xAAAA", "0xbbbb", 12345}
+	token := signTestJWT(t, "ES256", "", key, claims)
+	authorize := func(input interface{}) error {
+		ctx := core.WithAuthRequestInfo(context.Background(), &core.AuthRequestInfo{Input: input})
+		return a.Authorize(ctx, bearerReq(token))
+	}
+
+	msg := func(key string) *core.MessageInOut {
+		m := &core.MessageInOut{}
+		m.Header.Key = key
+		return m
+	}
+	assert.NoError(t, authorize(msg("0xaaaa")))
+	assert.NoError(t, authorize(&[]*core.MessageInOut{msg("0xaaaa"), msg("0xBBBB")}))
+	assert.NoError(t, authorize(&core.TokenTransferInput{TokenTransfer: core.TokenTransfer{Key: "0xaaaa"}}))
+	assert.NoError(t, authorize(&core.ContractCallRequest{Key: "0xaaaa"}))
+	assert.NoError(t, authorize(nil))
+
+	assert.Regexp(t, "FF10532.*alice.*0xcccc", authorize(msg("0xcccc")))
+	assert.Regexp(t, "FF10532", authorize(msg("")))
+	assert.Regexp(t, "FF10532", authorize(&[]*core.MessageInOut{msg("0xaaaa"), msg("0xcccc")}))
+	assert.Regexp(t, "FF10532", authorize(&core.TokenApprovalInput{TokenApproval: core.TokenApproval{Key: "0xcccc"}}))
+	assert.Regexp(t, "FF10532", authorize(&core.TokenPoolInput{TokenPool: core.TokenPool{Key: "0xcccc"}}))
+	assert.Regexp(t, "FF10532", authorize(&core.ContractDeployRequest{Key: "0xcccc"}))
+	assert.Regexp(t, "FF10532", authorize(&core.IdentityCreateDTO{Key: "0xcccc"}))
+
+	// A single key as a string
+	claims["keys"] = "0xaaaa"
+	token = signTestJWT(t, "ES256", "", key, claims)
+	assert.NoError(t, authorize(msg("0xaaaa")))
+	assert.Regexp(t, "FF10532", authorize(msg("0xbbbb")))
+
+	// Principals without the claim are not restricted
+	delete(claims, "keys")
+	token = signTestJWT(t, "ES256", "", key, claims)
+	assert.NoError(t, authorize(msg("0xcccc")))
+}
@@ -0,0 +1,379 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffresty"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+var jwtHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// jwtCurveAlgs is the algorithm that must be used with each size of EC key
+var jwtCurveAlgs = map[int]string{
+	256: "ES256",
+	384: "ES384",
+	521: "ES512",
+}
+
+var jwkCurves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// Verifier checks the signature, issuer, audience and validity period of JWT bearer tokens,
+// against a single public key or the keys in a JSON Web Key Set
+type Verifier struct {
+	claim     string
+	issuer    string
+	audience  string
+	clockSkew time.Duration
+
+	mux                sync.Mutex
+	keys               map[string]crypto.PublicKey // by key ID, with "" for a key from a PEM file
+	jwksClient         *resty.Client
+	jwksURL            string
+	minRefreshInterval time.Duration
+	maxAge             time.Duration
+	lastFetch          time.Time // the last attempt to fetch the JWKS
+	keysFetched        time.Time // when the current keys were fetched
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []*jwk `json:"keys"`
+}
+
+// NewVerifier builds a verifier from a section initialized with InitVerifierConfig.
+// It returns nil if none of keyFile, jwks.file or jwks.url are set.
+func NewVerifier(ctx context.Context, conf config.Section) (v *Verifier, err error) {
+	v = &Verifier{
+		claim:     conf.GetString(JWTClaim),
+		issuer:    conf.GetString(JWTIssuer),
+		audience:  conf.GetString(JWTAudience),
+		clockSkew: conf.GetDuration(JWTClockSkew),
+	}
+	jwksConf := conf.SubSection(JWTJWKS)
+	switch {
+	case conf.GetString(JWTKeyFile) != "":
+		keyFile := conf.GetString(JWTKeyFile)
+		key, err := loadPublicKeyFile(ctx, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = map[string]crypto.PublicKey{"": key}
+	case jwksConf.GetString(JWTJWKSFile) != "":
+		jwksFile := jwksConf.GetString(JWTJWKSFile)
+		b, err := os.ReadFile(jwksFile)
+		if err == nil {
+			v.keys, err = parseJWKS(b)
+		}
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgJWKSInvalid, jwksFile)
+		}
+	case jwksConf.GetString(ffresty.HTTPConfigURL) != "":
+		// Keys are fetched on first use, so a node can start while the identity provider is unavailable
+		v.jwksURL = jwksConf.GetString(ffresty.HTTPConfigURL)
+		v.minRefreshInterval = jwksConf.GetDuration(JWTJWKSMinRefreshInterval)
+		v.maxAge = jwksConf.GetDuration(JWTJWKSMaxAge)
+		if v.maxAge < v.minRefreshInterval {
+			v.maxAge = v.minRefreshInterval
+		}
+		if v.jwksClient, err = ffresty.New(ctx, jwksConf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+	return v, nil
+}
+
+func loadPublicKeyFile(ctx context.Context, keyFile string) (crypto.PublicKey, error) {
+	b, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgJWTKeyInvalid, keyFile)
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgJWTKeyInvalid, keyFile)
+	}
+	var key crypto.PublicKey
+	if block.Type == "CERTIFICATE" {
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			key = cert.PublicKey
+		}
+	} else {
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgJWTKeyInvalid, keyFile)
+	}
+	return key, nil
+}
+
+// parseJWKS returns the RSA and EC signing keys in a JSON Web Key Set, ignoring any other keys
+func parseJWKS(b []byte) (map[string]crypto.PublicKey, error) {
+	var set jwks
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		switch k.Kty {
+		case "RSA":
+			n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+			e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+			if err1 != nil || err2 != nil || len(n) == 0 || len(e) == 0 {
+				return nil, fmt.Errorf("invalid RSA key '%s'", k.Kid)
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			curve, ok := jwkCurves[k.Crv]
+			x, err1 := base64.RawURLEncoding.DecodeString(k.X)
+			y, err2 := base64.RawURLEncoding.DecodeString(k.Y)
+			if !ok || err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid EC key '%s'", k.Kid)
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	return keys, nil
+}
+
+// Verify returns the claims of the token if it is correctly signed and currently valid.
+// The reason for any failure is logged, and the caller just gets an unauthorized error.
+func (v *Verifier) Verify(ctx context.Context, token string) (map[string]interface{}, error) {
+	claims, err := v.verifyToken(ctx, token)
+	if err != nil {
+		log.L(ctx).Warnf("JWT verification failed: %s", err)
+		return nil, i18n.NewError(ctx, i18n.MsgUnauthorized)
+	}
+	return claims, nil
+}
+
+// Principal returns the name of the principal from verified claims, or an empty string if it is not set
+func (v *Verifier) Principal(claims map[string]interface{}) string {
+	principal, _ := claims[v.claim].(string)
+	return principal
+}
+
+func (v *Verifier) verifyToken(ctx context.Context, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token does not have three parts")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	hash, ok := jwtHashes[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported algorithm '%s'", header.Alg)
+	}
+	key, err := v.getKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	hasher := hash.New()
+	hasher.Write([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(key, header.Alg, hash, hasher.Sum(nil), sig); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	return claims, v.checkClaims(claims)
+}
+
+func verifySignature(key crypto.PublicKey, alg string, hash crypto.Hash, digest, sig []byte) error {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("algorithm '%s' does not match RSA key", alg)
+		}
+		return rsa.VerifyPKCS1v15(key, hash, digest, sig)
+	case *ecdsa.PublicKey:
+		bitSize := key.Curve.Params().BitSize
+		size := (bitSize + 7) / 8
+		if alg != jwtCurveAlgs[bitSize] || len(sig) != 2*size {
+			return fmt.Errorf("algorithm '%s' or signature length does not match EC key", alg)
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+func (v *Verifier) checkClaims(claims map[string]interface{}) error {
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("token has no expiry")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(v.clockSkew)) {
+		return fmt.Errorf("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0).Add(-v.clockSkew)) {
+		return fmt.Errorf("token not yet valid")
+	}
+	if v.issuer != "" && claims["iss"] != v.issuer {
+		return fmt.Errorf("token issuer '%v' does not match", claims["iss"])
+	}
+	if v.audience != "" && !hasAudience(claims["aud"], v.audience) {
+		return fmt.Errorf("token audience '%v' does not match", claims["aud"])
+	}
+	return nil
+}
+
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getKey returns the key with the given ID, fetching the JWKS again if the key is not known
+// and the last fetch was long enough ago, or if the keys are older than the maximum age.
+// Tokens without a key ID can only be verified when there is exactly one key.
+func (v *Verifier) getKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mux.Lock()
+	defer v.mux.Unlock()
+
+	key, ok := v.lookupKey(kid)
+	expired := v.jwksClient != nil && v.keys != nil && time.Since(v.keysFetched) >= v.maxAge
+	if (!ok || expired) && v.jwksClient != nil && time.Since(v.lastFetch) >= v.minRefreshInterval {
+		if err := v.fetchJWKS(ctx); err != nil {
+			return nil, err
+		}
+		key, ok = v.lookupKey(kid)
+		expired = false
+	}
+	if expired {
+		// Any of the keys might have been revoked since they were fetched
+		return nil, fmt.Errorf("keys fetched from JWKS '%s' at %s have expired", v.jwksURL, v.keysFetched.Format(time.RFC3339))
+	}
+	if !ok {
+		return nil, fmt.Errorf("no key found for key ID '%s'", kid)
+	}
+	return key, nil
+}
+
+func (v *Verifier) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if key, ok := v.keys[kid]; ok {
+		return key, true
+	}
+	// A key loaded from keyFile has no ID, so is used for every token
+	if key, ok := v.keys[""]; ok {
+		return key, true
+	}
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func (v *Verifier) fetchJWKS(ctx context.Context) error {
+	v.lastFetch = time.Now()
+	res, err := v.jwksClient.R().SetContext(ctx).Get("")
+	if err == nil && !res.IsSuccess() {
+		err = fmt.Errorf("HTTP status %d", res.StatusCode())
+	}
+	var keys map[string]crypto.PublicKey
+	if err == nil {
+		keys, err = parseJWKS(res.Body())
+	}
+	if err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgJWKSInvalid, v.jwksURL)
+	}
+	log.L(ctx).Infof("Fetched %d keys from JWKS '%s'", len(keys), v.jwksURL)
+	v.keys = keys
+	v.keysFetched = v.lastFetch
+	return nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err == nil {
+		err = json.Unmarshal(b, v)
+	}
+	return err
+}
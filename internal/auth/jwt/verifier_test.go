@@ -0,0 +1,485 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestVerifierConfig() config.Section {
+	config.RootConfigReset()
+	conf := config.RootSection("jwt")
+	(&Auth{}).InitConfig(conf)
+	return conf
+}
+
+func writeTestFile(t *testing.T, b []byte) string {
+	file := filepath.Join(t.TempDir(), "file")
+	err := os.WriteFile(file, b, 0600)
+	assert.NoError(t, err)
+	return file
+}
+
+func writeTestPEM(t *testing.T, pemType string, b []byte) string {
+	return writeTestFile(t, pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: b}))
+}
+
+func writeTestPublicKey(t *testing.T, key crypto.PublicKey) string {
+	b, err := x509.MarshalPKIXPublicKey(key)
+	assert.NoError(t, err)
+	return writeTestPEM(t, "PUBLIC KEY", b)
+}
+
+func validClaims(sub string) map[string]interface{} {
+	return map[string]interface{}{"sub": sub, "exp": time.Now().Add(time.Hour).Unix()}
+}
+
+func signTestJWT(t *testing.T, alg, kid string, key crypto.Signer, claims interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := jwtHashes[alg]
+	hasher := hash.New()
+	hasher.Write([]byte(signingInput))
+	digest := hasher.Sum(nil)
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest)
+		assert.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		assert.NoError(t, err)
+		size := (k.Curve.Params().BitSize + 7) / 8
+		sig = make([]byte, 2*size)
+		r.FillBytes(sig[:size])
+		s.FillBytes(sig[size:])
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func testJWKS(t *testing.T, rsaKey *rsa.PublicKey, ecKey *ecdsa.PublicKey) []byte {
+	b, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec1",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+				"y":   base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+			},
+			{"kty": "RSA", "kid": "enc1", "use": "enc"},
+			{"kty": "oct", "kid": "hmac1"},
+		},
+	})
+	assert.NoError(t, err)
+	return b
+}
+
+func TestNewVerifierNotConfigured(t *testing.T) {
+	v, err := NewVerifier(context.Background(), newTestVerifierConfig())
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestVerifyKeyFileRSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	conf := newTestVerifierConfig()
+	conf.Set(JWTKeyFile, writeTestPublicKey(t, &key.PublicKey))
+	v, err := NewVerifier(context.Background(), conf)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	claims, err := v.Verify(ctx, signTestJWT(t, "RS256", "", key, validClaims("alice")))
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", v.Principal(claims))
+	_, err = v.Verify(ctx, signTestJWT(t, "RS512", "any", key, validClaims("alice")))
+	assert.NoError(t, err)
+
+	// A token signed with the right algorithm, but the wrong key
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, err = v.Verify(ctx, signTestJWT(t, "RS256", "", otherKey, validClaims("alice")))
+	assert.Regexp(t, "FF00169", err)
+	// An EC token against an RSA key
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	_, err = v.Verify(ctx, signTestJWT(t, "ES256", "", ecKey, validClaims("alice")))
+	assert.Regexp(t, "FF00169", err)
+}
+
+func TestVerifyKeyFileECDSACertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "issuer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	conf := newTestVerifierConfig()
+	conf.Set(JWTKeyFile, writeTestPEM(t, "CERTIFICATE", cert))
+	conf.Set(JWTClaim, "user")
+	v, err := NewVerifier(context.Background(), conf)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	claims, err := v.Verify(ctx, signTestJWT(t, "ES384", "", key, map[string]interface{}{"user": "alice", "exp": time.Now().Add(time.Hour).Unix()}))
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", v.Principal(claims))
+	assert.Empty(t, v.Principal(map[string]interface{}{"sub": "alice"}))
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	_, err = v.Verify(ctx, signTestJWT(t, "ES384", "", otherKey, validClaims("alice")))
+	assert.Regexp(t, "FF00169", err)
+	// The hash must match the curve
+	_, err = v.Verify(ctx, signTestJWT(t, "ES256", "", key, validClaims("alice")))
+	assert.Regexp(t, "FF00169", err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, err = v.Verify(ctx, signTestJWT(t, "RS256", "", rsaKey, validClaims("alice")))
+	assert.Regexp(t, "FF00169", err)
+}
+
+func TestVerifyClaims(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	v := &Verifier{
+		claim:     "sub",
+		issuer:    "https://issuer.example.com",
+		audience:  "firefly",
+		clockSkew: time.Minute,
+		keys:      map[string]crypto.PublicKey{"": &key.PublicKey},
+	}
+	ctx := context.Background()
+	claims := func(changes map[string]interface{}) map[string]interface{} {
+		c := validClaims("alice")
+		c["iss"] = "https://issuer.example.com"
+		c["aud"] = "firefly"
+		for k, val := range changes {
+			c[k] = val
+		}
+		return c
+	}
+
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "", key, claims(nil)))
+	assert.NoError(t, err)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "", key, claims(map[string]interface{}{"aud": []string{"other", "firefly"}})))
+	assert.NoError(t, err)
+	// Within the clock skew
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "", key, claims(map[string]interface{}{
+		"exp": time.Now().Add(-30 * time.Second).Unix(),
+		"nbf": time.Now().Add(30 * time.Second).Unix(),
+	})))
+	assert.NoError(t, err)
+
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "", key, claims(map[string]interface{}{"exp": nil})))
+	assert.Regexp(t, "no expiry", err)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "", key, claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})))
+	assert.Regexp(t, "expired", err)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "", key, claims(map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()})))
+	assert.Regexp(t, "not yet valid", err)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "", key, claims(map[string]interface{}{"iss": "other"})))
+	assert.Regexp(t, "issuer", err)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "", key, claims(map[string]interface{}{"aud": "other"})))
+	assert.Regexp(t, "audience", err)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "", key, claims(map[string]interface{}{"aud": []string{"other"}})))
+	assert.Regexp(t, "audience", err)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "", key, claims(map[string]interface{}{"aud": nil})))
+	assert.Regexp(t, "audience", err)
+}
+
+func TestVerifyTokenBadFormat(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	v := &Verifier{claim: "sub", keys: map[string]crypto.PublicKey{"": &key.PublicKey}}
+	ctx := context.Background()
+	valid := signTestJWT(t, "ES256", "", key, validClaims("alice"))
+	header := strings.Split(valid, ".")[0]
+
+	_, err = v.verifyToken(ctx, "not a jwt")
+	assert.Regexp(t, "three parts", err)
+	_, err = v.verifyToken(ctx, "!!!.e30.sig")
+	assert.Error(t, err)
+	_, err = v.verifyToken(ctx, header+".e30.!!!")
+	assert.Error(t, err)
+	_, err = v.verifyToken(ctx, base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))+".e30.")
+	assert.Regexp(t, "unsupported algorithm", err)
+	// A valid signature over a payload that is not a JSON object
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "", key, []string{}))
+	assert.Error(t, err)
+
+	v.keys[""] = ed25519.PublicKey{}
+	_, err = v.verifyToken(ctx, valid)
+	assert.Regexp(t, "unsupported key type", err)
+}
+
+func TestVerifyJWKSFile(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	conf := newTestVerifierConfig()
+	conf.SubSection(JWTJWKS).Set(JWTJWKSFile, writeTestFile(t, testJWKS(t, &rsaKey.PublicKey, &ecKey.PublicKey)))
+	v, err := NewVerifier(context.Background(), conf)
+	assert.NoError(t, err)
+	assert.Len(t, v.keys, 2)
+	ctx := context.Background()
+
+	_, err = v.Verify(ctx, signTestJWT(t, "RS256", "rsa1", rsaKey, validClaims("alice")))
+	assert.NoError(t, err)
+	_, err = v.Verify(ctx, signTestJWT(t, "ES256", "ec1", ecKey, validClaims("alice")))
+	assert.NoError(t, err)
+	_, err = v.Verify(ctx, signTestJWT(t, "ES256", "rsa1", ecKey, validClaims("alice")))
+	assert.Regexp(t, "FF00169", err)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "RS256", "unknown", rsaKey, validClaims("alice")))
+	assert.Error(t, err)
+	// With more than one key, the key ID is required
+	_, err = v.verifyToken(ctx, signTestJWT(t, "RS256", "", rsaKey, validClaims("alice")))
+	assert.Regexp(t, "no key found", err)
+}
+
+func TestVerifyJWKSSingleKeyNoKeyID(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	jwks, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "EC",
+			"kid": "ec1",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+			"y":   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+		}},
+	})
+	assert.NoError(t, err)
+	conf := newTestVerifierConfig()
+	conf.SubSection(JWTJWKS).Set(JWTJWKSFile, writeTestFile(t, jwks))
+	v, err := NewVerifier(context.Background(), conf)
+	assert.NoError(t, err)
+
+	_, err = v.Verify(context.Background(), signTestJWT(t, "ES256", "", key, validClaims("alice")))
+	assert.NoError(t, err)
+	_, err = v.Verify(context.Background(), signTestJWT(t, "ES256", "other", key, validClaims("alice")))
+	assert.Regexp(t, "FF00169", err)
+}
+
+func TestNewVerifierBadJWKSFile(t *testing.T) {
+	for _, jwks := range []string{
+		`!json`,
+		`{"keys":[{"kty":"RSA","kid":"rsa1"}]}`,
+		`{"keys":[{"kty":"RSA","kid":"rsa1","n":"!!!","e":"AQAB"}]}`,
+		`{"keys":[{"kty":"EC","kid":"ec1","crv":"P-192"}]}`,
+		`{"keys":[{"kty":"EC","kid":"ec1","crv":"P-256","x":"!!!"}]}`,
+	} {
+		conf := newTestVerifierConfig()
+		conf.SubSection(JWTJWKS).Set(JWTJWKSFile, writeTestFile(t, []byte(jwks)))
+		_, err := NewVerifier(context.Background(), conf)
+		assert.Regexp(t, "FF10531", err, jwks)
+	}
+
+	conf := newTestVerifierConfig()
+	conf.SubSection(JWTJWKS).Set(JWTJWKSFile, "/does/not/exist")
+	_, err := NewVerifier(context.Background(), conf)
+	assert.Regexp(t, "FF10531", err)
+}
+
+func TestVerifyJWKSURL(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(testJWKS(t, &rsaKey.PublicKey, &ecKey.PublicKey))
+	}))
+	defer server.Close()
+
+	conf := newTestVerifierConfig()
+	conf.SubSection(JWTJWKS).Set("url", server.URL+"/jwks")
+	conf.SubSection(JWTJWKS).Set(JWTJWKSMinRefreshInterval, "1h")
+	v, err := NewVerifier(context.Background(), conf)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, fetches)
+	ctx := context.Background()
+
+	_, err = v.Verify(ctx, signTestJWT(t, "RS256", "rsa1", rsaKey, validClaims("alice")))
+	assert.NoError(t, err)
+	_, err = v.Verify(ctx, signTestJWT(t, "ES256", "ec1", ecKey, validClaims("alice")))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+
+	// An unknown key does not cause another fetch within the refresh interval
+	_, err = v.Verify(ctx, signTestJWT(t, "RS256", "unknown", rsaKey, validClaims("alice")))
+	assert.Regexp(t, "FF00169", err)
+	assert.Equal(t, 1, fetches)
+
+	// ... but does after it
+	v.lastFetch = time.Now().Add(-2 * time.Hour)
+	_, err = v.Verify(ctx, signTestJWT(t, "RS256", "unknown", rsaKey, validClaims("alice")))
+	assert.Regexp(t, "FF00169", err)
+	assert.Equal(t, 2, fetches)
+}
+
+func TestVerifyJWKSURLMaxAge(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	rotatedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	fetches := 0
+	revoked := false
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if revoked {
+			_, _ = w.Write(testJWKS(t, &rotatedKey.PublicKey, &ecKey.PublicKey))
+		} else {
+			_, _ = w.Write(testJWKS(t, &rsaKey.PublicKey, &ecKey.PublicKey))
+		}
+	}))
+	defer server.Close()
+
+	conf := newTestVerifierConfig()
+	conf.SubSection(JWTJWKS).Set("url", server.URL+"/jwks")
+	conf.SubSection(JWTJWKS).Set(JWTJWKSMinRefreshInterval, "1m")
+	conf.SubSection(JWTJWKS).Set(JWTJWKSMaxAge, "10m")
+	v, err := NewVerifier(context.Background(), conf)
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, v.maxAge)
+	ctx := context.Background()
+	age := func(d time.Duration) {
+		v.lastFetch = v.lastFetch.Add(-d)
+		v.keysFetched = v.keysFetched.Add(-d)
+	}
+
+	_, err = v.verifyToken(ctx, signTestJWT(t, "RS256", "rsa1", rsaKey, validClaims("alice")))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+
+	// A key that is revoked, by replacing it, is trusted until the keys reach the maximum age
+	revoked = true
+	age(5 * time.Minute)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "RS256", "rsa1", rsaKey, validClaims("alice")))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+	age(6 * time.Minute)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "RS256", "rsa1", rsaKey, validClaims("alice")))
+	assert.Regexp(t, "verification error", err)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "ec1", ecKey, validClaims("alice")))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+
+	// Expired keys are not used if they cannot be fetched again
+	failing = true
+	age(11 * time.Minute)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "ec1", ecKey, validClaims("alice")))
+	assert.Regexp(t, "FF10531.*500", err)
+	_, err = v.verifyToken(ctx, signTestJWT(t, "ES256", "ec1", ecKey, validClaims("alice")))
+	assert.Regexp(t, "expired", err)
+	assert.Equal(t, 3, fetches)
+}
+
+func TestNewVerifierMaxAgeBelowRefreshInterval(t *testing.T) {
+	conf := newTestVerifierConfig()
+	conf.SubSection(JWTJWKS).Set("url", "http://localhost:12345")
+	conf.SubSection(JWTJWKS).Set(JWTJWKSMinRefreshInterval, "1h")
+	v, err := NewVerifier(context.Background(), conf)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, v.maxAge)
+}
+
+func TestVerifyJWKSURLFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	conf := newTestVerifierConfig()
+	conf.SubSection(JWTJWKS).Set("url", server.URL)
+	v, err := NewVerifier(context.Background(), conf)
+	assert.NoError(t, err)
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	_, err = v.verifyToken(context.Background(), signTestJWT(t, "ES256", "ec1", key, validClaims("alice")))
+	assert.Regexp(t, "FF10531.*500", err)
+}
+
+func TestNewVerifierBadJWKSURLConfig(t *testing.T) {
+	conf := newTestVerifierConfig()
+	conf.SubSection(JWTJWKS).Set("url", "http://localhost:12345")
+	conf.SubSection(JWTJWKS).SubSection("tls").Set("enabled", true)
+	conf.SubSection(JWTJWKS).SubSection("tls").Set("caFile", "/does/not/exist")
+	_, err := NewVerifier(context.Background(), conf)
+	assert.Error(t, err)
+}
+
+func TestNewVerifierBadKeyFile(t *testing.T) {
+	for _, keyFile := range []string{
+		"/does/not/exist",
+		writeTestFile(t, []byte("not a pem")),
+		writeTestPEM(t, "PUBLIC KEY", []byte("bad")),
+		writeTestPEM(t, "CERTIFICATE", []byte("bad")),
+	} {
+		conf := newTestVerifierConfig()
+		conf.Set(JWTKeyFile, keyFile)
+		_, err := NewVerifier(context.Background(), conf)
+		assert.Regexp(t, "FF10529", err)
+	}
+}
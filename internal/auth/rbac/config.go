@@ -0,0 +1,83 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"github.com/hyperledger/firefly-common/pkg/auth/basic"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly/internal/auth/jwt"
+)
+
+const (
+	// RBACBasic is the sub-section configuring basic auth users as principals
+	RBACBasic = "basic"
+	// RBACJWT is the sub-section configuring verified JWT bearer tokens as principals
+	RBACJWT = "jwt"
+	// RBACMTLS is the sub-section configuring mTLS client certificates as principals
+	RBACMTLS = "mtls"
+	// RBACMTLSEnabled enables mTLS client certificate subjects as principals
+	RBACMTLSEnabled = "enabled"
+	// RBACMTLSPrincipal is the part of the certificate subject used as the name of the principal - 'cn' or 'subject'
+	RBACMTLSPrincipal = "principal"
+
+	// RBACRoles is the array of roles
+	RBACRoles = "roles"
+	// RBACRoleName is the name of the role, used in decision logs
+	RBACRoleName = "name"
+	// RBACRoleNamespace is the namespace the role applies to - empty for all namespaces
+	RBACRoleNamespace = "namespace"
+	// RBACRolePrincipals is the list of principals that hold the role
+	RBACRolePrincipals = "principals"
+	// RBACRoleRules is the array of rules in the role
+	RBACRoleRules = "rules"
+	// RBACRuleEffect is whether a matching rule allows or denies the request
+	RBACRuleEffect = "effect"
+	// RBACRuleGroups is the list of route groups the rule matches
+	RBACRuleGroups = "groups"
+	// RBACRuleMethods is the list of HTTP methods the rule matches
+	RBACRuleMethods = "methods"
+	// RBACRuleContractAPIs is the list of contract API names the rule matches
+	RBACRuleContractAPIs = "contractAPIs"
+	// RBACRuleContractMethods is the list of contract method paths the rule matches
+	RBACRuleContractMethods = "contractMethods"
+	// RBACRuleTokenPools is the list of token pool names or IDs the rule matches
+	RBACRuleTokenPools = "tokenPools"
+
+	defaultMTLSPrincipal = mtlsPrincipalCN
+)
+
+func (a *Auth) InitConfig(conf config.Section) {
+	(&basic.Auth{}).InitConfig(conf.SubSection(RBACBasic))
+
+	jwt.InitVerifierConfig(conf.SubSection(RBACJWT))
+
+	mtlsConf := conf.SubSection(RBACMTLS)
+	mtlsConf.AddKnownKey(RBACMTLSEnabled, false)
+	mtlsConf.AddKnownKey(RBACMTLSPrincipal, defaultMTLSPrincipal)
+
+	roles := conf.SubArray(RBACRoles)
+	roles.AddKnownKey(RBACRoleName)
+	roles.AddKnownKey(RBACRoleNamespace)
+	roles.AddKnownKey(RBACRolePrincipals)
+	rules := roles.SubArray(RBACRoleRules)
+	rules.AddKnownKey(RBACRuleEffect, effectAllow)
+	rules.AddKnownKey(RBACRuleGroups)
+	rules.AddKnownKey(RBACRuleMethods)
+	rules.AddKnownKey(RBACRuleContractAPIs)
+	rules.AddKnownKey(RBACRuleContractMethods)
+	rules.AddKnownKey(RBACRuleTokenPools)
+}
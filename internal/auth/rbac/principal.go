@@ -0,0 +1,96 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/auth/jwt"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const (
+	principalTypeBasic = "basic"
+	principalTypeJWT   = "jwt"
+	principalTypeMTLS  = "mtls"
+
+	mtlsPrincipalCN      = "cn"
+	mtlsPrincipalSubject = "subject"
+
+	authHeaderName    = "Authorization"
+	basicAuthPrefix   = "Basic "
+	bearerTokenPrefix = "Bearer "
+)
+
+// principal is the authenticated caller of an API, which roles are granted to by name
+type principal struct {
+	Type string
+	Name string
+}
+
+func (p *principal) String() string {
+	return p.Type + ":" + p.Name
+}
+
+// resolvePrincipal authenticates the caller, using the first of a verified client certificate, a basic
+// auth header or a JWT bearer token that is configured and present on the request
+func (a *Auth) resolvePrincipal(ctx context.Context, req *fftypes.AuthReq) (*principal, error) {
+	if a.mtlsPrincipal != "" {
+		if tlsState := core.GetAuthRequestInfo(ctx).TLS; tlsState != nil && len(tlsState.VerifiedChains) > 0 && len(tlsState.VerifiedChains[0]) > 0 {
+			subject := tlsState.VerifiedChains[0][0].Subject
+			name := subject.CommonName
+			if a.mtlsPrincipal == mtlsPrincipalSubject {
+				name = subject.String()
+			}
+			return &principal{Type: principalTypeMTLS, Name: name}, nil
+		}
+	}
+
+	if authHeader := req.Header.Get(authHeaderName); a.basic != nil && strings.HasPrefix(authHeader, basicAuthPrefix) {
+		return a.resolveBasicPrincipal(ctx, req, strings.TrimPrefix(authHeader, basicAuthPrefix))
+	}
+	if token := jwt.BearerToken(req); a.jwt != nil && token != "" {
+		claims, err := a.jwt.Verify(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		name := a.jwt.Principal(claims)
+		if name == "" {
+			log.L(ctx).Warnf("JWT has no claim to identify the principal")
+			return nil, i18n.NewError(ctx, i18n.MsgUnauthorized)
+		}
+		return &principal{Type: principalTypeJWT, Name: name}, nil
+	}
+	return nil, i18n.NewError(ctx, i18n.MsgUnauthorized)
+}
+
+func (a *Auth) resolveBasicPrincipal(ctx context.Context, req *fftypes.AuthReq, credentials string) (*principal, error) {
+	decoded, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil || !strings.Contains(string(decoded), ":") {
+		return nil, i18n.NewError(ctx, i18n.MsgUnauthorized)
+	}
+	// The password is checked against the password file by the basic auth plugin
+	if err := a.basic.Authorize(ctx, req); err != nil {
+		return nil, err
+	}
+	return &principal{Type: principalTypeBasic, Name: strings.SplitN(string(decoded), ":", 2)[0]}, nil
+}
@@ -0,0 +1,148 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func mtlsContext(cn string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn, Organization: []string{"org1"}}}
+	return core.WithAuthRequestInfo(context.Background(), &core.AuthRequestInfo{
+		TLS: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	})
+}
+
+func TestResolvePrincipalMTLS(t *testing.T) {
+	a, err := newTestRBAC(t, `
+  mtls:
+    enabled: true
+`)
+	assert.NoError(t, err)
+
+	p, err := a.resolvePrincipal(mtlsContext("alice"), &fftypes.AuthReq{Header: http.Header{}})
+	assert.NoError(t, err)
+	assert.Equal(t, "mtls:alice", p.String())
+
+	a.mtlsPrincipal = mtlsPrincipalSubject
+	p, err = a.resolvePrincipal(mtlsContext("alice"), &fftypes.AuthReq{Header: http.Header{}})
+	assert.NoError(t, err)
+	assert.Equal(t, "CN=alice,O=org1", p.Name)
+
+	// An unverified connection has no principal
+	ctx := core.WithAuthRequestInfo(context.Background(), &core.AuthRequestInfo{TLS: &tls.ConnectionState{}})
+	_, err = a.resolvePrincipal(ctx, &fftypes.AuthReq{Header: http.Header{}})
+	assert.Regexp(t, "FF00169", err)
+}
+
+func TestResolvePrincipalBasic(t *testing.T) {
+	a := newTestBasicRBAC(t)
+	ctx := context.Background()
+
+	p, err := a.resolvePrincipal(ctx, basicAuthReq("alice", http.MethodGet, "/api/v1/status"))
+	assert.NoError(t, err)
+	assert.Equal(t, "basic:alice", p.String())
+
+	header := http.Header{}
+	header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("alice:wrong")))
+	_, err = a.resolvePrincipal(ctx, &fftypes.AuthReq{Header: header})
+	assert.Regexp(t, "FF00169", err)
+
+	header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("alice")))
+	_, err = a.resolvePrincipal(ctx, &fftypes.AuthReq{Header: header})
+	assert.Regexp(t, "FF00169", err)
+
+	header.Set("Authorization", "Basic !!!")
+	_, err = a.resolvePrincipal(ctx, &fftypes.AuthReq{Header: header})
+	assert.Regexp(t, "FF00169", err)
+
+	// Bearer tokens are not accepted when jwt is not configured
+	header.Set("Authorization", "Bearer token")
+	_, err = a.resolvePrincipal(ctx, &fftypes.AuthReq{Header: header})
+	assert.Regexp(t, "FF00169", err)
+}
+
+func signTestJWT(t *testing.T, key *ecdsa.PrivateKey, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	assert.NoError(t, err)
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func bearerAuthReq(token string) *fftypes.AuthReq {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	return &fftypes.AuthReq{
+		Method:    http.MethodPost,
+		URL:       &url.URL{Path: "/api/v1/namespaces/ns1/messages/broadcast"},
+		Header:    header,
+		Namespace: "ns1",
+	}
+}
+
+func TestAuthorizeJWT(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	b, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b}), 0600)
+	assert.NoError(t, err)
+	a, err := newTestRBAC(t, `
+  jwt:
+    keyFile: `+keyFile+testRolesConfig)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	exp := time.Now().Add(time.Hour).Unix()
+	p, err := a.resolvePrincipal(ctx, bearerAuthReq(signTestJWT(t, key, map[string]interface{}{"sub": "alice", "exp": exp})))
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt:alice", p.String())
+	assert.NoError(t, a.Authorize(ctx, bearerAuthReq(signTestJWT(t, key, map[string]interface{}{"sub": "alice", "exp": exp}))))
+	assert.Regexp(t, "FF00170", a.Authorize(ctx, bearerAuthReq(signTestJWT(t, key, map[string]interface{}{"sub": "bob", "exp": exp}))))
+
+	// An expired token, and a token with no principal claim
+	assert.Regexp(t, "FF00169", a.Authorize(ctx, bearerAuthReq(signTestJWT(t, key, map[string]interface{}{"sub": "alice", "exp": exp - 7200}))))
+	assert.Regexp(t, "FF00169", a.Authorize(ctx, bearerAuthReq(signTestJWT(t, key, map[string]interface{}{"name": "alice", "exp": exp}))))
+	assert.Regexp(t, "FF00169", a.Authorize(ctx, bearerAuthReq("bad")))
+}
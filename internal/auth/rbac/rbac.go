@@ -0,0 +1,240 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/auth/basic"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/auth/jwt"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const (
+	effectAllow = "allow"
+	effectDeny  = "deny"
+)
+
+// Auth is an auth plugin that identifies the principal making each request, and then allows or denies
+// the request according to the rules in the roles granted to that principal in the request's namespace
+type Auth struct {
+	name          string
+	basic         *basic.Auth
+	jwt           *jwt.Verifier
+	mtlsPrincipal string
+	roles         []*role
+}
+
+type role struct {
+	name       string
+	namespace  string
+	principals []string
+	rules      []*rule
+}
+
+type rule struct {
+	allow           bool
+	groups          []string
+	methods         []string
+	contractAPIs    []string
+	contractMethods []string
+	tokenPools      []string
+}
+
+func Name() string {
+	return "rbac"
+}
+
+func (a *Auth) Name() string {
+	return Name()
+}
+
+func (a *Auth) Init(ctx context.Context, name string, conf config.Section) (err error) {
+	a.name = name
+
+	basicConf := conf.SubSection(RBACBasic)
+	if basicConf.GetString(basic.PasswordFile) != "" {
+		a.basic = &basic.Auth{}
+		if err := a.basic.Init(ctx, name, basicConf); err != nil {
+			return err
+		}
+	}
+
+	if a.jwt, err = jwt.NewVerifier(ctx, conf.SubSection(RBACJWT)); err != nil {
+		return err
+	}
+
+	mtlsConf := conf.SubSection(RBACMTLS)
+	if mtlsConf.GetBool(RBACMTLSEnabled) {
+		a.mtlsPrincipal = mtlsConf.GetString(RBACMTLSPrincipal)
+		if a.mtlsPrincipal != mtlsPrincipalCN && a.mtlsPrincipal != mtlsPrincipalSubject {
+			return i18n.NewError(ctx, coremsgs.MsgRBACInvalidMTLSPrincipal, a.mtlsPrincipal)
+		}
+	}
+
+	if a.basic == nil && a.jwt == nil && a.mtlsPrincipal == "" {
+		return i18n.NewError(ctx, coremsgs.MsgRBACNoPrincipalSource, name)
+	}
+
+	roles := conf.SubArray(RBACRoles)
+	roleCount := roles.ArraySize()
+	a.roles = make([]*role, 0, roleCount)
+	for i := 0; i < roleCount; i++ {
+		r, err := a.loadRole(ctx, i, roles.ArrayEntry(i))
+		if err != nil {
+			return err
+		}
+		a.roles = append(a.roles, r)
+	}
+
+	log.L(ctx).Infof("rbac auth plugin enabled (name=%s,roles=%d)", name, len(a.roles))
+	return nil
+}
+
+func (a *Auth) loadRole(ctx context.Context, index int, conf config.Section) (*role, error) {
+	r := &role{
+		name:       conf.GetString(RBACRoleName),
+		namespace:  conf.GetString(RBACRoleNamespace),
+		principals: conf.GetStringSlice(RBACRolePrincipals),
+	}
+	if r.name == "" {
+		return nil, i18n.NewError(ctx, coremsgs.MsgRBACRoleNameRequired, index, a.name)
+	}
+	for _, p := range r.principals {
+		if p != "*" && !strings.Contains(p, ":") {
+			return nil, i18n.NewError(ctx, coremsgs.MsgRBACInvalidPrincipal, p, r.name)
+		}
+	}
+
+	rules := conf.SubArray(RBACRoleRules)
+	ruleCount := rules.ArraySize()
+	for i := 0; i < ruleCount; i++ {
+		ruleConf := rules.ArrayEntry(i)
+		effect := strings.ToLower(ruleConf.GetString(RBACRuleEffect))
+		if effect != effectAllow && effect != effectDeny {
+			return nil, i18n.NewError(ctx, coremsgs.MsgRBACInvalidEffect, effect, i, r.name)
+		}
+		ru := &rule{
+			allow:           effect == effectAllow,
+			groups:          ruleConf.GetStringSlice(RBACRuleGroups),
+			methods:         ruleConf.GetStringSlice(RBACRuleMethods),
+			contractAPIs:    ruleConf.GetStringSlice(RBACRuleContractAPIs),
+			contractMethods: ruleConf.GetStringSlice(RBACRuleContractMethods),
+			tokenPools:      ruleConf.GetStringSlice(RBACRuleTokenPools),
+		}
+		for _, group := range ru.groups {
+			if group != "*" && !validGroups[group] {
+				return nil, i18n.NewError(ctx, coremsgs.MsgRBACInvalidGroup, group, i, r.name)
+			}
+		}
+		for j, method := range ru.methods {
+			ru.methods[j] = strings.ToUpper(method)
+		}
+		r.rules = append(r.rules, ru)
+	}
+	return r, nil
+}
+
+func (a *Auth) Authorize(ctx context.Context, req *fftypes.AuthReq) error {
+	r := classifyRequest(ctx, req)
+	p, err := a.resolvePrincipal(ctx, req)
+	if err != nil {
+		log.L(ctx).Infof("rbac decision=unauthenticated namespace=%s method=%s path=%s group=%s", r.namespace, r.method, r.path, r.group)
+		return err
+	}
+
+	info := core.GetAuthRequestInfo(ctx)
+	info.Principal = p.String()
+
+	// Resources are only looked up once the caller is authenticated
+	if err := r.resolve(ctx, info.Resolver); err != nil {
+		return err
+	}
+	allowed, roleName := a.evaluate(p, r)
+	decision := effectDeny
+	if allowed {
+		decision = effectAllow
+	}
+	log.L(ctx).Infof("rbac decision=%s principal=%s role=%s namespace=%s method=%s path=%s group=%s contractAPI=%s contractMethod=%s tokenPool=%s",
+		decision, p, roleName, r.namespace, r.method, r.path, r.group, r.contractAPI, r.contractMethod, r.tokenPool)
+	if !allowed {
+		return i18n.NewError(ctx, i18n.MsgForbidden)
+	}
+	return nil
+}
+
+// evaluate checks every rule of every role the principal holds in the namespace.
+// A matching deny rule always wins, and a request that matches no allow rule is denied.
+func (a *Auth) evaluate(p *principal, r *request) (allowed bool, roleName string) {
+	for _, ro := range a.roles {
+		if (ro.namespace != "" && ro.namespace != r.namespace) || !matches(ro.principals, p.String()) {
+			continue
+		}
+		for _, ru := range ro.rules {
+			if !ru.matches(r) {
+				continue
+			}
+			if !ru.allow {
+				return false, ro.name
+			}
+			if !allowed {
+				allowed, roleName = true, ro.name
+			}
+		}
+	}
+	return allowed, roleName
+}
+
+func (ru *rule) matches(r *request) bool {
+	return (len(ru.groups) == 0 || matches(ru.groups, r.group)) &&
+		(len(ru.methods) == 0 || matches(ru.methods, r.method)) &&
+		ru.matchesResource(ru.contractAPIs, r.contractAPI) &&
+		(len(ru.contractMethods) == 0 || matches(ru.contractMethods, r.contractMethod)) &&
+		ru.matchesResource(ru.tokenPools, r.tokenPool)
+}
+
+// matchesResource returns true if the rule does not list resources, or lists the name or ID of the resource.
+// A resource that could not be resolved might be any resource, so it is matched by deny rules that list
+// resources, but not by allow rules unless they have the "*" wildcard.
+func (ru *rule) matchesResource(list []string, res *resource) bool {
+	switch {
+	case len(list) == 0:
+		return true
+	case res == nil:
+		return matches(list, "")
+	case !res.resolved:
+		return !ru.allow || matches(list, "")
+	default:
+		return matches(list, res.name) || matches(list, res.id)
+	}
+}
+
+// matches returns true if the value is in the list, or the list contains the "*" wildcard
+func matches(list []string, value string) bool {
+	for _, v := range list {
+		if v == "*" || (v == value && value != "") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,325 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/coremocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testPasswordHash = "$2a$04$5/XwjjrjByjqI8S80yON5OkLPk5548wq9590OrSxB0s4b9M2emlji" // "pass"
+
+const testRolesConfig = `
+  roles:
+  - name: reader
+    namespace: ns1
+    principals: ["*"]
+    rules:
+    - methods: [GET]
+  - name: messenger
+    namespace: ns1
+    principals: [basic:alice, jwt:alice]
+    rules:
+    - groups: [messages, subscriptions]
+  - name: token-operator
+    principals: [basic:bob]
+    rules:
+    - groups: [tokens]
+      tokenPools: [pool1]
+    - groups: [contracts]
+      contractAPIs: [api1]
+      contractMethods: [set]
+  - name: no-admin
+    principals: [basic:bob]
+    rules:
+    - effect: deny
+      groups: [admin]
+    - effect: deny
+      tokenPools: [pool3]
+`
+
+func newTestRBAC(t *testing.T, yaml string) (*Auth, error) {
+	config.RootConfigReset()
+	authConf := config.RootArray("plugins.auth")
+	a := &Auth{}
+	a.InitConfig(authConf.SubSection(Name()))
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader("plugins:\n  auth:\n  - rbac:" + strings.ReplaceAll(yaml, "\n", "\n    ")))
+	assert.NoError(t, err)
+	return a, a.Init(context.Background(), "rbac1", authConf.ArrayEntry(0).SubSection(Name()))
+}
+
+func writeTestPasswordFile(t *testing.T) string {
+	passwordFile := filepath.Join(t.TempDir(), "users")
+	err := os.WriteFile(passwordFile, []byte("alice:"+testPasswordHash+"\nbob:"+testPasswordHash+"\n"), 0600)
+	assert.NoError(t, err)
+	return passwordFile
+}
+
+func newTestBasicRBAC(t *testing.T) *Auth {
+	a, err := newTestRBAC(t, `
+  basic:
+    passwordfile: `+writeTestPasswordFile(t)+testRolesConfig)
+	assert.NoError(t, err)
+	return a
+}
+
+func basicAuthReq(user, method, path string) *fftypes.AuthReq {
+	header := http.Header{}
+	header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user+":pass")))
+	return &fftypes.AuthReq{
+		Method:    method,
+		URL:       &url.URL{Path: path},
+		Header:    header,
+		Namespace: "ns1",
+	}
+}
+
+func TestName(t *testing.T) {
+	a := &Auth{}
+	assert.Equal(t, "rbac", a.Name())
+}
+
+func TestInitRoles(t *testing.T) {
+	a := newTestBasicRBAC(t)
+	assert.NotNil(t, a.basic)
+	assert.Len(t, a.roles, 4)
+	assert.Equal(t, "ns1", a.roles[0].namespace)
+	assert.Equal(t, []string{"GET"}, a.roles[0].rules[0].methods)
+	assert.True(t, a.roles[2].rules[1].allow)
+	assert.Equal(t, []string{"api1"}, a.roles[2].rules[1].contractAPIs)
+	assert.False(t, a.roles[3].rules[0].allow)
+}
+
+func TestInitNoPrincipalSource(t *testing.T) {
+	_, err := newTestRBAC(t, testRolesConfig)
+	assert.Regexp(t, "FF10524.*rbac1", err)
+}
+
+func TestInitBadPasswordFile(t *testing.T) {
+	_, err := newTestRBAC(t, `
+  basic:
+    passwordfile: /does/not/exist
+`)
+	assert.Error(t, err)
+}
+
+func TestInitBadJWTKeyFile(t *testing.T) {
+	_, err := newTestRBAC(t, `
+  jwt:
+    keyFile: /does/not/exist
+`)
+	assert.Regexp(t, "FF10529", err)
+}
+
+func TestInitBadMTLSPrincipal(t *testing.T) {
+	_, err := newTestRBAC(t, `
+  mtls:
+    enabled: true
+    principal: wrong
+`)
+	assert.Regexp(t, "FF10525.*wrong", err)
+}
+
+func TestInitRoleMissingName(t *testing.T) {
+	_, err := newTestRBAC(t, `
+  mtls:
+    enabled: true
+  roles:
+  - principals: [basic:alice]
+`)
+	assert.Regexp(t, "FF10526", err)
+}
+
+func TestInitRoleBadPrincipal(t *testing.T) {
+	_, err := newTestRBAC(t, `
+  mtls:
+    enabled: true
+  roles:
+  - name: role1
+    principals: [alice]
+`)
+	assert.Regexp(t, "FF10563.*alice.*role1", err)
+}
+
+func TestInitRuleBadEffect(t *testing.T) {
+	_, err := newTestRBAC(t, `
+  mtls:
+    enabled: true
+  roles:
+  - name: role1
+    rules:
+    - effect: maybe
+`)
+	assert.Regexp(t, "FF10527.*maybe", err)
+}
+
+func TestInitRuleBadGroup(t *testing.T) {
+	_, err := newTestRBAC(t, `
+  mtls:
+    enabled: true
+  roles:
+  - name: role1
+    rules:
+    - groups: [wrong]
+`)
+	assert.Regexp(t, "FF10528.*wrong", err)
+}
+
+func TestAuthorizeUnauthenticated(t *testing.T) {
+	a := newTestBasicRBAC(t)
+	err := a.Authorize(context.Background(), &fftypes.AuthReq{
+		Method: http.MethodGet,
+		URL:    &url.URL{Path: "/api/v1/namespaces/ns1/messages"},
+		Header: http.Header{},
+	})
+	assert.Regexp(t, "FF00169", err)
+}
+
+var (
+	testPool1 = &core.TokenPool{ID: fftypes.NewUUID(), Name: "pool1"}
+	testPool2 = &core.TokenPool{ID: fftypes.NewUUID(), Name: "pool2"}
+	testAPI1  = &core.ContractAPI{ID: fftypes.NewUUID(), Name: "api1"}
+	testAPI2  = &core.ContractAPI{ID: fftypes.NewUUID(), Name: "api2"}
+)
+
+func newTestResolver() *coremocks.AuthResourceResolver {
+	res := &coremocks.AuthResourceResolver{}
+	res.On("ResolveTokenPool", mock.Anything, "pool1").Return(testPool1, nil).Maybe()
+	res.On("ResolveTokenPool", mock.Anything, testPool1.ID.String()).Return(testPool1, nil).Maybe()
+	res.On("ResolveTokenPool", mock.Anything, "").Return(testPool1, nil).Maybe()
+	res.On("ResolveTokenPool", mock.Anything, "pool2").Return(testPool2, nil).Maybe()
+	res.On("ResolveTokenPool", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	res.On("ResolveContractAPI", mock.Anything, "api1").Return(testAPI1, nil).Maybe()
+	res.On("ResolveContractAPI", mock.Anything, "api2").Return(testAPI2, nil).Maybe()
+	res.On("ResolveContractAPIForCall", mock.Anything, mock.MatchedBy(func(call *core.ContractCallRequest) bool {
+		return call.Location != nil
+	})).Return(testAPI1, nil).Maybe()
+	res.On("ResolveContractAPIForCall", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
+	return res
+}
+
+func TestAuthorizeDecisions(t *testing.T) {
+	a := newTestBasicRBAC(t)
+	res := newTestResolver()
+	ctx := core.WithAuthRequestInfo(context.Background(), &core.AuthRequestInfo{Resolver: res})
+	inputCtx := func(input interface{}) context.Context {
+		return core.WithAuthRequestInfo(context.Background(), &core.AuthRequestInfo{Input: input, Resolver: res})
+	}
+
+	// Any principal can read in ns1
+	assert.NoError(t, a.Authorize(ctx, basicAuthReq("bob", http.MethodGet, "/api/v1/namespaces/ns1/messages")))
+	// ... but only alice can send messages
+	assert.NoError(t, a.Authorize(ctx, basicAuthReq("alice", http.MethodPost, "/api/v1/namespaces/ns1/messages/broadcast")))
+	assert.Regexp(t, "FF00170", a.Authorize(ctx, basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/messages/broadcast")))
+	// ... and reads are not allowed outside ns1
+	req := basicAuthReq("bob", http.MethodGet, "/api/v1/namespaces/ns2/messages")
+	req.Namespace = "ns2"
+	assert.Regexp(t, "FF00170", a.Authorize(ctx, req))
+
+	// The principal is returned to the caller
+	info := &core.AuthRequestInfo{}
+	assert.NoError(t, a.Authorize(core.WithAuthRequestInfo(ctx, info), basicAuthReq("alice", http.MethodGet, "/api/v1/namespaces/ns1/messages")))
+	assert.Equal(t, "basic:alice", info.Principal)
+
+	// Deny wins over allow, for bob reading the status in the admin group
+	assert.Regexp(t, "FF00170", a.Authorize(ctx, basicAuthReq("bob", http.MethodGet, "/api/v1/namespaces/ns1/status")))
+	assert.NoError(t, a.Authorize(ctx, basicAuthReq("alice", http.MethodGet, "/api/v1/namespaces/ns1/status")))
+
+	// Token pools are restricted by path and by input, matching the pool by name or ID
+	assert.NoError(t, a.Authorize(ctx, basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/tokens/pools/pool1/publish")))
+	assert.NoError(t, a.Authorize(ctx, basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/tokens/pools/"+testPool1.ID.String()+"/publish")))
+	assert.Regexp(t, "FF00170", a.Authorize(ctx, basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/tokens/pools/pool2/publish")))
+	assert.NoError(t, a.Authorize(inputCtx(&core.TokenTransferInput{Pool: "pool1"}), basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/tokens/transfers")))
+	assert.NoError(t, a.Authorize(inputCtx(&core.TokenTransferInput{Pool: testPool1.ID.String()}), basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/tokens/transfers")))
+	assert.Regexp(t, "FF00170", a.Authorize(ctx, basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/tokens/transfers")))
+	// ... including the only pool in the namespace, when none is specified
+	assert.NoError(t, a.Authorize(inputCtx(&core.TokenTransferInput{}), basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/tokens/transfers")))
+	// ... and an unknown pool is not allowed by a rule listing pools, but is denied by one
+	assert.Regexp(t, "FF00170", a.Authorize(inputCtx(&core.TokenTransferInput{Pool: "unknown"}), basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/tokens/transfers")))
+	assert.NoError(t, a.Authorize(ctx, basicAuthReq("bob", http.MethodGet, "/api/v1/namespaces/ns1/tokens/pools/pool2")))
+	assert.Regexp(t, "FF00170", a.Authorize(ctx, basicAuthReq("bob", http.MethodGet, "/api/v1/namespaces/ns1/tokens/pools/unknown")))
+	assert.NoError(t, a.Authorize(ctx, basicAuthReq("alice", http.MethodGet, "/api/v1/namespaces/ns1/tokens/pools/unknown")))
+
+	// Contract APIs and methods are restricted
+	assert.NoError(t, a.Authorize(ctx, basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/apis/api1/invoke/set")))
+	assert.Regexp(t, "FF00170", a.Authorize(ctx, basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/apis/api1/invoke/get")))
+	assert.Regexp(t, "FF00170", a.Authorize(ctx, basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/apis/api2/invoke/set")))
+	// ... including direct calls to the contract of an API
+	call := &core.ContractCallRequest{MethodPath: "set", Location: fftypes.JSONAnyPtr(`{"address":"0x12345"}`)}
+	assert.NoError(t, a.Authorize(inputCtx(call), basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/contracts/invoke")))
+	assert.Regexp(t, "FF00170", a.Authorize(inputCtx(&core.ContractCallRequest{MethodPath: "set"}), basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/contracts/invoke")))
+
+	// Websocket listeners are in the subscriptions group
+	wsReq := basicAuthReq("alice", "", "")
+	wsReq.URL = nil
+	assert.NoError(t, a.Authorize(ctx, wsReq))
+}
+
+func TestAuthorizeResolveFail(t *testing.T) {
+	a := newTestBasicRBAC(t)
+	res := &coremocks.AuthResourceResolver{}
+	res.On("ResolveTokenPool", mock.Anything, "pool1").Return(nil, fmt.Errorf("pop"))
+	ctx := core.WithAuthRequestInfo(context.Background(), &core.AuthRequestInfo{Resolver: res})
+	err := a.Authorize(ctx, basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/tokens/pools/pool1/publish"))
+	assert.Regexp(t, "pop", err)
+	res.AssertExpectations(t)
+}
+
+func TestAuthorizeNoResolver(t *testing.T) {
+	a := newTestBasicRBAC(t)
+	err := a.Authorize(context.Background(), basicAuthReq("bob", http.MethodPost, "/api/v1/namespaces/ns1/tokens/pools/pool1/publish"))
+	assert.Regexp(t, "FF00170", err)
+}
+
+func TestMatchesResource(t *testing.T) {
+	allow := &rule{allow: true}
+	deny := &rule{}
+	resolved := &resource{ref: "id1", name: "name1", id: "id1", resolved: true}
+	unresolved := &resource{ref: "name1"}
+	assert.True(t, allow.matchesResource(nil, nil))
+	assert.True(t, allow.matchesResource([]string{"*"}, nil))
+	assert.False(t, allow.matchesResource([]string{"name1"}, nil))
+	assert.True(t, allow.matchesResource([]string{"name1"}, resolved))
+	assert.True(t, allow.matchesResource([]string{"id1"}, resolved))
+	assert.False(t, allow.matchesResource([]string{"name2"}, resolved))
+	assert.False(t, allow.matchesResource([]string{"name1"}, unresolved))
+	assert.True(t, allow.matchesResource([]string{"*"}, unresolved))
+	assert.True(t, deny.matchesResource([]string{"name2"}, unresolved))
+}
+
+func TestMatches(t *testing.T) {
+	assert.True(t, matches([]string{"a", "b"}, "b"))
+	assert.True(t, matches([]string{"*"}, ""))
+	assert.False(t, matches([]string{"a"}, ""))
+	assert.False(t, matches([]string{}, "a"))
+}
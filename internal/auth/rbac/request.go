@@ -0,0 +1,227 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// Route groups that rules can allow or deny
+const (
+	GroupMessages      = "messages"
+	GroupTokens        = "tokens"
+	GroupContracts     = "contracts"
+	GroupSubscriptions = "subscriptions"
+	GroupIdentities    = "identities"
+	GroupTransactions  = "transactions"
+	GroupGraphQL       = "graphql"
+	GroupAdmin         = "admin"
+)
+
+var validGroups = map[string]bool{
+	GroupMessages:      true,
+	GroupTokens:        true,
+	GroupContracts:     true,
+	GroupSubscriptions: true,
+	GroupIdentities:    true,
+	GroupTransactions:  true,
+	GroupGraphQL:       true,
+	GroupAdmin:         true,
+}
+
+// resourceGroups maps the first path segment of a namespaced API route to its group.
+// Anything not listed here (status, network, pins, the SPI etc.) is in the admin group.
+var resourceGroups = map[string]string{
+	"messages":               GroupMessages,
+	"data":                   GroupMessages,
+	"datatypes":              GroupMessages,
+	"batches":                GroupMessages,
+	"groups":                 GroupMessages,
+	"tokens":                 GroupTokens,
+	"contracts":              GroupContracts,
+	"apis":                   GroupContracts,
+	"subscriptions":          GroupSubscriptions,
+	"events":                 GroupSubscriptions,
+	"websockets":             GroupSubscriptions,
+	"identities":             GroupIdentities,
+	"verifiers":              GroupIdentities,
+	"transactions":           GroupTransactions,
+	"operations":             GroupTransactions,
+	"blockchainevents":       GroupTransactions,
+	"blockchaintransactions": GroupTransactions,
+	"graphql":                GroupGraphQL,
+}
+
+// request is the view of an API request that rules are matched against
+type request struct {
+	namespace      string
+	method         string
+	path           string
+	group          string
+	contractAPI    *resource
+	contractMethod string
+	tokenPool      *resource
+	contractCall   *core.ContractCallRequest
+}
+
+// resource is a token pool or contract API that a request refers to, which rules match by its canonical
+// name or ID once it has been resolved - however the request referred to it
+type resource struct {
+	ref      string
+	name     string
+	id       string
+	resolved bool
+}
+
+func (res *resource) String() string {
+	switch {
+	case res == nil:
+		return ""
+	case res.resolved:
+		return res.name
+	default:
+		return res.ref + "(unresolved)"
+	}
+}
+
+func classifyRequest(ctx context.Context, req *fftypes.AuthReq) *request {
+	r := &request{
+		namespace: req.Namespace,
+		method:    strings.ToUpper(req.Method),
+	}
+	if req.URL == nil {
+		// Websocket messages are authorized without a URL, and are only used to listen for events
+		r.method = http.MethodGet
+		r.group = GroupSubscriptions
+		return r
+	}
+	r.path = req.URL.Path
+
+	segments := resourceSegments(req.URL.Path)
+	r.group = groupOf(segments)
+	if segments == nil {
+		return r
+	}
+
+	switch {
+	case segments[0] == "graphql":
+		// GraphQL queries are posted, but are read-only. Each field of the query is then authorized
+		// as a GET of the equivalent REST route, so is matched against the rules for that route.
+		r.method = http.MethodGet
+	case segments[0] == "apis" && len(segments) > 1:
+		r.contractAPI = &resource{ref: segments[1]}
+		if len(segments) > 3 && (segments[2] == "invoke" || segments[2] == "query") {
+			r.contractMethod = segments[3]
+		}
+	case segments[0] == "tokens" && len(segments) > 2 && segments[1] == "pools":
+		r.tokenPool = &resource{ref: segments[2]}
+	}
+
+	switch input := core.GetAuthRequestInfo(ctx).Input.(type) {
+	case *core.ContractCallRequest:
+		if r.contractMethod == "" {
+			r.contractMethod = input.MethodPath
+			if r.contractMethod == "" && input.Method != nil {
+				r.contractMethod = input.Method.Name
+			}
+		}
+		if r.contractAPI == nil {
+			// A direct call to a contract is matched against the contract API for the same contract
+			r.contractAPI = &resource{}
+			r.contractCall = input
+		}
+	case *core.TokenTransferInput:
+		r.tokenPool = &resource{ref: input.Pool}
+	case *core.TokenApprovalInput:
+		r.tokenPool = &resource{ref: input.Pool}
+	case *core.TokenPoolInput:
+		// A new pool is matched by the name it is being created with
+		r.tokenPool = &resource{ref: input.Name, name: input.Name, resolved: input.Name != ""}
+	}
+	return r
+}
+
+// resolve looks up the token pool and contract API of the request, so they can be matched by their
+// canonical name or ID. Anything that cannot be resolved is left unresolved, rather than failing the request.
+func (r *request) resolve(ctx context.Context, resolver core.AuthResourceResolver) error {
+	if resolver == nil {
+		return nil
+	}
+	if r.tokenPool != nil && !r.tokenPool.resolved {
+		pool, err := resolver.ResolveTokenPool(ctx, r.tokenPool.ref)
+		if err != nil {
+			return err
+		}
+		if pool != nil {
+			r.tokenPool.name, r.tokenPool.id, r.tokenPool.resolved = pool.Name, pool.ID.String(), true
+		}
+	}
+	if r.contractAPI != nil {
+		var api *core.ContractAPI
+		var err error
+		if r.contractCall != nil {
+			api, err = resolver.ResolveContractAPIForCall(ctx, r.contractCall)
+		} else {
+			api, err = resolver.ResolveContractAPI(ctx, r.contractAPI.ref)
+		}
+		if err != nil {
+			return err
+		}
+		if api != nil {
+			r.contractAPI.name, r.contractAPI.id, r.contractAPI.resolved = api.Name, api.ID.String(), true
+		}
+	}
+	return nil
+}
+
+// IsRouteGroup returns true if the supplied name is one of the route groups
+func IsRouteGroup(name string) bool {
+	return validGroups[name]
+}
+
+// RouteGroup returns the group of the API route with the supplied URL path
+func RouteGroup(path string) string {
+	return groupOf(resourceSegments(path))
+}
+
+// resourceSegments returns the path segments of a namespaced API route from the resource onwards,
+// or nil for the SPI and anything else outside of the namespaced API
+func resourceSegments(path string) []string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 3 || segments[0] != "api" {
+		return nil
+	}
+	segments = segments[2:] // the API version
+	if segments[0] == "namespaces" && len(segments) > 2 {
+		segments = segments[2:]
+	}
+	return segments
+}
+
+func groupOf(segments []string) string {
+	if segments != nil {
+		if group, ok := resourceGroups[segments[0]]; ok {
+			return group
+		}
+	}
+	return GroupAdmin
+}
@@ -0,0 +1,117 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/coremocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func classifyTestRequest(method, path string, input interface{}) *request {
+	ctx := core.WithAuthRequestInfo(context.Background(), &core.AuthRequestInfo{Input: input})
+	return classifyRequest(ctx, &fftypes.AuthReq{
+		Method:    method,
+		URL:       &url.URL{Path: path},
+		Namespace: "ns1",
+	})
+}
+
+func TestClassifyRequestGroups(t *testing.T) {
+	for path, group := range map[string]string{
+		"/api/v1/messages/broadcast":                GroupMessages,
+		"/api/v1/namespaces/ns1/data":               GroupMessages,
+		"/api/v1/namespaces/ns1/tokens/transfers":   GroupTokens,
+		"/api/v1/namespaces/ns1/contracts/invoke":   GroupContracts,
+		"/api/v1/namespaces/ns1/subscriptions":      GroupSubscriptions,
+		"/api/v1/namespaces/ns1/identities":         GroupIdentities,
+		"/api/v1/namespaces/ns1/transactions":       GroupTransactions,
+		"/api/v1/namespaces/ns1/status":             GroupAdmin,
+		"/api/v1/namespaces/ns1":                    GroupAdmin,
+		"/spi/v1/namespaces/ns1/operations/op1":     GroupAdmin,
+		"/api/v1/namespaces/ns1/network/nodes/self": GroupAdmin,
+	} {
+		r := classifyTestRequest(http.MethodPost, path, nil)
+		assert.Equal(t, group, r.group, path)
+		assert.Equal(t, group, RouteGroup(path), path)
+		assert.True(t, IsRouteGroup(group))
+		assert.Equal(t, "ns1", r.namespace)
+		assert.Equal(t, http.MethodPost, r.method)
+	}
+}
+
+func TestClassifyRequestGraphQL(t *testing.T) {
+	r := classifyTestRequest(http.MethodPost, "/api/v1/namespaces/ns1/graphql", &core.GraphQLRequest{})
+	assert.Equal(t, GroupGraphQL, r.group)
+	assert.Equal(t, GroupGraphQL, RouteGroup("/api/v1/namespaces/ns1/graphql"))
+	assert.True(t, IsRouteGroup(GroupGraphQL))
+	assert.Equal(t, http.MethodGet, r.method)
+}
+
+func TestClassifyRequestContracts(t *testing.T) {
+	r := classifyTestRequest(http.MethodPost, "/api/v1/namespaces/ns1/apis/api1/query/get", &core.ContractCallRequest{MethodPath: "ignored"})
+	assert.Equal(t, &resource{ref: "api1"}, r.contractAPI)
+	assert.Equal(t, "get", r.contractMethod)
+	assert.Nil(t, r.contractCall)
+
+	r = classifyTestRequest(http.MethodGet, "/api/v1/namespaces/ns1/apis/api1", nil)
+	assert.Equal(t, &resource{ref: "api1"}, r.contractAPI)
+	assert.Empty(t, r.contractMethod)
+
+	call := &core.ContractCallRequest{MethodPath: "set"}
+	r = classifyTestRequest(http.MethodPost, "/api/v1/namespaces/ns1/contracts/invoke", call)
+	assert.Equal(t, &resource{}, r.contractAPI)
+	assert.Equal(t, call, r.contractCall)
+	assert.Equal(t, "set", r.contractMethod)
+
+	r = classifyTestRequest(http.MethodPost, "/api/v1/namespaces/ns1/contracts/invoke", &core.ContractCallRequest{Method: &fftypes.FFIMethod{Name: "set"}})
+	assert.Equal(t, "set", r.contractMethod)
+}
+
+func TestClassifyRequestTokenPools(t *testing.T) {
+	r := classifyTestRequest(http.MethodPost, "/api/v1/namespaces/ns1/tokens/approvals", &core.TokenApprovalInput{Pool: "pool1"})
+	assert.Equal(t, &resource{ref: "pool1"}, r.tokenPool)
+
+	r = classifyTestRequest(http.MethodPost, "/api/v1/namespaces/ns1/tokens/pools", &core.TokenPoolInput{TokenPool: core.TokenPool{Name: "pool2"}})
+	assert.Equal(t, &resource{ref: "pool2", name: "pool2", resolved: true}, r.tokenPool)
+
+	r = classifyTestRequest(http.MethodGet, "/api/v1/namespaces/ns1/tokens/pools/pool3", nil)
+	assert.Equal(t, &resource{ref: "pool3"}, r.tokenPool)
+}
+
+func TestResourceString(t *testing.T) {
+	var res *resource
+	assert.Empty(t, res.String())
+	assert.Equal(t, "pool1(unresolved)", (&resource{ref: "pool1"}).String())
+	assert.Equal(t, "pool1", (&resource{ref: "id1", name: "pool1", resolved: true}).String())
+}
+
+func TestResolveRequestContractAPIFail(t *testing.T) {
+	res := &coremocks.AuthResourceResolver{}
+	res.On("ResolveContractAPI", mock.Anything, "api1").Return(nil, fmt.Errorf("pop"))
+	r := classifyTestRequest(http.MethodGet, "/api/v1/namespaces/ns1/apis/api1", nil)
+	assert.Regexp(t, "pop", r.resolve(context.Background(), res))
+	res.AssertExpectations(t)
+}
@@ -65,6 +65,9 @@ func NewBatchManager(ctx context.Context, ns string, di database.Plugin, dm data
 		shoulderTap:                make(chan bool, 1),
 		rewindOffset:               -1,
 		done:                       make(chan struct{}),
+		priorityLanes:              readPriorityLaneOptions(),
+		priorityGate:               newPriorityGate(),
+		sealLocks:                  make(map[string]*sync.Mutex),
 		retry: &retry.Retry{
 			InitialDelay: config.GetDuration(coreconfig.BatchRetryInitDelay),
 			MaximumDelay: config.GetDuration(coreconfig.BatchRetryMaxDelay),
@@ -120,6 +123,10 @@ type batchManager struct {
 	minimumPollDelay           time.Duration
 	messagePollTimeout         time.Duration
 	startupOffsetRetryAttempts int
+	priorityLanes              map[core.MessagePriority]*priorityLaneOptions
+	priorityGate               *priorityGate
+	sealLocksMux               sync.Mutex
+	sealLocks                  map[string]*sync.Mutex
 }
 
 type DispatchHandler func(context.Context, *DispatchPayload) error
@@ -139,8 +146,8 @@ type dispatcher struct {
 	options    DispatcherOptions
 }
 
-func (bm *batchManager) getProcessorKey(author string, groupID *fftypes.Bytes32) string {
-	return fmt.Sprintf("%s|%v", author, groupID)
+func (bm *batchManager) getProcessorKey(author string, groupID *fftypes.Bytes32, priority core.MessagePriority) string {
+	return fmt.Sprintf("%s|%v|%s", author, groupID, priority)
 }
 
 func (bm *batchManager) getDispatcherKey(pinned bool, msgType core.MessageType) string {
@@ -178,7 +185,7 @@ func (bm *batchManager) NewMessages() chan<- int64 {
 	return bm.newMessages
 }
 
-func (bm *batchManager) getProcessor(txType core.TransactionType, msgType core.MessageType, group *fftypes.Bytes32, author string, create bool) (*batchProcessor, error) {
+func (bm *batchManager) getProcessor(txType core.TransactionType, msgType core.MessageType, group *fftypes.Bytes32, author string, priority core.MessagePriority, create bool) (*batchProcessor, error) {
 	bm.dispatcherMux.Lock()
 	defer bm.dispatcherMux.Unlock()
 
@@ -188,15 +195,19 @@ func (bm *batchManager) getProcessor(txType core.TransactionType, msgType core.M
 	if !ok {
 		return nil, i18n.NewError(bm.ctx, coremsgs.MsgUnregisteredBatchType, dispatcherKey)
 	}
-	name := bm.getProcessorKey(author, group)
+	if priority == "" {
+		priority = core.MessagePriorityNormal
+	}
+	name := bm.getProcessorKey(author, group, priority)
 	processor, ok := dispatcher.processors[name]
 	if !ok && create {
 		processor = newBatchProcessor(
 			bm,
 			&batchProcessorConf{
-				DispatcherOptions: dispatcher.options,
+				DispatcherOptions: bm.getPriorityOptions(dispatcher.options, priority),
 				name:              name,
 				pinned:            pinned,
+				priority:          priority,
 				dispatcherName:    dispatcher.name,
 				author:            author,
 				group:             group,
@@ -327,7 +338,13 @@ func (bm *batchManager) messageSequencer() {
 				// the database store. Meaning we cannot rely on the sequence having been set.
 				msg.Sequence = entry.Sequence
 
-				processor, err := bm.getProcessor(msg.Header.TxType, msg.Header.Type, msg.Header.Group, msg.Header.SignerRef.Author, true)
+				// Expired messages are never dispatched - the aggregator marks them cancelled
+				if msg.Header.Expired(fftypes.Now()) {
+					l.Warnf("Skipping message %s (seq=%d) that expired at %s", msg.Header.ID, entry.Sequence, msg.Header.Expiry)
+					continue
+				}
+
+				processor, err := bm.getProcessor(msg.Header.TxType, msg.Header.Type, msg.Header.Group, msg.Header.SignerRef.Author, msg.Priority, true)
 				if err != nil {
 					l.Errorf("Failed to dispatch message %s: %s", msg.Header.ID, err)
 					continue
@@ -622,13 +639,23 @@ func (bm *batchManager) CancelBatch(ctx context.Context, batchID string) error {
 	if len(batch.Payload.Messages) == 0 {
 		return i18n.NewError(ctx, coremsgs.MsgErrorLoadingBatch)
 	}
+	// The priority is not transferred in the batch, so we check the processor for each priority
 	msg := batch.Payload.Messages[0]
-	processor, err := bm.getProcessor(msg.Header.TxType, msg.Header.Type, msg.Header.Group, msg.Header.SignerRef.Author, false)
-	if err != nil {
-		return err
+	var found *batchProcessor
+	for _, priority := range messagePriorities {
+		processor, err := bm.getProcessor(msg.Header.TxType, msg.Header.Type, msg.Header.Group, msg.Header.SignerRef.Author, priority, false)
+		if err != nil {
+			return err
+		}
+		if processor != nil {
+			found = processor
+			if processor.isFlushing(id) {
+				break
+			}
+		}
 	}
-	if processor == nil {
+	if found == nil {
 		return i18n.NewError(ctx, coremsgs.MsgBatchNotDispatching, batchID, nil)
 	}
-	return processor.cancelFlush(ctx, id)
+	return found.cancelFlush(ctx, id)
 }
@@ -146,7 +146,7 @@ func TestE2EDispatchBroadcast(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, fmt.Sprintf("( id IN ['%s'] ) && ( state == 'ready' )", msg.Header.ID.String()), fi.String())
 		return true
-	}), mock.Anything).Return(nil)
+	}), mock.Anything).Return(int64(1), nil)
 	mdi.On("InsertTransaction", mock.Anything, mock.Anything).Return(nil)
 	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil) // transaction submit
 
@@ -268,7 +268,7 @@ func TestE2EDispatchPrivateUnpinned(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, fmt.Sprintf("( id IN ['%s'] ) && ( state == 'ready' )", msg.Header.ID.String()), fi.String())
 		return true
-	}), mock.Anything).Return(nil)
+	}), mock.Anything).Return(int64(1), nil)
 	mdi.On("GetNonce", mock.Anything, mock.Anything).Return(&core.Nonce{
 		Nonce: int64(12344),
 	}, nil).Twice()
@@ -339,7 +339,7 @@ func TestGetInvalidBatchTypeMsg(t *testing.T) {
 	txHelper, _ := txcommon.NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
 	bm, _ := NewBatchManager(context.Background(), "ns1", mdi, mdm, mim, txHelper)
 	defer bm.Close()
-	_, err := bm.(*batchManager).getProcessor(core.BatchTypeBroadcast, "wrong", nil, "", true)
+	_, err := bm.(*batchManager).getProcessor(core.BatchTypeBroadcast, "wrong", nil, "", core.MessagePriorityNormal, true)
 	assert.Regexp(t, "FF10126", err)
 }
 
@@ -406,6 +406,52 @@ func TestMessageSequencerMissingMessageData(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestMessageSequencerExpiredMessage(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	mim := &identitymanagermocks.Manager{}
+	ctx := context.Background()
+	cmi := &cachemocks.Manager{}
+	cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(ctx, 100, 5*time.Minute), nil)
+	txHelper, _ := txcommon.NewTransactionHelper(ctx, "ns1", mdi, mdm, cmi)
+	bm, _ := NewBatchManager(context.Background(), "ns1", mdi, mdm, mim, txHelper)
+	bm.RegisterDispatcher("utdispatcher", false, []core.MessageType{core.MessageTypeBroadcast},
+		func(c context.Context, state *DispatchPayload) error {
+			return nil
+		},
+		DispatcherOptions{BatchType: core.BatchTypeBroadcast},
+	)
+
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      core.MessageTypeBroadcast,
+			Namespace: "ns1",
+			TxType:    core.TransactionTypeBatchPin,
+			Expiry:    fftypes.Now(),
+		},
+	}
+
+	mdi.On("GetMessageIDs", mock.Anything, "ns1", mock.Anything).
+		Return([]*core.IDAndSequence{{ID: *msg.Header.ID, Sequence: 12345}}, nil, nil).
+		Run(func(args mock.Arguments) {
+			bm.Close()
+		}).
+		Once()
+	mdi.On("GetMessageIDs", mock.Anything, "ns1", mock.Anything).Return([]*core.IDAndSequence{}, nil, nil)
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(msg, core.DataArray{}, true, nil)
+
+	bm.(*batchManager).messageSequencer()
+
+	bm.WaitStop()
+
+	assert.Empty(t, bm.(*batchManager).inflightSequences)
+	assert.Equal(t, int64(12345), bm.(*batchManager).readOffset)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
 func TestMessageSequencerUpdateMessagesFail(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
@@ -442,7 +488,7 @@ func TestMessageSequencerUpdateMessagesFail(t *testing.T) {
 	mdi.On("InsertTransaction", mock.Anything, mock.Anything).Return(nil)
 	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil) // transaction submit
 	mdi.On("InsertOrGetBatch", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
-	mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("fizzle"))
+	mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(int64(0), fmt.Errorf("fizzle"))
 	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything, mock.Anything)
 	rag.RunFn = func(a mock.Arguments) {
 		ctx := a.Get(0).(context.Context)
@@ -975,7 +1021,7 @@ func TestCancelBatch(t *testing.T) {
 		DispatcherOptions{BatchType: core.BatchTypePrivate},
 	)
 	group := fftypes.NewRandB32()
-	_, err := bm.getProcessor(core.TransactionTypeContractInvokePin, core.MessageTypePrivate, group, "did:firefly:org/abcd", true)
+	_, err := bm.getProcessor(core.TransactionTypeContractInvokePin, core.MessageTypePrivate, group, "did:firefly:org/abcd", core.MessagePriorityNormal, true)
 	assert.NoError(t, err)
 
 	batchID := fftypes.NewUUID()
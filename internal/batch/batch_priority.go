@@ -0,0 +1,152 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// messagePriorities are the priority lanes, in order of decreasing priority
+var messagePriorities = []core.MessagePriority{
+	core.MessagePriorityHigh,
+	core.MessagePriorityNormal,
+	core.MessagePriorityLow,
+}
+
+// priorityRank returns a higher number for a higher priority. Messages stored before priorities
+// were introduced, and messages received from other members, have no priority and are normal.
+func priorityRank(priority core.MessagePriority) int {
+	switch priority {
+	case core.MessagePriorityHigh:
+		return 2
+	case core.MessagePriorityLow:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// priorityLaneOptions are the batch size and timeout overrides for a priority lane.
+// Zero values mean the options of the dispatcher are used.
+type priorityLaneOptions struct {
+	batchMaxSize uint
+	batchTimeout time.Duration
+}
+
+func readPriorityLaneOptions() map[core.MessagePriority]*priorityLaneOptions {
+	return map[core.MessagePriority]*priorityLaneOptions{
+		core.MessagePriorityHigh: {
+			batchMaxSize: config.GetUint(coreconfig.BatchPriorityHighSize),
+			batchTimeout: config.GetDuration(coreconfig.BatchPriorityHighTimeout),
+		},
+		core.MessagePriorityLow: {
+			batchMaxSize: config.GetUint(coreconfig.BatchPriorityLowSize),
+			batchTimeout: config.GetDuration(coreconfig.BatchPriorityLowTimeout),
+		},
+	}
+}
+
+func (bm *batchManager) getPriorityOptions(options DispatcherOptions, priority core.MessagePriority) DispatcherOptions {
+	if lane, ok := bm.priorityLanes[priority]; ok {
+		if lane.batchMaxSize > 0 {
+			options.BatchMaxSize = lane.batchMaxSize
+		}
+		if lane.batchTimeout > 0 {
+			options.BatchTimeout = lane.batchTimeout
+		}
+	}
+	return options
+}
+
+// getSealLock returns the lock shared by the processors of all the priority lanes for an author and group.
+// Sealing a pinned batch reads and increments the nonces for the contexts of the author in the group, so
+// two lanes sealing at the same time could otherwise assign the same nonce.
+func (bm *batchManager) getSealLock(author string, group *fftypes.Bytes32) *sync.Mutex {
+	bm.sealLocksMux.Lock()
+	defer bm.sealLocksMux.Unlock()
+	key := fmt.Sprintf("%s|%v", author, group)
+	lock, ok := bm.sealLocks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		bm.sealLocks[key] = lock
+	}
+	return lock
+}
+
+// priorityGate holds back the pinned dispatch of lower priority batches, while a higher priority
+// batch is being retried after a failed dispatch - such as when the blockchain connector is applying
+// back-pressure. So when the connector recovers, the higher priority batches are pinned first.
+type priorityGate struct {
+	mux      sync.Mutex
+	retrying map[core.MessagePriority]int
+	changed  chan struct{}
+}
+
+func newPriorityGate() *priorityGate {
+	return &priorityGate{
+		retrying: make(map[core.MessagePriority]int),
+		changed:  make(chan struct{}),
+	}
+}
+
+func (pg *priorityGate) setRetrying(priority core.MessagePriority, retrying bool) {
+	pg.mux.Lock()
+	defer pg.mux.Unlock()
+	if retrying {
+		pg.retrying[priority]++
+	} else {
+		pg.retrying[priority]--
+	}
+	close(pg.changed)
+	pg.changed = make(chan struct{})
+}
+
+func (pg *priorityGate) blocked(priority core.MessagePriority) (bool, chan struct{}) {
+	pg.mux.Lock()
+	defer pg.mux.Unlock()
+	for other, count := range pg.retrying {
+		if count > 0 && priorityRank(other) > priorityRank(priority) {
+			return true, pg.changed
+		}
+	}
+	return false, nil
+}
+
+// wait blocks until there are no batches of a higher priority retrying dispatch
+func (pg *priorityGate) wait(ctx context.Context, priority core.MessagePriority) error {
+	for {
+		blocked, changed := pg.blocked(priority)
+		if !blocked {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return i18n.NewError(ctx, coremsgs.MsgContextCanceled)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPriorityRank(t *testing.T) {
+	assert.Greater(t, priorityRank(core.MessagePriorityHigh), priorityRank(core.MessagePriorityNormal))
+	assert.Greater(t, priorityRank(core.MessagePriorityNormal), priorityRank(core.MessagePriorityLow))
+	assert.Equal(t, priorityRank(core.MessagePriorityNormal), priorityRank(""))
+}
+
+func TestPriorityLaneProcessors(t *testing.T) {
+	testConfigReset()
+	config.Set(coreconfig.BatchPriorityHighSize, 1)
+	config.Set(coreconfig.BatchPriorityHighTimeout, "10ms")
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	bm.RegisterDispatcher("utdispatcher", true, []core.MessageType{core.MessageTypeBroadcast},
+		func(c context.Context, state *DispatchPayload) error {
+			return nil
+		},
+		DispatcherOptions{BatchType: core.BatchTypeBroadcast, BatchMaxSize: 100, BatchTimeout: time.Second},
+	)
+
+	high, err := bm.getProcessor(core.TransactionTypeBatchPin, core.MessageTypeBroadcast, nil, "org1", core.MessagePriorityHigh, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "org1||high", high.conf.name)
+	assert.Equal(t, uint(1), high.conf.BatchMaxSize)
+	assert.Equal(t, 10*time.Millisecond, high.conf.BatchTimeout)
+
+	normal, err := bm.getProcessor(core.TransactionTypeBatchPin, core.MessageTypeBroadcast, nil, "org1", "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "org1||normal", normal.conf.name)
+	assert.Equal(t, uint(100), normal.conf.BatchMaxSize)
+	assert.Equal(t, time.Second, normal.conf.BatchTimeout)
+
+	low, err := bm.getProcessor(core.TransactionTypeBatchPin, core.MessageTypeBroadcast, nil, "org1", core.MessagePriorityLow, true)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(100), low.conf.BatchMaxSize)
+	assert.Equal(t, time.Second, low.conf.BatchTimeout)
+	assert.NotEqual(t, high, normal)
+	assert.NotEqual(t, normal, low)
+}
+
+func TestPriorityLanesShareSealLock(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	bm.RegisterDispatcher("utdispatcher", true, []core.MessageType{core.MessageTypePrivate},
+		func(c context.Context, state *DispatchPayload) error {
+			return nil
+		},
+		DispatcherOptions{BatchType: core.BatchTypePrivate, BatchMaxSize: 100, BatchTimeout: time.Second},
+	)
+
+	group1 := fftypes.NewRandB32()
+	group2 := fftypes.NewRandB32()
+	high, err := bm.getProcessor(core.TransactionTypeBatchPin, core.MessageTypePrivate, group1, "org1", core.MessagePriorityHigh, true)
+	assert.NoError(t, err)
+	low, err := bm.getProcessor(core.TransactionTypeBatchPin, core.MessageTypePrivate, group1, "org1", core.MessagePriorityLow, true)
+	assert.NoError(t, err)
+	otherGroup, err := bm.getProcessor(core.TransactionTypeBatchPin, core.MessageTypePrivate, group2, "org1", core.MessagePriorityLow, true)
+	assert.NoError(t, err)
+	otherAuthor, err := bm.getProcessor(core.TransactionTypeBatchPin, core.MessageTypePrivate, group1, "org2", core.MessagePriorityLow, true)
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, high, low)
+	assert.Same(t, high.sealLock, low.sealLock)
+	assert.NotSame(t, high.sealLock, otherGroup.sealLock)
+	assert.NotSame(t, high.sealLock, otherAuthor.sealLock)
+}
+
+func TestSealBatchWaitsForSealLock(t *testing.T) {
+	cancel, mdi, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchPayload) error {
+		return nil
+	})
+	defer cancel()
+	bp.cancelCtx()
+
+	sealing := make(chan struct{})
+	mdi.On("RunAsGroup", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		close(sealing)
+	}).Return(fmt.Errorf("pop"))
+
+	bp.sealLock.Lock()
+	sealed := make(chan error)
+	go func() {
+		sealed <- bp.sealBatch(&DispatchPayload{
+			Batch: core.BatchPersisted{TX: core.TransactionRef{Type: core.TransactionTypeBatchPin}},
+		})
+	}()
+
+	select {
+	case <-sealing:
+		assert.Fail(t, "sealed while another lane held the lock")
+	case <-time.After(10 * time.Millisecond):
+	}
+	bp.sealLock.Unlock()
+	<-sealing
+	assert.Regexp(t, "FF00154", <-sealed)
+}
+
+func TestPriorityGateBlocksLowerPriority(t *testing.T) {
+	pg := newPriorityGate()
+	ctx := context.Background()
+
+	pg.setRetrying(core.MessagePriorityNormal, true)
+	assert.NoError(t, pg.wait(ctx, core.MessagePriorityHigh))
+	assert.NoError(t, pg.wait(ctx, core.MessagePriorityNormal))
+
+	waited := make(chan error)
+	go func() {
+		waited <- pg.wait(ctx, core.MessagePriorityLow)
+	}()
+	select {
+	case <-waited:
+		assert.Fail(t, "low priority was not blocked")
+	case <-time.After(10 * time.Millisecond):
+	}
+	pg.setRetrying(core.MessagePriorityNormal, false)
+	assert.NoError(t, <-waited)
+}
+
+func TestPriorityGateWaitCancelled(t *testing.T) {
+	pg := newPriorityGate()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pg.setRetrying(core.MessagePriorityHigh, true)
+	err := pg.wait(ctx, core.MessagePriorityLow)
+	assert.Regexp(t, "FF00154", err)
+}
+
+func TestDispatchBatchRetryingHoldsBackLowerPriority(t *testing.T) {
+	attempts := 0
+	var bp *batchProcessor
+	cancel, _, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchPayload) error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("pop")
+		}
+		blocked, _ := bp.bm.priorityGate.blocked(core.MessagePriorityLow)
+		assert.True(t, blocked)
+		return nil
+	})
+	defer cancel()
+
+	err := bp.dispatchBatch(&DispatchPayload{Batch: core.BatchPersisted{TX: core.TransactionRef{Type: core.TransactionTypeBatchPin}}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	blocked, _ := bp.bm.priorityGate.blocked(core.MessagePriorityLow)
+	assert.False(t, blocked)
+}
+
+func TestDispatchBatchWaitsForHigherPriority(t *testing.T) {
+	cancel, _, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchPayload) error {
+		return nil
+	})
+	defer cancel()
+
+	bp.bm.priorityGate.setRetrying(core.MessagePriorityHigh, true)
+	bp.cancelCtx()
+	err := bp.dispatchBatch(&DispatchPayload{})
+	assert.Regexp(t, "FF00154", err)
+}
+
+func TestCancelBatchOtherPriority(t *testing.T) {
+	bm, cancel := newTestBatchManager(t)
+	defer cancel()
+
+	bm.RegisterDispatcher("utdispatcher", true, []core.MessageType{core.MessageTypePrivate},
+		func(c context.Context, state *DispatchPayload) error {
+			return nil
+		},
+		DispatcherOptions{BatchType: core.BatchTypePrivate},
+	)
+	group := fftypes.NewRandB32()
+	_, err := bm.getProcessor(core.TransactionTypeContractInvokePin, core.MessageTypePrivate, group, "did:firefly:org/abcd", core.MessagePriorityHigh, true)
+	assert.NoError(t, err)
+	low, err := bm.getProcessor(core.TransactionTypeContractInvokePin, core.MessageTypePrivate, group, "did:firefly:org/abcd", core.MessagePriorityLow, true)
+	assert.NoError(t, err)
+
+	batchID := fftypes.NewUUID()
+	low.flushStatus.Flushing = batchID
+	bp := &core.BatchPersisted{
+		BatchHeader: core.BatchHeader{
+			ID: batchID,
+		},
+		TX: core.TransactionRef{
+			Type: core.TransactionTypeContractInvokePin,
+		},
+	}
+	batch := &core.Batch{
+		BatchHeader: bp.BatchHeader,
+		Payload: core.BatchPayload{
+			Messages: []*core.Message{{
+				Header: core.MessageHeader{
+					Type:      core.MessageTypePrivate,
+					TxType:    core.TransactionTypeContractInvokePin,
+					Group:     group,
+					SignerRef: core.SignerRef{Author: "did:firefly:org/abcd"},
+				},
+			}},
+		},
+	}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdi.On("GetBatchByID", context.Background(), "ns1", batchID).Return(bp, nil)
+	mdm.On("HydrateBatch", context.Background(), bp).Return(batch, nil)
+
+	err = bm.CancelBatch(context.Background(), batchID.String())
+	assert.NoError(t, err)
+	assert.True(t, low.isCancelled())
+}
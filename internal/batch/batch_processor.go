@@ -45,6 +45,7 @@ type batchProcessorConf struct {
 	name           string
 	dispatcherName string
 	pinned         bool
+	priority       core.MessagePriority
 	author         string
 	group          *fftypes.Bytes32
 	dispatch       DispatchHandler
@@ -89,6 +90,7 @@ type batchProcessor struct {
 	flushStatus        FlushStatus
 	retry              *retry.Retry
 	conf               *batchProcessorConf
+	sealLock           *sync.Mutex
 }
 
 type nonceState struct {
@@ -148,7 +150,8 @@ func newBatchProcessor(bm *batchManager, conf *batchProcessorConf, baseRetryConf
 			MaximumDelay: baseRetryConf.MaximumDelay,
 			Factor:       baseRetryConf.Factor,
 		},
-		conf: conf,
+		conf:     conf,
+		sealLock: bm.getSealLock(conf.author, conf.group),
 		flushStatus: FlushStatus{
 			LastFlushTime: fftypes.Now(),
 		},
@@ -313,6 +316,12 @@ func (bp *batchProcessor) cancelFlush(ctx context.Context, id *fftypes.UUID) err
 	return nil
 }
 
+func (bp *batchProcessor) isFlushing(id *fftypes.UUID) bool {
+	bp.statusMux.Lock()
+	defer bp.statusMux.Unlock()
+	return id.Equals(bp.flushStatus.Flushing)
+}
+
 func (bp *batchProcessor) isCancelled() bool {
 	bp.statusMux.Lock()
 	defer bp.statusMux.Unlock()
@@ -401,7 +410,20 @@ func (bp *batchProcessor) assemblyLoop() {
 func (bp *batchProcessor) flush(overflow bool) error {
 	id, flushWork, byteSize := bp.startFlush(overflow)
 
+	// Messages can expire while they wait in the assembly queue
+	flushWork = bp.removeExpired(flushWork)
+	if len(flushWork) == 0 {
+		log.L(bp.ctx).Debugf("Discarding batch %s as all messages expired", id)
+		bp.statusMux.Lock()
+		bp.flushStatus.Flushing = nil
+		bp.statusMux.Unlock()
+		return nil
+	}
+
 	log.L(bp.ctx).Debugf("Flushing batch %s", id)
+	if err := bp.loadLargeValues(flushWork); err != nil {
+		return err
+	}
 	state := bp.initPayload(id, flushWork)
 
 	// Sealing phase: assigns persisted pins to messages, and finalizes the manifest
@@ -436,6 +458,47 @@ func (bp *batchProcessor) flush(overflow bool) error {
 	return nil
 }
 
+// loadLargeValues reads in any values held in the blob store, as the full values are sent in the batch.
+// The work is updated with copies of the data, so the full values are not retained in the message cache.
+func (bp *batchProcessor) loadLargeValues(flushWork []*batchWork) error {
+	for _, w := range flushWork {
+		for _, d := range w.data {
+			if d.ValueRef != nil {
+				work := w
+				err := bp.retry.Do(bp.ctx, "load large values", func(attempt int) (retry bool, err error) {
+					work.data, err = bp.data.LoadLargeValues(bp.ctx, work.data)
+					return true, err
+				})
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// removeExpired returns the work that has not expired, and notifies the manager that the expired
+// work is complete, so it is not held in-flight
+func (bp *batchProcessor) removeExpired(flushWork []*batchWork) []*batchWork {
+	now := fftypes.Now()
+	unexpired := make([]*batchWork, 0, len(flushWork))
+	var expired []int64
+	for _, work := range flushWork {
+		if work.msg.Header.Expired(now) {
+			log.L(bp.ctx).Warnf("Removing message %s (seq=%d) that expired at %s from batch", work.msg.Header.ID, work.msg.Sequence, work.msg.Header.Expiry)
+			expired = append(expired, work.msg.Sequence)
+		} else {
+			unexpired = append(unexpired, work)
+		}
+	}
+	if len(expired) > 0 {
+		bp.bm.notifyFlushed(expired)
+	}
+	return unexpired
+}
+
 func (bp *batchProcessor) initPayload(id *fftypes.UUID, flushWork []*batchWork) *DispatchPayload {
 	payload := &DispatchPayload{
 		Batch: core.BatchPersisted{
@@ -552,6 +615,10 @@ func (bp *batchProcessor) sealBatch(payload *DispatchPayload) (err error) {
 	txType := payload.Batch.TX.Type
 
 	err = bp.retry.Do(bp.ctx, "batch persist", func(attempt int) (retry bool, err error) {
+		// The processors for each priority lane of an author and group assign nonces from the same
+		// contexts, so only one of them can be sealing a batch at a time
+		bp.sealLock.Lock()
+		defer bp.sealLock.Unlock()
 		return true, bp.database.RunAsGroup(bp.ctx, func(ctx context.Context) (err error) {
 
 			// Clear state from any previous retry. We need to do fresh queries against the DB for nonces.
@@ -617,10 +684,28 @@ func (bp *batchProcessor) sealBatch(payload *DispatchPayload) (err error) {
 }
 
 func (bp *batchProcessor) dispatchBatch(payload *DispatchPayload) error {
+	// Pinned batches give way to higher priority batches that are retrying, and hold back lower
+	// priority batches while we are retrying
+	retrying := false
+	defer func() {
+		if retrying {
+			bp.bm.priorityGate.setRetrying(bp.conf.priority, false)
+		}
+	}()
+
 	// Call the dispatcher to do the heavy lifting - will only exit if we're closed
 	return operations.RunWithOperationContext(bp.ctx, func(ctx context.Context) error {
 		return bp.retry.Do(ctx, "batch dispatch", func(attempt int) (retry bool, err error) {
+			if bp.conf.pinned {
+				if err := bp.bm.priorityGate.wait(ctx, bp.conf.priority); err != nil {
+					return false, err
+				}
+			}
 			err = bp.conf.dispatch(ctx, payload)
+			if bp.conf.pinned && retrying != (err != nil) {
+				retrying = err != nil
+				bp.bm.priorityGate.setRetrying(bp.conf.priority, retrying)
+			}
 			if err != nil {
 				if bp.isCancelled() {
 					var gapFillPayload *DispatchPayload
@@ -732,7 +817,7 @@ func (bp *batchProcessor) markPayloadDispatched(payload *DispatchPayload) error
 				if state.toState == core.MessageStateConfirmed {
 					allMsgsUpdate.Set("confirmed", confirmTime)
 				}
-				if err = bp.database.UpdateMessages(ctx, bp.bm.namespace, filter, allMsgsUpdate); err != nil {
+				if _, err = bp.database.UpdateMessages(ctx, bp.bm.namespace, filter, allMsgsUpdate); err != nil {
 					return err
 				}
 
@@ -99,7 +99,7 @@ func TestUnfilledBatch(t *testing.T) {
 	defer cancel()
 
 	mockRunAsGroupPassthrough(mdi)
-	mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
 	mdi.On("InsertOrGetBatch", mock.Anything, mock.Anything).Return(nil, nil)
 
 	mth := bp.txHelper.(*txcommonmocks.Helper)
@@ -141,6 +141,94 @@ func TestUnfilledBatch(t *testing.T) {
 	mim.AssertExpectations(t)
 }
 
+func TestFlushAllExpired(t *testing.T) {
+	cancel, _, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchPayload) error {
+		panic("should not be dispatched")
+	})
+	defer cancel()
+
+	bp.assemblyQueue = []*batchWork{
+		{msg: &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Expiry: fftypes.Now()}, Sequence: 1000}},
+		{msg: &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Expiry: fftypes.Now()}, Sequence: 1001}},
+	}
+	err := bp.flush(false)
+	assert.NoError(t, err)
+
+	assert.Nil(t, bp.flushStatus.Flushing)
+	assert.Equal(t, int64(0), bp.flushStatus.TotalBatches)
+	assert.Equal(t, []int64{1000, 1001}, bp.bm.inflightFlushed)
+	assert.Empty(t, bp.assemblyQueue)
+}
+
+func TestRemoveExpired(t *testing.T) {
+	cancel, _, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchPayload) error {
+		return nil
+	})
+	defer cancel()
+
+	expiry := fftypes.FFTime(time.Now().Add(1 * time.Hour))
+	unexpired := []*batchWork{
+		{msg: &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}, Sequence: 1000}},
+		{msg: &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Expiry: &expiry}, Sequence: 1002}},
+	}
+	flushWork := []*batchWork{
+		unexpired[0],
+		{msg: &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID(), Expiry: fftypes.Now()}, Sequence: 1001}},
+		unexpired[1],
+	}
+	assert.Equal(t, unexpired, bp.removeExpired(flushWork))
+	assert.Equal(t, []int64{1001}, bp.bm.inflightFlushed)
+
+	assert.Equal(t, unexpired, bp.removeExpired(unexpired))
+	assert.Equal(t, []int64{1001}, bp.bm.inflightFlushed)
+}
+
+func TestLoadLargeValues(t *testing.T) {
+	cancel, _, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchPayload) error {
+		return nil
+	})
+	defer cancel()
+
+	inline := core.DataArray{{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"small"`)}}
+	offloaded := core.DataArray{{ID: fftypes.NewUUID(), ValueRef: &core.DataValueRef{Size: 12345}}}
+	loaded := core.DataArray{{ID: offloaded[0].ID, Value: fftypes.JSONAnyPtr(`"large"`)}}
+	mdm := bp.data.(*datamocks.Manager)
+	mdm.On("LoadLargeValues", bp.ctx, offloaded).Return(loaded, nil).Once()
+
+	flushWork := []*batchWork{
+		{msg: &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}, data: inline},
+		{msg: &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}, data: offloaded},
+	}
+	err := bp.loadLargeValues(flushWork)
+	assert.NoError(t, err)
+	assert.Equal(t, inline, flushWork[0].data)
+	assert.Equal(t, loaded, flushWork[1].data)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestLoadLargeValuesClosed(t *testing.T) {
+	cancel, _, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchPayload) error {
+		return nil
+	})
+	defer cancel()
+	bp.cancelCtx()
+
+	mdm := bp.data.(*datamocks.Manager)
+	mdm.On("LoadLargeValues", bp.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	bp.assemblyQueue = []*batchWork{
+		{
+			msg:  &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}},
+			data: core.DataArray{{ID: fftypes.NewUUID(), ValueRef: &core.DataValueRef{Size: 12345}}},
+		},
+	}
+	err := bp.flush(false)
+	assert.Regexp(t, "FF00154", err)
+
+	mdm.AssertExpectations(t)
+}
+
 func TestHandleDispatchConflictError(t *testing.T) {
 	cancel, _, bp := newTestBatchProcessor(t, func(c context.Context, state *DispatchPayload) error {
 		conflictErr := testConflictError{err: fmt.Errorf("pop")}
@@ -164,7 +252,7 @@ func TestBatchSizeOverflow(t *testing.T) {
 	defer cancel()
 	bp.conf.BatchMaxBytes = batchSizeEstimateBase + (&core.Message{}).EstimateSize(false) + 100
 	mockRunAsGroupPassthrough(mdi)
-	mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
 	mdi.On("InsertOrGetBatch", mock.Anything, mock.Anything).Return(nil, nil)
 
 	mth := bp.txHelper.(*txcommonmocks.Helper)
@@ -563,7 +651,7 @@ func TestMarkMessageDispatchedUnpinnedOK(t *testing.T) {
 	defer cancel()
 
 	mockRunAsGroupPassthrough(mdi)
-	mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
 	mdi.On("InsertOrGetBatch", mock.Anything, mock.Anything).Return(nil, nil)
 	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Once()
 	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
@@ -962,7 +1050,7 @@ func TestCancelBatchPrivate(t *testing.T) {
 		assert.Equal(t, "state", info.SetOperations[2].Field)
 		val, _ := info.SetOperations[2].Value.Value()
 		return val == "cancelled"
-	})).Return(nil).Once()
+	})).Return(int64(1), nil).Once()
 
 	mdi.On("UpdateMessages", mock.Anything, "ns1", mock.MatchedBy(func(filter ffapi.AndFilter) bool {
 		info, err := filter.Finalize()
@@ -978,7 +1066,7 @@ func TestCancelBatchPrivate(t *testing.T) {
 		assert.Equal(t, "state", info.SetOperations[2].Field)
 		val, _ := info.SetOperations[2].Value.Value()
 		return val == "sent"
-	})).Return(nil).Once()
+	})).Return(int64(1), nil).Once()
 
 	// Race condition - may or may not finish this second message
 	mdi.On("UpdateMessage", mock.Anything, "ns1", msg2, mock.Anything).Return(nil).Maybe()
@@ -987,7 +1075,7 @@ func TestCancelBatchPrivate(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, info.Children, 2)
 		return info.Children[0].String() == "id IN ['"+msg2.String()+"']" && info.Children[1].String() == "state == 'ready'"
-	}), mock.Anything).Return(nil).Maybe()
+	}), mock.Anything).Return(int64(1), nil).Maybe()
 
 	mim := bp.bm.identity.(*identitymanagermocks.Manager)
 	mim.On("GetLocalNode", mock.Anything).Return(&core.Identity{}, nil)
@@ -1064,7 +1152,7 @@ func TestCancelBatchBroadcast(t *testing.T) {
 		assert.Equal(t, "state", info.SetOperations[2].Field)
 		val, _ := info.SetOperations[2].Value.Value()
 		return val == "cancelled"
-	})).Return(nil).Once()
+	})).Return(int64(1), nil).Once()
 	mdi.On("UpdateMessages", mock.Anything, "ns1", mock.MatchedBy(func(filter ffapi.AndFilter) bool {
 		info, err := filter.Finalize()
 		assert.NoError(t, err)
@@ -1079,7 +1167,7 @@ func TestCancelBatchBroadcast(t *testing.T) {
 		assert.Equal(t, "state", info.SetOperations[2].Field)
 		val, _ := info.SetOperations[2].Value.Value()
 		return val == "sent"
-	})).Return(nil).Maybe() // race condition - may or may not finish this second message
+	})).Return(int64(1), nil).Maybe() // race condition - may or may not finish this second message
 
 	mim := bp.bm.identity.(*identitymanagermocks.Manager)
 	mim.On("GetLocalNode", mock.Anything).Return(&core.Identity{}, nil)
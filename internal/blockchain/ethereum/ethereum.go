@@ -24,6 +24,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly-common/pkg/config"
@@ -38,6 +39,7 @@ import (
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/health"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
@@ -69,6 +71,8 @@ type Ethereum struct {
 	streams              *streamManager
 	streamID             map[string]string
 	wsconn               map[string]wsclient.WSClient
+	streamHealth         map[string]*health.StreamTracker
+	healthLock           sync.Mutex
 	wsConfig             *wsclient.WSConfig
 	closed               map[string]chan struct{}
 	addressResolveAlways bool
@@ -196,11 +200,35 @@ func (e *Ethereum) Init(ctx context.Context, cancelCtx context.CancelFunc, conf
 	e.streamID = make(map[string]string)
 	e.closed = make(map[string]chan struct{})
 	e.wsconn = make(map[string]wsclient.WSClient)
+	e.streamHealth = make(map[string]*health.StreamTracker)
 	e.streams = newStreamManager(e.client, e.cache, e.ethconnectConf.GetUint(EthconnectConfigBatchSize), uint(e.ethconnectConf.GetDuration(EthconnectConfigBatchTimeout).Milliseconds()))
 
 	return nil
 }
 
+func (e *Ethereum) CheckHealth(ctx context.Context, namespace string) (*core.EventStreamHealth, error) {
+	_, err := e.streams.getEventStreams(ctx)
+	return e.getStreamHealth(namespace).Status(), err
+}
+
+// getStreamHealth returns the health tracker for a namespace. Namespaces are started and stopped at runtime,
+// while CheckHealth is being called, so the map is guarded by healthLock.
+func (e *Ethereum) getStreamHealth(namespace string) *health.StreamTracker {
+	e.healthLock.Lock()
+	defer e.healthLock.Unlock()
+	return e.streamHealth[namespace]
+}
+
+func (e *Ethereum) setStreamHealth(namespace string, streamHealth *health.StreamTracker) {
+	e.healthLock.Lock()
+	defer e.healthLock.Unlock()
+	if streamHealth == nil {
+		delete(e.streamHealth, namespace)
+	} else {
+		e.streamHealth[namespace] = streamHealth
+	}
+}
+
 func (e *Ethereum) getTopic(namespace string) string {
 	return fmt.Sprintf("%s/%s", e.pluginTopic, namespace)
 }
@@ -209,7 +237,8 @@ func (e *Ethereum) StartNamespace(ctx context.Context, namespace string) (err er
 	log.L(e.ctx).Debugf("Starting namespace: %s", namespace)
 	topic := e.getTopic(namespace)
 
-	e.wsconn[namespace], err = wsclient.New(ctx, e.wsConfig, nil, func(ctx context.Context, w wsclient.WSClient) error {
+	streamHealth := health.NewStreamTracker()
+	e.wsconn[namespace], err = wsclient.New(ctx, e.wsConfig, streamHealth.BeforeConnect(nil), streamHealth.AfterConnect(func(ctx context.Context, w wsclient.WSClient) error {
 		// Send a subscribe to our topic after each connect/reconnect
 		b, _ := json.Marshal(&ethWSCommandPayload{
 			Type:  "listen",
@@ -223,7 +252,7 @@ func (e *Ethereum) StartNamespace(ctx context.Context, namespace string) (err er
 			err = w.Send(ctx, b)
 		}
 		return err
-	})
+	}))
 	if err != nil {
 		return err
 	}
@@ -234,6 +263,7 @@ func (e *Ethereum) StartNamespace(ctx context.Context, namespace string) (err er
 	}
 	log.L(e.ctx).Infof("Event stream: %s (topic=%s)", stream.ID, topic)
 	e.streamID[namespace] = stream.ID
+	e.setStreamHealth(namespace, streamHealth)
 
 	err = e.wsconn[namespace].Connect()
 	if err != nil {
@@ -254,6 +284,7 @@ func (e *Ethereum) StopNamespace(ctx context.Context, namespace string) (err err
 	}
 	delete(e.wsconn, namespace)
 	delete(e.streamID, namespace)
+	e.setStreamHealth(namespace, nil)
 	delete(e.closed, namespace)
 
 	return nil
@@ -454,6 +485,7 @@ func (e *Ethereum) handleMessageBatch(ctx context.Context, batchID int64, messag
 
 func (e *Ethereum) eventLoop(namespace string, wsconn wsclient.WSClient, closed chan struct{}) {
 	topic := e.getTopic(namespace)
+	streamHealth := e.getStreamHealth(namespace)
 	defer wsconn.Close()
 	defer close(closed)
 	l := log.L(e.ctx).WithField("role", "event-loop").WithField("namespace", namespace)
@@ -470,6 +502,7 @@ func (e *Ethereum) eventLoop(namespace string, wsconn wsclient.WSClient, closed
 				e.cancelCtx()
 				return
 			}
+			streamHealth.EventReceived()
 
 			var msgParsed interface{}
 			err := json.Unmarshal(msgBytes, &msgParsed)
@@ -394,10 +394,28 @@ func TestStartStopNamespace(t *testing.T) {
 
 	<-toServer
 
+	streamHealth, err := e.CheckHealth(e.ctx, "ns1")
+	assert.NoError(t, err)
+	assert.NotNil(t, streamHealth)
+
 	err = e.StopNamespace(e.ctx, "ns1")
 	assert.NoError(t, err)
 }
 
+func TestCheckHealthFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams",
+		httpmock.NewStringResponder(500, "pop"))
+
+	streamHealth, err := e.CheckHealth(e.ctx, "ns1")
+	assert.Regexp(t, "FF10111", err)
+	assert.Nil(t, streamHealth)
+}
+
 func TestStartStopNamespaceOldEventstream(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly-common/pkg/config"
@@ -36,6 +37,7 @@ import (
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/health"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
@@ -60,6 +62,8 @@ type Fabric struct {
 	streamID       map[string]string
 	idCache        map[string]*fabIdentity
 	wsconn         map[string]wsclient.WSClient
+	streamHealth   map[string]*health.StreamTracker
+	healthLock     sync.Mutex
 	wsConfig       *wsclient.WSConfig
 	closed         map[string]chan struct{}
 	metrics        metrics.Manager
@@ -242,11 +246,34 @@ func (f *Fabric) Init(ctx context.Context, cancelCtx context.CancelFunc, conf co
 	f.streamID = make(map[string]string)
 	f.closed = make(map[string]chan struct{})
 	f.wsconn = make(map[string]wsclient.WSClient)
+	f.streamHealth = make(map[string]*health.StreamTracker)
 	f.streams = newStreamManager(f.client, f.signer, f.cache, f.fabconnectConf.GetUint(FabconnectConfigBatchSize), uint(f.fabconnectConf.GetDuration(FabconnectConfigBatchTimeout).Milliseconds()))
 
 	return nil
 }
 
+func (f *Fabric) CheckHealth(ctx context.Context, namespace string) (*core.EventStreamHealth, error) {
+	_, err := f.streams.getEventStreams(ctx)
+	return f.getStreamHealth(namespace).Status(), err
+}
+
+// getStreamHealth returns the health tracker for a namespace, under the lock that protects it from StartNamespace
+func (f *Fabric) getStreamHealth(namespace string) *health.StreamTracker {
+	f.healthLock.Lock()
+	defer f.healthLock.Unlock()
+	return f.streamHealth[namespace]
+}
+
+func (f *Fabric) setStreamHealth(namespace string, streamHealth *health.StreamTracker) {
+	f.healthLock.Lock()
+	defer f.healthLock.Unlock()
+	if streamHealth == nil {
+		delete(f.streamHealth, namespace)
+	} else {
+		f.streamHealth[namespace] = streamHealth
+	}
+}
+
 func (f *Fabric) getTopic(namespace string) string {
 	return fmt.Sprintf("%s/%s", f.pluginTopic, namespace)
 }
@@ -255,7 +282,8 @@ func (f *Fabric) StartNamespace(ctx context.Context, namespace string) (err erro
 	log.L(f.ctx).Debugf("Starting namespace: %s", namespace)
 	topic := f.getTopic(namespace)
 
-	f.wsconn[namespace], err = wsclient.New(ctx, f.wsConfig, nil, func(ctx context.Context, w wsclient.WSClient) error {
+	streamHealth := health.NewStreamTracker()
+	f.wsconn[namespace], err = wsclient.New(ctx, f.wsConfig, streamHealth.BeforeConnect(nil), streamHealth.AfterConnect(func(ctx context.Context, w wsclient.WSClient) error {
 		// Send a subscribe to our topic after each connect/reconnect
 		b, _ := json.Marshal(&fabWSCommandPayload{
 			Type:  "listen",
@@ -269,7 +297,7 @@ func (f *Fabric) StartNamespace(ctx context.Context, namespace string) (err erro
 			err = w.Send(ctx, b)
 		}
 		return err
-	})
+	}))
 	if err != nil {
 		return err
 	}
@@ -280,6 +308,7 @@ func (f *Fabric) StartNamespace(ctx context.Context, namespace string) (err erro
 	}
 	log.L(f.ctx).Infof("Event stream: %s (topic=%s)", stream.ID, topic)
 	f.streamID[namespace] = stream.ID
+	f.setStreamHealth(namespace, streamHealth)
 
 	err = f.wsconn[namespace].Connect()
 	if err != nil {
@@ -299,6 +328,7 @@ func (f *Fabric) StopNamespace(ctx context.Context, namespace string) (err error
 		wsconn.Close()
 	}
 	delete(f.wsconn, namespace)
+	f.setStreamHealth(namespace, nil)
 	delete(f.streamID, namespace)
 	delete(f.closed, namespace)
 
@@ -502,6 +532,7 @@ func (f *Fabric) handleMessageBatch(ctx context.Context, messages []interface{})
 
 func (f *Fabric) eventLoop(namespace string, wsconn wsclient.WSClient, closed chan struct{}) {
 	topic := f.getTopic(namespace)
+	streamHealth := f.getStreamHealth(namespace)
 	defer wsconn.Close()
 	defer close(closed)
 	l := log.L(f.ctx).WithField("role", "event-loop").WithField("namespace", namespace)
@@ -518,6 +549,7 @@ func (f *Fabric) eventLoop(namespace string, wsconn wsclient.WSClient, closed ch
 				f.cancelCtx()
 				return
 			}
+			streamHealth.EventReceived()
 
 			var msgParsed interface{}
 			err := json.Unmarshal(msgBytes, &msgParsed)
@@ -209,10 +209,29 @@ func TestStartStopNamespace(t *testing.T) {
 
 	<-toServer
 
+	streamHealth, err := e.CheckHealth(e.ctx, "ns1")
+	assert.NoError(t, err)
+	assert.NotNil(t, streamHealth)
+
 	err = e.StopNamespace(e.ctx, "ns1")
 	assert.NoError(t, err)
 }
 
+func TestCheckHealthFail(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams",
+		httpmock.NewStringResponder(500, "pop"))
+
+	e.streams = newTestStreamManager(e.client, e.signer)
+	streamHealth, err := e.CheckHealth(e.ctx, "ns1")
+	assert.Regexp(t, "FF10284", err)
+	assert.Nil(t, streamHealth)
+}
+
 func TestInitMissingURL(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
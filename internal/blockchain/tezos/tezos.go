@@ -35,6 +35,7 @@ import (
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/health"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
@@ -61,6 +62,7 @@ type Tezos struct {
 	streams              *streamManager
 	streamID             string
 	wsconn               wsclient.WSClient
+	streamHealth         *health.StreamTracker
 	closed               chan struct{}
 	addressResolveAlways bool
 	addressResolver      *addressResolver
@@ -186,7 +188,8 @@ func (t *Tezos) Init(ctx context.Context, cancelCtx context.CancelFunc, conf con
 	if wsConfig.WSKeyPath == "" {
 		wsConfig.WSKeyPath = "/ws"
 	}
-	t.wsconn, err = wsclient.New(ctx, wsConfig, nil, t.afterConnect)
+	t.streamHealth = health.NewStreamTracker()
+	t.wsconn, err = wsclient.New(ctx, wsConfig, t.streamHealth.BeforeConnect(nil), t.streamHealth.AfterConnect(t.afterConnect))
 	if err != nil {
 		return err
 	}
@@ -261,6 +264,11 @@ func (t *Tezos) Capabilities() *blockchain.Capabilities {
 	return t.capabilities
 }
 
+func (t *Tezos) CheckHealth(ctx context.Context, namespace string) (*core.EventStreamHealth, error) {
+	_, err := t.streams.getEventStreams(ctx)
+	return t.streamHealth.Status(), err
+}
+
 func (t *Tezos) AddFireflySubscription(ctx context.Context,
 	namespace *core.Namespace,
 	contract *blockchain.MultipartyContract,
@@ -801,6 +809,7 @@ func (t *Tezos) eventLoop() {
 				t.cancelCtx()
 				return
 			}
+			t.streamHealth.EventReceived()
 
 			var msgParsed interface{}
 			err := json.Unmarshal(msgBytes, &msgParsed)
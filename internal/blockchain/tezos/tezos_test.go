@@ -234,6 +234,12 @@ func TestInitAndStartWithTezosConnect(t *testing.T) {
 	fromServer <- `[]`                // empty batch, will be ignored, but acked
 	reply := <-toServer
 	assert.Equal(t, `{"type":"ack","topic":"topic1"}`, reply)
+
+	streamHealth, err := tz.CheckHealth(tz.ctx, "ns1")
+	assert.NoError(t, err)
+	assert.True(t, streamHealth.Connected)
+	assert.NotNil(t, streamHealth.LastEvent)
+
 	fromServer <- `[{}]` // bad batch
 
 	// Bad data will be ignored
@@ -242,6 +248,21 @@ func TestInitAndStartWithTezosConnect(t *testing.T) {
 	fromServer <- `42`
 }
 
+func TestCheckHealthFail(t *testing.T) {
+	tz, cancel := newTestTezos()
+	defer cancel()
+	httpmock.ActivateNonDefault(tz.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams",
+		httpmock.NewStringResponder(500, "pop"))
+
+	tz.streams = newTestStreamManager(tz.client)
+	streamHealth, err := tz.CheckHealth(tz.ctx, "ns1")
+	assert.Regexp(t, "FF10283", err)
+	assert.Nil(t, streamHealth)
+}
+
 func TestBackgroundStart(t *testing.T) {
 	tz, cancel := newTestTezos()
 	defer cancel()
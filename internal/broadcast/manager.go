@@ -48,6 +48,7 @@ type Manager interface {
 
 	NewBroadcast(in *core.MessageInOut) syncasync.Sender
 	BroadcastMessage(ctx context.Context, in *core.MessageInOut, waitConfirm bool) (out *core.Message, err error)
+	BroadcastMessages(ctx context.Context, in []*core.MessageInOut) ([]*core.MessageBulkResult, error)
 	PublishDataValue(ctx context.Context, id string, idempotencyKey core.IdempotencyKey) (*core.Data, error)
 	PublishDataBlob(ctx context.Context, id string, idempotencyKey core.IdempotencyKey) (*core.Data, error)
 	Start() error
@@ -70,6 +71,8 @@ type broadcastManager struct {
 	syncasync             syncasync.Bridge
 	multiparty            multiparty.Manager
 	maxBatchPayloadLength int64
+	maxBulkMessages       int
+	compression           core.CompressionType
 	metrics               metrics.Manager
 	operations            operations.Manager
 	txHelper              txcommon.Helper
@@ -79,6 +82,10 @@ func NewBroadcastManager(ctx context.Context, ns *core.Namespace, di database.Pl
 	if di == nil || im == nil || dm == nil || bi == nil || dx == nil || si == nil || mm == nil || om == nil || txHelper == nil {
 		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "BroadcastManager")
 	}
+	compression, err := fftypes.FFEnumParseString(ctx, "compressiontype", config.GetString(coreconfig.BroadcastBatchCompression))
+	if err != nil {
+		return nil, err
+	}
 	bm := &broadcastManager{
 		ctx:                   ctx,
 		namespace:             ns,
@@ -91,6 +98,8 @@ func NewBroadcastManager(ctx context.Context, ns *core.Namespace, di database.Pl
 		syncasync:             sa,
 		multiparty:            mult,
 		maxBatchPayloadLength: config.GetByteSize(coreconfig.BroadcastBatchPayloadLimit),
+		maxBulkMessages:       config.GetInt(coreconfig.MessageBulkMaxMessages),
+		compression:           compression,
 		metrics:               mm,
 		operations:            om,
 		txHelper:              txHelper,
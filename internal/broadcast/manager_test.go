@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly/internal/batch"
@@ -105,6 +106,16 @@ func TestInitFail(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitBadCompression(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.BroadcastBatchCompression, "lz4")
+	_, err := NewBroadcastManager(context.Background(), &core.Namespace{},
+		&databasemocks.Plugin{}, &blockchainmocks.Plugin{}, &dataexchangemocks.Plugin{}, &sharedstoragemocks.Plugin{},
+		&identitymanagermocks.Manager{}, &datamocks.Manager{}, &batchmocks.Manager{}, &syncasyncmocks.Bridge{},
+		&multipartymocks.Manager{}, &metricsmocks.Manager{}, &operationmocks.Manager{}, &txcommonmocks.Helper{})
+	assert.Regexp(t, "FF00172", err)
+}
+
 func TestName(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
@@ -56,6 +56,42 @@ func (bm *broadcastManager) BroadcastMessage(ctx context.Context, in *core.Messa
 	return &in.Message, err
 }
 
+// BroadcastMessages prepares each message individually, then writes all the messages that are valid
+// in a single database transaction. A result is returned for each message, so that failures of
+// individual messages do not reject the whole request.
+func (bm *broadcastManager) BroadcastMessages(ctx context.Context, in []*core.MessageInOut) ([]*core.MessageBulkResult, error) {
+	if len(in) > bm.maxBulkMessages {
+		return nil, i18n.NewError(ctx, coremsgs.MsgBulkMessagesTooMany, len(in), bm.maxBulkMessages)
+	}
+
+	results := make([]*core.MessageBulkResult, len(in))
+	prepared := make([]*data.NewMessage, 0, len(in))
+	preparedResults := make([]*core.MessageBulkResult, 0, len(in))
+	for i, msgIn := range in {
+		results[i] = &core.MessageBulkResult{}
+		msgIn.Header.Type = core.MessageTypeBroadcast
+		broadcast := bm.NewBroadcast(msgIn).(*broadcastSender)
+		if err := broadcast.Prepare(ctx); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		prepared = append(prepared, broadcast.msg)
+		preparedResults = append(preparedResults, results[i])
+	}
+
+	if len(prepared) > 0 {
+		for i, err := range bm.data.WriteNewMessages(ctx, prepared) {
+			if err != nil {
+				preparedResults[i].Error = err.Error()
+			} else {
+				preparedResults[i].Message = &prepared[i].Message.Message
+			}
+		}
+	}
+	log.L(ctx).Infof("Sent bulk broadcast of %d messages (%d prepared)", len(in), len(prepared))
+	return results, nil
+}
+
 type broadcastSender struct {
 	mgr      *broadcastManager
 	msg      *data.NewMessage
@@ -94,6 +130,10 @@ func (s *broadcastSender) setDefaults() {
 	msg.Header.Namespace = s.mgr.namespace.NetworkName
 	msg.LocalNamespace = s.mgr.namespace.Name
 	msg.State = core.MessageStateReady
+	if msg.ScheduledAfter(fftypes.Now()) {
+		// Held back from the batch manager, until released by the scheduler
+		msg.State = core.MessageStateStaged
+	}
 	if msg.Header.Type == "" {
 		msg.Header.Type = core.MessageTypeBroadcast
 	}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/data"
@@ -63,6 +64,39 @@ func TestBroadcastMessageOk(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestBroadcastMessageScheduled(t *testing.T) {
+	bm, cancel := newTestBroadcastWithMetrics(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, mock.Anything).Return(nil)
+
+	scheduled := fftypes.FFTime(time.Now().Add(1 * time.Hour))
+	msg, err := bm.BroadcastMessage(ctx, &core.MessageInOut{
+		Message: core.Message{
+			Header: core.MessageHeader{
+				SignerRef: core.SignerRef{
+					Author: "did:firefly:org/abcd",
+					Key:    "0x12345",
+				},
+			},
+			Scheduled: &scheduled,
+		},
+		InlineData: core.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, core.MessageStateStaged, msg.State)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
 func TestBroadcastMessageWriteFail(t *testing.T) {
 	bm, cancel := newTestBroadcastWithMetrics(t)
 	defer cancel()
@@ -261,3 +295,46 @@ func TestNewMessageContractInvoke(t *testing.T) {
 	assert.NotNil(t, sender)
 
 }
+
+func TestBroadcastMessagesBulk(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessages", ctx, mock.MatchedBy(func(newMsgs []*data.NewMessage) bool {
+		return len(newMsgs) == 2
+	})).Return([]error{nil, fmt.Errorf("pop")})
+	mim.On("ResolveInputSigningIdentity", ctx, mock.MatchedBy(func(signer *core.SignerRef) bool {
+		return signer.Author != "bad"
+	})).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	results, err := bm.BroadcastMessages(ctx, []*core.MessageInOut{
+		{InlineData: core.InlineData{{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)}}},
+		{Message: core.Message{Header: core.MessageHeader{SignerRef: core.SignerRef{Author: "bad"}}}},
+		{InlineData: core.InlineData{{Value: fftypes.JSONAnyPtr(`{"hello": "again"}`)}}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Empty(t, results[0].Error)
+	assert.Equal(t, core.MessageTypeBroadcast, results[0].Message.Header.Type)
+	assert.Regexp(t, "FF10206", results[1].Error)
+	assert.Nil(t, results[1].Message)
+	assert.Regexp(t, "pop", results[2].Error)
+	assert.Nil(t, results[2].Message)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestBroadcastMessagesBulkTooMany(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.maxBulkMessages = 1
+
+	_, err := bm.BroadcastMessages(context.Background(), []*core.MessageInOut{{}, {}})
+	assert.Regexp(t, "FF10512", err)
+}
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -162,6 +163,16 @@ func (bm *broadcastManager) uploadBatch(ctx context.Context, data uploadBatchDat
 		return nil, core.OpPhaseInitializing, i18n.WrapError(ctx, err, coremsgs.MsgSerializationFailed)
 	}
 
+	// Compression wraps the serialized batch, so the batch hash is unaffected
+	compression, err := bm.broadcastCompression(ctx)
+	if err != nil {
+		return nil, core.OpPhaseInitializing, err
+	}
+	payload, err = core.CompressPayload(ctx, compression, payload)
+	if err != nil {
+		return nil, core.OpPhaseInitializing, err
+	}
+
 	// Write it to IPFS to get a payload reference
 	payloadRef, err := bm.sharedstorage.UploadData(ctx, bytes.NewReader(payload))
 	if err != nil {
@@ -171,6 +182,37 @@ func (bm *broadcastManager) uploadBatch(ctx context.Context, data uploadBatchDat
 	return getUploadBatchOutputs(payloadRef), core.OpPhaseComplete, nil
 }
 
+// broadcastCompression returns the configured compression if every node registered in the network
+// advertises support for it, as any of them might download the batch. Nodes advertise support when
+// they are registered, so an existing node must be registered again before compression is used.
+func (bm *broadcastManager) broadcastCompression(ctx context.Context) (core.CompressionType, error) {
+	if bm.compression == core.CompressionTypeNone {
+		return core.CompressionTypeNone, nil
+	}
+	fb := database.IdentityQueryFactory.NewFilter(ctx)
+	nodes, _, err := bm.database.GetIdentities(ctx, bm.namespace.Name, fb.Eq("type", core.IdentityTypeNode))
+	if err != nil {
+		return "", err
+	}
+	if len(nodes) == 0 {
+		return core.CompressionTypeNone, nil
+	}
+	for _, node := range nodes {
+		supported := false
+		for _, compression := range node.Profile.GetStringArray(core.CompressionProfileKey) {
+			if compression == bm.compression.String() {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			log.L(ctx).Debugf("Node '%s' does not advertise support for %s compression - batch is not compressed", node.DID, bm.compression)
+			return core.CompressionTypeNone, nil
+		}
+	}
+	return bm.compression, nil
+}
+
 // uploadBlob streams a blob from the local data exchange, to public storage
 func (bm *broadcastManager) uploadBlob(ctx context.Context, data uploadBlobData) (outputs fftypes.JSONObject, phase core.OpPhase, err error) {
 
@@ -200,8 +242,17 @@ func (bm *broadcastManager) uploadBlob(ctx context.Context, data uploadBlobData)
 // uploadValue streams the value JSON from a data record to public storage
 func (bm *broadcastManager) uploadValue(ctx context.Context, data uploadValue) (outputs fftypes.JSONObject, phase core.OpPhase, err error) {
 
-	// Upload to shared storage
-	data.Data.Public, err = bm.sharedstorage.UploadData(ctx, bytes.NewReader(data.Data.Value.Bytes()))
+	// Upload to shared storage, streaming any value that is held in the blob store
+	var reader io.Reader = bytes.NewReader(data.Data.Value.Bytes())
+	if data.Data.ValueRef != nil {
+		valueReader, err := bm.data.DownloadLargeValue(ctx, data.Data)
+		if err != nil {
+			return nil, core.OpPhaseInitializing, err
+		}
+		defer valueReader.Close()
+		reader = valueReader
+	}
+	data.Data.Public, err = bm.sharedstorage.UploadData(ctx, reader)
 	if err != nil {
 		return nil, core.OpPhaseInitializing, err
 	}
@@ -17,7 +17,9 @@ package broadcast
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"strings"
 	"testing"
@@ -231,6 +233,133 @@ func TestRunOperationBatchBroadcast(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestRunOperationBatchBroadcastCompressed(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.compression = core.CompressionTypeZstd
+
+	op := &core.Operation{}
+	batch := &core.Batch{
+		BatchHeader: core.BatchHeader{
+			ID: fftypes.NewUUID(),
+		},
+		Hash: fftypes.NewRandB32(),
+	}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", context.Background(), "ns1", mock.Anything).Return([]*core.Identity{
+		{IdentityProfile: core.IdentityProfile{Profile: fftypes.JSONObject{core.CompressionProfileKey: []interface{}{"gzip", "zstd"}}}},
+		{IdentityProfile: core.IdentityProfile{Profile: fftypes.JSONObject{core.CompressionProfileKey: []interface{}{"zstd"}}}},
+	}, nil, nil)
+	mps := bm.sharedstorage.(*sharedstoragemocks.Plugin)
+	var uploaded []byte
+	mps.On("UploadData", context.Background(), mock.Anything).Return("123", nil).Run(func(args mock.Arguments) {
+		uploaded, _ = io.ReadAll(args[1].(io.Reader))
+	})
+
+	outputs, phase, err := bm.RunOperation(context.Background(), opUploadBatch(op, batch))
+	assert.NoError(t, err)
+	assert.Equal(t, core.OpPhaseComplete, phase)
+	assert.Equal(t, "123", outputs["payloadRef"])
+
+	assert.True(t, core.IsCompressedPayload(uploaded))
+	payload, err := core.DecompressPayload(context.Background(), uploaded, 1024)
+	assert.NoError(t, err)
+	var parsed *core.Batch
+	err = json.Unmarshal(payload, &parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, batch.ID, parsed.ID)
+	assert.Equal(t, batch.Hash, parsed.Hash)
+
+	mps.AssertExpectations(t)
+}
+
+func TestRunOperationBatchBroadcastNodeNotSupportingCompression(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.compression = core.CompressionTypeZstd
+
+	op := &core.Operation{}
+	batch := &core.Batch{
+		BatchHeader: core.BatchHeader{
+			ID: fftypes.NewUUID(),
+		},
+	}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", context.Background(), "ns1", mock.Anything).Return([]*core.Identity{
+		{IdentityProfile: core.IdentityProfile{Profile: fftypes.JSONObject{core.CompressionProfileKey: []interface{}{"zstd"}}}},
+		{IdentityProfile: core.IdentityProfile{Profile: fftypes.JSONObject{}}},
+	}, nil, nil)
+	mps := bm.sharedstorage.(*sharedstoragemocks.Plugin)
+	var uploaded []byte
+	mps.On("UploadData", context.Background(), mock.Anything).Return("123", nil).Run(func(args mock.Arguments) {
+		uploaded, _ = io.ReadAll(args[1].(io.Reader))
+	})
+
+	_, phase, err := bm.RunOperation(context.Background(), opUploadBatch(op, batch))
+	assert.NoError(t, err)
+	assert.Equal(t, core.OpPhaseComplete, phase)
+	assert.False(t, core.IsCompressedPayload(uploaded))
+
+	mdi.AssertExpectations(t)
+	mps.AssertExpectations(t)
+}
+
+func TestBroadcastCompressionNoNodes(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.compression = core.CompressionTypeGzip
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", context.Background(), "ns1", mock.Anything).Return([]*core.Identity{}, nil, nil)
+
+	compression, err := bm.broadcastCompression(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, core.CompressionTypeNone, compression)
+}
+
+func TestRunOperationBatchBroadcastGetNodesFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.compression = core.CompressionTypeGzip
+
+	op := &core.Operation{}
+	batch := &core.Batch{
+		BatchHeader: core.BatchHeader{
+			ID: fftypes.NewUUID(),
+		},
+	}
+
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", context.Background(), "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, phase, err := bm.RunOperation(context.Background(), opUploadBatch(op, batch))
+	assert.Equal(t, core.OpPhaseInitializing, phase)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRunOperationBatchBroadcastCompressFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bm.compression = "lz4"
+
+	op := &core.Operation{}
+	batch := &core.Batch{
+		BatchHeader: core.BatchHeader{
+			ID: fftypes.NewUUID(),
+		},
+	}
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", context.Background(), "ns1", mock.Anything).Return([]*core.Identity{
+		{IdentityProfile: core.IdentityProfile{Profile: fftypes.JSONObject{core.CompressionProfileKey: []interface{}{"lz4"}}}},
+	}, nil, nil)
+
+	_, phase, err := bm.RunOperation(context.Background(), opUploadBatch(op, batch))
+	assert.Equal(t, core.OpPhaseInitializing, phase)
+	assert.Regexp(t, "FF10504", err)
+}
+
 func TestPrepareAndRunUploadBlob(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
@@ -562,6 +691,58 @@ func TestRunOperationUploadValueUpdateFail(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestRunOperationUploadLargeValue(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &core.Operation{}
+	data := &core.Data{
+		ID:       fftypes.NewUUID(),
+		ValueRef: &core.DataValueRef{Size: 15},
+	}
+
+	mps := bm.sharedstorage.(*sharedstoragemocks.Plugin)
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+
+	mdm.On("DownloadLargeValue", context.Background(), data).Return(io.NopCloser(strings.NewReader(`{"some":"data"}`)), nil)
+	var uploaded []byte
+	mps.On("UploadData", context.Background(), mock.Anything).Run(func(args mock.Arguments) {
+		uploaded, _ = io.ReadAll(args[1].(io.Reader))
+	}).Return("123", nil)
+	mdi.On("UpdateData", context.Background(), "ns1", data.ID, mock.Anything).Return(nil)
+
+	outputs, phase, err := bm.RunOperation(context.Background(), opUploadValue(op, data))
+	assert.NoError(t, err)
+	assert.Equal(t, core.OpPhaseComplete, phase)
+	assert.Equal(t, "123", outputs["payloadRef"])
+	assert.Equal(t, `{"some":"data"}`, string(uploaded))
+
+	mps.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestRunOperationUploadLargeValueDownloadFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	op := &core.Operation{}
+	data := &core.Data{
+		ID:       fftypes.NewUUID(),
+		ValueRef: &core.DataValueRef{Size: 15},
+	}
+
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("DownloadLargeValue", context.Background(), data).Return(nil, fmt.Errorf("pop"))
+
+	_, phase, err := bm.RunOperation(context.Background(), opUploadValue(op, data))
+	assert.Equal(t, core.OpPhaseInitializing, phase)
+	assert.Regexp(t, "pop", err)
+
+	mdm.AssertExpectations(t)
+}
+
 func TestRunOperationUploadBlobUploadFail(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
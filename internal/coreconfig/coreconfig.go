@@ -47,6 +47,36 @@ const (
 	NamespaceDefaultKey = "defaultKey"
 	// NamespaceAssetKeyNormalization mechanism to normalize keys before using them. Valid options: "blockchain_plugin" - use blockchain plugin (default), "none" - do not attempt normalization
 	NamespaceAssetKeyNormalization = "asset.manager.keyNormalization"
+	// NamespaceRetentionMaxAge is the age beyond which completed records are purged from the namespace. Retention is disabled when not set
+	NamespaceRetentionMaxAge = "retention.maxAge"
+	// NamespaceRetentionInterval is how often the retention manager checks for records to purge
+	NamespaceRetentionInterval = "retention.interval"
+	// NamespaceRetentionBatchSize is the number of records archived and deleted in each database transaction
+	NamespaceRetentionBatchSize = "retention.batchSize"
+	// NamespaceRetentionArchiveDirectory is a directory to which records are written as JSON lines before they are deleted
+	NamespaceRetentionArchiveDirectory = "retention.archiveDirectory"
+	// NamespaceOperations contains the timeout and retry policies for operations in a namespace
+	NamespaceOperations = "operations"
+	// NamespaceOperationsCheckInterval is how often operations are checked against the policies for their type
+	NamespaceOperationsCheckInterval = "checkInterval"
+	// NamespaceOperationsPolicies is the list of policies, each for a single operation type
+	NamespaceOperationsPolicies = "policies"
+	// NamespaceOperationsPolicyType is the operation type the policy applies to
+	NamespaceOperationsPolicyType = "type"
+	// NamespaceOperationsPolicyTimeout is the time without an update after which a pending operation is timed out
+	NamespaceOperationsPolicyTimeout = "timeout"
+	// NamespaceOperationsPolicyTimeoutAction is the action taken on timeout - "fail" or "query"
+	NamespaceOperationsPolicyTimeoutAction = "timeoutAction"
+	// NamespaceOperationsPolicyRetryMaxAttempts is the maximum number of automatic retries of a failed operation
+	NamespaceOperationsPolicyRetryMaxAttempts = "retry.maxAttempts"
+	// NamespaceOperationsPolicyRetryInitialDelay is the delay before the first automatic retry
+	NamespaceOperationsPolicyRetryInitialDelay = "retry.initialDelay"
+	// NamespaceOperationsPolicyRetryMaxDelay is the maximum delay between automatic retries
+	NamespaceOperationsPolicyRetryMaxDelay = "retry.maxDelay"
+	// NamespaceOperationsPolicyRetryFactor is the backoff factor applied to the delay for each retry
+	NamespaceOperationsPolicyRetryFactor = "retry.factor"
+	// NamespaceOperationsPolicyRetryErrors is a list of regular expressions, one of which must match the error for a failed operation to be retried
+	NamespaceOperationsPolicyRetryErrors = "retry.errors"
 	// NamespaceMultiparty contains the multiparty configuration for a namespace
 	NamespaceMultiparty = "multiparty"
 	// NamespaceMultipartyEnabled specifies if multi-party mode is enabled for a namespace
@@ -92,12 +122,40 @@ var (
 	APIOASPanicOnMissingDescription = ffc("api.oas.panicOnMissingDescription")
 	// APIPassThroughHeaders is a list of HTTP request headers to pass through to requests made to dependency microservices
 	APIPassthroughHeaders = ffc("api.passthroughHeaders")
+	// APIRateLimitNamespaceRequestsPerSecond is the rate that requests to each namespace are allowed at, or 0 for no limit
+	APIRateLimitNamespaceRequestsPerSecond = ffc("api.rateLimit.namespace.requestsPerSecond")
+	// APIRateLimitNamespaceBurst is the number of requests to each namespace that are allowed in a burst above the rate
+	APIRateLimitNamespaceBurst = ffc("api.rateLimit.namespace.burst")
+	// APIRateLimitPrincipalRequestsPerSecond is the rate that requests from each principal in a namespace are allowed at, or 0 for no limit
+	APIRateLimitPrincipalRequestsPerSecond = ffc("api.rateLimit.principal.requestsPerSecond")
+	// APIRateLimitPrincipalBurst is the number of requests from each principal in a namespace that are allowed in a burst above the rate
+	APIRateLimitPrincipalBurst = ffc("api.rateLimit.principal.burst")
+	// APIQuotaNamespaceDailyChainWrites is the number of chain-writing requests allowed in each namespace per day, or 0 for no quota
+	APIQuotaNamespaceDailyChainWrites = ffc("api.quota.namespaceDailyChainWrites")
+	// APIQuotaPrincipalDailyChainWrites is the number of chain-writing requests allowed from each principal in a namespace per day, or 0 for no quota
+	APIQuotaPrincipalDailyChainWrites = ffc("api.quota.principalDailyChainWrites")
+	// APIGraphQLMaxDepth is the deepest that fields can be nested in a GraphQL query
+	APIGraphQLMaxDepth = ffc("api.graphql.maxDepth")
+	// APIGraphQLMaxNodes is the most nodes that a GraphQL query can resolve, when every list in it is full
+	APIGraphQLMaxNodes = ffc("api.graphql.maxNodes")
+	// APIGraphQLMaxTokens is the most tokens that a GraphQL query document can contain
+	APIGraphQLMaxTokens = ffc("api.graphql.maxTokens")
+	// APIAuditEnabled records every mutating request to a namespace in the audit log of the namespace
+	APIAuditEnabled = ffc("api.audit.enabled")
 	// BatchManagerReadPageSize is the size of each page of messages read from the database into memory when assembling batches
 	BatchManagerReadPageSize = ffc("batch.manager.readPageSize")
 	// BatchManagerReadPollTimeout is how long without any notifications of new messages to wait, before doing a page query
 	BatchManagerReadPollTimeout = ffc("batch.manager.pollTimeout")
 	// BatchManagerMinimumPollDelay is the minimum time the batch manager waits between polls on the DB - to prevent thrashing
 	BatchManagerMinimumPollDelay = ffc("batch.manager.minimumPollDelay")
+	// BatchPriorityHighSize is the maximum number of messages in a batch of high priority messages
+	BatchPriorityHighSize = ffc("batch.priority.high.size")
+	// BatchPriorityHighTimeout is the timeout to wait for a batch of high priority messages to fill, before sending
+	BatchPriorityHighTimeout = ffc("batch.priority.high.timeout")
+	// BatchPriorityLowSize is the maximum number of messages in a batch of low priority messages
+	BatchPriorityLowSize = ffc("batch.priority.low.size")
+	// BatchPriorityLowTimeout is the timeout to wait for a batch of low priority messages to fill, before sending
+	BatchPriorityLowTimeout = ffc("batch.priority.low.timeout")
 	// BatchRetryFactor is the retry backoff factor for database operations performed by the batch manager
 	BatchRetryFactor = ffc("batch.retry.factor")
 	// BatchRetryInitDelay is the retry initial delay for database operations
@@ -119,6 +177,8 @@ var (
 
 	// BroadcastBatchAgentTimeout how long to keep around a batching agent for a sending identity before disposal
 	BroadcastBatchAgentTimeout = ffc("broadcast.batch.agentTimeout")
+	// BroadcastBatchCompression is the compression applied to batches uploaded to shared storage
+	BroadcastBatchCompression = ffc("broadcast.batch.compression")
 	// BroadcastBatchSize is the maximum number of messages that can be packed into a batch
 	BroadcastBatchSize = ffc("broadcast.batch.size")
 	// BroadcastBatchPayloadLimit is the maximum payload size of a batch for broadcast messages
@@ -197,6 +257,8 @@ var (
 	DownloadRetryFactor = ffc("download.retry.factor")
 	// PrivateMessagingBatchAgentTimeout how long to keep around a batching agent for a sending identity before disposal
 	PrivateMessagingBatchAgentTimeout = ffc("privatemessaging.batch.agentTimeout")
+	// PrivateMessagingBatchCompression is the compression applied to batches sent to nodes that advertise support for it
+	PrivateMessagingBatchCompression = ffc("privatemessaging.batch.compression")
 	// PrivateMessagingBatchSize is the maximum size of a batch for broadcast messages
 	PrivateMessagingBatchSize = ffc("privatemessaging.batch.size")
 	// PrivateMessagingBatchPayloadLimit is the maximum payload size of a private message data exchange payload
@@ -211,6 +273,10 @@ var (
 	PrivateMessagingRetryMaxDelay = ffc("privatemessaging.retry.maxDelay")
 	// DatabaseType the type of the database interface plugin to use
 	HistogramsMaxChartRows = ffc("histograms.maxChartRows")
+	// HealthTimeout is the maximum time to wait for each plugin to respond to a health probe
+	HealthTimeout = ffc("health.timeout")
+	// HealthEventStreamMaxIdle is the time since the last event on a connected event stream, after which the stream is reported as lagging. Disabled when zero
+	HealthEventStreamMaxIdle = ffc("health.eventStreamMaxIdle")
 	// TokensList is the root key containing a list of supported token connectors
 	TokensList = ffc("tokens")
 	// PluginsTokensList is the key containing a list of supported tokens plugins
@@ -243,6 +309,8 @@ var (
 	EventAggregatorBatchTimeout = ffc("event.aggregator.batchTimeout")
 	// EventAggregatorPollTimeout the time to wait without a notification of new events, before trying a select on the table
 	EventAggregatorPollTimeout = ffc("event.aggregator.pollTimeout")
+	// EventAggregatorExpiryInterval how often to check for local messages that have passed their expiry without being confirmed
+	EventAggregatorExpiryInterval = ffc("event.aggregator.expiryInterval")
 	// EventAggregatorRewindTimeout the minimum time to wait for rewinds to accumulate before resolving them
 	EventAggregatorRewindTimeout = ffc("event.aggregator.rewindTimeout")
 	// EventAggregatorRewindQueueLength the size of the queue into the rewind dispatcher
@@ -269,10 +337,16 @@ var (
 	EventDispatcherRetryMaxDelay = ffc("event.dispatcher.retry.maxDelay")
 	// EventDBEventsBufferSize the size of the buffer of change events
 	EventDBEventsBufferSize = ffc("event.dbevents.bufferSize")
+	// GRPCEnabled determines whether the gRPC server will be enabled or not
+	GRPCEnabled = ffc("grpc.enabled")
+	// GRPCMaxMessageSize is the maximum size of a request message sent to the gRPC server
+	GRPCMaxMessageSize = ffc("grpc.maxMessageSize")
 	// LegacyAdminEnabled is the deprecated key that pre-dates spi.enabled
 	LegacyAdminEnabled = ffc("admin.enabled")
 	// SPIEnabled determines whether the admin interface will be enabled or not
 	SPIEnabled = ffc("spi.enabled")
+	// SPIGlobalAuthPlugin is the name of the auth plugin that authorizes admin requests that are not in a namespace
+	SPIGlobalAuthPlugin = ffc("spi.globalAuthPlugin")
 	// SPIWebSocketEventQueueLength is the maximum number of events that will queue up on the server side of each WebSocket connection before events start being dropped
 	SPIWebSocketEventQueueLength = ffc("spi.ws.eventQueueLength")
 	// SPIWebSocketBlockedWarnInterval how often to emit a warning if an admin.ws is blocked and not receiving events
@@ -281,12 +355,26 @@ var (
 	SPIWebSocketReadBufferSize = ffc("spi.ws.readBufferSize")
 	// SPIWebSocketWriteBufferSize is the WebSocket write buffer size for the admin change-event WebSocket
 	SPIWebSocketWriteBufferSize = ffc("spi.ws.writeBufferSize")
+	// MessageBulkMaxMessages is the maximum number of messages that can be submitted in a single bulk request
+	MessageBulkMaxMessages = ffc("message.bulk.maxMessages")
+	// MessageSchedulerBatchSize is the maximum number of scheduled messages released in each database transaction
+	MessageSchedulerBatchSize = ffc("message.scheduler.batchSize")
+	// MessageSchedulerInterval is how often the scheduler checks for scheduled messages that are due to be sent
+	MessageSchedulerInterval = ffc("message.scheduler.interval")
+	// MessageThreadMaxDepth is the maximum depth of replies that can be requested when querying a message thread
+	MessageThreadMaxDepth = ffc("message.thread.maxDepth")
+	// MessageThreadMaxSize is the maximum number of messages in a message thread that can be queried
+	MessageThreadMaxSize = ffc("message.thread.maxSize")
 	// MessageWriterCount
 	MessageWriterCount = ffc("message.writer.count")
 	// MessageWriterBatchTimeout
 	MessageWriterBatchTimeout = ffc("message.writer.batchTimeout")
 	// MessageWriterBatchMaxInserts
 	MessageWriterBatchMaxInserts = ffc("message.writer.batchMaxInserts")
+	// DataLargeValueThreshold is the size above which JSON values are held in the blob store, rather than the database
+	DataLargeValueThreshold = ffc("data.largeValueThreshold")
+	// ValidatorMaxBlobSize is the largest blob that will be read into memory, to validate against a datatype
+	ValidatorMaxBlobSize = ffc("validator.maxBlobSize")
 	// MetricsEnabled determines whether metrics will be instrumented and if the metrics server will be enabled or not
 	MetricsEnabled = ffc("metrics.enabled")
 	// MetricsPath determines what path to serve the Prometheus metrics from
@@ -365,6 +453,14 @@ func setDefaults() {
 	viper.SetDefault(string(APIMaxFilterSkip), 1000) // protects database (skip+limit pagination is not for bulk operations)
 	viper.SetDefault(string(APIRequestTimeout), "120s")
 	viper.SetDefault(string(APIPassthroughHeaders), []string{})
+	viper.SetDefault(string(APIRateLimitNamespaceRequestsPerSecond), 0)
+	viper.SetDefault(string(APIRateLimitPrincipalRequestsPerSecond), 0)
+	viper.SetDefault(string(APIQuotaNamespaceDailyChainWrites), 0)
+	viper.SetDefault(string(APIQuotaPrincipalDailyChainWrites), 0)
+	viper.SetDefault(string(APIAuditEnabled), false)
+	viper.SetDefault(string(APIGraphQLMaxDepth), 10)
+	viper.SetDefault(string(APIGraphQLMaxNodes), 10000)
+	viper.SetDefault(string(APIGraphQLMaxTokens), 10000)
 	viper.SetDefault(string(AssetManagerKeyNormalization), "blockchain_plugin")
 	viper.SetDefault(string(CacheBatchLimit), 100)
 	viper.SetDefault(string(CacheBatchTTL), "5m")
@@ -386,6 +482,7 @@ func setDefaults() {
 	viper.SetDefault(string(CacheBlockchainEventLimit), 1000)
 	viper.SetDefault(string(CacheBlockchainEventTTL), "5m")
 	viper.SetDefault(string(BroadcastBatchAgentTimeout), "2m")
+	viper.SetDefault(string(BroadcastBatchCompression), "none")
 	viper.SetDefault(string(BroadcastBatchSize), 200)
 	viper.SetDefault(string(BroadcastBatchPayloadLimit), "800Kb")
 	viper.SetDefault(string(BroadcastBatchTimeout), "1s")
@@ -399,6 +496,8 @@ func setDefaults() {
 	viper.SetDefault(string(CacheMethodsLimit), 200)
 	viper.SetDefault(string(CacheMethodsTTL), "5m")
 	viper.SetDefault(string(HistogramsMaxChartRows), 100)
+	viper.SetDefault(string(HealthTimeout), "5s")
+	viper.SetDefault(string(HealthEventStreamMaxIdle), "0")
 	viper.SetDefault(string(DebugPort), -1)
 	viper.SetDefault(string(DebugAddress), "localhost")
 	viper.SetDefault(string(DownloadWorkerCount), 10)
@@ -410,6 +509,7 @@ func setDefaults() {
 	viper.SetDefault(string(EventAggregatorBatchSize), 200)
 	viper.SetDefault(string(EventAggregatorBatchTimeout), "0ms")
 	viper.SetDefault(string(EventAggregatorPollTimeout), "30s")
+	viper.SetDefault(string(EventAggregatorExpiryInterval), "1s")
 	viper.SetDefault(string(EventAggregatorRewindTimeout), "50ms")
 	viper.SetDefault(string(EventAggregatorRewindQueueLength), 10)
 	viper.SetDefault(string(EventAggregatorRewindQueryLimit), 1000)
@@ -420,12 +520,14 @@ func setDefaults() {
 	viper.SetDefault(string(EventDispatcherBufferLength), 5)
 	viper.SetDefault(string(EventDispatcherBatchTimeout), "0ms")
 	viper.SetDefault(string(EventDispatcherPollTimeout), "30s")
-	viper.SetDefault(string(EventTransportsEnabled), []string{"websockets", "webhooks"})
+	viper.SetDefault(string(EventTransportsEnabled), []string{"websockets", "webhooks", "grpc"})
 	viper.SetDefault(string(EventTransportsDefault), "websockets")
 	viper.SetDefault(string(CacheEventListenerTopicLimit), 100)
 	viper.SetDefault(string(CacheEventListenerTopicTTL), "5m")
 	viper.SetDefault(string(CacheGroupLimit), 50)
 	viper.SetDefault(string(CacheGroupTTL), "1h")
+	viper.SetDefault(string(GRPCEnabled), false)
+	viper.SetDefault(string(GRPCMaxMessageSize), "4Mb")
 	viper.SetDefault(string(SPIEnabled), false)
 	viper.SetDefault(string(SPIWebSocketReadBufferSize), "16Kb")
 	viper.SetDefault(string(SPIWebSocketWriteBufferSize), "16Kb")
@@ -433,9 +535,16 @@ func setDefaults() {
 	viper.SetDefault(string(SPIWebSocketEventQueueLength), 250)
 	viper.SetDefault(string(CacheMessageSize), "50Mb")
 	viper.SetDefault(string(CacheMessageTTL), "5m")
+	viper.SetDefault(string(MessageBulkMaxMessages), 1000)
+	viper.SetDefault(string(MessageSchedulerBatchSize), 100)
+	viper.SetDefault(string(MessageSchedulerInterval), "1s")
+	viper.SetDefault(string(MessageThreadMaxDepth), 32)
+	viper.SetDefault(string(MessageThreadMaxSize), 1000)
 	viper.SetDefault(string(MessageWriterBatchMaxInserts), 200)
 	viper.SetDefault(string(MessageWriterBatchTimeout), "10ms")
 	viper.SetDefault(string(MessageWriterCount), 5)
+	viper.SetDefault(string(ValidatorMaxBlobSize), "10Mb")
+	viper.SetDefault(string(DataLargeValueThreshold), "0")
 	viper.SetDefault(string(NamespacesDefault), "default")
 	viper.SetDefault(string(NamespacesRetryFactor), 2.0)
 	viper.SetDefault(string(NamespacesRetryMaxDelay), "1m")
@@ -452,6 +561,7 @@ func setDefaults() {
 	viper.SetDefault(string(PrivateMessagingRetryInitDelay), "100ms")
 	viper.SetDefault(string(PrivateMessagingRetryMaxDelay), "30s")
 	viper.SetDefault(string(PrivateMessagingBatchAgentTimeout), "2m")
+	viper.SetDefault(string(PrivateMessagingBatchCompression), "none")
 	viper.SetDefault(string(PrivateMessagingBatchSize), 200)
 	viper.SetDefault(string(PrivateMessagingBatchTimeout), "1s")
 	viper.SetDefault(string(PrivateMessagingBatchPayloadLimit), "800Kb")
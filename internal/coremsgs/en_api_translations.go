@@ -29,6 +29,7 @@ var (
 	APIParamsContractListenerID             = ffm("api.params.contractListenerID", "The contract listener ID")
 	APIParamsSubscriptionID                 = ffm("api.params.subscriptionID", "The subscription ID")
 	APIParamsBatchID                        = ffm("api.params.batchId", "The batch ID")
+	APIParamsBlockchainTxnID                = ffm("api.params.blockchainTxnID", "The blockchain transaction ID, such as a transaction hash")
 	APIParamsBlockchainEventID              = ffm("api.params.blockchainEventID", "The blockchain event ID")
 	APIParamsCollectionID                   = ffm("api.params.collectionID", "The collection ID")
 	APIParamsContractAPIName                = ffm("api.params.contractAPIName", "The name of the contract API")
@@ -41,6 +42,7 @@ var (
 	APIParamsDataID                         = ffm("api.params.dataID", "The data item ID")
 	APIParamsDatatypeName                   = ffm("api.params.datatypeName", "The name of the datatype")
 	APIParamsDatatypeVersion                = ffm("api.params.datatypeVersion", "The version of the datatype")
+	APIParamsDatatypeToVersion              = ffm("api.params.datatypeToVersion", "The version of the datatype to compare against")
 	APIParamsDataParentPath                 = ffm("api.params.dataParentPath", "The parent path to query")
 	APIParamsEventID                        = ffm("api.params.eventID", "The event ID")
 	APIParamsFetchReferences                = ffm("api.params.fetchReferences", "When set, the API will return the record that this item references in its 'reference' field")
@@ -53,6 +55,8 @@ var (
 	APIParamsNodeNameOrID                   = ffm("api.params.nodeNameOrID", "The name or ID of the node")
 	APIParamsOrgNameOrID                    = ffm("api.params.orgNameOrID", "The name or ID of the org")
 	APIParamsTokenAccountKey                = ffm("api.params.tokenAccountKey", "The key for the token account. The exact format may vary based on the token connector use")
+	APIParamsTokenBalanceAsOf               = ffm("api.params.tokenBalanceAsOf", "Return balances as they were at this point in time, as given by the blockchain events of transfers. History is recorded from the upgrade of FireFly that introduced it, which records the balance of each account at that time")
+	APIParamsTokenBalanceAsOfProtocolID     = ffm("api.params.tokenBalanceAsOfProtocolID", "Return balances as they were after applying all transfers with a protocol ID less than or equal to this value. For most blockchain connectors the protocol ID begins with the zero-padded block number, so this can be used to query balances as of a block")
 	APIParamsTokenPoolNameOrID              = ffm("api.params.tokenPoolNameOrID", "The token pool name or ID")
 	APIParamsTokenTransferFromOrTo          = ffm("api.params.tokenTransferFromOrTo", "The sending or receiving token account for a token transfer")
 	APIParamsTokenTransferID                = ffm("api.params.tokenTransferID", "The token transfer ID")
@@ -67,14 +71,25 @@ var (
 	APIParamsContractAPIID                  = ffm("api.params.contractAPIID", "The ID of the contract API")
 	APIParamsFetchStatus                    = ffm("api.params.fetchStatus", "When set, the API will return additional status information if available")
 
-	APIEndpointsAdminGetNamespaceByName = ffm("api.endpoints.adminGetNamespaceByName", "Gets a namespace by name")
-	APIEndpointsAdminGetNamespaces      = ffm("api.endpoints.adminGetNamespaces", "List namespaces")
-	APIEndpointsAdminGetOpByID          = ffm("api.endpoints.adminGetOpByID", "Gets an operation by ID")
-	APIEndpointsAdminGetOps             = ffm("api.endpoints.adminGetOps", "Lists operations")
-	APIEndpointsAdminPostReset          = ffm("api.endpoints.adminPostResetConfig", "Restarts FireFly Core HTTP servers and apply all configuration updates")
-	APIEndpointsAdminPatchOpByID        = ffm("api.endpoints.adminPatchOpByID", "Updates an operation by ID")
-	APIEndpointsAdminGetListenerByID    = ffm("api.endpoints.adminGetListenerByID", "Gets a contract listener by ID")
-	APIEndpointsAdminGetListeners       = ffm("api.endpoints.adminGetListeners", "Lists contract listeners")
+	APIEndpointsAdminGetNamespaceByName    = ffm("api.endpoints.adminGetNamespaceByName", "Gets a namespace by name")
+	APIEndpointsAdminGetNamespaces         = ffm("api.endpoints.adminGetNamespaces", "List namespaces")
+	APIEndpointsAdminGetOpByID             = ffm("api.endpoints.adminGetOpByID", "Gets an operation by ID")
+	APIEndpointsAdminGetOps                = ffm("api.endpoints.adminGetOps", "Lists operations")
+	APIEndpointsAdminPostReset             = ffm("api.endpoints.adminPostResetConfig", "Restarts FireFly Core HTTP servers and apply all configuration updates")
+	APIEndpointsAdminGetNamespaceExport    = ffm("api.endpoints.adminGetNamespaceExport", "Streams every record in the namespace to a portable JSON lines archive, which can be imported into a namespace on a fresh database")
+	APIEndpointsAdminPostNamespaceImport   = ffm("api.endpoints.adminPostNamespaceImport", "Restores a namespace archive into an empty namespace in a single database transaction, verifying the record counts and hashes against the archive and the restored records. The namespace should be restarted once the import is complete")
+	APIEndpointsAdminGetAuditRecords       = ffm("api.endpoints.adminGetAuditRecords", "Lists the audit records of the mutating API requests made against the namespace, or of the global admin requests for the ff_system namespace")
+	APIEndpointsAdminGetAuditRecordsExport = ffm("api.endpoints.adminGetAuditRecordsExport", "Streams every audit record in the namespace as JSON lines, in hash chain order")
+	APIEndpointsAdminGetAuditRecordsVerify = ffm("api.endpoints.adminGetAuditRecordsVerify", "Verifies the hash chain of the audit records in the namespace, reporting the first record that has been modified or removed")
+	APIEndpointsAdminPatchOpByID           = ffm("api.endpoints.adminPatchOpByID", "Updates an operation by ID")
+	APIEndpointsAdminPostNamespace         = ffm("api.endpoints.adminPostNamespace", "Creates a namespace at runtime, using plugins that are already configured on the node. The definition is persisted, and the namespace is started in the background")
+	APIEndpointsAdminPutNamespace          = ffm("api.endpoints.adminPutNamespace", "Updates a namespace that was created through the API, and restarts it with the new definition")
+	APIEndpointsAdminPostNamespaceStop     = ffm("api.endpoints.adminPostNamespaceStop", "Stops a namespace that was created through the API. The namespace remains stopped across restarts until it is updated")
+	APIEndpointsAdminDeleteNamespace       = ffm("api.endpoints.adminDeleteNamespace", "Stops and removes a namespace that was created through the API. The data in the namespace is not deleted")
+	APIEndpointsAdminGetLivez              = ffm("api.endpoints.adminGetLivez", "Liveness probe, which succeeds whenever the admin API is able to serve requests")
+	APIEndpointsAdminGetReadyz             = ffm("api.endpoints.adminGetReadyz", "Readiness probe, which fails with a 503 if any namespace is still initializing, or has a plugin that fails its health probe")
+	APIEndpointsAdminGetListenerByID       = ffm("api.endpoints.adminGetListenerByID", "Gets a contract listener by ID")
+	APIEndpointsAdminGetListeners          = ffm("api.endpoints.adminGetListeners", "Lists contract listeners")
 
 	APIEndpointsDeleteContractAPI               = ffm("api.endpoints.deleteContractAPI", "Delete a contract API")
 	APIEndpointsDeleteContractInterface         = ffm("api.endpoints.deleteContractInterface", "Delete a contract interface")
@@ -94,13 +109,15 @@ var (
 	APIEndpointsGetContractListenerByNameOrID   = ffm("api.endpoints.getContractListenerByNameOrID", "Gets a contract listener by its name or ID")
 	APIEndpointsGetContractListeners            = ffm("api.endpoints.getContractListeners", "Gets a list of contract listeners")
 	APIEndpointsGetDataBlob                     = ffm("api.endpoints.getDataBlob", "Downloads the original file that was previously uploaded or received")
-	APIEndpointsGetDataValue                    = ffm("api.endpoints.getDataValue", "Downloads the JSON value of the data resource, without the associated metadata")
+	APIEndpointsGetDataValue                    = ffm("api.endpoints.getDataValue", "Downloads the JSON value of the data resource, without the associated metadata. Large values held in the blob store are streamed")
 	APIEndpointsGetDataByID                     = ffm("api.endpoints.getDataByID", "Gets a data item by its ID, including metadata about this item")
 	APIEndpointsDeleteData                      = ffm("api.endpoints.deleteData", "Deletes a data item by its ID, including metadata about this item")
+	APIEndpointsPostDataRedact                  = ffm("api.endpoints.postDataRedact", "Redacts the value and any blob of a private data item, keeping its hash so the messages and batches containing it still verify")
 	APIEndpointsGetDataMsgs                     = ffm("api.endpoints.getDataMsgs", "Gets a list of the messages associated with a data item")
 	APIEndpointsGetData                         = ffm("api.endpoints.getData", "Gets a list of data items")
 	APIEndpointsGetDataSubPaths                 = ffm("api.endpoints.getDataSubPaths", "Gets a list of path names of named blob data, underneath a given parent path ('/' path prefixes are automatically pre-prepended)")
 	APIEndpointsGetDatatypeByName               = ffm("api.endpoints.getDatatypeByName", "Gets a datatype by its name and version")
+	APIEndpointsGetDatatypeDiff                 = ffm("api.endpoints.getDatatypeDiff", "Compares two versions of a datatype, and reports the compatibility of each change")
 	APIEndpointsGetDatatypes                    = ffm("api.endpoints.getDatatypes", "Gets a list of datatypes that have been published")
 	APIEndpointsGetEventByID                    = ffm("api.endpoints.eventID", "Gets an event by its ID")
 	APIEndpointsGetEvents                       = ffm("api.endpoints.getEvents", "Gets a list of events")
@@ -113,6 +130,7 @@ var (
 	APIEndpointsGetMsgByID                      = ffm("api.endpoints.getMsgByID", "Gets a message by its ID")
 	APIEndpointsGetMsgData                      = ffm("api.endpoints.getMsgData", "Gets the list of data items that are attached to a message")
 	APIEndpointsGetMsgEvents                    = ffm("api.endpoints.getMsgEvents", "Gets the list of events for a message")
+	APIEndpointsGetMsgThread                    = ffm("api.endpoints.getMsgThread", "Gets a message and all the replies to it - the messages with a cid that chains back to the message")
 	APIEndpointsGetMsgTxn                       = ffm("api.endpoints.getMsgTxn", "Gets the transaction for a message")
 	APIEndpointsGetMsgs                         = ffm("api.endpoints.getMsgs", "Gets a list of messages")
 	APIEndpointsGetNamespace                    = ffm("api.endpoints.getNamespace", "Gets a namespace")
@@ -140,6 +158,7 @@ var (
 	APIEndpointsGetTokenAccounts                = ffm("api.endpoints.getTokenAccounts", "Gets a list of token accounts")
 	APIEndpointsGetTokenApprovals               = ffm("api.endpoints.getTokenApprovals", "Gets a list of token approvals")
 	APIEndpointsGetTokenBalances                = ffm("api.endpoints.getTokenBalances", "Gets a list of token balances")
+	APIEndpointsGetTokenBalanceHistory          = ffm("api.endpoints.getTokenBalanceHistory", "Gets the history of changes to token balances, which can be filtered to build a timeline for an account")
 	APIEndpointsGetTokenConnectors              = ffm("api.endpoints.getTokenConnectors", "Gets the list of token connectors currently in use")
 	APIEndpointsGetTokenPoolByNameOrID          = ffm("api.endpoints.getTokenPoolByNameOrID", "Gets a token pool by its name or its ID")
 	APIEndpointsGetTokenPools                   = ffm("api.endpoints.getTokenPools", "Gets a list of token pools")
@@ -150,6 +169,7 @@ var (
 	APIEndpointsGetTxnOps                       = ffm("api.endpoints.getTxnOps", "Gets a list of operations in a specific transaction")
 	APIEndpointsGetTxnStatus                    = ffm("api.endpoints.getTxnStatus", "Gets the status of a transaction")
 	APIEndpointsGetTxns                         = ffm("api.endpoints.getTxns", "Gets a list of transactions")
+	APIEndpointsGetBlockchainTxnByID            = ffm("api.endpoints.getBlockchainTxnByID", "Gets the FireFly transactions, operations, messages, token activity and blockchain events related to a blockchain transaction ID, such as a transaction hash")
 	APIEndpointsGetVerifierByHash               = ffm("api.endpoints.getVerifierByHash", "Gets a verifier by its hash")
 	APIEndpointsGetVerifiers                    = ffm("api.endpoints.getVerifiers", "Gets a list of verifiers")
 	APIEndpointsPatchUpdateIdentity             = ffm("api.endpoints.patchUpdateIdentity", "Updates an identity")
@@ -164,6 +184,7 @@ var (
 	APIEndpointsPostContractInterfacePublish    = ffm("api.endpoints.postContractInterfacePublish", "Publish a contract interface to all other members of the multiparty network")
 	APIEndpointsPostContractInvoke              = ffm("api.endpoints.postContractInvoke", "Invokes a method on a smart contract. Performs a blockchain transaction.")
 	APIEndpointsPostContractQuery               = ffm("api.endpoints.postContractQuery", "Queries a method on a smart contract. Performs a read-only query.")
+	APIEndpointsPostMsgCancel                   = ffm("api.endpoints.postMsgCancel", "Cancel a message that is waiting to be sent at its scheduled time")
 	APIEndpointsPostData                        = ffm("api.endpoints.postData", "Creates a new data item in this FireFly node")
 	APIEndpointsPostDataValuePublish            = ffm("api.endpoints.postDataValuePublish", "Publishes the JSON value from the specified data resource, to shared storage")
 	APIEndpointsPostDataBlobPublish             = ffm("api.endpoints.postDataBlobPublish", "Publishes the binary blob attachment stored in your local data exchange, to shared storage")
@@ -172,9 +193,13 @@ var (
 	APIEndpointsPostNewContractListener         = ffm("api.endpoints.postNewContractListener", "Creates a new blockchain listener for events emitted by custom smart contracts")
 	APIEndpointsPostContractListenerHash        = ffm("api.endpoints.postContractListenerHash", "Calculates the hash of a blockchain listener filters and events")
 	APIEndpointsPostNewDatatype                 = ffm("api.endpoints.postNewDatatype", "Creates and broadcasts a new datatype")
+	APIEndpointsPostDatatypeValidate            = ffm("api.endpoints.postDatatypeValidate", "Validates the stored data of a datatype against a proposed new version, without publishing it")
+	APIEndpointsPostGraphQL                     = ffm("api.endpoints.postGraphQL", "Runs a read-only GraphQL query against the messages, data, transactions, events, tokens and identities in the namespace")
 	APIEndpointsPostNewIdentity                 = ffm("api.endpoints.postNewIdentity", "Registers a new identity in the network")
 	APIEndpointsPostNewMessageBroadcast         = ffm("api.endpoints.postNewMessageBroadcast", "Broadcasts a message to all members in the network")
 	APIEndpointsPostNewMessagePrivate           = ffm("api.endpoints.postNewMessagePrivate", "Privately sends a message to one or more members in the network")
+	APIEndpointsPostNewMessageBroadcastBulk     = ffm("api.endpoints.postNewMessageBroadcastBulk", "Broadcasts an array of messages to all members in the network, returning a result for each message")
+	APIEndpointsPostNewMessagePrivateBulk       = ffm("api.endpoints.postNewMessagePrivateBulk", "Privately sends an array of messages, returning a result for each message")
 	APIEndpointsPostNewMessageRequestReply      = ffm("api.endpoints.postNewMessageRequestReply", "Sends a message with a blocking HTTP request, waits for a reply to that message, then sends the reply as the HTTP response.")
 	APIEndpointsPostNewNamespace                = ffm("api.endpoints.postNewNamespace", "Creates and broadcasts a new namespace")
 	APIEndpointsPostNodesSelf                   = ffm("api.endpoints.postNodesSelf", "Instructs this FireFly node to register itself on the network")
@@ -203,6 +228,9 @@ var (
 	APIFilterLimitDesc         = ffm("api.filterLimit", "The maximum number of records to return (max: %d)")
 	APIFilterCountDesc         = ffm("api.filterCount", "Return a total count as well as items (adds extra database processing)")
 	APIFetchDataDesc           = ffm("api.fetchData", "Fetch the data and include it in the messages returned")
+	APIBlockchainIDQueryParam  = ffm("api.blockchainIdQueryParam", "Only return transactions that include this blockchain transaction ID, such as a transaction hash. Uses an index, so is more efficient than a filter on blockchainids")
+	APIJSONPathQueryParam      = ffm("api.jsonPathQueryParam", "Match data with a JSON value at a dot separated path, in the format 'path=value' such as 'order.id=123'. Can be specified multiple times, and all must match")
+	APIMessageThreadDepthParam = ffm("api.messageThreadDepth", "The maximum number of levels of replies to include in the thread, where 1 only includes direct replies to the message. Defaults to the configured maximum")
 	APIConfirmMsgQueryParam    = ffm("api.confirmMsgQueryParam", "When true the HTTP request blocks until the message is confirmed")
 	APIConfirmInvokeQueryParam = ffm("api.confirmInvokeQueryParam", "When true the HTTP request blocks until the blockchain transaction is confirmed")
 	APIPublishQueryParam       = ffm("api.publishQueryParam", "When true the definition will be published to all other members of the multiparty network")
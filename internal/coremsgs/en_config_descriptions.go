@@ -38,11 +38,36 @@ var (
 	ConfigEventRetryInitialDelay = ffc("config.global.eventRetry.initialDelay", "The initial retry delay, for event processing", i18n.TimeDurationType)
 	ConfigEventRetryMaxDelay     = ffc("config.global.eventRetry.maxDelay", "The maximum retry delay, for event processing", i18n.TimeDurationType)
 
+	ConfigGlobalJWTKeyFile                = ffc("config.global.jwt.keyFile", "A PEM file containing the public key or certificate used to verify the signatures of JWT bearer tokens", i18n.StringType)
+	ConfigGlobalJWTClaim                  = ffc("config.global.jwt.claim", "The claim in a verified JWT that is used as the name of the principal", i18n.StringType)
+	ConfigGlobalJWTIssuer                 = ffc("config.global.jwt.issuer", "The issuer that must match the iss claim of a JWT. Any issuer is accepted if not set", i18n.StringType)
+	ConfigGlobalJWTAudience               = ffc("config.global.jwt.audience", "The audience that must be in the aud claim of a JWT. Any audience is accepted if not set", i18n.StringType)
+	ConfigGlobalJWTClockSkew              = ffc("config.global.jwt.clockSkew", "The allowed difference between the local clock and the clock of the token issuer, when checking the exp and nbf claims", i18n.TimeDurationType)
+	ConfigGlobalJWTJWKSFile               = ffc("config.global.jwt.jwks.file", "A file containing a JSON Web Key Set used to verify the signatures of JWT bearer tokens", i18n.StringType)
+	ConfigGlobalJWTJWKSURL                = ffc("config.global.jwt.jwks.url", "The URL of a JSON Web Key Set used to verify the signatures of JWT bearer tokens, such as the jwks_uri of an OpenID Connect provider", urlStringType)
+	ConfigGlobalJWTJWKSMinRefreshInterval = ffc("config.global.jwt.jwks.minRefreshInterval", "The minimum time between fetches of the JSON Web Key Set from jwks.url, when a token is signed with an unknown key", i18n.TimeDurationType)
+	ConfigGlobalJWTJWKSMaxAge             = ffc("config.global.jwt.jwks.maxAge", "The maximum time the keys fetched from jwks.url are trusted, before they must be fetched again. Tokens are rejected while older keys cannot be fetched again, so keys revoked by the identity provider stop being accepted", i18n.TimeDurationType)
+	ConfigGlobalJWTSigningKeysClaim       = ffc("config.global.jwt.signingKeysClaim", "A claim listing the signing keys the principal is allowed to use. Principals whose token has the claim must set one of these keys on every request that signs. Not checked if not set", i18n.StringType)
+	ConfigGlobalRBACMTLSEnabled           = ffc("config.global.rbac.mtls.enabled", "Use the subject of a verified TLS client certificate as the principal", i18n.BooleanType)
+	ConfigGlobalRBACMTLSPrincipal         = ffc("config.global.rbac.mtls.principal", "The part of the client certificate subject used as the name of the principal - 'cn' for the common name, or 'subject' for the full distinguished name", i18n.StringType)
+	ConfigGlobalRBACRoles                 = ffc("config.global.rbac.roles", "The roles that can be granted to principals", i18n.ArrayStringType)
+	ConfigGlobalRBACRoleName              = ffc("config.global.rbac.roles[].name", "The name of the role, which is included in decision logs", i18n.StringType)
+	ConfigGlobalRBACRoleNamespace         = ffc("config.global.rbac.roles[].namespace", "The namespace the role applies in. The role applies in all namespaces if this is not set", i18n.StringType)
+	ConfigGlobalRBACRolePrincipals        = ffc("config.global.rbac.roles[].principals", "The principals granted the role, as type:name such as basic:alice, jwt:alice or mtls:alice. Use * for all authenticated principals", i18n.ArrayStringType)
+	ConfigGlobalRBACRoleRules             = ffc("config.global.rbac.roles[].rules", "The rules of the role. A request is allowed if it matches an allow rule, and no deny rule, in any role held by the principal", i18n.ArrayStringType)
+	ConfigGlobalRBACRuleEffect            = ffc("config.global.rbac.roles[].rules[].effect", "Whether a request matching the rule is allowed or denied - 'allow' (the default) or 'deny'", i18n.StringType)
+	ConfigGlobalRBACRuleGroups            = ffc("config.global.rbac.roles[].rules[].groups", "The route groups the rule matches - messages, tokens, contracts, subscriptions, identities, transactions, graphql or admin. Matches all groups if not set", i18n.ArrayStringType)
+	ConfigGlobalRBACRuleMethods           = ffc("config.global.rbac.roles[].rules[].methods", "The HTTP methods the rule matches. Matches all methods if not set", i18n.ArrayStringType)
+	ConfigGlobalRBACRuleContractAPIs      = ffc("config.global.rbac.roles[].rules[].contractAPIs", "The contract APIs the rule matches, by name or ID. A contract API that cannot be found is only matched by deny rules, and by *. Matches all requests if not set", i18n.ArrayStringType)
+	ConfigGlobalRBACRuleContractMethods   = ffc("config.global.rbac.roles[].rules[].contractMethods", "The contract method paths the rule matches. Matches all requests if not set", i18n.ArrayStringType)
+	ConfigGlobalRBACRuleTokenPools        = ffc("config.global.rbac.roles[].rules[].tokenPools", "The token pools the rule matches, by name or ID. A token pool that cannot be found is only matched by deny rules, and by *. Matches all requests if not set", i18n.ArrayStringType)
+
 	ConfigConfigAutoReload = ffc("config.config.autoReload", "Monitor the configuration file for changes, and automatically add/remove/reload namespaces and plugins", i18n.BooleanType)
 
 	ConfigLegacyAdmin     = ffc("config.admin.enabled", "Deprecated - use spi.enabled instead", i18n.BooleanType)
 	ConfigSPIAddress      = ffc("config.spi.address", "The IP address on which the admin HTTP API should listen", "IP Address "+i18n.StringType)
 	ConfigSPIEnabled      = ffc("config.spi.enabled", "Enables the admin HTTP API", i18n.BooleanType)
+	ConfigSPIGlobalAuth   = ffc("config.spi.globalAuthPlugin", "The name of an auth plugin in plugins.auth that authorizes the admin API routes that are not in a namespace, such as creating namespaces and the admin change event websocket. These routes are only protected by spi.auth if this is not set", i18n.StringType)
 	ConfigSPIPort         = ffc("config.spi.port", "The port on which the admin HTTP API should listen", i18n.IntType)
 	ConfigSPIPublicURL    = ffc("config.spi.publicURL", "The fully qualified public URL for the admin API. This is used for building URLs in HTTP responses and in OpenAPI Spec generation", urlStringType)
 	ConfigSPIReadTimeout  = ffc("config.spi.readTimeout", "The maximum time to wait when reading from an HTTP connection", i18n.TimeDurationType)
@@ -53,11 +78,30 @@ var (
 	ConfigAPIRequestMaxTimeout  = ffc("config.api.requestMaxTimeout", "The maximum amount of time that an HTTP client can specify in a `Request-Timeout` header to keep a specific request open", i18n.TimeDurationType)
 	ConfigAPIPassthroughHeaders = ffc("config.api.passthroughHeaders", "A list of HTTP request headers to pass through to dependency microservices", i18n.ArrayStringType)
 
+	ConfigAPIRateLimitNamespaceRequestsPerSecond = ffc("config.api.rateLimit.namespace.requestsPerSecond", "The rate that requests to each namespace are allowed at, with each message in a bulk request counted separately. Requests above the rate are rejected with a 429 status. No limit if not set", i18n.FloatType)
+	ConfigAPIRateLimitNamespaceBurst             = ffc("config.api.rateLimit.namespace.burst", "The number of requests to each namespace allowed in a burst above the rate. Defaults to one second of requests", i18n.IntType)
+	ConfigAPIRateLimitPrincipalRequestsPerSecond = ffc("config.api.rateLimit.principal.requestsPerSecond", "The rate that requests from each principal in a namespace are allowed at. The principal comes from the auth plugin, or is the client address if the plugin does not identify one. No limit if not set", i18n.FloatType)
+	ConfigAPIRateLimitPrincipalBurst             = ffc("config.api.rateLimit.principal.burst", "The number of requests from each principal allowed in a burst above the rate. Defaults to one second of requests", i18n.IntType)
+	ConfigAPIRateLimitGroups                     = ffc("config.api.rateLimit.groups", "Limits on the rate of requests from each principal in a namespace to a route group", i18n.ArrayStringType)
+	ConfigAPIRateLimitGroupsName                 = ffc("config.api.rateLimit.groups[].name", "The route group the limit applies to - messages, tokens, contracts, subscriptions, identities, transactions, graphql or admin", i18n.StringType)
+	ConfigAPIRateLimitGroupsRequestsPerSecond    = ffc("config.api.rateLimit.groups[].requestsPerSecond", "The rate that requests from each principal to the route group are allowed at", i18n.FloatType)
+	ConfigAPIRateLimitGroupsBurst                = ffc("config.api.rateLimit.groups[].burst", "The number of requests from each principal to the route group allowed in a burst above the rate. Defaults to one second of requests", i18n.IntType)
+	ConfigAPIQuotaNamespaceDailyChainWrites      = ffc("config.api.quota.namespaceDailyChainWrites", "The number of requests that write to the blockchain allowed in each namespace per UTC day, with each message in a bulk request counted separately. Quotas are counted in memory by each node, so restarting a node resets the count for the day. No quota if not set", i18n.IntType)
+	ConfigAPIQuotaPrincipalDailyChainWrites      = ffc("config.api.quota.principalDailyChainWrites", "The number of requests that write to the blockchain allowed from each principal in a namespace per UTC day, with each message in a bulk request counted separately. Quotas are counted in memory by each node, so restarting a node resets the count for the day. No quota if not set", i18n.IntType)
+	ConfigAPIGraphQLMaxDepth                     = ffc("config.api.graphql.maxDepth", "The deepest that fields can be nested in a GraphQL query. Deeper queries are rejected before they run. No limit if set to 0", i18n.IntType)
+	ConfigAPIGraphQLMaxTokens                    = ffc("config.api.graphql.maxTokens", "The most tokens, such as names and punctuation, that a GraphQL query can contain. Longer queries are rejected while they are parsed. No limit if set to 0", i18n.IntType)
+	ConfigAPIGraphQLMaxNodes                     = ffc("config.api.graphql.maxNodes", "The most resources that a GraphQL query can resolve, counting each list as its limit (or the default limit, for lists that cannot be paged). Queries that could resolve more are rejected before they run. No limit if set to 0", i18n.IntType)
+	ConfigAPIAuditEnabled                        = ffc("config.api.audit.enabled", "Records every POST, PUT, PATCH and DELETE request to a namespace in the hash-chained audit log of the namespace, and every such global admin request in the node-level log of the ff_system namespace. The logs can be queried, exported and verified on the admin API. Each record is written in its own database transaction, one at a time for each log, which limits the throughput of mutating requests to a single namespace", i18n.BooleanType)
+
 	ConfigAssetManagerKeyNormalization = ffc("config.asset.manager.keyNormalization", "Mechanism to normalize keys before using them. Valid options are `blockchain_plugin` - use blockchain plugin (default) or `none` - do not attempt normalization (deprecated - use namespaces.predefined[].asset.manager.keyNormalization)", i18n.StringType)
 
 	ConfigBatchManagerMinimumPollDelay = ffc("config.batch.manager.minimumPollDelay", "The minimum time the batch manager waits between polls on the DB - to prevent thrashing", i18n.TimeDurationType)
 	ConfigBatchManagerPollTimeout      = ffc("config.batch.manager.pollTimeout", "How long to wait without any notifications of new messages before doing a page query", i18n.TimeDurationType)
 	ConfigBatchManagerReadPageSize     = ffc("config.batch.manager.readPageSize", "The size of each page of messages read from the database into memory when assembling batches", i18n.IntType)
+	ConfigBatchPriorityHighSize        = ffc("config.batch.priority.high.size", "The maximum number of messages in a batch of high priority messages. Defaults to the batch size of the broadcast or private messaging dispatcher", i18n.IntType)
+	ConfigBatchPriorityHighTimeout     = ffc("config.batch.priority.high.timeout", "The timeout to wait for a batch of high priority messages to fill, before sending. Defaults to the batch timeout of the broadcast or private messaging dispatcher", i18n.TimeDurationType)
+	ConfigBatchPriorityLowSize         = ffc("config.batch.priority.low.size", "The maximum number of messages in a batch of low priority messages. Defaults to the batch size of the broadcast or private messaging dispatcher", i18n.IntType)
+	ConfigBatchPriorityLowTimeout      = ffc("config.batch.priority.low.timeout", "The timeout to wait for a batch of low priority messages to fill, before sending. Defaults to the batch timeout of the broadcast or private messaging dispatcher", i18n.TimeDurationType)
 
 	ConfigBlobreceiverWorkerBatchMaxInserts = ffc("config.blobreceiver.worker.batchMaxInserts", "The maximum number of items the blob receiver worker will insert in a batch", i18n.IntType)
 	ConfigBlobreceiverWorkerBatchTimeout    = ffc("config.blobreceiver.worker.batchTimeout", "The maximum amount of the the blob receiver worker will wait", i18n.TimeDurationType)
@@ -139,11 +183,15 @@ var (
 	ConfigPluginDatabaseName = ffc("config.plugins.database[].name", "The name of the Database plugin", i18n.StringType)
 	ConfigPluginDatabaseType = ffc("config.plugins.database[].type", "The type of the configured Database plugin", i18n.StringType)
 
-	ConfigPluginDatabasePostgresMaxConnIdleTime = ffc("config.plugins.database[].postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigPluginDatabasePostgresMaxConnLifetime = ffc("config.plugins.database[].postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigPluginDatabasePostgresMaxConns        = ffc("config.plugins.database[].postgres.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigPluginDatabasePostgresMaxIdleConns    = ffc("config.plugins.database[].postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigPluginDatabasePostgresURL             = ffc("config.plugins.database[].postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
+	ConfigPluginDatabasePostgresMaxConnIdleTime             = ffc("config.plugins.database[].postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresMaxConnLifetime             = ffc("config.plugins.database[].postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresMaxConns                    = ffc("config.plugins.database[].postgres.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigPluginDatabasePostgresMaxIdleConns                = ffc("config.plugins.database[].postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigPluginDatabasePostgresURL                         = ffc("config.plugins.database[].postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
+	ConfigPluginDatabasePostgresReadReplicaURL              = ffc("config.plugins.database[].postgres.readReplica.url", "The PostgreSQL connection string for an optional read replica. When set, non-transactional API queries are routed to the replica while its replication lag is within the configured maximum", i18n.StringType)
+	ConfigPluginDatabasePostgresReadReplicaMaxConns         = ffc("config.plugins.database[].postgres.readReplica.maxConns", "Maximum connections to the read replica. Defaults to the maximum connections of the primary database", i18n.IntType)
+	ConfigPluginDatabasePostgresReadReplicaMaxLag           = ffc("config.plugins.database[].postgres.readReplica.maxLag", "The maximum replication lag of the read replica, beyond which queries are routed to the primary database", i18n.TimeDurationType)
+	ConfigPluginDatabasePostgresReadReplicaLagCheckInterval = ffc("config.plugins.database[].postgres.readReplica.lagCheckInterval", "How often to check the replication lag of the read replica", i18n.TimeDurationType)
 
 	ConfigPluginDatabaseSqlite3MaxConnIdleTime = ffc("config.plugins.database[].sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
 	ConfigPluginDatabaseSqlite3MaxConnLifetime = ffc("config.plugins.database[].sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
@@ -225,17 +273,22 @@ var (
 	ConfigPluginBlockchainFabricFabconnectChannel                     = ffc("config.plugins.blockchain[].fabric.fabconnect.channel", "The Fabric channel that FireFly will use for BatchPin transactions", i18n.StringType)
 
 	ConfigBroadcastBatchAgentTimeout = ffc("config.broadcast.batch.agentTimeout", "How long to keep around a batching agent for a sending identity before disposal", i18n.StringType)
+	ConfigBroadcastBatchCompression  = ffc("config.broadcast.batch.compression", "The compression applied to batches uploaded to shared storage - one of `none`, `gzip` or `zstd`. Batches are only compressed once every registered node advertises support for the selected compression", i18n.StringType)
 	ConfigBroadcastBatchPayloadLimit = ffc("config.broadcast.batch.payloadLimit", "The maximum payload size of a batch for broadcast messages", i18n.ByteSizeType)
 	ConfigBroadcastBatchSize         = ffc("config.broadcast.batch.size", "The maximum number of messages that can be packed into a batch", i18n.IntType)
 	ConfigBroadcastBatchTimeout      = ffc("config.broadcast.batch.timeout", "The timeout to wait for a batch to fill, before sending", i18n.TimeDurationType)
 
 	ConfigDatabaseType = ffc("config.database.type", "The type of the database interface plugin to use", i18n.IntType)
 
-	ConfigDatabasePostgresMaxConnIdleTime = ffc("config.database.postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
-	ConfigDatabasePostgresMaxConnLifetime = ffc("config.database.postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
-	ConfigDatabasePostgresMaxConns        = ffc("config.database.postgres.maxConns", "Maximum connections to the database", i18n.IntType)
-	ConfigDatabasePostgresMaxIdleConns    = ffc("config.database.postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
-	ConfigDatabasePostgresURL             = ffc("config.database.postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
+	ConfigDatabasePostgresMaxConnIdleTime             = ffc("config.database.postgres.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
+	ConfigDatabasePostgresMaxConnLifetime             = ffc("config.database.postgres.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
+	ConfigDatabasePostgresMaxConns                    = ffc("config.database.postgres.maxConns", "Maximum connections to the database", i18n.IntType)
+	ConfigDatabasePostgresMaxIdleConns                = ffc("config.database.postgres.maxIdleConns", "The maximum number of idle connections to the database", i18n.IntType)
+	ConfigDatabasePostgresURL                         = ffc("config.database.postgres.url", "The PostgreSQL connection string for the database", i18n.StringType)
+	ConfigDatabasePostgresReadReplicaURL              = ffc("config.database.postgres.readReplica.url", "The PostgreSQL connection string for an optional read replica. When set, non-transactional API queries are routed to the replica while its replication lag is within the configured maximum", i18n.StringType)
+	ConfigDatabasePostgresReadReplicaMaxConns         = ffc("config.database.postgres.readReplica.maxConns", "Maximum connections to the read replica. Defaults to the maximum connections of the primary database", i18n.IntType)
+	ConfigDatabasePostgresReadReplicaMaxLag           = ffc("config.database.postgres.readReplica.maxLag", "The maximum replication lag of the read replica, beyond which queries are routed to the primary database", i18n.TimeDurationType)
+	ConfigDatabasePostgresReadReplicaLagCheckInterval = ffc("config.database.postgres.readReplica.lagCheckInterval", "How often to check the replication lag of the read replica", i18n.TimeDurationType)
 
 	ConfigDatabaseSqlite3MaxConnIdleTime = ffc("config.database.sqlite3.maxConnIdleTime", "The maximum amount of time a database connection can be idle", i18n.TimeDurationType)
 	ConfigDatabaseSqlite3MaxConnLifetime = ffc("config.database.sqlite3.maxConnLifetime", "The maximum amount of time to keep a database connection open", i18n.TimeDurationType)
@@ -245,9 +298,10 @@ var (
 
 	ConfigDataexchangeType = ffc("config.dataexchange.type", "The Data Exchange plugin to use", i18n.StringType)
 
-	ConfigDataexchangeFfdxInitEnabled     = ffc("config.dataexchange.ffdx.initEnabled", "Instructs FireFly to always post all current nodes to the `/init` API before connecting or reconnecting to the connector", i18n.BooleanType)
-	ConfigDataexchangeFfdxManifestEnabled = ffc("config.dataexchange.ffdx.manifestEnabled", "Determines whether to require+validate a manifest from other DX instances in the network. Must be supported by the connector", i18n.StringType)
-	ConfigDataexchangeFfdxURL             = ffc("config.dataexchange.ffdx.url", "The URL of the Data Exchange instance", urlStringType)
+	ConfigDataexchangeFfdxInitEnabled         = ffc("config.dataexchange.ffdx.initEnabled", "Instructs FireFly to always post all current nodes to the `/init` API before connecting or reconnecting to the connector", i18n.BooleanType)
+	ConfigDataexchangeFfdxMaxDecompressedSize = ffc("config.dataexchange.ffdx.maxDecompressedSize", "The maximum size a compressed batch received from a peer is allowed to expand to when decompressed", i18n.ByteSizeType)
+	ConfigDataexchangeFfdxManifestEnabled     = ffc("config.dataexchange.ffdx.manifestEnabled", "Determines whether to require+validate a manifest from other DX instances in the network. Must be supported by the connector", i18n.StringType)
+	ConfigDataexchangeFfdxURL                 = ffc("config.dataexchange.ffdx.url", "The URL of the Data Exchange instance", urlStringType)
 
 	ConfigDataexchangeFfdxProxyURL = ffc("config.dataexchange.ffdx.proxy.url", "Optional HTTP proxy server to use when connecting to the Data Exchange", urlStringType)
 
@@ -256,6 +310,7 @@ var (
 	ConfigPluginDataexchangeName = ffc("config.plugins.dataexchange[].name", "The name of the configured Data Exchange plugin", i18n.StringType)
 
 	ConfigPluginDataexchangeFfdxInitEnabled                 = ffc("config.plugins.dataexchange[].ffdx.initEnabled", "Instructs FireFly to always post all current nodes to the `/init` API before connecting or reconnecting to the connector", i18n.BooleanType)
+	ConfigPluginDataexchangeFfdxMaxDecompressedSize         = ffc("config.plugins.dataexchange[].ffdx.maxDecompressedSize", "The maximum size a compressed batch received from a peer is allowed to expand to when decompressed", i18n.ByteSizeType)
 	ConfigPluginDataexchangeFfdxManifestEnabled             = ffc("config.plugins.dataexchange[].ffdx.manifestEnabled", "Determines whether to require+validate a manifest from other DX instances in the network. Must be supported by the connector", i18n.StringType)
 	ConfigPluginDataexchangeFfdxURL                         = ffc("config.plugins.dataexchange[].ffdx.url", "The URL of the Data Exchange instance", urlStringType)
 	ConfigPluginDataexchangeFfdxBackgroundStart             = ffc("config.plugins.dataexchange[].ffdx.backgroundStart.enabled", "Start the data exchange plugin in the background and enter retry loop if failed to start", i18n.BooleanType)
@@ -273,6 +328,7 @@ var (
 
 	ConfigEventAggregatorBatchSize         = ffc("config.event.aggregator.batchSize", "The maximum number of records to read from the DB before performing an aggregation run", i18n.ByteSizeType)
 	ConfigEventAggregatorBatchTimeout      = ffc("config.event.aggregator.batchTimeout", "How long to wait for new events to arrive before performing aggregation on a page of events", i18n.TimeDurationType)
+	ConfigEventAggregatorExpiryInterval    = ffc("config.event.aggregator.expiryInterval", "How often to check for messages on this node that have passed their expiry before being batched, and cancel them", i18n.TimeDurationType)
 	ConfigEventAggregatorFirstEvent        = ffc("config.event.aggregator.firstEvent", "The first event the aggregator should process, if no previous offest is stored in the DB. Valid options are `oldest` or `newest`", i18n.StringType)
 	ConfigEventAggregatorPollTimeout       = ffc("config.event.aggregator.pollTimeout", "The time to wait without a notification of new events, before trying a select on the table", i18n.TimeDurationType)
 	ConfigEventAggregatorRewindQueueLength = ffc("config.event.aggregator.rewindQueueLength", "The size of the queue into the rewind dispatcher", i18n.IntType)
@@ -287,6 +343,15 @@ var (
 	ConfigEventTransportsDefault = ffc("config.event.transports.default", "The default event transport for new subscriptions", i18n.StringType)
 	ConfigEventTransportsEnabled = ffc("config.event.transports.enabled", "Which event interface plugins are enabled", i18n.BooleanType)
 
+	ConfigGRPCAddress        = ffc("config.grpc.address", "The IP address on which the gRPC API should listen", "IP Address "+i18n.StringType)
+	ConfigGRPCEnabled        = ffc("config.grpc.enabled", "Enables the gRPC API", i18n.BooleanType)
+	ConfigGRPCMaxMessageSize = ffc("config.grpc.maxMessageSize", "The maximum size of a request message sent to the gRPC API", i18n.ByteSizeType)
+	ConfigGRPCPort           = ffc("config.grpc.port", "The port on which the gRPC API should listen", i18n.IntType)
+	ConfigGRPCReadTimeout    = ffc("config.grpc.readTimeout", "The maximum time to wait for a gRPC client to complete the handshake of a new connection", i18n.TimeDurationType)
+
+	ConfigHealthEventStreamMaxIdle = ffc("config.health.eventStreamMaxIdle", "The time since the last event received on a connected event stream, after which the stream is reported as lagging and the plugin as unhealthy. Set to zero to disable", i18n.TimeDurationType)
+	ConfigHealthTimeout            = ffc("config.health.timeout", "The maximum time to wait for each plugin to respond to a health probe", i18n.TimeDurationType)
+
 	ConfigHistogramsMaxChartRows = ffc("config.histograms.maxChartRows", "The maximum rows to fetch for each histogram bucket", i18n.IntType)
 
 	ConfigHTTPAddress      = ffc("config.http.address", "The IP address on which the HTTP API should listen", "IP Address "+i18n.StringType)
@@ -312,10 +377,18 @@ var (
 	ConfigLogTimeFormat = ffc("config.log.timeFormat", "Custom time format for logs", i18n.TimeFormatType)
 	ConfigLogUtc        = ffc("config.log.utc", "Use UTC timestamps for logs", i18n.BooleanType)
 
+	ConfigMessageBulkMaxMessages       = ffc("config.message.bulk.maxMessages", "The maximum number of messages that can be submitted in a single bulk request", i18n.IntType)
+	ConfigMessageSchedulerBatchSize    = ffc("config.message.scheduler.batchSize", "The maximum number of scheduled messages to release for sending in a single database transaction", i18n.IntType)
+	ConfigMessageSchedulerInterval     = ffc("config.message.scheduler.interval", "How often to check for scheduled messages that are due to be sent", i18n.TimeDurationType)
+	ConfigMessageThreadMaxDepth        = ffc("config.message.thread.maxDepth", "The maximum depth of replies that can be requested when querying the thread of a message", i18n.IntType)
+	ConfigMessageThreadMaxSize         = ffc("config.message.thread.maxSize", "The maximum number of messages in a thread that can be queried, before the request must be narrowed with a smaller depth", i18n.IntType)
 	ConfigMessageWriterBatchMaxInserts = ffc("config.message.writer.batchMaxInserts", "The maximum number of database inserts to include when writing a single batch of messages + data", i18n.IntType)
 	ConfigMessageWriterBatchTimeout    = ffc("config.message.writer.batchTimeout", "How long to wait for more messages to arrive before flushing the batch", i18n.TimeDurationType)
 	ConfigMessageWriterCount           = ffc("config.message.writer.count", "The number of message writer workers", i18n.IntType)
 
+	ConfigDataLargeValueThreshold = ffc("config.data.largeValueThreshold", "JSON values larger than this size are held in the data exchange blob store instead of the database, and are streamed when needed. Zero disables this. Requires a data exchange plugin", i18n.ByteSizeType)
+	ConfigValidatorMaxBlobSize    = ffc("config.validator.maxBlobSize", "The maximum size of blob that is validated against an XML Schema, Protobuf or Avro datatype. Larger blobs fail validation", i18n.ByteSizeType)
+
 	ConfigTransactionWriterBatchMaxTransactions = ffc("config.transaction.writer.batchMaxTransactions", "The maximum number of transaction inserts to include in a batch", i18n.IntType)
 	ConfigTransactionWriterBatchTimeout         = ffc("config.transaction.writer.batchTimeout", "How long to wait for more transactions to arrive before flushing the batch", i18n.TimeDurationType)
 	ConfigTransactionWriterCount                = ffc("config.transaction.writer.count", "The number of message writer workers", i18n.IntType)
@@ -328,16 +401,31 @@ var (
 	ConfigMetricsReadTimeout  = ffc("config.metrics.readTimeout", "The maximum time to wait when reading from an HTTP connection", i18n.TimeDurationType)
 	ConfigMetricsWriteTimeout = ffc("config.metrics.writeTimeout", "The maximum time to wait when writing to an HTTP connection", i18n.TimeDurationType)
 
-	ConfigNamespacesDefault                    = ffc("config.namespaces.default", "The default namespace - must be in the predefined list", i18n.StringType)
-	ConfigNamespacesPredefined                 = ffc("config.namespaces.predefined", "A list of namespaces to ensure exists, without requiring a broadcast from the network", "List "+i18n.StringType)
-	ConfigNamespacesPredefinedName             = ffc("config.namespaces.predefined[].name", "The name of the namespace (must be unique)", i18n.StringType)
-	ConfigNamespacesPredefinedDescription      = ffc("config.namespaces.predefined[].description", "A description for the namespace", i18n.StringType)
-	ConfigNamespacesPredefinedPlugins          = ffc("config.namespaces.predefined[].plugins", "The list of plugins for this namespace", i18n.StringType)
-	ConfigNamespacesPredefinedDefaultKey       = ffc("config.namespaces.predefined[].defaultKey", "A default signing key for blockchain transactions within this namespace", i18n.StringType)
-	ConfigNamespacesPredefinedKeyNormalization = ffc("config.namespaces.predefined[].asset.manager.keyNormalization", "Mechanism to normalize keys before using them. Valid options are `blockchain_plugin` - use blockchain plugin (default) or `none` - do not attempt normalization", i18n.StringType)
-	ConfigNamespacesPredefinedTLSConfigs       = ffc("config.namespaces.predefined[].tlsConfigs", "Supply a set of tls certificates to be used by subscriptions for this namespace", "List "+i18n.StringType)
-	ConfigNamespacesPredefinedTLSConfigsName   = ffc("config.namespaces.predefined[].tlsConfigs[].name", "Name of the TLS Config", i18n.StringType)
+	ConfigNamespacesDefault                             = ffc("config.namespaces.default", "The default namespace - must be in the predefined list", i18n.StringType)
+	ConfigNamespacesPredefined                          = ffc("config.namespaces.predefined", "A list of namespaces to ensure exists, without requiring a broadcast from the network", "List "+i18n.StringType)
+	ConfigNamespacesPredefinedName                      = ffc("config.namespaces.predefined[].name", "The name of the namespace (must be unique)", i18n.StringType)
+	ConfigNamespacesPredefinedDescription               = ffc("config.namespaces.predefined[].description", "A description for the namespace", i18n.StringType)
+	ConfigNamespacesPredefinedPlugins                   = ffc("config.namespaces.predefined[].plugins", "The list of plugins for this namespace", i18n.StringType)
+	ConfigNamespacesPredefinedDefaultKey                = ffc("config.namespaces.predefined[].defaultKey", "A default signing key for blockchain transactions within this namespace", i18n.StringType)
+	ConfigNamespacesPredefinedKeyNormalization          = ffc("config.namespaces.predefined[].asset.manager.keyNormalization", "Mechanism to normalize keys before using them. Valid options are `blockchain_plugin` - use blockchain plugin (default) or `none` - do not attempt normalization", i18n.StringType)
+	ConfigNamespacesPredefinedRetentionMaxAge           = ffc("config.namespaces.predefined[].retention.maxAge", "The age beyond which confirmed messages and their data, events and completed operations are purged from this namespace, along with transactions and blockchain events that no remaining record refers to. Retention is disabled when not set", i18n.TimeDurationType)
+	ConfigNamespacesPredefinedRetentionInterval         = ffc("config.namespaces.predefined[].retention.interval", "How often to check for records that have passed the retention age", i18n.TimeDurationType)
+	ConfigNamespacesPredefinedRetentionBatchSize        = ffc("config.namespaces.predefined[].retention.batchSize", "The number of records archived and deleted in each database transaction", i18n.IntType)
+	ConfigNamespacesPredefinedRetentionArchiveDirectory = ffc("config.namespaces.predefined[].retention.archiveDirectory", "A directory to which records are written as JSON lines before they are deleted. Records are deleted without being archived when not set", i18n.StringType)
+	ConfigNamespacesPredefinedTLSConfigs                = ffc("config.namespaces.predefined[].tlsConfigs", "Supply a set of tls certificates to be used by subscriptions for this namespace", "List "+i18n.StringType)
+	ConfigNamespacesPredefinedTLSConfigsName            = ffc("config.namespaces.predefined[].tlsConfigs[].name", "Name of the TLS Config", i18n.StringType)
 	// ConfigNamespacesPredefinedTLSConfigsTLS      = ffc("config.namespaces.predefined[].tlsConfigs[].tls", "Specify the path to a CA, Cert and Key for TLS communication", i18n.StringType)
+	ConfigNamespacesPredefinedOperationsCheckInterval           = ffc("config.namespaces.predefined[].operations.checkInterval", "How often operations are checked against the timeout and retry policies of this namespace", i18n.TimeDurationType)
+	ConfigNamespacesPredefinedOperationsPolicies                = ffc("config.namespaces.predefined[].operations.policies", "A list of timeout and retry policies, each for a single operation type", "List "+i18n.StringType)
+	ConfigNamespacesPredefinedOperationsPolicyType              = ffc("config.namespaces.predefined[].operations.policies[].type", "The operation type the policy applies to, such as `blockchain_invoke`", i18n.StringType)
+	ConfigNamespacesPredefinedOperationsPolicyTimeout           = ffc("config.namespaces.predefined[].operations.policies[].timeout", "The time a pending operation can go without an update from the connector, before the timeout action is taken. Disabled when not set", i18n.TimeDurationType)
+	ConfigNamespacesPredefinedOperationsPolicyTimeoutAction     = ffc("config.namespaces.predefined[].operations.policies[].timeoutAction", "The action taken when an operation times out. Valid options are `fail` to mark the operation failed, or `query` to query the status of the transaction from the blockchain connector (blockchain operations only). Blockchain operations that are retried must use `query`, as a transaction that timed out could still be mined", i18n.StringType)
+	ConfigNamespacesPredefinedOperationsPolicyRetryMaxAttempts  = ffc("config.namespaces.predefined[].operations.policies[].retry.maxAttempts", "The maximum number of times a failed operation is retried automatically. Automatic retry is disabled when zero", i18n.IntType)
+	ConfigNamespacesPredefinedOperationsPolicyRetryInitialDelay = ffc("config.namespaces.predefined[].operations.policies[].retry.initialDelay", "The delay after a failure before the first automatic retry", i18n.TimeDurationType)
+	ConfigNamespacesPredefinedOperationsPolicyRetryMaxDelay     = ffc("config.namespaces.predefined[].operations.policies[].retry.maxDelay", "The maximum delay after a failure before an automatic retry", i18n.TimeDurationType)
+	ConfigNamespacesPredefinedOperationsPolicyRetryFactor       = ffc("config.namespaces.predefined[].operations.policies[].retry.factor", "The factor by which the delay increases for each retry of the same operation", i18n.FloatType)
+	ConfigNamespacesPredefinedOperationsPolicyRetryErrors       = ffc("config.namespaces.predefined[].operations.policies[].retry.errors", "A list of regular expressions matched against the error of a failed operation. The operation is only retried if one matches. All failures are retried when not set", "List "+i18n.StringType)
+
 	ConfigNamespacesMultipartyEnabled            = ffc("config.namespaces.predefined[].multiparty.enabled", "Enables multi-party mode for this namespace (defaults to true if an org name or key is configured, either here or at the root level)", i18n.BooleanType)
 	ConfigNamespacesMultipartyNetworkNamespace   = ffc("config.namespaces.predefined[].multiparty.networknamespace", "The shared namespace name to be sent in multiparty messages, if it differs from the local namespace name", i18n.StringType)
 	ConfigNamespacesMultipartyOrgName            = ffc("config.namespaces.predefined[].multiparty.org.name", "A short name for the local root organization within this namespace", i18n.StringType)
@@ -365,6 +453,7 @@ var (
 	ConfigOrgName        = ffc("config.org.name", "The name of the organization to which this FireFly node belongs (deprecated - should be set on each multi-party namespace instead)", i18n.StringType)
 
 	ConfigPrivatemessagingBatchAgentTimeout = ffc("config.privatemessaging.batch.agentTimeout", "How long to keep around a batching agent for a sending identity before disposal", i18n.TimeDurationType)
+	ConfigPrivatemessagingBatchCompression  = ffc("config.privatemessaging.batch.compression", "The compression applied to batches sent over Data Exchange - one of `none`, `gzip` or `zstd`. Batches are only compressed for recipient nodes that advertise support for the selected compression", i18n.StringType)
 	ConfigPrivatemessagingBatchPayloadLimit = ffc("config.privatemessaging.batch.payloadLimit", "The maximum payload size of a private message Data Exchange payload", i18n.ByteSizeType)
 	ConfigPrivatemessagingBatchSize         = ffc("config.privatemessaging.batch.size", "The maximum number of messages in a batch for private messages", i18n.IntType)
 	ConfigPrivatemessagingBatchTimeout      = ffc("config.privatemessaging.batch.timeout", "The timeout to wait for a batch to fill, before sending", i18n.TimeDurationType)
@@ -316,4 +316,90 @@ var (
 	MsgFiltersEmpty                            = ffe("FF10475", "No filters specified in contract listener: %s.", 500)
 	MsgContractListenerBlockchainFilterLimit   = ffe("FF10476", "Blockchain plugin only supports one filter for contract listener: %s.", 500)
 	MsgDuplicateContractListenerFilterLocation = ffe("FF10477", "Duplicate filter provided for contract listener for location", 400)
+	MsgInvalidTimestampParam                   = ffe("FF10478", "Invalid %s. Must be a valid timestamp.", 400)
+	MsgDBReadReplicaInitFailed                 = ffe("FF10479", "Database read replica initialization failed")
+	MsgRetentionArchiveFailed                  = ffe("FF10480", "Failed to archive %d records to '%s'")
+	MsgNamespaceNotEmpty                       = ffe("FF10481", "Cannot import into namespace '%s' as it already contains %s", 409)
+	MsgNamespaceArchiveInvalid                 = ffe("FF10482", "Invalid namespace archive at entry %d: %s", 400)
+	MsgNamespaceArchiveInvalidRecord           = ffe("FF10483", "Invalid %s record in namespace archive", 400)
+	MsgNamespaceArchiveWrongNamespace          = ffe("FF10484", "Namespace archive was exported from namespace '%s' and cannot be imported into namespace '%s'", 400)
+	MsgNamespaceArchiveVerifyFailed            = ffe("FF10485", "Namespace archive verification failed for %s: expected=%v actual=%v")
+	MsgNamespaceImportUploadRequired           = ffe("FF10486", "Namespace import requires the archive to be uploaded as a multi-part form", 400)
+	MsgInvalidJSONPath                         = ffe("FF10487", "Invalid JSON path '%s' - must be a dot separated list of up to 16 field names, containing only alphanumerics and underscores", 400)
+	MsgInvalidJSONPathMatch                    = ffe("FF10488", "Invalid JSON path match '%s' - must be in the format 'path=value'", 400)
+	MsgJSONPathQueryNotSupported               = ffe("FF10489", "JSON path queries are not supported by the database plugin", 400)
+	MsgXMLValidatorNotSupported                = ffe("FF10490", "XML Schema validation requires a build of FireFly with the 'xsd' build tag and cgo enabled", 400)
+	MsgDataInvalidPerSchema                    = ffe("FF10491", "Data does not conform to the %s schema of datatype '%s': %s", 400)
+	MsgDataValueNotXMLString                   = ffe("FF10492", "Data value for XML validation must be a JSON string containing the XML document", 400)
+	MsgXMLSchemaExternalReference              = ffe("FF10493", "XML Schema must be self contained - '%s' elements are not supported", 400)
+	MsgBlobTooLargeToValidate                  = ffe("FF10494", "Blob of size %d exceeds the maximum size of %d for validation against datatype '%s'", 400)
+	MsgDatatypeIncompatible                    = ffe("FF10495", "Datatype '%s' is not %s compatible with '%s': %s", 400)
+	MsgDatatypeVersionNotFound                 = ffe("FF10496", "Datatype '%s' not found", 404)
+	MsgMessageExpiryInPast                     = ffe("FF10497", "Message expiry '%s' must be after the time the message is created", 400)
+	MsgMessageExpired                          = ffe("FF10498", "Message expired at '%s' before it was confirmed")
+	MsgInvalidMessageThreadDepth               = ffe("FF10499", "Invalid depth '%s' - must be a number between 0 and %d", 400)
+	MsgMessageThreadTooLarge                   = ffe("FF10500", "Message thread has more than %d messages - specify a smaller depth", 400)
+	MsgMessageExpiryBeforeScheduled            = ffe("FF10501", "Message expiry '%s' must be after the scheduled send time '%s'", 400)
+	MsgScheduledMessageNotSupported            = ffe("FF10502", "Scheduled sending is not supported for messages attached to token transfers or approvals", 400)
+	MsgMessageNotScheduled                     = ffe("FF10503", "Message '%s' is in state '%s' and is not waiting to be sent at a scheduled time", 409)
+	MsgCompressionFailed                       = ffe("FF10504", "Failed to compress payload with '%s': %s")
+	MsgDecompressionFailed                     = ffe("FF10505", "Failed to decompress '%s' payload: %s", 400)
+	MsgDecompressedPayloadTooLarge             = ffe("FF10506", "Decompressed payload exceeds the maximum size of %d bytes", 400)
+	MsgDataAlreadyRedacted                     = ffe("FF10508", "Data '%s' has already been redacted", 409)
+	MsgDataRedactBroadcast                     = ffe("FF10509", "Data '%s' has been broadcast and cannot be redacted", 409)
+	MsgDataRedactMessageNotFinal               = ffe("FF10510", "Data '%s' is attached to message '%s' in state '%s', which must be confirmed, rejected or cancelled before the data can be redacted", 409)
+	MsgBulkMessagesTooMany                     = ffe("FF10512", "Bulk request contains %d messages, which exceeds the maximum of %d", 400)
+	MsgBulkMessageDuplicateIdempotencyKey      = ffe("FF10513", "Idempotency key '%s' is used by more than one message in the bulk request", 409)
+	MsgNamespaceAlreadyExists                  = ffe("FF10514", "Namespace '%s' already exists", 409)
+	MsgNamespaceNotDynamic                     = ffe("FF10515", "Namespace '%s' is defined in the config file, and cannot be modified through the API", 409)
+	MsgNamespaceStopped                        = ffe("FF10516", "Namespace '%s' is stopped", 412)
+	MsgDataLargeValueTooLarge                  = ffe("FF10511", "Value of data '%s' held in the blob store exceeds the expected size of %d bytes")
+	MsgOperationPolicyInvalidType              = ffe("FF10517", "Invalid operation type '%s' in operation policy")
+	MsgOperationPolicyDuplicateType            = ffe("FF10518", "More than one operation policy is configured for operation type '%s'")
+	MsgOperationPolicyInvalidTimeoutAction     = ffe("FF10519", "Invalid timeout action '%s' in operation policy for '%s' - must be 'fail' or 'query'")
+	MsgOperationPolicyQueryNotBlockchain       = ffe("FF10520", "Timeout action 'query' is only supported for blockchain operation types, not '%s'")
+	MsgOperationPolicyInvalidRetryError        = ffe("FF10521", "Invalid retry error pattern '%s' in operation policy for '%s': %s")
+	MsgOperationTimedOut                       = ffe("FF10522", "Operation timed out after %s with no update from the connector")
+	MsgOperationAlreadyRetried                 = ffe("FF10523", "Operation '%s' has already been retried", 409)
+	MsgRBACNoPrincipalSource                   = ffe("FF10524", "The rbac auth plugin '%s' must have at least one of basic, jwt or mtls configured to identify principals")
+	MsgRBACInvalidMTLSPrincipal                = ffe("FF10525", "Invalid mtls principal field '%s' - must be 'cn' or 'subject'")
+	MsgRBACRoleNameRequired                    = ffe("FF10526", "Role %d in rbac auth plugin '%s' must have a name")
+	MsgRBACInvalidEffect                       = ffe("FF10527", "Invalid effect '%s' in rule %d of rbac role '%s' - must be 'allow' or 'deny'")
+	MsgRBACInvalidGroup                        = ffe("FF10528", "Invalid route group '%s' in rule %d of rbac role '%s'")
+	MsgJWTKeyInvalid                           = ffe("FF10529", "Failed to load a JWT verification public key from '%s'")
+	MsgJWTNoKeySource                          = ffe("FF10530", "The jwt configuration of auth plugin '%s' must have one of keyFile, jwks.file or jwks.url set")
+	MsgJWKSInvalid                             = ffe("FF10531", "Failed to load a JSON Web Key Set from '%s'")
+	MsgJWTSigningKeyNotAllowed                 = ffe("FF10532", "Principal '%s' is not allowed to sign with key '%s'", 403)
+	MsgRateLimited                             = ffe("FF10533", "Too many requests - the %s rate limit in namespace '%s' has been exceeded", 429)
+	MsgChainWriteQuotaExceeded                 = ffe("FF10534", "The daily quota of %d chain-writing requests for the %s in namespace '%s' has been used", 429)
+	MsgRateLimitInvalidGroup                   = ffe("FF10535", "Invalid route group '%s' in rate limit %d")
+	MsgAuditRecordHashMismatch                 = ffe("FF10536", "The hash of audit record %d does not match its content")
+	MsgAuditRecordChainBroken                  = ffe("FF10537", "Audit record %d does not link to the hash of the audit record before it")
+	MsgGraphQLSyntax                           = ffe("FF10538", "GraphQL syntax error at line %d column %d: %s", 400)
+	MsgGraphQLOperationNotFound                = ffe("FF10539", "GraphQL operation '%s' not found in the document", 400)
+	MsgGraphQLOperationNameRequired            = ffe("FF10540", "An operationName is required, as the GraphQL document contains multiple operations", 400)
+	MsgGraphQLQueryOnly                        = ffe("FF10541", "Only GraphQL query operations are supported, not '%s'", 400)
+	MsgGraphQLUnknownField                     = ffe("FF10542", "Cannot query field '%s' on type '%s'", 400)
+	MsgGraphQLUnknownArgument                  = ffe("FF10543", "Unknown argument '%s' on field '%s'", 400)
+	MsgGraphQLMissingArgument                  = ffe("FF10544", "Field '%s' requires argument '%s'", 400)
+	MsgGraphQLSelectionRequired                = ffe("FF10545", "Field '%s' of type '%s' must have a selection of subfields", 400)
+	MsgGraphQLNoSubfields                      = ffe("FF10546", "Field '%s' is a scalar value, and cannot have a selection of subfields", 400)
+	MsgGraphQLUnknownFragment                  = ffe("FF10547", "Unknown fragment '%s'", 400)
+	MsgGraphQLFragmentCycle                    = ffe("FF10548", "Fragment '%s' spreads itself", 400)
+	MsgGraphQLUndefinedVariable                = ffe("FF10549", "Variable '$%s' is not defined by the operation", 400)
+	MsgGraphQLUnknownDirective                 = ffe("FF10550", "Unknown directive '@%s'", 400)
+	MsgGraphQLMissingVariable                  = ffe("FF10551", "Variable '$%s' of required type '%s' was not provided", 400)
+	MsgGraphQLNoOperations                     = ffe("FF10552", "The GraphQL document does not contain any operations", 400)
+	MsgGRPCInvalidMessage                      = ffe("FF10555", "Invalid gRPC request message", 400)
+	MsgGRPCStreamNotActive                     = ffe("FF10557", "gRPC event stream '%s' is not active", 404)
+	MsgGRPCStreamsNoData                       = ffe("FF10558", "gRPC event streams do not support streaming the full data payload, just the references (withData must be false)", 400)
+	MsgGRPCInvalidSubscribe                    = ffe("FF10559", "A request to subscribe to events must set either a name or ephemeral=true", 400)
+	MsgGRPCAckNotMatched                       = ffe("FF10560", "Acknowledgment does not match an event in flight on gRPC event stream '%s'", 404)
+	MsgGRPCAutoAckEnabled                      = ffe("FF10561", "Events are acknowledged automatically on gRPC event stream '%s'", 400)
+	MsgOperationPolicyFailRetryBlockchain      = ffe("FF10562", "Operation policy for '%s' cannot retry after the 'fail' timeout action, as the timed out blockchain transaction could still be mined - use the 'query' timeout action")
+	MsgRBACInvalidPrincipal                    = ffe("FF10563", "Invalid principal '%s' in rbac role '%s' - must be '*' or type:name, such as basic:alice")
+	MsgSPIGlobalAuthPluginNotFound             = ffe("FF10564", "The auth plugin '%s' in spi.globalAuthPlugin is not configured in plugins.auth")
+	MsgGraphQLMaxDepth                         = ffe("FF10565", "GraphQL query nests fields deeper than the maximum depth of %d", 400)
+	MsgGraphQLMaxNodes                         = ffe("FF10566", "GraphQL query could resolve more than the maximum of %d nodes. Reduce the limits of its lists, or the related resources it selects", 400)
+	MsgGraphQLMaxTokens                        = ffe("FF10567", "GraphQL query has more than the maximum of %d tokens", 400)
 )
@@ -59,6 +59,7 @@ var (
 	MessageHeaderTag       = ffm("MessageHeader.tag", "The message tag indicates the purpose of the message to the applications that process it")
 	MessageHeaderDataHash  = ffm("MessageHeader.datahash", "A single hash representing all data in the message. Derived from the array of data ids+hashes attached to this message")
 	MessageTxParent        = ffm("MessageHeader.txparent", "The parent transaction that originally triggered this message")
+	MessageHeaderExpiry    = ffm("MessageHeader.expiry", "Optional deadline for the message. If the message has not been confirmed by this time, it is not sent and is marked cancelled")
 
 	// Message field descriptions
 	MessageHeader         = ffm("Message.header", "The message header contains all fields that are used to build the message hash")
@@ -72,11 +73,17 @@ var (
 	MessagePins           = ffm("Message.pins", "For private messages, a unique pin hash:nonce is assigned for each topic")
 	MessageTransactionID  = ffm("Message.txid", "The ID of the transaction used to order/deliver this message")
 	MessageIdempotencyKey = ffm("Message.idempotencyKey", "An optional unique identifier for a message. Cannot be duplicated within a namespace, thus allowing idempotent submission of messages to the API. Local only - not transferred when the message is sent to other members of the network")
+	MessagePriority       = ffm("Message.priority", "The priority with which the message is batched and dispatched. Messages of each priority are assembled into separate batches, and higher priority batches are pinned first when the blockchain is applying back-pressure. Local only - not transferred when the message is sent to other members of the network")
+	MessageScheduled      = ffm("Message.scheduled", "An optional time at which the message should be sent. The message is held in the staged state until this time, and can be cancelled before it is sent. Local only - not transferred when the message is sent to other members of the network")
 
 	// MessageInOut field descriptions
 	MessageInOutData  = ffm("MessageInOut.data", "For input allows you to specify data in-line in the message, that will be turned into data attachments. For output when fetchdata is used on API calls, includes the in-line data payloads of all data attachments")
 	MessageInOutGroup = ffm("MessageInOut.group", "Allows you to specify details of the private group of recipients in-line in the message. Alternative to using the header.group to specify the hash of a group that has been previously resolved")
 
+	// MessageBulkResult field descriptions
+	MessageBulkResultMessage = ffm("MessageBulkResult.message", "The message that was stored for sending. Not set if the message failed")
+	MessageBulkResultError   = ffm("MessageBulkResult.error", "The error for this message, if it failed. Other messages in the request are not affected")
+
 	// InputGroup field descriptions
 	InputGroupName    = ffm("InputGroup.name", "Optional name for the group. Allows you to have multiple separate groups with the same list of participants")
 	InputGroupMembers = ffm("InputGroup.members", "An array of members of the group. If no identities local to the sending node are included, then the organization owner of the local node is added automatically")
@@ -129,21 +136,65 @@ var (
 	DataValue     = ffm("Data.value", "The value for the data, stored in the FireFly core database. Can be any JSON type - object, array, string, number or boolean. Can be combined with a binary blob attachment")
 	DataBlob      = ffm("Data.blob", "An optional hash reference to a binary blob attachment")
 	DataPublic    = ffm("Data.public", "If the JSON value has been published to shared storage, this field is the id of the data in the shared storage plugin (IPFS hash etc.)")
+	DataRedacted  = ffm("Data.redacted", "If the value and any blob of the data have been redacted, this records who redacted it, when and why")
+	DataValueRef  = ffm("Data.valueRef", "Set instead of the value, when a large JSON value is held in the blob store rather than the database. The value can be streamed from the data value API")
+
+	// DataValueRef field descriptions
+	DataValueRefSize = ffm("DataValueRef.size", "The size in bytes of the JSON value held in the blob store")
+
+	// DataRedaction field descriptions
+	DataRedactionBy      = ffm("DataRedaction.by", "The authenticated principal of the API request that redacted the data, as type:name, when an auth plugin identified one")
+	DataRedactionAuthor  = ffm("DataRedaction.author", "An optional note of the person or system that requested the redaction, as supplied by the caller")
+	DataRedactionReason  = ffm("DataRedaction.reason", "The reason given for the redaction, such as a reference to an erasure request")
+	DataRedactionCreated = ffm("DataRedaction.created", "The time the data was redacted")
+
+	// DataRedactInput field descriptions
+	DataRedactInputAuthor = ffm("DataRedactInput.author", "An optional note of the person or system requesting the redaction, which is recorded on the data alongside the authenticated principal")
+	DataRedactInputReason = ffm("DataRedactInput.reason", "An optional reason for the redaction, such as a reference to an erasure request")
 
 	// DatatypeRef field descriptions
 	DatatypeRefName    = ffm("DatatypeRef.name", "The name of the datatype")
 	DatatypeRefVersion = ffm("DatatypeRef.version", "The version of the datatype. Semantic versioning is encouraged, such as v1.0.1")
 
 	// Datatype field descriptions
-	DatatypeID        = ffm("Datatype.id", "The UUID of the datatype")
-	DatatypeMessage   = ffm("Datatype.message", "The UUID of the broadcast message that was used to publish this datatype to the network")
-	DatatypeValidator = ffm("Datatype.validator", "The validator that should be used to verify this datatype")
-	DatatypeNamespace = ffm("Datatype.namespace", "The namespace of the datatype. Data resources can only be created referencing datatypes in the same namespace")
-	DatatypeName      = ffm("Datatype.name", "The name of the datatype")
-	DatatypeVersion   = ffm("Datatype.version", "The version of the datatype. Multiple versions can exist with the same name. Use of semantic versioning is encourages, such as v1.0.1")
-	DatatypeHash      = ffm("Datatype.hash", "The hash of the value, such as the JSON schema. Allows all parties to be confident they have the exact same rules for verifying data created against a datatype")
-	DatatypeCreated   = ffm("Datatype.created", "The time the datatype was created")
-	DatatypeValue     = ffm("Datatype.value", "The definition of the datatype, in the syntax supported by the validator (such as a JSON Schema definition)")
+	DatatypeID            = ffm("Datatype.id", "The UUID of the datatype")
+	DatatypeMessage       = ffm("Datatype.message", "The UUID of the broadcast message that was used to publish this datatype to the network")
+	DatatypeValidator     = ffm("Datatype.validator", "The validator that should be used to verify this datatype")
+	DatatypeNamespace     = ffm("Datatype.namespace", "The namespace of the datatype. Data resources can only be created referencing datatypes in the same namespace")
+	DatatypeName          = ffm("Datatype.name", "The name of the datatype")
+	DatatypeVersion       = ffm("Datatype.version", "The version of the datatype. Multiple versions can exist with the same name. Use of semantic versioning is encourages, such as v1.0.1")
+	DatatypeHash          = ffm("Datatype.hash", "The hash of the value, such as the JSON schema. Allows all parties to be confident they have the exact same rules for verifying data created against a datatype")
+	DatatypeCreated       = ffm("Datatype.created", "The time the datatype was created")
+	DatatypeValue         = ffm("Datatype.value", "The definition of the datatype, in the syntax supported by the validator. A JSON Schema for json, a JSON string containing an XML Schema for xml, an object with the .proto source schema and the message name for protobuf, and an Avro schema for avro")
+	DatatypeIndexPaths    = ffm("Datatype.indexPaths", "Dot separated JSON paths within the values of data of this datatype, for which the database maintains an index to accelerate JSON path queries")
+	DatatypeCompatibility = ffm("Datatype.compatibility", "The compatibility this version must have with the previous version of the datatype with the same name, which is checked when the version is published. Defaults to none")
+
+	// DatatypeChange field descriptions
+	DatatypeChangePath          = ffm("DatatypeChange.path", "The path within the schema of the change")
+	DatatypeChangeDescription   = ffm("DatatypeChange.description", "A description of the change")
+	DatatypeChangeCompatibility = ffm("DatatypeChange.compatibility", "The compatibility retained by the change. Backward if data valid against the old version remains valid, forward if data valid against the new version is valid against the old version")
+
+	// DatatypeDiff field descriptions
+	DatatypeDiffFrom          = ffm("DatatypeDiff.from", "The datatype version the changes are from")
+	DatatypeDiffTo            = ffm("DatatypeDiff.to", "The datatype version the changes are to")
+	DatatypeDiffCompatibility = ffm("DatatypeDiff.compatibility", "The compatibility retained by all of the changes")
+	DatatypeDiffChanges       = ffm("DatatypeDiff.changes", "The list of changes between the versions")
+
+	// DatatypeDataCheck field descriptions
+	DatatypeDataCheckDiff       = ffm("DatatypeDataCheck.diff", "The changes from the latest published version of the datatype, if there is one")
+	DatatypeDataCheckCompatible = ffm("DatatypeDataCheck.compatible", "True if the changes from the latest published version retain the compatibility declared by the proposed version")
+	DatatypeDataCheckChecked    = ffm("DatatypeDataCheck.checked", "The number of items of stored data of any version of the datatype that were validated")
+	DatatypeDataCheckInvalid    = ffm("DatatypeDataCheck.invalid", "The number of items of stored data that are not valid against the proposed version")
+	DatatypeDataCheckFailures   = ffm("DatatypeDataCheck.failures", "The first items of stored data that are not valid against the proposed version, with the reason")
+
+	// DatatypeDataFailure field descriptions
+	DatatypeDataFailureData     = ffm("DatatypeDataFailure.data", "The UUID of the data")
+	DatatypeDataFailureDatatype = ffm("DatatypeDataFailure.datatype", "The datatype version the data currently refers to")
+	DatatypeDataFailureError    = ffm("DatatypeDataFailure.error", "The validation error against the proposed version")
+
+	// ProtobufSchema field descriptions
+	ProtobufSchemaSchema  = ffm("ProtobufSchema.schema", "The source of a self contained .proto file, which can import the well known types such as google/protobuf/timestamp.proto")
+	ProtobufSchemaMessage = ffm("ProtobufSchema.message", "The fully qualified name of the message within the schema that data must conform to, such as com.example.Order")
 
 	// SignerRef field descriptions
 	SignerRefAuthor = ffm("SignerRef.author", "The DID of identity of the submitter")
@@ -196,6 +247,14 @@ var (
 	OperationCreated     = ffm("Operation.created", "The time the operation was created")
 	OperationUpdated     = ffm("Operation.updated", "The last update time of the operation")
 	OperationRetry       = ffm("Operation.retry", "If this operation was initiated as a retry to a previous operation, this field points to the UUID of the operation being retried")
+	OperationPolicy      = ffm("Operation.policy", "The timeout and automatic retry decisions made for this operation, under the policy configured for its type")
+
+	// OperationPolicyDecision field descriptions
+	OperationPolicyDecisionAction = ffm("OperationPolicyDecision.action", "The action taken under the operation policy")
+	OperationPolicyDecisionTime   = ffm("OperationPolicyDecision.time", "The time the action was most recently taken")
+	OperationPolicyDecisionCount  = ffm("OperationPolicyDecision.count", "The number of consecutive times the action was taken, or for a retry the number of the attempt")
+	OperationPolicyDecisionReason = ffm("OperationPolicyDecision.reason", "The reason the action was taken")
+	OperationPolicyDecisionRetry  = ffm("OperationPolicyDecision.retry", "For a retry, the UUID of the new operation that was created")
 
 	// OperationWithDetail field description
 	OperationWithDetail = ffm("OperationWithDetail.detail", "Additional detailed information about an operation provided by the connector")
@@ -354,6 +413,7 @@ var (
 	EnrichedEventBlockchainEvent   = ffm("EnrichedEvent.blockchainEvent", "A blockchain event if referenced by the FireFly event")
 	EnrichedEventContractAPI       = ffm("EnrichedEvent.contractAPI", "A Contract API if referenced by the FireFly event")
 	EnrichedEventContractInterface = ffm("EnrichedEvent.contractInterface", "A Contract Interface (FFI) if referenced by the FireFly event")
+	EnrichedEventData              = ffm("EnrichedEvent.data", "A Data item if referenced by the FireFly event, without its value")
 	EnrichedEventDatatype          = ffm("EnrichedEvent.datatype", "A Datatype if referenced by the FireFly event")
 	EnrichedEventIdentity          = ffm("EnrichedEvent.identity", "An Identity if referenced by the FireFly event")
 	EnrichedEventMessage           = ffm("EnrichedEvent.message", "A Message if  referenced by the FireFly event")
@@ -414,6 +474,7 @@ var (
 	NamespaceNetworkName           = ffm("Namespace.networkName", "The shared namespace name within the multiparty network")
 	NamespaceDescription           = ffm("Namespace.description", "A description of the namespace")
 	NamespaceCreated               = ffm("Namespace.created", "The time the namespace was created")
+	NamespaceDefinition            = ffm("Namespace.definition", "The definition of a namespace that was created through the admin API, rather than in the config file")
 	MultipartyContractsActive      = ffm("MultipartyContracts.active", "The currently active FireFly smart contract")
 	MultipartyContractsTerminated  = ffm("MultipartyContracts.terminated", "Previously-terminated FireFly smart contracts")
 	MultipartyContractIndex        = ffm("MultipartyContract.index", "The index of this contract in the config file")
@@ -429,6 +490,27 @@ var (
 	// NamespaceWithInitStatus field descriptions
 	NamespaceWithInitStatusInitializing        = ffm("NamespaceWithInitStatus.initializing", "Set to true if the namespace is still initializing")
 	NamespaceWithInitStatusInitializationError = ffm("NamespaceWithInitStatus.initializationError", "Set to a non-empty string in the case that the namespace is currently failing to initialize")
+	NamespaceWithInitStatusStopped             = ffm("NamespaceWithInitStatus.stopped", "Set to true if the namespace has been stopped through the admin API")
+
+	// NamespaceDefinition field descriptions
+	NamespaceDefinitionName                  = ffm("NamespaceDefinition.name", "The name of the namespace (must be unique)")
+	NamespaceDefinitionDescription           = ffm("NamespaceDefinition.description", "A description for the namespace")
+	NamespaceDefinitionPlugins               = ffm("NamespaceDefinition.plugins", "The names of already-configured plugins to use in this namespace. All plugins other than events plugins are used when not set")
+	NamespaceDefinitionDefaultKey            = ffm("NamespaceDefinition.defaultKey", "A default signing key for blockchain transactions within this namespace")
+	NamespaceDefinitionAssetKeyNormalization = ffm("NamespaceDefinition.assetKeyNormalization", "Mechanism to normalize keys before using them. Defaults to the root asset manager setting when not set")
+	NamespaceDefinitionMultiparty            = ffm("NamespaceDefinition.multiparty", "The multiparty configuration for this namespace")
+	NamespaceDefinitionStopped               = ffm("NamespaceDefinition.stopped", "Set to true if the namespace has been stopped through the admin API")
+	NamespaceDefinitionMultipartyEnabled     = ffm("NamespaceDefinitionMultiparty.enabled", "Enables multi-party mode for this namespace")
+	NamespaceDefinitionMultipartyNetworkNS   = ffm("NamespaceDefinitionMultiparty.networkNamespace", "The shared namespace name to be sent in multiparty messages, if it differs from the local namespace name")
+	NamespaceDefinitionMultipartyOrg         = ffm("NamespaceDefinitionMultiparty.org", "The local root organization within this namespace")
+	NamespaceDefinitionMultipartyNode        = ffm("NamespaceDefinitionMultiparty.node", "The local node within this namespace")
+	NamespaceDefinitionMultipartyContract    = ffm("NamespaceDefinitionMultiparty.contract", "The list of FireFly multiparty contracts for this namespace, in the order they are to be used")
+	NamespaceDefinitionMemberName            = ffm("NamespaceDefinitionMember.name", "The name of the org or node")
+	NamespaceDefinitionMemberKey             = ffm("NamespaceDefinitionMember.key", "The signing key allocated to the root organization")
+	NamespaceDefinitionMemberDescription     = ffm("NamespaceDefinitionMember.description", "A description of the org or node")
+	NamespaceDefinitionContractLocation      = ffm("NamespaceDefinitionContract.location", "A blockchain specific contract identifier. For example an Ethereum contract address, or a Fabric chaincode name and channel")
+	NamespaceDefinitionContractFirstEvent    = ffm("NamespaceDefinitionContract.firstEvent", "The first event the contract listener should start from. Defaults to 'oldest'")
+	NamespaceDefinitionContractOptions       = ffm("NamespaceDefinitionContract.options", "Blockchain specific contract options")
 
 	// NamespaceStatus field descriptions
 	NodeNamespace       = ffm("NamespaceStatus.namespace", "The namespace that this status applies to")
@@ -437,6 +519,16 @@ var (
 	NamespacePlugins    = ffm("NamespaceStatus.plugins", "Information about plugins configured on this namespace")
 	NamespaceMultiparty = ffm("NamespaceStatus.multiparty", "Information about the multi-party system configured on this namespace")
 
+	// NamespaceArchiveSummary field descriptions
+	NamespaceArchiveSummaryNamespace   = ffm("NamespaceArchiveSummary.namespace", "The namespace that was exported to the archive")
+	NamespaceArchiveSummaryCreated     = ffm("NamespaceArchiveSummary.created", "The time the archive was exported")
+	NamespaceArchiveSummaryCollections = ffm("NamespaceArchiveSummary.collections", "The number of records and hash of the records for each collection in the archive")
+
+	// NamespaceArchiveCollection field descriptions
+	NamespaceArchiveCollectionName  = ffm("NamespaceArchiveCollection.name", "The name of the collection")
+	NamespaceArchiveCollectionCount = ffm("NamespaceArchiveCollection.count", "The number of records of the collection in the archive")
+	NamespaceArchiveCollectionHash  = ffm("NamespaceArchiveCollection.hash", "The sum of the SHA-256 hashes of the JSON records of the collection, excluding fields assigned by the database on restore")
+
 	// NamespaceStatusNode field descriptions
 	NamespaceStatusNodeName                  = ffm("NamespaceStatusNode.name", "The name of this node, as specified in the local configuration")
 	NamespaceStatusNodeRegistered            = ffm("NamespaceStatusNode.registered", "Whether the node has been successfully registered")
@@ -466,8 +558,33 @@ var (
 	NamespaceStatusPluginsTokens        = ffm("NamespaceStatusPlugins.tokens", "The token plugins on this namespace")
 
 	// NamespaceStatusPlugin field descriptions
-	NamespaceStatusPluginName = ffm("NamespaceStatusPlugin.name", "The name of the plugin")
-	NamespaceStatusPluginType = ffm("NamespaceStatusPlugin.pluginType", "The type of the plugin")
+	NamespaceStatusPluginName   = ffm("NamespaceStatusPlugin.name", "The name of the plugin")
+	NamespaceStatusPluginType   = ffm("NamespaceStatusPlugin.pluginType", "The type of the plugin")
+	NamespaceStatusPluginHealth = ffm("NamespaceStatusPlugin.health", "The result of probing the health of the plugin, and the service it connects to")
+
+	// PluginHealth field descriptions
+	PluginHealthHealthy       = ffm("PluginHealth.healthy", "True if the probe succeeded, and any event stream is connected and not lagging")
+	PluginHealthLatency       = ffm("PluginHealth.latency", "The time taken for the plugin to respond to the health probe")
+	PluginHealthChecked       = ffm("PluginHealth.checked", "The time the health probe was performed")
+	PluginHealthLastError     = ffm("PluginHealth.lastError", "The most recent error returned by a health probe of the plugin")
+	PluginHealthLastErrorTime = ffm("PluginHealth.lastErrorTime", "The time of the most recent failed health probe of the plugin")
+	PluginHealthEventStream   = ffm("PluginHealth.eventStream", "The state of the event stream from the connector, for plugins that receive events over a websocket")
+
+	// EventStreamHealth field descriptions
+	EventStreamHealthConnected = ffm("EventStreamHealth.connected", "True if the websocket event stream is currently connected")
+	EventStreamHealthLagging   = ffm("EventStreamHealth.lagging", "True if no event has been received on the stream within the configured maximum idle time")
+	EventStreamHealthLastEvent = ffm("EventStreamHealth.lastEvent", "The time the last event was received on the stream")
+
+	// NodeReadiness field descriptions
+	NodeReadinessReady      = ffm("NodeReadiness.ready", "True if every namespace is started and all of its plugins are healthy")
+	NodeReadinessNamespaces = ffm("NodeReadiness.namespaces", "The readiness of each namespace")
+
+	// NamespaceReadiness field descriptions
+	NamespaceReadinessName                = ffm("NamespaceReadiness.name", "The name of the namespace")
+	NamespaceReadinessReady               = ffm("NamespaceReadiness.ready", "True if the namespace is started and all of its plugins are healthy")
+	NamespaceReadinessInitializing        = ffm("NamespaceReadiness.initializing", "True if the namespace is still initializing")
+	NamespaceReadinessInitializationError = ffm("NamespaceReadiness.initializationError", "The most recent error encountered while initializing the namespace")
+	NamespaceReadinessUnhealthyPlugins    = ffm("NamespaceReadiness.unhealthyPlugins", "The names of the plugins in the namespace that failed their health probe")
 
 	// NamespaceStatusMultiparty field descriptions
 	NamespaceMultipartyEnabled  = ffm("NamespaceStatusMultiparty.enabled", "Whether multi-party mode is enabled for this namespace")
@@ -597,6 +714,68 @@ var (
 	TokenBalanceBalance    = ffm("TokenBalance.balance", "The numeric balance. For non-fungible tokens will always be 1. For fungible tokens, the number of decimals for the token pool should be considered when interpreting the balance. For example, with 18 decimals a fractional balance of 10.234 will be returned as 10,234,000,000,000,000,000")
 	TokenBalanceUpdated    = ffm("TokenBalance.updated", "The last time the balance was updated by applying a transfer event")
 
+	// AuditRecord field descriptions
+	AuditRecordID             = ffm("AuditRecord.id", "The UUID of the audit record")
+	AuditRecordNamespace      = ffm("AuditRecord.namespace", "The namespace the audited API request targeted")
+	AuditRecordPrincipal      = ffm("AuditRecord.principal", "The principal that made the request, as authenticated by the auth plugin. The client address is used when no auth plugin identifies the caller")
+	AuditRecordClient         = ffm("AuditRecord.client", "The network address of the client that made the request")
+	AuditRecordMethod         = ffm("AuditRecord.method", "The HTTP method of the request")
+	AuditRecordRoute          = ffm("AuditRecord.route", "The name of the API route that handled the request")
+	AuditRecordPath           = ffm("AuditRecord.path", "The URL path of the request")
+	AuditRecordParams         = ffm("AuditRecord.params", "The path, query and form parameters of the request")
+	AuditRecordInputHash      = ffm("AuditRecord.inputHash", "The SHA-256 hash of the request body. The body itself is not stored in the audit log")
+	AuditRecordRequestID      = ffm("AuditRecord.requestId", "The request ID of the API call, as supplied in or returned on the X-FireFly-Request-ID header")
+	AuditRecordIdempotencyKey = ffm("AuditRecord.idempotencyKey", "The idempotency key supplied in the request body, if any")
+	AuditRecordTransaction    = ffm("AuditRecord.tx", "The UUID of the FireFly transaction that resulted from the request, if any")
+	AuditRecordOperation      = ffm("AuditRecord.operation", "The UUID of the operation that resulted from the request, if any")
+	AuditRecordStatus         = ffm("AuditRecord.status", "The HTTP status code returned for the request")
+	AuditRecordError          = ffm("AuditRecord.error", "The error returned for the request, if it failed")
+	AuditRecordCreated        = ffm("AuditRecord.created", "The time the request completed")
+	AuditRecordPreviousHash   = ffm("AuditRecord.previousHash", "The hash of the previous audit record in the namespace. Unset on the first record")
+	AuditRecordHash           = ffm("AuditRecord.hash", "The SHA-256 hash of this audit record, covering all other fields including the previous hash")
+	AuditRecordSequence       = ffm("AuditRecord.sequence", "The local sequence number of the audit record, which is the order in which the hash chain is verified")
+
+	// AuditVerification field descriptions
+	AuditVerificationRecords      = ffm("AuditVerification.records", "The number of audit records that were checked")
+	AuditVerificationValid        = ffm("AuditVerification.valid", "True if every record hash matched its content, and linked to the hash of the record before it")
+	AuditVerificationLastHash     = ffm("AuditVerification.lastHash", "The hash of the last valid record in the chain")
+	AuditVerificationFirstInvalid = ffm("AuditVerification.firstInvalid", "The sequence of the first record that failed verification")
+	AuditVerificationError        = ffm("AuditVerification.error", "A description of why verification failed")
+
+	// GraphQLRequest field descriptions
+	GraphQLRequestQuery         = ffm("GraphQLRequest.query", "The GraphQL query document. Only query operations are supported")
+	GraphQLRequestOperationName = ffm("GraphQLRequest.operationName", "The name of the operation to execute, when the document contains more than one")
+	GraphQLRequestVariables     = ffm("GraphQLRequest.variables", "The values of the variables used by the operation")
+
+	// GraphQLResponse field descriptions
+	GraphQLResponseData   = ffm("GraphQLResponse.data", "The result of the query, in the shape of the selections in the query. Unset if the query was invalid")
+	GraphQLResponseErrors = ffm("GraphQLResponse.errors", "Errors that prevented the query from executing, or that caused individual fields to be returned as null")
+
+	// GraphQLError field descriptions
+	GraphQLErrorMessage   = ffm("GraphQLError.message", "A description of the error")
+	GraphQLErrorLocations = ffm("GraphQLError.locations", "The positions in the query document that the error relates to")
+	GraphQLErrorPath      = ffm("GraphQLError.path", "The path of response keys, and list indexes, to the field that failed")
+
+	// GraphQLLocation field descriptions
+	GraphQLLocationLine   = ffm("GraphQLLocation.line", "The line number, starting at 1")
+	GraphQLLocationColumn = ffm("GraphQLLocation.column", "The column number, starting at 1")
+
+	// TokenBalanceChange field descriptions
+	TokenBalanceChangePool            = ffm("TokenBalanceChange.pool", "The UUID the token pool this balance change applies to")
+	TokenBalanceChangeTokenIndex      = ffm("TokenBalanceChange.tokenIndex", "The index of the token within the pool that this balance change applies to")
+	TokenBalanceChangeURI             = ffm("TokenBalanceChange.uri", "The URI of the token this balance change applies to")
+	TokenBalanceChangeConnector       = ffm("TokenBalanceChange.connector", "The token connector that is responsible for the token pool of this balance change")
+	TokenBalanceChangeNamespace       = ffm("TokenBalanceChange.namespace", "The namespace of the token pool for this balance change")
+	TokenBalanceChangeKey             = ffm("TokenBalanceChange.key", "The blockchain signing identity whose balance changed")
+	TokenBalanceChangeAmount          = ffm("TokenBalanceChange.amount", "The amount of the change to the balance. For the first entry of an account that held a balance before history was recorded, this is the balance at that time")
+	TokenBalanceChangeDebit           = ffm("TokenBalanceChange.debit", "True when tokens were transferred out of, or burned from, the account, reducing the balance by the amount")
+	TokenBalanceChangeBalance         = ffm("TokenBalanceChange.balance", "The balance of the account immediately after this change was applied")
+	TokenBalanceChangeTransfer        = ffm("TokenBalanceChange.transfer", "The local UUID of the token transfer that caused this balance change")
+	TokenBalanceChangeProtocolID      = ffm("TokenBalanceChange.protocolId", "The protocol ID of the token transfer that caused this balance change. For most blockchain connectors this is an alphanumerically sortable string that begins with the block number")
+	TokenBalanceChangeBlockchainEvent = ffm("TokenBalanceChange.blockchainEvent", "The UUID of the blockchain event for the token transfer that caused this balance change")
+	TokenBalanceChangeCreated         = ffm("TokenBalanceChange.created", "The time the token transfer that caused this balance change was recorded by FireFly")
+	TokenBalanceChangeTimestamp       = ffm("TokenBalanceChange.timestamp", "The time of the blockchain event of the token transfer that caused this balance change. Point-in-time balances are calculated using this time")
+
 	// TokenBalance field descriptions
 	TokenConnectorName = ffm("TokenConnector.name", "The name of the token connector, as configured in the FireFly core configuration file")
 
@@ -651,6 +830,17 @@ var (
 	TokenTransferInputPool           = ffm("TokenTransferInput.pool", "The name or UUID of a token pool")
 	TokenTransferInputIdempotencyKey = ffm("TokenTransferInput.idempotencyKey", "An optional identifier to allow idempotent submission of requests. Stored on the transaction uniquely within a namespace")
 
+	// TransactionWithDetail field descriptions
+	TransactionWithDetailOperations     = ffm("TransactionWithDetail.operations", "The operations performed by this node as part of the transaction")
+	TransactionWithDetailMessages       = ffm("TransactionWithDetail.messages", "The messages sent in the transaction")
+	TransactionWithDetailTokenTransfers = ffm("TransactionWithDetail.tokenTransfers", "The token transfers made in the transaction")
+	TransactionWithDetailTokenApprovals = ffm("TransactionWithDetail.tokenApprovals", "The token approvals made in the transaction")
+
+	// BlockchainTransaction field descriptions
+	BlockchainTransactionBlockchainID     = ffm("BlockchainTransaction.blockchainId", "The blockchain transaction ID that was looked up")
+	BlockchainTransactionTransactions     = ffm("BlockchainTransaction.transactions", "The FireFly transactions that include the blockchain transaction, with their operations, messages and token activity")
+	BlockchainTransactionBlockchainEvents = ffm("BlockchainTransaction.blockchainEvents", "The blockchain events emitted by the blockchain transaction that were recorded in the namespace")
+
 	// TransactionStatus field descriptions
 	TransactionStatusStatus  = ffm("TransactionStatus.status", "The overall computed status of the transaction, after analyzing the details during the API call")
 	TransactionStatusDetails = ffm("TransactionStatus.details", "A set of records describing the activities within the transaction known by the local FireFly node")
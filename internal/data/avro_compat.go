@@ -0,0 +1,329 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// avroPromotions are the writer types that can be read as each reader type, by the Avro schema resolution rules
+var avroPromotions = map[string][]string{
+	"long":   {"int"},
+	"float":  {"int", "long"},
+	"double": {"int", "long", "float"},
+	"string": {"bytes"},
+	"bytes":  {"string"},
+}
+
+type avroField struct {
+	name       string
+	aliases    []string
+	hasDefault bool
+	schema     *avroNode
+}
+
+// avroNode is a parsed Avro schema, sufficient to apply the schema resolution rules
+type avroNode struct {
+	typ         string
+	name        string
+	aliases     []string
+	fields      []*avroField
+	symbols     []string
+	hasDefault  bool
+	size        float64
+	items       *avroNode
+	branches    []*avroNode
+	description string
+}
+
+type avroParser struct {
+	named map[string]*avroNode
+}
+
+// parse builds the tree of nodes for a schema, resolving references to named types defined earlier in the schema
+func (ap *avroParser) parse(schema interface{}, namespace string) (*avroNode, error) {
+	switch s := schema.(type) {
+	case string:
+		switch s {
+		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			return &avroNode{typ: s, description: s}, nil
+		}
+		if n := ap.named[avroFullName(s, namespace)]; n != nil {
+			return n, nil
+		}
+		if n := ap.named[s]; n != nil {
+			return n, nil
+		}
+		return nil, fmt.Errorf("unknown type '%s'", s)
+	case []interface{}:
+		n := &avroNode{typ: "union"}
+		descriptions := make([]string, len(s))
+		for i, b := range s {
+			branch, err := ap.parse(b, namespace)
+			if err != nil {
+				return nil, err
+			}
+			n.branches = append(n.branches, branch)
+			descriptions[i] = branch.description
+		}
+		n.description = "[" + strings.Join(descriptions, ",") + "]"
+		return n, nil
+	case map[string]interface{}:
+		return ap.parseComplex(s, namespace)
+	default:
+		return nil, fmt.Errorf("invalid schema %v", schema)
+	}
+}
+
+func (ap *avroParser) parseComplex(s map[string]interface{}, namespace string) (*avroNode, error) {
+	typ, _ := s["type"].(string)
+	n := &avroNode{typ: typ, description: typ}
+	switch typ {
+	case "record", "error", "enum", "fixed":
+		name, _ := s["name"].(string)
+		if ns, ok := s["namespace"].(string); ok && !strings.Contains(name, ".") {
+			namespace = ns
+		}
+		n.name = avroFullName(name, namespace)
+		if i := strings.LastIndex(n.name, "."); i >= 0 {
+			namespace = n.name[:i]
+		}
+		n.description = n.name
+		n.aliases = avroStrings(s["aliases"])
+		ap.named[n.name] = n
+	}
+	switch typ {
+	case "record", "error":
+		n.typ = "record"
+		fields, _ := s["fields"].([]interface{})
+		for _, f := range fields {
+			fm, _ := f.(map[string]interface{})
+			name, _ := fm["name"].(string)
+			_, hasDefault := fm["default"]
+			schema, err := ap.parse(fm["type"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			n.fields = append(n.fields, &avroField{name: name, aliases: avroStrings(fm["aliases"]), hasDefault: hasDefault, schema: schema})
+		}
+	case "enum":
+		n.symbols = avroStrings(s["symbols"])
+		_, n.hasDefault = s["default"]
+	case "fixed":
+		n.size, _ = s["size"].(float64)
+	case "array":
+		items, err := ap.parse(s["items"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		n.items = items
+	case "map":
+		values, err := ap.parse(s["values"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		n.items = values
+	default:
+		// a primitive type, possibly with a logical type that is resolved as the underlying type
+		return ap.parse(typ, namespace)
+	}
+	return n, nil
+}
+
+func avroFullName(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func avroShortName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func avroStrings(v interface{}) []string {
+	a, _ := v.([]interface{})
+	strs := make([]string, 0, len(a))
+	for _, e := range a {
+		if s, ok := e.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+func parseAvroSchema(ctx context.Context, dt *core.Datatype, ref *core.DatatypeRef) (*avroNode, error) {
+	var schema interface{}
+	err := json.Unmarshal(dt.Value.Bytes(), &schema)
+	if err == nil {
+		var n *avroNode
+		if n, err = (&avroParser{named: map[string]*avroNode{}}).parse(schema, ""); err == nil {
+			return n, nil
+		}
+	}
+	return nil, i18n.WrapError(ctx, err, coremsgs.MsgSchemaLoadFailed, ref)
+}
+
+// avroResolver applies the Avro schema resolution rules, to find where data written with one schema
+// cannot be read with another
+type avroResolver struct {
+	problems map[string]bool
+	visited  map[[2]*avroNode]bool
+}
+
+func (ar *avroResolver) problem(path, format string, args ...interface{}) {
+	ar.problems[path+"\x00"+fmt.Sprintf(format, args...)] = true
+}
+
+func (ar *avroResolver) resolve(path string, reader, writer *avroNode) {
+	if writer.typ == "union" {
+		for _, branch := range writer.branches {
+			ar.resolve(path, reader, branch)
+		}
+		return
+	}
+	if reader.typ == "union" {
+		for _, branch := range reader.branches {
+			if avroMatches(branch, writer) {
+				ar.resolve(path, branch, writer)
+				return
+			}
+		}
+		ar.problem(path, "type %s is not in union %s", writer.description, reader.description)
+		return
+	}
+	if !avroMatches(reader, writer) {
+		ar.problem(path, "type %s cannot be read as %s", writer.description, reader.description)
+		return
+	}
+	pair := [2]*avroNode{reader, writer}
+	if ar.visited[pair] {
+		return
+	}
+	ar.visited[pair] = true
+	switch reader.typ {
+	case "record":
+		for _, rf := range reader.fields {
+			if wf := avroWriterField(rf, writer); wf != nil {
+				ar.resolve(schemaPath(path, rf.name), rf.schema, wf.schema)
+			} else if !rf.hasDefault {
+				ar.problem(schemaPath(path, rf.name), "field without a default")
+			}
+		}
+	case "enum":
+		symbols := map[string]bool{}
+		for _, s := range reader.symbols {
+			symbols[s] = true
+		}
+		for _, s := range writer.symbols {
+			if !symbols[s] && !reader.hasDefault {
+				ar.problem(path, "enum symbol %s", s)
+			}
+		}
+	case "fixed":
+		if reader.size != writer.size {
+			ar.problem(path, "fixed size %v cannot be read as size %v", writer.size, reader.size)
+		}
+	case "array":
+		ar.resolve(schemaPath(path, "[]"), reader.items, writer.items)
+	case "map":
+		ar.resolve(schemaPath(path, "*"), reader.items, writer.items)
+	}
+}
+
+// avroMatches checks the types of a reader and writer schema are compatible, without checking their content
+func avroMatches(reader, writer *avroNode) bool {
+	if reader.typ != writer.typ {
+		for _, promotable := range avroPromotions[reader.typ] {
+			if promotable == writer.typ {
+				return true
+			}
+		}
+		return false
+	}
+	if reader.name == "" {
+		return true
+	}
+	if avroShortName(reader.name) == avroShortName(writer.name) {
+		return true
+	}
+	for _, alias := range reader.aliases {
+		if avroShortName(alias) == avroShortName(writer.name) {
+			return true
+		}
+	}
+	return false
+}
+
+func avroWriterField(rf *avroField, writer *avroNode) *avroField {
+	for _, wf := range writer.fields {
+		if wf.name == rf.name {
+			return wf
+		}
+	}
+	for _, wf := range writer.fields {
+		for _, alias := range rf.aliases {
+			if wf.name == alias {
+				return wf
+			}
+		}
+	}
+	return nil
+}
+
+func resolveAvro(reader, writer *avroNode) map[string]bool {
+	ar := &avroResolver{problems: map[string]bool{}, visited: map[[2]*avroNode]bool{}}
+	ar.resolve("$", reader, writer)
+	return ar.problems
+}
+
+// diffAvroSchemas applies the schema resolution rules in both directions - data written with the old
+// schema must be readable with the new schema for backward compatibility, and the reverse for forward
+func diffAvroSchemas(ctx context.Context, diff *core.DatatypeDiff, from, to *core.Datatype) error {
+	fromSchema, err := parseAvroSchema(ctx, from, diff.From)
+	if err != nil {
+		return err
+	}
+	toSchema, err := parseAvroSchema(ctx, to, diff.To)
+	if err != nil {
+		return err
+	}
+	backward := resolveAvro(toSchema, fromSchema)
+	forward := resolveAvro(fromSchema, toSchema)
+	addChanges := func(problems map[string]bool, compatibility core.DatatypeCompatibility, prefix string) {
+		keys := make([]string, 0, len(problems))
+		for k := range problems {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			path, description, _ := strings.Cut(k, "\x00")
+			diff.AddChange(path, compatibility, prefix+description)
+		}
+	}
+	addChanges(backward, core.DatatypeCompatibilityForward, "data of the previous version cannot be read: ")
+	addChanges(forward, core.DatatypeCompatibilityBackward, "data of the new version cannot be read by the previous version: ")
+	return nil
+}
@@ -0,0 +1,246 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func testDiffAvroSchemas(t *testing.T, from, to string) *core.DatatypeDiff {
+	diff, err := diffDatatypes(context.Background(), &core.Datatype{
+		Validator: core.ValidatorTypeAvro,
+		Name:      "customer",
+		Version:   "1",
+		Value:     fftypes.JSONAnyPtr(from),
+	}, &core.Datatype{
+		Validator: core.ValidatorTypeAvro,
+		Name:      "customer",
+		Version:   "2",
+		Value:     fftypes.JSONAnyPtr(to),
+	})
+	assert.NoError(t, err)
+	return diff
+}
+
+func TestDiffAvroSchemasCompatibility(t *testing.T) {
+	testCases := []struct {
+		name          string
+		from, to      string
+		compatibility core.DatatypeCompatibility
+		changes       []string
+	}{
+		{
+			name:          "field with default added",
+			from:          `{"type": "record", "name": "Customer", "fields": [{"name": "a", "type": "string"}]}`,
+			to:            `{"type": "record", "name": "Customer", "fields": [{"name": "a", "type": "string"}, {"name": "b", "type": "int", "default": 0}]}`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+		{
+			name:          "field without default added",
+			from:          `{"type": "record", "name": "Customer", "fields": [{"name": "a", "type": "string"}]}`,
+			to:            `{"type": "record", "name": "Customer", "fields": [{"name": "a", "type": "string"}, {"name": "b", "type": "int"}]}`,
+			compatibility: core.DatatypeCompatibilityForward,
+			changes:       []string{"$.b: data of the previous version cannot be read: field without a default (forward)"},
+		},
+		{
+			name:          "field without default removed",
+			from:          `{"type": "record", "name": "Customer", "fields": [{"name": "a", "type": "string"}, {"name": "b", "type": "int"}]}`,
+			to:            `{"type": "record", "name": "Customer", "fields": [{"name": "a", "type": "string"}]}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$.b: data of the new version cannot be read by the previous version: field without a default (backward)"},
+		},
+		{
+			name:          "field renamed with alias",
+			from:          `{"type": "record", "name": "Customer", "fields": [{"name": "a", "type": "string"}]}`,
+			to:            `{"type": "record", "name": "Customer", "fields": [{"name": "b", "aliases": ["a"], "type": "string"}]}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$.a: data of the new version cannot be read by the previous version: field without a default (backward)"},
+		},
+		{
+			name:          "record renamed with alias",
+			from:          `{"type": "record", "name": "acme.Customer", "fields": []}`,
+			to:            `{"type": "record", "name": "Client", "namespace": "acme", "aliases": ["Customer"], "fields": []}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$: data of the new version cannot be read by the previous version: type acme.Client cannot be read as acme.Customer (backward)"},
+		},
+		{
+			name:          "int promoted to long",
+			from:          `{"type": "record", "name": "Customer", "fields": [{"name": "a", "type": "int"}]}`,
+			to:            `{"type": "record", "name": "Customer", "fields": [{"name": "a", "type": "long"}]}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$.a: data of the new version cannot be read by the previous version: type long cannot be read as int (backward)"},
+		},
+		{
+			name:          "string and bytes",
+			from:          `"string"`,
+			to:            `"bytes"`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+		{
+			name:          "logical type of underlying type",
+			from:          `"long"`,
+			to:            `{"type": "long", "logicalType": "timestamp-millis"}`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+		{
+			name:          "type changed",
+			from:          `{"type": "array", "items": "boolean"}`,
+			to:            `{"type": "array", "items": "string"}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$[]: data of the previous version cannot be read: type boolean cannot be read as string (forward)",
+				"$[]: data of the new version cannot be read by the previous version: type string cannot be read as boolean (backward)",
+			},
+		},
+		{
+			name:          "union widened",
+			from:          `{"type": "map", "values": "string"}`,
+			to:            `{"type": "map", "values": ["null", "string"]}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$.*: data of the new version cannot be read by the previous version: type null cannot be read as string (backward)"},
+		},
+		{
+			name:          "union narrowed",
+			from:          `["null", "string", "int"]`,
+			to:            `["string", "int"]`,
+			compatibility: core.DatatypeCompatibilityForward,
+			changes:       []string{"$: data of the previous version cannot be read: type null is not in union [string,int] (forward)"},
+		},
+		{
+			name:          "union narrowed and widened",
+			from:          `["null", "string", "int"]`,
+			to:            `["string", "boolean"]`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$: data of the previous version cannot be read: type int is not in union [string,boolean] (forward)",
+				"$: data of the previous version cannot be read: type null is not in union [string,boolean] (forward)",
+				"$: data of the new version cannot be read by the previous version: type boolean is not in union [null,string,int] (backward)",
+			},
+		},
+		{
+			name:          "enum symbols",
+			from:          `{"type": "enum", "name": "Color", "symbols": ["RED", "GREEN"]}`,
+			to:            `{"type": "enum", "name": "Color", "symbols": ["RED", "GREEN", "BLUE"]}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$: data of the new version cannot be read by the previous version: enum symbol BLUE (backward)"},
+		},
+		{
+			name:          "enum symbols with default",
+			from:          `{"type": "enum", "name": "Color", "symbols": ["RED", "GREEN"], "default": "RED"}`,
+			to:            `{"type": "enum", "name": "Color", "symbols": ["RED", "BLUE"], "default": "RED"}`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+		{
+			name:          "fixed size",
+			from:          `{"type": "fixed", "name": "Hash", "size": 16}`,
+			to:            `{"type": "fixed", "name": "Hash", "size": 32}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$: data of the previous version cannot be read: fixed size 16 cannot be read as size 32 (forward)",
+				"$: data of the new version cannot be read by the previous version: fixed size 32 cannot be read as size 16 (backward)",
+			},
+		},
+		{
+			name: "recursive named types",
+			from: `{"type": "record", "name": "Node", "namespace": "acme", "fields": [
+				{"name": "children", "type": {"type": "array", "items": "Node"}},
+				{"name": "parent", "type": ["null", "acme.Node"]}
+			]}`,
+			to: `{"type": "record", "name": "Node", "namespace": "acme", "fields": [
+				{"name": "children", "type": {"type": "array", "items": "Node"}},
+				{"name": "parent", "type": ["null", "acme.Node"]},
+				{"name": "label", "type": "string", "default": ""}
+			]}`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+		{
+			name: "references to types without a namespace",
+			from: `{"type": "record", "name": "acme.A", "fields": [
+				{"name": "b", "type": {"type": "record", "name": "B", "namespace": "", "fields": []}},
+				{"name": "c", "type": "B"}
+			]}`,
+			to: `{"type": "record", "name": "acme.A", "fields": [
+				{"name": "b", "type": {"type": "record", "name": "B", "namespace": "", "fields": []}},
+				{"name": "c", "type": "B", "doc": "another B"}
+			]}`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+		{
+			name:          "errors are records",
+			from:          `{"type": "error", "name": "Failure", "fields": [{"name": "code", "type": "int"}]}`,
+			to:            `{"type": "record", "name": "Failure", "fields": [{"name": "code", "type": "int"}], "doc": "a failure"}`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff := testDiffAvroSchemas(t, tc.from, tc.to)
+			assert.Equal(t, tc.changes, changeStrings(diff))
+			assert.Equal(t, tc.compatibility, diff.Compatibility)
+		})
+	}
+}
+
+func TestDiffAvroSchemasBadSchemas(t *testing.T) {
+	for _, schema := range []string{
+		`!json`,
+		`"unknown"`,
+		`["null", "unknown"]`,
+		`12345`,
+		`{"type": "record", "name": "a", "fields": [{"name": "a", "type": "unknown"}]}`,
+		`{"type": "array", "items": "unknown"}`,
+		`{"type": "map", "values": "unknown"}`,
+	} {
+		_, err := diffDatatypes(context.Background(), &core.Datatype{
+			Validator: core.ValidatorTypeAvro,
+			Name:      "customer",
+			Version:   "1",
+			Value:     fftypes.JSONAnyPtr(schema),
+		}, &core.Datatype{
+			Validator: core.ValidatorTypeAvro,
+			Name:      "customer",
+			Version:   "2",
+			Value:     fftypes.JSONAnyPtr(`"string"`),
+		})
+		assert.Regexp(t, "FF10196.*customer/1", err)
+
+		_, err = diffDatatypes(context.Background(), &core.Datatype{
+			Validator: core.ValidatorTypeAvro,
+			Name:      "customer",
+			Version:   "1",
+			Value:     fftypes.JSONAnyPtr(`"string"`),
+		}, &core.Datatype{
+			Validator: core.ValidatorTypeAvro,
+			Name:      "customer",
+			Version:   "2",
+			Value:     fftypes.JSONAnyPtr(schema),
+		})
+		assert.Regexp(t, "FF10196.*customer/2", err)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/linkedin/goavro/v2"
+)
+
+var (
+	avroOCFMagic      = []byte("Obj\x01")
+	errUnexpectedData = errors.New("unexpected data after datum")
+)
+
+// avroValidator validates values in standard JSON (without the type wrappers that Avro JSON uses for unions),
+// and blobs in the Avro binary encoding - as a single datum, a single object encoding, or an object container file.
+type avroValidator struct {
+	id       *fftypes.UUID
+	size     int64
+	ns       string
+	datatype *core.DatatypeRef
+	codec    *goavro.Codec
+}
+
+func newAvroValidator(ctx context.Context, ns string, datatype *core.Datatype) (*avroValidator, error) {
+	av := &avroValidator{
+		id: datatype.ID,
+		ns: ns,
+		datatype: &core.DatatypeRef{
+			Name:    datatype.Name,
+			Version: datatype.Version,
+		},
+	}
+	codec, err := goavro.NewCodecForStandardJSONFull(datatype.Value.String())
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgSchemaLoadFailed, av.datatype)
+	}
+	av.codec = codec
+	av.size = int64(len(datatype.Value.String()))
+
+	log.L(ctx).Debugf("Found Avro schema validator for avro:%s:%s: %v", av.ns, datatype, av.id)
+	return av, nil
+}
+
+func (av *avroValidator) Validate(ctx context.Context, data *core.Data) error {
+	return av.ValidateValue(ctx, data.Value, data.Hash)
+}
+
+func (av *avroValidator) ValidateValue(ctx context.Context, value *fftypes.JSONAny, expectedHash *fftypes.Bytes32) error {
+	if err := checkValueHash(ctx, value, expectedHash); err != nil {
+		return err
+	}
+	_, remaining, err := av.codec.NativeFromTextual(value.Bytes())
+	if err == nil && len(bytes.TrimSpace(remaining)) > 0 {
+		err = errUnexpectedData
+	}
+	return av.validationResult(ctx, err)
+}
+
+func (av *avroValidator) ValidateBlob(ctx context.Context, content []byte) (err error) {
+	var remaining []byte
+	switch {
+	case bytes.HasPrefix(content, avroOCFMagic):
+		err = av.validateOCF(content)
+	case bytes.HasPrefix(content, []byte{0xC3, 0x01}):
+		_, remaining, err = av.codec.NativeFromSingle(content)
+	default:
+		_, remaining, err = av.codec.NativeFromBinary(content)
+	}
+	if err == nil && len(remaining) > 0 {
+		err = errUnexpectedData
+	}
+	return av.validationResult(ctx, err)
+}
+
+// validateOCF checks every record in an object container file can be encoded with the datatype schema,
+// as the records are written with the schema embedded in the file
+func (av *avroValidator) validateOCF(content []byte) error {
+	ocf, err := goavro.NewOCFReader(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	for ocf.Scan() {
+		record, err := ocf.Read()
+		if err == nil {
+			_, err = av.codec.BinaryFromNative(nil, record)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return ocf.Err()
+}
+
+func (av *avroValidator) validationResult(ctx context.Context, err error) error {
+	if err != nil {
+		log.L(ctx).Warnf("Avro schema %s [%v] validation failed: %s", av.datatype, av.id, err)
+		return i18n.NewError(ctx, coremsgs.MsgDataInvalidPerSchema, "Avro", av.datatype, err)
+	}
+	return nil
+}
+
+func (av *avroValidator) Size() int64 {
+	return av.size
+}
@@ -0,0 +1,130 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAvroSchema = `{
+	"type": "record",
+	"name": "Customer",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": ["null", "int"], "default": null}
+	]
+}`
+
+func newTestAvroValidator(t *testing.T) *avroValidator {
+	av, err := newAvroValidator(context.Background(), "ns1", &core.Datatype{
+		Validator: core.ValidatorTypeAvro,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtr(testAvroSchema),
+	})
+	assert.NoError(t, err)
+	return av
+}
+
+func TestAvroValidatorValue(t *testing.T) {
+	av := newTestAvroValidator(t)
+	ctx := context.Background()
+
+	err := av.Validate(ctx, &core.Data{Value: fftypes.JSONAnyPtr(`{"name": "fred", "age": 42}`)})
+	assert.NoError(t, err)
+
+	err = av.ValidateValue(ctx, fftypes.JSONAnyPtr(`{"name": "fred"}`), nil)
+	assert.NoError(t, err)
+
+	err = av.ValidateValue(ctx, fftypes.JSONAnyPtr(`{"name": "fred", "age": "old"}`), nil)
+	assert.Regexp(t, "FF10491.*Avro", err)
+
+	err = av.ValidateValue(ctx, fftypes.JSONAnyPtr(`{"name": "fred"} {"name": "wilma"}`), nil)
+	assert.Regexp(t, "FF10491.*unexpected data", err)
+
+	err = av.ValidateValue(ctx, nil, nil)
+	assert.Regexp(t, "FF10199", err)
+
+	assert.Equal(t, int64(len(testAvroSchema)), av.Size())
+}
+
+func TestAvroValidatorBlob(t *testing.T) {
+	av := newTestAvroValidator(t)
+	ctx := context.Background()
+	record := map[string]interface{}{"name": "fred", "age": goavro.Union("int", 42)}
+
+	binary, err := av.codec.BinaryFromNative(nil, record)
+	assert.NoError(t, err)
+	err = av.ValidateBlob(ctx, binary)
+	assert.NoError(t, err)
+
+	err = av.ValidateBlob(ctx, append(binary, 0x00))
+	assert.Regexp(t, "FF10491.*unexpected data", err)
+
+	err = av.ValidateBlob(ctx, []byte{0x08})
+	assert.Regexp(t, "FF10491", err)
+
+	single, err := av.codec.SingleFromNative(nil, record)
+	assert.NoError(t, err)
+	err = av.ValidateBlob(ctx, single)
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	ocf, err := goavro.NewOCFWriter(goavro.OCFConfig{W: buf, Schema: testAvroSchema})
+	assert.NoError(t, err)
+	err = ocf.Append([]interface{}{record, record})
+	assert.NoError(t, err)
+	err = av.ValidateBlob(ctx, buf.Bytes())
+	assert.NoError(t, err)
+}
+
+func TestAvroValidatorBlobOCFMismatch(t *testing.T) {
+	av := newTestAvroValidator(t)
+	ctx := context.Background()
+
+	buf := &bytes.Buffer{}
+	ocf, err := goavro.NewOCFWriter(goavro.OCFConfig{W: buf, Schema: `{
+		"type": "record",
+		"name": "Supplier",
+		"fields": [{"name": "id", "type": "long"}]
+	}`})
+	assert.NoError(t, err)
+	err = ocf.Append([]interface{}{map[string]interface{}{"id": 12345}})
+	assert.NoError(t, err)
+	err = av.ValidateBlob(ctx, buf.Bytes())
+	assert.Regexp(t, "FF10491", err)
+
+	err = av.ValidateBlob(ctx, []byte("Obj\x01"))
+	assert.Regexp(t, "FF10491", err)
+}
+
+func TestAvroValidatorParseSchemaFail(t *testing.T) {
+	_, err := newAvroValidator(context.Background(), "ns1", &core.Datatype{
+		Validator: core.ValidatorTypeAvro,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtr(`{"type": "wrong"}`),
+	})
+	assert.Regexp(t, "FF10196", err)
+}
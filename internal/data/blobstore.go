@@ -34,9 +34,10 @@ import (
 )
 
 type blobStore struct {
-	dm       *dataManager
-	database database.Plugin
-	exchange dataexchange.Plugin // optional
+	dm              *dataManager
+	database        database.Plugin
+	exchange        dataexchange.Plugin // optional
+	maxValidateSize int64
 }
 
 func (bs *blobStore) uploadVerifyBlob(ctx context.Context, id *fftypes.UUID, reader io.Reader) (hash *fftypes.Bytes32, written int64, payloadRef string, err error) {
@@ -119,7 +120,7 @@ func (bs *blobStore) UploadBlob(ctx context.Context, inData *core.DataRefOrValue
 		Created:    fftypes.Now(),
 	}
 
-	err = bs.dm.checkValidation(ctx, data.Validator, data.Datatype, data.Value)
+	err = bs.dm.checkValidation(ctx, data.Validator, data.Datatype, data.Value, blob)
 	if err == nil {
 		err = data.Seal(ctx, blob)
 	}
@@ -142,6 +143,29 @@ func (bs *blobStore) UploadBlob(ctx context.Context, inData *core.DataRefOrValue
 	return data, nil
 }
 
+// validateBlob reads the content of a blob into memory, to validate it against a datatype
+func (bs *blobStore) validateBlob(ctx context.Context, v BlobValidator, datatype *core.DatatypeRef, blob *core.Blob) error {
+	if bs.exchange == nil {
+		return i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
+	}
+	if blob.Size > bs.maxValidateSize {
+		return i18n.NewError(ctx, coremsgs.MsgBlobTooLargeToValidate, blob.Size, bs.maxValidateSize, datatype)
+	}
+	reader, err := bs.exchange.DownloadBlob(ctx, blob.PayloadRef)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(io.LimitReader(reader, bs.maxValidateSize+1))
+	if err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgBlobStreamingFailed)
+	}
+	if int64(len(content)) > bs.maxValidateSize {
+		return i18n.NewError(ctx, coremsgs.MsgBlobTooLargeToValidate, len(content), bs.maxValidateSize, datatype)
+	}
+	return v.ValidateBlob(ctx, content)
+}
+
 func (bs *blobStore) DownloadBlob(ctx context.Context, dataID string) (*core.Blob, io.ReadCloser, error) {
 
 	if bs.exchange == nil {
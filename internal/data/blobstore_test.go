@@ -563,3 +563,119 @@ func TestDeleteBlobBackwardCompatibilityFail(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 	mdb.AssertExpectations(t)
 }
+
+func TestUploadBlobValidateAvro(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	av := newTestAvroValidator(t)
+	b, err := av.codec.BinaryFromNative(nil, map[string]interface{}{"name": "fred"})
+	assert.NoError(t, err)
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "customer", "0.0.1").Return(&core.Datatype{
+		Validator: core.ValidatorTypeAvro,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtr(testAvroSchema),
+	}, nil)
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("InsertBlob", mock.Anything, mock.Anything).Return(nil)
+
+	var uploaded []byte
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	dxUpload := mdx.On("UploadBlob", ctx, "ns1", mock.Anything, mock.Anything)
+	dxUpload.RunFn = func(a mock.Arguments) {
+		readBytes, err := ioutil.ReadAll(a[3].(io.Reader))
+		assert.Nil(t, err)
+		uploaded = readBytes
+		var hash fftypes.Bytes32 = sha256.Sum256(readBytes)
+		dxUpload.ReturnArguments = mock.Arguments{"ns1/blob1", &hash, int64(len(readBytes)), err}
+	}
+	mdx.On("DownloadBlob", ctx, "ns1/blob1").Return(func(ctx context.Context, payloadRef string) io.ReadCloser {
+		return io.NopCloser(bytes.NewReader(uploaded))
+	}, nil)
+
+	dataIn := &core.DataRefOrValue{
+		Validator: core.ValidatorTypeAvro,
+		Datatype:  &core.DatatypeRef{Name: "customer", Version: "0.0.1"},
+	}
+	data, err := dm.UploadBlob(ctx, dataIn, &ffapi.Multipart{Data: bytes.NewReader(b), Filename: "fred.avro"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, core.ValidatorTypeAvro, data.Validator)
+
+	_, err = dm.UploadBlob(ctx, dataIn, &ffapi.Multipart{Data: bytes.NewReader([]byte{0x08})}, true)
+	assert.Regexp(t, "FF10491", err)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+
+}
+
+func TestValidateBlobDisabled(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.exchange = nil
+
+	err := dm.validateBlob(ctx, newTestAvroValidator(t), &core.DatatypeRef{}, &core.Blob{})
+	assert.Regexp(t, "FF10414", err)
+
+}
+
+func TestValidateBlobTooLarge(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	err := dm.validateBlob(ctx, newTestAvroValidator(t), &core.DatatypeRef{}, &core.Blob{Size: dm.maxValidateSize + 1})
+	assert.Regexp(t, "FF10494", err)
+
+}
+
+func TestValidateBlobDownloadFail(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/blob1").Return(nil, fmt.Errorf("pop"))
+
+	err := dm.validateBlob(ctx, newTestAvroValidator(t), &core.DatatypeRef{}, &core.Blob{PayloadRef: "ns1/blob1"})
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestValidateBlobReadFail(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/blob1").Return(io.NopCloser(iotest.ErrReader(fmt.Errorf("pop"))), nil)
+
+	err := dm.validateBlob(ctx, newTestAvroValidator(t), &core.DatatypeRef{}, &core.Blob{PayloadRef: "ns1/blob1"})
+	assert.Regexp(t, "FF10217.*pop", err)
+
+}
+
+func TestValidateBlobContentTooLarge(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.maxValidateSize = 5
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/blob1").Return(io.NopCloser(bytes.NewReader([]byte("more than five"))), nil)
+
+	err := dm.validateBlob(ctx, newTestAvroValidator(t), &core.DatatypeRef{}, &core.Blob{PayloadRef: "ns1/blob1", Size: 5})
+	assert.Regexp(t, "FF10494", err)
+
+}
@@ -36,6 +36,9 @@ import (
 
 type Manager interface {
 	CheckDatatype(ctx context.Context, datatype *core.Datatype) error
+	DiffDatatypes(ctx context.Context, from, to *core.Datatype) (*core.DatatypeDiff, error)
+	DiffLatestDatatype(ctx context.Context, datatype *core.Datatype) (*core.DatatypeDiff, error)
+	CheckDatatypeData(ctx context.Context, proposed *core.Datatype) (*core.DatatypeDataCheck, error)
 	ValidateAll(ctx context.Context, data core.DataArray) (valid bool, err error)
 	GetMessageWithDataCached(ctx context.Context, msgID *fftypes.UUID, options ...CacheReadOption) (msg *core.Message, data core.DataArray, foundAllData bool, err error)
 	GetMessageDataCached(ctx context.Context, msg *core.Message, options ...CacheReadOption) (data core.DataArray, foundAll bool, err error)
@@ -45,12 +48,18 @@ type Manager interface {
 	UpdateMessageStateIfCached(ctx context.Context, id *fftypes.UUID, state core.MessageState, confirmed *fftypes.FFTime, rejectReason string)
 	ResolveInlineData(ctx context.Context, msg *NewMessage) error
 	WriteNewMessage(ctx context.Context, newMsg *NewMessage) error
+	WriteNewMessages(ctx context.Context, newMsgs []*NewMessage) []error
 	BlobsEnabled() bool
 
 	UploadJSON(ctx context.Context, inData *core.DataRefOrValue) (*core.Data, error)
 	UploadBlob(ctx context.Context, inData *core.DataRefOrValue, blob *ffapi.Multipart, autoMeta bool) (*core.Data, error)
 	DownloadBlob(ctx context.Context, dataID string) (*core.Blob, io.ReadCloser, error)
+	DownloadLargeValue(ctx context.Context, d *core.Data) (io.ReadCloser, error)
+	LoadLargeValues(ctx context.Context, data core.DataArray) (core.DataArray, error)
+	OffloadLargeValues(ctx context.Context, data core.DataArray) (core.DataArray, error)
+	DeleteLargeValues(ctx context.Context, data core.DataArray)
 	DeleteData(ctx context.Context, dataID string) error
+	RedactData(ctx context.Context, dataID string, input *core.DataRedactInput) (*core.Data, error)
 	HydrateBatch(ctx context.Context, persistedBatch *core.BatchPersisted) (*core.Batch, error)
 	Start()
 	WaitStop()
@@ -63,6 +72,8 @@ type dataManager struct {
 	validatorCache cache.CInterface
 	messageCache   cache.CInterface
 	messageWriter  *messageWriter
+
+	largeValueThreshold int64
 }
 
 type messageCacheEntry struct {
@@ -98,13 +109,15 @@ func NewDataManager(ctx context.Context, ns *core.Namespace, di database.Plugin,
 		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "DataManager")
 	}
 	dm := &dataManager{
-		namespace: ns,
-		database:  di,
+		namespace:           ns,
+		database:            di,
+		largeValueThreshold: config.GetByteSize(coreconfig.DataLargeValueThreshold),
 	}
 	dm.blobStore = blobStore{
-		dm:       dm,
-		database: di,
-		exchange: dx,
+		dm:              dm,
+		database:        di,
+		exchange:        dx,
+		maxValidateSize: config.GetByteSize(coreconfig.ValidatorMaxBlobSize),
 	}
 
 	validatorCache, err := cacheManager.GetCache(
@@ -149,7 +162,7 @@ func (dm *dataManager) BlobsEnabled() bool {
 }
 
 func (dm *dataManager) CheckDatatype(ctx context.Context, datatype *core.Datatype) error {
-	_, err := newJSONValidator(ctx, dm.namespace.Name, datatype)
+	_, err := newValidator(ctx, dm.namespace.Name, datatype)
 	return err
 }
 
@@ -176,7 +189,12 @@ func (dm *dataManager) getValidatorForDatatype(ctx context.Context, validator co
 	if datatype == nil {
 		return nil, nil
 	}
-	v, err := newJSONValidator(ctx, dm.namespace.Name, datatype)
+	// The datatype defines the format of its schema, so determines the validator - regardless of the
+	// validator declared on the data
+	if datatype.Validator != validator {
+		log.L(ctx).Debugf("Datatype '%s:%s' has validator '%s' rather than '%s'", dm.namespace.Name, datatypeRef, datatype.Validator, validator)
+	}
+	v, err := newValidator(ctx, dm.namespace.Name, datatype)
 	if err != nil {
 		log.L(ctx).Errorf("Invalid validator stored for '%s:%s:%s': %s", validator, dm.namespace.Name, datatypeRef, err)
 		return nil, nil
@@ -326,8 +344,12 @@ func (dm *dataManager) ValidateAll(ctx context.Context, data core.DataArray) (va
 				log.L(ctx).Errorf("Datatype %s:%s:%s not found", d.Validator, d.Namespace, d.Datatype)
 				return false, err
 			}
-			err = v.ValidateValue(ctx, d.Value, d.Hash)
-			if err != nil {
+			if _, isBlobValidator := v.(BlobValidator); isBlobValidator && d.Blob != nil {
+				// The blob content was validated when it was uploaded, and is verified against the hash in the
+				// data when it is received. We do not download it here, as that would block the aggregator.
+				continue
+			}
+			if err = dm.validateStoredData(ctx, v, d, d.Datatype, d.Hash); err != nil {
 				return false, err
 			}
 		}
@@ -335,6 +357,27 @@ func (dm *dataManager) ValidateAll(ctx context.Context, data core.DataArray) (va
 	return true, nil
 }
 
+// validateStoredData validates the blob of the data if the validator supports blobs, otherwise the value
+func (dm *dataManager) validateStoredData(ctx context.Context, v Validator, d *core.Data, datatype *core.DatatypeRef, expectedHash *fftypes.Bytes32) error {
+	if bv, isBlobValidator := v.(BlobValidator); isBlobValidator {
+		blob, err := dm.resolveBlob(ctx, dm.namespace.Name, d.Blob, d.ID)
+		if err != nil {
+			return err
+		}
+		if blob != nil {
+			return dm.validateBlob(ctx, bv, datatype, blob)
+		}
+	}
+	value := d.Value
+	if d.ValueRef != nil {
+		var err error
+		if value, err = dm.readLargeValue(ctx, d); err != nil {
+			return err
+		}
+	}
+	return v.ValidateValue(ctx, value, expectedHash)
+}
+
 func (dm *dataManager) resolveRef(ctx context.Context, dataRef *core.DataRef) (*core.Data, error) {
 	if dataRef == nil || dataRef.ID == nil {
 		log.L(ctx).Warnf("data is nil")
@@ -371,7 +414,9 @@ func (dm *dataManager) resolveBlob(ctx context.Context, namespace string, blobRe
 	return nil, nil
 }
 
-func (dm *dataManager) checkValidation(ctx context.Context, validator core.ValidatorType, datatype *core.DatatypeRef, value *fftypes.JSONAny) error {
+// checkValidation validates the blob if one is supplied, and the datatype is for a format that can be
+// transferred as a blob - otherwise the value is validated
+func (dm *dataManager) checkValidation(ctx context.Context, validator core.ValidatorType, datatype *core.DatatypeRef, value *fftypes.JSONAny, blob *core.Blob) error {
 	if validator == "" {
 		validator = core.ValidatorTypeJSON
 	}
@@ -391,7 +436,11 @@ func (dm *dataManager) checkValidation(ctx context.Context, validator core.Valid
 			if v == nil {
 				return i18n.NewError(ctx, coremsgs.MsgDatatypeNotFound, datatype)
 			}
-			err = v.ValidateValue(ctx, value, nil)
+			if bv, isBlobValidator := v.(BlobValidator); isBlobValidator && blob != nil {
+				err = dm.validateBlob(ctx, bv, datatype, blob)
+			} else {
+				err = v.ValidateValue(ctx, value, nil)
+			}
 			if err != nil {
 				return err
 			}
@@ -407,12 +456,12 @@ func (dm *dataManager) validateInputData(ctx context.Context, inData *core.DataR
 	value := inData.Value
 	blobRef := inData.Blob
 
-	if err := dm.checkValidation(ctx, validator, datatype, value); err != nil {
+	blob, err := dm.resolveBlob(ctx, dm.namespace.Name, blobRef, inData.ID)
+	if err != nil {
 		return nil, err
 	}
 
-	blob, err := dm.resolveBlob(ctx, dm.namespace.Name, blobRef, inData.ID)
-	if err != nil {
+	if err := dm.checkValidation(ctx, validator, datatype, value, blob); err != nil {
 		return nil, err
 	}
 
@@ -425,6 +474,9 @@ func (dm *dataManager) validateInputData(ctx context.Context, inData *core.DataR
 		Blob:      blobRef,
 	}
 	err = data.Seal(ctx, blob)
+	if err == nil {
+		err = dm.offloadLargeValue(ctx, data)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -437,6 +489,7 @@ func (dm *dataManager) UploadJSON(ctx context.Context, inData *core.DataRefOrVal
 		return nil, err
 	}
 	if err = dm.messageWriter.WriteData(ctx, data); err != nil {
+		dm.DeleteLargeValues(ctx, core.DataArray{data})
 		return nil, err
 	}
 	return data, err
@@ -451,6 +504,12 @@ func (dm *dataManager) ResolveInlineData(ctx context.Context, newMessage *NewMes
 	if newMessage.Message == nil {
 		return i18n.NewError(ctx, i18n.MsgNilOrNullObject)
 	}
+	defer func() {
+		if err != nil {
+			// Remove any large values already stored for the new data, as the message will not be written
+			dm.DeleteLargeValues(ctx, newMessage.NewData)
+		}
+	}()
 
 	inData := newMessage.Message.InlineData
 	newMessage.AllData = make(core.DataArray, len(newMessage.Message.InlineData))
@@ -510,6 +569,11 @@ func (dm *dataManager) HydrateBatch(ctx context.Context, persistedBatch *core.Ba
 		if err != nil || d == nil {
 			return nil, i18n.WrapError(ctx, err, coremsgs.MsgFailedToRetrieve, "data", dr.ID)
 		}
+		if d.ValueRef != nil {
+			if d.Value, err = dm.readLargeValue(ctx, d); err != nil {
+				return nil, err
+			}
+		}
 		// BatchData removes any fields that could change after the batch was first assembled on the sender
 		batch.Payload.Data[i] = d.BatchData(persistedBatch.Type)
 	}
@@ -534,27 +598,37 @@ func (dm *dataManager) WriteNewMessage(ctx context.Context, newMsg *NewMessage)
 
 	err := dm.messageWriter.WriteNewMessage(ctx, newMsg)
 	if err != nil {
+		dm.DeleteLargeValues(ctx, newMsg.NewData)
 		return err
 	}
 	return nil
 }
 
+// WriteNewMessages writes a set of messages and their data in a single database transaction, returning
+// an error for each message. As with WriteNewMessage, the caller MUST NOT call this inside of a DB RunAsGroup.
+func (dm *dataManager) WriteNewMessages(ctx context.Context, newMsgs []*NewMessage) []error {
+	// The messages are written in-line, so unlike WriteNewMessage only those that were written are cached.
+	// The batch aggregator reads any message it picks up before then from the database.
+	errs := dm.messageWriter.WriteNewMessages(ctx, newMsgs)
+	for i, err := range errs {
+		if err != nil {
+			dm.DeleteLargeValues(ctx, newMsgs[i].NewData)
+		} else {
+			dm.UpdateMessageCache(&newMsgs[i].Message.Message, newMsgs[i].AllData)
+		}
+	}
+	return errs
+}
+
 func (dm *dataManager) WaitStop() {
 	dm.messageWriter.close()
 }
 
-func (dm *dataManager) DeleteData(ctx context.Context, dataID string) error {
-	id, err := fftypes.ParseUUID(ctx, dataID)
-	if err != nil {
-		return err
-	}
-
-	data, err := dm.database.GetDataByID(ctx, dm.namespace.Name, id, false)
-	if err != nil {
-		return err
-	}
-	if data == nil {
-		return i18n.NewError(ctx, coremsgs.Msg404NoResult)
+func (dm *dataManager) deleteDataBlobs(ctx context.Context, data *core.Data) error {
+	if data.ValueRef != nil && dm.exchange != nil {
+		if err := dm.exchange.DeleteBlob(ctx, data.ValueRef.PayloadRef); err != nil {
+			return err
+		}
 	}
 	if data.Blob != nil && data.Blob.Hash != nil {
 		fb := database.BlobQueryFactory.NewFilter(ctx)
@@ -571,6 +645,25 @@ func (dm *dataManager) DeleteData(ctx context.Context, dataID string) error {
 			}
 		}
 	}
+	return nil
+}
+
+func (dm *dataManager) DeleteData(ctx context.Context, dataID string) error {
+	id, err := fftypes.ParseUUID(ctx, dataID)
+	if err != nil {
+		return err
+	}
+
+	data, err := dm.database.GetDataByID(ctx, dm.namespace.Name, id, false)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return i18n.NewError(ctx, coremsgs.Msg404NoResult)
+	}
+	if err := dm.deleteDataBlobs(ctx, data); err != nil {
+		return err
+	}
 
 	// Invalidate cache entries for any messages that had these data refs
 	msgs, _, err := dm.database.GetMessagesForData(ctx, dm.namespace.Name, data.ID, database.MessageQueryFactory.NewFilter(ctx).And())
@@ -17,9 +17,11 @@
 package data
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"testing"
 	"time"
 
@@ -444,6 +446,18 @@ func TestCheckDatatypeVerifiesTheSchema(t *testing.T) {
 	assert.Regexp(t, "FF10196", err)
 }
 
+func TestCheckDatatypeSchemaFormats(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	err := dm.CheckDatatype(ctx, newTestProtobufDatatype(testProtobufSchema, "acme.Customer"))
+	assert.NoError(t, err)
+	err = dm.CheckDatatype(ctx, &core.Datatype{Validator: core.ValidatorTypeAvro, Value: fftypes.JSONAnyPtr(testAvroSchema)})
+	assert.NoError(t, err)
+	err = dm.CheckDatatype(ctx, &core.Datatype{Validator: core.ValidatorTypeXML, Value: fftypes.JSONAnyPtr(`{}`)})
+	assert.Regexp(t, "FF10196|FF10490", err)
+}
+
 func TestResolveInlineDataEmpty(t *testing.T) {
 
 	dm, ctx, cancel := newTestDataManager(t)
@@ -816,7 +830,8 @@ func TestValidateAllStoredValidatorInvalid(t *testing.T) {
 	defer cancel()
 	mdi := dm.database.(*databasemocks.Plugin)
 	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "customer", "0.0.1").Return(&core.Datatype{
-		Value: fftypes.JSONAnyPtr(`{"not": "a", "schema": true}`),
+		Validator: core.ValidatorTypeJSON,
+		Value:     fftypes.JSONAnyPtr(`{"not": "a", "schema": true}`),
 	}, nil)
 	data := &core.Data{
 		Namespace: "ns1",
@@ -831,6 +846,163 @@ func TestValidateAllStoredValidatorInvalid(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestGetValidatorForDatatypeMismatch(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "customer", "0.0.1").Return(&core.Datatype{
+		Validator: core.ValidatorTypeAvro,
+		Value:     fftypes.JSONAnyPtr(testAvroSchema),
+	}, nil)
+	v, err := dm.getValidatorForDatatype(ctx, core.ValidatorTypeJSON, &core.DatatypeRef{Name: "customer", Version: "0.0.1"})
+	assert.NoError(t, err)
+	assert.IsType(t, &avroValidator{}, v)
+	mdi.AssertExpectations(t)
+
+}
+
+func TestValidateAllBlobNotDownloaded(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "customer", "0.0.1").Return(&core.Datatype{
+		Validator: core.ValidatorTypeAvro,
+		Value:     fftypes.JSONAnyPtr(testAvroSchema),
+	}, nil)
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+
+	datatype := &core.DatatypeRef{Name: "customer", Version: "0.0.1"}
+	isValid, err := dm.ValidateAll(ctx, core.DataArray{
+		{
+			ID:        fftypes.NewUUID(),
+			Validator: core.ValidatorTypeAvro,
+			Datatype:  datatype,
+			Value:     fftypes.JSONAnyPtr(`{"filename": "fred.avro"}`),
+			Blob:      &core.BlobRef{Hash: fftypes.NewRandB32()},
+		},
+		{
+			ID:        fftypes.NewUUID(),
+			Validator: core.ValidatorTypeAvro,
+			Datatype:  datatype,
+			Value:     fftypes.JSONAnyPtr(`{"name": "wilma"}`),
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, isValid)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertNotCalled(t, "DownloadBlob", mock.Anything, mock.Anything)
+
+}
+
+func TestValidateAllJSONDataForAvroDatatype(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "customer", "0.0.1").Return(&core.Datatype{
+		Validator: core.ValidatorTypeAvro,
+		Value:     fftypes.JSONAnyPtr(testAvroSchema),
+	}, nil)
+
+	isValid, err := dm.ValidateAll(ctx, core.DataArray{
+		{
+			ID:        fftypes.NewUUID(),
+			Validator: core.ValidatorTypeJSON,
+			Datatype:  &core.DatatypeRef{Name: "customer", Version: "0.0.1"},
+			Value:     fftypes.JSONAnyPtr(`{"age": 10}`),
+		},
+	})
+	assert.Regexp(t, "FF10491", err)
+	assert.False(t, isValid)
+
+}
+
+func TestValidateStoredDataBlob(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	av := newTestAvroValidator(t)
+	b, err := av.codec.BinaryFromNative(nil, map[string]interface{}{"name": "fred"})
+	assert.NoError(t, err)
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	blobHash := fftypes.NewRandB32()
+	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{{
+		Hash:       blobHash,
+		PayloadRef: "ns1/blob1",
+		Size:       int64(len(b)),
+	}}, nil, nil)
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/blob1").Return(io.NopCloser(bytes.NewReader(b)), nil)
+
+	datatype := &core.DatatypeRef{Name: "customer", Version: "0.0.1"}
+	err = dm.validateStoredData(ctx, av, &core.Data{
+		ID:        fftypes.NewUUID(),
+		Validator: core.ValidatorTypeAvro,
+		Datatype:  datatype,
+		Value:     fftypes.JSONAnyPtr(`{"filename": "fred.avro"}`),
+		Blob:      &core.BlobRef{Hash: blobHash},
+	}, datatype, nil)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+
+}
+
+func TestValidateStoredDataBlobNotFound(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{}, nil, nil)
+
+	datatype := &core.DatatypeRef{Name: "customer", Version: "0.0.1"}
+	err := dm.validateStoredData(ctx, newTestAvroValidator(t), &core.Data{
+		ID:        fftypes.NewUUID(),
+		Validator: core.ValidatorTypeAvro,
+		Datatype:  datatype,
+		Blob:      &core.BlobRef{Hash: fftypes.NewRandB32()},
+	}, datatype, nil)
+	assert.Regexp(t, "FF10239", err)
+
+}
+
+func TestUploadJSONBlobValidationFail(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "customer", "0.0.1").Return(&core.Datatype{
+		Validator: core.ValidatorTypeAvro,
+		Value:     fftypes.JSONAnyPtr(testAvroSchema),
+	}, nil)
+	blobHash := fftypes.NewRandB32()
+	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{{
+		Hash:       blobHash,
+		PayloadRef: "ns1/blob1",
+	}}, nil, nil)
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/blob1").Return(io.NopCloser(bytes.NewReader([]byte{0x08})), nil)
+
+	_, err := dm.UploadJSON(ctx, &core.DataRefOrValue{
+		DataRef:   core.DataRef{ID: fftypes.NewUUID()},
+		Validator: core.ValidatorTypeAvro,
+		Datatype:  &core.DatatypeRef{Name: "customer", Version: "0.0.1"},
+		Value:     fftypes.JSONAnyPtr(`{"name": "valid value, but not validated"}`),
+		Blob:      &core.BlobRef{Hash: blobHash},
+	})
+	assert.Regexp(t, "FF10491", err)
+
+}
+
 func TestHydrateBatchOK(t *testing.T) {
 	dm, ctx, cancel := newTestDataManager(t)
 	defer cancel()
@@ -1379,3 +1551,21 @@ func TestDeleteDataFailGetMessages(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 	mdb.AssertExpectations(t)
 }
+
+func TestWriteNewMessages(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	nm := testBulkNewMessage("")
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("RunAsGroup", ctx, mock.Anything).Return(nil)
+
+	errs := dm.WriteNewMessages(ctx, []*NewMessage{nm})
+	assert.Equal(t, []error{nil}, errs)
+
+	msg, data := dm.PeekMessageCache(ctx, nm.Message.Header.ID)
+	assert.Equal(t, &nm.Message.Message, msg)
+	assert.Equal(t, nm.AllData, data)
+
+	mdi.AssertExpectations(t)
+}
@@ -0,0 +1,108 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// RedactData removes the value and any blob of a private data item from this node, to honor requests
+// to erase personal data. The hash is retained, so the pins and batches containing the data still verify.
+// Data that has been broadcast cannot be redacted, as it remains available on shared storage.
+func (dm *dataManager) RedactData(ctx context.Context, dataID string, input *core.DataRedactInput) (*core.Data, error) {
+	id, err := fftypes.ParseUUID(ctx, dataID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := dm.database.GetDataByID(ctx, dm.namespace.Name, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NoResult)
+	}
+	if data.Redacted != nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDataAlreadyRedacted, data.ID)
+	}
+	if data.Public != "" || (data.Blob != nil && data.Blob.Public != "") {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDataRedactBroadcast, data.ID)
+	}
+
+	// Every message containing the data must be private, and must have finished processing - otherwise
+	// the tombstone could be sent to, or validated against, in place of the original value
+	msgs, _, err := dm.database.GetMessagesForData(ctx, dm.namespace.Name, data.ID, database.MessageQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range msgs {
+		if msg.Header.Group == nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgDataRedactBroadcast, data.ID)
+		}
+		switch msg.State {
+		case core.MessageStateConfirmed, core.MessageStateRejected, core.MessageStateCancelled:
+		default:
+			return nil, i18n.NewError(ctx, coremsgs.MsgDataRedactMessageNotFinal, data.ID, msg.Header.ID, msg.State)
+		}
+	}
+
+	// Blobs are deleted from data exchange first, as this cannot be done in a database transaction.
+	// If the database update then fails, the redaction can be retried.
+	if err := dm.deleteDataBlobs(ctx, data); err != nil {
+		return nil, err
+	}
+
+	data.Value = core.DataRedactedValue
+	data.ValueSize = data.Value.Length()
+	data.ValueRef = nil
+	if data.Blob != nil {
+		// The blob name comes from the value, and could itself be personal data
+		data.Blob.Name = ""
+		data.Blob.Path = ""
+	}
+	// The redactor is the principal the auth plugin authenticated for the request, rather than
+	// anything supplied in the input - which is only kept as a note
+	data.Redacted = &core.DataRedaction{
+		By:      core.GetAuthRequestInfo(ctx).Principal,
+		Author:  input.Author,
+		Reason:  input.Reason,
+		Created: fftypes.Now(),
+	}
+	err = dm.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := dm.database.UpsertData(ctx, data, database.UpsertOptimizationExisting); err != nil {
+			return err
+		}
+		return dm.database.InsertEvent(ctx, core.NewEvent(core.EventTypeDataRedacted, dm.namespace.Name, data.ID, nil, ""))
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Redacted data '%s' by principal '%s' (author='%s')", data.ID, data.Redacted.By, input.Author)
+
+	// Invalidate cache entries for any messages that had these data refs
+	for _, msg := range msgs {
+		dm.messageCache.Set(msg.Header.ID.String(), nil)
+	}
+	return data, nil
+}
@@ -0,0 +1,231 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRedactableData(dm *dataManager) *core.Data {
+	return &core.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: dm.namespace.Name,
+		Hash:      fftypes.NewRandB32(),
+		Value:     fftypes.JSONAnyPtr(`{"name":"passport.pdf"}`),
+		Blob: &core.BlobRef{
+			Hash: fftypes.NewRandB32(),
+			Name: "passport.pdf",
+			Path: "/",
+			Size: 12345,
+		},
+	}
+}
+
+func newTestRedactableMessage(state core.MessageState) *core.Message {
+	return &core.Message{
+		Header: core.MessageHeader{
+			ID:    fftypes.NewUUID(),
+			Group: fftypes.NewRandB32(),
+		},
+		State: state,
+	}
+}
+
+func mockRedactRunAsGroup(mdi *databasemocks.Plugin) {
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+}
+
+func TestRedactData(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	ctx = core.WithAuthRequestInfo(ctx, &core.AuthRequestInfo{Principal: "jwt:dpo"})
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+
+	data := newTestRedactableData(dm)
+	hash := data.Hash
+	msg := newTestRedactableMessage(core.MessageStateConfirmed)
+	dm.UpdateMessageCache(msg, core.DataArray{data})
+	blob := &core.Blob{
+		Sequence:   12345,
+		Namespace:  dm.namespace.Name,
+		PayloadRef: "payloadRef",
+		Hash:       data.Blob.Hash,
+		DataID:     data.ID,
+	}
+
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, true).Return(data, nil)
+	mdi.On("GetMessagesForData", ctx, "ns1", data.ID, mock.Anything).Return([]*core.Message{msg}, &ffapi.FilterResult{}, nil)
+	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return([]*core.Blob{blob}, &ffapi.FilterResult{}, nil)
+	mdx.On("DeleteBlob", ctx, "payloadRef").Return(nil)
+	mdi.On("DeleteBlob", ctx, int64(12345)).Return(nil)
+	mockRedactRunAsGroup(mdi)
+	mdi.On("UpsertData", mock.Anything, mock.MatchedBy(func(d *core.Data) bool {
+		return d.Hash.Equals(hash) &&
+			d.Value.String() == core.DataRedactedValue.String() &&
+			d.Blob.Hash.Equals(blob.Hash) &&
+			d.Blob.Name == "" &&
+			d.Redacted.By == "jwt:dpo" &&
+			d.Redacted.Author == "dpo@example.com"
+	}), database.UpsertOptimizationExisting).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeDataRedacted && e.Reference.Equals(data.ID)
+	})).Return(nil)
+
+	redacted, err := dm.RedactData(ctx, data.ID.String(), &core.DataRedactInput{
+		Author: "dpo@example.com",
+		Reason: "erasure request 12345",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "erasure request 12345", redacted.Redacted.Reason)
+	assert.NotNil(t, redacted.Redacted.Created)
+	assert.Equal(t, int64(17), redacted.ValueSize)
+
+	// The cached message data is invalidated
+	cachedMsg, _ := dm.PeekMessageCache(ctx, msg.Header.ID)
+	assert.Nil(t, cachedMsg)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func TestRedactDataBadID(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	_, err := dm.RedactData(ctx, "!uuid", &core.DataRedactInput{Author: "dpo@example.com"})
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestRedactDataGetDataFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDataByID", ctx, "ns1", mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+	_, err := dm.RedactData(ctx, fftypes.NewUUID().String(), &core.DataRedactInput{Author: "dpo@example.com"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRedactDataNotFound(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDataByID", ctx, "ns1", mock.Anything, true).Return(nil, nil)
+	_, err := dm.RedactData(ctx, fftypes.NewUUID().String(), &core.DataRedactInput{Author: "dpo@example.com"})
+	assert.Regexp(t, "FF10143", err)
+}
+
+func TestRedactDataAlreadyRedacted(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	data := newTestRedactableData(dm)
+	data.Redacted = &core.DataRedaction{Author: "someone", Created: fftypes.Now()}
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, true).Return(data, nil)
+	_, err := dm.RedactData(ctx, data.ID.String(), &core.DataRedactInput{Author: "dpo@example.com"})
+	assert.Regexp(t, "FF10508", err)
+}
+
+func TestRedactDataPublished(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	data := newTestRedactableData(dm)
+	data.Blob.Public = "ipfs-ref"
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, true).Return(data, nil)
+	_, err := dm.RedactData(ctx, data.ID.String(), &core.DataRedactInput{Author: "dpo@example.com"})
+	assert.Regexp(t, "FF10509", err)
+}
+
+func TestRedactDataGetMessagesFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	data := newTestRedactableData(dm)
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, true).Return(data, nil)
+	mdi.On("GetMessagesForData", ctx, "ns1", data.ID, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := dm.RedactData(ctx, data.ID.String(), &core.DataRedactInput{Author: "dpo@example.com"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRedactDataBroadcastMessage(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	data := newTestRedactableData(dm)
+	msg := newTestRedactableMessage(core.MessageStateConfirmed)
+	msg.Header.Group = nil
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, true).Return(data, nil)
+	mdi.On("GetMessagesForData", ctx, "ns1", data.ID, mock.Anything).Return([]*core.Message{msg}, &ffapi.FilterResult{}, nil)
+	_, err := dm.RedactData(ctx, data.ID.String(), &core.DataRedactInput{Author: "dpo@example.com"})
+	assert.Regexp(t, "FF10509", err)
+}
+
+func TestRedactDataMessageNotFinal(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	data := newTestRedactableData(dm)
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, true).Return(data, nil)
+	mdi.On("GetMessagesForData", ctx, "ns1", data.ID, mock.Anything).Return([]*core.Message{
+		newTestRedactableMessage(core.MessageStateRejected),
+		newTestRedactableMessage(core.MessageStateSent),
+	}, &ffapi.FilterResult{}, nil)
+	_, err := dm.RedactData(ctx, data.ID.String(), &core.DataRedactInput{Author: "dpo@example.com"})
+	assert.Regexp(t, "FF10510.*sent", err)
+}
+
+func TestRedactDataDeleteBlobFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	data := newTestRedactableData(dm)
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, true).Return(data, nil)
+	mdi.On("GetMessagesForData", ctx, "ns1", data.ID, mock.Anything).Return([]*core.Message{}, &ffapi.FilterResult{}, nil)
+	mdi.On("GetBlobs", ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := dm.RedactData(ctx, data.ID.String(), &core.DataRedactInput{Author: "dpo@example.com"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRedactDataUpsertFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	data := newTestRedactableData(dm)
+	data.Blob = nil
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, true).Return(data, nil)
+	mdi.On("GetMessagesForData", ctx, "ns1", data.ID, mock.Anything).Return([]*core.Message{}, &ffapi.FilterResult{}, nil)
+	mockRedactRunAsGroup(mdi)
+	mdi.On("UpsertData", mock.Anything, mock.Anything, database.UpsertOptimizationExisting).Return(fmt.Errorf("pop"))
+	_, err := dm.RedactData(ctx, data.ID.String(), &core.DataRedactInput{Author: "dpo@example.com"})
+	assert.EqualError(t, err, "pop")
+}
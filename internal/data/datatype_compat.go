@@ -0,0 +1,141 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+const (
+	datatypeCheckPageSize    = 100
+	maxDatatypeCheckFailures = 100
+)
+
+// schemaPath builds the path of a change in a schema, such as $.order.items[].id
+func schemaPath(parent, child string) string {
+	if child == "[]" {
+		return parent + child
+	}
+	return parent + "." + child
+}
+
+// diffDatatypes returns the changes between two versions of a datatype, and the compatibility they retain.
+// Backward compatible changes mean data valid against the old version is valid against the new version,
+// and forward compatible changes mean data valid against the new version is valid against the old version.
+func diffDatatypes(ctx context.Context, from, to *core.Datatype) (diff *core.DatatypeDiff, err error) {
+	diff = &core.DatatypeDiff{
+		From:          &core.DatatypeRef{Name: from.Name, Version: from.Version},
+		To:            &core.DatatypeRef{Name: to.Name, Version: to.Version},
+		Compatibility: core.DatatypeCompatibilityFull,
+		Changes:       []*core.DatatypeChange{},
+	}
+	fromValidator, toValidator := from.Validator, to.Validator
+	if fromValidator == "" {
+		fromValidator = core.ValidatorTypeJSON
+	}
+	if toValidator == "" {
+		toValidator = core.ValidatorTypeJSON
+	}
+	switch {
+	case fromValidator != toValidator:
+		diff.AddChange("$", core.DatatypeCompatibilityNone, fmt.Sprintf("validator changed from '%s' to '%s'", fromValidator, toValidator))
+	case from.Value.Hash().Equals(to.Value.Hash()):
+		// identical schemas
+	case toValidator == core.ValidatorTypeJSON:
+		err = diffJSONSchemas(ctx, diff, from, to)
+	case toValidator == core.ValidatorTypeAvro:
+		err = diffAvroSchemas(ctx, diff, from, to)
+	case toValidator == core.ValidatorTypeProtobuf:
+		err = diffProtobufSchemas(ctx, diff, from, to)
+	default:
+		// XML Schema is too rich to analyze structurally, so any change is treated as incompatible
+		diff.AddChange("$", core.DatatypeCompatibilityNone, "XML Schema changed")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+func (dm *dataManager) DiffDatatypes(ctx context.Context, from, to *core.Datatype) (*core.DatatypeDiff, error) {
+	return diffDatatypes(ctx, from, to)
+}
+
+// DiffLatestDatatype returns the changes from the most recently created other version of the datatype,
+// or nil if this is the first version
+func (dm *dataManager) DiffLatestDatatype(ctx context.Context, datatype *core.Datatype) (*core.DatatypeDiff, error) {
+	fb := database.DatatypeQueryFactory.NewFilter(ctx)
+	filter := fb.And(fb.Eq("name", datatype.Name), fb.Neq("version", datatype.Version)).Sort("created").Descending().Limit(1)
+	previous, _, err := dm.database.GetDatatypes(ctx, dm.namespace.Name, filter)
+	if err != nil || len(previous) == 0 {
+		return nil, err
+	}
+	return diffDatatypes(ctx, previous[0], datatype)
+}
+
+// CheckDatatypeData validates all stored data that refers to any version of a datatype, against a proposed
+// new version - along with the compatibility of the proposed version with the latest version
+func (dm *dataManager) CheckDatatypeData(ctx context.Context, proposed *core.Datatype) (*core.DatatypeDataCheck, error) {
+	if proposed.Validator == "" {
+		proposed.Validator = core.ValidatorTypeJSON
+	}
+	if err := proposed.Validate(ctx, false); err != nil {
+		return nil, err
+	}
+	v, err := newValidator(ctx, dm.namespace.Name, proposed)
+	if err != nil {
+		return nil, err
+	}
+	check := &core.DatatypeDataCheck{
+		Compatible: true,
+		Failures:   []*core.DatatypeDataFailure{},
+	}
+	if check.Diff, err = dm.DiffLatestDatatype(ctx, proposed); err != nil {
+		return nil, err
+	}
+	if check.Diff != nil {
+		check.Compatible = check.Diff.Satisfies(proposed.Compatibility)
+	}
+
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	for skip := uint64(0); ; skip += datatypeCheckPageSize {
+		filter := fb.And(fb.Eq("datatype.name", proposed.Name)).Sort("created").Skip(skip).Limit(datatypeCheckPageSize)
+		data, _, err := dm.database.GetData(ctx, dm.namespace.Name, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range data {
+			if d.Validator == core.ValidatorTypeNone {
+				continue
+			}
+			check.Checked++
+			if err := dm.validateStoredData(ctx, v, d, d.Datatype, nil); err != nil {
+				check.Invalid++
+				if len(check.Failures) < maxDatatypeCheckFailures {
+					check.Failures = append(check.Failures, &core.DatatypeDataFailure{Data: d.ID, Datatype: d.Datatype, Error: err.Error()})
+				}
+			}
+		}
+		if len(data) < datatypeCheckPageSize {
+			return check, nil
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestCustomerDatatype(version, schema string) *core.Datatype {
+	return &core.Datatype{
+		ID:        fftypes.NewUUID(),
+		Validator: core.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Name:      "customer",
+		Version:   version,
+		Value:     fftypes.JSONAnyPtr(schema),
+	}
+}
+
+func TestDiffDatatypesValidatorChanged(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	from := newTestCustomerDatatype("1", `{}`)
+	from.Validator = ""
+	to := newTestProtobufDatatype(testProtobufSchema, "acme.Customer")
+	diff, err := dm.DiffDatatypes(ctx, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, core.DatatypeCompatibilityNone, diff.Compatibility)
+	assert.Equal(t, []string{"$: validator changed from 'json' to 'protobuf' (none)"}, changeStrings(diff))
+}
+
+func TestDiffDatatypesIdentical(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	from := newTestCustomerDatatype("1", `{"type": "object"}`)
+	to := newTestCustomerDatatype("2", `{"type": "object"}`)
+	to.Validator = ""
+	diff, err := dm.DiffDatatypes(ctx, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, core.DatatypeCompatibilityFull, diff.Compatibility)
+	assert.Empty(t, diff.Changes)
+	assert.Equal(t, "customer/1", diff.From.String())
+	assert.Equal(t, "customer/2", diff.To.String())
+}
+
+func TestDiffDatatypesXML(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	from := newTestCustomerDatatype("1", `"<xs:schema xmlns:xs=\"http://www.w3.org/2001/XMLSchema\"/>"`)
+	from.Validator = core.ValidatorTypeXML
+	to := newTestCustomerDatatype("2", `"<xs:schema xmlns:xs=\"http://www.w3.org/2001/XMLSchema\"></xs:schema>"`)
+	to.Validator = core.ValidatorTypeXML
+	diff, err := dm.DiffDatatypes(ctx, from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, core.DatatypeCompatibilityNone, diff.Compatibility)
+	assert.Equal(t, []string{"$: XML Schema changed (none)"}, changeStrings(diff))
+}
+
+func TestDiffLatestDatatype(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	previous := newTestCustomerDatatype("1", `{"type": "object", "properties": {"a": {"type": "string"}}}`)
+	proposed := newTestCustomerDatatype("2", `{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a"]}`)
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypes", ctx, "ns1", mock.MatchedBy(func(filter ffapi.Filter) bool {
+		fi, err := filter.Finalize()
+		assert.NoError(t, err)
+		return fi.String() == "( name == 'customer' ) && ( version != '2' ) sort=-created limit=1"
+	})).Return([]*core.Datatype{previous}, nil, nil)
+
+	diff, err := dm.DiffLatestDatatype(ctx, proposed)
+	assert.NoError(t, err)
+	assert.Equal(t, core.DatatypeCompatibilityForward, diff.Compatibility)
+	assert.Equal(t, "customer/1", diff.From.String())
+	mdi.AssertExpectations(t)
+}
+
+func TestDiffLatestDatatypeFirstVersion(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypes", ctx, "ns1", mock.Anything).Return([]*core.Datatype{}, nil, nil)
+
+	diff, err := dm.DiffLatestDatatype(ctx, newTestCustomerDatatype("1", `{}`))
+	assert.NoError(t, err)
+	assert.Nil(t, diff)
+	mdi.AssertExpectations(t)
+}
+
+func TestDiffLatestDatatypeFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypes", ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := dm.DiffLatestDatatype(ctx, newTestCustomerDatatype("1", `{}`))
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
+func TestCheckDatatypeData(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	previous := newTestCustomerDatatype("1", `{"type": "object", "properties": {"a": {"type": "string"}}}`)
+	proposed := newTestCustomerDatatype("2", `{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a"]}`)
+	proposed.Validator = ""
+	proposed.Compatibility = core.DatatypeCompatibilityBackward
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypes", ctx, "ns1", mock.Anything).Return([]*core.Datatype{previous}, nil, nil)
+
+	page := make(core.DataArray, datatypeCheckPageSize)
+	for i := range page {
+		page[i] = &core.Data{
+			ID:        fftypes.NewUUID(),
+			Validator: core.ValidatorTypeJSON,
+			Datatype:  &core.DatatypeRef{Name: "customer", Version: "1"},
+			Value:     fftypes.JSONAnyPtr(`{"a": "valid"}`),
+		}
+	}
+	page[0].Value = fftypes.JSONAnyPtr(`{}`)
+	page[1].Validator = core.ValidatorTypeNone
+	page[1].Value = fftypes.JSONAnyPtr(`"not an object"`)
+	mdi.On("GetData", ctx, "ns1", mock.Anything).Return(page, nil, nil).Once()
+	mdi.On("GetData", ctx, "ns1", mock.Anything).Return(core.DataArray{
+		{
+			ID:        fftypes.NewUUID(),
+			Validator: core.ValidatorTypeJSON,
+			Datatype:  &core.DatatypeRef{Name: "customer", Version: "1"},
+			Value:     fftypes.JSONAnyPtr(`{"a": 12345}`),
+		},
+	}, nil, nil).Once()
+
+	check, err := dm.CheckDatatypeData(ctx, proposed)
+	assert.NoError(t, err)
+	assert.False(t, check.Compatible)
+	assert.Equal(t, core.DatatypeCompatibilityForward, check.Diff.Compatibility)
+	assert.Equal(t, int64(datatypeCheckPageSize), check.Checked)
+	assert.Equal(t, int64(2), check.Invalid)
+	assert.Len(t, check.Failures, 2)
+	assert.Equal(t, page[0].ID, check.Failures[0].Data)
+	assert.Equal(t, "customer/1", check.Failures[0].Datatype.String())
+	assert.Regexp(t, "FF10198", check.Failures[0].Error)
+	mdi.AssertExpectations(t)
+}
+
+func TestCheckDatatypeDataMaxFailures(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypes", ctx, "ns1", mock.Anything).Return([]*core.Datatype{}, nil, nil)
+	invalid := func(count int) core.DataArray {
+		data := make(core.DataArray, count)
+		for i := range data {
+			data[i] = &core.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"not an object"`)}
+		}
+		return data
+	}
+	mdi.On("GetData", ctx, "ns1", mock.Anything).Return(invalid(datatypeCheckPageSize), nil, nil).Once()
+	mdi.On("GetData", ctx, "ns1", mock.Anything).Return(invalid(10), nil, nil).Once()
+
+	check, err := dm.CheckDatatypeData(ctx, newTestCustomerDatatype("1", `{"type": "object"}`))
+	assert.NoError(t, err)
+	assert.True(t, check.Compatible)
+	assert.Nil(t, check.Diff)
+	assert.Equal(t, int64(datatypeCheckPageSize+10), check.Invalid)
+	assert.Len(t, check.Failures, maxDatatypeCheckFailures)
+	mdi.AssertExpectations(t)
+}
+
+func TestCheckDatatypeDataInvalidDatatype(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	_, err := dm.CheckDatatypeData(ctx, newTestCustomerDatatype("!bad", `{}`))
+	assert.Regexp(t, "FF00140.*version", err)
+}
+
+func TestCheckDatatypeDataInvalidSchema(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	_, err := dm.CheckDatatypeData(ctx, newTestCustomerDatatype("1", `{"type": "unknown"}`))
+	assert.Regexp(t, "FF10196", err)
+}
+
+func TestCheckDatatypeDataDiffFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypes", ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := dm.CheckDatatypeData(ctx, newTestCustomerDatatype("1", `{}`))
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
+func TestCheckDatatypeDataQueryFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypes", ctx, "ns1", mock.Anything).Return([]*core.Datatype{}, nil, nil)
+	mdi.On("GetData", ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := dm.CheckDatatypeData(ctx, newTestCustomerDatatype("1", `{}`))
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
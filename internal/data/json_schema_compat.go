@@ -0,0 +1,424 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const maxJSONSchemaDiffDepth = 32
+
+// Keywords that do not affect which values are valid (format is an annotation in JSON Schema 2020-12,
+// and definitions are compared where they are referenced)
+var jsonSchemaAnnotations = map[string]bool{
+	"$id": true, "$schema": true, "$comment": true, "$anchor": true, "$dynamicAnchor": true, "$vocabulary": true,
+	"$defs": true, "definitions": true, "title": true, "description": true, "examples": true, "default": true,
+	"deprecated": true, "readOnly": true, "writeOnly": true, "format": true, "contentMediaType": true, "contentEncoding": true,
+}
+
+var (
+	jsonSchemaLowerBounds = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties", "minContains"}
+	jsonSchemaUpperBounds = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties", "maxContains"}
+)
+
+// jsonSchemaDiff compares the keywords of JSON Schema that can be analyzed structurally - types, allowed
+// values, bounds, required and additional properties, and the schemas of properties and array items.
+// Changes to any other keyword (such as pattern or allOf) are treated as incompatible.
+type jsonSchemaDiff struct {
+	diff     *core.DatatypeDiff
+	fromRoot interface{}
+	toRoot   interface{}
+}
+
+func diffJSONSchemas(ctx context.Context, diff *core.DatatypeDiff, from, to *core.Datatype) error {
+	jd := &jsonSchemaDiff{diff: diff}
+	if err := json.Unmarshal(from.Value.Bytes(), &jd.fromRoot); err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgSchemaLoadFailed, diff.From)
+	}
+	if err := json.Unmarshal(to.Value.Bytes(), &jd.toRoot); err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgSchemaLoadFailed, diff.To)
+	}
+	jd.compare("$", jd.fromRoot, jd.toRoot, 0)
+	return nil
+}
+
+func (jd *jsonSchemaDiff) widened(path, format string, args ...interface{}) {
+	jd.diff.AddChange(path, core.DatatypeCompatibilityBackward, fmt.Sprintf(format, args...))
+}
+
+func (jd *jsonSchemaDiff) narrowed(path, format string, args ...interface{}) {
+	jd.diff.AddChange(path, core.DatatypeCompatibilityForward, fmt.Sprintf(format, args...))
+}
+
+func (jd *jsonSchemaDiff) changed(path, format string, args ...interface{}) {
+	jd.diff.AddChange(path, core.DatatypeCompatibilityNone, fmt.Sprintf(format, args...))
+}
+
+func (jd *jsonSchemaDiff) compare(path string, from, to interface{}, depth int) {
+	if depth > maxJSONSchemaDiffDepth {
+		if !reflect.DeepEqual(from, to) {
+			jd.changed(path, "schema changed")
+		}
+		return
+	}
+	fromMap, fromOK := jsonSchemaAsMap(resolveJSONSchemaRef(jd.fromRoot, from))
+	toMap, toOK := jsonSchemaAsMap(resolveJSONSchemaRef(jd.toRoot, to))
+	switch {
+	case !fromOK && !toOK:
+		return
+	case !fromOK:
+		jd.widened(path, "values are now accepted")
+		return
+	case !toOK:
+		jd.narrowed(path, "values are no longer accepted")
+		return
+	}
+
+	handled := map[string]bool{}
+	jd.compareTypes(path, fromMap, toMap, handled)
+	jd.compareValues(path, fromMap, toMap, handled)
+	jd.compareBounds(path, fromMap, toMap, handled)
+	jd.compareRequired(path, fromMap, toMap, handled)
+	jd.compareProperties(path, fromMap, toMap, handled, depth)
+	jd.compareItems(path, fromMap, toMap, handled, depth)
+
+	for _, k := range sortedKeys(fromMap, toMap) {
+		if !handled[k] && !jsonSchemaAnnotations[k] && !reflect.DeepEqual(fromMap[k], toMap[k]) {
+			jd.changed(path, "'%s' changed", k)
+		}
+	}
+}
+
+// jsonSchemaAsMap returns the keywords of a schema, with true as an empty schema that accepts
+// all values - or false if the schema accepts no values
+func jsonSchemaAsMap(schema interface{}) (map[string]interface{}, bool) {
+	switch s := schema.(type) {
+	case map[string]interface{}:
+		return s, true
+	case bool:
+		return map[string]interface{}{}, s
+	default:
+		return nil, false
+	}
+}
+
+// resolveJSONSchemaRef follows local references, where the reference is the only keyword of the schema
+func resolveJSONSchemaRef(root, schema interface{}) interface{} {
+	for i := 0; i < maxJSONSchemaDiffDepth; i++ {
+		m, ok := schema.(map[string]interface{})
+		if !ok {
+			return schema
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#") {
+			return schema
+		}
+		for k := range m {
+			if k != "$ref" && !jsonSchemaAnnotations[k] {
+				return schema
+			}
+		}
+		target := root
+		for _, segment := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+			segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+			switch t := target.(type) {
+			case map[string]interface{}:
+				target = t[segment]
+			case []interface{}:
+				idx, err := strconv.Atoi(segment)
+				if err != nil || idx < 0 || idx >= len(t) {
+					return schema
+				}
+				target = t[idx]
+			default:
+				return schema
+			}
+		}
+		if target == nil {
+			return schema
+		}
+		schema = target
+	}
+	return schema
+}
+
+func sortedKeys(maps ...map[string]interface{}) []string {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// compareSets reports the values added to, and removed from, a set that restricts the valid values - where
+// a nil set is unrestricted
+func (jd *jsonSchemaDiff) compareSets(path, name string, from, to map[string]bool) {
+	switch {
+	case from == nil && to == nil:
+	case from == nil:
+		jd.narrowed(path, "%s restricted to %s", name, strings.Join(setKeys(to, nil), ", "))
+	case to == nil:
+		jd.widened(path, "%s no longer restricted", name)
+	default:
+		if added := setKeys(to, from); len(added) > 0 {
+			jd.widened(path, "%s added: %s", name, strings.Join(added, ", "))
+		}
+		if removed := setKeys(from, to); len(removed) > 0 {
+			jd.narrowed(path, "%s removed: %s", name, strings.Join(removed, ", "))
+		}
+	}
+}
+
+func setKeys(set, exclude map[string]bool) []string {
+	keys := []string{}
+	for k := range set {
+		if !exclude[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (jd *jsonSchemaDiff) compareTypes(path string, from, to map[string]interface{}, handled map[string]bool) {
+	fromTypes, fromOK := jsonSchemaTypes(from)
+	toTypes, toOK := jsonSchemaTypes(to)
+	if fromOK && toOK {
+		handled["type"] = true
+		jd.compareSets(path, "types", fromTypes, toTypes)
+	}
+}
+
+func jsonSchemaTypes(schema map[string]interface{}) (map[string]bool, bool) {
+	var types []interface{}
+	switch t := schema["type"].(type) {
+	case nil:
+		return nil, true
+	case string:
+		types = []interface{}{t}
+	case []interface{}:
+		types = t
+	default:
+		return nil, false
+	}
+	set := map[string]bool{}
+	for _, t := range types {
+		s, ok := t.(string)
+		if !ok {
+			return nil, false
+		}
+		set[s] = true
+	}
+	if set["number"] {
+		// integers are a subset of numbers
+		set["integer"] = true
+	}
+	return set, true
+}
+
+func (jd *jsonSchemaDiff) compareValues(path string, from, to map[string]interface{}, handled map[string]bool) {
+	fromValues, fromOK := jsonSchemaValues(from)
+	toValues, toOK := jsonSchemaValues(to)
+	if fromOK && toOK {
+		handled["enum"] = true
+		handled["const"] = true
+		jd.compareSets(path, "allowed values", fromValues, toValues)
+	}
+}
+
+func jsonSchemaValues(schema map[string]interface{}) (map[string]bool, bool) {
+	enum, hasEnum := schema["enum"]
+	constValue, hasConst := schema["const"]
+	var values []interface{}
+	switch {
+	case hasEnum && hasConst:
+		return nil, false
+	case hasConst:
+		values = []interface{}{constValue}
+	case hasEnum:
+		var ok bool
+		if values, ok = enum.([]interface{}); !ok {
+			return nil, false
+		}
+	default:
+		return nil, true
+	}
+	set := map[string]bool{}
+	for _, v := range values {
+		b, _ := json.Marshal(v)
+		set[string(b)] = true
+	}
+	return set, true
+}
+
+func (jd *jsonSchemaDiff) compareBounds(path string, from, to map[string]interface{}, handled map[string]bool) {
+	compareBound := func(k string, lower bool) {
+		fromValue, fromIsNumber := from[k].(float64)
+		toValue, toIsNumber := to[k].(float64)
+		if (!fromIsNumber && from[k] != nil) || (!toIsNumber && to[k] != nil) {
+			// for example the boolean exclusiveMinimum of earlier JSON Schema drafts
+			return
+		}
+		handled[k] = true
+		tighter := (lower && toValue > fromValue) || (!lower && toValue < fromValue)
+		switch {
+		case !fromIsNumber && !toIsNumber:
+		case !fromIsNumber:
+			jd.narrowed(path, "'%s' of %v added", k, toValue)
+		case !toIsNumber:
+			jd.widened(path, "'%s' of %v removed", k, fromValue)
+		case fromValue == toValue:
+		case tighter:
+			jd.narrowed(path, "'%s' changed from %v to %v", k, fromValue, toValue)
+		default:
+			jd.widened(path, "'%s' changed from %v to %v", k, fromValue, toValue)
+		}
+	}
+	for _, k := range jsonSchemaLowerBounds {
+		compareBound(k, true)
+	}
+	for _, k := range jsonSchemaUpperBounds {
+		compareBound(k, false)
+	}
+	fromUnique, fromOK := from["uniqueItems"].(bool)
+	toUnique, toOK := to["uniqueItems"].(bool)
+	if (fromOK || from["uniqueItems"] == nil) && (toOK || to["uniqueItems"] == nil) {
+		handled["uniqueItems"] = true
+		switch {
+		case !fromUnique && toUnique:
+			jd.narrowed(path, "items must now be unique")
+		case fromUnique && !toUnique:
+			jd.widened(path, "items no longer need to be unique")
+		}
+	}
+}
+
+func (jd *jsonSchemaDiff) compareRequired(path string, from, to map[string]interface{}, handled map[string]bool) {
+	fromRequired, fromOK := jsonSchemaStringSet(from["required"])
+	toRequired, toOK := jsonSchemaStringSet(to["required"])
+	if !fromOK || !toOK {
+		return
+	}
+	handled["required"] = true
+	for _, k := range setKeys(toRequired, fromRequired) {
+		jd.narrowed(schemaPath(path, k), "property is now required")
+	}
+	for _, k := range setKeys(fromRequired, toRequired) {
+		jd.widened(schemaPath(path, k), "property is no longer required")
+	}
+}
+
+func jsonSchemaStringSet(v interface{}) (map[string]bool, bool) {
+	set := map[string]bool{}
+	if v == nil {
+		return set, true
+	}
+	a, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	for _, e := range a {
+		s, ok := e.(string)
+		if !ok {
+			return nil, false
+		}
+		set[s] = true
+	}
+	return set, true
+}
+
+func (jd *jsonSchemaDiff) compareProperties(path string, from, to map[string]interface{}, handled map[string]bool, depth int) {
+	fromProps, fromOK := jsonSchemaProperties(from)
+	toProps, toOK := jsonSchemaProperties(to)
+	if !fromOK || !toOK || from["patternProperties"] != nil || to["patternProperties"] != nil {
+		return
+	}
+	handled["properties"] = true
+	handled["additionalProperties"] = true
+	if len(fromProps) == 0 && len(toProps) == 0 && from["additionalProperties"] == nil && to["additionalProperties"] == nil {
+		return
+	}
+	fromAdditional, toAdditional := from["additionalProperties"], to["additionalProperties"]
+	if fromAdditional == nil {
+		fromAdditional = true
+	}
+	if toAdditional == nil {
+		toAdditional = true
+	}
+	// A property that is only declared on one side is compared against the additional properties of the other
+	for _, k := range sortedKeys(fromProps, toProps) {
+		fromProp, ok := fromProps[k]
+		if !ok {
+			fromProp = fromAdditional
+		}
+		toProp, ok := toProps[k]
+		if !ok {
+			toProp = toAdditional
+		}
+		jd.compare(schemaPath(path, k), fromProp, toProp, depth+1)
+	}
+	jd.compare(schemaPath(path, "*"), fromAdditional, toAdditional, depth+1)
+}
+
+func jsonSchemaProperties(schema map[string]interface{}) (map[string]interface{}, bool) {
+	switch p := schema["properties"].(type) {
+	case nil:
+		return map[string]interface{}{}, true
+	case map[string]interface{}:
+		return p, true
+	default:
+		return nil, false
+	}
+}
+
+func (jd *jsonSchemaDiff) compareItems(path string, from, to map[string]interface{}, handled map[string]bool, depth int) {
+	fromItems, toItems := from["items"], to["items"]
+	if _, isTuple := fromItems.([]interface{}); isTuple || from["prefixItems"] != nil {
+		return
+	}
+	if _, isTuple := toItems.([]interface{}); isTuple || to["prefixItems"] != nil {
+		return
+	}
+	handled["items"] = true
+	if fromItems == nil && toItems == nil {
+		return
+	}
+	if fromItems == nil {
+		fromItems = true
+	}
+	if toItems == nil {
+		toItems = true
+	}
+	jd.compare(schemaPath(path, "[]"), fromItems, toItems, depth+1)
+}
@@ -0,0 +1,339 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func testDiffJSONSchemas(t *testing.T, from, to string) *core.DatatypeDiff {
+	diff, err := diffDatatypes(context.Background(), &core.Datatype{
+		Validator: core.ValidatorTypeJSON,
+		Name:      "customer",
+		Version:   "1",
+		Value:     fftypes.JSONAnyPtr(from),
+	}, &core.Datatype{
+		Validator: core.ValidatorTypeJSON,
+		Name:      "customer",
+		Version:   "2",
+		Value:     fftypes.JSONAnyPtr(to),
+	})
+	assert.NoError(t, err)
+	return diff
+}
+
+func changeStrings(diff *core.DatatypeDiff) []string {
+	changes := make([]string, len(diff.Changes))
+	for i, c := range diff.Changes {
+		changes[i] = c.String()
+	}
+	return changes
+}
+
+func TestDiffJSONSchemasCompatibility(t *testing.T) {
+	testCases := []struct {
+		name          string
+		from, to      string
+		compatibility core.DatatypeCompatibility
+		changes       []string
+	}{
+		{
+			name:          "identical",
+			from:          `{"type": "object"}`,
+			to:            `{"type": "object"}`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+		{
+			name:          "annotations only",
+			from:          `{"type": "object", "description": "a customer"}`,
+			to:            `{"type": "object", "description": "an important customer", "title": "Customer", "format": "x"}`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+		{
+			name:          "optional property added to closed object",
+			from:          `{"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": false}`,
+			to:            `{"type": "object", "properties": {"a": {"type": "string"}, "b": {"type": "string"}}, "additionalProperties": false}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$.b: values are now accepted (backward)"},
+		},
+		{
+			name:          "property removed from closed object",
+			from:          `{"properties": {"a": {"type": "string"}, "b": {"type": "string"}}, "additionalProperties": false}`,
+			to:            `{"properties": {"a": {"type": "string"}}, "additionalProperties": false}`,
+			compatibility: core.DatatypeCompatibilityForward,
+			changes:       []string{"$.b: values are no longer accepted (forward)"},
+		},
+		{
+			name:          "property required",
+			from:          `{"type": "object", "properties": {"a": {"type": "string"}}}`,
+			to:            `{"type": "object", "properties": {"a": {"type": "string"}}, "required": ["a"]}`,
+			compatibility: core.DatatypeCompatibilityForward,
+			changes:       []string{"$.a: property is now required (forward)"},
+		},
+		{
+			name:          "property no longer required",
+			from:          `{"required": ["a"]}`,
+			to:            `{}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$.a: property is no longer required (backward)"},
+		},
+		{
+			name:          "integer widened to number",
+			from:          `{"properties": {"a": {"type": "integer"}}}`,
+			to:            `{"properties": {"a": {"type": "number"}}}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$.a: types added: number (backward)"},
+		},
+		{
+			name:          "type changed",
+			from:          `{"properties": {"a": {"type": "string"}}}`,
+			to:            `{"properties": {"a": {"type": "boolean"}}}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$.a: types added: boolean (backward)",
+				"$.a: types removed: string (forward)",
+			},
+		},
+		{
+			name:          "type restricted",
+			from:          `{}`,
+			to:            `{"type": ["object", "null"]}`,
+			compatibility: core.DatatypeCompatibilityForward,
+			changes:       []string{"$: types restricted to null, object (forward)"},
+		},
+		{
+			name:          "type no longer restricted",
+			from:          `{"type": "object"}`,
+			to:            `{}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$: types no longer restricted (backward)"},
+		},
+		{
+			name:          "enum values added",
+			from:          `{"enum": ["red", "green"]}`,
+			to:            `{"enum": ["red", "green", "blue"]}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$: allowed values added: \"blue\" (backward)"},
+		},
+		{
+			name:          "const replaced by enum",
+			from:          `{"const": "red"}`,
+			to:            `{"enum": ["green"]}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$: allowed values added: \"green\" (backward)",
+				"$: allowed values removed: \"red\" (forward)",
+			},
+		},
+		{
+			name:          "bounds tightened and loosened",
+			from:          `{"properties": {"a": {"minLength": 1, "maxLength": 10}, "b": {"minimum": 5}}}`,
+			to:            `{"properties": {"a": {"minLength": 2, "maxLength": 20}, "b": {"maximum": 10}}}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$.a: 'minLength' changed from 1 to 2 (forward)",
+				"$.a: 'maxLength' changed from 10 to 20 (backward)",
+				"$.b: 'minimum' of 5 removed (backward)",
+				"$.b: 'maximum' of 10 added (forward)",
+			},
+		},
+		{
+			name:          "bounds loosened",
+			from:          `{"minItems": 2, "maxItems": 3}`,
+			to:            `{"minItems": 1, "maxItems": 3}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$: 'minItems' changed from 2 to 1 (backward)"},
+		},
+		{
+			name:          "unique items",
+			from:          `{"properties": {"a": {"uniqueItems": true}, "b": {}}}`,
+			to:            `{"properties": {"a": {"uniqueItems": false}, "b": {"uniqueItems": true}}}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$.a: items no longer need to be unique (backward)",
+				"$.b: items must now be unique (forward)",
+			},
+		},
+		{
+			name:          "array items changed",
+			from:          `{"type": "array", "items": {"type": "object", "properties": {"id": {"type": "integer"}}}}`,
+			to:            `{"type": "array", "items": {"type": "object", "properties": {"id": {"type": "number"}}}}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$[].id: types added: number (backward)"},
+		},
+		{
+			name:          "array items restricted",
+			from:          `{"type": "array"}`,
+			to:            `{"type": "array", "items": false}`,
+			compatibility: core.DatatypeCompatibilityForward,
+			changes:       []string{"$[]: values are no longer accepted (forward)"},
+		},
+		{
+			name:          "array items no longer restricted",
+			from:          `{"type": "array", "items": {"type": "string"}}`,
+			to:            `{"type": "array"}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$[]: types no longer restricted (backward)"},
+		},
+		{
+			name:          "additional properties opened",
+			from:          `{"properties": {"a": {}}, "additionalProperties": false}`,
+			to:            `{"properties": {"a": {}}}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$.*: values are now accepted (backward)"},
+		},
+		{
+			name:          "local references resolved",
+			from:          `{"$defs": {"id": {"type": "string"}}, "properties": {"a": {"$ref": "#/$defs/id"}}}`,
+			to:            `{"definitions": {"ids": [{"type": "string"}]}, "properties": {"a": {"$ref": "#/definitions/ids/0"}}}`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+		{
+			name:          "references with other keywords are compared as is",
+			from:          `{"$defs": {"id": {"type": "string"}}, "properties": {"a": {"$ref": "#/$defs/id", "minLength": 1}}}`,
+			to:            `{"$defs": {"id": {"type": "string"}}, "properties": {"a": {"$ref": "#/$defs/ID", "minLength": 1}}}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes:       []string{"$.a: '$ref' changed (none)"},
+		},
+		{
+			name:          "unresolvable references are compared as is",
+			from:          `{"properties": {"a": {"$ref": "#/$defs/missing"}, "b": {"$ref": "#/x/5"}, "c": {"$ref": "#/x/0/y"}, "d": {"$ref": "#/x/z"}, "e": {"$ref": "#/y"}}, "x": [1]}`,
+			to:            `{"properties": {"a": {"$ref": "#/$defs/other"}, "b": {"$ref": "#/x/6"}, "c": {"$ref": "#/x/0/z"}, "d": {"$ref": "#/x/y"}, "e": {"$ref": "#/z"}}, "x": [1]}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$.a: '$ref' changed (none)",
+				"$.b: '$ref' changed (none)",
+				"$.c: '$ref' changed (none)",
+				"$.d: '$ref' changed (none)",
+				"$.e: '$ref' changed (none)",
+			},
+		},
+		{
+			name:          "cyclic references",
+			from:          `{"title": "v1", "$defs": {"a": {"$ref": "#/$defs/a"}}, "properties": {"x": {"$ref": "#/$defs/a"}}}`,
+			to:            `{"title": "v2", "$defs": {"a": {"$ref": "#/$defs/a"}}, "properties": {"x": {"$ref": "#/$defs/a"}}}`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+		{
+			name:          "items changed to tuple",
+			from:          `{"items": {}}`,
+			to:            `{"items": [{}]}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes:       []string{"$: 'items' changed (none)"},
+		},
+		{
+			name:          "other keywords changed",
+			from:          `{"type": "string", "pattern": "^a"}`,
+			to:            `{"type": "string", "pattern": "^b"}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes:       []string{"$: 'pattern' changed (none)"},
+		},
+		{
+			name:          "keywords that cannot be analyzed",
+			from:          `{"type": 1, "enum": "a", "exclusiveMinimum": true, "required": "a", "properties": [], "items": [{}], "uniqueItems": 1}`,
+			to:            `{"type": [1], "enum": ["a"], "const": "a", "exclusiveMinimum": false, "required": [1], "properties": {}, "prefixItems": [{}], "uniqueItems": 0}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$: 'const' changed (none)",
+				"$: 'enum' changed (none)",
+				"$: 'exclusiveMinimum' changed (none)",
+				"$: 'items' changed (none)",
+				"$: 'prefixItems' changed (none)",
+				"$: 'properties' changed (none)",
+				"$: 'required' changed (none)",
+				"$: 'type' changed (none)",
+				"$: 'uniqueItems' changed (none)",
+			},
+		},
+		{
+			name:          "pattern properties and tuples cannot be analyzed",
+			from:          `{"patternProperties": {"^a": {}}, "properties": {}, "items": [{}]}`,
+			to:            `{"patternProperties": {"^a": {}}, "properties": {"a": {}}, "items": [{"type": "string"}]}`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$: 'items' changed (none)",
+				"$: 'properties' changed (none)",
+			},
+		},
+		{
+			name:          "non schemas",
+			from:          `{"properties": {"a": 1, "b": 1}}`,
+			to:            `{"properties": {"a": 2, "b": {}}}`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$.b: values are now accepted (backward)"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff := testDiffJSONSchemas(t, tc.from, tc.to)
+			assert.Equal(t, tc.changes, changeStrings(diff))
+			assert.Equal(t, tc.compatibility, diff.Compatibility)
+		})
+	}
+}
+
+func TestDiffJSONSchemasMaxDepth(t *testing.T) {
+	nested := func(leaf string) string {
+		return strings.Repeat(`{"properties": {"a": `, 40) + leaf + strings.Repeat("}}", 40)
+	}
+	diff := testDiffJSONSchemas(t, nested(`{"type": "string"}`), nested(`{"type": "number"}`))
+	assert.Len(t, diff.Changes, 1)
+	assert.Equal(t, "schema changed", diff.Changes[0].Description)
+	assert.Equal(t, core.DatatypeCompatibilityNone, diff.Compatibility)
+
+	// Beyond the maximum depth even annotation changes are reported, but identical schemas are not
+	diff = testDiffJSONSchemas(t, nested(`{"type": "string"}`), nested(`{"type": "string", "title": "a"}`))
+	assert.Len(t, diff.Changes, 1)
+	diff = testDiffJSONSchemas(t, `{"title": "a", "properties": {"b": `+nested(`{}`)+`}}`, `{"properties": {"b": `+nested(`{}`)+`}}`)
+	assert.Empty(t, diff.Changes)
+}
+
+func TestDiffJSONSchemasBadFrom(t *testing.T) {
+	_, err := diffDatatypes(context.Background(), &core.Datatype{
+		Name:    "customer",
+		Version: "1",
+		Value:   fftypes.JSONAnyPtr(`!json`),
+	}, &core.Datatype{
+		Name:    "customer",
+		Version: "2",
+		Value:   fftypes.JSONAnyPtr(`{}`),
+	})
+	assert.Regexp(t, "FF10196.*customer/1", err)
+}
+
+func TestDiffJSONSchemasBadTo(t *testing.T) {
+	_, err := diffDatatypes(context.Background(), &core.Datatype{
+		Name:    "customer",
+		Version: "1",
+		Value:   fftypes.JSONAnyPtr(`{}`),
+	}, &core.Datatype{
+		Name:    "customer",
+		Version: "2",
+		Value:   fftypes.JSONAnyPtr(`!json`),
+	})
+	assert.Regexp(t, "FF10196.*customer/2", err)
+}
@@ -73,17 +73,9 @@ func (jv *jsonValidator) Validate(ctx context.Context, data *core.Data) error {
 }
 
 func (jv *jsonValidator) ValidateValue(ctx context.Context, value *fftypes.JSONAny, expectedHash *fftypes.Bytes32) error {
-	if value == nil {
-		return i18n.NewError(ctx, coremsgs.MsgDataValueIsNull)
+	if err := checkValueHash(ctx, value, expectedHash); err != nil {
+		return err
 	}
-
-	if expectedHash != nil {
-		hash := value.Hash()
-		if *hash != *expectedHash {
-			return i18n.NewError(ctx, coremsgs.MsgDataInvalidHash, hash, expectedHash)
-		}
-	}
-
 	return jv.validateJSONString(ctx, value.String())
 }
 
@@ -0,0 +1,134 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/docker/go-units"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// offloadLargeValue moves a JSON value that is over the configured threshold into the blob store,
+// leaving a reference in its place. The data must already be sealed, as the hash covers the value.
+func (dm *dataManager) offloadLargeValue(ctx context.Context, d *core.Data) error {
+	if dm.largeValueThreshold <= 0 || dm.exchange == nil ||
+		d.Validator == core.ValidatorTypeSystemDefinition || d.Value.Length() <= dm.largeValueThreshold {
+		return nil
+	}
+
+	// The value is stored under its own ID, so it does not collide with any blob attached to the data
+	_, size, payloadRef, err := dm.uploadVerifyBlob(ctx, fftypes.NewUUID(), strings.NewReader(d.Value.String()))
+	if err != nil {
+		return err
+	}
+	log.L(ctx).Infof("Stored large value for data %s in blob store (%s)", d.ID, units.HumanSizeWithPrecision(float64(size), 2))
+
+	d.ValueSize = size
+	d.ValueRef = &core.DataValueRef{
+		Size:       size,
+		PayloadRef: payloadRef,
+	}
+	d.Value = nil
+	return nil
+}
+
+// OffloadLargeValues returns the data with any values over the configured threshold moved into the
+// blob store, for data received from other members before it is persisted.
+// Copies are returned for the data that is offloaded, so the received data is unchanged if it needs
+// to be processed again. If an upload fails, any values already uploaded are deleted again.
+func (dm *dataManager) OffloadLargeValues(ctx context.Context, data core.DataArray) (core.DataArray, error) {
+	offloaded := make(core.DataArray, len(data))
+	for i, d := range data {
+		dCopy := *d
+		if err := dm.offloadLargeValue(ctx, &dCopy); err != nil {
+			dm.DeleteLargeValues(ctx, offloaded[:i])
+			return nil, err
+		}
+		offloaded[i] = d
+		if dCopy.ValueRef != nil {
+			offloaded[i] = &dCopy
+		}
+	}
+	return offloaded, nil
+}
+
+// DeleteLargeValues removes the values held in the blob store for data that could not be written,
+// so they are not left orphaned. Failures are only logged, as the caller is already handling an error.
+func (dm *dataManager) DeleteLargeValues(ctx context.Context, data core.DataArray) {
+	if dm.exchange == nil {
+		return
+	}
+	for _, d := range data {
+		if d != nil && d.ValueRef != nil {
+			if err := dm.exchange.DeleteBlob(ctx, d.ValueRef.PayloadRef); err != nil {
+				log.L(ctx).Warnf("Failed to delete large value for data %s from blob store: %s", d.ID, err)
+			}
+		}
+	}
+}
+
+// DownloadLargeValue streams a JSON value that is held in the blob store
+func (dm *dataManager) DownloadLargeValue(ctx context.Context, d *core.Data) (io.ReadCloser, error) {
+	if dm.exchange == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgActionNotSupported)
+	}
+	return dm.exchange.DownloadBlob(ctx, d.ValueRef.PayloadRef)
+}
+
+func (dm *dataManager) readLargeValue(ctx context.Context, d *core.Data) (*fftypes.JSONAny, error) {
+	reader, err := dm.DownloadLargeValue(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	b, err := io.ReadAll(io.LimitReader(reader, d.ValueRef.Size+1))
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgBlobStreamingFailed)
+	}
+	if int64(len(b)) > d.ValueRef.Size {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDataLargeValueTooLarge, d.ID, d.ValueRef.Size)
+	}
+	return fftypes.JSONAnyPtrBytes(b), nil
+}
+
+// LoadLargeValues returns the data with any values held in the blob store read back in, for when
+// the full value is required (such as sending it to other members).
+// Copies are returned for the data that is loaded, so the full values are not retained in the cache.
+func (dm *dataManager) LoadLargeValues(ctx context.Context, data core.DataArray) (core.DataArray, error) {
+	loaded := make(core.DataArray, len(data))
+	for i, d := range data {
+		loaded[i] = d
+		if d.ValueRef != nil {
+			value, err := dm.readLargeValue(ctx, d)
+			if err != nil {
+				return nil, err
+			}
+			dCopy := *d
+			dCopy.Value = value
+			dCopy.ValueRef = nil
+			loaded[i] = &dCopy
+		}
+	}
+	return loaded, nil
+}
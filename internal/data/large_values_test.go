@@ -0,0 +1,471 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testLargeValue = `{"some":"value that is over the threshold"}`
+
+func testLargeValueData() *core.Data {
+	value := fftypes.JSONAnyPtr(testLargeValue)
+	return &core.Data{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Validator: core.ValidatorTypeJSON,
+		Hash:      value.Hash(),
+		ValueSize: int64(len(testLargeValue)),
+		ValueRef: &core.DataValueRef{
+			Size:       int64(len(testLargeValue)),
+			PayloadRef: "ns1/value1",
+		},
+	}
+}
+
+func TestValidateInputDataLargeValue(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.largeValueThreshold = 10
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	dxUpload := mdx.On("UploadBlob", ctx, "ns1", mock.Anything, mock.Anything)
+	dxUpload.RunFn = func(a mock.Arguments) {
+		b, err := io.ReadAll(a[3].(io.Reader))
+		assert.NoError(t, err)
+		assert.Equal(t, testLargeValue, string(b))
+		var hash fftypes.Bytes32 = sha256.Sum256(b)
+		dxUpload.ReturnArguments = mock.Arguments{"ns1/value1", &hash, int64(len(b)), nil}
+	}
+
+	data, err := dm.validateInputData(ctx, &core.DataRefOrValue{
+		Value: fftypes.JSONAnyPtr(testLargeValue),
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, data.Value)
+	assert.Equal(t, fftypes.JSONAnyPtr(testLargeValue).Hash(), data.Hash)
+	assert.Equal(t, int64(len(testLargeValue)), data.ValueSize)
+	assert.Equal(t, int64(len(testLargeValue)), data.ValueRef.Size)
+	assert.Equal(t, "ns1/value1", data.ValueRef.PayloadRef)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestValidateInputDataLargeValueUploadFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.largeValueThreshold = 10
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("UploadBlob", ctx, "ns1", mock.Anything, mock.Anything).Return("", nil, int64(-1), fmt.Errorf("pop"))
+
+	_, err := dm.validateInputData(ctx, &core.DataRefOrValue{
+		Value: fftypes.JSONAnyPtr(testLargeValue),
+	})
+	assert.Regexp(t, "pop", err)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestValidateInputDataSystemDefinitionNotOffloaded(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.largeValueThreshold = 10
+
+	data, err := dm.validateInputData(ctx, &core.DataRefOrValue{
+		Validator: core.ValidatorTypeSystemDefinition,
+		Value:     fftypes.JSONAnyPtr(testLargeValue),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, testLargeValue, data.Value.String())
+	assert.Nil(t, data.ValueRef)
+}
+
+func TestLoadLargeValues(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/value1").Return(io.NopCloser(strings.NewReader(testLargeValue)), nil)
+
+	inline := &core.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"small"`)}
+	offloaded := testLargeValueData()
+	loaded, err := dm.LoadLargeValues(ctx, core.DataArray{inline, offloaded})
+	assert.NoError(t, err)
+	assert.Len(t, loaded, 2)
+	assert.Same(t, inline, loaded[0])
+	assert.Equal(t, offloaded.ID, loaded[1].ID)
+	assert.Equal(t, testLargeValue, loaded[1].Value.String())
+	assert.Nil(t, loaded[1].ValueRef)
+
+	// The original is not modified, as it might be in the cache
+	assert.Nil(t, offloaded.Value)
+	assert.NotNil(t, offloaded.ValueRef)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestLoadLargeValuesDownloadFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/value1").Return(nil, fmt.Errorf("pop"))
+
+	_, err := dm.LoadLargeValues(ctx, core.DataArray{testLargeValueData()})
+	assert.Regexp(t, "pop", err)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestLoadLargeValuesReadFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/value1").Return(io.NopCloser(iotest.ErrReader(fmt.Errorf("pop"))), nil)
+
+	_, err := dm.LoadLargeValues(ctx, core.DataArray{testLargeValueData()})
+	assert.Regexp(t, "FF10217.*pop", err)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestLoadLargeValuesTooLarge(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/value1").Return(io.NopCloser(strings.NewReader(testLargeValue+"    ")), nil)
+
+	_, err := dm.LoadLargeValues(ctx, core.DataArray{testLargeValueData()})
+	assert.Regexp(t, "FF10511", err)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestDownloadLargeValueNoDX(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.exchange = nil
+
+	_, err := dm.DownloadLargeValue(ctx, testLargeValueData())
+	assert.Regexp(t, "FF10414", err)
+}
+
+func TestValidateAllLargeValue(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", ctx, "ns1", "customer", "0.0.1").Return(&core.Datatype{
+		ID:        fftypes.NewUUID(),
+		Validator: core.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtr(`{"type":"object","properties":{"some":{"type":"string"}}}`),
+	}, nil)
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/value1").Return(io.NopCloser(strings.NewReader(testLargeValue)), nil)
+
+	data := testLargeValueData()
+	data.Datatype = &core.DatatypeRef{Name: "customer", Version: "0.0.1"}
+	valid, err := dm.ValidateAll(ctx, core.DataArray{data})
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	assert.Nil(t, data.Value)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func TestValidateAllLargeValueDownloadFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", ctx, "ns1", "customer", "0.0.1").Return(&core.Datatype{
+		ID:        fftypes.NewUUID(),
+		Validator: core.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtr(`{"type":"object"}`),
+	}, nil)
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/value1").Return(nil, fmt.Errorf("pop"))
+
+	data := testLargeValueData()
+	data.Datatype = &core.DatatypeRef{Name: "customer", Version: "0.0.1"}
+	_, err := dm.ValidateAll(ctx, core.DataArray{data})
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func testLargeValueBatch(dataID *fftypes.UUID) *core.BatchPersisted {
+	batchID := fftypes.NewUUID()
+	return &core.BatchPersisted{
+		BatchHeader: core.BatchHeader{
+			Type:      core.BatchTypePrivate,
+			ID:        batchID,
+			Namespace: "ns1",
+		},
+		Manifest: fftypes.JSONAnyPtr(fmt.Sprintf(`{"id":"%s","messages":[],"data":[{"id":"%s"}]}`, batchID, dataID)),
+	}
+}
+
+func TestHydrateBatchLargeValue(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	data := testLargeValueData()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, true).Return(data, nil)
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/value1").Return(io.NopCloser(strings.NewReader(testLargeValue)), nil)
+
+	batch, err := dm.HydrateBatch(ctx, testLargeValueBatch(data.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, testLargeValue, batch.Payload.Data[0].Value.String())
+	assert.Nil(t, batch.Payload.Data[0].ValueRef)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func TestHydrateBatchLargeValueFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	data := testLargeValueData()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, true).Return(data, nil)
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBlob", ctx, "ns1/value1").Return(nil, fmt.Errorf("pop"))
+
+	_, err := dm.HydrateBatch(ctx, testLargeValueBatch(data.ID))
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func TestDeleteDataLargeValue(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	data := testLargeValueData()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, false).Return(data, nil)
+	mdi.On("GetMessagesForData", ctx, "ns1", data.ID, mock.Anything).Return([]*core.Message{}, nil, nil)
+	mdi.On("DeleteData", ctx, "ns1", data.ID).Return(nil)
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DeleteBlob", ctx, "ns1/value1").Return(nil)
+
+	err := dm.DeleteData(ctx, data.ID.String())
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func TestDeleteDataLargeValueFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	data := testLargeValueData()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDataByID", ctx, "ns1", data.ID, false).Return(data, nil)
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DeleteBlob", ctx, "ns1/value1").Return(fmt.Errorf("pop"))
+
+	err := dm.DeleteData(ctx, data.ID.String())
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func mockLargeValueUpload(t *testing.T, mdx *dataexchangemocks.Plugin, payloadRef string) *mock.Call {
+	dxUpload := mdx.On("UploadBlob", mock.Anything, "ns1", mock.Anything, mock.Anything).Once()
+	dxUpload.RunFn = func(a mock.Arguments) {
+		b, err := io.ReadAll(a[3].(io.Reader))
+		assert.NoError(t, err)
+		var hash fftypes.Bytes32 = sha256.Sum256(b)
+		dxUpload.ReturnArguments = mock.Arguments{payloadRef, &hash, int64(len(b)), nil}
+	}
+	return dxUpload
+}
+
+func TestOffloadLargeValues(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.largeValueThreshold = 10
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mockLargeValueUpload(t, mdx, "ns1/value1")
+
+	small := &core.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"small"`)}
+	large := &core.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(testLargeValue)}
+	definition := &core.Data{ID: fftypes.NewUUID(), Validator: core.ValidatorTypeSystemDefinition, Value: fftypes.JSONAnyPtr(testLargeValue)}
+	offloaded, err := dm.OffloadLargeValues(ctx, core.DataArray{small, large, definition})
+	assert.NoError(t, err)
+	assert.Len(t, offloaded, 3)
+	assert.Same(t, small, offloaded[0])
+	assert.Same(t, definition, offloaded[2])
+	assert.Nil(t, offloaded[1].Value)
+	assert.Equal(t, "ns1/value1", offloaded[1].ValueRef.PayloadRef)
+	// The received data is unchanged
+	assert.Equal(t, testLargeValue, large.Value.String())
+	assert.Nil(t, large.ValueRef)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestOffloadLargeValuesUploadFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.largeValueThreshold = 10
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mockLargeValueUpload(t, mdx, "ns1/value1")
+	mdx.On("UploadBlob", ctx, "ns1", mock.Anything, mock.Anything).Return("", nil, int64(-1), fmt.Errorf("pop")).Once()
+	mdx.On("DeleteBlob", ctx, "ns1/value1").Return(nil)
+
+	_, err := dm.OffloadLargeValues(ctx, core.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(testLargeValue)},
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(testLargeValue)},
+	})
+	assert.Regexp(t, "pop", err)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestDeleteLargeValues(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DeleteBlob", ctx, "ns1/value1").Return(fmt.Errorf("pop"))
+
+	// Failures are only logged
+	dm.DeleteLargeValues(ctx, core.DataArray{
+		nil,
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"small"`)},
+		testLargeValueData(),
+	})
+
+	mdx.AssertExpectations(t)
+}
+
+func TestDeleteLargeValuesNoExchange(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.exchange = nil
+
+	dm.DeleteLargeValues(ctx, core.DataArray{testLargeValueData()})
+}
+
+func TestUploadJSONLargeValueWriteFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.largeValueThreshold = 10
+	dm.messageWriter.close()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mockLargeValueUpload(t, mdx, "ns1/value1")
+	mdx.On("DeleteBlob", ctx, "ns1/value1").Return(nil)
+
+	_, err := dm.UploadJSON(ctx, &core.DataRefOrValue{
+		Value: fftypes.JSONAnyPtr(testLargeValue),
+	})
+	assert.Regexp(t, "FF00154", err)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestResolveInlineDataLargeValueFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.largeValueThreshold = 10
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mockLargeValueUpload(t, mdx, "ns1/value1")
+	mdx.On("DeleteBlob", ctx, "ns1/value1").Return(nil)
+
+	_, _, newMsg := testNewMessage()
+	newMsg.Message.InlineData = core.InlineData{
+		{Value: fftypes.JSONAnyPtr(testLargeValue)},
+		{ /* missing */ },
+	}
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.Regexp(t, "FF10205", err)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestWriteNewMessageLargeValueWriteFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.messageWriter.close()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DeleteBlob", ctx, "ns1/value1").Return(nil)
+
+	err := dm.WriteNewMessage(ctx, &NewMessage{
+		Message: &core.MessageInOut{},
+		NewData: core.DataArray{testLargeValueData()},
+	})
+	assert.Regexp(t, "FF00154", err)
+
+	mdx.AssertExpectations(t)
+}
+
+func TestWriteNewMessagesLargeValueWriteFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	nm := testBulkNewMessage("")
+	nm.NewData = core.DataArray{testLargeValueData()}
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("RunAsGroup", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DeleteBlob", ctx, "ns1/value1").Return(nil)
+
+	errs := dm.WriteNewMessages(ctx, []*NewMessage{nm})
+	assert.Regexp(t, "pop", errs[0])
+	msg, _ := dm.PeekMessageCache(ctx, nm.Message.Header.ID)
+	assert.Nil(t, msg)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
@@ -119,6 +119,65 @@ func (mw *messageWriter) WriteNewMessage(ctx context.Context, newMsg *NewMessage
 	return err
 }
 
+// WriteNewMessages writes a set of messages in-line on the context passed in, in a single database transaction,
+// returning an error for each message. If the transaction fails, any idempotency duplicates are reported
+// against the individual messages, and the remaining messages are retried one at a time so that each
+// reports its own error.
+func (mw *messageWriter) WriteNewMessages(ctx context.Context, newMsgs []*NewMessage) []error {
+	errs := make([]error, len(newMsgs))
+	pending := make([]int, 0, len(newMsgs))
+	idempotencyKeys := make(map[core.IdempotencyKey]bool)
+	for i, newMsg := range newMsgs {
+		if key := newMsg.Message.IdempotencyKey; key != "" {
+			if idempotencyKeys[key] {
+				errs[i] = i18n.NewError(ctx, coremsgs.MsgBulkMessageDuplicateIdempotencyKey, key)
+				continue
+			}
+			idempotencyKeys[key] = true
+		}
+		pending = append(pending, i)
+	}
+
+	err := mw.writeNewMessagesGroup(ctx, newMsgs, pending)
+	if err != nil {
+		log.L(ctx).Errorf("Failed bulk message insert (pre-idempotency check): %s", err)
+		remaining := make([]int, 0, len(pending))
+		for _, i := range pending {
+			if idempotencyErr := mw.checkIdempotencyDuplicate(ctx, &newMsgs[i].Message.Message); idempotencyErr != nil {
+				errs[i] = idempotencyErr
+			} else {
+				remaining = append(remaining, i)
+			}
+		}
+		if len(pending) == 1 {
+			for _, i := range remaining {
+				errs[i] = err
+			}
+			return errs
+		}
+		log.L(ctx).Infof("Retrying %d messages individually after removing %d idempotency duplicates", len(remaining), len(pending)-len(remaining))
+		for _, i := range remaining {
+			errs[i] = mw.writeNewMessagesGroup(ctx, newMsgs, []int{i})
+		}
+	}
+	return errs
+}
+
+func (mw *messageWriter) writeNewMessagesGroup(ctx context.Context, newMsgs []*NewMessage, indexes []int) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+	msgs := make([]*core.Message, len(indexes))
+	var data core.DataArray
+	for j, i := range indexes {
+		msgs[j] = &newMsgs[i].Message.Message
+		data = append(data, newMsgs[i].NewData...)
+	}
+	return mw.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		return mw.writeMessages(ctx, msgs, data)
+	})
+}
+
 // WriteData writes a piece of data independently of a message
 func (mw *messageWriter) WriteData(ctx context.Context, data *core.Data) error {
 	if mw.conf.workerCount > 0 {
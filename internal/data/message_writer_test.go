@@ -373,3 +373,139 @@ func TestWriteMessageNoWorkersIdempotencyDuplicate(t *testing.T) {
 	mdi.AssertExpectations(t)
 
 }
+
+func testBulkNewMessage(idempotencyKey core.IdempotencyKey) *NewMessage {
+	newData := core.DataArray{
+		&core.Data{Namespace: "ns1", ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()},
+	}
+	return &NewMessage{
+		Message: &core.MessageInOut{
+			Message: core.Message{
+				Header:         core.MessageHeader{Namespace: "ns1", ID: fftypes.NewUUID()},
+				Data:           newData.Refs(),
+				IdempotencyKey: idempotencyKey,
+			},
+		},
+		AllData: newData,
+		NewData: newData,
+	}
+}
+
+func TestWriteNewMessagesOneTransaction(t *testing.T) {
+	mw := newTestMessageWriter(t)
+	customCtx := context.WithValue(context.Background(), "dbtx", "on this context")
+
+	nm1 := testBulkNewMessage("idem1")
+	nm2 := testBulkNewMessage("")
+	nm3 := testBulkNewMessage("idem1")
+
+	mdi := mw.database.(*databasemocks.Plugin)
+	rag := mdi.On("RunAsGroup", customCtx, mock.Anything).Once()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	mdi.On("InsertDataArray", customCtx, append(nm1.NewData, nm2.NewData...)).Return(nil)
+	mdi.On("InsertMessages", customCtx, []*core.Message{&nm1.Message.Message, &nm2.Message.Message}).Return(nil)
+
+	errs := mw.WriteNewMessages(customCtx, []*NewMessage{nm1, nm2, nm3})
+	assert.Len(t, errs, 3)
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Regexp(t, "FF10513.*idem1", errs[2])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestWriteNewMessagesIdempotencyDuplicateResubmit(t *testing.T) {
+	mw := newTestMessageWriter(t)
+	customCtx := context.WithValue(context.Background(), "dbtx", "on this context")
+
+	nm1 := testBulkNewMessage("idem1")
+	nm2 := testBulkNewMessage("idem2")
+
+	mdi := mw.database.(*databasemocks.Plugin)
+	rag := mdi.On("RunAsGroup", customCtx, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	mdi.On("InsertDataArray", customCtx, append(nm1.NewData, nm2.NewData...)).Return(nil)
+	mdi.On("InsertMessages", customCtx, []*core.Message{&nm1.Message.Message, &nm2.Message.Message}).Return(fmt.Errorf("various keys were not unique"))
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		ff, _ := f.Finalize()
+		return strings.Contains(ff.String(), "idem1")
+	})).Return([]*core.Message{}, nil, nil)
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		ff, _ := f.Finalize()
+		return strings.Contains(ff.String(), "idem2")
+	})).Return([]*core.Message{{Header: core.MessageHeader{ID: fftypes.NewUUID()}}}, nil, nil)
+	mdi.On("InsertDataArray", customCtx, nm1.NewData).Return(nil)
+	mdi.On("InsertMessages", customCtx, []*core.Message{&nm1.Message.Message}).Return(nil)
+
+	errs := mw.WriteNewMessages(customCtx, []*NewMessage{nm1, nm2})
+	assert.NoError(t, errs[0])
+	assert.Regexp(t, "FF10430.*idem2", errs[1])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestWriteNewMessagesFail(t *testing.T) {
+	mw := newTestMessageWriter(t)
+	customCtx := context.WithValue(context.Background(), "dbtx", "on this context")
+
+	nm1 := testBulkNewMessage("")
+	nm2 := testBulkNewMessage("")
+
+	mdi := mw.database.(*databasemocks.Plugin)
+	mdi.On("RunAsGroup", customCtx, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	rag := mdi.On("RunAsGroup", customCtx, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	mdi.On("InsertDataArray", customCtx, mock.Anything).Return(nil)
+	mdi.On("InsertMessages", customCtx, []*core.Message{&nm1.Message.Message}).Return(nil)
+	mdi.On("InsertMessages", customCtx, []*core.Message{&nm2.Message.Message}).Return(fmt.Errorf("pop2"))
+
+	// Only the message that fails on its own is rejected
+	errs := mw.WriteNewMessages(customCtx, []*NewMessage{nm1, nm2})
+	assert.NoError(t, errs[0])
+	assert.Regexp(t, "pop2", errs[1])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestWriteNewMessagesSingleFail(t *testing.T) {
+	mw := newTestMessageWriter(t)
+	customCtx := context.WithValue(context.Background(), "dbtx", "on this context")
+
+	nm1 := testBulkNewMessage("")
+
+	mdi := mw.database.(*databasemocks.Plugin)
+	mdi.On("RunAsGroup", customCtx, mock.Anything).Return(fmt.Errorf("pop")).Once()
+
+	errs := mw.WriteNewMessages(customCtx, []*NewMessage{nm1})
+	assert.Regexp(t, "pop", errs[0])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestWriteNewMessagesAllDuplicates(t *testing.T) {
+	mw := newTestMessageWriter(t)
+	customCtx := context.WithValue(context.Background(), "dbtx", "on this context")
+
+	nm1 := testBulkNewMessage("idem1")
+
+	mdi := mw.database.(*databasemocks.Plugin)
+	mdi.On("RunAsGroup", customCtx, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return([]*core.Message{{Header: core.MessageHeader{ID: fftypes.NewUUID()}}}, nil, nil)
+
+	errs := mw.WriteNewMessages(customCtx, []*NewMessage{nm1})
+	assert.Regexp(t, "FF10430.*idem1", errs[0])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestWriteNewMessagesEmpty(t *testing.T) {
+	mw := newTestMessageWriter(t)
+	errs := mw.WriteNewMessages(context.Background(), []*NewMessage{})
+	assert.Empty(t, errs)
+}
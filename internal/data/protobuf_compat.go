@@ -0,0 +1,136 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperledger/firefly/pkg/core"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// protobufDiff compares messages by field number. As the protobuf validator rejects unknown fields, adding
+// a field is backward compatible and removing a field is forward compatible. Renaming a field is
+// incompatible, as values are validated in the JSON mapping that uses the field names.
+type protobufDiff struct {
+	diff    *core.DatatypeDiff
+	visited map[[2]protoreflect.FullName]bool
+}
+
+func diffProtobufSchemas(ctx context.Context, diff *core.DatatypeDiff, from, to *core.Datatype) error {
+	fromMessage, err := compileProtobufSchema(ctx, from, diff.From)
+	if err != nil {
+		return err
+	}
+	toMessage, err := compileProtobufSchema(ctx, to, diff.To)
+	if err != nil {
+		return err
+	}
+	pd := &protobufDiff{diff: diff, visited: map[[2]protoreflect.FullName]bool{}}
+	pd.compareMessages("$", fromMessage, toMessage)
+	return nil
+}
+
+func (pd *protobufDiff) change(path string, compatibility core.DatatypeCompatibility, format string, args ...interface{}) {
+	pd.diff.AddChange(path, compatibility, fmt.Sprintf(format, args...))
+}
+
+func (pd *protobufDiff) compareMessages(path string, from, to protoreflect.MessageDescriptor) {
+	key := [2]protoreflect.FullName{from.FullName(), to.FullName()}
+	if pd.visited[key] {
+		return
+	}
+	pd.visited[key] = true
+
+	fromFields, toFields := from.Fields(), to.Fields()
+	for i := 0; i < fromFields.Len(); i++ {
+		ff := fromFields.Get(i)
+		fieldPath := schemaPath(path, string(ff.Name()))
+		if tf := toFields.ByNumber(ff.Number()); tf != nil {
+			pd.compareFields(fieldPath, ff, tf)
+		} else {
+			pd.change(fieldPath, core.DatatypeCompatibilityForward, "field %d removed", ff.Number())
+		}
+	}
+	for i := 0; i < toFields.Len(); i++ {
+		tf := toFields.Get(i)
+		if fromFields.ByNumber(tf.Number()) != nil {
+			continue
+		}
+		fieldPath := schemaPath(path, string(tf.Name()))
+		if tf.Cardinality() == protoreflect.Required {
+			pd.change(fieldPath, core.DatatypeCompatibilityNone, "required field %d added", tf.Number())
+		} else {
+			pd.change(fieldPath, core.DatatypeCompatibilityBackward, "field %d added", tf.Number())
+		}
+	}
+}
+
+func (pd *protobufDiff) compareFields(path string, from, to protoreflect.FieldDescriptor) {
+	switch {
+	case from.Name() != to.Name():
+		pd.change(path, core.DatatypeCompatibilityNone, "field %d renamed from '%s' to '%s'", from.Number(), from.Name(), to.Name())
+		return
+	case from.Kind() != to.Kind():
+		pd.change(path, core.DatatypeCompatibilityNone, "type changed from %s to %s", from.Kind(), to.Kind())
+		return
+	case from.IsMap() != to.IsMap():
+		pd.change(path, core.DatatypeCompatibilityNone, "changed between map and repeated field")
+		return
+	case from.Cardinality() != to.Cardinality():
+		switch {
+		case from.Cardinality() == protoreflect.Repeated || to.Cardinality() == protoreflect.Repeated:
+			pd.change(path, core.DatatypeCompatibilityNone, "changed from %s to %s", from.Cardinality(), to.Cardinality())
+			return
+		case to.Cardinality() == protoreflect.Required:
+			pd.change(path, core.DatatypeCompatibilityForward, "field is now required")
+		default:
+			pd.change(path, core.DatatypeCompatibilityBackward, "field is no longer required")
+		}
+	}
+	switch {
+	case from.IsMap():
+		if from.MapKey().Kind() != to.MapKey().Kind() {
+			pd.change(path, core.DatatypeCompatibilityNone, "map key type changed from %s to %s", from.MapKey().Kind(), to.MapKey().Kind())
+		}
+		pd.compareFields(schemaPath(path, "*"), from.MapValue(), to.MapValue())
+	case from.Message() != nil:
+		pd.compareMessages(path, from.Message(), to.Message())
+	case from.Enum() != nil:
+		pd.compareEnums(path, from.Enum(), to.Enum())
+	}
+}
+
+func (pd *protobufDiff) compareEnums(path string, from, to protoreflect.EnumDescriptor) {
+	fromValues, toValues := from.Values(), to.Values()
+	for i := 0; i < fromValues.Len(); i++ {
+		fv := fromValues.Get(i)
+		tv := toValues.ByNumber(fv.Number())
+		switch {
+		case tv == nil:
+			pd.change(path, core.DatatypeCompatibilityForward, "enum value %s removed", fv.Name())
+		case tv.Name() != fv.Name():
+			pd.change(path, core.DatatypeCompatibilityNone, "enum value %d renamed from %s to %s", fv.Number(), fv.Name(), tv.Name())
+		}
+	}
+	for i := 0; i < toValues.Len(); i++ {
+		if tv := toValues.Get(i); fromValues.ByNumber(tv.Number()) == nil {
+			pd.change(path, core.DatatypeCompatibilityBackward, "enum value %s added", tv.Name())
+		}
+	}
+}
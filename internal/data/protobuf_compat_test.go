@@ -0,0 +1,159 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func testDiffProtobufSchemas(t *testing.T, syntax, from, to string) *core.DatatypeDiff {
+	fromDT := newTestProtobufDatatype(`syntax = "`+syntax+`"; package acme; `+from, "acme.Customer")
+	toDT := newTestProtobufDatatype(`syntax = "`+syntax+`"; package acme; `+to, "acme.Customer")
+	toDT.Version = "0.0.2"
+	diff, err := diffDatatypes(context.Background(), fromDT, toDT)
+	assert.NoError(t, err)
+	return diff
+}
+
+func TestDiffProtobufSchemasCompatibility(t *testing.T) {
+	testCases := []struct {
+		name          string
+		syntax        string
+		from, to      string
+		compatibility core.DatatypeCompatibility
+		changes       []string
+	}{
+		{
+			name:   "comments only",
+			syntax: "proto3",
+			from:   `message Customer { string name = 1; }`,
+			to: `message Customer { string name = 1; // the name
+			}`,
+			compatibility: core.DatatypeCompatibilityFull,
+			changes:       []string{},
+		},
+		{
+			name:          "field added and removed",
+			syntax:        "proto3",
+			from:          `message Customer { string name = 1; int32 age = 2; }`,
+			to:            `message Customer { string name = 1; string email = 3; }`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$.age: field 2 removed (forward)",
+				"$.email: field 3 added (backward)",
+			},
+		},
+		{
+			name:          "field renamed",
+			syntax:        "proto3",
+			from:          `message Customer { string name = 1; }`,
+			to:            `message Customer { string full_name = 1; }`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes:       []string{"$.name: field 1 renamed from 'name' to 'full_name' (none)"},
+		},
+		{
+			name:          "field type changed",
+			syntax:        "proto3",
+			from:          `message Customer { int32 age = 1; }`,
+			to:            `message Customer { int64 age = 1; }`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes:       []string{"$.age: type changed from int32 to int64 (none)"},
+		},
+		{
+			name:          "changed to map",
+			syntax:        "proto3",
+			from:          `message Entry { string key = 1; string value = 2; } message Customer { repeated Entry tags = 1; }`,
+			to:            `message Customer { map<string, string> tags = 1; }`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes:       []string{"$.tags: changed between map and repeated field (none)"},
+		},
+		{
+			name:          "changed to repeated",
+			syntax:        "proto3",
+			from:          `message Customer { string tag = 1; }`,
+			to:            `message Customer { repeated string tag = 1; }`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes:       []string{"$.tag: changed from optional to repeated (none)"},
+		},
+		{
+			name:          "map changed",
+			syntax:        "proto3",
+			from:          `message Customer { map<string, int32> counts = 1; }`,
+			to:            `message Customer { map<int32, int64> counts = 1; }`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$.counts: map key type changed from string to int32 (none)",
+				"$.counts.*: type changed from int32 to int64 (none)",
+			},
+		},
+		{
+			name:   "nested and recursive messages",
+			syntax: "proto3",
+			from: `message Address { string city = 1; Address next = 2; }
+				message Customer { Address home = 1; Address work = 2; }`,
+			to: `message Address { string city = 1; Address next = 2; string country = 3; }
+				message Customer { Address home = 1; Address work = 2; }`,
+			compatibility: core.DatatypeCompatibilityBackward,
+			changes:       []string{"$.home.country: field 3 added (backward)"},
+		},
+		{
+			name:          "enum values",
+			syntax:        "proto3",
+			from:          `enum Color { RED = 0; GREEN = 1; BLUE = 2; } message Customer { Color color = 1; }`,
+			to:            `enum Color { RED = 0; VERDE = 1; PURPLE = 3; } message Customer { Color color = 1; }`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$.color: enum value 1 renamed from GREEN to VERDE (none)",
+				"$.color: enum value BLUE removed (forward)",
+				"$.color: enum value PURPLE added (backward)",
+			},
+		},
+		{
+			name:          "required fields",
+			syntax:        "proto2",
+			from:          `message Customer { optional string name = 1; required int32 age = 2; }`,
+			to:            `message Customer { required string name = 1; optional int32 age = 2; required string email = 3; }`,
+			compatibility: core.DatatypeCompatibilityNone,
+			changes: []string{
+				"$.name: field is now required (forward)",
+				"$.age: field is no longer required (backward)",
+				"$.email: required field 3 added (none)",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diff := testDiffProtobufSchemas(t, tc.syntax, tc.from, tc.to)
+			assert.Equal(t, tc.changes, changeStrings(diff))
+			assert.Equal(t, tc.compatibility, diff.Compatibility)
+		})
+	}
+}
+
+func TestDiffProtobufSchemasBadSchemas(t *testing.T) {
+	good := newTestProtobufDatatype(testProtobufSchema, "acme.Customer")
+	bad := newTestProtobufDatatype(`!protobuf`, "acme.Customer")
+	bad.Version = "0.0.2"
+	_, err := diffDatatypes(context.Background(), bad, good)
+	assert.Regexp(t, "FF10196.*customer/0.0.2", err)
+	_, err = diffDatatypes(context.Background(), good, bad)
+	assert.Regexp(t, "FF10196.*customer/0.0.2", err)
+}
@@ -0,0 +1,151 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const protobufSchemaFile = "schema.proto"
+
+// protobufValidator validates values in the canonical protobuf JSON mapping, and blobs in the
+// protobuf binary wire format, against a message in a self contained .proto schema.
+// The well known types (google/protobuf/*.proto) can be imported by the schema.
+type protobufValidator struct {
+	id       *fftypes.UUID
+	size     int64
+	ns       string
+	datatype *core.DatatypeRef
+	message  protoreflect.MessageDescriptor
+}
+
+func newProtobufValidator(ctx context.Context, ns string, datatype *core.Datatype) (*protobufValidator, error) {
+	pv := &protobufValidator{
+		id: datatype.ID,
+		ns: ns,
+		datatype: &core.DatatypeRef{
+			Name:    datatype.Name,
+			Version: datatype.Version,
+		},
+	}
+
+	message, err := compileProtobufSchema(ctx, datatype, pv.datatype)
+	if err != nil {
+		return nil, err
+	}
+	pv.message = message
+	pv.size = int64(len(datatype.Value.String()))
+
+	log.L(ctx).Debugf("Found Protobuf schema validator for protobuf:%s:%s: %v", pv.ns, datatype, pv.id)
+	return pv, nil
+}
+
+// compileProtobufSchema compiles the .proto source of a datatype, and returns the descriptor of its message
+func compileProtobufSchema(ctx context.Context, datatype *core.Datatype, ref *core.DatatypeRef) (protoreflect.MessageDescriptor, error) {
+	var schema core.ProtobufSchema
+	if err := json.Unmarshal(datatype.Value.Bytes(), &schema); err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgSchemaLoadFailed, ref)
+	}
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{
+				protobufSchemaFile: schema.Schema,
+			}),
+		}),
+	}
+	files, err := compiler.Compile(ctx, protobufSchemaFile)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgSchemaLoadFailed, ref)
+	}
+	message, ok := files[0].FindDescriptorByName(protoreflect.FullName(schema.Message)).(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, i18n.WrapError(ctx, fmt.Errorf("message '%s' not found", schema.Message), coremsgs.MsgSchemaLoadFailed, ref)
+	}
+	return message, nil
+}
+
+func (pv *protobufValidator) Validate(ctx context.Context, data *core.Data) error {
+	return pv.ValidateValue(ctx, data.Value, data.Hash)
+}
+
+func (pv *protobufValidator) ValidateValue(ctx context.Context, value *fftypes.JSONAny, expectedHash *fftypes.Bytes32) error {
+	if err := checkValueHash(ctx, value, expectedHash); err != nil {
+		return err
+	}
+	msg := dynamicpb.NewMessage(pv.message)
+	return pv.validationResult(ctx, protojson.Unmarshal(value.Bytes(), msg))
+}
+
+func (pv *protobufValidator) ValidateBlob(ctx context.Context, content []byte) error {
+	msg := dynamicpb.NewMessage(pv.message)
+	err := proto.Unmarshal(content, msg)
+	if err == nil {
+		err = checkNoUnknownFields(msg)
+	}
+	return pv.validationResult(ctx, err)
+}
+
+func (pv *protobufValidator) validationResult(ctx context.Context, err error) error {
+	if err != nil {
+		log.L(ctx).Warnf("Protobuf schema %s [%v] validation failed: %s", pv.datatype, pv.id, err)
+		return i18n.NewError(ctx, coremsgs.MsgDataInvalidPerSchema, "Protobuf", pv.datatype, err)
+	}
+	return nil
+}
+
+func (pv *protobufValidator) Size() int64 {
+	return pv.size
+}
+
+// checkNoUnknownFields rejects binary messages containing fields that are not in the schema, which
+// the wire format otherwise accepts (and preserves) to allow for schema evolution
+func checkNoUnknownFields(msg protoreflect.Message) (err error) {
+	if len(msg.GetUnknown()) > 0 {
+		return fmt.Errorf("unknown fields in message '%s'", msg.Descriptor().FullName())
+	}
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len() && err == nil; i++ {
+				err = checkNoUnknownFields(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				err = checkNoUnknownFields(mv.Message())
+				return err == nil
+			})
+		case fd.Message() != nil && !fd.IsMap():
+			err = checkNoUnknownFields(v.Message())
+		}
+		return err == nil
+	})
+	return err
+}
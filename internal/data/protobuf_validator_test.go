@@ -0,0 +1,168 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const testProtobufSchema = `
+syntax = "proto3";
+package acme;
+
+import "google/protobuf/timestamp.proto";
+
+message Address {
+	string city = 1;
+}
+
+message Customer {
+	string name = 1;
+	int32 age = 2;
+	repeated Address addresses = 3;
+	map<string, Address> named = 4;
+	Address primary = 5;
+	google.protobuf.Timestamp created = 6;
+	repeated string tags = 7;
+}
+`
+
+func newTestProtobufDatatype(schema, message string) *core.Datatype {
+	b, _ := json.Marshal(&core.ProtobufSchema{Schema: schema, Message: message})
+	return &core.Datatype{
+		Validator: core.ValidatorTypeProtobuf,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtrBytes(b),
+	}
+}
+
+func newTestProtobufValidator(t *testing.T) *protobufValidator {
+	pv, err := newProtobufValidator(context.Background(), "ns1", newTestProtobufDatatype(testProtobufSchema, "acme.Customer"))
+	assert.NoError(t, err)
+	return pv
+}
+
+func TestProtobufValidatorValue(t *testing.T) {
+	pv := newTestProtobufValidator(t)
+	ctx := context.Background()
+
+	err := pv.Validate(ctx, &core.Data{Value: fftypes.JSONAnyPtr(`{
+		"name": "fred",
+		"age": 42,
+		"addresses": [{"city": "Bedrock"}],
+		"created": "2024-01-01T00:00:00Z"
+	}`)})
+	assert.NoError(t, err)
+
+	err = pv.ValidateValue(ctx, fftypes.JSONAnyPtr(`{"name": "fred", "unknown": true}`), nil)
+	assert.Regexp(t, "FF10491.*Protobuf", err)
+
+	err = pv.ValidateValue(ctx, fftypes.JSONAnyPtr(`{"age": "old"}`), nil)
+	assert.Regexp(t, "FF10491", err)
+
+	err = pv.ValidateValue(ctx, nil, nil)
+	assert.Regexp(t, "FF10199", err)
+
+	assert.Greater(t, pv.Size(), int64(0))
+}
+
+func TestProtobufValidatorBlob(t *testing.T) {
+	pv := newTestProtobufValidator(t)
+	ctx := context.Background()
+
+	msg := dynamicpb.NewMessage(pv.message)
+	fields := pv.message.Fields()
+	address := func(city string) protoreflect.Value {
+		a := dynamicpb.NewMessage(fields.ByName("primary").Message())
+		a.Set(a.Descriptor().Fields().ByName("city"), protoreflect.ValueOfString(city))
+		return protoreflect.ValueOfMessage(a)
+	}
+	msg.Set(fields.ByName("name"), protoreflect.ValueOfString("fred"))
+	msg.Set(fields.ByName("primary"), address("Bedrock"))
+	msg.Mutable(fields.ByName("addresses")).List().Append(address("Rockvegas"))
+	msg.Mutable(fields.ByName("named")).Map().Set(protoreflect.ValueOfString("home").MapKey(), address("Bedrock"))
+	msg.Mutable(fields.ByName("tags")).List().Append(protoreflect.ValueOfString("vip"))
+	b, err := proto.Marshal(msg)
+	assert.NoError(t, err)
+
+	err = pv.ValidateBlob(ctx, b)
+	assert.NoError(t, err)
+
+	err = pv.ValidateBlob(ctx, protowire.AppendVarint(protowire.AppendTag(b, 99, protowire.VarintType), 1))
+	assert.Regexp(t, "FF10491.*unknown fields.*acme.Customer", err)
+
+	err = pv.ValidateBlob(ctx, []byte{0xff})
+	assert.Regexp(t, "FF10491", err)
+}
+
+func TestProtobufValidatorBlobNestedUnknown(t *testing.T) {
+	pv := newTestProtobufValidator(t)
+	ctx := context.Background()
+
+	unknownAddress := protowire.AppendTag(nil, 99, protowire.VarintType)
+	unknownAddress = protowire.AppendVarint(unknownAddress, 1)
+
+	b := protowire.AppendTag(nil, 3, protowire.BytesType)
+	b = protowire.AppendBytes(b, unknownAddress)
+	err := pv.ValidateBlob(ctx, b)
+	assert.Regexp(t, "FF10491.*unknown fields.*acme.Address", err)
+
+	entry := protowire.AppendTag(nil, 1, protowire.BytesType)
+	entry = protowire.AppendString(entry, "home")
+	entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+	entry = protowire.AppendBytes(entry, unknownAddress)
+	b = protowire.AppendTag(nil, 4, protowire.BytesType)
+	b = protowire.AppendBytes(b, entry)
+	err = pv.ValidateBlob(ctx, b)
+	assert.Regexp(t, "FF10491.*unknown fields.*acme.Address", err)
+
+	b = protowire.AppendTag(nil, 5, protowire.BytesType)
+	b = protowire.AppendBytes(b, unknownAddress)
+	err = pv.ValidateBlob(ctx, b)
+	assert.Regexp(t, "FF10491.*unknown fields.*acme.Address", err)
+}
+
+func TestProtobufValidatorParseSchemaFail(t *testing.T) {
+	_, err := newProtobufValidator(context.Background(), "ns1", &core.Datatype{
+		Validator: core.ValidatorTypeProtobuf,
+		Value:     fftypes.JSONAnyPtr(`"not an object"`),
+	})
+	assert.Regexp(t, "FF10196", err)
+
+	_, err = newProtobufValidator(context.Background(), "ns1", newTestProtobufDatatype("message {", "acme.Customer"))
+	assert.Regexp(t, "FF10196", err)
+
+	_, err = newProtobufValidator(context.Background(), "ns1", newTestProtobufDatatype(`syntax = "proto3"; import "other.proto";`, "acme.Customer"))
+	assert.Regexp(t, "FF10196", err)
+
+	_, err = newProtobufValidator(context.Background(), "ns1", newTestProtobufDatatype(testProtobufSchema, "acme.Supplier"))
+	assert.Regexp(t, "FF10196.*acme.Supplier", err)
+
+	_, err = newProtobufValidator(context.Background(), "ns1", newTestProtobufDatatype(testProtobufSchema, "acme.Customer.name"))
+	assert.Regexp(t, "FF10196", err)
+}
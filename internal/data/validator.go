@@ -1,4 +1,4 @@
-// Copyright © 2022 Kaleido, Inc.
+// Copyright © 2024 Kaleido, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -20,6 +20,8 @@ import (
 	"context"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/pkg/core"
 )
 
@@ -28,3 +30,36 @@ type Validator interface {
 	ValidateValue(ctx context.Context, value *fftypes.JSONAny, expectedHash *fftypes.Bytes32) error
 	Size() int64 // for cache management
 }
+
+// BlobValidator is implemented by validators for formats that can be transferred as blobs, such as
+// XML documents. Data with a blob attached is validated on the content of the blob, rather than the value.
+type BlobValidator interface {
+	Validator
+	ValidateBlob(ctx context.Context, content []byte) error
+}
+
+func newValidator(ctx context.Context, ns string, datatype *core.Datatype) (Validator, error) {
+	switch datatype.Validator {
+	case core.ValidatorTypeXML:
+		return newXMLValidator(ctx, ns, datatype)
+	case core.ValidatorTypeProtobuf:
+		return newProtobufValidator(ctx, ns, datatype)
+	case core.ValidatorTypeAvro:
+		return newAvroValidator(ctx, ns, datatype)
+	default:
+		return newJSONValidator(ctx, ns, datatype)
+	}
+}
+
+func checkValueHash(ctx context.Context, value *fftypes.JSONAny, expectedHash *fftypes.Bytes32) error {
+	if value == nil {
+		return i18n.NewError(ctx, coremsgs.MsgDataValueIsNull)
+	}
+	if expectedHash != nil {
+		hash := value.Hash()
+		if *hash != *expectedHash {
+			return i18n.NewError(ctx, coremsgs.MsgDataInvalidHash, hash, expectedHash)
+		}
+	}
+	return nil
+}
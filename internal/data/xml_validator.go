@@ -0,0 +1,133 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build xsd && cgo
+// +build xsd,cgo
+
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	xsdvalidate "github.com/terminalstatic/go-xsd-validate"
+)
+
+const xmlSchemaNamespace = "http://www.w3.org/2001/XMLSchema"
+
+var libxml2Init sync.Once
+
+// xmlValidator validates XML documents against an XML Schema (XSD), using libxml2. Values are
+// JSON strings containing the document, and blobs contain the document itself.
+type xmlValidator struct {
+	id       *fftypes.UUID
+	size     int64
+	ns       string
+	datatype *core.DatatypeRef
+	handler  *xsdvalidate.XsdHandler
+}
+
+func newXMLValidator(ctx context.Context, ns string, datatype *core.Datatype) (*xmlValidator, error) {
+	xv := &xmlValidator{
+		id: datatype.ID,
+		ns: ns,
+		datatype: &core.DatatypeRef{
+			Name:    datatype.Name,
+			Version: datatype.Version,
+		},
+	}
+
+	var schema string
+	if err := json.Unmarshal(datatype.Value.Bytes(), &schema); err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgSchemaLoadFailed, xv.datatype)
+	}
+	if err := checkSelfContainedXSD(ctx, xv.datatype, []byte(schema)); err != nil {
+		return nil, err
+	}
+	libxml2Init.Do(func() {
+		_ = xsdvalidate.Init()
+	})
+	handler, err := xsdvalidate.NewXsdHandlerMem([]byte(schema), xsdvalidate.ParsErrVerbose)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgSchemaLoadFailed, xv.datatype)
+	}
+	xv.handler = handler
+	// Validators are shared through the cache, so the schema is freed when the last reference is dropped
+	runtime.SetFinalizer(xv, func(xv *xmlValidator) { xv.handler.Free() })
+	xv.size = int64(len(schema))
+
+	log.L(ctx).Debugf("Found XML schema validator for xml:%s:%s: %v", xv.ns, datatype, xv.id)
+	return xv, nil
+}
+
+// checkSelfContainedXSD rejects schemas that reference other schema documents, as libxml2 would
+// otherwise load them from the filesystem or network when the schema is compiled
+func checkSelfContainedXSD(ctx context.Context, datatype *core.DatatypeRef, schema []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(schema))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgSchemaLoadFailed, datatype)
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Space == xmlSchemaNamespace {
+			switch start.Name.Local {
+			case "import", "include", "redefine", "override":
+				return i18n.NewError(ctx, coremsgs.MsgXMLSchemaExternalReference, start.Name.Local)
+			}
+		}
+	}
+}
+
+func (xv *xmlValidator) Validate(ctx context.Context, data *core.Data) error {
+	return xv.ValidateValue(ctx, data.Value, data.Hash)
+}
+
+func (xv *xmlValidator) ValidateValue(ctx context.Context, value *fftypes.JSONAny, expectedHash *fftypes.Bytes32) error {
+	if err := checkValueHash(ctx, value, expectedHash); err != nil {
+		return err
+	}
+	var doc string
+	if err := json.Unmarshal(value.Bytes(), &doc); err != nil {
+		return i18n.NewError(ctx, coremsgs.MsgDataValueNotXMLString)
+	}
+	return xv.ValidateBlob(ctx, []byte(doc))
+}
+
+func (xv *xmlValidator) ValidateBlob(ctx context.Context, content []byte) error {
+	if err := xv.handler.ValidateMem(content, xsdvalidate.ParsErrDefault); err != nil {
+		log.L(ctx).Warnf("XML schema %s [%v] validation failed: %s", xv.datatype, xv.id, err)
+		return i18n.NewError(ctx, coremsgs.MsgDataInvalidPerSchema, "XML", xv.datatype, err)
+	}
+	return nil
+}
+
+func (xv *xmlValidator) Size() int64 {
+	return xv.size
+}
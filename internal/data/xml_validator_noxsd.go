@@ -0,0 +1,33 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !xsd || !cgo
+// +build !xsd !cgo
+
+package data
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// XML Schema validation requires libxml2, so is only available in builds with the xsd tag and cgo enabled
+func newXMLValidator(ctx context.Context, ns string, datatype *core.Datatype) (Validator, error) {
+	return nil, i18n.NewError(ctx, coremsgs.MsgXMLValidatorNotSupported)
+}
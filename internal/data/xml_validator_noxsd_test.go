@@ -0,0 +1,33 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !xsd || !cgo
+// +build !xsd !cgo
+
+package data
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXMLValidatorNotSupported(t *testing.T) {
+	_, err := newXMLValidator(context.Background(), "ns1", &core.Datatype{Validator: core.ValidatorTypeXML})
+	assert.Regexp(t, "FF10490", err)
+}
@@ -0,0 +1,104 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build xsd && cgo
+// +build xsd,cgo
+
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+const testXMLSchema = `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+	<xs:element name="customer">
+		<xs:complexType>
+			<xs:sequence>
+				<xs:element name="name" type="xs:string"/>
+				<xs:element name="age" type="xs:int" minOccurs="0"/>
+			</xs:sequence>
+		</xs:complexType>
+	</xs:element>
+</xs:schema>`
+
+func newTestXMLDatatype(schema string) *core.Datatype {
+	b, _ := json.Marshal(schema)
+	return &core.Datatype{
+		Validator: core.ValidatorTypeXML,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtrBytes(b),
+	}
+}
+
+func TestXMLValidator(t *testing.T) {
+	xv, err := newXMLValidator(context.Background(), "ns1", newTestXMLDatatype(testXMLSchema))
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	err = xv.Validate(ctx, &core.Data{Value: fftypes.JSONAnyPtr(`"<customer><name>fred</name><age>42</age></customer>"`)})
+	assert.NoError(t, err)
+
+	err = xv.ValidateValue(ctx, fftypes.JSONAnyPtr(`"<customer><age>old</age></customer>"`), nil)
+	assert.Regexp(t, "FF10491.*XML", err)
+
+	err = xv.ValidateValue(ctx, fftypes.JSONAnyPtr(`{"customer": {}}`), nil)
+	assert.Regexp(t, "FF10492", err)
+
+	err = xv.ValidateValue(ctx, nil, nil)
+	assert.Regexp(t, "FF10199", err)
+
+	err = xv.ValidateBlob(ctx, []byte(`<customer><name>fred</name></customer>`))
+	assert.NoError(t, err)
+
+	err = xv.ValidateBlob(ctx, []byte(`<customer><name>fred`))
+	assert.Regexp(t, "FF10491", err)
+
+	assert.Equal(t, int64(len(testXMLSchema)), xv.Size())
+}
+
+func TestXMLValidatorParseSchemaFail(t *testing.T) {
+	_, err := newXMLValidator(context.Background(), "ns1", &core.Datatype{
+		Validator: core.ValidatorTypeXML,
+		Value:     fftypes.JSONAnyPtr(`{"not": "a string"}`),
+	})
+	assert.Regexp(t, "FF10196", err)
+
+	_, err = newXMLValidator(context.Background(), "ns1", newTestXMLDatatype(`<xs:schema`))
+	assert.Regexp(t, "FF10196", err)
+
+	_, err = newXMLValidator(context.Background(), "ns1", newTestXMLDatatype(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:element name="a" type="missing"/></xs:schema>`))
+	assert.Regexp(t, "FF10196", err)
+}
+
+func TestXMLValidatorExternalReference(t *testing.T) {
+	_, err := newXMLValidator(context.Background(), "ns1", newTestXMLDatatype(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+		<xs:import namespace="urn:other" schemaLocation="http://example.com/other.xsd"/>
+	</xs:schema>`))
+	assert.Regexp(t, "FF10493.*import", err)
+
+	_, err = newXMLValidator(context.Background(), "ns1", newTestXMLDatatype(`<schema xmlns="http://www.w3.org/2001/XMLSchema">
+		<include schemaLocation="/etc/other.xsd"/>
+	</schema>`))
+	assert.Regexp(t, "FF10493.*include", err)
+}
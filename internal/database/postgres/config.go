@@ -27,5 +27,6 @@ const (
 
 func (psql *Postgres) InitConfig(config config.Section) {
 	psql.SQLCommon.InitConfig(psql, config)
+	psql.SQLCommon.InitReadReplicaConfig(config)
 	config.SetDefault(sqlcommon.SQLConfMaxConnections, defaultConnectionLimitPostgreSQL)
 }
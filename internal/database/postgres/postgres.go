@@ -18,8 +18,10 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"database/sql"
 
@@ -92,6 +94,36 @@ func (psql *Postgres) ApplyInsertQueryCustomizations(insert sq.InsertBuilder, re
 	return insert.Suffix(suffix), true
 }
 
+// ReplicationLagQuery returns the replay lag of a streaming replica in seconds. The lag is zero
+// when all received WAL has been replayed (so an idle primary does not appear as lag),
+// and for a database that is not in recovery.
+func (psql *Postgres) ReplicationLagQuery() string {
+	return `SELECT COALESCE(CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 ` +
+		`ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END, 0);`
+}
+
+func jsonPathExpression(column string, path []string) string {
+	return fmt.Sprintf("((%s::jsonb) #> '{%s}')", column, strings.Join(path, ","))
+}
+
+// JSONPathCondition uses containment, so that queries can be served by the GIN index. A scalar only
+// contains an equal scalar, but an array contains each of its entries - so arrays are excluded.
+func (psql *Postgres) JSONPathCondition(column string, path []string, candidates []interface{}) sq.Sqlizer {
+	expr := jsonPathExpression(column, path)
+	or := make(sq.Or, len(candidates))
+	for i, c := range candidates {
+		b, _ := json.Marshal(c)
+		or[i] = sq.Expr(fmt.Sprintf("%s @> ?::jsonb", expr), string(b))
+	}
+	return sq.And{or, sq.Expr(fmt.Sprintf("jsonb_typeof(%s) <> 'array'", expr))}
+}
+
+// JSONPathIndex returns a GIN index on the value at the path. The index is built concurrently, so that
+// writes to the table are not blocked while it builds - which means it must run outside of a transaction.
+func (psql *Postgres) JSONPathIndex(name, table, column string, path []string) string {
+	return fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s USING GIN (%s jsonb_path_ops);", name, table, jsonPathExpression(column, path))
+}
+
 func (psql *Postgres) Open(url string) (*sql.DB, error) {
 	return sql.Open(psql.Name(), url)
 }
@@ -50,4 +50,20 @@ func TestPostgresProvider(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)  ON CONFLICT DO NOTHING RETURNING seq", sql)
 	assert.True(t, query)
+
+	assert.Contains(t, psql.ReplicationLagQuery(), "pg_last_xact_replay_timestamp()")
+	assert.Equal(t, "5s", config.GetString(sqlcommon.SQLConfReadReplicaMaxLag))
+}
+
+func TestPostgresJSONPath(t *testing.T) {
+	psql := &Postgres{}
+
+	sql, args, err := psql.JSONPathCondition("d.value", []string{"order", "id"}, []interface{}{float64(123), "123"}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "((((d.value::jsonb) #> '{order,id}') @> ?::jsonb OR ((d.value::jsonb) #> '{order,id}') @> ?::jsonb) "+
+		"AND jsonb_typeof(((d.value::jsonb) #> '{order,id}')) <> 'array')", sql)
+	assert.Equal(t, []interface{}{"123", `"123"`}, args)
+
+	assert.Equal(t, "CREATE INDEX CONCURRENTLY IF NOT EXISTS data_value_abc ON data USING GIN (((value::jsonb) #> '{order,id}') jsonb_path_ops);",
+		psql.JSONPathIndex("data_value_abc", "data", "value", []string{"order", "id"}))
 }
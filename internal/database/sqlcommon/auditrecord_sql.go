@@ -0,0 +1,179 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const auditrecordsTable = "auditrecords"
+
+var (
+	auditRecordColumns = []string{
+		"id",
+		"namespace",
+		"principal",
+		"client",
+		"method",
+		"route",
+		"path",
+		"params",
+		"input_hash",
+		"request_id",
+		"idempotency_key",
+		"tx_id",
+		"op_id",
+		"status",
+		"error",
+		"created",
+		"previous_hash",
+		"hash",
+	}
+	auditRecordFilterFieldMap = map[string]string{
+		"requestid":      "request_id",
+		"idempotencykey": "idempotency_key",
+		"tx":             "tx_id",
+		"operation":      "op_id",
+	}
+)
+
+// Audit records are append only - there are deliberately no update or delete functions
+func (s *SQLCommon) InsertAuditRecord(ctx context.Context, record *core.AuditRecord) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	record.Sequence, err = s.InsertTx(ctx, auditrecordsTable, tx,
+		sq.Insert(auditrecordsTable).
+			Columns(auditRecordColumns...).
+			Values(
+				record.ID,
+				record.Namespace,
+				record.Principal,
+				record.Client,
+				record.Method,
+				record.Route,
+				record.Path,
+				record.Params,
+				record.InputHash,
+				record.RequestID,
+				record.IdempotencyKey,
+				record.Transaction,
+				record.Operation,
+				record.Status,
+				record.Error,
+				record.Created,
+				record.PreviousHash,
+				record.Hash,
+			),
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) auditRecordResult(ctx context.Context, row *sql.Rows) (*core.AuditRecord, error) {
+	var record core.AuditRecord
+	err := row.Scan(
+		&record.ID,
+		&record.Namespace,
+		&record.Principal,
+		&record.Client,
+		&record.Method,
+		&record.Route,
+		&record.Path,
+		&record.Params,
+		&record.InputHash,
+		&record.RequestID,
+		&record.IdempotencyKey,
+		&record.Transaction,
+		&record.Operation,
+		&record.Status,
+		&record.Error,
+		&record.Created,
+		&record.PreviousHash,
+		&record.Hash,
+		&record.Sequence,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, auditrecordsTable)
+	}
+	return &record, nil
+}
+
+func (s *SQLCommon) GetLastAuditRecord(ctx context.Context, namespace string) (*core.AuditRecord, error) {
+	cols := append([]string{}, auditRecordColumns...)
+	cols = append(cols, s.SequenceColumn())
+	rows, _, err := s.Query(ctx, auditrecordsTable,
+		sq.Select(cols...).
+			From(auditrecordsTable).
+			Where(sq.Eq{"namespace": namespace}).
+			OrderBy(s.SequenceColumn()+" DESC").
+			Limit(1),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("No audit records in namespace '%s'", namespace)
+		return nil, nil
+	}
+
+	return s.auditRecordResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetAuditRecords(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.AuditRecord, *ffapi.FilterResult, error) {
+	cols := append([]string{}, auditRecordColumns...)
+	cols = append(cols, s.SequenceColumn())
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(cols...).From(auditrecordsTable),
+		filter, auditRecordFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, auditrecordsTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	records := []*core.AuditRecord{}
+	for rows.Next() {
+		record, err := s.auditRecordResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, s.QueryRes(ctx, auditrecordsTable, tx, fop, nil, fi), err
+}
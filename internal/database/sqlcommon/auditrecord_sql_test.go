@@ -0,0 +1,167 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRecordsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	last, err := s.GetLastAuditRecord(ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Nil(t, last)
+
+	// Append a pair of records to the chain
+	record1 := &core.AuditRecord{
+		ID:             fftypes.NewUUID(),
+		Namespace:      "ns1",
+		Principal:      "user:alice",
+		Client:         "addr:127.0.0.1",
+		Method:         "POST",
+		Route:          "postNewMessageBroadcast",
+		Path:           "/api/v1/namespaces/ns1/messages/broadcast",
+		Params:         fftypes.JSONObject{"query": fftypes.JSONObject{"confirm": []interface{}{"true"}}},
+		InputHash:      fftypes.NewRandB32(),
+		RequestID:      "req1",
+		IdempotencyKey: "idem1",
+		Transaction:    fftypes.NewUUID(),
+		Status:         202,
+		Created:        fftypes.Now(),
+	}
+	record1.Seal(nil)
+	err = s.InsertAuditRecord(ctx, record1)
+	assert.NoError(t, err)
+	assert.Greater(t, record1.Sequence, int64(0))
+
+	record2 := &core.AuditRecord{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Method:    "DELETE",
+		Path:      "/api/v1/namespaces/ns1/subscriptions/sub1",
+		Operation: fftypes.NewUUID(),
+		Status:    500,
+		Error:     "pop",
+		Created:   fftypes.Now(),
+	}
+	record2.Seal(record1.Hash)
+	err = s.InsertAuditRecord(ctx, record2)
+	assert.NoError(t, err)
+
+	// Query back the last record
+	last, err = s.GetLastAuditRecord(ctx, "ns1")
+	assert.NoError(t, err)
+	record2Json, _ := json.Marshal(record2)
+	lastJson, _ := json.Marshal(last)
+	assert.Equal(t, string(record2Json), string(lastJson))
+	assert.Equal(t, *record2.Hash, *last.CalcHash())
+
+	// Query back the first record by filter
+	fb := database.AuditRecordQueryFactory.NewFilter(ctx)
+	records, res, err := s.GetAuditRecords(ctx, "ns1", fb.And(
+		fb.Eq("principal", "user:alice"),
+		fb.Eq("idempotencykey", "idem1"),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	record1Json, _ := json.Marshal(record1)
+	readJson, _ := json.Marshal(records[0])
+	assert.Equal(t, string(record1Json), string(readJson))
+	assert.Equal(t, *record1.Hash, *records[0].CalcHash())
+}
+
+func TestInsertAuditRecordFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertAuditRecord(context.Background(), &core.AuditRecord{})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertAuditRecordFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertAuditRecord(context.Background(), &core.AuditRecord{})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertAuditRecordFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertAuditRecord(context.Background(), &core.AuditRecord{})
+	assert.Regexp(t, "FF00180", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLastAuditRecordQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetLastAuditRecord(context.Background(), "ns1")
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLastAuditRecordScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetLastAuditRecord(context.Background(), "ns1")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAuditRecordsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.AuditRecordQueryFactory.NewFilter(context.Background()).Eq("principal", "")
+	_, _, err := s.GetAuditRecords(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAuditRecordsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.AuditRecordQueryFactory.NewFilter(context.Background()).Eq("principal", map[bool]bool{true: false})
+	_, _, err := s.GetAuditRecords(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00143.*principal", err)
+}
+
+func TestGetAuditRecordsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.AuditRecordQueryFactory.NewFilter(context.Background()).Eq("principal", "")
+	_, _, err := s.GetAuditRecords(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
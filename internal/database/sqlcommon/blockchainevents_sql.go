@@ -230,3 +230,21 @@ func (s *SQLCommon) GetBlockchainEvents(ctx context.Context, namespace string, f
 
 	return events, s.QueryRes(ctx, blockchaineventsTable, tx, fop, nil, fi), err
 }
+
+func (s *SQLCommon) DeleteBlockchainEvents(ctx context.Context, namespace string, ids []*fftypes.UUID) error {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, blockchaineventsTable, tx, sq.Delete(blockchaineventsTable).Where(sq.Eq{
+		"namespace": namespace,
+		"id":        ids,
+	}), nil)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
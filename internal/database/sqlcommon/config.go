@@ -38,6 +38,14 @@ const (
 	SQLConfMaxIdleConns = "maxIdleConns"
 	// SQLConfMaxConnLifetime maximum connections to the database
 	SQLConfMaxConnLifetime = "maxConnLifetime"
+	// SQLConfReadReplicaURL is the datasource connection URL string of an optional read replica
+	SQLConfReadReplicaURL = "readReplica.url"
+	// SQLConfReadReplicaMaxConnections maximum connections to the read replica
+	SQLConfReadReplicaMaxConnections = "readReplica.maxConns"
+	// SQLConfReadReplicaMaxLag maximum replication lag before reads are routed back to the primary
+	SQLConfReadReplicaMaxLag = "readReplica.maxLag"
+	// SQLConfReadReplicaLagCheckInterval how often to check the replication lag of the read replica
+	SQLConfReadReplicaLagCheckInterval = "readReplica.lagCheckInterval"
 )
 
 const (
@@ -53,3 +61,11 @@ func (s *SQLCommon) InitConfig(provider dbsql.Provider, config config.Section) {
 	config.AddKnownKey(SQLConfMaxIdleConns) // defaults to the max connections
 	config.AddKnownKey(SQLConfMaxConnLifetime)
 }
+
+// InitReadReplicaConfig adds the configuration for a read replica, for providers that implement ReadReplicaProvider
+func (s *SQLCommon) InitReadReplicaConfig(config config.Section) {
+	config.AddKnownKey(SQLConfReadReplicaURL)
+	config.AddKnownKey(SQLConfReadReplicaMaxConnections) // defaults to the max connections of the primary
+	config.AddKnownKey(SQLConfReadReplicaMaxLag, "5s")
+	config.AddKnownKey(SQLConfReadReplicaLagCheckInterval, "5s")
+}
@@ -48,6 +48,11 @@ var (
 		"blob_size",
 		"public",
 		"value_size",
+		"redacted",
+		"redacted_by",
+		"redacted_author",
+		"redacted_reason",
+		"value_ref",
 	}
 	dataColumnsWithValue = append(append([]string{}, dataColumnsNoValue...), "value")
 	dataFilterFieldMap   = map[string]string{
@@ -59,6 +64,9 @@ var (
 		"blob.name":        "blob_name",
 		"blob.path":        "blob_path",
 		"blob.size":        "blob_size",
+		"redacted.created": "redacted",
+		"redacted.by":      "redacted_by",
+		"redacted.author":  "redacted_author",
 	}
 )
 
@@ -73,6 +81,14 @@ func (s *SQLCommon) attemptDataUpdate(ctx context.Context, tx *dbsql.TXWrapper,
 	if blob == nil {
 		blob = &core.BlobRef{}
 	}
+	redacted := data.Redacted
+	if redacted == nil {
+		redacted = &core.DataRedaction{}
+	}
+	valueRef := data.ValueRef
+	if valueRef == nil {
+		valueRef = &core.DataValueRef{}
+	}
 	data.CalcPath()
 	return s.UpdateTx(ctx, dataTable, tx,
 		sq.Update(dataTable).
@@ -88,6 +104,11 @@ func (s *SQLCommon) attemptDataUpdate(ctx context.Context, tx *dbsql.TXWrapper,
 			Set("blob_size", blob.Size).
 			Set("public", data.Public).
 			Set("value_size", data.ValueSize).
+			Set("redacted", redacted.Created).
+			Set("redacted_by", redacted.By).
+			Set("redacted_author", redacted.Author).
+			Set("redacted_reason", redacted.Reason).
+			Set("value_ref", valueRef.PayloadRef).
 			Set("value", data.Value).
 			Where(sq.Eq{
 				"id":        data.ID,
@@ -108,6 +129,14 @@ func (s *SQLCommon) setDataInsertValues(query sq.InsertBuilder, data *core.Data)
 	if blob == nil {
 		blob = &core.BlobRef{}
 	}
+	redacted := data.Redacted
+	if redacted == nil {
+		redacted = &core.DataRedaction{}
+	}
+	valueRef := data.ValueRef
+	if valueRef == nil {
+		valueRef = &core.DataValueRef{}
+	}
 	data.CalcPath()
 	return query.Values(
 		data.ID,
@@ -124,6 +153,11 @@ func (s *SQLCommon) setDataInsertValues(query sq.InsertBuilder, data *core.Data)
 		blob.Size,
 		data.Public,
 		data.ValueSize,
+		redacted.Created,
+		redacted.By,
+		redacted.Author,
+		redacted.Reason,
+		valueRef.PayloadRef,
 		data.Value,
 	)
 }
@@ -234,6 +268,8 @@ func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue boo
 	data := core.Data{
 		Datatype: &core.DatatypeRef{},
 		Blob:     &core.BlobRef{},
+		Redacted: &core.DataRedaction{},
+		ValueRef: &core.DataValueRef{},
 	}
 	results := []interface{}{
 		&data.ID,
@@ -250,6 +286,11 @@ func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue boo
 		&data.Blob.Size,
 		&data.Public,
 		&data.ValueSize,
+		&data.Redacted.Created,
+		&data.Redacted.By,
+		&data.Redacted.Author,
+		&data.Redacted.Reason,
+		&data.ValueRef.PayloadRef,
 	}
 	if withValue {
 		results = append(results, &data.Value)
@@ -261,6 +302,14 @@ func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue boo
 	if data.Datatype.Name == "" && data.Datatype.Version == "" {
 		data.Datatype = nil
 	}
+	if data.Redacted.Created == nil {
+		data.Redacted = nil
+	}
+	if data.ValueRef.PayloadRef == "" {
+		data.ValueRef = nil
+	} else {
+		data.ValueRef.Size = data.ValueSize
+	}
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, dataTable)
 	}
@@ -299,10 +348,22 @@ func (s *SQLCommon) GetDataByID(ctx context.Context, namespace string, id *fftyp
 }
 
 func (s *SQLCommon) GetData(ctx context.Context, namespace string, filter ffapi.Filter) (message core.DataArray, res *ffapi.FilterResult, err error) {
+	return s.getDataQuery(ctx, filter, sq.Eq{"namespace": namespace})
+}
+
+func (s *SQLCommon) GetDataByValue(ctx context.Context, namespace string, matches []*core.JSONPathMatch, filter ffapi.Filter) (message core.DataArray, res *ffapi.FilterResult, err error) {
+	conditions, err := s.jsonPathConditions(ctx, "value", matches)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.getDataQuery(ctx, filter, append([]sq.Sqlizer{sq.Eq{"namespace": namespace}}, conditions...)...)
+}
+
+func (s *SQLCommon) getDataQuery(ctx context.Context, filter ffapi.Filter, preconditions ...sq.Sqlizer) (message core.DataArray, res *ffapi.FilterResult, err error) {
 
 	query, fop, fi, err := s.FilterSelect(
 		ctx, "", sq.Select(dataColumnsWithValue...).From(dataTable),
-		filter, dataFilterFieldMap, []interface{}{"sequence"}, sq.Eq{"namespace": namespace})
+		filter, dataFilterFieldMap, []interface{}{"sequence"}, preconditions...)
 	if err != nil {
 		return nil, nil, err
 	}
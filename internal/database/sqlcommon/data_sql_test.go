@@ -165,6 +165,40 @@ func TestDataE2EWithDB(t *testing.T) {
 	assert.Equal(t, 1, len(dataRes))
 	assert.Equal(t, int64(1), *res.TotalCount)
 
+	// Redact the value, keeping the hash
+	dataUpdated.Datatype.Version = v2
+	dataUpdated.Value = core.DataRedactedValue
+	dataUpdated.Redacted = &core.DataRedaction{
+		By:      "jwt:dpo",
+		Author:  "did:firefly:org/org1",
+		Reason:  "erasure request 12345",
+		Created: fftypes.Now(),
+	}
+	err = s.UpsertData(ctx, dataUpdated, database.UpsertOptimizationExisting)
+	assert.NoError(t, err)
+	dataRes, _, err = s.GetData(ctx, "ns1", fb.And(fb.Eq("redacted.by", "jwt:dpo"), fb.Eq("redacted.author", "did:firefly:org/org1"), fb.Gt("redacted.created", 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(dataRes))
+	dataJson, _ = json.Marshal(&dataUpdated)
+	dataReadJson, _ = json.Marshal(dataRes[0])
+	assert.Equal(t, string(dataJson), string(dataReadJson))
+
+	// Hold the value in the blob store, with a reference in place of the value
+	dataUpdated.Redacted = nil
+	dataUpdated.Value = nil
+	dataUpdated.ValueSize = 5000
+	dataUpdated.ValueRef = &core.DataValueRef{
+		Size:       5000,
+		PayloadRef: "ns1/value1",
+	}
+	err = s.UpsertData(ctx, dataUpdated, database.UpsertOptimizationExisting)
+	assert.NoError(t, err)
+	dataRead, err = s.GetDataByID(ctx, "ns1", dataID, true)
+	assert.NoError(t, err)
+	assert.Nil(t, dataRead.Value)
+	assert.Equal(t, int64(5000), dataRead.ValueRef.Size)
+	assert.Equal(t, "ns1/value1", dataRead.ValueRef.PayloadRef)
+
 	s.callbacks.AssertExpectations(t)
 
 	// Delete
@@ -42,6 +42,8 @@ var (
 		"hash",
 		"created",
 		"value",
+		"index_paths",
+		"compatibility",
 	}
 	datatypeFilterFieldMap = map[string]string{
 		"message": "message_id",
@@ -87,6 +89,8 @@ func (s *SQLCommon) UpsertDatatype(ctx context.Context, datatype *core.Datatype,
 				Set("hash", datatype.Hash).
 				Set("created", datatype.Created).
 				Set("value", datatype.Value).
+				Set("index_paths", datatype.IndexPaths).
+				Set("compatibility", string(datatype.Compatibility)).
 				Where(sq.Eq{"id": datatype.ID}),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionDataTypes, core.ChangeEventTypeUpdated, datatype.Namespace, datatype.ID)
@@ -108,6 +112,8 @@ func (s *SQLCommon) UpsertDatatype(ctx context.Context, datatype *core.Datatype,
 					datatype.Hash,
 					datatype.Created,
 					datatype.Value,
+					datatype.IndexPaths,
+					string(datatype.Compatibility),
 				),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionDataTypes, core.ChangeEventTypeCreated, datatype.Namespace, datatype.ID)
@@ -117,6 +123,8 @@ func (s *SQLCommon) UpsertDatatype(ctx context.Context, datatype *core.Datatype,
 		}
 	}
 
+	s.ensureJSONPathIndexes(ctx, tx, dataTable, "value", datatype.IndexPaths)
+
 	return s.CommitTx(ctx, tx, autoCommit)
 }
 
@@ -132,6 +140,8 @@ func (s *SQLCommon) datatypeResult(ctx context.Context, row *sql.Rows) (*core.Da
 		&datatype.Hash,
 		&datatype.Created,
 		&datatype.Value,
+		&datatype.IndexPaths,
+		&datatype.Compatibility,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, datatypesTable)
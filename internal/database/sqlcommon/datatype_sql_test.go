@@ -80,15 +80,16 @@ func TestDatatypeE2EWithDB(t *testing.T) {
 		},
 	}
 	datatypeUpdated := &core.Datatype{
-		ID:        datatypeID,
-		Message:   fftypes.NewUUID(),
-		Validator: core.ValidatorTypeJSON,
-		Namespace: "ns1",
-		Name:      "customer",
-		Version:   "0.0.1",
-		Hash:      randB32,
-		Created:   fftypes.Now(),
-		Value:     fftypes.JSONAnyPtr(val2.String()),
+		ID:            datatypeID,
+		Message:       fftypes.NewUUID(),
+		Validator:     core.ValidatorTypeJSON,
+		Namespace:     "ns1",
+		Name:          "customer",
+		Version:       "0.0.1",
+		Hash:          randB32,
+		Created:       fftypes.Now(),
+		Value:         fftypes.JSONAnyPtr(val2.String()),
+		Compatibility: core.DatatypeCompatibilityBackward,
 	}
 	err = s.UpsertDatatype(context.Background(), datatypeUpdated, true)
 	assert.NoError(t, err)
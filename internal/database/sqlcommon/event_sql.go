@@ -78,6 +78,19 @@ func (s *SQLCommon) InsertEvent(ctx context.Context, event *core.Event) (err err
 	return s.CommitTx(ctx, tx, autoCommit)
 }
 
+func (s *SQLCommon) InsertEvents(ctx context.Context, events []*core.Event) (err error) {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	if err := (&eventsPCA{s: s, events: events}).PreCommit(ctx, tx); err != nil {
+		return err
+	}
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
 func (s *SQLCommon) setEventInsertValues(query sq.InsertBuilder, event *core.Event) sq.InsertBuilder {
 	return query.Values(
 		event.ID,
@@ -246,3 +259,21 @@ func (s *SQLCommon) GetEventsInSequenceRange(ctx context.Context, namespace stri
 
 	return s.getEventsGeneric(ctx, namespace, query, filter)
 }
+
+func (s *SQLCommon) DeleteEvents(ctx context.Context, namespace string, ids []*fftypes.UUID) error {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, eventsTable, tx, sq.Delete(eventsTable).Where(sq.Eq{
+		"namespace": namespace,
+		"id":        ids,
+	}), nil)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
@@ -86,6 +86,13 @@ func TestEventE2EWithDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(events))
 
+	// Delete the event
+	err = s.DeleteEvents(ctx, "ns1", []*fftypes.UUID{eventID})
+	assert.NoError(t, err)
+	eventRead, err = s.GetEventByID(ctx, "ns1", eventID)
+	assert.NoError(t, err)
+	assert.Nil(t, eventRead)
+
 	s.callbacks.AssertExpectations(t)
 }
 
@@ -139,6 +146,55 @@ func TestGetEventsInSequenceRangeE2EWithDB(t *testing.T) {
 	assert.Equal(t, 1, len(events))
 }
 
+func TestInsertEventsE2EWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	events := []*core.Event{
+		{ID: fftypes.NewUUID(), Namespace: "ns1", Type: core.EventTypeMessageConfirmed, Created: fftypes.Now()},
+		{ID: fftypes.NewUUID(), Namespace: "ns1", Type: core.EventTypeMessageConfirmed, Created: fftypes.Now()},
+	}
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, core.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+
+	err := s.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := s.InsertEvents(ctx, events); err != nil {
+			return err
+		}
+		// The events are visible, with their sequences, before the transaction commits
+		eventsRead, _, err := s.GetEvents(ctx, "ns1", database.EventQueryFactory.NewFilter(ctx).And().Sort("sequence"))
+		assert.NoError(t, err)
+		assert.Len(t, eventsRead, 2)
+		assert.Equal(t, events[0].ID, eventsRead[0].ID)
+		assert.Equal(t, events[0].Sequence, eventsRead[0].Sequence)
+		assert.Equal(t, events[1].ID, eventsRead[1].ID)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestInsertEventsFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertEvents(context.Background(), []*core.Event{{}})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertEventsFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("<acquire lock ns1>").WillReturnResult(driver.ResultNoRows)
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertEvents(context.Background(), []*core.Event{{ID: fftypes.NewUUID(), Namespace: "ns1"}})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestInsertEventFailBegin(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
@@ -329,3 +385,30 @@ func TestGetEventsInSequenceRangeShouldCallGetEventsWhenNoSequencedProvidedAndTh
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestDeleteEventsFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteEvents(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteEventsFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteEvents(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteEventsNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(driver.RowsAffected(0))
+	mock.ExpectCommit()
+	err := s.DeleteEvents(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
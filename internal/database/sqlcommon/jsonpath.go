@@ -0,0 +1,104 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// JSONPathProvider is implemented by database providers that support querying, and indexing,
+// values at a JSON path within a column containing JSON text. The path segments passed to the
+// provider have been validated by core.ValidateJSONPath, so can be safely embedded in SQL.
+type JSONPathProvider interface {
+	// JSONPathCondition returns a condition that matches rows where the value at the path in
+	// the column equals any of the candidate values
+	JSONPathCondition(column string, path []string, candidates []interface{}) sq.Sqlizer
+	// JSONPathIndex returns a statement that creates the named index on the path in the column,
+	// if it does not already exist
+	JSONPathIndex(name, table, column string, path []string) string
+}
+
+func (s *SQLCommon) jsonPathConditions(ctx context.Context, column string, matches []*core.JSONPathMatch) ([]sq.Sqlizer, error) {
+	if s.jsonPath == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgJSONPathQueryNotSupported)
+	}
+	conditions := make([]sq.Sqlizer, len(matches))
+	for i, m := range matches {
+		conditions[i] = s.jsonPath.JSONPathCondition(column, m.Segments(), m.Candidates())
+	}
+	return conditions, nil
+}
+
+// jsonPathIndexName derives a stable name for the index of a path, within the identifier length limits of all databases
+func jsonPathIndexName(table, column, path string) string {
+	h := sha256.Sum256([]byte(strings.Join([]string{table, column, path}, ":")))
+	return table + "_" + column + "_" + hex.EncodeToString(h[0:8])
+}
+
+// ensureJSONPathIndexes schedules a build of any missing indexes for the paths, once the transaction that
+// declared them has committed. Building an index over a large data table can take a long time, so the build
+// runs in the background outside of any transaction - allowing the provider to build it without blocking
+// writes - and builds are serialized. Indexes are shared across all datatypes and namespaces that declare
+// the same path, and are never dropped automatically.
+func (s *SQLCommon) ensureJSONPathIndexes(ctx context.Context, tx *dbsql.TXWrapper, table, column string, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	if s.jsonPath == nil {
+		log.L(ctx).Warnf("Database does not support JSON path indexes - paths will not be indexed: %v", paths)
+		return
+	}
+	tx.AddPostCommitHook(func() {
+		s.jsonPathIndexing.Add(1)
+		go s.buildJSONPathIndexes(table, column, paths)
+	})
+}
+
+func (s *SQLCommon) buildJSONPathIndexes(table, column string, paths []string) {
+	defer s.jsonPathIndexing.Done()
+	s.jsonPathIndexLock.Lock()
+	defer s.jsonPathIndexLock.Unlock()
+
+	ctx := log.WithLogField(context.Background(), "role", "jsonpath-indexer")
+	for _, path := range paths {
+		m := &core.JSONPathMatch{Path: path}
+		statement := s.jsonPath.JSONPathIndex(jsonPathIndexName(table, column, path), table, column, m.Segments())
+		before := time.Now()
+		if _, err := s.DB().ExecContext(ctx, statement); err != nil {
+			log.L(ctx).Errorf("Failed to build index for JSON path '%s' on %s.%s: %s", path, table, column, err)
+			// A failed concurrent build can leave an invalid index behind, which would satisfy IF NOT EXISTS
+			// on the next attempt - so remove it
+			if _, err := s.DB().ExecContext(ctx, fmt.Sprintf("DROP INDEX IF EXISTS %s;", jsonPathIndexName(table, column, path))); err != nil {
+				log.L(ctx).Errorf("Failed to remove index for JSON path '%s' on %s.%s: %s", path, table, column, err)
+			}
+			continue
+		}
+		log.L(ctx).Infof("Ensured index for JSON path '%s' on %s.%s (%.2fs)", path, table, column, time.Since(before).Seconds())
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type errJSONPathProvider struct {
+	sqliteGoTestProvider
+}
+
+func (*errJSONPathProvider) JSONPathCondition(column string, path []string, candidates []interface{}) sq.Sqlizer {
+	return errSqlizer{}
+}
+
+func TestJSONPathQueriesE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("UUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	datatype := &core.Datatype{
+		ID:         fftypes.NewUUID(),
+		Message:    fftypes.NewUUID(),
+		Validator:  core.ValidatorTypeJSON,
+		Namespace:  "ns1",
+		Name:       "order",
+		Version:    "1.0.0",
+		Hash:       fftypes.NewRandB32(),
+		Created:    fftypes.Now(),
+		Value:      fftypes.JSONAnyPtr(`{}`),
+		IndexPaths: fftypes.FFStringArray{"order.id", "status"},
+	}
+	err := s.UpsertDatatype(ctx, datatype, false)
+	assert.NoError(t, err)
+	err = s.UpsertDatatype(ctx, datatype, true)
+	assert.NoError(t, err)
+
+	datatypeRead, err := s.GetDatatypeByID(ctx, "ns1", datatype.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, datatype.IndexPaths, datatypeRead.IndexPaths)
+
+	// Indexes are built in the background, after the commit
+	s.jsonPathIndexing.Wait()
+	rows, _, err := s.Query(ctx, "sqlite_master", sq.Select("name").From("sqlite_master").Where(sq.Eq{
+		"type": "index",
+		"name": jsonPathIndexName(dataTable, "value", "order.id"),
+	}))
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	rows.Close()
+
+	newData := func(ns, value string) *core.Data {
+		d := &core.Data{
+			ID:        fftypes.NewUUID(),
+			Validator: core.ValidatorTypeJSON,
+			Namespace: ns,
+			Hash:      fftypes.NewRandB32(),
+			Created:   fftypes.Now(),
+			Value:     fftypes.JSONAnyPtr(value),
+		}
+		err := s.UpsertData(ctx, d, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		return d
+	}
+	d1 := newData("ns1", `{"order":{"id":"123"},"status":"open"}`)
+	d2 := newData("ns1", `{"order":{"id":123},"status":"closed"}`)
+	d3 := newData("ns1", `{"order":{"id":["123"]},"status":true}`)
+	newData("ns2", `{"order":{"id":"123"},"status":"open"}`)
+
+	newMessage := func(d *core.Data) *core.Message {
+		msg := &core.Message{
+			Header: core.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				Type:      core.MessageTypeBroadcast,
+				Namespace: "ns1",
+				Created:   fftypes.Now(),
+				DataHash:  fftypes.NewRandB32(),
+			},
+			LocalNamespace: "ns1",
+			Hash:           fftypes.NewRandB32(),
+			Data:           core.DataRefs{{ID: d.ID, Hash: d.Hash}},
+		}
+		err := s.UpsertMessage(ctx, msg, database.UpsertOptimizationNew)
+		assert.NoError(t, err)
+		return msg
+	}
+	m1 := newMessage(d1)
+	newMessage(d2)
+
+	match := func(s string) *core.JSONPathMatch {
+		m, err := core.ParseJSONPathMatch(ctx, s)
+		assert.NoError(t, err)
+		return m
+	}
+	fb := database.DataQueryFactory.NewFilter(ctx)
+
+	data, _, err := s.GetDataByValue(ctx, "ns1", []*core.JSONPathMatch{match("order.id=123")}, fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, data, 2)
+
+	data, _, err = s.GetDataByValue(ctx, "ns1", []*core.JSONPathMatch{match(`order.id="123"`)}, fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+	assert.Equal(t, d1.ID, data[0].ID)
+
+	data, _, err = s.GetDataByValue(ctx, "ns1", []*core.JSONPathMatch{match("order.id=123"), match("status=closed")}, fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+	assert.Equal(t, d2.ID, data[0].ID)
+
+	data, _, err = s.GetDataByValue(ctx, "ns1", []*core.JSONPathMatch{match("status=true")}, fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+	assert.Equal(t, d3.ID, data[0].ID)
+
+	mfb := database.MessageQueryFactory.NewFilter(ctx)
+	msgs, _, err := s.GetMessagesByDataValue(ctx, "ns1", []*core.JSONPathMatch{match("status=open")}, mfb.And())
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, m1.Header.ID, msgs[0].Header.ID)
+	assert.Equal(t, d1.ID, msgs[0].Data[0].ID)
+
+	msgs, res, err := s.GetMessagesByDataValue(ctx, "ns1", []*core.JSONPathMatch{match("order.id=123")}, mfb.And().Count(true))
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, int64(2), *res.TotalCount)
+}
+
+func TestJSONPathIndexNotSupported(t *testing.T) {
+	s, db := newMockProvider().init()
+	db.ExpectBegin()
+	db.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	db.ExpectCommit()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDataTypes, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+	err := s.UpsertDatatype(context.Background(), &core.Datatype{Namespace: "ns1", IndexPaths: fftypes.FFStringArray{"a.b"}}, false)
+	assert.NoError(t, err)
+	assert.NoError(t, db.ExpectationsWereMet())
+}
+
+func TestJSONPathIndexOutsideTransaction(t *testing.T) {
+	s, db := newMockProvider().init()
+	s.jsonPath = &sqliteGoTestProvider{}
+	db.ExpectBegin()
+	db.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	db.ExpectCommit()
+	db.ExpectExec("CREATE INDEX .*").WillReturnResult(driver.ResultNoRows)
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDataTypes, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+	err := s.UpsertDatatype(context.Background(), &core.Datatype{Namespace: "ns1", IndexPaths: fftypes.FFStringArray{"a.b"}}, false)
+	assert.NoError(t, err)
+	s.jsonPathIndexing.Wait()
+	assert.NoError(t, db.ExpectationsWereMet())
+}
+
+func TestJSONPathIndexFail(t *testing.T) {
+	s, db := newMockProvider().init()
+	s.jsonPath = &sqliteGoTestProvider{}
+	db.ExpectBegin()
+	db.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	db.ExpectCommit()
+	db.ExpectExec("CREATE INDEX .*").WillReturnError(fmt.Errorf("pop"))
+	db.ExpectExec("DROP INDEX .*").WillReturnError(fmt.Errorf("pop"))
+	db.ExpectExec("CREATE INDEX .*").WillReturnResult(driver.ResultNoRows)
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionDataTypes, core.ChangeEventTypeCreated, "ns1", mock.Anything).Return()
+	err := s.UpsertDatatype(context.Background(), &core.Datatype{Namespace: "ns1", IndexPaths: fftypes.FFStringArray{"a.b", "c"}}, false)
+	assert.NoError(t, err)
+	s.jsonPathIndexing.Wait()
+	assert.NoError(t, db.ExpectationsWereMet())
+}
+
+func TestJSONPathQueriesNotSupported(t *testing.T) {
+	s, _ := newMockProvider().init()
+	matches := []*core.JSONPathMatch{{Path: "a", Value: "b"}}
+	_, _, err := s.GetDataByValue(context.Background(), "ns1", matches, database.DataQueryFactory.NewFilter(context.Background()).And())
+	assert.Regexp(t, "FF10489", err)
+	_, _, err = s.GetMessagesByDataValue(context.Background(), "ns1", matches, database.MessageQueryFactory.NewFilter(context.Background()).And())
+	assert.Regexp(t, "FF10489", err)
+}
+
+func TestGetMessagesByDataValueBuildFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	s.jsonPath = &errJSONPathProvider{}
+	matches := []*core.JSONPathMatch{{Path: "a", Value: "b"}}
+	_, _, err := s.GetMessagesByDataValue(context.Background(), "ns1", matches, database.MessageQueryFactory.NewFilter(context.Background()).And())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGetMessagesByDataValueBadQuery(t *testing.T) {
+	s, _ := newMockProvider().init()
+	s.jsonPath = &sqliteGoTestProvider{}
+	matches := []*core.JSONPathMatch{{Path: "a", Value: "b"}}
+	f := database.MessageQueryFactory.NewFilter(context.Background()).Eq("!wrong", "")
+	_, _, err := s.GetMessagesByDataValue(context.Background(), "ns1", matches, f)
+	assert.Regexp(t, "FF00142", err)
+}
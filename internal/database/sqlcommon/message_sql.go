@@ -57,6 +57,9 @@ var (
 		"tx_parent_id",
 		"batch_id",
 		"idempotency_key",
+		"expiry",
+		"scheduled",
+		"priority",
 	}
 	msgFilterFieldMap = map[string]string{
 		"type":           "mtype",
@@ -104,6 +107,9 @@ func (s *SQLCommon) attemptMessageUpdate(ctx context.Context, tx *dbsql.TXWrappe
 			Set("tx_parent_id", txParentID).
 			Set("batch_id", message.BatchID).
 			Set("idempotency_key", message.IdempotencyKey).
+			Set("expiry", message.Header.Expiry).
+			Set("scheduled", message.Scheduled).
+			Set("priority", message.Priority).
 			Where(sq.Eq{
 				"id":              message.Header.ID,
 				"hash":            message.Hash,
@@ -147,6 +153,9 @@ func (s *SQLCommon) setMessageInsertValues(query sq.InsertBuilder, message *core
 		txParentID,
 		message.BatchID,
 		message.IdempotencyKey,
+		message.Header.Expiry,
+		message.Scheduled,
+		message.Priority,
 	)
 }
 
@@ -461,6 +470,9 @@ func (s *SQLCommon) msgResult(ctx context.Context, row *sql.Rows) (*core.Message
 		&txParent.ID,
 		&msg.BatchID,
 		&msg.IdempotencyKey,
+		&msg.Header.Expiry,
+		&msg.Scheduled,
+		&msg.Priority,
 		// Must be added to the list of columns in all selects
 		&msg.Sequence,
 	)
@@ -609,6 +621,36 @@ func (s *SQLCommon) GetMessages(ctx context.Context, namespace string, filter ff
 	return s.getMessagesQuery(ctx, namespace, query, fop, fi, true)
 }
 
+func (s *SQLCommon) GetMessagesByDataValue(ctx context.Context, namespace string, matches []*core.JSONPathMatch, filter ffapi.Filter) (message []*core.Message, fr *ffapi.FilterResult, err error) {
+	conditions, err := s.jsonPathConditions(ctx, "d.value", matches)
+	if err != nil {
+		return nil, nil, err
+	}
+	dataQuery := sq.Select("md.message_id").
+		From("messages_data AS md").
+		Join("data AS d ON d.id = md.data_id").
+		Where(sq.Eq{"md.namespace": namespace, "d.namespace": namespace})
+	for _, c := range conditions {
+		dataQuery = dataQuery.Where(c)
+	}
+	dataSQL, dataArgs, err := dataQuery.ToSql()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cols := append([]string{}, msgColumns...)
+	cols = append(cols, s.SequenceColumn())
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(cols...).From(messagesTable), filter, msgFilterFieldMap,
+		[]interface{}{
+			&ffapi.SortField{Field: "confirmed", Descending: true, Nulls: ffapi.NullsFirst},
+			&ffapi.SortField{Field: "created", Descending: true},
+		}, sq.Eq{"namespace_local": namespace}, sq.Expr(fmt.Sprintf("id IN (%s)", dataSQL), dataArgs...))
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.getMessagesQuery(ctx, namespace, query, fop, fi, true)
+}
+
 func (s *SQLCommon) GetMessagesForData(ctx context.Context, namespace string, dataID *fftypes.UUID, filter ffapi.Filter) (message []*core.Message, fr *ffapi.FilterResult, err error) {
 	cols := make([]string, len(msgColumns)+1)
 	for i, col := range msgColumns {
@@ -628,31 +670,58 @@ func (s *SQLCommon) GetMessagesForData(ctx context.Context, namespace string, da
 }
 
 func (s *SQLCommon) UpdateMessage(ctx context.Context, namespace string, msgid *fftypes.UUID, update ffapi.Update) (err error) {
-	return s.UpdateMessages(ctx, namespace, database.MessageQueryFactory.NewFilter(ctx).Eq("id", msgid), update)
+	_, err = s.UpdateMessages(ctx, namespace, database.MessageQueryFactory.NewFilter(ctx).Eq("id", msgid), update)
+	return err
 }
 
-func (s *SQLCommon) UpdateMessages(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) (err error) {
+func (s *SQLCommon) UpdateMessages(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) (updated int64, err error) {
 
 	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer s.RollbackTx(ctx, tx, autoCommit)
 
 	query, err := s.BuildUpdate(sq.Update(messagesTable).Where(sq.Eq{"namespace_local": namespace}), update, msgFilterFieldMap)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	query, err = s.FilterUpdate(ctx, query, filter, msgFilterFieldMap)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	_, err = s.UpdateTx(ctx, messagesTable, tx, query, nil /* no change events filter based update */)
+	updated, err = s.UpdateTx(ctx, messagesTable, tx, query, nil /* no change events filter based update */)
+	if err != nil {
+		return 0, err
+	}
+
+	return updated, s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) DeleteMessages(ctx context.Context, namespace string, ids []*fftypes.UUID) error {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
 	if err != nil {
 		return err
 	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, messagesDataJoinTable, tx, sq.Delete(messagesDataJoinTable).Where(sq.Eq{
+		"namespace":  namespace,
+		"message_id": ids,
+	}), nil)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
+	err = s.DeleteTx(ctx, messagesTable, tx, sq.Delete(messagesTable).Where(sq.Eq{
+		"namespace": namespace,
+		"id":        ids,
+	}), nil)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
 
 	return s.CommitTx(ctx, tx, autoCommit)
 }
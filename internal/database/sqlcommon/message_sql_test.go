@@ -117,6 +117,7 @@ func TestUpsertE2EWithDB(t *testing.T) {
 				Type: core.TransactionTypeTokenTransfer,
 				ID:   fftypes.NewUUID(),
 			},
+			Expiry: fftypes.Now(),
 		},
 		Hash:           fftypes.NewRandB32(),
 		Pins:           []string{fftypes.NewRandB32().String(), fftypes.NewRandB32().String()},
@@ -124,6 +125,8 @@ func TestUpsertE2EWithDB(t *testing.T) {
 		Confirmed:      fftypes.Now(),
 		BatchID:        bid,
 		IdempotencyKey: "myBusinessIdentifier",
+		Scheduled:      fftypes.Now(),
+		Priority:       core.MessagePriorityHigh,
 		Data: []*core.DataRef{
 			{ID: dataID1, Hash: rand1},
 			{ID: dataID2, Hash: rand2}, // Note the data refs cannot change, as it would affect the hash, and the hash is immutable
@@ -167,6 +170,9 @@ func TestUpsertE2EWithDB(t *testing.T) {
 		fb.Eq("idempotencykey", msgUpdated.IdempotencyKey),
 		fb.Gt("created", "0"),
 		fb.Gt("confirmed", "0"),
+		fb.Gt("expiry", "0"),
+		fb.Gt("scheduled", "0"),
+		fb.Eq("priority", core.MessagePriorityHigh),
 	)
 	msgs, res, err := s.GetMessages(ctx, "ns12345", filter.Count(true))
 	assert.NoError(t, err)
@@ -228,6 +234,14 @@ func TestUpsertE2EWithDB(t *testing.T) {
 	assert.Equal(t, 1, len(msgs))
 	assert.Equal(t, *bid2, *msgs[0].BatchID)
 
+	// Update with a filter that matches nothing
+	updated, err := s.UpdateMessages(ctx, "ns12345", fb.And(fb.Eq("id", msgID), fb.Eq("state", core.MessageStateCancelled)), up)
+	assert.NoError(t, err)
+	assert.Zero(t, updated)
+	updated, err = s.UpdateMessages(ctx, "ns12345", fb.And(fb.Eq("id", msgID)), up)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+
 	// Bump and Update - this is for a ready transition
 	msgUpdated.State = core.MessageStateReady
 	err = s.ReplaceMessage(context.Background(), msgUpdated)
@@ -248,6 +262,13 @@ func TestUpsertE2EWithDB(t *testing.T) {
 	assert.Equal(t, 1, len(msgs))
 	assert.Equal(t, *msgID, *msgs[0].Header.ID)
 
+	// Delete the message, along with its data references
+	err = s.DeleteMessages(ctx, "ns12345", []*fftypes.UUID{msgID})
+	assert.NoError(t, err)
+	msgRead, err = s.GetMessageByID(ctx, "ns12345", msgID)
+	assert.NoError(t, err)
+	assert.Nil(t, msgRead)
+
 	s.callbacks.AssertExpectations(t)
 }
 
@@ -576,7 +597,7 @@ func TestGetMessageByIDLoadRefsFail(t *testing.T) {
 	cols := append([]string{}, msgColumns...)
 	cols = append(cols, "id()")
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(cols).
-		AddRow(msgID.String(), nil, core.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "", "pin", nil, "", nil, nil, "bob", 0))
+		AddRow(msgID.String(), nil, core.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "", "pin", nil, "", nil, nil, "bob", nil, nil, "", 0))
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	_, err := s.GetMessageByID(context.Background(), "ns1", msgID)
 	assert.Regexp(t, "FF00176", err)
@@ -623,7 +644,7 @@ func TestGetMessagesLoadRefsFail(t *testing.T) {
 	cols := append([]string{}, msgColumns...)
 	cols = append(cols, "id()")
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(cols).
-		AddRow(msgID.String(), nil, core.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "", "pin", nil, "", nil, nil, "bob", 0))
+		AddRow(msgID.String(), nil, core.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "", "pin", nil, "", nil, nil, "bob", nil, nil, "", 0))
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	f := database.MessageQueryFactory.NewFilter(context.Background()).Gt("confirmed", "0")
 	_, _, err := s.GetMessages(context.Background(), "ns1", f)
@@ -678,7 +699,7 @@ func TestMessagesUpdateBuildFilterFail(t *testing.T) {
 	mock.ExpectBegin()
 	f := database.MessageQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
 	u := database.MessageQueryFactory.NewUpdate(context.Background()).Set("type", core.MessageTypeBroadcast)
-	err := s.UpdateMessages(context.Background(), "ns1", f, u)
+	_, err := s.UpdateMessages(context.Background(), "ns1", f, u)
 	assert.Regexp(t, "FF00143.*id", err)
 }
 
@@ -709,3 +730,43 @@ func TestGetBatchIDsForMessagesScanFail(t *testing.T) {
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestDeleteMessagesFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteMessages(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMessagesFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(driver.RowsAffected(1))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteMessages(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMessagesNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(driver.RowsAffected(1))
+	mock.ExpectExec("DELETE .*").WillReturnResult(driver.RowsAffected(0))
+	mock.ExpectCommit()
+	err := s.DeleteMessages(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMessagesFailDeleteRefs(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteMessages(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -34,6 +34,7 @@ var (
 		"description",
 		"created",
 		"firefly_contracts",
+		"definition",
 	}
 )
 
@@ -69,6 +70,7 @@ func (s *SQLCommon) UpsertNamespace(ctx context.Context, namespace *core.Namespa
 				Set("description", namespace.Description).
 				Set("created", namespace.Created).
 				Set("firefly_contracts", namespace.Contracts).
+				Set("definition", namespace.Definition).
 				Where(sq.Eq{"name": namespace.Name}),
 			nil,
 		); err != nil {
@@ -84,6 +86,7 @@ func (s *SQLCommon) UpsertNamespace(ctx context.Context, namespace *core.Namespa
 					namespace.Description,
 					namespace.Created,
 					namespace.Contracts,
+					namespace.Definition,
 				),
 			nil,
 		); err != nil {
@@ -102,6 +105,7 @@ func (s *SQLCommon) namespaceResult(ctx context.Context, row *sql.Rows) (*core.N
 		&namespace.Description,
 		&namespace.Created,
 		&namespace.Contracts,
+		&namespace.Definition,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, namespacesTable)
@@ -136,3 +140,26 @@ func (s *SQLCommon) getNamespaceEq(ctx context.Context, eq sq.Eq, textName strin
 func (s *SQLCommon) GetNamespace(ctx context.Context, name string) (message *core.Namespace, err error) {
 	return s.getNamespaceEq(ctx, sq.Eq{"name": name}, name)
 }
+
+func (s *SQLCommon) GetDynamicNamespaces(ctx context.Context) (namespaces []*core.Namespace, err error) {
+	rows, _, err := s.Query(ctx, namespacesTable,
+		sq.Select(namespaceColumns...).
+			From(namespacesTable).
+			Where(sq.NotEq{"definition": nil}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	namespaces = []*core.Namespace{}
+	for rows.Next() {
+		namespace, err := s.namespaceResult(ctx, rows)
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, namespace)
+	}
+
+	return namespaces, nil
+}
@@ -59,12 +59,21 @@ func TestNamespacesE2EWithDB(t *testing.T) {
 	namespaceReadJson, _ := json.Marshal(&namespaceRead)
 	assert.Equal(t, string(namespaceJson), string(namespaceReadJson))
 
+	// Not returned as a dynamic namespace, as it has no definition
+	dynamicNamespaces, err := s.GetDynamicNamespaces(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, dynamicNamespaces)
+
 	// Update the namespace (this is testing what's possible at the database layer,
 	// and does not account for the verification that happens at the higher level)
 	namespaceUpdated := &core.Namespace{
 		Name:        "namespace1",
 		Description: "description1",
 		Created:     fftypes.Now(),
+		Definition: &core.NamespaceDefinition{
+			Name:    "namespace1",
+			Plugins: []string{"database1"},
+		},
 	}
 	err = s.UpsertNamespace(context.Background(), namespaceUpdated, true)
 	assert.NoError(t, err)
@@ -76,6 +85,12 @@ func TestNamespacesE2EWithDB(t *testing.T) {
 	namespaceReadJson, _ = json.Marshal(&namespaceRead)
 	assert.Equal(t, string(namespaceJson), string(namespaceReadJson))
 
+	// Now returned as a dynamic namespace
+	dynamicNamespaces, err = s.GetDynamicNamespaces(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, dynamicNamespaces, 1)
+	assert.Equal(t, []string{"database1"}, dynamicNamespaces[0].Definition.Plugins)
+
 	s.callbacks.AssertExpectations(t)
 }
 
@@ -155,3 +170,19 @@ func TestGetNamespaceByNameScanFail(t *testing.T) {
 	assert.Regexp(t, "FF10121", err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetDynamicNamespacesSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetDynamicNamespaces(context.Background())
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDynamicNamespacesScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("only one"))
+	_, err := s.GetDynamicNamespaces(context.Background())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
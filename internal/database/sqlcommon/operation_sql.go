@@ -45,6 +45,7 @@ var (
 		"input",
 		"output",
 		"retry_id",
+		"policy",
 	}
 	opFilterFieldMap = map[string]string{
 		"tx":     "tx_id",
@@ -70,6 +71,7 @@ func (s *SQLCommon) setOperationInsertValues(query sq.InsertBuilder, operation *
 		operation.Input,
 		operation.Output,
 		operation.Retry,
+		operation.Policy,
 	)
 }
 
@@ -156,6 +158,7 @@ func (s *SQLCommon) opResult(ctx context.Context, row *sql.Rows) (*core.Operatio
 		&op.Input,
 		&op.Output,
 		&op.Retry,
+		&op.Policy,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, operationsTable)
@@ -247,3 +250,21 @@ func (s *SQLCommon) UpdateOperation(ctx context.Context, ns string, id *fftypes.
 	}
 	return ra > 0, s.CommitTx(ctx, tx, autoCommit)
 }
+
+func (s *SQLCommon) DeleteOperations(ctx context.Context, namespace string, ids []*fftypes.UUID) error {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, operationsTable, tx, sq.Delete(operationsTable).Where(sq.Eq{
+		"namespace": namespace,
+		"id":        ids,
+	}), nil)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
@@ -18,6 +18,7 @@ package sqlcommon
 
 import (
 	"context"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -49,6 +50,9 @@ func TestOperationE2EWithDB(t *testing.T) {
 		Output:      fftypes.JSONObject{"some": "output-info"},
 		Created:     fftypes.Now(),
 		Updated:     fftypes.Now(),
+		Policy: core.OperationPolicyLog{
+			{Action: core.OpPolicyActionTimeoutFailed, Time: fftypes.Now()},
+		},
 	}
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeCreated, "ns1", operationID).Return()
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionOperations, core.ChangeEventTypeUpdated, "ns1", operationID).Return()
@@ -100,6 +104,9 @@ func TestOperationE2EWithDB(t *testing.T) {
 	update := database.OperationQueryFactory.NewUpdate(ctx).S()
 	update.Set("status", core.OpStatusFailed)
 	update.Set("error", errMsg)
+	policy := append(operation.Policy, &core.OperationPolicyDecision{Action: core.OpPolicyActionRetryExhausted, Time: fftypes.Now()})
+	policyJSON, _ := policy.Value()
+	update.Set("policy", policyJSON)
 	updated, err := s.UpdateOperation(ctx, operation.Namespace, operation.ID, nil, update)
 	assert.True(t, updated)
 	assert.NoError(t, err)
@@ -115,10 +122,12 @@ func TestOperationE2EWithDB(t *testing.T) {
 		fb.Eq("id", operation.ID.String()),
 		fb.Eq("status", core.OpStatusFailed),
 		fb.Eq("error", "FF10143"),
+		fb.Eq("retry", nil),
 	)
 	operations, _, err = s.GetOperations(ctx, "ns1", filter)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(operations))
+	assert.Equal(t, core.OpPolicyActionRetryExhausted, operations[0].Policy.Last().Action)
 
 	s.callbacks.AssertExpectations(t)
 }
@@ -306,3 +315,31 @@ func TestInsertOperationsSingleRowFail(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 	s.callbacks.AssertExpectations(t)
 }
+
+func TestDeleteOperationsFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteOperations(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteOperationsFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteOperations(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteOperationsNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(driver.RowsAffected(0))
+	mock.ExpectCommit()
+	err := s.DeleteOperations(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -19,8 +19,10 @@ package sqlcommon
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 
 	sq "github.com/Masterminds/squirrel"
@@ -98,6 +100,14 @@ func (tp *sqliteGoTestProvider) ApplyInsertQueryCustomizations(insert sq.InsertB
 	return insert, false
 }
 
+func (tp *sqliteGoTestProvider) JSONPathCondition(column string, path []string, candidates []interface{}) sq.Sqlizer {
+	return sq.Eq{fmt.Sprintf("json_extract(%s, '$.%s')", column, strings.Join(path, ".")): candidates}
+}
+
+func (tp *sqliteGoTestProvider) JSONPathIndex(name, table, column string, path []string) string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (json_extract(%s, '$.%s'));", name, table, column, strings.Join(path, "."))
+}
+
 func (tp *sqliteGoTestProvider) Open(url string) (*sql.DB, error) {
 	return sql.Open("sqlite3", url)
 }
@@ -0,0 +1,207 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// ReadReplicaProvider is implemented by database providers that support routing
+// non-transactional reads to a separate read replica of the database
+type ReadReplicaProvider interface {
+	// ReplicationLagQuery returns a query returning a single row/column, containing the
+	// current replication lag of the replica in (fractional) seconds
+	ReplicationLagQuery() string
+}
+
+type readReplica struct {
+	db            *sql.DB
+	lagQuery      string
+	maxLag        time.Duration
+	checkInterval time.Duration
+	available     atomic.Bool
+	cancelCtx     context.CancelFunc
+	monitorDone   chan struct{}
+}
+
+func (s *SQLCommon) initReadReplica(ctx context.Context, provider dbsql.Provider, conf config.Section) (err error) {
+	rp, ok := provider.(ReadReplicaProvider)
+	if !ok || conf.GetString(SQLConfReadReplicaURL) == "" {
+		return nil
+	}
+
+	rr := &readReplica{
+		lagQuery:      rp.ReplicationLagQuery(),
+		maxLag:        conf.GetDuration(SQLConfReadReplicaMaxLag),
+		checkInterval: conf.GetDuration(SQLConfReadReplicaLagCheckInterval),
+		monitorDone:   make(chan struct{}),
+	}
+	if rr.db, err = provider.Open(conf.GetString(SQLConfReadReplicaURL)); err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgDBReadReplicaInitFailed)
+	}
+	connLimit := conf.GetInt(SQLConfReadReplicaMaxConnections)
+	if connLimit <= 0 {
+		connLimit = s.ConnLimit()
+	}
+	if connLimit > 0 {
+		rr.db.SetMaxOpenConns(connLimit)
+		rr.db.SetMaxIdleConns(connLimit)
+		rr.db.SetConnMaxIdleTime(conf.GetDuration(SQLConfMaxConnIdleTime))
+		rr.db.SetConnMaxLifetime(conf.GetDuration(SQLConfMaxConnLifetime))
+	}
+
+	// Check the lag once synchronously, so reads are routed correctly from the start
+	rr.checkLag(ctx)
+
+	var monitorCtx context.Context
+	monitorCtx, rr.cancelCtx = context.WithCancel(log.WithLogField(ctx, "dbreplica", provider.Name()))
+	go rr.lagMonitor(monitorCtx)
+	s.replica = rr
+	return nil
+}
+
+func (rr *readReplica) lagMonitor(ctx context.Context) {
+	defer close(rr.monitorDone)
+	ticker := time.NewTicker(rr.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rr.checkLag(ctx)
+		case <-ctx.Done():
+			log.L(ctx).Debugf("Read replica lag monitor exiting")
+			return
+		}
+	}
+}
+
+func (rr *readReplica) checkLag(ctx context.Context) {
+	var lagSeconds float64
+	err := rr.db.QueryRowContext(ctx, rr.lagQuery).Scan(&lagSeconds)
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	available := err == nil && lag <= rr.maxLag
+	if rr.available.Swap(available) != available {
+		switch {
+		case err != nil:
+			log.L(ctx).Warnf("Read replica unavailable - routing reads to primary: %s", err)
+		case !available:
+			log.L(ctx).Warnf("Read replica lag %s exceeds maximum %s - routing reads to primary", lag, rr.maxLag)
+		default:
+			log.L(ctx).Infof("Read replica available (lag=%s) - routing non-transactional reads to replica", lag)
+		}
+	}
+}
+
+func (rr *readReplica) close() {
+	rr.cancelCtx()
+	<-rr.monitorDone
+	_ = rr.db.Close()
+}
+
+// replicaForRead returns the read replica only when one is configured and within the
+// maximum lag, the context has opted into replica reads, and the caller is not inside a
+// transaction (including a RunAsGroup). Reads within a transaction must go to the primary,
+// to see the writes of that transaction.
+func (s *SQLCommon) replicaForRead(ctx context.Context, tx *dbsql.TXWrapper) *readReplica {
+	if s.replica == nil || tx != nil || dbsql.GetTXFromContext(ctx) != nil ||
+		!database.ReadReplicaAllowed(ctx) || !s.replica.available.Load() {
+		return nil
+	}
+	return s.replica
+}
+
+// Query overrides the query function of the embedded database, to route non-transactional
+// queries to the read replica when available. Any failure on the replica marks it
+// unavailable until the next successful lag check, and the query is retried on the primary.
+func (s *SQLCommon) Query(ctx context.Context, table string, q sq.SelectBuilder) (*sql.Rows, *dbsql.TXWrapper, error) {
+	if rr := s.replicaForRead(ctx, nil); rr != nil {
+		sqlQuery, args, err := q.PlaceholderFormat(s.Features().PlaceholderFormat).ToSql()
+		if err != nil {
+			return nil, nil, i18n.WrapError(ctx, err, i18n.MsgDBQueryBuildFailed)
+		}
+		before := time.Now()
+		log.L(ctx).Tracef(`SQL-> replica query: %s (args: %+v)`, sqlQuery, args)
+		rows, err := rr.db.QueryContext(ctx, sqlQuery, args...)
+		if err == nil {
+			log.L(ctx).Debugf(`SQL<- replica query %s (%.2fms)`, table, floatMillisSince(before))
+			return rows, nil, nil
+		}
+		log.L(ctx).Warnf(`SQL replica query failed - retrying on primary: %s sql=[ %s ]`, err, sqlQuery)
+		rr.available.Store(false)
+	}
+	return s.Database.Query(ctx, table, q)
+}
+
+// QueryRes overrides the function of the embedded database, so that counts for
+// non-transactional queries are also served by the read replica when available.
+func (s *SQLCommon) QueryRes(ctx context.Context, table string, tx *dbsql.TXWrapper, fop sq.Sqlizer, qm dbsql.QueryModifier, fi *ffapi.FilterInfo) *ffapi.FilterResult {
+	rr := s.replicaForRead(ctx, tx)
+	if rr == nil || !fi.Count {
+		return s.Database.QueryRes(ctx, table, tx, fop, qm, fi)
+	}
+	count, err := s.replicaCount(ctx, rr, table, fop, qm, fi.CountExpr)
+	if err != nil {
+		log.L(ctx).Warnf("Unable to return count for query from replica - retrying on primary: %s", err)
+		return s.Database.QueryRes(ctx, table, tx, fop, qm, fi)
+	}
+	return &ffapi.FilterResult{TotalCount: &count}
+}
+
+func (s *SQLCommon) replicaCount(ctx context.Context, rr *readReplica, table string, fop sq.Sqlizer, qm dbsql.QueryModifier, countExpr string) (count int64, err error) {
+	if countExpr == "" {
+		countExpr = "*"
+	}
+	q := sq.Select(fmt.Sprintf("COUNT(%s)", countExpr)).From(table).Where(fop)
+	if qm != nil {
+		if q, err = qm(q); err != nil {
+			return -1, err
+		}
+	}
+	sqlQuery, args, err := q.PlaceholderFormat(s.Features().PlaceholderFormat).ToSql()
+	if err != nil {
+		return -1, i18n.WrapError(ctx, err, i18n.MsgDBQueryBuildFailed)
+	}
+	if err = rr.db.QueryRowContext(ctx, sqlQuery, args...).Scan(&count); err != nil {
+		return -1, i18n.WrapError(ctx, err, i18n.MsgDBQueryFailed)
+	}
+	return count, nil
+}
+
+// Close closes the read replica (if configured) as well as the primary database
+func (s *SQLCommon) Close() {
+	if s.replica != nil {
+		s.replica.close()
+	}
+	s.Database.Close()
+}
+
+func floatMillisSince(t time.Time) float64 {
+	return float64(time.Since(t)) / float64(time.Millisecond)
+}
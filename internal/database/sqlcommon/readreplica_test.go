@@ -0,0 +1,240 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockReplicaProvider struct {
+	*mockProvider
+	replicaDB *sql.DB
+	openErr   error
+}
+
+func (mrp *mockReplicaProvider) ReplicationLagQuery() string {
+	return "SELECT lag"
+}
+
+func (mrp *mockReplicaProvider) Open(url string) (*sql.DB, error) {
+	return mrp.replicaDB, mrp.openErr
+}
+
+func newMockReplicaProvider(t *testing.T) (*mockProvider, sqlmock.Sqlmock, *mockReplicaProvider, sqlmock.Sqlmock) {
+	mp := newMockProvider()
+	mp.SQLCommon.InitReadReplicaConfig(mp.config)
+	s, mock := mp.init()
+	mrp := &mockReplicaProvider{mockProvider: s}
+	var rmock sqlmock.Sqlmock
+	mrp.replicaDB, rmock, _ = sqlmock.New()
+	s.config.Set(SQLConfReadReplicaURL, "replica")
+	s.config.Set(SQLConfReadReplicaMaxLag, "1s")
+	s.config.Set(SQLConfReadReplicaLagCheckInterval, "1h")
+	return s, mock, mrp, rmock
+}
+
+func TestInitReadReplicaNotConfigured(t *testing.T) {
+	s, _ := newMockProvider().init()
+	err := s.initReadReplica(context.Background(), s, s.config)
+	assert.NoError(t, err)
+	assert.Nil(t, s.replica)
+}
+
+func TestInitReadReplicaOpenFail(t *testing.T) {
+	s, _, mrp, _ := newMockReplicaProvider(t)
+	mrp.openErr = fmt.Errorf("pop")
+	err := s.initReadReplica(context.Background(), mrp, s.config)
+	assert.Regexp(t, "FF10479.*pop", err)
+}
+
+func TestReadReplicaQueryRouting(t *testing.T) {
+	s, mock, mrp, rmock := newMockReplicaProvider(t)
+	rmock.ExpectQuery("SELECT lag").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0.5))
+	err := s.initReadReplica(context.Background(), mrp, s.config)
+	assert.NoError(t, err)
+	assert.True(t, s.replica.available.Load())
+
+	// Without the context marker, reads go to the primary
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, _, err = s.GetTokenBalances(context.Background(), "ns1", database.TokenBalanceQueryFactory.NewFilter(context.Background()).And())
+	assert.NoError(t, err)
+
+	// With the context marker, reads (and counts) go to the replica
+	ctx := database.WithReadReplica(context.Background())
+	rmock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(tokenBalanceColumns))
+	rmock.ExpectQuery("SELECT COUNT.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+	_, res, err := s.GetTokenBalances(ctx, "ns1", database.TokenBalanceQueryFactory.NewFilter(ctx).Count(true).And())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), *res.TotalCount)
+
+	// Within a transaction, reads go to the primary
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+	err = s.RunAsGroup(ctx, func(ctx context.Context) error {
+		_, _, err := s.GetTokenBalances(ctx, "ns1", database.TokenBalanceQueryFactory.NewFilter(ctx).And())
+		return err
+	})
+	assert.NoError(t, err)
+
+	rmock.ExpectClose()
+	mock.ExpectClose()
+	s.Close()
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, rmock.ExpectationsWereMet())
+}
+
+func TestReadReplicaQueryFallback(t *testing.T) {
+	s, mock, mrp, rmock := newMockReplicaProvider(t)
+	rmock.ExpectQuery("SELECT lag").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0))
+	err := s.initReadReplica(context.Background(), mrp, s.config)
+	assert.NoError(t, err)
+	defer s.replica.close()
+
+	ctx := database.WithReadReplica(context.Background())
+	rmock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, _, err = s.GetTokenBalances(ctx, "ns1", database.TokenBalanceQueryFactory.NewFilter(ctx).And())
+	assert.NoError(t, err)
+	assert.False(t, s.replica.available.Load())
+
+	// Subsequent reads go straight to the primary
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, _, err = s.GetTokenBalances(ctx, "ns1", database.TokenBalanceQueryFactory.NewFilter(ctx).And())
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, rmock.ExpectationsWereMet())
+}
+
+func TestReadReplicaQueryBuildFail(t *testing.T) {
+	s, _, mrp, rmock := newMockReplicaProvider(t)
+	rmock.ExpectQuery("SELECT lag").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0))
+	err := s.initReadReplica(context.Background(), mrp, s.config)
+	assert.NoError(t, err)
+	defer s.replica.close()
+
+	ctx := database.WithReadReplica(context.Background())
+	_, _, err = s.Query(ctx, "table1", sq.Select())
+	assert.Regexp(t, "FF00174", err)
+}
+
+func TestReadReplicaCountFallback(t *testing.T) {
+	s, mock, mrp, rmock := newMockReplicaProvider(t)
+	rmock.ExpectQuery("SELECT lag").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0))
+	err := s.initReadReplica(context.Background(), mrp, s.config)
+	assert.NoError(t, err)
+	defer s.replica.close()
+
+	ctx := database.WithReadReplica(context.Background())
+	rmock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(tokenBalanceColumns))
+	rmock.ExpectQuery("SELECT COUNT.*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectQuery("SELECT COUNT.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	_, res, err := s.GetTokenBalances(ctx, "ns1", database.TokenBalanceQueryFactory.NewFilter(ctx).Count(true).And())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), *res.TotalCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, rmock.ExpectationsWereMet())
+}
+
+func TestReadReplicaCountQueryModifierFail(t *testing.T) {
+	s, _, mrp, rmock := newMockReplicaProvider(t)
+	rmock.ExpectQuery("SELECT lag").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0))
+	err := s.initReadReplica(context.Background(), mrp, s.config)
+	assert.NoError(t, err)
+	defer s.replica.close()
+
+	_, err = s.replicaCount(context.Background(), s.replica, "table1", sq.Eq{}, func(sb sq.SelectBuilder) (sq.SelectBuilder, error) {
+		return sb, fmt.Errorf("pop")
+	}, "")
+	assert.Regexp(t, "pop", err)
+}
+
+type errSqlizer struct{}
+
+func (errSqlizer) ToSql() (string, []interface{}, error) {
+	return "", nil, fmt.Errorf("pop")
+}
+
+func TestReadReplicaCountBuildFail(t *testing.T) {
+	s, _, mrp, rmock := newMockReplicaProvider(t)
+	rmock.ExpectQuery("SELECT lag").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0))
+	err := s.initReadReplica(context.Background(), mrp, s.config)
+	assert.NoError(t, err)
+	defer s.replica.close()
+
+	_, err = s.replicaCount(context.Background(), s.replica, "table1", errSqlizer{}, nil, "")
+	assert.Regexp(t, "FF00174", err)
+}
+
+func TestInitPrimaryFail(t *testing.T) {
+	mp := newMockProvider()
+	mp.openError = fmt.Errorf("pop")
+	err := mp.Init(context.Background(), mp, mp.config, mp.capabilities)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestReadReplicaLagCheck(t *testing.T) {
+	s, _, mrp, rmock := newMockReplicaProvider(t)
+	rmock.ExpectQuery("SELECT lag").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(5))
+	err := s.initReadReplica(context.Background(), mrp, s.config)
+	assert.NoError(t, err)
+	defer s.replica.close()
+	assert.False(t, s.replica.available.Load())
+
+	ctx := database.WithReadReplica(context.Background())
+	assert.Nil(t, s.replicaForRead(ctx, nil))
+
+	rmock.ExpectQuery("SELECT lag").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0.1))
+	s.replica.checkLag(ctx)
+	assert.NotNil(t, s.replicaForRead(ctx, nil))
+	assert.Nil(t, s.replicaForRead(ctx, &dbsql.TXWrapper{}))
+
+	rmock.ExpectQuery("SELECT lag").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(2))
+	s.replica.checkLag(ctx)
+	assert.Nil(t, s.replicaForRead(ctx, nil))
+
+	rmock.ExpectQuery("SELECT lag").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0))
+	s.replica.checkLag(ctx)
+	rmock.ExpectQuery("SELECT lag").WillReturnError(fmt.Errorf("pop"))
+	s.replica.checkLag(ctx)
+	assert.Nil(t, s.replicaForRead(ctx, nil))
+	assert.NoError(t, rmock.ExpectationsWereMet())
+}
+
+func TestReadReplicaLagMonitor(t *testing.T) {
+	s, _, mrp, rmock := newMockReplicaProvider(t)
+	s.config.Set(SQLConfReadReplicaLagCheckInterval, "1ms")
+	rmock.MatchExpectationsInOrder(false)
+	rmock.ExpectQuery("SELECT lag").WillReturnError(fmt.Errorf("pop"))
+	rmock.ExpectQuery("SELECT lag").WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0))
+	err := s.initReadReplica(context.Background(), mrp, s.config)
+	assert.NoError(t, err)
+	for rmock.ExpectationsWereMet() != nil {
+		time.Sleep(1 * time.Millisecond)
+	}
+	s.replica.close()
+}
@@ -34,6 +34,11 @@ type SQLCommon struct {
 	dbsql.Database
 	capabilities *database.Capabilities
 	callbacks    callbacks
+	replica      *readReplica
+	jsonPath     JSONPathProvider
+
+	jsonPathIndexLock sync.Mutex
+	jsonPathIndexing  sync.WaitGroup
 }
 
 type callbacks struct {
@@ -79,7 +84,11 @@ func (cb *callbacks) HashCollectionNSEvent(resType database.HashCollectionNS, ev
 
 func (s *SQLCommon) Init(ctx context.Context, provider dbsql.Provider, config config.Section, capabilities *database.Capabilities) (err error) {
 	s.capabilities = capabilities
-	return s.Database.Init(ctx, provider, config)
+	if err = s.Database.Init(ctx, provider, config); err != nil {
+		return err
+	}
+	s.jsonPath, _ = provider.(JSONPathProvider)
+	return s.initReadReplica(ctx, provider, config)
 }
 
 func (s *SQLCommon) SetHandler(namespace string, handler database.Callbacks) {
@@ -96,3 +105,7 @@ func (s *SQLCommon) SetHandler(namespace string, handler database.Callbacks) {
 }
 
 func (s *SQLCommon) Capabilities() *database.Capabilities { return s.capabilities }
+
+func (s *SQLCommon) CheckHealth(ctx context.Context, _ string) (*core.EventStreamHealth, error) {
+	return nil, s.DB().PingContext(ctx)
+}
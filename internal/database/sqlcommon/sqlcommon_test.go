@@ -46,6 +46,15 @@ func TestMigrationUpDown(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCheckHealth(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+
+	streamHealth, err := s.CheckHealth(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.Nil(t, streamHealth)
+}
+
 func TestTXConcurrency(t *testing.T) {
 	s, cleanup := newSQLiteTestProvider(t)
 	defer cleanup()
@@ -49,7 +49,7 @@ var (
 	}
 )
 
-func (s *SQLCommon) addTokenBalance(ctx context.Context, tx *dbsql.TXWrapper, transfer *core.TokenTransfer, key string, negate bool) error {
+func (s *SQLCommon) addTokenBalance(ctx context.Context, tx *dbsql.TXWrapper, transfer *core.TokenTransfer, key string, negate bool, timestamp *fftypes.FFTime) error {
 	balance, err := s.GetTokenBalance(ctx, transfer.Namespace, transfer.Pool, transfer.TokenIndex, key)
 	if err != nil {
 		return err
@@ -103,7 +103,7 @@ func (s *SQLCommon) addTokenBalance(ctx context.Context, tx *dbsql.TXWrapper, tr
 		}
 	}
 
-	return nil
+	return s.insertTokenBalanceChange(ctx, tx, transfer, key, negate, total, timestamp)
 }
 
 func (s *SQLCommon) UpdateTokenBalances(ctx context.Context, transfer *core.TokenTransfer) (err error) {
@@ -113,13 +113,17 @@ func (s *SQLCommon) UpdateTokenBalances(ctx context.Context, transfer *core.Toke
 	}
 	defer s.RollbackTx(ctx, tx, autoCommit)
 
+	timestamp, err := s.tokenTransferTimestamp(ctx, transfer)
+	if err != nil {
+		return err
+	}
 	if transfer.From != "" {
-		if err := s.addTokenBalance(ctx, tx, transfer, transfer.From, true); err != nil {
+		if err := s.addTokenBalance(ctx, tx, transfer, transfer.From, true, timestamp); err != nil {
 			return err
 		}
 	}
 	if transfer.To != "" {
-		if err := s.addTokenBalance(ctx, tx, transfer, transfer.To, false); err != nil {
+		if err := s.addTokenBalance(ctx, tx, transfer, transfer.To, false, timestamp); err != nil {
 			return err
 		}
 	}
@@ -275,5 +279,13 @@ func (s *SQLCommon) DeleteTokenBalances(ctx context.Context, namespace string, p
 		return err
 	}
 
+	err = s.DeleteTx(ctx, tokenbalancehistoryTable, tx, sq.Delete(tokenbalancehistoryTable).Where(sq.Eq{
+		"namespace": namespace,
+		"pool_id":   poolID,
+	}), nil)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
 	return s.CommitTx(ctx, tx, autoCommit)
 }
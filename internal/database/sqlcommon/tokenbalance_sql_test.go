@@ -45,6 +45,8 @@ func TestTokenBalanceE2EWithDB(t *testing.T) {
 		Namespace:  "ns1",
 		To:         "0x0",
 		Amount:     *fftypes.NewFFBigInt(10),
+		ProtocolID: "1",
+		Created:    fftypes.UnixTime(1700000000),
 	}
 	balance := &core.TokenBalance{
 		Pool:       transfer.Pool,
@@ -85,10 +87,21 @@ func TestTokenBalanceE2EWithDB(t *testing.T) {
 	balanceReadJson, _ = json.Marshal(balances[0])
 	assert.Equal(t, string(balanceJson), string(balanceReadJson))
 
-	// Transfer half to a different address
+	// Transfer half to a different address, with a blockchain event whose time is not the time it was recorded
+	chainEvent := &core.BlockchainEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Timestamp: fftypes.UnixTime(1690000000),
+	}
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionBlockchainEvents, core.ChangeEventTypeCreated, "ns1", chainEvent.ID).Return()
+	_, err = s.InsertOrGetBlockchainEvent(ctx, chainEvent)
+	assert.NoError(t, err)
 	transfer.From = "0x0"
 	transfer.To = "0x1"
 	transfer.Amount = *fftypes.NewFFBigInt(5)
+	transfer.ProtocolID = "2"
+	transfer.Created = fftypes.UnixTime(1700000001)
+	transfer.BlockchainEvent = chainEvent.ID
 	err = s.UpdateTokenBalances(ctx, transfer)
 	assert.NoError(t, err)
 
@@ -132,9 +145,59 @@ func TestTokenBalanceE2EWithDB(t *testing.T) {
 	assert.Equal(t, 1, len(pools))
 	assert.Equal(t, *transfer.Pool, *pools[0].Pool)
 
+	// Query the balance history for the original account
+	hfb := database.TokenBalanceChangeQueryFactory.NewFilter(ctx)
+	changes, _, err := s.GetTokenBalanceHistory(ctx, "ns1", hfb.And(
+		hfb.Eq("key", "0x0"),
+	).Sort("created"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, int64(10), changes[0].Amount.Int().Int64())
+	assert.False(t, changes[0].Debit)
+	assert.Equal(t, int64(10), changes[0].Balance.Int().Int64())
+	assert.Equal(t, int64(1700000000), changes[0].Timestamp.Time().Unix())
+	assert.Equal(t, int64(5), changes[1].Amount.Int().Int64())
+	assert.True(t, changes[1].Debit)
+	assert.Equal(t, int64(5), changes[1].Balance.Int().Int64())
+	assert.Equal(t, int64(1690000000), changes[1].Timestamp.Time().Unix())
+
+	// Amounts and balances are compared as integers
+	changes, _, err = s.GetTokenBalanceHistory(ctx, "ns1", hfb.And(
+		hfb.Eq("key", "0x0"),
+		hfb.Gt("balance", 9),
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, int64(10), changes[0].Balance.Int().Int64())
+
+	// Query the balances as of the time of the block of the second transfer
+	asOf := &core.TokenBalancePointInTime{Timestamp: fftypes.UnixTime(1690000000)}
+	balances, _, err = s.GetTokenBalancesAsOf(ctx, "ns1", asOf, fb.And(fb.Eq("updated", fftypes.UnixTime(1690000000))))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(balances))
+	assert.Equal(t, int64(1690000000), balances[0].Updated.Time().Unix())
+
+	// Query the balances as they were before the second transfer
+	asOf = &core.TokenBalancePointInTime{ProtocolID: "1"}
+	balances, _, err = s.GetTokenBalancesAsOf(ctx, "ns1", asOf, fb.And())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(balances))
+	assert.Equal(t, "0x0", balances[0].Key)
+	assert.Equal(t, int64(10), balances[0].Balance.Int().Int64())
+
+	// Query the balances as of the latest change
+	asOf = &core.TokenBalancePointInTime{ProtocolID: "2"}
+	balances, _, err = s.GetTokenBalancesAsOf(ctx, "ns1", asOf, fb.And(fb.Eq("key", "0x0")))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(balances))
+	assert.Equal(t, int64(5), balances[0].Balance.Int().Int64())
+
 	// Delete the token balances
 	err = s.DeleteTokenBalances(ctx, "ns1", transfer.Pool)
 	assert.NoError(t, err)
+	changes, _, err = s.GetTokenBalanceHistory(ctx, "ns1", hfb.And())
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
 }
 
 func TestUpdateTokenBalancesFailBegin(t *testing.T) {
@@ -145,6 +208,16 @@ func TestUpdateTokenBalancesFailBegin(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpdateTokenBalancesFailGetBlockchainEvent(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpdateTokenBalances(context.Background(), &core.TokenTransfer{To: "0x0", BlockchainEvent: fftypes.NewUUID()})
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpdateTokenBalancesFailSelect(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
@@ -187,11 +260,24 @@ func TestUpdateTokenBalancesFailUpdate(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpdateTokenBalancesFailInsertHistory(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT INTO tokenbalance .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO tokenbalancehistory .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpdateTokenBalances(context.Background(), &core.TokenTransfer{To: "0x0"})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpdateTokenBalancesFailCommit(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
 	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
 	err := s.UpdateTokenBalances(context.Background(), &core.TokenTransfer{To: "0x0"})
 	assert.Regexp(t, "FF00180", err)
@@ -298,6 +384,17 @@ func TestDeleteTokenBalancesFailBegin(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestDeleteTokenBalancesFailDeleteHistory(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM tokenbalance .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM tokenbalancehistory .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteTokenBalances(context.Background(), "ns1", fftypes.NewUUID())
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeleteTokenBalancesFailDelete(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
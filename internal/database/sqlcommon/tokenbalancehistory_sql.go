@@ -0,0 +1,211 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly-common/pkg/dbsql"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+const tokenbalancehistoryTable = "tokenbalancehistory"
+
+var (
+	tokenBalanceHistoryColumns = []string{
+		"pool_id",
+		"token_index",
+		"uri",
+		"connector",
+		"namespace",
+		"key",
+		"amount",
+		"debit",
+		"balance",
+		"transfer_id",
+		"protocol_id",
+		"blockchain_event",
+		"created",
+		"timestamp",
+	}
+	tokenBalanceHistoryFilterFieldMap = map[string]string{
+		"pool":            "pool_id",
+		"tokenindex":      "token_index",
+		"transfer":        "transfer_id",
+		"protocolid":      "protocol_id",
+		"blockchainevent": "blockchain_event",
+	}
+	// Point-in-time balances are read from the history table, but returned as TokenBalance entries
+	// with the time of the blockchain event that last changed them as the updated time
+	tokenBalanceAsOfColumns = []string{
+		"pool_id",
+		"token_index",
+		"uri",
+		"connector",
+		"namespace",
+		"key",
+		"balance",
+		"timestamp",
+	}
+	tokenBalanceAsOfFilterFieldMap = map[string]string{
+		"pool":       "pool_id",
+		"tokenindex": "token_index",
+		"updated":    "timestamp",
+	}
+)
+
+// tokenTransferTimestamp returns the time of the blockchain event of a transfer, which is the same on
+// every member of the network, falling back to the time the transfer was recorded by this node
+func (s *SQLCommon) tokenTransferTimestamp(ctx context.Context, transfer *core.TokenTransfer) (*fftypes.FFTime, error) {
+	if transfer.BlockchainEvent != nil {
+		event, err := s.GetBlockchainEventByID(ctx, transfer.Namespace, transfer.BlockchainEvent)
+		if err != nil {
+			return nil, err
+		}
+		if event != nil && event.Timestamp != nil {
+			return event.Timestamp, nil
+		}
+	}
+	if transfer.Created != nil {
+		return transfer.Created, nil
+	}
+	return fftypes.Now(), nil
+}
+
+func (s *SQLCommon) insertTokenBalanceChange(ctx context.Context, tx *dbsql.TXWrapper, transfer *core.TokenTransfer, key string, debit bool, balance *fftypes.FFBigInt, timestamp *fftypes.FFTime) error {
+	created := transfer.Created
+	if created == nil {
+		created = fftypes.Now()
+	}
+	_, err := s.InsertTx(ctx, tokenbalancehistoryTable, tx,
+		sq.Insert(tokenbalancehistoryTable).
+			Columns(tokenBalanceHistoryColumns...).
+			Values(
+				transfer.Pool,
+				transfer.TokenIndex,
+				transfer.URI,
+				transfer.Connector,
+				transfer.Namespace,
+				key,
+				transfer.Amount,
+				debit,
+				balance,
+				transfer.LocalID,
+				transfer.ProtocolID,
+				transfer.BlockchainEvent,
+				created,
+				timestamp,
+			),
+		nil,
+	)
+	return err
+}
+
+func (s *SQLCommon) tokenBalanceChangeResult(ctx context.Context, row *sql.Rows) (*core.TokenBalanceChange, error) {
+	change := core.TokenBalanceChange{}
+	err := row.Scan(
+		&change.Pool,
+		&change.TokenIndex,
+		&change.URI,
+		&change.Connector,
+		&change.Namespace,
+		&change.Key,
+		&change.Amount,
+		&change.Debit,
+		&change.Balance,
+		&change.Transfer,
+		&change.ProtocolID,
+		&change.BlockchainEvent,
+		&change.Created,
+		&change.Timestamp,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDBReadErr, tokenbalancehistoryTable)
+	}
+	return &change, nil
+}
+
+func (s *SQLCommon) GetTokenBalanceHistory(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.TokenBalanceChange, *ffapi.FilterResult, error) {
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(tokenBalanceHistoryColumns...).From(tokenbalancehistoryTable),
+		filter, tokenBalanceHistoryFilterFieldMap, []interface{}{"seq"}, sq.Eq{"namespace": namespace})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, tokenbalancehistoryTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	changes := []*core.TokenBalanceChange{}
+	for rows.Next() {
+		c, err := s.tokenBalanceChangeResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, s.QueryRes(ctx, tokenbalancehistoryTable, tx, fop, nil, fi), err
+}
+
+func (s *SQLCommon) GetTokenBalancesAsOf(ctx context.Context, namespace string, asOf *core.TokenBalancePointInTime, filter ffapi.Filter) ([]*core.TokenBalance, *ffapi.FilterResult, error) {
+	// The latest change for each account at (or before) the point in time holds the balance at that time.
+	// The time is that of the blockchain event, so every member reconstructs the same balances.
+	bounds := sq.And{sq.Eq{"namespace": namespace}}
+	if asOf.Timestamp != nil {
+		bounds = append(bounds, sq.LtOrEq{"timestamp": asOf.Timestamp})
+	}
+	if asOf.ProtocolID != "" {
+		bounds = append(bounds, sq.LtOrEq{"protocol_id": asOf.ProtocolID})
+	}
+	latest := sq.Select("MAX(seq)").
+		From(tokenbalancehistoryTable).
+		Where(bounds).
+		GroupBy("pool_id", "token_index", "key")
+
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(tokenBalanceAsOfColumns...).From(tokenbalancehistoryTable),
+		filter, tokenBalanceAsOfFilterFieldMap, []interface{}{"seq"},
+		sq.Eq{"namespace": namespace}, sq.Expr("seq IN (?)", latest))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, tokenbalancehistoryTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	balances := []*core.TokenBalance{}
+	for rows.Next() {
+		b, err := s.tokenBalanceResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		balances = append(balances, b)
+	}
+
+	return balances, s.QueryRes(ctx, tokenbalancehistoryTable, tx, fop, nil, fi), err
+}
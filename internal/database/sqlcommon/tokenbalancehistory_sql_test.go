@@ -0,0 +1,82 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTokenBalanceHistoryQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.TokenBalanceChangeQueryFactory.NewFilter(context.Background()).Eq("pool", "")
+	_, _, err := s.GetTokenBalanceHistory(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTokenBalanceHistoryBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.TokenBalanceChangeQueryFactory.NewFilter(context.Background()).Eq("pool", map[bool]bool{true: false})
+	_, _, err := s.GetTokenBalanceHistory(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF00143.*pool", err)
+}
+
+func TestGetTokenBalanceHistoryScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"pool"}).AddRow("only one"))
+	f := database.TokenBalanceChangeQueryFactory.NewFilter(context.Background()).Eq("pool", "")
+	_, _, err := s.GetTokenBalanceHistory(context.Background(), "ns1", f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTokenBalancesAsOfQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.TokenBalanceQueryFactory.NewFilter(context.Background()).Eq("pool", "")
+	asOf := &core.TokenBalancePointInTime{Timestamp: fftypes.Now(), ProtocolID: "000000000001"}
+	_, _, err := s.GetTokenBalancesAsOf(context.Background(), "ns1", asOf, f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTokenBalancesAsOfBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.TokenBalanceQueryFactory.NewFilter(context.Background()).Eq("pool", map[bool]bool{true: false})
+	asOf := &core.TokenBalancePointInTime{Timestamp: fftypes.Now()}
+	_, _, err := s.GetTokenBalancesAsOf(context.Background(), "ns1", asOf, f)
+	assert.Regexp(t, "FF00143.*pool", err)
+}
+
+func TestGetTokenBalancesAsOfScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"pool"}).AddRow("only one"))
+	f := database.TokenBalanceQueryFactory.NewFilter(context.Background()).Eq("pool", "")
+	asOf := &core.TokenBalancePointInTime{Timestamp: fftypes.Now()}
+	_, _, err := s.GetTokenBalancesAsOf(context.Background(), "ns1", asOf, f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -19,6 +19,7 @@ package sqlcommon
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly-common/pkg/dbsql"
@@ -49,6 +50,9 @@ var (
 
 const transactionsTable = "transactions"
 
+// The blockchain IDs of each transaction are also held one per row, so that a transaction can be found by an index lookup
+const txBlockchainIDsTable = "txblockchainids"
+
 type IdempotencyError struct {
 	ExistingTXID  *fftypes.UUID
 	OriginalError error
@@ -93,7 +97,7 @@ func (s *SQLCommon) attemptInsertTxnWithIdempotencyCheck(ctx context.Context, tx
 		}
 		return false, err
 	}
-	return false, nil
+	return false, s.updateTransactionBlockchainIDs(ctx, tx, transaction.Namespace, transaction.ID, transaction.BlockchainIDs, false)
 }
 
 func (s *SQLCommon) InsertTransaction(ctx context.Context, transaction *core.Transaction) (err error) {
@@ -140,6 +144,11 @@ func (s *SQLCommon) InsertTransactions(ctx context.Context, txns []*core.Transac
 		if err != nil {
 			return err
 		}
+		for _, txn := range txns {
+			if err := s.updateTransactionBlockchainIDs(ctx, tx, txn.Namespace, txn.ID, txn.BlockchainIDs, false); err != nil {
+				return err
+			}
+		}
 	} else {
 		// Fall back to individual inserts grouped in a TX, where if one fails for idempotency error we
 		// return the error, but the others get inserted.
@@ -230,6 +239,37 @@ func (s *SQLCommon) GetTransactions(ctx context.Context, namespace string, filte
 
 }
 
+func (s *SQLCommon) GetTransactionsByBlockchainID(ctx context.Context, namespace, blockchainID string, filter ffapi.Filter) (message []*core.Transaction, fr *ffapi.FilterResult, err error) {
+
+	txIDs := sq.Select("tx_id").From(txBlockchainIDsTable).Where(sq.Eq{
+		"namespace":     namespace,
+		"blockchain_id": strings.ToLower(blockchainID),
+	})
+	query, fop, fi, err := s.FilterSelect(ctx, "", sq.Select(transactionColumns...).From(transactionsTable), filter, transactionFilterFieldMap, []interface{}{"sequence"},
+		sq.Eq{"namespace": namespace}, sq.Expr("id IN (?)", txIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.Query(ctx, transactionsTable, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	transactions := []*core.Transaction{}
+	for rows.Next() {
+		transaction, err := s.transactionResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return transactions, s.QueryRes(ctx, transactionsTable, tx, fop, nil, fi), err
+
+}
+
 func (s *SQLCommon) UpdateTransaction(ctx context.Context, namespace string, id *fftypes.UUID, update ffapi.Update) (err error) {
 
 	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
@@ -249,5 +289,83 @@ func (s *SQLCommon) UpdateTransaction(ctx context.Context, namespace string, id
 		return err
 	}
 
+	if blockchainIDs, ok := s.blockchainIDsFromUpdate(update); ok {
+		if err := s.updateTransactionBlockchainIDs(ctx, tx, namespace, id, blockchainIDs, true); err != nil {
+			return err
+		}
+	}
+
+	return s.CommitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) DeleteTransactions(ctx context.Context, namespace string, ids []*fftypes.UUID) error {
+	ctx, tx, autoCommit, err := s.BeginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.RollbackTx(ctx, tx, autoCommit)
+
+	err = s.DeleteTx(ctx, txBlockchainIDsTable, tx, sq.Delete(txBlockchainIDsTable).Where(sq.Eq{
+		"namespace": namespace,
+		"tx_id":     ids,
+	}), nil)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
+	err = s.DeleteTx(ctx, transactionsTable, tx, sq.Delete(transactionsTable).Where(sq.Eq{
+		"namespace": namespace,
+		"id":        ids,
+	}), nil)
+	if err != nil && err != fftypes.DeleteRecordNotFound {
+		return err
+	}
+
 	return s.CommitTx(ctx, tx, autoCommit)
 }
+
+func (s *SQLCommon) blockchainIDsFromUpdate(update ffapi.Update) (blockchainIDs fftypes.FFStringArray, ok bool) {
+	info, err := update.Finalize()
+	if err != nil {
+		return nil, false
+	}
+	for _, so := range info.SetOperations {
+		if so.Field == "blockchainids" {
+			v, _ := so.Value.Value()
+			_ = blockchainIDs.Scan(v)
+			return blockchainIDs, true
+		}
+	}
+	return nil, false
+}
+
+// updateTransactionBlockchainIDs indexes the full set of blockchain IDs of a transaction
+func (s *SQLCommon) updateTransactionBlockchainIDs(ctx context.Context, tx *dbsql.TXWrapper, namespace string, id *fftypes.UUID, blockchainIDs fftypes.FFStringArray, recreate bool) error {
+	if recreate {
+		// Delete all the existing entries, to replace them with new ones below
+		if err := s.DeleteTx(ctx, txBlockchainIDsTable, tx,
+			sq.Delete(txBlockchainIDsTable).Where(sq.Eq{"namespace": namespace, "tx_id": id}),
+			nil, // no change event
+		); err != nil && err != fftypes.DeleteRecordNotFound {
+			return err
+		}
+	}
+
+	indexed := make(map[string]bool, len(blockchainIDs))
+	for _, blockchainID := range blockchainIDs {
+		blockchainID = strings.ToLower(blockchainID)
+		if blockchainID == "" || indexed[blockchainID] {
+			continue
+		}
+		indexed[blockchainID] = true
+		if _, err := s.InsertTx(ctx, txBlockchainIDsTable, tx,
+			sq.Insert(txBlockchainIDsTable).
+				Columns("namespace", "tx_id", "blockchain_id").
+				Values(namespace, id, blockchainID),
+			nil, // no change event
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -60,8 +60,17 @@ func TestTransactionE2EWithDB(t *testing.T) {
 	transactionReadJson, _ := json.Marshal(&transactionRead)
 	assert.Equal(t, string(transactionJson), string(transactionReadJson))
 
-	// Query back the transaction
+	// Look up the transaction by its blockchain ID
 	fb := database.TransactionQueryFactory.NewFilter(ctx)
+	transactions, _, err := s.GetTransactionsByBlockchainID(ctx, "ns1", "TX1", fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 1)
+	assert.Equal(t, transactionID, transactions[0].ID)
+	transactions, _, err = s.GetTransactionsByBlockchainID(ctx, "ns2", "tx1", fb.And())
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+
+	// Query back the transaction
 	filter := fb.And(
 		fb.Eq("id", transaction.ID.String()),
 		fb.Gt("created", "0"),
@@ -107,6 +116,21 @@ func TestTransactionE2EWithDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(transactions))
 	assert.Equal(t, (core.IdempotencyKey)("testKey"), transactions[0].IdempotencyKey)
+
+	// The blockchain ID index follows the update
+	transactions, _, err = s.GetTransactionsByBlockchainID(ctx, "ns1", "0x23456", fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 1)
+	transactions, _, err = s.GetTransactionsByBlockchainID(ctx, "ns1", "tx1", fb.And())
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+
+	// Delete removes the index entries
+	err = s.DeleteTransactions(ctx, "ns1", []*fftypes.UUID{transaction.ID})
+	assert.NoError(t, err)
+	transactions, _, err = s.GetTransactionsByBlockchainID(ctx, "ns1", "0x12345", fb.And())
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
 }
 
 func TestTransactionE2EInsertManyIdempotency(t *testing.T) {
@@ -168,6 +192,11 @@ func TestTransactionE2EInsertManyIdempotency(t *testing.T) {
 		}
 	}
 
+	// All of them share the same blockchain ID
+	resolvedTX, _, err = s.GetTransactionsByBlockchainID(ctx, "ns1", "tx1", fb.And())
+	assert.NoError(t, err)
+	assert.Len(t, resolvedTX, len(txns))
+
 }
 
 func TestInsertTransactionFailBegin(t *testing.T) {
@@ -281,6 +310,67 @@ func TestTransactionUpdateBuildQueryFail(t *testing.T) {
 	assert.Regexp(t, "FF00143.*id", err)
 }
 
+func TestTransactionUpdateBlockchainIDsDeleteFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(driver.RowsAffected(1))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	u := database.TransactionQueryFactory.NewUpdate(context.Background()).Set("blockchainids", fftypes.FFStringArray{"0x12345"})
+	err := s.UpdateTransaction(context.Background(), "ns1", fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTransactionUpdateBlockchainIDsInsertFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(driver.RowsAffected(1))
+	mock.ExpectExec("DELETE .*").WillReturnResult(driver.RowsAffected(0))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	u := database.TransactionQueryFactory.NewUpdate(context.Background()).Set("blockchainids", fftypes.FFStringArray{"", "0x12345"})
+	err := s.UpdateTransaction(context.Background(), "ns1", fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBlockchainIDsFromUpdateFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	u := database.TransactionQueryFactory.NewUpdate(context.Background()).Set("blockchainids", map[bool]bool{true: false})
+	_, ok := s.blockchainIDsFromUpdate(u)
+	assert.False(t, ok)
+
+	u = database.TransactionQueryFactory.NewUpdate(context.Background()).Set("idempotencykey", "testKey")
+	_, ok = s.blockchainIDsFromUpdate(u)
+	assert.False(t, ok)
+}
+
+func TestGetTransactionsByBlockchainIDQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.TransactionQueryFactory.NewFilter(context.Background()).And()
+	_, _, err := s.GetTransactionsByBlockchainID(context.Background(), "ns1", "0x12345", f)
+	assert.Regexp(t, "FF00176", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTransactionsByBlockchainIDBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.TransactionQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
+	_, _, err := s.GetTransactionsByBlockchainID(context.Background(), "ns1", "0x12345", f)
+	assert.Regexp(t, "FF00143.*id", err)
+}
+
+func TestGetTransactionsByBlockchainIDReadFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.TransactionQueryFactory.NewFilter(context.Background()).And()
+	_, _, err := s.GetTransactionsByBlockchainID(context.Background(), "ns1", "0x12345", f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestTransactionUpdateFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
@@ -306,7 +396,7 @@ func TestInsertTransactionsMultiRowOK(t *testing.T) {
 	s.fakePSQLInsert = true
 	s, mock := s.init()
 
-	tx1 := &core.Transaction{ID: fftypes.NewUUID(), Namespace: "ns1"}
+	tx1 := &core.Transaction{ID: fftypes.NewUUID(), Namespace: "ns1", BlockchainIDs: fftypes.FFStringArray{"0x12345"}}
 	tx2 := &core.Transaction{ID: fftypes.NewUUID(), Namespace: "ns1"}
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTransactions, core.ChangeEventTypeCreated, "ns1", tx1.ID)
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTransactions, core.ChangeEventTypeCreated, "ns1", tx2.ID)
@@ -316,6 +406,7 @@ func TestInsertTransactionsMultiRowOK(t *testing.T) {
 		AddRow(int64(1001)).
 		AddRow(int64(1002)),
 	)
+	mock.ExpectQuery("INSERT.*txblockchainids").WillReturnRows(sqlmock.NewRows([]string{s.SequenceColumn()}).AddRow(int64(1)))
 	mock.ExpectCommit()
 	err := s.RunAsGroup(context.Background(), func(ctx context.Context) error {
 		return s.InsertTransactions(ctx, []*core.Transaction{tx1, tx2})
@@ -325,6 +416,25 @@ func TestInsertTransactionsMultiRowOK(t *testing.T) {
 	s.callbacks.AssertExpectations(t)
 }
 
+func TestInsertTransactionsMultiRowBlockchainIDsFail(t *testing.T) {
+	s := newMockProvider()
+	s.multiRowInsert = true
+	s.fakePSQLInsert = true
+	s, mock := s.init()
+
+	tx1 := &core.Transaction{ID: fftypes.NewUUID(), Namespace: "ns1", BlockchainIDs: fftypes.FFStringArray{"0x12345"}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT.*transactions").WillReturnRows(sqlmock.NewRows([]string{s.SequenceColumn()}).AddRow(int64(1001)))
+	mock.ExpectQuery("INSERT.*txblockchainids").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.RunAsGroup(context.Background(), func(ctx context.Context) error {
+		return s.InsertTransactions(ctx, []*core.Transaction{tx1})
+	})
+	assert.Regexp(t, "FF00177", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestInsertTransactionsOutsideTXFail(t *testing.T) {
 	s := newMockProvider()
 	s, mock := s.init()
@@ -362,3 +472,43 @@ func TestInsertTransactionsSingleRowFail(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 	s.callbacks.AssertExpectations(t)
 }
+
+func TestDeleteTransactionsFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteTransactions(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00175", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTransactionsFailDeleteBlockchainIDs(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteTransactions(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTransactionsFailDelete(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(driver.RowsAffected(1))
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteTransactions(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF00179", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteTransactionsNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnResult(driver.RowsAffected(0))
+	mock.ExpectExec("DELETE .*").WillReturnResult(driver.RowsAffected(0))
+	mock.ExpectCommit()
+	err := s.DeleteTransactions(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
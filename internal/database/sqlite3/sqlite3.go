@@ -21,6 +21,8 @@ package sqlite3
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"database/sql"
 
@@ -89,6 +91,20 @@ func (sqlite *SQLite3) ApplyInsertQueryCustomizations(insert sq.InsertBuilder, r
 	return insert, false
 }
 
+func jsonPathExpression(column string, path []string) string {
+	return fmt.Sprintf("json_extract(%s, '$.%s')", column, strings.Join(path, "."))
+}
+
+// JSONPathCondition compares the SQL value extracted from the JSON, so booleans are compared as 1 and 0
+func (sqlite *SQLite3) JSONPathCondition(column string, path []string, candidates []interface{}) sq.Sqlizer {
+	return sq.Eq{jsonPathExpression(column, path): candidates}
+}
+
+// JSONPathIndex returns an expression index on the value at the path
+func (sqlite *SQLite3) JSONPathIndex(name, table, column string, path []string) string {
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s);", name, table, jsonPathExpression(column, path))
+}
+
 func (sqlite *SQLite3) Open(url string) (*sql.DB, error) {
 	return sql.Open("sqlite3_ff", url)
 }
@@ -61,3 +61,15 @@ func TestSQLite3GoProvider(t *testing.T) {
 	assert.Equal(t, "INSERT INTO test (col1) VALUES (?)", sql)
 	assert.False(t, query)
 }
+
+func TestSQLite3JSONPath(t *testing.T) {
+	sqlite := &SQLite3{}
+
+	sql, args, err := sqlite.JSONPathCondition("d.value", []string{"order", "id"}, []interface{}{float64(123), "123"}).ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "json_extract(d.value, '$.order.id') IN (?,?)", sql)
+	assert.Equal(t, []interface{}{float64(123), "123"}, args)
+
+	assert.Equal(t, "CREATE INDEX IF NOT EXISTS data_value_abc ON data (json_extract(value, '$.order.id'));",
+		sqlite.JSONPathIndex("data_value_abc", "data", "value", []string{"order", "id"}))
+}
@@ -28,6 +28,8 @@ const (
 	DataExchangeManifestEnabled = "manifestEnabled"
 	// DataExchangeInitEnabled instructs FireFly to always post all current nodes to the /init API before connecting or reconnecting to the connector
 	DataExchangeInitEnabled = "initEnabled"
+	// DataExchangeMaxDecompressedSize is the maximum size a compressed message received from a peer can expand to
+	DataExchangeMaxDecompressedSize = "maxDecompressedSize"
 
 	DataExchangeEventRetryInitialDelay = "eventRetry.initialDelay"
 	DataExchangeEventRetryMaxDelay     = "eventRetry.maxDelay"
@@ -40,12 +42,14 @@ const (
 	defaultBackgroundInitialDelay           = "5s"
 	defaultBackgroundRetryFactor            = 2.0
 	defaultBackgroundMaxDelay               = "1m"
+	defaultMaxDecompressedSize              = "10Mb"
 )
 
 func (h *FFDX) InitConfig(config config.Section) {
 	wsclient.InitConfig(config)
 	config.AddKnownKey(DataExchangeManifestEnabled, false)
 	config.AddKnownKey(DataExchangeInitEnabled, false)
+	config.AddKnownKey(DataExchangeMaxDecompressedSize, defaultMaxDecompressedSize)
 	config.AddKnownKey(DataExchangeEventRetryInitialDelay, 50*time.Millisecond)
 	config.AddKnownKey(DataExchangeEventRetryMaxDelay, 30*time.Second)
 	config.AddKnownKey(DataExchangeEventRetryFactor, 2.0)
@@ -159,9 +159,13 @@ func (h *FFDX) dispatchEvent(msg *wsEvent) {
 		return
 
 	case messageReceived:
-		// De-serialize the transport wrapper
+		// De-serialize the transport wrapper, which might be compressed by the sender
 		var wrapper *core.TransportWrapper
-		err = json.Unmarshal([]byte(msg.Message), &wrapper)
+		var payload []byte
+		payload, err = core.DecompressPayload(h.ctx, []byte(msg.Message), h.maxDecompressed)
+		if err == nil {
+			err = json.Unmarshal(payload, &wrapper)
+		}
 		switch {
 		case err != nil:
 			err = fmt.Errorf("invalid transmission from peer '%s': %s", msg.Sender, err)
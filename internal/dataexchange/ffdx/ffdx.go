@@ -33,6 +33,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/retry"
 	"github.com/hyperledger/firefly-common/pkg/wsclient"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/health"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
 )
@@ -46,6 +47,7 @@ type FFDX struct {
 	callbacks       callbacks
 	client          *resty.Client
 	wsconn          wsclient.WSClient
+	streamHealth    *health.StreamTracker
 	needsInit       bool
 	initialized     bool
 	initMutex       sync.Mutex
@@ -54,6 +56,7 @@ type FFDX struct {
 	retry           *retry.Retry
 	backgroundStart bool
 	backgroundRetry *retry.Retry
+	maxDecompressed int64
 }
 
 type dxNode struct {
@@ -178,6 +181,7 @@ func (h *FFDX) Init(ctx context.Context, cancelCtx context.CancelFunc, config co
 		opHandlers: make(map[string]core.OperationCallbacks),
 	}
 	h.needsInit = config.GetBool(DataExchangeInitEnabled)
+	h.maxDecompressed = config.GetByteSize(DataExchangeMaxDecompressedSize)
 	h.nodes = make(map[string]*dxNode)
 
 	if config.GetString(ffresty.HTTPConfigURL) == "" {
@@ -206,7 +210,8 @@ func (h *FFDX) Init(ctx context.Context, cancelCtx context.CancelFunc, config co
 		wsConfig.WSKeyPath = "/ws"
 	}
 
-	h.wsconn, err = wsclient.New(ctx, wsConfig, h.beforeConnect, nil)
+	h.streamHealth = health.NewStreamTracker()
+	h.wsconn, err = wsclient.New(ctx, wsConfig, h.streamHealth.BeforeConnect(h.beforeConnect), h.streamHealth.AfterConnect(nil))
 	if err != nil {
 		return err
 	}
@@ -313,6 +318,15 @@ func (h *FFDX) GetPeerID(peer fftypes.JSONObject) string {
 	return peer.GetString("id")
 }
 
+func (h *FFDX) CheckHealth(ctx context.Context, _ string) (*core.EventStreamHealth, error) {
+	res, err := h.client.R().SetContext(ctx).
+		Get("/api/v1/id")
+	if err != nil || !res.IsSuccess() {
+		return h.streamHealth.Status(), ffresty.WrapRestErr(ctx, res, err, coremsgs.MsgDXRESTErr)
+	}
+	return h.streamHealth.Status(), nil
+}
+
 func (h *FFDX) GetEndpointInfo(ctx context.Context, nodeName string) (peer fftypes.JSONObject, err error) {
 	res, err := h.client.R().SetContext(ctx).
 		SetResult(&peer).
@@ -485,6 +499,7 @@ func (h *FFDX) eventLoop() {
 				h.cancelCtx()
 				return
 			}
+			h.streamHealth.EventReceived()
 
 			l.Tracef("DX message: %s", msgBytes)
 			var msg wsEvent
@@ -191,6 +191,32 @@ func TestGetEndpointInfo(t *testing.T) {
 	}, peer)
 }
 
+func TestCheckHealth(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/id", httpURL),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{
+			"id": "peer1",
+		}))
+
+	streamHealth, err := h.CheckHealth(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.False(t, streamHealth.Connected)
+}
+
+func TestCheckHealthError(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/id", httpURL),
+		httpmock.NewJsonResponderOrPanic(500, fftypes.JSONObject{}))
+
+	streamHealth, err := h.CheckHealth(context.Background(), "ns1")
+	assert.Regexp(t, "FF10229", err)
+	assert.NotNil(t, streamHealth)
+}
+
 func TestGetEndpointMissingID(t *testing.T) {
 	h, _, _, httpURL, done := newTestFFDX(t, false)
 	defer done()
@@ -602,6 +628,48 @@ func TestMessageEvents(t *testing.T) {
 	ocb.AssertExpectations(t)
 }
 
+func TestMessageEventsCompressed(t *testing.T) {
+
+	h, toServer, fromServer, _, done := newTestFFDX(t, false)
+	defer done()
+	h.maxDecompressed = 1024
+
+	mcb := &dataexchangemocks.Callbacks{}
+	h.SetHandler("ns1", "node1", mcb)
+	h.AddNode(context.Background(), "ns1", "node1", fftypes.JSONObject{"id": "peer1"})
+
+	err := h.Start()
+	assert.NoError(t, err)
+
+	batchID := fftypes.NewUUID()
+	compressed, err := core.CompressPayload(context.Background(), core.CompressionTypeGzip,
+		[]byte(`{"batch":{"id":"`+batchID.String()+`","namespace":"ns1"}}`))
+	assert.NoError(t, err)
+	mcb.On("DXEvent", h, mock.MatchedBy(func(ev dataexchange.DXEvent) bool {
+		return ev.EventID() == "1" &&
+			ev.Type() == dataexchange.DXEventTypeMessageReceived &&
+			ev.MessageReceived().Transport.Batch.ID.Equals(batchID)
+	})).Run(acker()).Return(nil)
+	event, _ := json.Marshal(fftypes.JSONObject{
+		"id": "1", "type": "message-received", "sender": "peer2", "recipient": "peer1", "message": string(compressed),
+	})
+	fromServer <- string(event)
+	msg := <-toServer
+	assert.Equal(t, `{"action":"ack","id":"1"}`, string(msg))
+
+	// Payloads that expand beyond the limit are acked without being dispatched
+	compressed, err = core.CompressPayload(context.Background(), core.CompressionTypeZstd, make([]byte, 2048))
+	assert.NoError(t, err)
+	event, _ = json.Marshal(fftypes.JSONObject{
+		"id": "2", "type": "message-received", "sender": "peer2", "recipient": "peer1", "message": string(compressed),
+	})
+	fromServer <- string(event)
+	msg = <-toServer
+	assert.Equal(t, `{"action":"ack","id":"2"}`, string(msg))
+
+	mcb.AssertExpectations(t)
+}
+
 func TestBlobEvents(t *testing.T) {
 
 	h, toServer, fromServer, _, done := newTestFFDX(t, false)
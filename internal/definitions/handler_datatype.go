@@ -18,6 +18,7 @@ package definitions
 
 import (
 	"context"
+	"strings"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -46,6 +47,14 @@ func (dh *definitionHandler) handleDatatypeBroadcast(ctx context.Context, state
 		return HandlerResult{Action: core.ActionReject}, i18n.NewError(ctx, coremsgs.MsgDefRejectedConflict, "datatype", dt.ID, existing.ID)
 	}
 
+	diff, err := dh.data.DiffLatestDatatype(ctx, &dt)
+	if err != nil {
+		return HandlerResult{Action: core.ActionRetry}, err
+	}
+	if err := checkDatatypeCompatibility(ctx, &dt, diff); err != nil {
+		return HandlerResult{Action: core.ActionReject}, i18n.NewError(ctx, coremsgs.MsgDefRejectedSchemaFail, "datatype", dt.ID, err)
+	}
+
 	if err = dh.database.UpsertDatatype(ctx, &dt, false); err != nil {
 		return HandlerResult{Action: core.ActionRetry}, err
 	}
@@ -56,3 +65,16 @@ func (dh *definitionHandler) handleDatatypeBroadcast(ctx context.Context, state
 	})
 	return HandlerResult{Action: core.ActionConfirm}, nil
 }
+
+// checkDatatypeCompatibility verifies the changes from the previous version retain the compatibility declared by the datatype
+func checkDatatypeCompatibility(ctx context.Context, dt *core.Datatype, diff *core.DatatypeDiff) error {
+	if diff == nil || diff.Satisfies(dt.Compatibility) {
+		return nil
+	}
+	incompatibilities := diff.Incompatibilities(dt.Compatibility)
+	changes := make([]string, len(incompatibilities))
+	for i, c := range incompatibilities {
+		changes[i] = c.String()
+	}
+	return i18n.NewError(ctx, coremsgs.MsgDatatypeIncompatible, diff.To, dt.Compatibility, diff.From, strings.Join(changes, "; "))
+}
@@ -49,6 +49,7 @@ func TestHandleDefinitionBroadcastDatatypeOk(t *testing.T) {
 
 	dh.mdm.On("CheckDatatype", mock.Anything, mock.Anything).Return(nil)
 	dh.mdi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver1").Return(nil, nil)
+	dh.mdm.On("DiffLatestDatatype", mock.Anything, mock.Anything).Return(nil, nil)
 	dh.mdi.On("UpsertDatatype", mock.Anything, mock.Anything, false).Return(nil)
 	dh.mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
 
@@ -84,6 +85,7 @@ func TestHandleDefinitionBroadcastDatatypeEventFail(t *testing.T) {
 
 	dh.mdm.On("CheckDatatype", mock.Anything, mock.Anything).Return(nil)
 	dh.mdi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver1").Return(nil, nil)
+	dh.mdm.On("DiffLatestDatatype", mock.Anything, mock.Anything).Return(nil, nil)
 	dh.mdi.On("UpsertDatatype", mock.Anything, mock.Anything, false).Return(nil)
 	dh.mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, &core.Message{
@@ -234,6 +236,7 @@ func TestHandleDefinitionBroadcastUpsertFail(t *testing.T) {
 
 	dh.mdm.On("CheckDatatype", mock.Anything, mock.Anything).Return(nil)
 	dh.mdi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver1").Return(nil, nil)
+	dh.mdm.On("DiffLatestDatatype", mock.Anything, mock.Anything).Return(nil, nil)
 	dh.mdi.On("UpsertDatatype", mock.Anything, mock.Anything, false).Return(fmt.Errorf("pop"))
 	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, &core.Message{
 		Header: core.MessageHeader{
@@ -277,3 +280,79 @@ func TestHandleDefinitionBroadcastDatatypeDuplicate(t *testing.T) {
 
 	bs.assertNoFinalizers()
 }
+
+func TestHandleDefinitionBroadcastDatatypeDiffFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	dt := &core.Datatype{
+		ID:        fftypes.NewUUID(),
+		Validator: core.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Name:      "name1",
+		Version:   "ver2",
+		Value:     fftypes.JSONAnyPtr(`{}`),
+	}
+	dt.Hash = dt.Value.Hash()
+	b, err := json.Marshal(&dt)
+	assert.NoError(t, err)
+	data := &core.Data{
+		Value: fftypes.JSONAnyPtrBytes(b),
+	}
+
+	dh.mdm.On("CheckDatatype", mock.Anything, mock.Anything).Return(nil)
+	dh.mdi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver2").Return(nil, nil)
+	dh.mdm.On("DiffLatestDatatype", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, &core.Message{
+		Header: core.MessageHeader{
+			Tag: core.SystemTagDefineDatatype,
+		},
+	}, core.DataArray{data}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionRetry}, action)
+	assert.EqualError(t, err, "pop")
+
+	bs.assertNoFinalizers()
+}
+
+func TestHandleDefinitionBroadcastDatatypeIncompatible(t *testing.T) {
+	dh, bs := newTestDefinitionHandler(t)
+	defer dh.cleanup(t)
+
+	dt := &core.Datatype{
+		ID:            fftypes.NewUUID(),
+		Validator:     core.ValidatorTypeJSON,
+		Namespace:     "ns1",
+		Name:          "name1",
+		Version:       "ver2",
+		Value:         fftypes.JSONAnyPtr(`{}`),
+		Compatibility: core.DatatypeCompatibilityBackward,
+	}
+	dt.Hash = dt.Value.Hash()
+	b, err := json.Marshal(&dt)
+	assert.NoError(t, err)
+	data := &core.Data{
+		Value: fftypes.JSONAnyPtrBytes(b),
+	}
+
+	diff := &core.DatatypeDiff{
+		From:          &core.DatatypeRef{Name: "name1", Version: "ver1"},
+		To:            &core.DatatypeRef{Name: "name1", Version: "ver2"},
+		Compatibility: core.DatatypeCompatibilityFull,
+	}
+	diff.AddChange("$.properties.a", core.DatatypeCompatibilityBackward, "property added")
+	diff.AddChange("$.required", core.DatatypeCompatibilityForward, "property 'b' required")
+
+	dh.mdm.On("CheckDatatype", mock.Anything, mock.Anything).Return(nil)
+	dh.mdi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver2").Return(nil, nil)
+	dh.mdm.On("DiffLatestDatatype", mock.Anything, mock.Anything).Return(diff, nil)
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), &bs.BatchState, &core.Message{
+		Header: core.MessageHeader{
+			Tag: core.SystemTagDefineDatatype,
+		},
+	}, core.DataArray{data}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: core.ActionReject}, action)
+	assert.Regexp(t, "FF10495.*backward.*property 'b' required", err)
+	assert.NotRegexp(t, "property added", err)
+
+	bs.assertNoFinalizers()
+}
@@ -42,6 +42,14 @@ func (ds *definitionSender) DefineDatatype(ctx context.Context, datatype *core.D
 		if err := ds.data.CheckDatatype(ctx, datatype); err != nil {
 			return err
 		}
+		// Check compatibility with the previous version now, as well as when the definition is confirmed
+		diff, err := ds.data.DiffLatestDatatype(ctx, datatype)
+		if err != nil {
+			return err
+		}
+		if err := checkDatatypeCompatibility(ctx, datatype, diff); err != nil {
+			return err
+		}
 
 		datatype.Namespace = ""
 		msg, err := ds.getSenderDefault(ctx, datatype, core.SystemTagDefineDatatype).send(ctx, waitConfirm)
@@ -44,6 +44,7 @@ func TestBroadcastDatatypeBadValue(t *testing.T) {
 	ds.multiparty = true
 
 	ds.mdm.On("CheckDatatype", mock.Anything, mock.Anything).Return(nil)
+	ds.mdm.On("DiffLatestDatatype", mock.Anything, mock.Anything).Return(nil, nil)
 	ds.mim.On("GetRootOrg", context.Background()).Return(&core.Identity{
 		IdentityBase: core.IdentityBase{
 			DID: "firefly:org1",
@@ -89,6 +90,7 @@ func TestBroadcastOk(t *testing.T) {
 	}, nil)
 	ds.mim.On("ResolveInputSigningIdentity", mock.Anything, mock.Anything).Return(nil)
 	ds.mdm.On("CheckDatatype", mock.Anything, mock.Anything).Return(nil)
+	ds.mdm.On("DiffLatestDatatype", mock.Anything, mock.Anything).Return(nil, nil)
 	ds.mbm.On("NewBroadcast", mock.Anything).Return(mms)
 	mms.On("Send", context.Background()).Return(nil)
 
@@ -116,3 +118,44 @@ func TestDefineDatatypeNonMultiparty(t *testing.T) {
 	}, false)
 	assert.Regexp(t, "FF10414", err)
 }
+
+func TestDefineDatatypeDiffFail(t *testing.T) {
+	ds := newTestDefinitionSender(t)
+	defer ds.cleanup(t)
+	ds.multiparty = true
+
+	ds.mdm.On("CheckDatatype", mock.Anything, mock.Anything).Return(nil)
+	ds.mdm.On("DiffLatestDatatype", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	err := ds.DefineDatatype(context.Background(), &core.Datatype{
+		Namespace: "ns1",
+		Name:      "ent1",
+		Version:   "0.0.2",
+		Value:     fftypes.JSONAnyPtr(`{"some": "data"}`),
+	}, false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDefineDatatypeIncompatible(t *testing.T) {
+	ds := newTestDefinitionSender(t)
+	defer ds.cleanup(t)
+	ds.multiparty = true
+
+	diff := &core.DatatypeDiff{
+		From:          &core.DatatypeRef{Name: "ent1", Version: "0.0.1"},
+		To:            &core.DatatypeRef{Name: "ent1", Version: "0.0.2"},
+		Compatibility: core.DatatypeCompatibilityFull,
+	}
+	diff.AddChange("$.properties.some", core.DatatypeCompatibilityNone, "type changed from 'string' to 'number'")
+	ds.mdm.On("CheckDatatype", mock.Anything, mock.Anything).Return(nil)
+	ds.mdm.On("DiffLatestDatatype", mock.Anything, mock.Anything).Return(diff, nil)
+
+	err := ds.DefineDatatype(context.Background(), &core.Datatype{
+		Namespace:     "ns1",
+		Name:          "ent1",
+		Version:       "0.0.2",
+		Value:         fftypes.JSONAnyPtr(`{"some": "data"}`),
+		Compatibility: core.DatatypeCompatibilityForward,
+	}, false)
+	assert.Regexp(t, "FF10495", err)
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
@@ -47,19 +48,21 @@ const (
 )
 
 type aggregator struct {
-	ctx          context.Context
-	namespace    string
-	database     database.Plugin
-	messaging    privatemessaging.Manager
-	definitions  definitions.Handler
-	identity     identity.Manager
-	data         data.Manager
-	eventPoller  *eventPoller
-	verifierType core.VerifierType
-	retry        *retry.Retry
-	metrics      metrics.Manager
-	batchCache   cache.CInterface
-	rewinder     *rewinder
+	ctx            context.Context
+	namespace      string
+	database       database.Plugin
+	messaging      privatemessaging.Manager
+	definitions    definitions.Handler
+	identity       identity.Manager
+	data           data.Manager
+	eventPoller    *eventPoller
+	verifierType   core.VerifierType
+	retry          *retry.Retry
+	metrics        metrics.Manager
+	batchCache     cache.CInterface
+	rewinder       *rewinder
+	expiryInterval time.Duration
+	expiryDone     chan struct{}
 }
 
 type batchCacheEntry struct {
@@ -94,15 +97,17 @@ func privatePinHash(topic string, group *fftypes.Bytes32, identity string, nonce
 func newAggregator(ctx context.Context, ns string, di database.Plugin, bi blockchain.Plugin, pm privatemessaging.Manager, sh definitions.Handler, im identity.Manager, dm data.Manager, en *eventNotifier, mm metrics.Manager, cacheManager cache.Manager) (*aggregator, error) {
 	batchSize := config.GetInt(coreconfig.EventAggregatorBatchSize)
 	ag := &aggregator{
-		ctx:          log.WithLogField(ctx, "role", "aggregator"),
-		namespace:    ns,
-		database:     di,
-		messaging:    pm,
-		definitions:  sh,
-		identity:     im,
-		data:         dm,
-		verifierType: bi.VerifierType(),
-		metrics:      mm,
+		ctx:            log.WithLogField(ctx, "role", "aggregator"),
+		namespace:      ns,
+		database:       di,
+		messaging:      pm,
+		definitions:    sh,
+		identity:       im,
+		data:           dm,
+		verifierType:   bi.VerifierType(),
+		metrics:        mm,
+		expiryInterval: config.GetDuration(coreconfig.EventAggregatorExpiryInterval),
+		expiryDone:     make(chan struct{}),
 	}
 
 	batchCache, err := cacheManager.GetCache(
@@ -149,6 +154,7 @@ func newAggregator(ctx context.Context, ns string, di database.Plugin, bi blockc
 func (ag *aggregator) start() {
 	ag.rewinder.start()
 	ag.eventPoller.start()
+	go ag.expiryLoop()
 }
 
 func (ag *aggregator) queueBatchRewind(batchID *fftypes.UUID) {
@@ -461,6 +467,7 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *core.BatchMa
 	unmaskedContexts := make([]*fftypes.Bytes32, 0)
 	nextPins := make([]*nextPinState, 0)
 	action := core.ActionWait
+	expired := false
 	var correlator *fftypes.UUID
 
 	var cro data.CacheReadOption
@@ -524,6 +531,17 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *core.BatchMa
 		}
 
 		if action == core.ActionConfirm {
+			if expired, err = ag.pinnedAfterExpiry(ctx, msg, manifest.TX.ID, state); err != nil {
+				return err
+			}
+		}
+		switch {
+		case action != core.ActionConfirm:
+		case expired:
+			// The batch was pinned after the message expired, so it is cancelled in sequence
+		case msg.State == core.MessageStateCancelled:
+			// The message was already cancelled on this node, so it must not also be confirmed
+		default:
 			l.Debugf("Attempt dispatch msg=%s broadcastContexts=%v privatePins=%v", msg.Header.ID, unmaskedContexts, msg.Pins)
 			action, correlator, err = ag.readyForDispatch(ctx, msg, data, manifest.TX.ID, state)
 		}
@@ -539,13 +557,22 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *core.BatchMa
 		return nil
 	}
 
-	if action == core.ActionReject && err != nil {
-		log.L(ctx).Warnf("Message '%s' rejected: %s", msg.Header.ID, err)
-		msg.RejectReason = err.Error()
+	var newState core.MessageState
+	switch {
+	case expired:
+		newState = ag.completeExpiry(ctx, msg, manifest.TX.ID, state)
+	case action == core.ActionConfirm && msg.State == core.MessageStateCancelled:
+		// The pin is consumed, but the events for the cancellation have already been emitted
+		l.Infof("Message '%s' was cancelled before it was pinned, so is not confirmed", msg.Header.ID)
+		newState = core.MessageStateCancelled
+	default:
+		if action == core.ActionReject && err != nil {
+			log.L(ctx).Warnf("Message '%s' rejected: %s", msg.Header.ID, err)
+			msg.RejectReason = err.Error()
+		}
+		newState = ag.completeDispatch(action, correlator, msg, manifest.TX.ID, state)
 	}
 
-	newState := ag.completeDispatch(action, correlator, msg, manifest.TX.ID, state)
-
 	// Mark all message pins dispatched, and increment all nextPins
 	for _, np := range nextPins {
 		np.IncrementNextPin(ctx, ag.namespace)
@@ -666,19 +693,7 @@ func (ag *aggregator) completeDispatch(action core.MessageAction, correlator *ff
 	}
 
 	state.AddFinalize(func(ctx context.Context) error {
-		// Generate the appropriate event - one per topic (events cover a single topic)
-		for _, topic := range msg.Header.Topics {
-			event := core.NewEvent(eventType, ag.namespace, msg.Header.ID, tx, topic)
-			event.Correlator = msg.Header.CID
-			if correlator != nil {
-				// Definition handlers can set a custom event correlator (such as a token pool ID)
-				event.Correlator = correlator
-			}
-			if err := ag.database.InsertEvent(ctx, event); err != nil {
-				return err
-			}
-		}
-		return nil
+		return ag.insertMessageEvents(ctx, eventType, msg, tx, correlator)
 	})
 	if ag.metrics.IsMetricsEnabled() {
 		ag.metrics.MessageConfirmed(msg, eventType)
@@ -686,6 +701,22 @@ func (ag *aggregator) completeDispatch(action core.MessageAction, correlator *ff
 	return newState
 }
 
+// insertMessageEvents generates the appropriate event for a message - one per topic (events cover a single topic)
+func (ag *aggregator) insertMessageEvents(ctx context.Context, eventType core.EventType, msg *core.Message, tx, correlator *fftypes.UUID) error {
+	for _, topic := range msg.Header.Topics {
+		event := core.NewEvent(eventType, ag.namespace, msg.Header.ID, tx, topic)
+		event.Correlator = msg.Header.CID
+		if correlator != nil {
+			// Definition handlers can set a custom event correlator (such as a token pool ID)
+			event.Correlator = correlator
+		}
+		if err := ag.database.InsertEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // resolveBlobs ensures that the blobs for all the attachments in the data array, have been received into the
 // local data exchange blob store. Either because of a private transfer, or by downloading them from the shared storage
 func (ag *aggregator) resolveBlobs(ctx context.Context, data core.DataArray) (resolved bool, err error) {
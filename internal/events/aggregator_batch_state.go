@@ -38,6 +38,7 @@ func newBatchState(ag *aggregator) *batchState {
 		maskedContexts:     make(map[fftypes.Bytes32]*nextPinGroupState),
 		unmaskedContexts:   make(map[fftypes.Bytes32]*contextState),
 		dispatchedMessages: make([]*dispatchedMessage, 0),
+		pinTimes:           make(map[fftypes.UUID]*fftypes.FFTime),
 		BatchState: core.BatchState{
 			PendingConfirms: make(map[fftypes.UUID]*core.Message),
 		},
@@ -103,6 +104,7 @@ type batchState struct {
 	maskedContexts     map[fftypes.Bytes32]*nextPinGroupState
 	unmaskedContexts   map[fftypes.Bytes32]*contextState
 	dispatchedMessages []*dispatchedMessage
+	pinTimes           map[fftypes.UUID]*fftypes.FFTime
 }
 
 func (bs *batchState) RunPreFinalize(ctx context.Context) error {
@@ -231,7 +233,8 @@ func (bs *batchState) confirmMessages(ctx context.Context, msgIDs []*fftypes.UUI
 		Set("confirmed", confirmTime).
 		Set("state", msgState).
 		Set("rejectreason", rejectReason)
-	return bs.database.UpdateMessages(ctx, bs.namespace, filter, setConfirmed)
+	_, err := bs.database.UpdateMessages(ctx, bs.namespace, filter, setConfirmed)
+	return err
 }
 
 func (bs *batchState) flushPins(ctx context.Context) error {
@@ -276,7 +279,7 @@ func (bs *batchState) flushPins(ctx context.Context) error {
 			pinsDispatched[*dm.batchID] = batchDispatched
 		}
 
-		if dm.newState == core.MessageStateRejected {
+		if dm.newState == core.MessageStateRejected || dm.rejectReason != "" {
 			if err := bs.confirmMessages(ctx, []*fftypes.UUID{dm.msgID}, dm.newState, confirmTime, dm.rejectReason); err != nil {
 				return err
 			}
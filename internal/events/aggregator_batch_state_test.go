@@ -52,7 +52,7 @@ func TestFlushPinsFailUpdateMessages(t *testing.T) {
 	msgID := fftypes.NewUUID()
 
 	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
-	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(0), fmt.Errorf("pop"))
 	ag.mdm.On("UpdateMessageStateIfCached", ag.ctx, msgID, core.MessageStateConfirmed, mock.Anything, "").Return()
 
 	bs.markMessageDispatched(fftypes.NewUUID(), &core.Message{
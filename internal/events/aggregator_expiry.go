@@ -0,0 +1,150 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// expiryLoop periodically cancels messages on this node that have passed their expiry while still
+// waiting to be batched. Once a message has been sent, whether it expired depends on the time its batch
+// was pinned, so that is decided by the aggregator when the pin arrives, consistently on every member.
+func (ag *aggregator) expiryLoop() {
+	defer close(ag.expiryDone)
+
+	ticker := time.NewTicker(ag.expiryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// Retry indefinitely for database errors (until the context closes)
+			_ = ag.retry.Do(ag.ctx, "expire messages", func(attempt int) (retry bool, err error) {
+				return true, ag.expireMessages(ag.ctx)
+			})
+		case <-ag.ctx.Done():
+			log.L(ag.ctx).Debugf("Expiry loop stopping")
+			return
+		}
+	}
+}
+
+func (ag *aggregator) expireMessages(ctx context.Context) error {
+	now := fftypes.Now()
+	fb := database.MessageQueryFactory.NewFilterLimit(ctx, uint64(ag.eventPoller.conf.eventBatchSize))
+	msgs, _, err := ag.database.GetMessages(ctx, ag.namespace, fb.And(
+		fb.Eq("state", core.MessageStateReady),
+		fb.Lte("expiry", now),
+	).Sort("sequence"))
+	if err != nil || len(msgs) == 0 {
+		return err
+	}
+
+	for _, msg := range msgs {
+		msg.State = core.MessageStateCancelled
+		msg.RejectReason = i18n.NewError(ctx, coremsgs.MsgMessageExpired, msg.Header.Expiry).Error()
+	}
+
+	cancelled := make([]*core.Message, 0, len(msgs))
+	err = ag.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		cancelled = cancelled[:0]
+		for _, msg := range msgs {
+			// The state condition ensures we do not cancel a message that was sent since we queried it
+			fb := database.MessageQueryFactory.NewFilter(ctx)
+			update := database.MessageQueryFactory.NewUpdate(ctx).
+				Set("state", msg.State).
+				Set("confirmed", now).
+				Set("rejectreason", msg.RejectReason)
+			updated, err := ag.database.UpdateMessages(ctx, ag.namespace, fb.And(
+				fb.Eq("id", msg.Header.ID),
+				fb.Eq("state", core.MessageStateReady),
+			), update)
+			if err != nil {
+				return err
+			}
+			if updated == 0 {
+				log.L(ctx).Debugf("Message '%s' is no longer waiting to be batched, so was not expired", msg.Header.ID)
+				continue
+			}
+			log.L(ctx).Warnf("Message '%s' expired at %s before it was confirmed", msg.Header.ID, msg.Header.Expiry)
+			if err := ag.insertMessageEvents(ctx, core.EventTypeMessageExpired, msg, msg.TransactionID, nil); err != nil {
+				return err
+			}
+			cancelled = append(cancelled, msg)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, msg := range cancelled {
+		ag.data.UpdateMessageStateIfCached(ctx, msg.Header.ID, msg.State, now, msg.RejectReason)
+	}
+	return nil
+}
+
+// getPinTime returns the timestamp of the earliest blockchain event for a batch pin transaction
+func (ag *aggregator) getPinTime(ctx context.Context, tx *fftypes.UUID) (pinTime *fftypes.FFTime, err error) {
+	fb := database.BlockchainEventQueryFactory.NewFilter(ctx)
+	events, _, err := ag.database.GetBlockchainEvents(ctx, ag.namespace, fb.Eq("tx.id", tx))
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if event.Timestamp != nil && (pinTime == nil || event.Timestamp.Time().Before(*pinTime.Time())) {
+			pinTime = event.Timestamp
+		}
+	}
+	return pinTime, nil
+}
+
+// pinnedAfterExpiry returns true if the blockchain event that pinned the batch of a message is after
+// the expiry of the message. The time is looked up once per transaction, within each aggregator batch.
+func (ag *aggregator) pinnedAfterExpiry(ctx context.Context, msg *core.Message, tx *fftypes.UUID, state *batchState) (bool, error) {
+	if msg.Header.Expiry == nil || tx == nil {
+		return false, nil
+	}
+	pinTime, cached := state.pinTimes[*tx]
+	if !cached {
+		var err error
+		if pinTime, err = ag.getPinTime(ctx, tx); err != nil {
+			return false, err
+		}
+		state.pinTimes[*tx] = pinTime
+	}
+	return msg.Header.Expired(pinTime), nil
+}
+
+// completeExpiry cancels a message with a pin that was received after its expiry.
+// No events are emitted if the message was already cancelled on this node.
+func (ag *aggregator) completeExpiry(ctx context.Context, msg *core.Message, tx *fftypes.UUID, state *batchState) core.MessageState {
+	log.L(ctx).Warnf("Message '%s' expired at %s before it was confirmed", msg.Header.ID, msg.Header.Expiry)
+	if msg.State != core.MessageStateCancelled {
+		state.AddFinalize(func(ctx context.Context) error {
+			return ag.insertMessageEvents(ctx, core.EventTypeMessageExpired, msg, tx, nil)
+		})
+	}
+	msg.RejectReason = i18n.NewError(ctx, coremsgs.MsgMessageExpired, msg.Header.Expiry).Error()
+	return core.MessageStateCancelled
+}
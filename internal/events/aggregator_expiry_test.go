@@ -0,0 +1,282 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestExpiredMessage(batchID *fftypes.UUID) *core.Message {
+	expiry := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+	return &core.Message{
+		Header: core.MessageHeader{
+			ID:     fftypes.NewUUID(),
+			Topics: fftypes.FFStringArray{"topic1", "topic2"},
+			Expiry: &expiry,
+		},
+		BatchID:       batchID,
+		TransactionID: fftypes.NewUUID(),
+		State:         core.MessageStateSent,
+	}
+}
+
+func TestProcessMsgExpired(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	bs := newBatchState(&ag.aggregator)
+
+	msg1, _, org1, manifest := newTestManifest(core.MessageTypeBroadcast, nil)
+	expiry := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+	msg1.Header.Expiry = &expiry
+
+	ag.mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything).Return(org1, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msg1.Header.ID, data.CRORequirePublicBlobRefs).Return(msg1, core.DataArray{}, true, nil)
+	ag.mdm.On("UpdateMessageStateIfCached", ag.ctx, msg1.Header.ID, core.MessageStateCancelled, mock.Anything, mock.MatchedBy(func(reason string) bool {
+		return assert.Regexp(t, "FF10498", reason)
+	})).Return()
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	ag.mdi.On("GetBlockchainEvents", ag.ctx, "ns1", mock.Anything).Return([]*core.BlockchainEvent{
+		{TX: core.BlockchainTransactionRef{ID: manifest.TX.ID}, Timestamp: fftypes.Now()},
+	}, nil, nil)
+	ag.mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *core.Event) bool {
+		return *e.Reference == *msg1.Header.ID && e.Type == core.EventTypeMessageExpired && *e.Transaction == *manifest.TX.ID
+	})).Return(nil)
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	pin := &core.Pin{Sequence: 12345, Signer: msg1.Header.Key, Created: fftypes.Now()}
+	err := ag.processMessage(ag.ctx, manifest, pin, 0, manifest.Messages[0], &core.BatchPersisted{}, bs)
+	assert.NoError(t, err)
+
+	assert.Len(t, bs.dispatchedMessages, 1)
+	assert.Equal(t, core.MessageStateCancelled, bs.dispatchedMessages[0].newState)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	ag.mdi.AssertNumberOfCalls(t, "InsertEvent", 1)
+}
+
+func TestProcessMsgExpiredAlreadyCancelled(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	bs := newBatchState(&ag.aggregator)
+
+	msg1, _, org1, manifest := newTestManifest(core.MessageTypeBroadcast, nil)
+	expiry := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+	msg1.Header.Expiry = &expiry
+	msg1.State = core.MessageStateCancelled
+
+	ag.mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything).Return(org1, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msg1.Header.ID, data.CRORequirePublicBlobRefs).Return(msg1, core.DataArray{}, true, nil)
+	ag.mdm.On("UpdateMessageStateIfCached", ag.ctx, msg1.Header.ID, core.MessageStateCancelled, mock.Anything, mock.Anything).Return()
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	ag.mdi.On("GetBlockchainEvents", ag.ctx, "ns1", mock.Anything).Return([]*core.BlockchainEvent{
+		{TX: core.BlockchainTransactionRef{ID: manifest.TX.ID}, Timestamp: fftypes.Now()},
+	}, nil, nil)
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	pin := &core.Pin{Sequence: 12345, Signer: msg1.Header.Key, Created: fftypes.Now()}
+	err := ag.processMessage(ag.ctx, manifest, pin, 0, manifest.Messages[0], &core.BatchPersisted{}, bs)
+	assert.NoError(t, err)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	ag.mdi.AssertNotCalled(t, "InsertEvent", mock.Anything, mock.Anything)
+}
+
+func TestProcessMsgCancelledPinnedBeforeExpiry(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	bs := newBatchState(&ag.aggregator)
+
+	msg1, _, org1, manifest := newTestManifest(core.MessageTypeBroadcast, nil)
+	expiry := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+	msg1.Header.Expiry = &expiry
+	msg1.State = core.MessageStateCancelled
+
+	ag.mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything).Return(org1, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msg1.Header.ID, data.CRORequirePublicBlobRefs).Return(msg1, core.DataArray{}, true, nil)
+	blockTime := fftypes.FFTime(time.Now().Add(-2 * time.Minute))
+	ag.mdi.On("GetBlockchainEvents", ag.ctx, "ns1", mock.Anything).Return([]*core.BlockchainEvent{
+		{TX: core.BlockchainTransactionRef{ID: manifest.TX.ID}, Timestamp: &blockTime},
+	}, nil, nil)
+	ag.mdm.On("UpdateMessageStateIfCached", ag.ctx, msg1.Header.ID, core.MessageStateCancelled, mock.Anything, mock.Anything).Return()
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	pin := &core.Pin{Sequence: 12345, Signer: msg1.Header.Key, Created: fftypes.Now()}
+	err := ag.processMessage(ag.ctx, manifest, pin, 0, manifest.Messages[0], &core.BatchPersisted{}, bs)
+	assert.NoError(t, err)
+
+	// The pin is consumed, but the message stays cancelled with no confirmed event
+	assert.Len(t, bs.dispatchedMessages, 1)
+	assert.Equal(t, core.MessageStateCancelled, bs.dispatchedMessages[0].newState)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	ag.mdi.AssertNotCalled(t, "InsertEvent", mock.Anything, mock.Anything)
+	ag.mdm.AssertNotCalled(t, "ValidateAll", mock.Anything, mock.Anything)
+}
+
+func TestProcessMsgPinnedBeforeExpiry(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	bs := newBatchState(&ag.aggregator)
+
+	msg1, _, org1, manifest := newTestManifest(core.MessageTypeBroadcast, nil)
+	expiry := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+	msg1.Header.Expiry = &expiry
+
+	ag.mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything).Return(org1, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msg1.Header.ID, data.CRORequirePublicBlobRefs).Return(msg1, core.DataArray{}, true, nil)
+	ag.mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	blockTime := fftypes.FFTime(time.Now().Add(-2 * time.Minute))
+	ag.mdi.On("GetBlockchainEvents", ag.ctx, "ns1", mock.Anything).Return([]*core.BlockchainEvent{
+		{TX: core.BlockchainTransactionRef{ID: manifest.TX.ID}, Timestamp: fftypes.Now()},
+		{TX: core.BlockchainTransactionRef{ID: manifest.TX.ID}, Timestamp: &blockTime},
+		{TX: core.BlockchainTransactionRef{ID: manifest.TX.ID}},
+		{TX: core.BlockchainTransactionRef{ID: manifest.TX.ID}, Timestamp: fftypes.Now()},
+	}, nil, nil).Once()
+
+	// The pin was created locally after the expiry, but the blockchain event was before it
+	pin := &core.Pin{Sequence: 12345, Signer: msg1.Header.Key, Created: fftypes.Now()}
+	err := ag.processMessage(ag.ctx, manifest, pin, 0, manifest.Messages[0], &core.BatchPersisted{}, bs)
+	assert.NoError(t, err)
+
+	assert.Len(t, bs.dispatchedMessages, 1)
+	assert.Equal(t, core.MessageStateConfirmed, bs.dispatchedMessages[0].newState)
+
+	// The time is cached for the transaction within the aggregator batch
+	expired, err := ag.pinnedAfterExpiry(ag.ctx, msg1, manifest.TX.ID, bs)
+	assert.NoError(t, err)
+	assert.False(t, expired)
+	ag.mdi.AssertExpectations(t)
+}
+
+func TestProcessMsgExpiryGetBlockchainEventsFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	bs := newBatchState(&ag.aggregator)
+
+	msg1, _, org1, manifest := newTestManifest(core.MessageTypeBroadcast, nil)
+	expiry := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+	msg1.Header.Expiry = &expiry
+
+	ag.mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything).Return(org1, nil)
+	ag.mdm.On("GetMessageWithDataCached", ag.ctx, msg1.Header.ID, data.CRORequirePublicBlobRefs).Return(msg1, core.DataArray{}, true, nil)
+	ag.mdi.On("GetPins", ag.ctx, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil)
+	ag.mdi.On("GetBlockchainEvents", ag.ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	pin := &core.Pin{Sequence: 12345, Signer: msg1.Header.Key, Created: fftypes.Now()}
+	err := ag.processMessage(ag.ctx, manifest, pin, 0, manifest.Messages[0], &core.BatchPersisted{}, bs)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExpiryLoop(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	ag.expiryInterval = 1 * time.Millisecond
+
+	ag.mdi.On("GetMessages", ag.ctx, "ns1", mock.Anything).Return([]*core.Message{}, nil, nil).
+		Run(func(args mock.Arguments) {
+			ag.cancel()
+		})
+
+	ag.expiryLoop()
+	<-ag.expiryDone
+}
+
+func TestExpireMessages(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mockRunAsGroupPassthrough(ag.mdi)
+
+	msg1 := newTestExpiredMessage(nil)
+	msg1.State = core.MessageStateReady
+	msg2 := newTestExpiredMessage(nil)
+	msg2.State = core.MessageStateReady
+
+	ag.mdi.On("GetMessages", ag.ctx, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return strings.Contains(fi.String(), "state == 'ready'")
+	})).Return([]*core.Message{msg1, msg2}, nil, nil)
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil).Once()
+	// msg2 was sent since it was queried, so is not updated
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(0), nil).Once()
+	ag.mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *core.Event) bool {
+		return e.Type == core.EventTypeMessageExpired
+	})).Return(nil).Twice()
+	ag.mdm.On("UpdateMessageStateIfCached", ag.ctx, msg1.Header.ID, core.MessageStateCancelled, mock.Anything, mock.Anything).Return()
+
+	err := ag.expireMessages(ag.ctx)
+	assert.NoError(t, err)
+
+	assert.Regexp(t, "FF10498", msg1.RejectReason)
+	ag.mdi.AssertExpectations(t)
+	ag.mdm.AssertExpectations(t)
+}
+
+func TestExpireMessagesGetMessagesFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+
+	ag.mdi.On("GetMessages", ag.ctx, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := ag.expireMessages(ag.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExpireMessagesUpdateFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mockRunAsGroupPassthrough(ag.mdi)
+
+	ag.mdi.On("GetMessages", ag.ctx, "ns1", mock.Anything).Return([]*core.Message{newTestExpiredMessage(nil)}, nil, nil)
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(0), fmt.Errorf("pop"))
+
+	err := ag.expireMessages(ag.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExpireMessagesInsertEventFail(t *testing.T) {
+	ag := newTestAggregator()
+	defer ag.cleanup(t)
+	mockRunAsGroupPassthrough(ag.mdi)
+
+	ag.mdi.On("GetMessages", ag.ctx, "ns1", mock.Anything).Return([]*core.Message{newTestExpiredMessage(nil)}, nil, nil)
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
+	ag.mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := ag.expireMessages(ag.ctx)
+	assert.EqualError(t, err, "pop")
+}
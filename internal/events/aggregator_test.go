@@ -330,7 +330,7 @@ func TestAggregationMaskedZeroNonceMatch(t *testing.T) {
 		assert.Equal(t, "confirmed", v)
 
 		return true
-	})).Return(nil)
+	})).Return(int64(1), nil)
 
 	err := ag.processPins(ag.ctx, []*core.Pin{
 		{
@@ -445,7 +445,7 @@ func TestAggregationMaskedNextSequenceMatch(t *testing.T) {
 	// Set the pin to dispatched
 	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
 	// Update the message
-	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
 
 	_, err := ag.processPinsEventsHandler([]core.LocallySequenced{
 		&core.Pin{
@@ -524,7 +524,7 @@ func TestAggregationBroadcast(t *testing.T) {
 	// Set the pin to dispatched
 	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
 	// Update the message
-	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
 
 	err := ag.processPins(ag.ctx, []*core.Pin{
 		{
@@ -611,7 +611,7 @@ func TestAggregationMigratedBroadcast(t *testing.T) {
 	// Set the pin to dispatched
 	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
 	// Update the message
-	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
 
 	err = ag.processPins(ag.ctx, []*core.Pin{
 		{
@@ -1232,7 +1232,7 @@ func TestProcessMsgGapFill(t *testing.T) {
 	ag.mdi.On("UpdateNextPin", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
 	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
 
-	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Twice().Return(nil)
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Twice().Return(int64(1), nil)
 
 	err := ag.processMessage(ag.ctx, &core.BatchManifest{
 		ID: fftypes.NewUUID(),
@@ -1794,7 +1794,7 @@ func TestDefinitionBroadcastActionRejectFailUpdate(t *testing.T) {
 		return event.Type == core.EventTypeMessageRejected && event.Correlator.Equals(customCorrelator)
 	})).Return(nil)
 	ag.mdm.On("UpdateMessageStateIfCached", ag.ctx, msg.Header.ID, core.MessageStateRejected, mock.Anything, "reject-reason").Return()
-	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	ag.mdi.On("UpdateMessages", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(0), fmt.Errorf("pop"))
 
 	newState := ag.completeDispatch(core.ActionReject, customCorrelator, msg, nil, bs)
 	assert.Equal(t, core.MessageStateRejected, newState)
@@ -1980,7 +1980,7 @@ func TestDefinitionBroadcastActionReject(t *testing.T) {
 		assert.Equal(t, "pop", v)
 
 		return true
-	})).Return(nil)
+	})).Return(int64(1), nil)
 	ag.mdi.On("UpdatePins", ag.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
 
 	err = bs.RunFinalize(ag.ctx)
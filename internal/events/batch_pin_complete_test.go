@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -652,6 +653,93 @@ func TestPersistBatchGoodDataUpsertOptimizeFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestPersistBatchOffloadLargeValues(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	em.mdm = &datamocks.Manager{}
+	em.data = em.mdm
+	data := &core.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, core.BatchTypePrivate, core.TransactionTypeBatchPin, core.DataArray{data})
+	// A reference to a value in the blob store of the sender is not kept
+	data.ValueRef = &core.DataValueRef{PayloadRef: "peer/value1"}
+
+	offloaded := *data
+	offloaded.Value = nil
+	offloaded.ValueRef = &core.DataValueRef{Size: 6, PayloadRef: "ns1/value1"}
+	em.mdm.On("OffloadLargeValues", mock.Anything, mock.MatchedBy(func(data core.DataArray) bool {
+		return data[0].ValueRef == nil
+	})).Return(core.DataArray{&offloaded}, nil)
+	em.mdm.On("UpdateMessageCache", mock.Anything, core.DataArray{&offloaded}).Return()
+
+	em.mdi.On("InsertOrGetBatch", mock.Anything, mock.Anything).Return(nil, nil)
+	em.mdi.On("InsertDataArray", mock.Anything, core.DataArray{&offloaded}).Return(nil)
+	em.mdi.On("InsertMessages", mock.Anything, mock.Anything, mock.AnythingOfType("database.PostCompletionHook")).
+		Run(func(args mock.Arguments) {
+			args[2].(database.PostCompletionHook)()
+		}).
+		Return(nil)
+
+	em.mim.On("GetLocalNode", mock.Anything).Return(testNode, nil)
+
+	bp, valid, err := em.persistBatch(context.Background(), batch)
+	assert.NotNil(t, bp)
+	assert.True(t, valid)
+	assert.NoError(t, err)
+	// The received batch is unchanged, so it can be processed again
+	assert.Equal(t, `"test"`, data.Value.String())
+
+	em.mdm.AssertExpectations(t)
+}
+
+func TestPersistBatchOffloadLargeValuesFail(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	em.mdm = &datamocks.Manager{}
+	em.data = em.mdm
+	data := &core.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, core.BatchTypePrivate, core.TransactionTypeBatchPin, core.DataArray{data})
+
+	em.mdm.On("OffloadLargeValues", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	em.mdi.On("InsertOrGetBatch", mock.Anything, mock.Anything).Return(nil, nil)
+	em.mim.On("GetLocalNode", mock.Anything).Return(testNode, nil)
+
+	bp, valid, err := em.persistBatch(context.Background(), batch)
+	assert.Nil(t, bp)
+	assert.False(t, valid)
+	assert.EqualError(t, err, "pop")
+
+	em.mdm.AssertExpectations(t)
+}
+
+func TestPersistBatchDeleteLargeValuesOnFail(t *testing.T) {
+	em := newTestEventManager(t)
+	defer em.cleanup(t)
+	em.mdm = &datamocks.Manager{}
+	em.data = em.mdm
+	data := &core.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, core.BatchTypePrivate, core.TransactionTypeBatchPin, core.DataArray{data})
+
+	offloaded := *data
+	offloaded.Value = nil
+	offloaded.ValueRef = &core.DataValueRef{Size: 6, PayloadRef: "ns1/value1"}
+	em.mdm.On("OffloadLargeValues", mock.Anything, mock.Anything).Return(core.DataArray{&offloaded}, nil)
+	em.mdm.On("DeleteLargeValues", mock.Anything, core.DataArray{&offloaded}).Return()
+
+	em.mdi.On("InsertOrGetBatch", mock.Anything, mock.Anything).Return(nil, nil)
+	em.mdi.On("InsertDataArray", mock.Anything, mock.Anything).Return(nil)
+	em.mdi.On("InsertMessages", mock.Anything, mock.Anything, mock.AnythingOfType("database.PostCompletionHook")).Return(fmt.Errorf("optimzation miss"))
+	em.mdi.On("UpsertMessage", mock.Anything, mock.Anything, database.UpsertOptimizationExisting, mock.AnythingOfType("database.PostCompletionHook")).Return(fmt.Errorf("pop"))
+
+	em.mim.On("GetLocalNode", mock.Anything).Return(testNode, nil)
+
+	bp, valid, err := em.persistBatch(context.Background(), batch)
+	assert.Nil(t, bp)
+	assert.False(t, valid)
+	assert.EqualError(t, err, "pop")
+
+	em.mdm.AssertExpectations(t)
+}
+
 func TestPersistBatchGoodDataMessageFail(t *testing.T) {
 	em := newTestEventManager(t)
 	defer em.cleanup(t)
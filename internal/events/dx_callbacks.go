@@ -149,7 +149,7 @@ func (em *eventManager) markUnpinnedMessagesConfirmed(ctx context.Context, batch
 		Set("state", core.MessageStateConfirmed).
 		Set("confirmed", fftypes.Now())
 
-	if err := em.database.UpdateMessages(ctx, em.namespace.Name, filter, update); err != nil {
+	if _, err := em.database.UpdateMessages(ctx, em.namespace.Name, filter, update); err != nil {
 		return err
 	}
 
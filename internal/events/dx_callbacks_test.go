@@ -691,7 +691,7 @@ func TestMessageReceiveUnpinnedBatchOk(t *testing.T) {
 	em.mdi.On("InsertMessages", em.ctx, mock.Anything, mock.AnythingOfType("database.PostCompletionHook")).Return(nil, nil).Run(func(args mock.Arguments) {
 		args[2].(database.PostCompletionHook)()
 	})
-	em.mdi.On("UpdateMessages", em.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	em.mdi.On("UpdateMessages", em.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
 	em.mdi.On("InsertEvent", em.ctx, mock.Anything).Return(nil)
 	em.mdm.On("UpdateMessageCache", mock.Anything, mock.Anything).Return()
 
@@ -734,7 +734,7 @@ func TestMessageReceiveUnpinnedBatchConfirmMessagesFail(t *testing.T) {
 	em.mdi.On("InsertMessages", em.ctx, mock.Anything, mock.AnythingOfType("database.PostCompletionHook")).Return(nil, nil).Run(func(args mock.Arguments) {
 		args[2].(database.PostCompletionHook)()
 	})
-	em.mdi.On("UpdateMessages", em.ctx, "ns1", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	em.mdi.On("UpdateMessages", em.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(0), fmt.Errorf("pop"))
 	em.mdm.On("UpdateMessageCache", mock.Anything, mock.Anything).Return()
 
 	// no ack as we are simulating termination mid retry
@@ -777,7 +777,7 @@ func TestMessageReceiveUnpinnedBatchPersistEventFail(t *testing.T) {
 	em.mdi.On("InsertMessages", em.ctx, mock.Anything, mock.AnythingOfType("database.PostCompletionHook")).Return(nil, nil).Run(func(args mock.Arguments) {
 		args[2].(database.PostCompletionHook)()
 	})
-	em.mdi.On("UpdateMessages", em.ctx, "ns1", mock.Anything, mock.Anything).Return(nil)
+	em.mdi.On("UpdateMessages", em.ctx, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
 	em.mdi.On("InsertEvent", em.ctx, mock.Anything).Return(fmt.Errorf("pop"))
 	em.mdm.On("UpdateMessageCache", mock.Anything, mock.Anything).Return()
 
@@ -22,6 +22,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/events/grpcstreams"
 	"github.com/hyperledger/firefly/internal/events/system"
 	"github.com/hyperledger/firefly/internal/events/webhooks"
 	"github.com/hyperledger/firefly/internal/events/websockets"
@@ -32,6 +33,7 @@ var plugins = []events.Plugin{
 	&websockets.WebSockets{},
 	&webhooks.WebHooks{},
 	&system.Events{},
+	&grpcstreams.GRPCStreams{},
 }
 
 var pluginsByName = make(map[string]events.Plugin)
@@ -52,6 +52,13 @@ func TestGetPluginEvents(t *testing.T) {
 	assert.NotNil(t, plugin)
 }
 
+func TestGetPluginGRPCStreams(t *testing.T) {
+	ctx := context.Background()
+	plugin, err := GetPlugin(ctx, "grpc")
+	assert.NoError(t, err)
+	assert.NotNil(t, plugin)
+}
+
 var root = config.RootSection("di")
 
 func TestInitConfig(t *testing.T) {
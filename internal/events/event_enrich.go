@@ -68,7 +68,7 @@ func (em *eventEnricher) enrichEvent(ctx context.Context, event *core.Event) (*c
 			return nil, err
 		}
 		e.Transaction = tx
-	case core.EventTypeMessageConfirmed, core.EventTypeMessageRejected:
+	case core.EventTypeMessageConfirmed, core.EventTypeMessageRejected, core.EventTypeMessageExpired:
 		msg, _, _, err := em.data.GetMessageWithDataCached(ctx, event.Reference)
 		if err != nil {
 			return nil, err
@@ -92,6 +92,12 @@ func (em *eventEnricher) enrichEvent(ctx context.Context, event *core.Event) (*c
 			return nil, err
 		}
 		e.ContractInterface = contractInterface
+	case core.EventTypeDataRedacted:
+		d, err := em.database.GetDataByID(ctx, em.namespace, event.Reference, false)
+		if err != nil {
+			return nil, err
+		}
+		e.Data = d
 	case core.EventTypeDatatypeConfirmed:
 		dt, err := em.database.GetDatatypeByID(ctx, em.namespace, event.Reference)
 		if err != nil {
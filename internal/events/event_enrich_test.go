@@ -144,6 +144,31 @@ func TestEnrichMessageRejected(t *testing.T) {
 	assert.Equal(t, ref1, enriched.Message.Header.ID)
 }
 
+func TestEnrichMessageExpired(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(&core.Message{
+		Header: core.MessageHeader{ID: ref1},
+	}, nil, true, nil)
+
+	event := &core.Event{
+		ID:        ev1,
+		Type:      core.EventTypeMessageExpired,
+		Reference: ref1,
+	}
+
+	enriched, err := em.enrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.Message.Header.ID)
+}
+
 func TestEnrichTxSubmitted(t *testing.T) {
 	em := newTestEventEnricher()
 	ctx := context.Background()
@@ -332,6 +357,55 @@ func TestEnrichContractInterfacetFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestEnrichDataRedacted(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetDataByID", mock.Anything, "ns1", ref1, false).Return(&core.Data{
+		ID:       ref1,
+		Redacted: &core.DataRedaction{Author: "dpo@example.com"},
+	}, nil)
+
+	event := &core.Event{
+		ID:        ev1,
+		Type:      core.EventTypeDataRedacted,
+		Reference: ref1,
+	}
+
+	enriched, err := em.enrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.Data.ID)
+	assert.Equal(t, "dpo@example.com", enriched.Data.Redacted.Author)
+}
+
+func TestEnrichDataRedactedFail(t *testing.T) {
+	em := newTestEventEnricher()
+	ctx := context.Background()
+
+	// Setup the IDs
+	ref1 := fftypes.NewUUID()
+	ev1 := fftypes.NewUUID()
+
+	// Setup enrichment
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetDataByID", mock.Anything, "ns1", ref1, false).Return(nil, fmt.Errorf("pop"))
+
+	event := &core.Event{
+		ID:        ev1,
+		Type:      core.EventTypeDataRedacted,
+		Reference: ref1,
+	}
+
+	_, err := em.enrichEvent(ctx, event)
+	assert.EqualError(t, err, "pop")
+}
+
 func TestEnrichDatatypeConfirmed(t *testing.T) {
 	em := newTestEventEnricher()
 	ctx := context.Background()
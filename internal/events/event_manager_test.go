@@ -147,6 +147,10 @@ func newTestEventManagerCommon(t *testing.T, metrics, dbconcurrency bool) *testE
 	mdi.On("Capabilities").Return(&database.Capabilities{Concurrency: dbconcurrency}).Maybe()
 	mev.On("SetHandler", "ns1", mock.Anything).Return(nil).Maybe()
 	mev.On("ValidateOptions", mock.Anything, mock.Anything).Return(nil).Maybe()
+	mdm.On("OffloadLargeValues", mock.Anything, mock.Anything).Return(func(_ context.Context, data core.DataArray) core.DataArray {
+		return data
+	}, nil).Maybe()
+	mdm.On("DeleteLargeValues", mock.Anything, mock.Anything).Maybe()
 	ns := &core.Namespace{Name: "ns1", NetworkName: "ns1"}
 	emi, err := NewEventManager(ctx, ns, mdi, mbi, mim, msh, mdm, mds, mbm, mpm, mam, msd, mmi, mom, txHelper, events, mmp, cmi)
 	em := emi.(*eventManager)
@@ -0,0 +1,141 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcstreams
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+type eventStream struct {
+	ctx        context.Context
+	cancelCtx  func()
+	gs         *GRPCStreams
+	connID     string
+	start      *core.WSStart
+	startTime  *fftypes.FFTime
+	autoAck    bool
+	send       SendFunc
+	deliveries chan *core.EventDelivery
+	inflight   []*core.EventDeliveryResponse
+	mux        sync.Mutex
+}
+
+func newEventStream(pCtx context.Context, gs *GRPCStreams, start *core.WSStart, send SendFunc) *eventStream {
+	connID := fftypes.NewUUID().String()
+	ctx := log.WithLogField(pCtx, "grpcstream", connID)
+	ctx, cancelCtx := context.WithCancel(ctx)
+	return &eventStream{
+		ctx:        ctx,
+		cancelCtx:  cancelCtx,
+		gs:         gs,
+		connID:     connID,
+		start:      start,
+		startTime:  fftypes.Now(),
+		autoAck:    start.AutoAck != nil && *start.AutoAck,
+		send:       send,
+		deliveries: make(chan *core.EventDelivery),
+	}
+}
+
+// sendLoop runs on the goroutine of the stream, as only that goroutine can write to it
+func (es *eventStream) sendLoop() error {
+	l := log.L(es.ctx)
+	for {
+		select {
+		case event := <-es.deliveries:
+			l.Tracef("Sending: %s", event.ID)
+			if err := es.send(es.connID, event); err != nil {
+				l.Errorf("Send failed on stream: %s", err)
+				return err
+			}
+		case <-es.ctx.Done():
+			l.Debugf("Stream closing - context cancelled")
+			return nil
+		}
+	}
+}
+
+func (es *eventStream) dispatch(event *core.EventDelivery) error {
+	inflight := &core.EventDeliveryResponse{
+		ID:           event.ID,
+		Subscription: event.Subscription,
+	}
+
+	if !es.autoAck {
+		es.mux.Lock()
+		es.inflight = append(es.inflight, inflight)
+		es.mux.Unlock()
+	}
+
+	select {
+	case es.deliveries <- event:
+	case <-es.ctx.Done():
+		return i18n.NewError(es.ctx, coremsgs.MsgGRPCStreamNotActive, es.connID)
+	}
+
+	if es.autoAck {
+		es.gs.ack(es.connID, inflight)
+	}
+	return nil
+}
+
+func (es *eventStream) durableSubMatcher(sr core.SubscriptionRef) bool {
+	return sr.Namespace == es.start.Namespace && sr.Name == es.start.Name
+}
+
+func (es *eventStream) checkAck(ctx context.Context, ack *core.WSAck) (*core.EventDeliveryResponse, error) {
+	if es.autoAck {
+		return nil, i18n.NewError(ctx, coremsgs.MsgGRPCAutoAckEnabled, es.connID)
+	}
+
+	es.mux.Lock()
+	defer es.mux.Unlock()
+	for i, candidate := range es.inflight {
+		// Without an ID, the oldest event in flight is acknowledged
+		if ack.ID == nil || (candidate.ID.Equals(ack.ID) && (ack.Subscription == nil ||
+			(ack.Subscription.ID != nil && ack.Subscription.ID.Equals(candidate.Subscription.ID)) ||
+			(ack.Subscription.Name == candidate.Subscription.Name && ack.Subscription.Namespace == candidate.Subscription.Namespace))) {
+			es.inflight = append(es.inflight[0:i:i], es.inflight[i+1:]...)
+			return candidate, nil
+		}
+	}
+	return nil, i18n.NewError(ctx, coremsgs.MsgGRPCAckNotMatched, es.connID)
+}
+
+func (es *eventStream) restartForNamespace(ns string, startTime time.Time) {
+	es.mux.Lock()
+	restart := es.start.Namespace == ns && es.startTime.Time().Before(startTime)
+	if restart {
+		es.startTime = fftypes.Now()
+	}
+	es.mux.Unlock()
+	if restart {
+		log.L(es.ctx).Infof("Restarting subscription '%s:%s' (ephemeral=%t)", es.start.Namespace, es.start.Name, es.start.Ephemeral)
+		if err := es.gs.start(es); err != nil {
+			log.L(es.ctx).Errorf("Failed restart subscription '%s:%s' (closing): %s", es.start.Namespace, es.start.Name, err)
+			es.cancelCtx()
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcstreams
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+)
+
+// SendFunc writes an event to the server stream of a gRPC client, along with the
+// ID of the connection that the client uses to acknowledge it
+type SendFunc func(connID string, event *core.EventDelivery) error
+
+// GRPCStreams is the transport for events delivered over server streams of the gRPC API.
+// Each stream is a connection with a single subscription, which lasts as long as the stream.
+type GRPCStreams struct {
+	ctx          context.Context
+	capabilities *events.Capabilities
+	callbacks    callbacks
+	streams      map[string]*eventStream
+	streamsMux   sync.Mutex
+}
+
+type callbacks struct {
+	writeLock sync.Mutex
+	handlers  map[string]events.Callbacks
+}
+
+func (gs *GRPCStreams) Name() string { return "grpc" }
+
+func (gs *GRPCStreams) InitConfig(config config.Section) {}
+
+func (gs *GRPCStreams) Init(ctx context.Context, config config.Section) error {
+	*gs = GRPCStreams{
+		ctx:          ctx,
+		streams:      make(map[string]*eventStream),
+		capabilities: &events.Capabilities{},
+		callbacks: callbacks{
+			handlers: make(map[string]events.Callbacks),
+		},
+	}
+	return nil
+}
+
+func (gs *GRPCStreams) SetHandler(namespace string, handler events.Callbacks) error {
+	gs.callbacks.writeLock.Lock()
+	defer gs.callbacks.writeLock.Unlock()
+	if handler == nil {
+		delete(gs.callbacks.handlers, namespace)
+		return nil
+	}
+	gs.callbacks.handlers[namespace] = handler
+	return nil
+}
+
+func (gs *GRPCStreams) handler(namespace string) (events.Callbacks, bool) {
+	gs.callbacks.writeLock.Lock()
+	defer gs.callbacks.writeLock.Unlock()
+	cb, ok := gs.callbacks.handlers[namespace]
+	return cb, ok
+}
+
+func (gs *GRPCStreams) Capabilities() *events.Capabilities {
+	return gs.capabilities
+}
+
+func (gs *GRPCStreams) ValidateOptions(ctx context.Context, options *core.SubscriptionOptions) error {
+	// As with websockets, only the references are streamed, and each event is acknowledged individually
+	if options.WithData != nil && *options.WithData {
+		return i18n.NewError(ctx, coremsgs.MsgGRPCStreamsNoData)
+	}
+	if options.Batch != nil && *options.Batch {
+		return i18n.NewError(ctx, coremsgs.MsgBatchDeliveryNotSupported, gs.Name())
+	}
+	forceFalse := false
+	options.WithData = &forceFalse
+	return nil
+}
+
+func (gs *GRPCStreams) getStream(ctx context.Context, connID string) (*eventStream, error) {
+	gs.streamsMux.Lock()
+	es, ok := gs.streams[connID]
+	gs.streamsMux.Unlock()
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgGRPCStreamNotActive, connID)
+	}
+	return es, nil
+}
+
+func (gs *GRPCStreams) DeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, event *core.EventDelivery, data core.DataArray) error {
+	es, err := gs.getStream(ctx, connID)
+	if err != nil {
+		return err
+	}
+	return es.dispatch(event)
+}
+
+func (gs *GRPCStreams) BatchDeliveryRequest(ctx context.Context, connID string, sub *core.Subscription, events []*core.CombinedEventDataDelivery) error {
+	return i18n.NewError(ctx, coremsgs.MsgBatchDeliveryNotSupported, gs.Name()) // should never happen
+}
+
+// ServeStream starts the subscription of a server stream, and sends the events delivered
+// to it until the context of the stream is cancelled, or a send fails
+func (gs *GRPCStreams) ServeStream(ctx context.Context, start *core.WSStart, send SendFunc) error {
+	if !start.Ephemeral && start.Name == "" {
+		return i18n.NewError(ctx, coremsgs.MsgGRPCInvalidSubscribe)
+	}
+	es := newEventStream(ctx, gs, start, send)
+	gs.streamsMux.Lock()
+	gs.streams[es.connID] = es
+	gs.streamsMux.Unlock()
+	defer gs.streamClosed(es)
+
+	if err := gs.start(es); err != nil {
+		return err
+	}
+	return es.sendLoop()
+}
+
+// Ack acknowledges an event delivered on a stream in the namespace. Acks are not checked
+// against the principal that started the stream, as they are only accepted for events
+// sent on the stream, and the ID of the stream is only known to that client.
+func (gs *GRPCStreams) Ack(ctx context.Context, namespace, connID string, ack *core.WSAck) error {
+	es, err := gs.getStream(ctx, connID)
+	if err == nil && es.start.Namespace != namespace {
+		err = i18n.NewError(ctx, coremsgs.MsgGRPCStreamNotActive, connID)
+	}
+	if err != nil {
+		return err
+	}
+	inflight, err := es.checkAck(ctx, ack)
+	if err != nil {
+		return err
+	}
+	gs.ack(connID, inflight)
+	return nil
+}
+
+func (gs *GRPCStreams) ack(connID string, inflight *core.EventDeliveryResponse) {
+	if cb, ok := gs.handler(inflight.Subscription.Namespace); ok {
+		cb.DeliveryResponse(connID, inflight)
+	}
+}
+
+func (gs *GRPCStreams) start(es *eventStream) error {
+	start := es.start
+	if cb, ok := gs.handler(start.Namespace); ok {
+		if start.Ephemeral {
+			return cb.EphemeralSubscription(es.connID, start.Namespace, &start.Filter, &start.Options)
+		}
+		return cb.RegisterConnection(es.connID, es.durableSubMatcher)
+	}
+	return i18n.NewError(es.ctx, coremsgs.MsgNamespaceDoesNotExist)
+}
+
+func (gs *GRPCStreams) streamClosed(es *eventStream) {
+	es.cancelCtx()
+	gs.streamsMux.Lock()
+	delete(gs.streams, es.connID)
+	gs.streamsMux.Unlock()
+	// Drop lock before calling back
+	gs.callbacks.writeLock.Lock()
+	handlers := make([]events.Callbacks, 0, len(gs.callbacks.handlers))
+	for _, cb := range gs.callbacks.handlers {
+		handlers = append(handlers, cb)
+	}
+	gs.callbacks.writeLock.Unlock()
+	for _, cb := range handlers {
+		cb.ConnectionClosed(es.connID)
+	}
+}
+
+func (gs *GRPCStreams) NamespaceRestarted(ns string, startTime time.Time) {
+	gs.streamsMux.Lock()
+	streams := make([]*eventStream, 0, len(gs.streams))
+	for _, es := range gs.streams {
+		streams = append(streams, es)
+	}
+	gs.streamsMux.Unlock()
+
+	for _, es := range streams {
+		es.restartForNamespace(ns, startTime)
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcstreams
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testSend struct {
+	events chan *core.EventDelivery
+	err    error
+}
+
+func newTestSend() *testSend {
+	return &testSend{
+		events: make(chan *core.EventDelivery, 10),
+	}
+}
+
+func (ts *testSend) send(connID string, event *core.EventDelivery) error {
+	ts.events <- event
+	return ts.err
+}
+
+func newTestGRPCStreams(t *testing.T, cbs *eventsmocks.Callbacks) *GRPCStreams {
+	gs := &GRPCStreams{}
+	conf := config.RootSection("ut.grpc")
+	gs.InitConfig(conf)
+	err := gs.Init(context.Background(), conf)
+	assert.NoError(t, err)
+	assert.Equal(t, "grpc", gs.Name())
+	assert.Equal(t, &events.Capabilities{}, gs.Capabilities())
+	if cbs != nil {
+		gs.SetHandler("ns1", cbs)
+		cbs.On("ConnectionClosed", mock.Anything).Return().Maybe()
+	}
+	return gs
+}
+
+// serveTestStream runs a stream in the background, returning its connection ID once the subscription
+// has started, and a function that cancels the stream and returns the result of serving it
+func serveTestStream(t *testing.T, gs *GRPCStreams, cbs *eventsmocks.Callbacks, start *core.WSStart, ts *testSend) (string, func() error) {
+	started := make(chan string)
+	if start.Ephemeral {
+		cbs.On("EphemeralSubscription", mock.Anything, "ns1", &start.Filter, &start.Options).
+			Run(func(args mock.Arguments) { started <- args[0].(string) }).Return(nil).Once()
+	} else {
+		cbs.On("RegisterConnection", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { started <- args[0].(string) }).Return(nil).Once()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- gs.ServeStream(ctx, start, ts.send)
+	}()
+	connID := <-started
+	return connID, func() error {
+		cancel()
+		return <-done
+	}
+}
+
+func newTestEvent() *core.EventDelivery {
+	return &core.EventDelivery{
+		EnrichedEvent: core.EnrichedEvent{
+			Event: core.Event{ID: fftypes.NewUUID()},
+		},
+		Subscription: core.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+	}
+}
+
+func TestSetHandler(t *testing.T) {
+	gs := newTestGRPCStreams(t, nil)
+	cbs := &eventsmocks.Callbacks{}
+	err := gs.SetHandler("ns1", cbs)
+	assert.NoError(t, err)
+	_, ok := gs.handler("ns1")
+	assert.True(t, ok)
+	err = gs.SetHandler("ns1", nil)
+	assert.NoError(t, err)
+	_, ok = gs.handler("ns1")
+	assert.False(t, ok)
+}
+
+func TestValidateOptions(t *testing.T) {
+	gs := newTestGRPCStreams(t, nil)
+	options := &core.SubscriptionOptions{}
+	err := gs.ValidateOptions(context.Background(), options)
+	assert.NoError(t, err)
+	assert.False(t, *options.WithData)
+
+	yes := true
+	err = gs.ValidateOptions(context.Background(), &core.SubscriptionOptions{
+		SubscriptionCoreOptions: core.SubscriptionCoreOptions{WithData: &yes},
+	})
+	assert.Regexp(t, "FF10558", err)
+
+	err = gs.ValidateOptions(context.Background(), &core.SubscriptionOptions{
+		SubscriptionCoreOptions: core.SubscriptionCoreOptions{Batch: &yes},
+	})
+	assert.Regexp(t, "FF10461.*grpc", err)
+}
+
+func TestBatchDeliveryRequest(t *testing.T) {
+	gs := newTestGRPCStreams(t, nil)
+	err := gs.BatchDeliveryRequest(context.Background(), "conn1", nil, nil)
+	assert.Regexp(t, "FF10461", err)
+}
+
+func TestDeliveryRequestNotActive(t *testing.T) {
+	gs := newTestGRPCStreams(t, nil)
+	err := gs.DeliveryRequest(context.Background(), "conn1", nil, newTestEvent(), nil)
+	assert.Regexp(t, "FF10557", err)
+}
+
+func TestServeStreamInvalid(t *testing.T) {
+	gs := newTestGRPCStreams(t, nil)
+	err := gs.ServeStream(context.Background(), &core.WSStart{Namespace: "ns1"}, newTestSend().send)
+	assert.Regexp(t, "FF10559", err)
+}
+
+func TestServeStreamUnknownNamespace(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	gs := newTestGRPCStreams(t, cbs)
+	err := gs.ServeStream(context.Background(), &core.WSStart{Namespace: "ns2", Name: "sub1"}, newTestSend().send)
+	assert.Regexp(t, "FF10187", err)
+	assert.Empty(t, gs.streams)
+	cbs.AssertCalled(t, "ConnectionClosed", mock.Anything)
+}
+
+func TestServeStreamDurableWithAck(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	gs := newTestGRPCStreams(t, cbs)
+	ts := newTestSend()
+	connID, stop := serveTestStream(t, gs, cbs, &core.WSStart{Namespace: "ns1", Name: "sub1"}, ts)
+
+	matcher := cbs.Calls[0].Arguments[1].(events.SubscriptionMatcher)
+	assert.True(t, matcher(core.SubscriptionRef{Namespace: "ns1", Name: "sub1"}))
+	assert.False(t, matcher(core.SubscriptionRef{Namespace: "ns1", Name: "sub2"}))
+
+	event1 := newTestEvent()
+	event2 := newTestEvent()
+	err := gs.DeliveryRequest(context.Background(), connID, nil, event1, nil)
+	assert.NoError(t, err)
+	err = gs.DeliveryRequest(context.Background(), connID, nil, event2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, event1, <-ts.events)
+	assert.Equal(t, event2, <-ts.events)
+
+	acked := make(chan *core.EventDeliveryResponse, 2)
+	cbs.On("DeliveryResponse", connID, mock.Anything).Run(func(args mock.Arguments) {
+		acked <- args[1].(*core.EventDeliveryResponse)
+	}).Return()
+
+	// Acks must be for the namespace of the stream, and for an event in flight
+	err = gs.Ack(context.Background(), "ns2", connID, &core.WSAck{ID: event2.ID})
+	assert.Regexp(t, "FF10557", err)
+	err = gs.Ack(context.Background(), "ns1", connID, &core.WSAck{ID: fftypes.NewUUID()})
+	assert.Regexp(t, "FF10560", err)
+	err = gs.Ack(context.Background(), "ns1", connID, &core.WSAck{ID: event2.ID, Subscription: &core.SubscriptionRef{Namespace: "ns1", Name: "sub2"}})
+	assert.Regexp(t, "FF10560", err)
+
+	err = gs.Ack(context.Background(), "ns1", connID, &core.WSAck{ID: event2.ID, Subscription: &core.SubscriptionRef{ID: event2.Subscription.ID}})
+	assert.NoError(t, err)
+	assert.Equal(t, event2.ID, (<-acked).ID)
+	err = gs.Ack(context.Background(), "ns1", connID, &core.WSAck{})
+	assert.NoError(t, err)
+	assert.Equal(t, event1.ID, (<-acked).ID)
+	err = gs.Ack(context.Background(), "ns1", connID, &core.WSAck{})
+	assert.Regexp(t, "FF10560", err)
+
+	assert.NoError(t, stop())
+	err = gs.Ack(context.Background(), "ns1", connID, &core.WSAck{})
+	assert.Regexp(t, "FF10557", err)
+	cbs.AssertCalled(t, "ConnectionClosed", connID)
+}
+
+func TestServeStreamEphemeralAutoAck(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	gs := newTestGRPCStreams(t, cbs)
+	ts := newTestSend()
+	autoAck := true
+	connID, stop := serveTestStream(t, gs, cbs, &core.WSStart{Namespace: "ns1", Ephemeral: true, AutoAck: &autoAck}, ts)
+
+	acked := make(chan *core.EventDeliveryResponse, 1)
+	cbs.On("DeliveryResponse", connID, mock.Anything).Run(func(args mock.Arguments) {
+		acked <- args[1].(*core.EventDeliveryResponse)
+	}).Return()
+
+	event := newTestEvent()
+	err := gs.DeliveryRequest(context.Background(), connID, nil, event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, event, <-ts.events)
+	assert.Equal(t, event.ID, (<-acked).ID)
+
+	err = gs.Ack(context.Background(), "ns1", connID, &core.WSAck{ID: event.ID})
+	assert.Regexp(t, "FF10561", err)
+
+	assert.NoError(t, stop())
+}
+
+func TestServeStreamSendFails(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	gs := newTestGRPCStreams(t, cbs)
+	ts := newTestSend()
+	ts.err = fmt.Errorf("pop")
+	connID, stop := serveTestStream(t, gs, cbs, &core.WSStart{Namespace: "ns1", Name: "sub1"}, ts)
+
+	err := gs.DeliveryRequest(context.Background(), connID, nil, newTestEvent(), nil)
+	assert.NoError(t, err)
+	<-ts.events
+	assert.Regexp(t, "pop", stop())
+}
+
+func TestDispatchStreamClosed(t *testing.T) {
+	gs := newTestGRPCStreams(t, nil)
+	es := newEventStream(context.Background(), gs, &core.WSStart{Namespace: "ns1", Name: "sub1"}, newTestSend().send)
+	es.cancelCtx()
+	err := es.dispatch(newTestEvent())
+	assert.Regexp(t, "FF10557", err)
+}
+
+func TestNamespaceRestarted(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	gs := newTestGRPCStreams(t, cbs)
+	ts := newTestSend()
+	connID, stop := serveTestStream(t, gs, cbs, &core.WSStart{Namespace: "ns1", Name: "sub1"}, ts)
+
+	// Streams that started after the restart, or are in other namespaces, are not restarted
+	gs.NamespaceRestarted("ns2", time.Now())
+	gs.NamespaceRestarted("ns1", time.Now().Add(-1*time.Hour))
+	cbs.AssertNumberOfCalls(t, "RegisterConnection", 1)
+
+	cbs.On("RegisterConnection", connID, mock.Anything).Return(nil).Once()
+	gs.NamespaceRestarted("ns1", time.Now().Add(1*time.Hour))
+	cbs.AssertNumberOfCalls(t, "RegisterConnection", 2)
+
+	// A failed restart closes the stream
+	cbs.On("RegisterConnection", connID, mock.Anything).Return(fmt.Errorf("pop")).Once()
+	gs.NamespaceRestarted("ns1", time.Now().Add(2*time.Hour))
+	assert.NoError(t, stop())
+	cbs.AssertCalled(t, "ConnectionClosed", connID)
+}
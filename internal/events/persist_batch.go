@@ -102,6 +102,8 @@ func (em *eventManager) validateAndPersistBatchContent(ctx context.Context, batc
 			return false, err
 		}
 		data.Namespace = em.namespace.Name
+		// Values are only held in the blob store by reference locally, so a reference cannot be received
+		data.ValueRef = nil
 		dataByID[*data.ID] = data
 	}
 
@@ -267,12 +269,36 @@ func (em *eventManager) persistBatchContent(ctx context.Context, batch *core.Bat
 		log.L(ctx).Warnf("Batch %s was sent by our UUID, but the content was not already stored. Assuming node has been reset", batch.ID)
 	}
 
-	// Otherwise try a one-shot insert of all the data, on the basis it's likely unique
-	err = em.database.InsertDataArray(ctx, batch.Payload.Data)
+	// Large values are moved into the blob store before the data is inserted, in the same way as for
+	// data sent by this node. The values are deleted again if the batch content is not written.
+	data, err := em.data.OffloadLargeValues(ctx, batch.Payload.Data)
+	if err != nil {
+		return false, err
+	}
+	offloaded := make(map[fftypes.UUID]*core.Data, len(data))
+	for _, d := range data {
+		offloaded[*d.ID] = d
+	}
+	for _, mm := range matchedMsgs {
+		for i, d := range mm.data {
+			mm.data[i] = offloaded[*d.ID]
+		}
+	}
+	valid, err = em.insertBatchContent(ctx, batch, data, matchedMsgs)
+	if err != nil || !valid {
+		em.data.DeleteLargeValues(ctx, data)
+	}
+	return valid, err
+}
+
+func (em *eventManager) insertBatchContent(ctx context.Context, batch *core.Batch, data core.DataArray, matchedMsgs []*messageAndData) (valid bool, err error) {
+
+	// Try a one-shot insert of all the data, on the basis it's likely unique
+	err = em.database.InsertDataArray(ctx, data)
 	if err != nil {
 		log.L(ctx).Debugf("Batch data insert optimization failed for batch '%s': %s", batch.ID, err)
 		// Fall back to individual upserts
-		for i, data := range batch.Payload.Data {
+		for i, data := range data {
 			if err := em.database.UpsertData(ctx, data, database.UpsertOptimizationExisting); err != nil {
 				if err == database.HashMismatch {
 					log.L(ctx).Errorf("Invalid data entry %d in batch '%s'. Hash mismatch with existing record with same UUID '%s' Hash=%s", i, batch.ID, data.ID, data.Hash)
@@ -30,6 +30,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/auth/jwt"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/pkg/core"
 )
@@ -65,7 +66,16 @@ type websocketConnection struct {
 func newConnection(pCtx context.Context, ws *WebSockets, wsConn *websocket.Conn, req *http.Request, auth core.Authorizer) *websocketConnection {
 	connID := fftypes.NewUUID().String()
 	ctx := log.WithLogField(pCtx, "websocket", connID)
+	ctx = core.WithAuthRequestInfo(ctx, &core.AuthRequestInfo{TLS: req.TLS})
 	ctx, cancelCtx := context.WithCancel(ctx)
+	header := req.Header
+	if req.URL != nil && header.Get("Authorization") == "" {
+		// Browser clients cannot set headers on a websocket, so can pass a bearer token on the query
+		if token := req.URL.Query().Get(jwt.AccessTokenQueryParam); token != "" {
+			header = header.Clone()
+			header.Set("Authorization", "Bearer "+token)
+		}
+	}
 	wc := &websocketConnection{
 		ctx:          ctx,
 		ws:           ws,
@@ -77,7 +87,7 @@ func newConnection(pCtx context.Context, ws *WebSockets, wsConn *websocket.Conn,
 		receiverDone: make(chan struct{}),
 		remoteAddr:   req.RemoteAddr,
 		userAgent:    req.UserAgent(),
-		header:       req.Header,
+		header:       header,
 		auth:         auth,
 	}
 	go wc.sendLoop()
@@ -538,6 +538,27 @@ func TestStartReceiveDurableWithAuth(t *testing.T) {
 	cbs.AssertExpectations(t)
 }
 
+type testBearerAuthorizer struct {
+	authHeader chan string
+}
+
+func (t *testBearerAuthorizer) Authorize(ctx context.Context, authReq *fftypes.AuthReq) error {
+	t.authHeader <- authReq.Header.Get("Authorization")
+	return nil
+}
+
+func TestStartAccessTokenQueryParam(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	authorizer := &testBearerAuthorizer{authHeader: make(chan string, 1)}
+	_, wsc, cancel := newTestWebsockets(t, cbs, authorizer, "access_token=token1")
+	defer cancel()
+	cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	err := wsc.Send(context.Background(), []byte(`{"type":"start","namespace":"ns1","name":"sub1"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer token1", <-authorizer.authHeader)
+}
+
 func TestStartReceiveDurableUnauthorized(t *testing.T) {
 	cbs := &eventsmocks.Callbacks{}
 	_, wsc, cancel := newTestWebsockets(t, cbs, &testAuthorizer{})
@@ -0,0 +1,600 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"math/bits"
+	"reflect"
+	"strconv"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Env is the environment for the execution of a single query against a namespace
+type Env struct {
+	Orchestrator orchestrator.Orchestrator
+	// Authorize is called with the path, relative to the namespace, of the REST API route that returns
+	// the same resources as a field, before the field is resolved
+	Authorize    func(ctx context.Context, path string) error
+	DefaultLimit uint64
+	MaxLimit     uint64
+	MaxSkip      uint64
+	// MaxTokens is the most tokens a query document can contain, MaxDepth is the deepest that fields can be nested
+	// in a query, and MaxNodes is the most nodes that a query can resolve, as estimated before it is executed.
+	// Any of them can be zero for no limit.
+	MaxTokens uint64
+	MaxDepth  uint64
+	MaxNodes  uint64
+
+	authorized map[string]error
+}
+
+// authorize checks each route only once in a query, however many times a field is resolved from it
+func (env *Env) authorize(ctx context.Context, path string) error {
+	if env.authorized == nil {
+		env.authorized = make(map[string]error)
+	}
+	err, checked := env.authorized[path]
+	if !checked {
+		err = env.Authorize(ctx, path)
+		env.authorized[path] = err
+	}
+	return err
+}
+
+// resolver returns the value of a field of the source value, which is nil for the fields of the query type.
+// A list of values can be returned as any type of slice.
+type resolver func(ctx context.Context, env *Env, source interface{}, args map[string]interface{}) (interface{}, error)
+
+// objectType is a type with named fields that can be selected in a query
+type objectType struct {
+	name   string
+	fields map[string]*fieldDef
+}
+
+// fieldDef is a field of an object type. A field with a type resolves to one (or a list of) values of that type,
+// from which the selected subfields are resolved in turn. A field without a type resolves to a JSON value, from
+// which subfields can optionally be selected. A field without a resolver is the property of the same name in
+// the JSON form of the source value.
+type fieldDef struct {
+	typ     *objectType
+	list    bool
+	args    map[string]bool // the names of the arguments, mapped to whether they are required
+	resolve resolver
+}
+
+// jsonTypeName is the type name of a JSON value, returned for its __typename
+const jsonTypeName = "JSON"
+
+type executor struct {
+	ctx       context.Context
+	env       *Env
+	doc       *ast.QueryDocument
+	defined   map[string]bool
+	visiting  map[string]bool
+	variables map[string]interface{}
+	errors    []*core.GraphQLError
+	nodes     uint64
+}
+
+// Execute runs a query against the namespace of the orchestrator in the environment. An error is returned if
+// the query is not valid, or exceeds the limits of the environment, in which case none of it is executed. Errors in resolving individual fields are
+// instead returned in the response, with the value of the field set to null.
+func Execute(ctx context.Context, env *Env, req *core.GraphQLRequest) (*core.GraphQLResponse, error) {
+	doc, err := parseDocument(ctx, req.Query, env.MaxTokens)
+	if err != nil {
+		return nil, err
+	}
+	op, err := selectOperation(ctx, doc, req.OperationName)
+	if err != nil {
+		return nil, err
+	}
+	e := &executor{
+		ctx:      ctx,
+		env:      env,
+		doc:      doc,
+		defined:  make(map[string]bool),
+		visiting: make(map[string]bool),
+	}
+	for _, def := range op.VariableDefinitions {
+		e.defined[def.Variable] = true
+	}
+	if err := e.validateSelections(querySchema, op.SelectionSet); err != nil {
+		return nil, err
+	}
+	if err := e.coerceVariables(op, req.Variables); err != nil {
+		return nil, err
+	}
+	if err := e.checkLimits(querySchema, op.SelectionSet, 1, 1); err != nil {
+		return nil, err
+	}
+	data := e.executeSelections(querySchema, nil, op.SelectionSet, nil)
+	return &core.GraphQLResponse{
+		Data:   data,
+		Errors: e.errors,
+	}, nil
+}
+
+func selectOperation(ctx context.Context, doc *ast.QueryDocument, name string) (*ast.OperationDefinition, error) {
+	var op *ast.OperationDefinition
+	switch {
+	case name != "":
+		if op = doc.Operations.ForName(name); op == nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgGraphQLOperationNotFound, name)
+		}
+	case len(doc.Operations) == 0:
+		return nil, i18n.NewError(ctx, coremsgs.MsgGraphQLNoOperations)
+	case len(doc.Operations) > 1:
+		return nil, i18n.NewError(ctx, coremsgs.MsgGraphQLOperationNameRequired)
+	default:
+		op = doc.Operations[0]
+	}
+	if op.Operation != ast.Query {
+		return nil, i18n.NewError(ctx, coremsgs.MsgGraphQLQueryOnly, op.Operation)
+	}
+	return op, nil
+}
+
+// coerceVariables applies the defaults of any variables that were not supplied. Variables are not checked
+// against their declared types, as every argument accepts the types of value that can be passed to a filter.
+func (e *executor) coerceVariables(op *ast.OperationDefinition, supplied map[string]interface{}) error {
+	e.variables = make(map[string]interface{})
+	for _, def := range op.VariableDefinitions {
+		v, ok := supplied[def.Variable]
+		switch {
+		case v != nil:
+			e.variables[def.Variable] = v
+		case def.DefaultValue != nil:
+			e.variables[def.Variable] = e.valueOf(def.DefaultValue)
+		case def.Type.NonNull:
+			return i18n.NewError(e.ctx, coremsgs.MsgGraphQLMissingVariable, def.Variable, def.Type.String())
+		case ok:
+			e.variables[def.Variable] = nil
+		}
+	}
+	return nil
+}
+
+// validateSelections checks a selection set against an object type, or against a JSON value if the type is nil,
+// before anything is executed. Fragments that cannot apply to the type are not checked, as they are never executed.
+func (e *executor) validateSelections(typ *objectType, selections ast.SelectionSet) error {
+	for _, sel := range selections {
+		var err error
+		switch s := sel.(type) {
+		case *ast.Field:
+			if err = e.validateDirectives(s.Directives); err == nil {
+				err = e.validateField(typ, s)
+			}
+		case *ast.FragmentSpread:
+			if err = e.validateDirectives(s.Directives); err == nil {
+				err = e.validateFragmentSpread(typ, s)
+			}
+		case *ast.InlineFragment:
+			if err = e.validateDirectives(s.Directives); err == nil && typeApplies(typ, s.TypeCondition) {
+				err = e.validateSelections(typ, s.SelectionSet)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *executor) validateFragmentSpread(typ *objectType, s *ast.FragmentSpread) error {
+	frag := e.doc.Fragments.ForName(s.Name)
+	if frag == nil {
+		return i18n.NewError(e.ctx, coremsgs.MsgGraphQLUnknownFragment, s.Name)
+	}
+	if e.visiting[s.Name] {
+		return i18n.NewError(e.ctx, coremsgs.MsgGraphQLFragmentCycle, s.Name)
+	}
+	if !typeApplies(typ, frag.TypeCondition) {
+		return nil
+	}
+	e.visiting[s.Name] = true
+	defer delete(e.visiting, s.Name)
+	if err := e.validateDirectives(frag.Directives); err != nil {
+		return err
+	}
+	return e.validateSelections(typ, frag.SelectionSet)
+}
+
+func (e *executor) validateField(typ *objectType, f *ast.Field) error {
+	for _, arg := range f.Arguments {
+		if err := e.validateValue(arg.Value); err != nil {
+			return err
+		}
+	}
+	if f.Name == "__typename" || typ == nil {
+		// Neither the type name, nor the properties of JSON values, have arguments
+		if len(f.Arguments) > 0 {
+			return i18n.NewError(e.ctx, coremsgs.MsgGraphQLUnknownArgument, f.Arguments[0].Name, f.Name)
+		}
+		if f.Name == "__typename" && len(f.SelectionSet) > 0 {
+			return i18n.NewError(e.ctx, coremsgs.MsgGraphQLNoSubfields, f.Name)
+		}
+		return e.validateSelections(nil, f.SelectionSet)
+	}
+	def, ok := typ.fields[f.Name]
+	if !ok {
+		return i18n.NewError(e.ctx, coremsgs.MsgGraphQLUnknownField, f.Name, typ.name)
+	}
+	supplied := make(map[string]bool)
+	for _, arg := range f.Arguments {
+		if _, ok := def.args[arg.Name]; !ok {
+			return i18n.NewError(e.ctx, coremsgs.MsgGraphQLUnknownArgument, arg.Name, f.Name)
+		}
+		supplied[arg.Name] = true
+	}
+	for name, required := range def.args {
+		if required && !supplied[name] {
+			return i18n.NewError(e.ctx, coremsgs.MsgGraphQLMissingArgument, f.Name, name)
+		}
+	}
+	if def.typ != nil && len(f.SelectionSet) == 0 {
+		return i18n.NewError(e.ctx, coremsgs.MsgGraphQLSelectionRequired, f.Name, def.typ.name)
+	}
+	return e.validateSelections(def.typ, f.SelectionSet)
+}
+
+// validateDirectives checks for the only directives that apply to an executable document: @skip and @include
+func (e *executor) validateDirectives(directives ast.DirectiveList) error {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			return i18n.NewError(e.ctx, coremsgs.MsgGraphQLUnknownDirective, d.Name)
+		}
+		if len(d.Arguments) == 0 {
+			return i18n.NewError(e.ctx, coremsgs.MsgGraphQLMissingArgument, "@"+d.Name, "if")
+		}
+		for _, arg := range d.Arguments {
+			if arg.Name != "if" {
+				return i18n.NewError(e.ctx, coremsgs.MsgGraphQLUnknownArgument, arg.Name, "@"+d.Name)
+			}
+			if err := e.validateValue(arg.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *executor) validateValue(v *ast.Value) error {
+	if v.Kind == ast.Variable && !e.defined[v.Raw] {
+		return i18n.NewError(e.ctx, coremsgs.MsgGraphQLUndefinedVariable, v.Raw)
+	}
+	for _, child := range v.Children {
+		if err := e.validateValue(child.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkLimits rejects a query that nests fields deeper than the maximum depth, or that could resolve more than the
+// maximum number of nodes. Each field with a resolver counts as a node for each of the values it is resolved from,
+// and a list as many nodes as its limit, so the count is the most the query would resolve if every list were full.
+// As the schema is cyclic, this is what stops a query from fanning out through the related resources.
+func (e *executor) checkLimits(typ *objectType, selections ast.SelectionSet, depth, sources uint64) error {
+	fs := e.collectFields(typ, selections)
+	for _, key := range fs.keys {
+		if e.env.MaxDepth > 0 && depth > e.env.MaxDepth {
+			return i18n.NewError(e.ctx, coremsgs.MsgGraphQLMaxDepth, e.env.MaxDepth)
+		}
+		f := fs.fields[key][0]
+		var def *fieldDef
+		if typ != nil && f.Name != "__typename" {
+			def = typ.fields[f.Name]
+		}
+		count := sources
+		if def != nil && def.resolve != nil {
+			if def.list {
+				count = saturatingMul(sources, e.listSize(e.arguments(f)))
+			}
+			e.nodes = saturatingAdd(e.nodes, count)
+			if e.env.MaxNodes > 0 && e.nodes > e.env.MaxNodes {
+				return i18n.NewError(e.ctx, coremsgs.MsgGraphQLMaxNodes, e.env.MaxNodes)
+			}
+		}
+		var subType *objectType
+		if def != nil {
+			subType = def.typ
+		}
+		if err := e.checkLimits(subType, subSelections(fs.fields[key]), depth+1, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listSize is the most entries a list field can return, which is its limit. Lists that cannot be paged are
+// assumed to be no longer than a default page.
+func (e *executor) listSize(args map[string]interface{}) uint64 {
+	if limit := argStrings(args["limit"]); len(limit) > 0 {
+		if l, err := strconv.ParseUint(limit[0], 10, 64); err == nil && l > 0 {
+			return l
+		}
+	}
+	return e.env.DefaultLimit
+}
+
+func saturatingMul(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	if hi != 0 {
+		return math.MaxUint64
+	}
+	return lo
+}
+
+func saturatingAdd(a, b uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return math.MaxUint64
+	}
+	return sum
+}
+
+func typeApplies(typ *objectType, typeCondition string) bool {
+	return typeCondition == "" || (typ != nil && typ.name == typeCondition)
+}
+
+// valueOf returns the Go value of an argument, in the same form as a JSON variable
+func (e *executor) valueOf(v *ast.Value) interface{} {
+	switch v.Kind {
+	case ast.Variable:
+		return e.variables[v.Raw]
+	case ast.IntValue, ast.FloatValue:
+		return json.Number(v.Raw)
+	case ast.BooleanValue:
+		return v.Raw == "true"
+	case ast.NullValue:
+		return nil
+	case ast.ListValue:
+		list := make([]interface{}, len(v.Children))
+		for i, entry := range v.Children {
+			list[i] = e.valueOf(entry.Value)
+		}
+		return list
+	case ast.ObjectValue:
+		obj := make(map[string]interface{}, len(v.Children))
+		for _, f := range v.Children {
+			obj[f.Name] = e.valueOf(f.Value)
+		}
+		return obj
+	default: // strings, block strings and enums
+		return v.Raw
+	}
+}
+
+func (e *executor) arguments(f *ast.Field) map[string]interface{} {
+	args := make(map[string]interface{}, len(f.Arguments))
+	for _, arg := range f.Arguments {
+		args[arg.Name] = e.valueOf(arg.Value)
+	}
+	return args
+}
+
+// included applies the @skip and @include directives
+func (e *executor) included(directives ast.DirectiveList) bool {
+	for _, d := range directives {
+		condition, _ := e.valueOf(d.Arguments[len(d.Arguments)-1].Value).(bool)
+		if condition == (d.Name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldSet is the fields selected from an object, grouped by response key in the order they were first selected
+type fieldSet struct {
+	keys   []string
+	fields map[string][]*ast.Field
+}
+
+func (e *executor) collectFields(typ *objectType, selections ast.SelectionSet) *fieldSet {
+	fs := &fieldSet{fields: make(map[string][]*ast.Field)}
+	e.collectFieldsInto(fs, typ, selections, make(map[string]bool))
+	return fs
+}
+
+func (e *executor) collectFieldsInto(fs *fieldSet, typ *objectType, selections ast.SelectionSet, visited map[string]bool) {
+	for _, sel := range selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if e.included(s.Directives) {
+				// The parser sets the alias to the name of the field when there is no alias
+				key := s.Alias
+				if _, ok := fs.fields[key]; !ok {
+					fs.keys = append(fs.keys, key)
+				}
+				fs.fields[key] = append(fs.fields[key], s)
+			}
+		case *ast.FragmentSpread:
+			frag := e.doc.Fragments.ForName(s.Name)
+			if !visited[s.Name] && e.included(s.Directives) && typeApplies(typ, frag.TypeCondition) {
+				visited[s.Name] = true
+				e.collectFieldsInto(fs, typ, frag.SelectionSet, visited)
+			}
+		case *ast.InlineFragment:
+			if e.included(s.Directives) && typeApplies(typ, s.TypeCondition) {
+				e.collectFieldsInto(fs, typ, s.SelectionSet, visited)
+			}
+		}
+	}
+}
+
+// subSelections merges the selections of all the fields with the same response key
+func subSelections(fields []*ast.Field) ast.SelectionSet {
+	var selections ast.SelectionSet
+	for _, f := range fields {
+		selections = append(selections, f.SelectionSet...)
+	}
+	return selections
+}
+
+func appendPath(path []interface{}, elem interface{}) []interface{} {
+	return append(append(make([]interface{}, 0, len(path)+1), path...), elem)
+}
+
+func (e *executor) fieldError(err error, f *ast.Field, path []interface{}) {
+	gqlErr := &core.GraphQLError{
+		Message: err.Error(),
+		Path:    path,
+	}
+	if f.Position != nil {
+		gqlErr.Locations = []*core.GraphQLLocation{{Line: f.Position.Line, Column: f.Position.Column}}
+	}
+	e.errors = append(e.errors, gqlErr)
+}
+
+func (e *executor) executeSelections(typ *objectType, source interface{}, selections ast.SelectionSet, path []interface{}) *orderedObject {
+	fs := e.collectFields(typ, selections)
+	result := newOrderedObject()
+	var sourceJSON map[string]interface{}
+	for _, key := range fs.keys {
+		f := fs.fields[key][0]
+		fieldPath := appendPath(path, key)
+		if f.Name == "__typename" {
+			result.set(key, typ.name)
+			continue
+		}
+		def := typ.fields[f.Name]
+		var v interface{}
+		var err error
+		if def.resolve != nil {
+			v, err = def.resolve(e.ctx, e.env, source, e.arguments(f))
+		} else {
+			if sourceJSON == nil {
+				sourceJSON, _ = toJSON(source).(map[string]interface{})
+			}
+			v = sourceJSON[f.Name]
+		}
+		if err != nil {
+			e.fieldError(err, f, fieldPath)
+			result.set(key, nil)
+			continue
+		}
+		result.set(key, e.completeValue(def.typ, v, subSelections(fs.fields[key]), f, fieldPath))
+	}
+	return result
+}
+
+// completeValue resolves the selected subfields of the value of a field, for each entry if it is a list
+func (e *executor) completeValue(typ *objectType, v interface{}, selections ast.SelectionSet, f *ast.Field, path []interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	switch {
+	case v == nil:
+		return nil
+	case (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Map) && rv.IsNil():
+		return nil
+	case typ == nil && len(selections) == 0:
+		return v
+	case typ == nil:
+		return e.projectJSON(toJSON(v), selections, f, path)
+	case rv.Kind() == reflect.Slice:
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = e.completeValue(typ, rv.Index(i).Interface(), selections, f, appendPath(path, i))
+		}
+		return list
+	default:
+		return e.executeSelections(typ, v, selections, path)
+	}
+}
+
+// projectJSON returns the selected properties of a JSON object, or of each object in a JSON array
+func (e *executor) projectJSON(v interface{}, selections ast.SelectionSet, f *ast.Field, path []interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, entry := range v {
+			list[i] = e.projectJSON(entry, selections, f, appendPath(path, i))
+		}
+		return list
+	case map[string]interface{}:
+		fs := e.collectFields(nil, selections)
+		result := newOrderedObject()
+		for _, key := range fs.keys {
+			sf := fs.fields[key][0]
+			switch sub := subSelections(fs.fields[key]); {
+			case sf.Name == "__typename":
+				result.set(key, jsonTypeName)
+			case len(sub) == 0:
+				result.set(key, v[sf.Name])
+			default:
+				result.set(key, e.projectJSON(v[sf.Name], sub, sf, appendPath(path, key)))
+			}
+		}
+		return result
+	default:
+		e.fieldError(i18n.NewError(e.ctx, coremsgs.MsgGraphQLNoSubfields, f.Name), f, path)
+		return nil
+	}
+}
+
+// toJSON returns the generic form of a value, as it is serialized in the REST API
+func toJSON(v interface{}) (generic interface{}) {
+	b, _ := json.Marshal(v)
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	_ = d.Decode(&generic)
+	return generic
+}
+
+// orderedObject is a JSON object that is serialized with its keys in the order they were selected
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedObject() *orderedObject {
+	return &orderedObject{values: make(map[string]interface{})}
+}
+
+func (o *orderedObject) set(key string, v interface{}) {
+	o.keys = append(o.keys, key)
+	o.values[key] = v
+}
+
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString("{")
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		v, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
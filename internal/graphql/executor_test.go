@@ -0,0 +1,351 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/orchestratormocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func newTestEnv(t *testing.T) (*Env, *orchestratormocks.Orchestrator, *[]string) {
+	or := orchestratormocks.NewOrchestrator(t)
+	authorized := []string{}
+	return &Env{
+		Orchestrator: or,
+		Authorize: func(ctx context.Context, path string) error {
+			authorized = append(authorized, path)
+			return nil
+		},
+		DefaultLimit: 25,
+	}, or, &authorized
+}
+
+func executeJSON(t *testing.T, env *Env, req *core.GraphQLRequest) string {
+	res, err := Execute(context.Background(), env, req)
+	assert.NoError(t, err)
+	b, err := json.Marshal(res)
+	assert.NoError(t, err)
+	return string(b)
+}
+
+func TestExecuteSelections(t *testing.T) {
+	env, or, authorized := newTestEnv(t)
+	msgID := fftypes.MustParseUUID("4a0fea32-3c07-4a5e-9c4a-63a4d8e96fb4")
+	batchID := fftypes.MustParseUUID("e9a5cbc4-fe1d-4c9b-96ad-a8f4ccb3d3e6")
+	or.On("GetMessageByID", mock.Anything, msgID.String()).Return(&core.Message{
+		Header: core.MessageHeader{
+			ID:        msgID,
+			SignerRef: core.SignerRef{Author: "org1"},
+			Topics:    fftypes.FFStringArray{"topic1"},
+			Tag:       "tag1",
+		},
+		BatchID: batchID,
+	}, nil)
+	or.On("GetBatchByID", mock.Anything, batchID.String()).Return(&core.BatchPersisted{
+		BatchHeader: core.BatchHeader{ID: batchID},
+	}, nil)
+
+	res := executeJSON(t, env, &core.GraphQLRequest{
+		Query: `
+			query Msg($id: String!, $skipHash: Boolean = true) {
+				__typename
+				msg: message(id: $id) {
+					__typename
+					...ids
+					...ids
+					hash @skip(if: $skipHash)
+					header { author topics __typename }
+					header { tag }
+					batch { id __typename }
+					transaction { id }
+					... on Batch { hash }
+					... @include(if: false) { hash }
+				}
+			}
+			fragment ids on Message { header { id cid { id } } }
+		`,
+		OperationName: "Msg",
+		Variables:     fftypes.JSONObject{"id": msgID.String()},
+	})
+	assert.JSONEq(t, fmt.Sprintf(`{"data": {
+		"__typename": "Query",
+		"msg": {
+			"__typename": "Message",
+			"header": {"id": "%s", "cid": null, "author": "org1", "topics": ["topic1"], "__typename": "JSON", "tag": "tag1"},
+			"batch": {"id": "%s", "__typename": "Batch"},
+			"transaction": null
+		}
+	}}`, msgID, batchID), res)
+	assert.Regexp(t, `^{"data":{"__typename":"Query","msg":{"__typename":"Message","header":{"id"`, res)
+	assert.Equal(t, []string{"messages/" + msgID.String(), "batches/" + batchID.String()}, *authorized)
+}
+
+func TestExecuteJSONProjection(t *testing.T) {
+	env, or, _ := newTestEnv(t)
+	txID := fftypes.NewUUID()
+	or.On("GetTransactionByID", mock.Anything, "tx1").Return(&core.Transaction{ID: txID}, nil)
+	or.On("GetTransactionStatus", mock.Anything, txID.String()).Return(&core.TransactionStatus{
+		Status: core.OpStatusSucceeded,
+		Details: []*core.TransactionStatusDetails{
+			{Type: core.TransactionStatusTypeBatch, Status: core.OpStatusSucceeded},
+		},
+	}, nil)
+
+	res := executeJSON(t, env, &core.GraphQLRequest{
+		Query: `{ transaction(id: "tx1") {
+			status { status details { type } }
+			s2: status { status { nope } }
+		} }`,
+	})
+	assert.JSONEq(t, `{
+		"data": {"transaction": {
+			"status": {"status": "Succeeded", "details": [{"type": "Batch"}]},
+			"s2": {"status": null}
+		}},
+		"errors": [{
+			"message": "FF10546: Field 'status' is a scalar value, and cannot have a selection of subfields",
+			"locations": [{"line": 3, "column": 17}],
+			"path": ["transaction", "s2", "status"]
+		}]
+	}`, res)
+}
+
+func TestExecuteFieldErrors(t *testing.T) {
+	env, or, authorized := newTestEnv(t)
+	env.Authorize = func(ctx context.Context, path string) error {
+		*authorized = append(*authorized, path)
+		return fmt.Errorf("pop")
+	}
+	msgID := fftypes.NewUUID()
+	or.On("GetMessages", mock.Anything, mock.Anything).Return([]*core.Message{{Header: core.MessageHeader{ID: msgID}}}, nil, nil)
+
+	res := executeJSON(t, env, &core.GraphQLRequest{
+		Query: `{
+			a: status
+			b: status
+			messages { data { id } }
+			message(id: "m1") { hash }
+		}`,
+	})
+	assert.JSONEq(t, `{
+		"data": {"a": null, "b": null, "messages": null, "message": null},
+		"errors": [
+			{"message": "pop", "locations": [{"line": 2, "column": 4}], "path": ["a"]},
+			{"message": "pop", "locations": [{"line": 3, "column": 4}], "path": ["b"]},
+			{"message": "pop", "locations": [{"line": 4, "column": 4}], "path": ["messages"]},
+			{"message": "pop", "locations": [{"line": 5, "column": 4}], "path": ["message"]}
+		]
+	}`, res)
+	assert.Equal(t, []string{"status", "messages", "messages/m1"}, *authorized)
+
+	env.authorized = nil
+	env.Authorize = func(ctx context.Context, path string) error { return nil }
+	or.On("GetMessageData", mock.Anything, msgID.String()).Return(nil, fmt.Errorf("pop"))
+	res = executeJSON(t, env, &core.GraphQLRequest{
+		Query: `{ messages { data { id } } }`,
+	})
+	assert.JSONEq(t, `{
+		"data": {"messages": [{"data": null}]},
+		"errors": [{"message": "pop", "locations": [{"line": 1, "column": 14}], "path": ["messages", 0, "data"]}]
+	}`, res)
+}
+
+func TestExecuteEmptyList(t *testing.T) {
+	env, or, _ := newTestEnv(t)
+	or.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, nil)
+	res := executeJSON(t, env, &core.GraphQLRequest{
+		Query:     `query Q($skip: Boolean) { messages @skip(if: $skip) { hash } }`,
+		Variables: fftypes.JSONObject{"skip": nil},
+	})
+	assert.JSONEq(t, `{"data": {"messages": []}}`, res)
+}
+
+func TestExecuteFragmentNotApplicable(t *testing.T) {
+	env, _, _ := newTestEnv(t)
+	res := executeJSON(t, env, &core.GraphQLRequest{
+		Query: `{ ...f ... on Query { __typename } } fragment f on Message { nope }`,
+	})
+	assert.JSONEq(t, `{"data": {"__typename": "Query"}}`, res)
+}
+
+func TestExecuteInvalid(t *testing.T) {
+	for query, code := range map[string]string{
+		`{`:                                                     "FF10538",
+		`query A { status } query B { status }`:                 "FF10540",
+		`fragment f on Query { status }`:                        "FF10552",
+		`mutation { status }`:                                   "FF10541",
+		`{ nope }`:                                              "FF10542",
+		`{ messages { nope } }`:                                 "FF10542",
+		`{ ... on Query { nope } }`:                             "FF10542",
+		`{ message(id: "1", x: 1) { hash } }`:                   "FF10543",
+		`{ __typename(a: 1) }`:                                  "FF10543",
+		`{ status(a: 1) }`:                                      "FF10543",
+		`{ status { a(b: 1) } }`:                                "FF10543",
+		`{ status @skip(unless: true) }`:                        "FF10543",
+		`{ message { hash } }`:                                  "FF10544",
+		`{ status @skip }`:                                      "FF10544",
+		`{ message(id: "1") }`:                                  "FF10545",
+		`{ __typename { a } }`:                                  "FF10546",
+		`{ ...f }`:                                              "FF10547",
+		`{ ...f } fragment f on Query { ...f }`:                 "FF10548",
+		`{ message(id: $x) { hash } }`:                          "FF10549",
+		`{ message(id: [{a: $x}]) { hash } }`:                   "FF10549",
+		`{ status @skip(if: $x) }`:                              "FF10549",
+		`{ ... @skip(if: $x) { status } }`:                      "FF10549",
+		`{ ...f @skip(if: $x) } fragment f on Query { status }`: "FF10549",
+		`{ ...f } fragment f on Query @skip(if: $x) { status }`: "FF10549",
+		`{ status @foo }`:                                       "FF10550",
+		`query Q($id: String!) { message(id: $id) { hash } }`:   "FF10551",
+	} {
+		env, _, _ := newTestEnv(t)
+		_, err := Execute(context.Background(), env, &core.GraphQLRequest{Query: query})
+		assert.Regexp(t, code, err, query)
+	}
+}
+
+func TestExecuteOperationNotFound(t *testing.T) {
+	env, _, _ := newTestEnv(t)
+	_, err := Execute(context.Background(), env, &core.GraphQLRequest{
+		Query:         `query A { status }`,
+		OperationName: "B",
+	})
+	assert.Regexp(t, "FF10539.*B", err)
+}
+
+func TestExecuteRequiredVariableNull(t *testing.T) {
+	env, _, _ := newTestEnv(t)
+	_, err := Execute(context.Background(), env, &core.GraphQLRequest{
+		Query:     `query Q($id: String!) { message(id: $id) { hash } }`,
+		Variables: fftypes.JSONObject{"id": nil},
+	})
+	assert.Regexp(t, "FF10551", err)
+}
+
+func TestValueOf(t *testing.T) {
+	doc, err := parseDocument(context.Background(), `{ a(b: [1, 2.5, "s", ENUM, true, null, {c: $d}]) }`, 0)
+	assert.NoError(t, err)
+	e := &executor{variables: map[string]interface{}{"d": "e"}}
+	assert.Equal(t, []interface{}{
+		json.Number("1"), json.Number("2.5"), "s", "ENUM", true, nil, map[string]interface{}{"c": "e"},
+	}, e.valueOf(doc.Operations[0].SelectionSet[0].(*ast.Field).Arguments[0].Value))
+}
+
+func TestCompleteValueNilMap(t *testing.T) {
+	e := &executor{}
+	assert.Nil(t, e.completeValue(nil, fftypes.JSONObject(nil), nil, &ast.Field{}, nil))
+}
+
+func TestOrderedObjectMarshalFail(t *testing.T) {
+	o := newOrderedObject()
+	o.set("a", map[bool]bool{true: true})
+	_, err := json.Marshal(o)
+	assert.Error(t, err)
+}
+
+func TestExecuteMaxDepth(t *testing.T) {
+	env, or, _ := newTestEnv(t)
+	env.MaxDepth = 3
+
+	_, err := Execute(context.Background(), env, &core.GraphQLRequest{
+		Query: `{ messages { batch { transaction { messages { header { id } } } } } }`,
+	})
+	assert.Regexp(t, "FF10565.*3", err)
+
+	// Skipped fields do not count
+	or.On("GetMessages", mock.Anything, mock.Anything).Return([]*core.Message{}, nil, nil)
+	_, err = Execute(context.Background(), env, &core.GraphQLRequest{
+		Query: `{ messages { hash batch @skip(if: true) { transaction { id } } } }`,
+	})
+	assert.NoError(t, err)
+}
+
+func TestExecuteMaxNodes(t *testing.T) {
+	env, or, _ := newTestEnv(t)
+	env.MaxNodes = 100
+
+	// 10 messages, each with up to 25 (the default limit) data items
+	_, err := Execute(context.Background(), env, &core.GraphQLRequest{
+		Query:     `query Q($limit: Int) { messages(limit: $limit) { header { id } batch { id } data { id } } }`,
+		Variables: fftypes.JSONObject{"limit": 10},
+	})
+	assert.Regexp(t, "FF10566.*100", err)
+
+	// 3 messages, each with one batch and 25 data items
+	or.On("GetMessages", mock.Anything, mock.Anything).Return([]*core.Message{}, nil, nil)
+	_, err = Execute(context.Background(), env, &core.GraphQLRequest{
+		Query: `{ messages(limit: 3) { header { id } batch { id } data { id } } }`,
+	})
+	assert.NoError(t, err)
+}
+
+func TestCheckLimitsSaturates(t *testing.T) {
+	env, _, _ := newTestEnv(t)
+	env.MaxNodes = 1000
+	doc, err := parseDocument(context.Background(), `{ messages(limit: 18446744073709551615) {
+		data { messages(limit: 18446744073709551615) { batch { id } } }
+	} }`, 0)
+	assert.NoError(t, err)
+	e := &executor{ctx: context.Background(), env: env, doc: doc}
+	err = e.checkLimits(querySchema, doc.Operations[0].SelectionSet, 1, 1)
+	assert.Regexp(t, "FF10566", err)
+
+	env.MaxNodes = 0
+	e.nodes = 0
+	err = e.checkLimits(querySchema, doc.Operations[0].SelectionSet, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), e.nodes)
+}
+
+func FuzzExecute(f *testing.F) {
+	for _, seed := range []string{
+		`{ status { node { name } } }`,
+		`query Q($limit: Int = 3) { messages(limit: $limit) { header { id topics } batch { id } ...f } } fragment f on Message { hash data { id } }`,
+		`{ message(id: "x") { __typename header @skip(if: true) { id } ... on Message { hash } } }`,
+		`{ messages { batch { transaction { messages { header { id } } } } } }`,
+		`fragment f on Message { ...f } { messages { ...f } }`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		// Every resolver is denied, so the orchestrator is never called, but the query is still parsed,
+		// validated, checked against the limits, and executed
+		env := &Env{
+			Authorize: func(ctx context.Context, path string) error {
+				return fmt.Errorf("pop")
+			},
+			DefaultLimit: 25,
+			MaxTokens:    1000,
+			MaxDepth:     10,
+			MaxNodes:     10000,
+		}
+		res, err := Execute(context.Background(), env, &core.GraphQLRequest{Query: query})
+		if err == nil {
+			_, err = json.Marshal(res)
+			assert.NoError(t, err)
+		}
+	})
+}
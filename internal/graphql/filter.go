@@ -0,0 +1,128 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+)
+
+// The arguments of every list field that control paging and sorting, as in the REST API
+var pagingArgs = []string{"skip", "limit", "sort", "descending", "ascending"}
+
+// argName returns the argument for a field of a query factory, as '.' is not valid in a GraphQL name
+func argName(fieldName string) string {
+	return strings.ReplaceAll(fieldName, ".", "_")
+}
+
+// filterArgs returns the arguments of a list field, which are the fields of its query factory and the paging
+// and sorting arguments. Each filter argument takes a value, or a list of values, in the same form as the query
+// parameters of the REST API - including the operator prefixes such as ">=" and "!".
+func filterArgs(qf ffapi.QueryFactory) map[string]bool {
+	args := make(map[string]bool)
+	for _, name := range pagingArgs {
+		args[name] = false
+	}
+	for _, name := range qf.NewFilter(context.Background()).Fields() {
+		args[argName(name)] = false
+	}
+	return args
+}
+
+// buildFilter builds the filter for a list field from its arguments, in the same way as for the REST API
+func (env *Env) buildFilter(ctx context.Context, qf ffapi.QueryFactory, args map[string]interface{}) (ffapi.AndFilter, error) {
+	fb := qf.NewFilterLimit(ctx, env.DefaultLimit)
+	filter := fb.And()
+	fields := fb.Fields()
+	sort.Strings(fields)
+	for _, name := range fields {
+		f, err := ffapi.ParseFilterParam(ctx, fb, name, argStrings(args[argName(name)]))
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			filter.Condition(f)
+		}
+	}
+	if skip := argStrings(args["skip"]); len(skip) > 0 {
+		s, _ := strconv.ParseUint(skip[0], 10, 64)
+		if env.MaxSkip != 0 && s > env.MaxSkip {
+			return nil, i18n.NewError(ctx, i18n.MsgMaxFilterSkip, env.MaxSkip)
+		}
+		filter.Skip(s)
+	}
+	if limit := argStrings(args["limit"]); len(limit) > 0 {
+		l, _ := strconv.ParseUint(limit[0], 10, 64)
+		if env.MaxLimit != 0 && l > env.MaxLimit {
+			return nil, i18n.NewError(ctx, i18n.MsgMaxFilterLimit, env.MaxLimit)
+		}
+		filter.Limit(l)
+	}
+	for _, sv := range argStrings(args["sort"]) {
+		for _, ssv := range strings.Split(sv, ",") {
+			if ssv = strings.TrimSpace(ssv); ssv != "" {
+				filter.Sort(ssv)
+			}
+		}
+	}
+	if argTrue(args["descending"]) {
+		filter.Descending()
+	} else if argTrue(args["ascending"]) {
+		filter.Ascending()
+	}
+	return filter, nil
+}
+
+// argStrings returns the values of an argument as strings, treating null as no value
+func argStrings(v interface{}) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, entry := range v {
+			values = append(values, argStrings(entry)...)
+		}
+		return values
+	default:
+		return []string{argString(v)}
+	}
+}
+
+func argString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		// Numbers in JSON variables are decoded as floats, but are written as integers where they are whole
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func argTrue(v interface{}) bool {
+	return v != nil && strings.EqualFold(argString(v), "true")
+}
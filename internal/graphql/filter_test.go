@@ -0,0 +1,96 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterArgs(t *testing.T) {
+	args := filterArgs(database.TokenTransferQueryFactory)
+	assert.Contains(t, args, "tx_id")
+	assert.Contains(t, args, "pool")
+	assert.Contains(t, args, "limit")
+	assert.NotContains(t, args, "tx.id")
+	for _, required := range args {
+		assert.False(t, required)
+	}
+}
+
+func TestBuildFilter(t *testing.T) {
+	env := &Env{DefaultLimit: 25}
+	filter, err := env.buildFilter(context.Background(), database.TokenTransferQueryFactory, map[string]interface{}{
+		"tx_id":      "4a0fea32-3c07-4a5e-9c4a-63a4d8e96fb4",
+		"amount":     []interface{}{">=10", float64(20)},
+		"key":        nil,
+		"skip":       json.Number("5"),
+		"sort":       []interface{}{"created, amount", ""},
+		"descending": true,
+	})
+	assert.NoError(t, err)
+	fi, err := filter.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "( ( amount == 20 ) || ( amount >= 10 ) ) && ( tx.id == '4a0fea32-3c07-4a5e-9c4a-63a4d8e96fb4' ) sort=-created,-amount skip=5 limit=25", fi.String())
+
+	filter, err = env.buildFilter(context.Background(), database.TokenTransferQueryFactory, map[string]interface{}{
+		"limit":      float64(10),
+		"descending": false,
+		"ascending":  "TRUE",
+	})
+	assert.NoError(t, err)
+	fi, err = filter.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, " limit=10", fi.String())
+}
+
+func TestBuildFilterMaxSkip(t *testing.T) {
+	env := &Env{MaxSkip: 10}
+	_, err := env.buildFilter(context.Background(), database.MessageQueryFactory, map[string]interface{}{
+		"skip": json.Number("11"),
+	})
+	assert.Regexp(t, "FF00191", err)
+}
+
+func TestBuildFilterMaxLimit(t *testing.T) {
+	env := &Env{MaxLimit: 10}
+	_, err := env.buildFilter(context.Background(), database.MessageQueryFactory, map[string]interface{}{
+		"limit": json.Number("11"),
+	})
+	assert.Regexp(t, "FF00192", err)
+}
+
+func TestBuildFilterBadCondition(t *testing.T) {
+	env := &Env{}
+	_, err := env.buildFilter(context.Background(), database.MessageQueryFactory, map[string]interface{}{
+		"created": "!>0",
+	})
+	assert.Regexp(t, "FF00193", err)
+}
+
+func TestArgString(t *testing.T) {
+	assert.Equal(t, "", argString(nil))
+	assert.Equal(t, "1000000", argString(float64(1e6)))
+	assert.Equal(t, "1.5", argString(float64(1.5)))
+	assert.Equal(t, "true", argString(true))
+	assert.True(t, argTrue("True"))
+	assert.False(t, argTrue(nil))
+}
@@ -0,0 +1,50 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// parseDocument parses an executable GraphQL document with the parser from gqlparser, which is the parser
+// used by gqlgen. The document is not validated by gqlparser, as the schema is defined in code, and the
+// properties of JSON values can be selected without being part of it. The executor validates it instead.
+// A document with more than maxTokens tokens is rejected part way through parsing, unless it is zero.
+func parseDocument(ctx context.Context, src string, maxTokens uint64) (*ast.QueryDocument, error) {
+	doc, err := parser.ParseQueryWithTokenLimit(&ast.Source{Input: src}, int(min(maxTokens, math.MaxInt32)))
+	if err != nil {
+		var gqlErr *gqlerror.Error
+		if !errors.As(err, &gqlErr) {
+			// The parser only returns a plain error when the token limit is exceeded
+			return nil, i18n.NewError(ctx, coremsgs.MsgGraphQLMaxTokens, maxTokens)
+		}
+		line, column := 0, 0
+		if len(gqlErr.Locations) > 0 {
+			line, column = gqlErr.Locations[0].Line, gqlErr.Locations[0].Column
+		}
+		return nil, i18n.NewError(ctx, coremsgs.MsgGraphQLSyntax, line, column, gqlErr.Message)
+	}
+	return doc, nil
+}
@@ -0,0 +1,123 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestParseDocumentFull(t *testing.T) {
+	doc, err := parseDocument(context.Background(), `
+		# A comment
+		query Messages($limit: Int = 10, $topics: [String!]!) {
+			messages(limit: $limit, topics: $topics) {
+				id: header { id }, ...msgFields
+				hash
+			}
+		}
+		fragment msgFields on Message { batch { id } }
+		{ status }
+	`, 100)
+	assert.NoError(t, err)
+	assert.Len(t, doc.Operations, 2)
+	assert.NotNil(t, doc.Fragments.ForName("msgFields"))
+
+	op := doc.Operations[0]
+	assert.Equal(t, ast.Query, op.Operation)
+	assert.True(t, op.VariableDefinitions[1].Type.NonNull)
+	assert.Equal(t, "[String!]!", op.VariableDefinitions[1].Type.String())
+
+	// The executor relies on the alias being the response key of every field, and the position being set
+	messages := op.SelectionSet[0].(*ast.Field)
+	assert.Equal(t, "messages", messages.Alias)
+	assert.Equal(t, 4, messages.Position.Line)
+	assert.Equal(t, 4, messages.Position.Column)
+	aliased := messages.SelectionSet[0].(*ast.Field)
+	assert.Equal(t, "id", aliased.Alias)
+	assert.Equal(t, "header", aliased.Name)
+
+	// The shorthand form is a query
+	assert.Equal(t, ast.Query, doc.Operations[1].Operation)
+}
+
+func TestParseDocumentBlockString(t *testing.T) {
+	doc, err := parseDocument(context.Background(), `{
+		a(s: """
+			first
+			  second \""" quoted
+
+		""")
+	}`, 0)
+	assert.NoError(t, err)
+	arg := doc.Operations[0].SelectionSet[0].(*ast.Field).Arguments[0].Value
+	assert.Equal(t, "first\n  second \"\"\" quoted", arg.Raw)
+}
+
+func TestParseDocumentEmpty(t *testing.T) {
+	doc, err := parseDocument(context.Background(), "  # nothing\n", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, doc.Operations)
+}
+
+func TestParseDocumentErrors(t *testing.T) {
+	for src, msg := range map[string]string{
+		`}`:                       "FF10538.*line 1 column 1",
+		`{`:                       "FF10538.*line 1 column 2",
+		`query q($a Int) { a }`:   "FF10538",
+		`{ a(b: "x`:               "FF10538",
+		"{ a(b: 1) }\n  ?":        "FF10538.*line 2 column 3",
+		`{ a(b: 1) } { b } { c }`: "",
+	} {
+		_, err := parseDocument(context.Background(), src, 0)
+		if msg == "" {
+			assert.NoError(t, err, src)
+			continue
+		}
+		assert.Regexp(t, msg, err, src)
+	}
+}
+
+func TestParseDocumentMaxTokens(t *testing.T) {
+	_, err := parseDocument(context.Background(), `{ a { b } }`, 6)
+	assert.NoError(t, err)
+
+	_, err = parseDocument(context.Background(), `{ a { b } }`, 5)
+	assert.Regexp(t, "FF10567.*5", err)
+}
+
+func FuzzParseDocument(f *testing.F) {
+	for _, seed := range []string{
+		`{ status }`,
+		`query Q($limit: Int = 10) { messages(limit: $limit) { id: header { id } ...f } } fragment f on Message { hash }`,
+		`{ a(b: [1, -2.5e3, "xé", true, null, ENUM, {c: $d}]) @skip(if: false) { ... on T { e } ... { f } } }`,
+		`{ a(s: """ block """) }`,
+		`mutation { a }`,
+		`{`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		doc, err := parseDocument(context.Background(), src, 1000)
+		if err == nil {
+			assert.NotNil(t, doc)
+		}
+	})
+}
@@ -0,0 +1,376 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// querySchema is the root of the schema. Each object type has the JSON properties of its REST API resource,
+// along with fields that resolve the related resources. Where a property is the ID of a single related resource
+// (such as the batch of a message) the field of the same name resolves the resource itself.
+var querySchema = newSchema()
+
+func newSchema() *objectType {
+	messageType := modelObject("Message", core.Message{})
+	dataType := modelObject("Data", core.Data{})
+	batchType := modelObject("Batch", core.BatchPersisted{})
+	transactionType := modelObject("Transaction", core.Transaction{})
+	operationType := modelObject("Operation", core.Operation{})
+	eventType := modelObject("Event", core.Event{})
+	blockchainEventType := modelObject("BlockchainEvent", core.BlockchainEvent{})
+	tokenPoolType := modelObject("TokenPool", core.TokenPool{})
+	tokenTransferType := modelObject("TokenTransfer", core.TokenTransfer{})
+	tokenApprovalType := modelObject("TokenApproval", core.TokenApproval{})
+	tokenBalanceType := modelObject("TokenBalance", core.TokenBalance{})
+	identityType := modelObject("Identity", core.Identity{})
+	verifierType := modelObject("Verifier", core.Verifier{})
+
+	getMessage := func(ctx context.Context, or orchestrator.Orchestrator, id string) (interface{}, error) {
+		return or.GetMessageByID(ctx, id)
+	}
+	getData := func(ctx context.Context, or orchestrator.Orchestrator, id string) (interface{}, error) {
+		return or.GetDataByID(ctx, id)
+	}
+	getBatch := func(ctx context.Context, or orchestrator.Orchestrator, id string) (interface{}, error) {
+		return or.GetBatchByID(ctx, id)
+	}
+	getTransaction := func(ctx context.Context, or orchestrator.Orchestrator, id string) (interface{}, error) {
+		return or.GetTransactionByID(ctx, id)
+	}
+	getOperation := func(ctx context.Context, or orchestrator.Orchestrator, id string) (interface{}, error) {
+		return or.GetOperationByID(ctx, id)
+	}
+	getEvent := func(ctx context.Context, or orchestrator.Orchestrator, id string) (interface{}, error) {
+		return or.GetEventByID(ctx, id)
+	}
+	getBlockchainEvent := func(ctx context.Context, or orchestrator.Orchestrator, id string) (interface{}, error) {
+		return or.GetBlockchainEventByID(ctx, id)
+	}
+	getTokenPool := func(ctx context.Context, or orchestrator.Orchestrator, nameOrID string) (interface{}, error) {
+		return or.Assets().GetTokenPoolByNameOrID(ctx, nameOrID)
+	}
+	getTokenTransfer := func(ctx context.Context, or orchestrator.Orchestrator, id string) (interface{}, error) {
+		return or.Assets().GetTokenTransferByID(ctx, id)
+	}
+	getIdentity := func(ctx context.Context, or orchestrator.Orchestrator, id string) (interface{}, error) {
+		return or.NetworkMap().GetIdentityByID(ctx, id)
+	}
+	getMessages := func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+		messages, _, err := or.GetMessages(ctx, filter)
+		return messages, err
+	}
+	getTokenTransfers := func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+		transfers, _, err := or.Assets().GetTokenTransfers(ctx, filter)
+		return transfers, err
+	}
+	getTokenApprovals := func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+		approvals, _, err := or.Assets().GetTokenApprovals(ctx, filter)
+		return approvals, err
+	}
+	getTokenBalances := func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+		balances, _, err := or.Assets().GetTokenBalances(ctx, filter)
+		return balances, err
+	}
+
+	messageType.fields["data"] = listField(dataType, nil,
+		func(s interface{}) string { return "messages/" + s.(*core.Message).Header.ID.String() + "/data" },
+		func(ctx context.Context, or orchestrator.Orchestrator, s interface{}, _ ffapi.AndFilter) (interface{}, error) {
+			return or.GetMessageData(ctx, s.(*core.Message).Header.ID.String())
+		})
+	messageType.fields["batch"] = oneField(batchType, nil, under("batches"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.Message).BatchID) }, getBatch)
+	messageType.fields["transaction"] = oneField(transactionType, nil, under("transactions"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.Message).TransactionID) }, getTransaction)
+	messageType.fields["events"] = listField(eventType, database.EventQueryFactory,
+		func(s interface{}) string { return "messages/" + s.(*core.Message).Header.ID.String() + "/events" },
+		func(ctx context.Context, or orchestrator.Orchestrator, s interface{}, filter ffapi.AndFilter) (interface{}, error) {
+			events, _, err := or.GetMessageEvents(ctx, s.(*core.Message).Header.ID.String(), filter)
+			return events, err
+		})
+
+	dataType.fields["messages"] = listField(messageType, database.MessageQueryFactory,
+		func(s interface{}) string { return "data/" + s.(*core.Data).ID.String() + "/messages" },
+		func(ctx context.Context, or orchestrator.Orchestrator, s interface{}, filter ffapi.AndFilter) (interface{}, error) {
+			messages, _, err := or.GetMessagesForData(ctx, s.(*core.Data).ID.String(), filter)
+			return messages, err
+		})
+
+	batchType.fields["transaction"] = oneField(transactionType, nil, under("transactions"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.BatchPersisted).TX.ID) }, getTransaction)
+	batchType.fields["messages"] = listField(messageType, database.MessageQueryFactory, fixed("messages"),
+		withCondition(getMessages, func(fb ffapi.FilterBuilder, s interface{}) ffapi.Filter {
+			return fb.Eq("batch", s.(*core.BatchPersisted).ID)
+		}))
+
+	transactionType.fields["operations"] = listField(operationType, nil,
+		func(s interface{}) string { return "transactions/" + s.(*core.Transaction).ID.String() + "/operations" },
+		func(ctx context.Context, or orchestrator.Orchestrator, s interface{}, _ ffapi.AndFilter) (interface{}, error) {
+			operations, _, err := or.GetTransactionOperations(ctx, s.(*core.Transaction).ID.String())
+			return operations, err
+		})
+	transactionType.fields["blockchainEvents"] = listField(blockchainEventType, nil,
+		func(s interface{}) string {
+			return "transactions/" + s.(*core.Transaction).ID.String() + "/blockchainevents"
+		},
+		func(ctx context.Context, or orchestrator.Orchestrator, s interface{}, _ ffapi.AndFilter) (interface{}, error) {
+			events, _, err := or.GetTransactionBlockchainEvents(ctx, s.(*core.Transaction).ID.String())
+			return events, err
+		})
+	transactionType.fields["status"] = oneField(nil, nil,
+		func(id string) string { return "transactions/" + url.PathEscape(id) + "/status" },
+		func(s interface{}, _ map[string]interface{}) string { return s.(*core.Transaction).ID.String() },
+		func(ctx context.Context, or orchestrator.Orchestrator, id string) (interface{}, error) {
+			return or.GetTransactionStatus(ctx, id)
+		})
+	transactionType.fields["messages"] = listField(messageType, database.MessageQueryFactory, fixed("messages"),
+		withCondition(getMessages, func(fb ffapi.FilterBuilder, s interface{}) ffapi.Filter {
+			return fb.Eq("txid", s.(*core.Transaction).ID)
+		}))
+	transactionType.fields["tokenTransfers"] = listField(tokenTransferType, database.TokenTransferQueryFactory, fixed("tokens/transfers"),
+		withCondition(getTokenTransfers, func(fb ffapi.FilterBuilder, s interface{}) ffapi.Filter {
+			return fb.Eq("tx.id", s.(*core.Transaction).ID)
+		}))
+	transactionType.fields["tokenApprovals"] = listField(tokenApprovalType, database.TokenApprovalQueryFactory, fixed("tokens/approvals"),
+		withCondition(getTokenApprovals, func(fb ffapi.FilterBuilder, s interface{}) ffapi.Filter {
+			return fb.Eq("tx.id", s.(*core.Transaction).ID)
+		}))
+
+	operationType.fields["transaction"] = oneField(transactionType, nil, under("transactions"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.Operation).Transaction) }, getTransaction)
+	eventType.fields["transaction"] = oneField(transactionType, nil, under("transactions"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.Event).Transaction) }, getTransaction)
+	blockchainEventType.fields["transaction"] = oneField(transactionType, nil, under("transactions"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.BlockchainEvent).TX.ID) }, getTransaction)
+
+	tokenPoolType.fields["transaction"] = oneField(transactionType, nil, under("transactions"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.TokenPool).TX.ID) }, getTransaction)
+	tokenPoolType.fields["transfers"] = listField(tokenTransferType, database.TokenTransferQueryFactory, fixed("tokens/transfers"),
+		withCondition(getTokenTransfers, func(fb ffapi.FilterBuilder, s interface{}) ffapi.Filter {
+			return fb.Eq("pool", s.(*core.TokenPool).ID)
+		}))
+	tokenPoolType.fields["balances"] = listField(tokenBalanceType, database.TokenBalanceQueryFactory, fixed("tokens/balances"),
+		withCondition(getTokenBalances, func(fb ffapi.FilterBuilder, s interface{}) ffapi.Filter {
+			return fb.Eq("pool", s.(*core.TokenPool).ID)
+		}))
+
+	tokenTransferType.fields["pool"] = oneField(tokenPoolType, nil, under("tokens/pools"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.TokenTransfer).Pool) }, getTokenPool)
+	tokenTransferType.fields["transaction"] = oneField(transactionType, nil, under("transactions"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.TokenTransfer).TX.ID) }, getTransaction)
+	tokenTransferType.fields["message"] = oneField(messageType, nil, under("messages"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.TokenTransfer).Message) }, getMessage)
+	tokenTransferType.fields["blockchainEvent"] = oneField(blockchainEventType, nil, under("blockchainevents"),
+		func(s interface{}, _ map[string]interface{}) string {
+			return idString(s.(*core.TokenTransfer).BlockchainEvent)
+		}, getBlockchainEvent)
+
+	tokenApprovalType.fields["pool"] = oneField(tokenPoolType, nil, under("tokens/pools"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.TokenApproval).Pool) }, getTokenPool)
+	tokenApprovalType.fields["transaction"] = oneField(transactionType, nil, under("transactions"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.TokenApproval).TX.ID) }, getTransaction)
+	tokenApprovalType.fields["message"] = oneField(messageType, nil, under("messages"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.TokenApproval).Message) }, getMessage)
+	tokenApprovalType.fields["blockchainEvent"] = oneField(blockchainEventType, nil, under("blockchainevents"),
+		func(s interface{}, _ map[string]interface{}) string {
+			return idString(s.(*core.TokenApproval).BlockchainEvent)
+		}, getBlockchainEvent)
+
+	tokenBalanceType.fields["pool"] = oneField(tokenPoolType, nil, under("tokens/pools"),
+		func(s interface{}, _ map[string]interface{}) string { return idString(s.(*core.TokenBalance).Pool) }, getTokenPool)
+
+	identityType.fields["verifiers"] = listField(verifierType, database.VerifierQueryFactory,
+		func(s interface{}) string { return "identities/" + s.(*core.Identity).ID.String() + "/verifiers" },
+		func(ctx context.Context, or orchestrator.Orchestrator, s interface{}, filter ffapi.AndFilter) (interface{}, error) {
+			verifiers, _, err := or.NetworkMap().GetIdentityVerifiers(ctx, s.(*core.Identity).ID.String(), filter)
+			return verifiers, err
+		})
+
+	return &objectType{
+		name: "Query",
+		fields: map[string]*fieldDef{
+			"message":  oneField(messageType, idArg, under("messages"), argKey("id"), getMessage),
+			"messages": listField(messageType, database.MessageQueryFactory, fixed("messages"), getMessages),
+			"data":     oneField(dataType, idArg, under("data"), argKey("id"), getData),
+			"dataItems": listField(dataType, database.DataQueryFactory, fixed("data"),
+				func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+					data, _, err := or.GetData(ctx, filter)
+					return data, err
+				}),
+			"batch": oneField(batchType, idArg, under("batches"), argKey("id"), getBatch),
+			"batches": listField(batchType, database.BatchQueryFactory, fixed("batches"),
+				func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+					batches, _, err := or.GetBatches(ctx, filter)
+					return batches, err
+				}),
+			"transaction": oneField(transactionType, idArg, under("transactions"), argKey("id"), getTransaction),
+			"transactions": listField(transactionType, database.TransactionQueryFactory, fixed("transactions"),
+				func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+					transactions, _, err := or.GetTransactions(ctx, filter)
+					return transactions, err
+				}),
+			"operation": oneField(operationType, idArg, under("operations"), argKey("id"), getOperation),
+			"operations": listField(operationType, database.OperationQueryFactory, fixed("operations"),
+				func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+					operations, _, err := or.GetOperations(ctx, filter)
+					return operations, err
+				}),
+			"event": oneField(eventType, idArg, under("events"), argKey("id"), getEvent),
+			"events": listField(eventType, database.EventQueryFactory, fixed("events"),
+				func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+					events, _, err := or.GetEvents(ctx, filter)
+					return events, err
+				}),
+			"blockchainEvent": oneField(blockchainEventType, idArg, under("blockchainevents"), argKey("id"), getBlockchainEvent),
+			"blockchainEvents": listField(blockchainEventType, database.BlockchainEventQueryFactory, fixed("blockchainevents"),
+				func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+					events, _, err := or.GetBlockchainEvents(ctx, filter)
+					return events, err
+				}),
+			"tokenPool": oneField(tokenPoolType, map[string]bool{"nameOrId": true}, under("tokens/pools"), argKey("nameOrId"), getTokenPool),
+			"tokenPools": listField(tokenPoolType, database.TokenPoolQueryFactory, fixed("tokens/pools"),
+				func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+					pools, _, err := or.Assets().GetTokenPools(ctx, filter)
+					return pools, err
+				}),
+			"tokenTransfer":  oneField(tokenTransferType, idArg, under("tokens/transfers"), argKey("id"), getTokenTransfer),
+			"tokenTransfers": listField(tokenTransferType, database.TokenTransferQueryFactory, fixed("tokens/transfers"), getTokenTransfers),
+			"tokenApprovals": listField(tokenApprovalType, database.TokenApprovalQueryFactory, fixed("tokens/approvals"), getTokenApprovals),
+			"tokenBalances":  listField(tokenBalanceType, database.TokenBalanceQueryFactory, fixed("tokens/balances"), getTokenBalances),
+			"identity":       oneField(identityType, idArg, under("identities"), argKey("id"), getIdentity),
+			"identities": listField(identityType, database.IdentityQueryFactory, fixed("identities"),
+				func(ctx context.Context, or orchestrator.Orchestrator, _ interface{}, filter ffapi.AndFilter) (interface{}, error) {
+					identities, _, err := or.NetworkMap().GetIdentities(ctx, filter)
+					return identities, err
+				}),
+			"status": {
+				resolve: func(ctx context.Context, env *Env, _ interface{}, _ map[string]interface{}) (interface{}, error) {
+					if err := env.authorize(ctx, "status"); err != nil {
+						return nil, err
+					}
+					return env.Orchestrator.GetStatus(ctx)
+				},
+			},
+		},
+	}
+}
+
+// modelObject returns an object type with a field for each JSON property of a REST API resource
+func modelObject(name string, model interface{}) *objectType {
+	typ := &objectType{name: name, fields: make(map[string]*fieldDef)}
+	addModelFields(typ, reflect.TypeOf(model))
+	return typ
+}
+
+func addModelFields(typ *objectType, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		switch {
+		case name == "" && sf.Anonymous:
+			// The properties of embedded structs are serialized as properties of the resource itself
+			addModelFields(typ, sf.Type)
+		case name != "" && name != "-":
+			typ.fields[name] = &fieldDef{}
+		}
+	}
+}
+
+var idArg = map[string]bool{"id": true}
+
+// oneField returns a field that resolves a single resource. The key function returns the ID (or name) of the
+// resource, from the source value or from the arguments, and the field is null if there is no key. The field
+// is authorized against the REST API route that returns the resource, built from the key by the route function.
+func oneField(typ *objectType, args map[string]bool, route func(key string) string, key func(source interface{}, args map[string]interface{}) string, get func(ctx context.Context, or orchestrator.Orchestrator, key string) (interface{}, error)) *fieldDef {
+	return &fieldDef{
+		typ:  typ,
+		args: args,
+		resolve: func(ctx context.Context, env *Env, source interface{}, args map[string]interface{}) (interface{}, error) {
+			k := key(source, args)
+			if k == "" {
+				return nil, nil
+			}
+			if err := env.authorize(ctx, route(k)); err != nil {
+				return nil, err
+			}
+			return get(ctx, env.Orchestrator, k)
+		},
+	}
+}
+
+// listField returns a field that resolves a list of resources, filtered by the arguments of the field if it has
+// a query factory. The field is authorized against the REST API route that returns the same list.
+func listField(typ *objectType, qf ffapi.QueryFactory, route func(source interface{}) string, get func(ctx context.Context, or orchestrator.Orchestrator, source interface{}, filter ffapi.AndFilter) (interface{}, error)) *fieldDef {
+	f := &fieldDef{
+		typ:  typ,
+		list: true,
+		resolve: func(ctx context.Context, env *Env, source interface{}, args map[string]interface{}) (interface{}, error) {
+			if err := env.authorize(ctx, route(source)); err != nil {
+				return nil, err
+			}
+			var filter ffapi.AndFilter
+			if qf != nil {
+				var err error
+				if filter, err = env.buildFilter(ctx, qf, args); err != nil {
+					return nil, err
+				}
+			}
+			return get(ctx, env.Orchestrator, source, filter)
+		},
+	}
+	if qf != nil {
+		f.args = filterArgs(qf)
+	}
+	return f
+}
+
+// withCondition adds a condition on the source value to the filter of a list, for related resources that are
+// returned by filtering a top level REST API route
+func withCondition(get func(ctx context.Context, or orchestrator.Orchestrator, source interface{}, filter ffapi.AndFilter) (interface{}, error), condition func(fb ffapi.FilterBuilder, source interface{}) ffapi.Filter) func(ctx context.Context, or orchestrator.Orchestrator, source interface{}, filter ffapi.AndFilter) (interface{}, error) {
+	return func(ctx context.Context, or orchestrator.Orchestrator, source interface{}, filter ffapi.AndFilter) (interface{}, error) {
+		filter.Condition(condition(filter.Builder(), source))
+		return get(ctx, or, source, filter)
+	}
+}
+
+func fixed(route string) func(interface{}) string {
+	return func(interface{}) string { return route }
+}
+
+func under(route string) func(key string) string {
+	return func(key string) string { return route + "/" + url.PathEscape(key) }
+}
+
+func argKey(name string) func(source interface{}, args map[string]interface{}) string {
+	return func(_ interface{}, args map[string]interface{}) string { return argString(args[name]) }
+}
+
+func idString(id *fftypes.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
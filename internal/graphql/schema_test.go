@@ -0,0 +1,176 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/assetmocks"
+	"github.com/hyperledger/firefly/mocks/networkmapmocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func filterContains(s string) interface{} {
+	return mock.MatchedBy(func(filter ffapi.AndFilter) bool {
+		fi, _ := filter.Finalize()
+		return strings.Contains(fi.String(), s)
+	})
+}
+
+func TestSchemaResolvers(t *testing.T) {
+	env, or, authorized := newTestEnv(t)
+	am := assetmocks.NewManager(t)
+	nm := networkmapmocks.NewManager(t)
+	or.On("Assets").Return(am)
+	or.On("NetworkMap").Return(nm)
+
+	txID := fftypes.NewUUID()
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}, BatchID: fftypes.NewUUID(), TransactionID: txID}
+	data := &core.Data{ID: fftypes.NewUUID()}
+	batch := &core.BatchPersisted{BatchHeader: core.BatchHeader{ID: msg.BatchID}, TX: core.TransactionRef{ID: txID}}
+	tx := &core.Transaction{ID: txID}
+	op := &core.Operation{ID: fftypes.NewUUID(), Transaction: txID}
+	event := &core.Event{ID: fftypes.NewUUID(), Transaction: txID}
+	blockchainEvent := &core.BlockchainEvent{ID: fftypes.NewUUID(), TX: core.BlockchainTransactionRef{ID: txID}}
+	pool := &core.TokenPool{ID: fftypes.NewUUID(), TX: core.TransactionRef{ID: txID}}
+	transfer := &core.TokenTransfer{LocalID: fftypes.NewUUID(), Pool: pool.ID, TX: core.TransactionRef{ID: txID}, Message: msg.Header.ID, BlockchainEvent: blockchainEvent.ID}
+	approval := &core.TokenApproval{LocalID: fftypes.NewUUID(), Pool: pool.ID, TX: core.TransactionRef{ID: txID}, Message: msg.Header.ID, BlockchainEvent: blockchainEvent.ID}
+	balance := &core.TokenBalance{Pool: pool.ID, Key: "0x1"}
+	identity := &core.Identity{IdentityBase: core.IdentityBase{ID: fftypes.NewUUID()}}
+	verifier := &core.Verifier{Hash: fftypes.NewRandB32()}
+
+	or.On("GetMessageByID", mock.Anything, "m1").Return(msg, nil)
+	or.On("GetMessageByID", mock.Anything, msg.Header.ID.String()).Return(msg, nil)
+	or.On("GetMessages", mock.Anything, filterContains("batch ==")).Return([]*core.Message{msg}, nil, nil)
+	or.On("GetMessages", mock.Anything, filterContains("txid ==")).Return([]*core.Message{msg}, nil, nil)
+	or.On("GetMessages", mock.Anything, filterContains("topics == 't1'")).Return([]*core.Message{msg}, nil, nil)
+	or.On("GetMessageData", mock.Anything, msg.Header.ID.String()).Return(core.DataArray{data}, nil)
+	or.On("GetMessageEvents", mock.Anything, msg.Header.ID.String(), mock.Anything).Return([]*core.Event{event}, nil, nil)
+	or.On("GetDataByID", mock.Anything, "d1").Return(data, nil)
+	or.On("GetData", mock.Anything, mock.Anything).Return(core.DataArray{data}, nil, nil)
+	or.On("GetMessagesForData", mock.Anything, data.ID.String(), mock.Anything).Return([]*core.Message{msg}, nil, nil)
+	or.On("GetBatchByID", mock.Anything, mock.Anything).Return(batch, nil)
+	or.On("GetBatches", mock.Anything, mock.Anything).Return([]*core.BatchPersisted{batch}, nil, nil)
+	or.On("GetTransactionByID", mock.Anything, mock.Anything).Return(tx, nil)
+	or.On("GetTransactions", mock.Anything, mock.Anything).Return([]*core.Transaction{tx}, nil, nil)
+	or.On("GetTransactionOperations", mock.Anything, txID.String()).Return([]*core.Operation{op}, nil, nil)
+	or.On("GetTransactionBlockchainEvents", mock.Anything, txID.String()).Return([]*core.BlockchainEvent{blockchainEvent}, nil, nil)
+	or.On("GetTransactionStatus", mock.Anything, txID.String()).Return(&core.TransactionStatus{Status: core.OpStatusPending}, nil)
+	or.On("GetOperationByID", mock.Anything, "o1").Return(op, nil)
+	or.On("GetOperations", mock.Anything, mock.Anything).Return([]*core.Operation{op}, nil, nil)
+	or.On("GetEventByID", mock.Anything, "e1").Return(event, nil)
+	or.On("GetEvents", mock.Anything, mock.Anything).Return([]*core.Event{event}, nil, nil)
+	or.On("GetBlockchainEventByID", mock.Anything, mock.Anything).Return(blockchainEvent, nil)
+	or.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*core.BlockchainEvent{blockchainEvent}, nil, nil)
+	or.On("GetStatus", mock.Anything).Return(&core.NamespaceStatus{}, nil)
+	am.On("GetTokenPoolByNameOrID", mock.Anything, mock.Anything).Return(pool, nil)
+	am.On("GetTokenPools", mock.Anything, mock.Anything).Return([]*core.TokenPool{pool}, nil, nil)
+	am.On("GetTokenTransferByID", mock.Anything, "t1").Return(transfer, nil)
+	am.On("GetTokenTransfers", mock.Anything, filterContains("tx.id ==")).Return([]*core.TokenTransfer{transfer}, nil, nil)
+	am.On("GetTokenTransfers", mock.Anything, filterContains("pool ==")).Return([]*core.TokenTransfer{transfer}, nil, nil)
+	am.On("GetTokenTransfers", mock.Anything, filterContains("limit=1")).Return([]*core.TokenTransfer{transfer}, nil, nil)
+	am.On("GetTokenApprovals", mock.Anything, filterContains("tx.id ==")).Return([]*core.TokenApproval{approval}, nil, nil)
+	am.On("GetTokenApprovals", mock.Anything, filterContains("limit=2")).Return([]*core.TokenApproval{approval}, nil, nil)
+	am.On("GetTokenBalances", mock.Anything, filterContains("pool ==")).Return([]*core.TokenBalance{balance}, nil, nil)
+	am.On("GetTokenBalances", mock.Anything, filterContains("key == '0x1'")).Return([]*core.TokenBalance{balance}, nil, nil)
+	nm.On("GetIdentityByID", mock.Anything, "i1").Return(identity, nil)
+	nm.On("GetIdentities", mock.Anything, mock.Anything).Return([]*core.Identity{identity}, nil, nil)
+	nm.On("GetIdentityVerifiers", mock.Anything, identity.ID.String(), mock.Anything).Return([]*core.Verifier{verifier}, nil, nil)
+
+	res, err := Execute(context.Background(), env, &core.GraphQLRequest{
+		Query: `{
+			message(id: "m1") { data { id } batch { id } transaction { id } events { id } }
+			messages(topics: "t1") { hash }
+			data(id: "d1") { messages { hash } }
+			dataItems { id }
+			batch(id: "b1") { transaction { id } messages { hash } }
+			batches { id }
+			transaction(id: "tx1") {
+				operations { id }
+				blockchainEvents { id }
+				status { status }
+				messages { hash }
+				tokenTransfers { localId }
+				tokenApprovals { localId }
+			}
+			transactions { id }
+			operation(id: "o1") { transaction { id } }
+			operations { id }
+			event(id: "e1") { transaction { id } }
+			events { id }
+			blockchainEvent(id: "be1") { transaction { id } }
+			blockchainEvents { id }
+			tokenPool(nameOrId: "pool1") { transaction { id } transfers { localId } balances { key } }
+			tokenPools { id }
+			tokenTransfer(id: "t1") { pool { id } transaction { id } message { hash } blockchainEvent { id } }
+			tokenTransfers(limit: 1) { localId }
+			tokenApprovals(limit: 2) { pool { id } transaction { id } message { hash } blockchainEvent { id } }
+			tokenBalances(key: "0x1") { pool { id } }
+			identity(id: "i1") { verifiers { hash } }
+			identities { id }
+			status { node { name } }
+		}`,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, res.Errors)
+	b, _ := json.Marshal(res.Data)
+	var result map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &result))
+	assert.Len(t, result, 23)
+	assert.Equal(t, data.ID.String(), result["dataItems"].([]interface{})[0].(map[string]interface{})["id"])
+
+	assert.Contains(t, *authorized, "messages/m1")
+	assert.Contains(t, *authorized, "messages/"+msg.Header.ID.String()+"/data")
+	assert.Contains(t, *authorized, "messages/"+msg.Header.ID.String()+"/events")
+	assert.Contains(t, *authorized, "data/"+data.ID.String()+"/messages")
+	assert.Contains(t, *authorized, "transactions/"+txID.String()+"/status")
+	assert.Contains(t, *authorized, "transactions/"+txID.String()+"/operations")
+	assert.Contains(t, *authorized, "transactions/"+txID.String()+"/blockchainevents")
+	assert.Contains(t, *authorized, "tokens/pools/"+pool.ID.String())
+	assert.Contains(t, *authorized, "tokens/transfers")
+	assert.Contains(t, *authorized, "identities/"+identity.ID.String()+"/verifiers")
+	assert.Contains(t, *authorized, "status")
+}
+
+func TestSchemaListFilterFail(t *testing.T) {
+	env, _, _ := newTestEnv(t)
+	env.MaxLimit = 10
+	res, err := Execute(context.Background(), env, &core.GraphQLRequest{
+		Query: `{ messages(limit: 11) { hash } }`,
+	})
+	assert.NoError(t, err)
+	assert.Regexp(t, "FF00192", res.Errors[0].Message)
+}
+
+func TestSchemaModelFields(t *testing.T) {
+	identityType := modelObject("Identity", core.Identity{})
+	assert.Contains(t, identityType.fields, "id")       // embedded IdentityBase
+	assert.Contains(t, identityType.fields, "profile")  // embedded IdentityProfile
+	assert.Contains(t, identityType.fields, "messages") // named struct
+	assert.NotContains(t, identityType.fields, "IdentityBase")
+}
+
+func TestIDString(t *testing.T) {
+	assert.Equal(t, "", idString(nil))
+}
@@ -0,0 +1,94 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/wsclient"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// StreamTracker records the state of a websocket event stream from a connector, so that plugins
+// can report whether the stream is connected, and when the last event arrived, in their health probes.
+//
+// The websocket client calls the before connect hook each time it (re)connects, which is the only
+// signal available that the previous connection was lost.
+//
+// A nil tracker is valid for recording events and reporting status, for a stream that has not been started.
+type StreamTracker struct {
+	mux       sync.Mutex
+	connected bool
+	lastEvent *fftypes.FFTime
+}
+
+func NewStreamTracker() *StreamTracker {
+	return &StreamTracker{}
+}
+
+// BeforeConnect wraps a websocket before connect hook (which may be nil), marking the stream disconnected
+func (st *StreamTracker) BeforeConnect(fn wsclient.WSPreConnectHandler) wsclient.WSPreConnectHandler {
+	return func(ctx context.Context, w wsclient.WSClient) error {
+		st.setConnected(false)
+		if fn != nil {
+			return fn(ctx, w)
+		}
+		return nil
+	}
+}
+
+// AfterConnect wraps a websocket after connect hook (which may be nil), marking the stream connected
+// once the hook has succeeded
+func (st *StreamTracker) AfterConnect(fn wsclient.WSPostConnectHandler) wsclient.WSPostConnectHandler {
+	return func(ctx context.Context, w wsclient.WSClient) (err error) {
+		if fn != nil {
+			err = fn(ctx, w)
+		}
+		st.setConnected(err == nil)
+		return err
+	}
+}
+
+// EventReceived must be called for each message received on the websocket
+func (st *StreamTracker) EventReceived() {
+	if st == nil {
+		return
+	}
+	st.mux.Lock()
+	defer st.mux.Unlock()
+	st.lastEvent = fftypes.Now()
+}
+
+func (st *StreamTracker) Status() *core.EventStreamHealth {
+	if st == nil {
+		return nil
+	}
+	st.mux.Lock()
+	defer st.mux.Unlock()
+	return &core.EventStreamHealth{
+		Connected: st.connected,
+		LastEvent: st.lastEvent,
+	}
+}
+
+func (st *StreamTracker) setConnected(connected bool) {
+	st.mux.Lock()
+	defer st.mux.Unlock()
+	st.connected = connected
+}
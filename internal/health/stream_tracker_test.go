@@ -0,0 +1,72 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/wsclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamTrackerConnectLifecycle(t *testing.T) {
+	st := NewStreamTracker()
+	ctx := context.Background()
+
+	status := st.Status()
+	assert.False(t, status.Connected)
+	assert.Nil(t, status.LastEvent)
+
+	err := st.AfterConnect(nil)(ctx, nil)
+	assert.NoError(t, err)
+	assert.True(t, st.Status().Connected)
+
+	st.EventReceived()
+	assert.NotNil(t, st.Status().LastEvent)
+
+	err = st.BeforeConnect(nil)(ctx, nil)
+	assert.NoError(t, err)
+	assert.False(t, st.Status().Connected)
+	assert.NotNil(t, st.Status().LastEvent)
+}
+
+func TestStreamTrackerWrapsHooks(t *testing.T) {
+	st := NewStreamTracker()
+	ctx := context.Background()
+
+	beforeCalled := false
+	err := st.BeforeConnect(func(ctx context.Context, w wsclient.WSClient) error {
+		beforeCalled = true
+		return nil
+	})(ctx, nil)
+	assert.NoError(t, err)
+	assert.True(t, beforeCalled)
+
+	err = st.AfterConnect(func(ctx context.Context, w wsclient.WSClient) error {
+		return fmt.Errorf("pop")
+	})(ctx, nil)
+	assert.Regexp(t, "pop", err)
+	assert.False(t, st.Status().Connected)
+}
+
+func TestStreamTrackerNil(t *testing.T) {
+	var st *StreamTracker
+	st.EventReceived()
+	assert.Nil(t, st.Status())
+}
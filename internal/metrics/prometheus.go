@@ -88,6 +88,7 @@ func initMetricsCollectors() {
 	InitTokenBurnMetrics()
 	InitBatchPinMetrics()
 	InitBlockchainMetrics()
+	InitRateLimitMetrics()
 }
 
 func registerMetricsCollectors() {
@@ -101,4 +102,5 @@ func registerMetricsCollectors() {
 	RegisterTokenTransferMetrics()
 	RegisterTokenBurnMetrics()
 	RegisterBlockchainMetrics()
+	RegisterRateLimitMetrics()
 }
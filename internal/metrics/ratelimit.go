@@ -0,0 +1,58 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var APIRateLimitedCounter *prometheus.CounterVec
+var APIChainWritesCounter *prometheus.CounterVec
+var APIChainWriteQuotaRemainingGauge *prometheus.GaugeVec
+
+// APIRateLimitedCounterName is the prometheus metric for tracking the total number of API requests rejected by a rate limit or quota
+var APIRateLimitedCounterName = "ff_api_rate_limited_total"
+
+// APIChainWritesCounterName is the prometheus metric for tracking the total number of chain-writing API requests accepted
+var APIChainWritesCounterName = "ff_api_chain_writes_total"
+
+// APIChainWriteQuotaRemainingGaugeName is the prometheus metric for tracking the chain-writing operations left in the daily quota of each namespace
+var APIChainWriteQuotaRemainingGaugeName = "ff_api_chain_write_quota_remaining"
+
+var NamespaceLabelName = "ns"
+var LimitLabelName = "limit"
+
+func InitRateLimitMetrics() {
+	APIRateLimitedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: APIRateLimitedCounterName,
+		Help: "Number of API requests rejected by a rate limit or quota",
+	}, []string{NamespaceLabelName, LimitLabelName})
+	APIChainWritesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: APIChainWritesCounterName,
+		Help: "Number of chain-writing API requests accepted",
+	}, []string{NamespaceLabelName})
+	APIChainWriteQuotaRemainingGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: APIChainWriteQuotaRemainingGaugeName,
+		Help: "Number of chain-writing operations left in the daily quota of the namespace",
+	}, []string{NamespaceLabelName})
+}
+
+func RegisterRateLimitMetrics() {
+	registry.MustRegister(APIRateLimitedCounter)
+	registry.MustRegister(APIChainWritesCounter)
+	registry.MustRegister(APIChainWriteQuotaRemainingGauge)
+}
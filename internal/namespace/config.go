@@ -17,9 +17,12 @@
 package namespace
 
 import (
+	"github.com/hyperledger/firefly-common/pkg/auth"
 	"github.com/hyperledger/firefly-common/pkg/auth/authfactory"
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftls"
+	"github.com/hyperledger/firefly/internal/auth/jwt"
+	"github.com/hyperledger/firefly/internal/auth/rbac"
 	"github.com/hyperledger/firefly/internal/blockchain/bifactory"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/database/difactory"
@@ -57,6 +60,22 @@ func InitConfig() {
 	namespacePredefined.AddKnownKey(coreconfig.NamespacePlugins)
 	namespacePredefined.AddKnownKey(coreconfig.NamespaceDefaultKey)
 	namespacePredefined.AddKnownKey(coreconfig.NamespaceAssetKeyNormalization)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionMaxAge)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionInterval, "1h")
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionBatchSize, 1000)
+	namespacePredefined.AddKnownKey(coreconfig.NamespaceRetentionArchiveDirectory)
+
+	operationsConf := namespacePredefined.SubSection(coreconfig.NamespaceOperations)
+	operationsConf.AddKnownKey(coreconfig.NamespaceOperationsCheckInterval, "30s")
+	policiesConf := operationsConf.SubArray(coreconfig.NamespaceOperationsPolicies)
+	policiesConf.AddKnownKey(coreconfig.NamespaceOperationsPolicyType)
+	policiesConf.AddKnownKey(coreconfig.NamespaceOperationsPolicyTimeout)
+	policiesConf.AddKnownKey(coreconfig.NamespaceOperationsPolicyTimeoutAction, "fail")
+	policiesConf.AddKnownKey(coreconfig.NamespaceOperationsPolicyRetryMaxAttempts, 0)
+	policiesConf.AddKnownKey(coreconfig.NamespaceOperationsPolicyRetryInitialDelay, "10s")
+	policiesConf.AddKnownKey(coreconfig.NamespaceOperationsPolicyRetryMaxDelay, "5m")
+	policiesConf.AddKnownKey(coreconfig.NamespaceOperationsPolicyRetryFactor, 2.0)
+	policiesConf.AddKnownKey(coreconfig.NamespaceOperationsPolicyRetryErrors)
 
 	multipartyConf := namespacePredefined.SubSection(coreconfig.NamespaceMultiparty)
 	multipartyConf.AddKnownKey(coreconfig.NamespaceMultipartyEnabled)
@@ -83,6 +102,12 @@ func InitConfig() {
 	dxfactory.InitConfig(dataexchangeConfig)
 	iifactory.InitConfig(identityConfig)
 	tifactory.InitConfig(tokensConfig)
+	authfactory.RegisterPlugins(map[string]func() auth.Plugin{
+		rbac.Name(): func() auth.Plugin { return &rbac.Auth{} },
+	}, authConfig.SubSection(rbac.Name()))
+	authfactory.RegisterPlugins(map[string]func() auth.Plugin{
+		jwt.Name(): func() auth.Plugin { return &jwt.Auth{} },
+	}, authConfig.SubSection(jwt.Name()))
 	authfactory.InitConfigArray(authConfig)
 	eifactory.InitConfig(eventsConfig)
 }
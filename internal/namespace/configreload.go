@@ -89,9 +89,14 @@ func (nm *namespaceManager) configReloaded(ctx context.Context) {
 
 	// From this point we need to block any API calls resolving namespaces,
 	// until the reload is complete
+	nm.dynamicMux.Lock()
+	defer nm.dynamicMux.Unlock()
 	nm.nsMux.Lock()
 	defer nm.nsMux.Unlock()
 
+	// Carry over the namespaces that were created through the admin API, rather than the config file
+	nm.mergeDynamicNamespaces(ctx, availablePlugins, allNewNamespaces)
+
 	// Stop all defunct namespaces
 	availableNS, updatedNamespaces := nm.stopDefunctNamespaces(ctx, availablePlugins, allNewNamespaces)
 
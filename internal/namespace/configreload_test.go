@@ -412,6 +412,7 @@ ui:
 func mockInitConfig(nmm *nmMocks) {
 	nmm.mdi.On("Init", mock.Anything, mock.Anything).Return(nil)
 	nmm.mdi.On("SetHandler", database.GlobalHandler, mock.Anything).Return()
+	nmm.mdi.On("GetDynamicNamespaces", mock.Anything).Return([]*core.Namespace{}, nil)
 	nmm.mbi.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	nmm.mdx.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 	nmm.mps.On("Init", mock.Anything, mock.Anything).Return(nil)
@@ -419,6 +420,7 @@ func mockInitConfig(nmm *nmMocks) {
 	nmm.mei[0].On("Init", mock.Anything, mock.Anything).Return(nil)
 	nmm.mei[1].On("Init", mock.Anything, mock.Anything).Return(nil)
 	nmm.mei[2].On("Init", mock.Anything, mock.Anything).Return(nil)
+	nmm.mei[3].On("Init", mock.Anything, mock.Anything).Return(nil)
 	nmm.mdi.On("GetNamespace", mock.Anything, "ns1").Return(nil, nil)
 	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(nil, nil)
 	nmm.mdi.On("GetNamespace", mock.Anything, "ns3").Return(nil, nil).Maybe()
@@ -0,0 +1,349 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"sort"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+// loadDynamicNamespace builds a namespace from a definition supplied through the admin API,
+// applying the same defaults and validation as a namespace loaded from the config file.
+func (nm *namespaceManager) loadDynamicNamespace(ctx context.Context, def *core.NamespaceDefinition, availablePlugins map[string]*plugin) (*namespace, error) {
+	if err := fftypes.ValidateFFNameField(ctx, def.Name, "name"); err != nil {
+		return nil, err
+	}
+	if def.Name == core.LegacySystemNamespace {
+		return nil, i18n.NewError(ctx, coremsgs.MsgFFSystemReservedName, core.LegacySystemNamespace)
+	}
+
+	keyNormalization := def.AssetKeyNormalization
+	if keyNormalization == "" {
+		keyNormalization = config.GetString(coreconfig.AssetManagerKeyNormalization)
+	}
+
+	// As in the config file, if no plugins are listed use all defined plugins by default
+	pluginNames := def.Plugins
+	if pluginNames == nil {
+		for pluginName, p := range availablePlugins {
+			if p.category != pluginCategoryEvents {
+				pluginNames = append(pluginNames, pluginName)
+			}
+		}
+		sort.Strings(pluginNames)
+	}
+
+	networkName := def.Name
+	nsConfig := orchestrator.Config{
+		DefaultKey:                  def.DefaultKey,
+		TokenBroadcastNames:         nm.tokenBroadcastNames,
+		KeyNormalization:            keyNormalization,
+		MaxHistoricalEventScanLimit: config.GetInt(coreconfig.SubscriptionMaxHistoricalEventScanLength),
+	}
+	if mp := def.Multiparty; mp != nil && mp.Enabled {
+		if mp.NetworkNamespace == core.LegacySystemNamespace {
+			return nil, i18n.NewError(ctx, coremsgs.MsgFFSystemReservedName, core.LegacySystemNamespace)
+		}
+		if mp.NetworkNamespace != "" {
+			networkName = mp.NetworkNamespace
+		}
+
+		contracts := make([]blockchain.MultipartyContract, len(mp.Contract))
+		for i, c := range mp.Contract {
+			firstEvent := c.FirstEvent
+			if firstEvent == "" {
+				firstEvent = string(core.SubOptsFirstEventOldest)
+			}
+			contracts[i] = blockchain.MultipartyContract{
+				Location:   fftypes.JSONAnyPtr(c.Location.JSONObject().String()),
+				FirstEvent: firstEvent,
+				Options:    fftypes.JSONAnyPtr(c.Options.JSONObject().String()),
+			}
+		}
+
+		nsConfig.Multiparty.Enabled = true
+		nsConfig.Multiparty.Org.Name = mp.Org.Name
+		nsConfig.Multiparty.Org.Key = mp.Org.Key
+		nsConfig.Multiparty.Org.Description = mp.Org.Description
+		nsConfig.Multiparty.Node.Name = mp.Node.Name
+		nsConfig.Multiparty.Node.Description = mp.Node.Description
+		nsConfig.Multiparty.Contracts = contracts
+	}
+
+	defBytes, _ := json.Marshal(def)
+	ns := &namespace{
+		Namespace: core.Namespace{
+			Name:        def.Name,
+			NetworkName: networkName,
+			Description: def.Description,
+			TLSConfigs:  make(map[string]*tls.Config),
+			Definition:  def,
+		},
+		loadTime:    fftypes.Now(),
+		config:      nsConfig,
+		configHash:  fftypes.HashString(string(defBytes)),
+		pluginNames: pluginNames,
+		stopped:     def.Stopped,
+	}
+	if err := nm.validateNamespace(ctx, ns, availablePlugins); err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+// loadDynamicNamespaces restores the namespaces created through the admin API, from each database plugin.
+// Namespaces in the config file take precedence, and definitions that are no longer valid against the
+// configured plugins are logged and skipped, so that they can be corrected through the API.
+func (nm *namespaceManager) loadDynamicNamespaces(ctx context.Context) error {
+	for _, p := range nm.plugins {
+		if p.category != pluginCategoryDatabase {
+			continue
+		}
+		stored, err := p.database.GetDynamicNamespaces(ctx)
+		if err != nil {
+			return err
+		}
+		for _, storedNS := range stored {
+			if _, exists := nm.namespaces[storedNS.Name]; exists {
+				log.L(ctx).Warnf("Namespace '%s' is defined in the config file - ignoring the definition stored in database plugin '%s'", storedNS.Name, p.name)
+				continue
+			}
+			def := storedNS.Definition
+			def.Name = storedNS.Name
+			ns, err := nm.loadDynamicNamespace(ctx, def, nm.plugins)
+			if err != nil {
+				log.L(ctx).Errorf("Failed to load namespace '%s' stored in database plugin '%s': %s", storedNS.Name, p.name, err)
+				continue
+			}
+			if ns.plugins.Database.Name != p.name {
+				log.L(ctx).Warnf("Namespace '%s' no longer uses database plugin '%s' - ignoring the stored definition", storedNS.Name, p.name)
+				continue
+			}
+			log.L(ctx).Infof("Loaded namespace '%s' from database plugin '%s' stopped=%t", ns.Name, p.name, ns.stopped)
+			nm.namespaces[ns.Name] = ns
+		}
+	}
+	return nil
+}
+
+// mergeDynamicNamespaces re-validates the namespaces created through the admin API against the plugins
+// available after a config reload, and adds them to the new set of namespaces.
+// Must be called while holding nsMux.
+func (nm *namespaceManager) mergeDynamicNamespaces(ctx context.Context, availablePlugins map[string]*plugin, newNamespaces map[string]*namespace) {
+	for name, existingNS := range nm.namespaces {
+		if existingNS.Definition == nil {
+			continue
+		}
+		if newNamespaces[name] != nil {
+			log.L(ctx).Warnf("Namespace '%s' is now defined in the config file, which takes precedence over the definition created through the API", name)
+			continue
+		}
+		ns, err := nm.loadDynamicNamespace(ctx, existingNS.Definition, availablePlugins)
+		if err != nil {
+			log.L(ctx).Errorf("Namespace '%s' is no longer valid after config reload, and will be stopped: %s", name, err)
+			continue
+		}
+		newNamespaces[name] = ns
+	}
+}
+
+func (nm *namespaceManager) getDynamicNamespace(ctx context.Context, name string) (*namespace, error) {
+	nm.nsMux.Lock()
+	defer nm.nsMux.Unlock()
+	ns := nm.namespaces[name]
+	if ns == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgUnknownNamespace, name)
+	}
+	if ns.Definition == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceNotDynamic, name)
+	}
+	return ns, nil
+}
+
+// startDynamicNamespace starts the namespace in the background, using the same retry loop as
+// namespaces loaded from the config file.
+// Must be called while holding nsMux.
+func (nm *namespaceManager) startDynamicNamespace(ns *namespace) error {
+	log.L(nm.ctx).Infof("Initiating start of namespace '%s'", ns.Name)
+	if err := nm.preInitNamespace(ns); err != nil {
+		return err
+	}
+	nm.namespaces[ns.Name] = ns
+	go nm.namespaceStarter(ns)
+	return nil
+}
+
+// stopDynamicNamespace stops the orchestrator, and removes its handlers from the plugins.
+// Must be called while holding dynamicMux, but not nsMux - as the namespace starter needs nsMux to
+// record the outcome of a start that is in progress, before the orchestrator can be stopped.
+func (nm *namespaceManager) stopDynamicNamespace(ctx context.Context, ns *namespace) {
+	nm.nsMux.Lock()
+	ns.started = false
+	ns.stopped = true
+	nm.nsMux.Unlock()
+
+	nm.stopNamespace(ctx, ns)
+	if ns.orchestrator != nil {
+		orchestrator.Purge(ctx, &ns.Namespace, ns.plugins, ns.config.Multiparty.Node.Name)
+	}
+	nm.cacheManager.ResetCachesForNamespace(ns.Name)
+}
+
+// storeDefinition updates only the definition on the stored namespace, so that the multiparty contract
+// state is preserved even if the namespace was never started since the node was restarted.
+// If the namespace has not been stored, such as when it is first created, a new record is stored.
+func (nm *namespaceManager) storeDefinition(ctx context.Context, ns *namespace, def *core.NamespaceDefinition) error {
+	database := ns.plugins.Database.Plugin
+	stored, err := database.GetNamespace(ctx, ns.Name)
+	if err != nil {
+		return err
+	}
+	if stored == nil {
+		if def == nil {
+			// Nothing to remove
+			return nil
+		}
+		stored = &core.Namespace{
+			Name:        ns.Name,
+			NetworkName: ns.NetworkName,
+			Description: ns.Description,
+			Created:     fftypes.Now(),
+			Contracts: &core.MultipartyContracts{
+				Active: &core.MultipartyContract{},
+			},
+		}
+	}
+	stored.Definition = def
+	return database.UpsertNamespace(ctx, stored, true)
+}
+
+func (nm *namespaceManager) CreateNamespace(ctx context.Context, def *core.NamespaceDefinition) (*core.Namespace, error) {
+	nm.dynamicMux.Lock()
+	defer nm.dynamicMux.Unlock()
+	nm.nsMux.Lock()
+	defer nm.nsMux.Unlock()
+
+	if _, exists := nm.namespaces[def.Name]; exists {
+		return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceAlreadyExists, def.Name)
+	}
+	def.Stopped = false
+	ns, err := nm.loadDynamicNamespace(ctx, def, nm.plugins)
+	if err != nil {
+		return nil, err
+	}
+	// The definition is stored before starting, so the namespace is restored if the node restarts
+	if err := nm.storeDefinition(ctx, ns, def); err != nil {
+		return nil, err
+	}
+	if err := nm.startDynamicNamespace(ns); err != nil {
+		return nil, err
+	}
+	return &ns.Namespace, nil
+}
+
+func (nm *namespaceManager) UpdateNamespace(ctx context.Context, name string, def *core.NamespaceDefinition) (*core.Namespace, error) {
+	nm.dynamicMux.Lock()
+	defer nm.dynamicMux.Unlock()
+
+	existing, err := nm.getDynamicNamespace(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	def.Name = name
+	def.Stopped = false
+	ns, err := nm.loadDynamicNamespace(ctx, def, nm.plugins)
+	if err != nil {
+		return nil, err
+	}
+	if !existing.stopped && existing.configHash.Equals(ns.configHash) {
+		log.L(ctx).Infof("Namespace '%s' unchanged", name)
+		return &existing.Namespace, nil
+	}
+
+	if !existing.stopped {
+		nm.stopDynamicNamespace(ctx, existing)
+	}
+	if err := nm.storeDefinition(ctx, ns, def); err != nil {
+		return nil, err
+	}
+	nm.nsMux.Lock()
+	defer nm.nsMux.Unlock()
+	if err := nm.startDynamicNamespace(ns); err != nil {
+		return nil, err
+	}
+	return &ns.Namespace, nil
+}
+
+func (nm *namespaceManager) StopNamespace(ctx context.Context, name string) (*core.Namespace, error) {
+	nm.dynamicMux.Lock()
+	defer nm.dynamicMux.Unlock()
+
+	ns, err := nm.getDynamicNamespace(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if ns.stopped {
+		return &ns.Namespace, nil
+	}
+	nm.stopDynamicNamespace(ctx, ns)
+
+	// Record the stop, so the namespace is not restarted when the node restarts
+	def := *ns.Definition
+	def.Stopped = true
+	if err := nm.storeDefinition(ctx, ns, &def); err != nil {
+		return nil, err
+	}
+	nm.nsMux.Lock()
+	defer nm.nsMux.Unlock()
+	ns.Definition = &def
+	return &ns.Namespace, nil
+}
+
+func (nm *namespaceManager) DeleteNamespace(ctx context.Context, name string) error {
+	nm.dynamicMux.Lock()
+	defer nm.dynamicMux.Unlock()
+
+	ns, err := nm.getDynamicNamespace(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !ns.stopped {
+		nm.stopDynamicNamespace(ctx, ns)
+	}
+
+	// The data in the namespace remains in the database, but the namespace is no longer loaded
+	if err := nm.storeDefinition(ctx, ns, nil); err != nil {
+		return err
+	}
+	nm.nsMux.Lock()
+	defer nm.nsMux.Unlock()
+	delete(nm.namespaces, name)
+	log.L(ctx).Infof("Namespace '%s' deleted", name)
+	return nil
+}
@@ -0,0 +1,512 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestDefinition(name string) *core.NamespaceDefinition {
+	return &core.NamespaceDefinition{
+		Name:    name,
+		Plugins: []string{"postgres"},
+	}
+}
+
+func newTestDynamicNamespace(t *testing.T, nm *namespaceManager, name string) *namespace {
+	ns, err := nm.loadDynamicNamespace(context.Background(), newTestDefinition(name), nm.plugins)
+	assert.NoError(t, err)
+	nm.namespaces[name] = ns
+	return ns
+}
+
+func TestLoadDynamicNamespaceMultipartyDefaults(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	ns, err := nm.loadDynamicNamespace(context.Background(), &core.NamespaceDefinition{
+		Name: "ns2",
+		Multiparty: &core.NamespaceDefinitionMultiparty{
+			Enabled:          true,
+			NetworkNamespace: "shared2",
+			Org:              core.NamespaceDefinitionMember{Name: "org1", Key: "0x12345"},
+			Node:             core.NamespaceDefinitionMember{Name: "node1"},
+			Contract: []*core.NamespaceDefinitionContract{
+				{Location: fftypes.JSONAnyPtr(`{"address":"0x7359d2ecc199C48369b390522c29b77A5Af30882"}`)},
+			},
+		},
+	}, nm.plugins)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "shared2", ns.NetworkName)
+	assert.Equal(t, []string{"basicauth", "erc1155", "erc721", "ethereum", "ffdx", "ipfs", "postgres", "tbd"}, ns.pluginNames)
+	assert.True(t, ns.config.Multiparty.Enabled)
+	assert.Equal(t, "0x12345", ns.config.Multiparty.Org.Key)
+	assert.Equal(t, "node1", ns.config.Multiparty.Node.Name)
+	assert.Len(t, ns.config.Multiparty.Contracts, 1)
+	assert.Equal(t, "oldest", ns.config.Multiparty.Contracts[0].FirstEvent)
+	assert.Equal(t, "{}", ns.config.Multiparty.Contracts[0].Options.String())
+	assert.Equal(t, "blockchain_plugin", ns.config.KeyNormalization)
+	assert.Len(t, ns.plugins.Events, 4)
+}
+
+func TestLoadDynamicNamespaceBadName(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	_, err := nm.loadDynamicNamespace(context.Background(), newTestDefinition("!bad"), nm.plugins)
+	assert.Regexp(t, "FF00140", err)
+}
+
+func TestLoadDynamicNamespaceReservedName(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	_, err := nm.loadDynamicNamespace(context.Background(), newTestDefinition(core.LegacySystemNamespace), nm.plugins)
+	assert.Regexp(t, "FF10388", err)
+}
+
+func TestLoadDynamicNamespaceReservedNetworkName(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	def := newTestDefinition("ns2")
+	def.Multiparty = &core.NamespaceDefinitionMultiparty{
+		Enabled:          true,
+		NetworkNamespace: core.LegacySystemNamespace,
+	}
+	_, err := nm.loadDynamicNamespace(context.Background(), def, nm.plugins)
+	assert.Regexp(t, "FF10388", err)
+}
+
+func TestLoadDynamicNamespaceUnknownPlugin(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	def := newTestDefinition("ns2")
+	def.Plugins = []string{"postgres", "unknown"}
+	_, err := nm.loadDynamicNamespace(context.Background(), def, nm.plugins)
+	assert.Regexp(t, "FF10390", err)
+}
+
+func TestLoadDynamicNamespaceNoDatabase(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	def := newTestDefinition("ns2")
+	def.Plugins = []string{}
+	def.AssetKeyNormalization = "none"
+	_, err := nm.loadDynamicNamespace(context.Background(), def, nm.plugins)
+	assert.Regexp(t, "FF10392", err)
+}
+
+func TestInitLoadDynamicNamespaces(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	badDef := newTestDefinition("ns3")
+	badDef.Plugins = []string{"unknown"}
+	stoppedDef := newTestDefinition("ns4")
+	stoppedDef.Stopped = true
+	nmm.mdi.On("GetDynamicNamespaces", mock.Anything).Return([]*core.Namespace{
+		{Name: "default", Definition: newTestDefinition("default")},
+		{Name: "ns2", Definition: newTestDefinition("ns2")},
+		{Name: "ns3", Definition: badDef},
+		{Name: "ns4", Definition: stoppedDef},
+	}, nil).Once()
+
+	// A second database plugin, which the stored namespace no longer uses
+	mdi2 := &databasemocks.Plugin{}
+	mdi2.On("GetDynamicNamespaces", mock.Anything).Return([]*core.Namespace{
+		{Name: "ns5", Definition: newTestDefinition("ns5")},
+	}, nil).Once()
+	nm.plugins["sqlite"] = &plugin{name: "sqlite", category: pluginCategoryDatabase, database: mdi2}
+
+	err := nm.loadDynamicNamespaces(context.Background())
+	assert.NoError(t, err)
+
+	assert.Len(t, nm.namespaces, 3)
+	assert.Nil(t, nm.namespaces["default"].Definition)
+	assert.Equal(t, "ns2", nm.namespaces["ns2"].Definition.Name)
+	assert.False(t, nm.namespaces["ns2"].stopped)
+	assert.True(t, nm.namespaces["ns4"].stopped)
+
+	mdi2.AssertExpectations(t)
+}
+
+func TestInitLoadDynamicNamespacesFail(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	nmm.mdi.On("GetDynamicNamespaces", mock.Anything).Return(nil, fmt.Errorf("pop")).Once()
+
+	err := nm.loadDynamicNamespaces(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestInitComponentsFailBeforeLoadDynamicNamespaces(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+
+	nmm.mei[0].On("Init", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Maybe()
+	nmm.mei[1].On("Init", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Maybe()
+	nmm.mei[2].On("Init", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Maybe()
+	nmm.mei[3].On("Init", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Maybe()
+
+	err := nm.Init(nm.ctx, nm.cancelCtx, nm.reset, nm.reloadConfig)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestStartSkipsStoppedNamespaces(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	ns := newTestDynamicNamespace(t, nm, "ns2")
+	ns.stopped = true
+
+	err := nm.startNamespacesAndPlugins(map[string]*namespace{"ns2": ns}, map[string]*plugin{})
+	assert.NoError(t, err)
+
+	_, err = nm.Orchestrator(context.Background(), "ns2", true)
+	assert.Regexp(t, "FF10516", err)
+
+	results, err := nm.GetNamespaces(context.Background(), true)
+	assert.NoError(t, err)
+	for _, r := range results {
+		if r.Name == "ns2" {
+			assert.True(t, r.Stopped)
+			assert.False(t, r.Initializing)
+		}
+	}
+}
+
+func TestMergeDynamicNamespaces(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	newTestDynamicNamespace(t, nm, "ns2")
+	newTestDynamicNamespace(t, nm, "ns3")
+	newTestDynamicNamespace(t, nm, "ns4")
+
+	newNamespaces := map[string]*namespace{
+		"ns3": {Namespace: core.Namespace{Name: "ns3"}},
+	}
+	availablePlugins := map[string]*plugin{
+		"postgres": nm.plugins["postgres"],
+	}
+	nm.namespaces["ns4"].Definition.Plugins = []string{"ethereum"}
+	nm.mergeDynamicNamespaces(context.Background(), availablePlugins, newNamespaces)
+
+	assert.Len(t, newNamespaces, 2)
+	assert.NotNil(t, newNamespaces["ns2"].Definition)
+	assert.Nil(t, newNamespaces["ns3"].Definition)
+}
+
+func TestCreateNamespaceOK(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(nil, nil)
+	nmm.mdi.On("UpsertNamespace", mock.Anything, mock.MatchedBy(func(ns *core.Namespace) bool {
+		return ns.Name == "ns2" && ns.Definition != nil && ns.Created != nil
+	}), true).Return(nil)
+	nmm.mo.On("PreInit", mock.Anything, mock.Anything).Return()
+	nmm.mo.On("Init").Return(nil)
+	nmm.mo.On("Start").Return(nil)
+	nmm.mo.On("WaitStop").Return().Maybe()
+	waitInit := namespaceInitWaiter(t, nmm, []string{"ns2"})
+
+	ns, err := nm.CreateNamespace(context.Background(), newTestDefinition("ns2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ns2", ns.Name)
+
+	waitInit.Wait()
+
+	_, err = nm.Orchestrator(context.Background(), "ns2", false)
+	assert.NoError(t, err)
+}
+
+func TestCreateNamespaceExists(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	_, err := nm.CreateNamespace(context.Background(), newTestDefinition("default"))
+	assert.Regexp(t, "FF10514", err)
+}
+
+func TestCreateNamespaceInvalid(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	def := newTestDefinition("ns2")
+	def.Plugins = []string{"unknown"}
+	_, err := nm.CreateNamespace(context.Background(), def)
+	assert.Regexp(t, "FF10390", err)
+}
+
+func TestCreateNamespacePreInitFail(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(nil, nil).Once()
+	nmm.mdi.On("UpsertNamespace", mock.Anything, mock.Anything, true).Return(nil)
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.CreateNamespace(context.Background(), newTestDefinition("ns2"))
+	assert.EqualError(t, err, "pop")
+	assert.Nil(t, nm.namespaces["ns2"])
+}
+
+func TestCreateNamespaceStoreFail(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(nil, nil)
+	nmm.mdi.On("UpsertNamespace", mock.Anything, mock.Anything, true).Return(fmt.Errorf("pop"))
+
+	_, err := nm.CreateNamespace(context.Background(), newTestDefinition("ns2"))
+	assert.EqualError(t, err, "pop")
+	assert.Nil(t, nm.namespaces["ns2"])
+	nmm.mo.AssertNotCalled(t, "PreInit", mock.Anything, mock.Anything)
+}
+
+func TestUpdateNamespaceRestart(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	existing := newTestDynamicNamespace(t, nm, "ns2")
+	existing.cancelCtx = func() {}
+	existing.orchestrator = nmm.mo
+
+	nmm.mo.On("WaitStop").Run(func(args mock.Arguments) {
+		// The orchestrator is not waited for while holding the lock needed by the namespace starter
+		assert.True(t, nm.nsMux.TryLock())
+		nm.nsMux.Unlock()
+	}).Return()
+	nmm.mdi.On("SetHandler", "ns2", nil).Return()
+	nmm.cmi.On("ResetCachesForNamespace", "ns2").Return()
+	nm.cacheManager = nmm.cmi
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(&core.Namespace{Name: "ns2", Created: fftypes.Now()}, nil)
+	nmm.mdi.On("UpsertNamespace", mock.Anything, mock.MatchedBy(func(ns *core.Namespace) bool {
+		return ns.Definition.Description == "updated"
+	}), true).Return(nil)
+	nmm.mo.On("PreInit", mock.Anything, mock.Anything).Return()
+	nmm.mo.On("Init").Return(nil)
+	nmm.mo.On("Start").Return(nil)
+	waitInit := namespaceInitWaiter(t, nmm, []string{"ns2"})
+
+	def := newTestDefinition("")
+	def.Description = "updated"
+	ns, err := nm.UpdateNamespace(context.Background(), "ns2", def)
+	assert.NoError(t, err)
+	assert.Equal(t, "updated", ns.Description)
+	assert.True(t, existing.stopped)
+
+	waitInit.Wait()
+}
+
+func TestUpdateNamespaceUnchanged(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	existing := newTestDynamicNamespace(t, nm, "ns2")
+
+	ns, err := nm.UpdateNamespace(context.Background(), "ns2", newTestDefinition("ns2"))
+	assert.NoError(t, err)
+	assert.Equal(t, &existing.Namespace, ns)
+}
+
+func TestUpdateNamespaceStoppedStartFail(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	existing := newTestDynamicNamespace(t, nm, "ns2")
+	existing.stopped = true
+
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.UpdateNamespace(context.Background(), "ns2", newTestDefinition("ns2"))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestUpdateNamespaceStoppedPreInitFail(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	existing := newTestDynamicNamespace(t, nm, "ns2")
+	existing.stopped = true
+
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(&core.Namespace{Name: "ns2"}, nil).Once()
+	nmm.mdi.On("UpsertNamespace", mock.Anything, mock.Anything, true).Return(nil)
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.UpdateNamespace(context.Background(), "ns2", newTestDefinition("ns2"))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestUpdateNamespaceInvalid(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	newTestDynamicNamespace(t, nm, "ns2")
+
+	def := newTestDefinition("ns2")
+	def.Plugins = []string{"unknown"}
+	_, err := nm.UpdateNamespace(context.Background(), "ns2", def)
+	assert.Regexp(t, "FF10390", err)
+}
+
+func TestUpdateNamespaceNotDynamic(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	_, err := nm.UpdateNamespace(context.Background(), "default", newTestDefinition("default"))
+	assert.Regexp(t, "FF10515", err)
+}
+
+func TestUpdateNamespaceUnknown(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	_, err := nm.UpdateNamespace(context.Background(), "ns2", newTestDefinition("ns2"))
+	assert.Regexp(t, "FF10436", err)
+}
+
+func TestStopNamespaceOK(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	existing := newTestDynamicNamespace(t, nm, "ns2")
+
+	nmm.cmi.On("ResetCachesForNamespace", "ns2").Return()
+	nm.cacheManager = nmm.cmi
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(&core.Namespace{Name: "ns2", Created: fftypes.Now()}, nil)
+	nmm.mdi.On("UpsertNamespace", mock.Anything, mock.MatchedBy(func(ns *core.Namespace) bool {
+		return ns.Created != nil && ns.Definition.Stopped
+	}), true).Return(nil)
+
+	ns, err := nm.StopNamespace(context.Background(), "ns2")
+	assert.NoError(t, err)
+	assert.True(t, ns.Definition.Stopped)
+	assert.True(t, existing.stopped)
+
+	// Stopping again is a no-op
+	_, err = nm.StopNamespace(context.Background(), "ns2")
+	assert.NoError(t, err)
+}
+
+func TestStopNamespaceNotStored(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	newTestDynamicNamespace(t, nm, "ns2")
+
+	nmm.cmi.On("ResetCachesForNamespace", "ns2").Return()
+	nm.cacheManager = nmm.cmi
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(nil, nil)
+	nmm.mdi.On("UpsertNamespace", mock.Anything, mock.MatchedBy(func(ns *core.Namespace) bool {
+		return ns.Name == "ns2" && ns.Created != nil && ns.Contracts.Active != nil && ns.Definition.Stopped
+	}), true).Return(nil)
+
+	ns, err := nm.StopNamespace(context.Background(), "ns2")
+	assert.NoError(t, err)
+	assert.True(t, ns.Definition.Stopped)
+}
+
+func TestStopNamespaceStoreFail(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	newTestDynamicNamespace(t, nm, "ns2")
+
+	nmm.cmi.On("ResetCachesForNamespace", "ns2").Return()
+	nm.cacheManager = nmm.cmi
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.StopNamespace(context.Background(), "ns2")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestStopNamespaceUnknown(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	_, err := nm.StopNamespace(context.Background(), "ns2")
+	assert.Regexp(t, "FF10436", err)
+}
+
+func TestDeleteNamespaceOK(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	newTestDynamicNamespace(t, nm, "ns2")
+
+	nmm.cmi.On("ResetCachesForNamespace", "ns2").Return()
+	nm.cacheManager = nmm.cmi
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(&core.Namespace{Name: "ns2", Definition: newTestDefinition("ns2")}, nil)
+	nmm.mdi.On("UpsertNamespace", mock.Anything, mock.MatchedBy(func(ns *core.Namespace) bool {
+		return ns.Definition == nil
+	}), true).Return(nil)
+
+	err := nm.DeleteNamespace(context.Background(), "ns2")
+	assert.NoError(t, err)
+	assert.Nil(t, nm.namespaces["ns2"])
+}
+
+func TestDeleteNamespaceStoppedNotStored(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	existing := newTestDynamicNamespace(t, nm, "ns2")
+	existing.stopped = true
+
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(nil, nil)
+
+	err := nm.DeleteNamespace(context.Background(), "ns2")
+	assert.NoError(t, err)
+	assert.Nil(t, nm.namespaces["ns2"])
+}
+
+func TestDeleteNamespaceStoreFail(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	existing := newTestDynamicNamespace(t, nm, "ns2")
+	existing.stopped = true
+
+	nmm.mdi.On("GetNamespace", mock.Anything, "ns2").Return(nil, fmt.Errorf("pop"))
+
+	err := nm.DeleteNamespace(context.Background(), "ns2")
+	assert.EqualError(t, err, "pop")
+	assert.NotNil(t, nm.namespaces["ns2"])
+}
+
+func TestDeleteNamespaceNotDynamic(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	err := nm.DeleteNamespace(context.Background(), "default")
+	assert.Regexp(t, "FF10515", err)
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -32,6 +34,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly-common/pkg/retry"
+	"github.com/hyperledger/firefly/internal/audit"
 	"github.com/hyperledger/firefly/internal/blockchain/bifactory"
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
@@ -42,7 +45,9 @@ import (
 	"github.com/hyperledger/firefly/internal/events/system"
 	"github.com/hyperledger/firefly/internal/identity/iifactory"
 	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/internal/retention"
 	"github.com/hyperledger/firefly/internal/sharedstorage/ssfactory"
 	"github.com/hyperledger/firefly/internal/spievents"
 	"github.com/hyperledger/firefly/internal/tokens/tifactory"
@@ -70,6 +75,13 @@ type Manager interface {
 	GetOperationByNamespacedID(ctx context.Context, nsOpID string) (*core.Operation, error)
 	ResolveOperationByNamespacedID(ctx context.Context, nsOpID string, op *core.OperationUpdateDTO) error
 	Authorize(ctx context.Context, authReq *fftypes.AuthReq) error
+	AuthorizeGlobal(ctx context.Context, authReq *fftypes.AuthReq) error
+	NodeAudit(ctx context.Context) (audit.Manager, error)
+	CreateNamespace(ctx context.Context, def *core.NamespaceDefinition) (*core.Namespace, error)
+	UpdateNamespace(ctx context.Context, name string, def *core.NamespaceDefinition) (*core.Namespace, error)
+	StopNamespace(ctx context.Context, name string) (*core.Namespace, error)
+	DeleteNamespace(ctx context.Context, name string) error
+	GetReadiness(ctx context.Context) (*core.NodeReadiness, error)
 }
 
 type namespace struct {
@@ -83,6 +95,7 @@ type namespace struct {
 	pluginNames  []string
 	plugins      *orchestrator.Plugins
 	started      bool
+	stopped      bool
 	initError    string
 }
 
@@ -92,6 +105,7 @@ type namespaceManager struct {
 	ctx                 context.Context
 	cancelCtx           context.CancelFunc
 	nsMux               sync.Mutex
+	dynamicMux          sync.Mutex // serializes changes through the admin API, and is always locked before nsMux
 	namespaces          map[string]*namespace
 	plugins             map[string]*plugin
 	metricsEnabled      bool
@@ -101,6 +115,8 @@ type namespaceManager struct {
 	tokenBroadcastNames map[string]string
 	watchConfig         func() // indirect from viper.WatchConfig for testing
 	nsStartupRetry      *retry.Retry
+	nodeAudit           audit.Manager
+	nodeAuditDB         database.Plugin
 
 	orchestratorFactory  func(ns *core.Namespace, config orchestrator.Config, plugins *orchestrator.Plugins, metrics metrics.Manager, cacheManager cache.Manager) orchestrator.Orchestrator
 	blockchainFactory    func(ctx context.Context, pluginType string) (blockchain.Plugin, error)
@@ -198,11 +214,18 @@ func (nm *namespaceManager) Init(ctx context.Context, cancelCtx context.CancelFu
 	if nm.plugins, err = nm.loadPlugins(ctx, initTimeRawConfig); err != nil {
 		return err
 	}
+	if _, err = nm.globalAuthPlugin(ctx, nm.plugins); err != nil {
+		return err
+	}
 	if nm.namespaces, err = nm.loadNamespaces(ctx, initTimeRawConfig, nm.plugins); err != nil {
 		return err
 	}
 
-	return nm.initComponents()
+	if err = nm.initComponents(); err != nil {
+		return err
+	}
+
+	return nm.loadDynamicNamespaces(ctx)
 }
 
 func (nm *namespaceManager) initComponents() (err error) {
@@ -254,6 +277,7 @@ func (nm *namespaceManager) startV1NamespaceIfRequired(nsToCheck *namespace) err
 		}
 		systemNS.Name = core.LegacySystemNamespace
 		systemNS.NetworkName = core.LegacySystemNamespace
+		systemNS.Definition = nil
 
 		// Start the namespace synchronously, while holding the nsMux (but without retry), so that
 		// all namespaces can do the above ^^^ check ok
@@ -396,6 +420,10 @@ func (nm *namespaceManager) Start() error {
 
 func (nm *namespaceManager) startNamespacesAndPlugins(namespacesToStart map[string]*namespace, pluginsToStart map[string]*plugin) error {
 	for _, ns := range namespacesToStart {
+		if ns.stopped {
+			log.L(nm.ctx).Infof("Not starting namespace '%s' as it has been stopped", ns.Name)
+			continue
+		}
 		// Orchestrators must all be initialized to the point they register their
 		// callbacks on the plugins, before we start the plugins.
 		//
@@ -789,6 +817,57 @@ func (nm *namespaceManager) loadTLSConfig(ctx context.Context, tlsConfigs map[st
 	return nil
 }
 
+func (nm *namespaceManager) loadOperationsConfig(ctx context.Context, conf config.Section) (opsConfig operations.Config, err error) {
+	opsConfig.CheckInterval = conf.GetDuration(coreconfig.NamespaceOperationsCheckInterval)
+	opsConfig.Policies = make(map[core.OpType]*operations.Policy)
+	policiesConf := conf.SubArray(coreconfig.NamespaceOperationsPolicies)
+	policiesConfArraySize := policiesConf.ArraySize()
+	for i := 0; i < policiesConfArraySize; i++ {
+		entry := policiesConf.ArrayEntry(i)
+		opType, err := fftypes.FFEnumParseString(ctx, "optype", entry.GetString(coreconfig.NamespaceOperationsPolicyType))
+		if err != nil {
+			return opsConfig, i18n.NewError(ctx, coremsgs.MsgOperationPolicyInvalidType, entry.GetString(coreconfig.NamespaceOperationsPolicyType))
+		}
+		if opsConfig.Policies[opType] != nil {
+			return opsConfig, i18n.NewError(ctx, coremsgs.MsgOperationPolicyDuplicateType, opType)
+		}
+
+		policy := &operations.Policy{
+			Timeout:       entry.GetDuration(coreconfig.NamespaceOperationsPolicyTimeout),
+			TimeoutAction: entry.GetString(coreconfig.NamespaceOperationsPolicyTimeoutAction),
+			Retry: operations.RetryPolicy{
+				MaxAttempts:  entry.GetInt(coreconfig.NamespaceOperationsPolicyRetryMaxAttempts),
+				InitialDelay: entry.GetDuration(coreconfig.NamespaceOperationsPolicyRetryInitialDelay),
+				MaxDelay:     entry.GetDuration(coreconfig.NamespaceOperationsPolicyRetryMaxDelay),
+				Factor:       entry.GetFloat64(coreconfig.NamespaceOperationsPolicyRetryFactor),
+			},
+		}
+		switch policy.TimeoutAction {
+		case operations.TimeoutActionFail:
+			// Retrying a blockchain operation that was only failed by the timeout could submit the
+			// transaction twice, if the original transaction is still mined
+			if policy.Timeout > 0 && policy.Retry.MaxAttempts > 0 && (&core.Operation{Type: opType}).IsBlockchainOperation() {
+				return opsConfig, i18n.NewError(ctx, coremsgs.MsgOperationPolicyFailRetryBlockchain, opType)
+			}
+		case operations.TimeoutActionQuery:
+			if !(&core.Operation{Type: opType}).IsBlockchainOperation() {
+				return opsConfig, i18n.NewError(ctx, coremsgs.MsgOperationPolicyQueryNotBlockchain, opType)
+			}
+		default:
+			return opsConfig, i18n.NewError(ctx, coremsgs.MsgOperationPolicyInvalidTimeoutAction, policy.TimeoutAction, opType)
+		}
+		for _, pattern := range entry.GetStringSlice(coreconfig.NamespaceOperationsPolicyRetryErrors) {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return opsConfig, i18n.NewError(ctx, coremsgs.MsgOperationPolicyInvalidRetryError, pattern, opType, err)
+			}
+			policy.Retry.Errors = append(policy.Retry.Errors, re)
+		}
+		opsConfig.Policies[opType] = policy
+	}
+	return opsConfig, nil
+}
+
 // nolint: gocyclo
 func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, index int, conf config.Section, rawNSConfig fftypes.JSONObject, availablePlugins map[string]*plugin) (ns *namespace, err error) {
 	if err := fftypes.ValidateFFNameField(ctx, name, fmt.Sprintf("namespaces.predefined[%d].name", index)); err != nil {
@@ -881,11 +960,23 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 		return nil, err
 	}
 
+	opsConfig, err := nm.loadOperationsConfig(ctx, conf.SubSection(coreconfig.NamespaceOperations))
+	if err != nil {
+		return nil, err
+	}
+
 	config := orchestrator.Config{
 		DefaultKey:                  conf.GetString(coreconfig.NamespaceDefaultKey),
 		TokenBroadcastNames:         nm.tokenBroadcastNames,
 		KeyNormalization:            keyNormalization,
 		MaxHistoricalEventScanLimit: config.GetInt(coreconfig.SubscriptionMaxHistoricalEventScanLength),
+		Retention: retention.Config{
+			MaxAge:           conf.GetDuration(coreconfig.NamespaceRetentionMaxAge),
+			Interval:         conf.GetDuration(coreconfig.NamespaceRetentionInterval),
+			BatchSize:        conf.GetInt(coreconfig.NamespaceRetentionBatchSize),
+			ArchiveDirectory: conf.GetString(coreconfig.NamespaceRetentionArchiveDirectory),
+		},
+		Operations: opsConfig,
 	}
 	if multipartyEnabled.(bool) {
 		contractsConf := multipartyConf.SubArray(coreconfig.NamespaceMultipartyContract)
@@ -927,9 +1018,16 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 	}
 	log.L(ctx).Tracef("Namespace %s config: %s", name, rawNSConfig.String())
 
-	if ns.plugins, err = nm.validateNSPlugins(ctx, ns, availablePlugins); err != nil {
+	if err = nm.validateNamespace(ctx, ns, availablePlugins); err != nil {
 		return nil, err
 	}
+	return ns, nil
+}
+
+func (nm *namespaceManager) validateNamespace(ctx context.Context, ns *namespace, availablePlugins map[string]*plugin) (err error) {
+	if ns.plugins, err = nm.validateNSPlugins(ctx, ns, availablePlugins); err != nil {
+		return err
+	}
 
 	if ns.config.Multiparty.Enabled {
 		err = nm.validateMultiPartyConfig(ctx, ns)
@@ -937,7 +1035,7 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 		err = nm.validateNonMultipartyConfig(ctx, ns)
 	}
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	ns.plugins.Events = make(map[string]events.Plugin)
@@ -947,7 +1045,7 @@ func (nm *namespaceManager) loadNamespace(ctx context.Context, name string, inde
 		}
 	}
 
-	return ns, nil
+	return nil
 }
 
 func (nm *namespaceManager) validateNSPlugins(ctx context.Context, ns *namespace, availablePlugins map[string]*plugin) (*orchestrator.Plugins, error) {
@@ -1046,6 +1144,9 @@ func (nm *namespaceManager) Orchestrator(ctx context.Context, ns string, include
 	defer nm.nsMux.Unlock()
 	// Only return started namespaces from this call
 	if namespace, ok := nm.namespaces[ns]; ok && namespace != nil {
+		if namespace.stopped {
+			return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceStopped, ns)
+		}
 		if !includeInitializing && !namespace.started {
 			return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceInitializing, ns)
 		}
@@ -1071,14 +1172,74 @@ func (nm *namespaceManager) GetNamespaces(ctx context.Context, includeInitializi
 		if includeInitializing || ns.started {
 			results = append(results, &core.NamespaceWithInitStatus{
 				Namespace:           &ns.Namespace,
-				Initializing:        !ns.started,
+				Initializing:        !ns.started && !ns.stopped,
 				InitializationError: ns.initError,
+				Stopped:             ns.stopped,
 			})
 		}
 	}
 	return results, nil
 }
 
+// GetReadiness reports the node as ready only when every namespace that has not been stopped has
+// finished initializing, and all of its plugins pass their health probes
+func (nm *namespaceManager) GetReadiness(ctx context.Context) (*core.NodeReadiness, error) {
+	nm.nsMux.Lock()
+	readiness := &core.NodeReadiness{
+		Ready:      true,
+		Namespaces: make([]*core.NamespaceReadiness, 0, len(nm.namespaces)),
+	}
+	orchestrators := make(map[*core.NamespaceReadiness]orchestrator.Orchestrator)
+	for _, ns := range nm.namespaces {
+		if ns.stopped {
+			continue
+		}
+		nsReadiness := &core.NamespaceReadiness{
+			Name:                ns.Name,
+			Initializing:        !ns.started,
+			InitializationError: ns.initError,
+		}
+		if ns.started {
+			orchestrators[nsReadiness] = ns.orchestrator
+		}
+		readiness.Namespaces = append(readiness.Namespaces, nsReadiness)
+	}
+	nm.nsMux.Unlock()
+
+	var wg sync.WaitGroup
+	for nsReadiness, or := range orchestrators {
+		wg.Add(1)
+		go func(nsReadiness *core.NamespaceReadiness, or orchestrator.Orchestrator) {
+			defer wg.Done()
+			nsReadiness.UnhealthyPlugins = unhealthyPlugins(or.GetPluginHealth(ctx))
+		}(nsReadiness, or)
+	}
+	wg.Wait()
+
+	for _, nsReadiness := range readiness.Namespaces {
+		nsReadiness.Ready = !nsReadiness.Initializing && len(nsReadiness.UnhealthyPlugins) == 0
+		readiness.Ready = readiness.Ready && nsReadiness.Ready
+	}
+	sort.Slice(readiness.Namespaces, func(i, j int) bool {
+		return readiness.Namespaces[i].Name < readiness.Namespaces[j].Name
+	})
+	return readiness, nil
+}
+
+func unhealthyPlugins(plugins core.NamespaceStatusPlugins) []string {
+	var unhealthy []string
+	for _, list := range [][]*core.NamespaceStatusPlugin{
+		plugins.Blockchain, plugins.Database, plugins.DataExchange, plugins.SharedStorage, plugins.Tokens,
+	} {
+		for _, p := range list {
+			if p.Health != nil && !p.Health.Healthy {
+				unhealthy = append(unhealthy, p.Name)
+			}
+		}
+	}
+	return unhealthy
+}
+
 func (nm *namespaceManager) GetOperationByNamespacedID(ctx context.Context, nsOpID string) (*core.Operation, error) {
 	ns, u, err := core.ParseNamespacedOpID(ctx, nsOpID)
 	if err != nil {
@@ -1162,3 +1323,62 @@ func (nm *namespaceManager) Authorize(ctx context.Context, authReq *fftypes.Auth
 	}
 	return or.Authorize(ctx, authReq)
 }
+
+// AuthorizeGlobal authorizes an admin request that is not in a namespace, with the auth plugin
+// set in spi.globalAuthPlugin. Requests are allowed if no plugin is set.
+func (nm *namespaceManager) AuthorizeGlobal(ctx context.Context, authReq *fftypes.AuthReq) error {
+	nm.nsMux.Lock()
+	p, err := nm.globalAuthPlugin(ctx, nm.plugins)
+	nm.nsMux.Unlock()
+	if err != nil || p == nil {
+		return err
+	}
+	return p.Authorize(ctx, authReq)
+}
+
+func (nm *namespaceManager) globalAuthPlugin(ctx context.Context, plugins map[string]*plugin) (auth.Plugin, error) {
+	name := config.GetString(coreconfig.SPIGlobalAuthPlugin)
+	if name == "" {
+		return nil, nil
+	}
+	p := plugins[name]
+	if p == nil || p.category != pluginCategoryAuth {
+		return nil, i18n.NewError(ctx, coremsgs.MsgSPIGlobalAuthPluginNotFound, name)
+	}
+	return p.auth, nil
+}
+
+// NodeAudit returns the audit manager for the node-level chain, which records the admin requests that
+// are not made against a namespace. The chain is stored under the reserved system namespace name, in
+// the database plugin of the default namespace (or the first database plugin by name, if the default
+// namespace does not exist).
+func (nm *namespaceManager) NodeAudit(ctx context.Context) (audit.Manager, error) {
+	nm.nsMux.Lock()
+	defer nm.nsMux.Unlock()
+	var db database.Plugin
+	if ns := nm.namespaces[config.GetString(coreconfig.NamespacesDefault)]; ns != nil && ns.plugins != nil {
+		db = ns.plugins.Database.Plugin
+	}
+	if db == nil {
+		names := make([]string, 0)
+		for name, p := range nm.plugins {
+			if p.category == pluginCategoryDatabase {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			db = nm.plugins[names[0]].database
+		}
+	}
+	// Keep the same manager while the database is unchanged, so writes to the chain stay serialized
+	if nm.nodeAudit == nil || nm.nodeAuditDB != db {
+		am, err := audit.NewAuditManager(ctx, core.LegacySystemNamespace, db)
+		if err != nil {
+			return nil, err
+		}
+		nm.nodeAudit = am
+		nm.nodeAuditDB = db
+	}
+	return nm.nodeAudit, nil
+}
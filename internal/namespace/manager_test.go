@@ -39,6 +39,8 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/retry"
+	"github.com/hyperledger/firefly/internal/auth/jwt"
+	"github.com/hyperledger/firefly/internal/auth/rbac"
 	"github.com/hyperledger/firefly/internal/blockchain/bifactory"
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
@@ -158,6 +160,7 @@ func (nmm *nmMocks) cleanup(t *testing.T) {
 	nmm.mei[0].AssertExpectations(t)
 	nmm.mei[1].AssertExpectations(t)
 	nmm.mei[2].AssertExpectations(t)
+	nmm.mei[3].AssertExpectations(t)
 	nmm.mo.AssertExpectations(t)
 }
 
@@ -177,7 +180,7 @@ func mockPluginFactories(inm Manager) (nmm *nmMocks) {
 		mdx: &dataexchangemocks.Plugin{},
 		mps: &sharedstoragemocks.Plugin{},
 		mti: []*tokenmocks.Plugin{{}, {}},
-		mei: []*eventsmocks.Plugin{{}, {}, {}},
+		mei: []*eventsmocks.Plugin{{}, {}, {}, {}},
 		mai: &authmocks.Plugin{},
 		mii: &identitymocks.Plugin{},
 		mo:  &orchestratormocks.Orchestrator{},
@@ -191,6 +194,7 @@ func mockPluginFactories(inm Manager) (nmm *nmMocks) {
 	factoryMocks(&nmm.mei[0].Mock, "system")
 	factoryMocks(&nmm.mei[1].Mock, "websockets")
 	factoryMocks(&nmm.mei[2].Mock, "webhooks")
+	factoryMocks(&nmm.mei[3].Mock, "grpc")
 	factoryMocks(&nmm.mai.Mock, "basicauth")
 
 	nm.orchestratorFactory = func(ns *core.Namespace, config orchestrator.Config, plugins *orchestrator.Plugins, metrics metrics.Manager, cacheManager cache.Manager) orchestrator.Orchestrator {
@@ -225,6 +229,8 @@ func mockPluginFactories(inm Manager) (nmm *nmMocks) {
 			return nmm.mei[1], nil
 		case "webhooks":
 			return nmm.mei[2], nil
+		case "grpc":
+			return nmm.mei[3], nil
 		default:
 			panic(fmt.Errorf("Add plugin type %s to test", pluginType))
 		}
@@ -280,7 +286,9 @@ func newTestNamespaceManager(t *testing.T, initConfig bool) (*namespaceManager,
 		nmm.mei[0].On("Init", mock.Anything, mock.Anything).Return(nil)
 		nmm.mei[1].On("Init", mock.Anything, mock.Anything).Return(nil)
 		nmm.mei[2].On("Init", mock.Anything, mock.Anything).Return(nil)
+		nmm.mei[3].On("Init", mock.Anything, mock.Anything).Return(nil)
 		nmm.mai.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+		nmm.mdi.On("GetDynamicNamespaces", mock.Anything).Return([]*core.Namespace{}, nil).Once()
 
 		err = nmm.nm.Init(nmm.nm.ctx, nmm.nm.cancelCtx, nmm.nm.reset, nmm.nm.reloadConfig)
 		assert.NoError(t, err)
@@ -308,11 +316,12 @@ func TestInitEmpty(t *testing.T) {
 	nmm.mei[0].On("Init", mock.Anything, mock.Anything).Return(nil)
 	nmm.mei[1].On("Init", mock.Anything, mock.Anything).Return(nil)
 	nmm.mei[2].On("Init", mock.Anything, mock.Anything).Return(nil)
+	nmm.mei[3].On("Init", mock.Anything, mock.Anything).Return(nil)
 
 	err := nm.Init(nm.ctx, nm.cancelCtx, nm.reset, nm.reloadConfig)
 	assert.NoError(t, err)
 
-	assert.Len(t, nm.plugins, 3) // events
+	assert.Len(t, nm.plugins, 4) // events
 	assert.Empty(t, nm.namespaces)
 }
 
@@ -1046,7 +1055,7 @@ func TestEventsPluginDefaults(t *testing.T) {
 	defer cleanup()
 	plugins := make(map[string]*plugin)
 	err := nm.getEventPlugins(context.Background(), plugins, nm.dumpRootConfig())
-	assert.Equal(t, 3, len(plugins))
+	assert.Equal(t, 4, len(plugins))
 	assert.NoError(t, err)
 }
 
@@ -1075,6 +1084,46 @@ func TestAuthPlugin(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestAuthPluginRBAC(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	coreconfig.Reset()
+	InitConfig()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+plugins:
+  auth:
+  - name: rbacauth
+    type: rbac
+`))
+	assert.NoError(t, err)
+	nm.authFactory = authfactory.GetPlugin
+	plugins := make(map[string]*plugin)
+	err = nm.getAuthPlugin(context.Background(), plugins, nm.dumpRootConfig())
+	assert.NoError(t, err)
+	assert.IsType(t, &rbac.Auth{}, plugins["rbacauth"].auth)
+}
+
+func TestAuthPluginJWT(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	coreconfig.Reset()
+	InitConfig()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+plugins:
+  auth:
+  - name: jwtauth
+    type: jwt
+`))
+	assert.NoError(t, err)
+	nm.authFactory = authfactory.GetPlugin
+	plugins := make(map[string]*plugin)
+	err = nm.getAuthPlugin(context.Background(), plugins, nm.dumpRootConfig())
+	assert.NoError(t, err)
+	assert.IsType(t, &jwt.Auth{}, plugins["jwtauth"].auth)
+}
+
 func TestAuthPluginBadType(t *testing.T) {
 	nm, _, cleanup := newTestNamespaceManager(t, false)
 	defer cleanup()
@@ -1208,6 +1257,110 @@ func TestLoadNamespacesNetworkName(t *testing.T) {
 	assert.Equal(t, "default", newNS["ns1"].NetworkName)
 }
 
+func TestLoadNamespacesRetention(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	coreconfig.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      retention:
+        maxAge: 720h
+        archiveDirectory: /data/archive
+    `))
+	assert.NoError(t, err)
+
+	newNS, err := nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+	assert.NoError(t, err)
+
+	retention := newNS["ns1"].config.Retention
+	assert.Equal(t, 720*time.Hour, retention.MaxAge)
+	assert.Equal(t, time.Hour, retention.Interval)
+	assert.Equal(t, 1000, retention.BatchSize)
+	assert.Equal(t, "/data/archive", retention.ArchiveDirectory)
+}
+
+func TestLoadNamespacesOperationPolicies(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	coreconfig.Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      operations:
+        checkInterval: 10s
+        policies:
+        - type: blockchain_invoke
+          timeout: 5m
+          timeoutAction: query
+          retry:
+            maxAttempts: 3
+            errors: ["(?i)timeout", "nonce too low"]
+        - type: dataexchange_send_batch
+          timeout: 1h
+    `))
+	assert.NoError(t, err)
+
+	newNS, err := nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+	assert.NoError(t, err)
+
+	ops := newNS["ns1"].config.Operations
+	assert.Equal(t, 10*time.Second, ops.CheckInterval)
+	assert.Len(t, ops.Policies, 2)
+	invoke := ops.Policies[core.OpTypeBlockchainInvoke]
+	assert.Equal(t, 5*time.Minute, invoke.Timeout)
+	assert.Equal(t, "query", invoke.TimeoutAction)
+	assert.Equal(t, 3, invoke.Retry.MaxAttempts)
+	assert.Equal(t, 10*time.Second, invoke.Retry.InitialDelay)
+	assert.Equal(t, 5*time.Minute, invoke.Retry.MaxDelay)
+	assert.Equal(t, 2.0, invoke.Retry.Factor)
+	assert.Len(t, invoke.Retry.Errors, 2)
+	dx := ops.Policies[core.OpTypeDataExchangeSendBatch]
+	assert.Equal(t, time.Hour, dx.Timeout)
+	assert.Equal(t, "fail", dx.TimeoutAction)
+	assert.Zero(t, dx.Retry.MaxAttempts)
+}
+
+func TestLoadNamespacesOperationPolicyErrors(t *testing.T) {
+	for _, tc := range []struct {
+		policy string
+		err    string
+	}{
+		{`[{type: wrong}]`, "FF10517"},
+		{`[{type: token_transfer}, {type: token_transfer}]`, "FF10518"},
+		{`[{type: token_transfer, timeoutAction: wrong}]`, "FF10519"},
+		{`[{type: token_transfer, timeoutAction: query}]`, "FF10520"},
+		{`[{type: token_transfer, retry: {errors: ["["]}}]`, "FF10521"},
+		{`[{type: blockchain_invoke, timeout: 1m, retry: {maxAttempts: 1}}]`, "FF10562"},
+	} {
+		nm, _, cleanup := newTestNamespaceManager(t, true)
+
+		coreconfig.Reset()
+		viper.SetConfigType("yaml")
+		err := viper.ReadConfig(strings.NewReader(`
+  namespaces:
+    default: ns1
+    predefined:
+    - name: ns1
+      operations:
+        policies: ` + tc.policy + `
+    `))
+		assert.NoError(t, err)
+
+		_, err = nm.loadNamespaces(context.Background(), nm.dumpRootConfig(), nm.plugins)
+		assert.Regexp(t, tc.err, err)
+		cleanup()
+	}
+}
+
 func TestLoadNamespacesReservedNetworkName(t *testing.T) {
 	nm, _, cleanup := newTestNamespaceManager(t, true)
 	defer cleanup()
@@ -1983,6 +2136,65 @@ func TestGetNamespaces(t *testing.T) {
 	assert.Len(t, results, 1)
 }
 
+func TestGetReadiness(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	mo1 := &orchestratormocks.Orchestrator{}
+	mo2 := &orchestratormocks.Orchestrator{}
+	nm.namespaces = map[string]*namespace{
+		"ns1": {Namespace: core.Namespace{Name: "ns1"}, orchestrator: mo1, started: true},
+		"ns2": {Namespace: core.Namespace{Name: "ns2"}, orchestrator: mo2, started: true},
+		"ns3": {Namespace: core.Namespace{Name: "ns3"}, initError: "pop"},
+		"ns4": {Namespace: core.Namespace{Name: "ns4"}, stopped: true},
+	}
+
+	mo1.On("GetPluginHealth", mock.Anything).Return(core.NamespaceStatusPlugins{
+		Database: []*core.NamespaceStatusPlugin{
+			{Name: "database0", Health: &core.PluginHealth{Healthy: true}},
+		},
+		Events: []*core.NamespaceStatusPlugin{{PluginType: "websockets"}},
+	})
+	mo2.On("GetPluginHealth", mock.Anything).Return(core.NamespaceStatusPlugins{
+		Blockchain: []*core.NamespaceStatusPlugin{
+			{Name: "ethereum", Health: &core.PluginHealth{Healthy: false}},
+		},
+		Tokens: []*core.NamespaceStatusPlugin{
+			{Name: "erc1155", Health: &core.PluginHealth{Healthy: false}},
+		},
+	})
+
+	readiness, err := nm.GetReadiness(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, []*core.NamespaceReadiness{
+		{Name: "ns1", Ready: true},
+		{Name: "ns2", UnhealthyPlugins: []string{"ethereum", "erc1155"}},
+		{Name: "ns3", Initializing: true, InitializationError: "pop"},
+	}, readiness.Namespaces)
+
+	mo1.AssertExpectations(t)
+	mo2.AssertExpectations(t)
+}
+
+func TestGetReadinessAllReady(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	mo := &orchestratormocks.Orchestrator{}
+	nm.namespaces = map[string]*namespace{
+		"ns1": {Namespace: core.Namespace{Name: "ns1"}, orchestrator: mo, started: true},
+	}
+	mo.On("GetPluginHealth", mock.Anything).Return(core.NamespaceStatusPlugins{})
+
+	readiness, err := nm.GetReadiness(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, readiness.Ready)
+	assert.Len(t, readiness.Namespaces, 1)
+
+	mo.AssertExpectations(t)
+}
+
 func TestGetOperationByNamespacedID(t *testing.T) {
 	nm, _, cleanup := newTestNamespaceManager(t, true)
 	defer cleanup()
@@ -2109,6 +2321,74 @@ func TestAuthorizeBadNamespace(t *testing.T) {
 	assert.Regexp(t, "FF10436", err)
 }
 
+func TestAuthorizeGlobal(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+	config.Set(coreconfig.SPIGlobalAuthPlugin, "basicauth")
+	authReq := &fftypes.AuthReq{}
+	nmm.mai.On("Authorize", mock.Anything, authReq).Return(nil)
+	err := nm.AuthorizeGlobal(context.Background(), authReq)
+	assert.NoError(t, err)
+}
+
+func TestAuthorizeGlobalNotSet(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+	err := nm.AuthorizeGlobal(context.Background(), &fftypes.AuthReq{})
+	assert.NoError(t, err)
+}
+
+func TestAuthorizeGlobalNotAuthPlugin(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+	config.Set(coreconfig.SPIGlobalAuthPlugin, "postgres")
+	err := nm.AuthorizeGlobal(context.Background(), &fftypes.AuthReq{})
+	assert.Regexp(t, "FF10564.*postgres", err)
+}
+
+func TestInitGlobalAuthPluginNotFound(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(testBaseConfig))
+	assert.NoError(t, err)
+	config.Set(coreconfig.SPIGlobalAuthPlugin, "wrong")
+	err = nm.Init(nm.ctx, nm.cancelCtx, nm.reset, nm.reloadConfig)
+	assert.Regexp(t, "FF10564.*wrong", err)
+}
+
+func TestNodeAudit(t *testing.T) {
+	nm, nmm, cleanup := newTestNamespaceManager(t, true)
+	defer cleanup()
+
+	// Falls back to the first database plugin when there is no default namespace
+	nm.namespaces = map[string]*namespace{}
+	am, err := nm.NodeAudit(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, nmm.mdi, nm.nodeAuditDB)
+	am2, err := nm.NodeAudit(context.Background())
+	assert.NoError(t, err)
+	assert.Same(t, am, am2)
+
+	// Moves to the database of the default namespace
+	mdi2 := &databasemocks.Plugin{}
+	config.Set(coreconfig.NamespacesDefault, "default")
+	nm.namespaces["default"] = &namespace{
+		plugins: &orchestrator.Plugins{Database: orchestrator.DatabasePlugin{Plugin: mdi2}},
+	}
+	am3, err := nm.NodeAudit(context.Background())
+	assert.NoError(t, err)
+	assert.NotSame(t, am, am3)
+	assert.Equal(t, mdi2, nm.nodeAuditDB)
+}
+
+func TestNodeAuditNoDatabase(t *testing.T) {
+	nm, _, cleanup := newTestNamespaceManager(t, false)
+	defer cleanup()
+	_, err := nm.NodeAudit(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
 func TestValidateNonMultipartyConfig(t *testing.T) {
 	nm, _, cleanup := newTestNamespaceManager(t, true)
 	defer cleanup()
@@ -48,6 +48,18 @@ func (nm *networkMap) RegisterNode(ctx context.Context, waitConfirm bool) (ident
 	if err != nil {
 		return nil, err
 	}
+	// Advertise the compression types this node can read, so peers know they can send it compressed batches
+	nodeRequest.Profile[core.CompressionProfileKey] = core.SupportedCompressionTypes()
+
+	// Registering a node that is already registered updates its profile. This is how a node registered
+	// by an earlier version starts to advertise the compression types it can read.
+	existing, err := nm.identity.GetLocalNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nm.updateIdentityID(ctx, existing.ID, &core.IdentityUpdateDTO{IdentityProfile: nodeRequest.IdentityProfile}, waitConfirm)
+	}
 
 	return nm.RegisterIdentity(ctx, nodeRequest, waitConfirm)
 }
@@ -41,6 +41,7 @@ func TestRegisterNodeOk(t *testing.T) {
 
 	mim := nm.identity.(*identitymanagermocks.Manager)
 	mim.On("GetRootOrg", nm.ctx).Return(parentOrg, nil)
+	mim.On("GetLocalNode", nm.ctx).Return(nil, nil)
 	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*core.Identity")).Return(parentOrg, false, nil)
 	mim.On("ResolveIdentitySigner", nm.ctx, parentOrg).Return(signerRef, nil)
 
@@ -63,6 +64,7 @@ func TestRegisterNodeOk(t *testing.T) {
 	node, err := nm.RegisterNode(nm.ctx, false)
 	assert.NoError(t, err)
 	assert.NotNil(t, node)
+	assert.Equal(t, core.SupportedCompressionTypes(), node.Profile[core.CompressionProfileKey])
 
 	mim.AssertExpectations(t)
 	mdx.AssertExpectations(t)
@@ -70,6 +72,75 @@ func TestRegisterNodeOk(t *testing.T) {
 	mmp.AssertExpectations(t)
 }
 
+func TestRegisterNodeExistingUpdatesProfile(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	parentOrg := testOrg("org1")
+	signerRef := &core.SignerRef{Key: "0x23456"}
+	existing := &core.Identity{
+		IdentityBase: core.IdentityBase{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			DID:       "did:firefly:node/node1",
+			Name:      "node1",
+			Type:      core.IdentityTypeNode,
+			Parent:    parentOrg.ID,
+		},
+		IdentityProfile: core.IdentityProfile{
+			Profile: fftypes.JSONObject{"id": "peer1"},
+		},
+	}
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetRootOrg", nm.ctx).Return(parentOrg, nil)
+	mim.On("GetLocalNode", nm.ctx).Return(existing, nil)
+	mim.On("CachedIdentityLookupByID", nm.ctx, existing.ID).Return(existing, nil)
+	mim.On("ResolveIdentitySigner", nm.ctx, existing).Return(signerRef, nil)
+
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("GetEndpointInfo", nm.ctx, "node1").Return(fftypes.JSONObject{
+		"id":       "peer1",
+		"endpoint": "details",
+	}, nil)
+
+	mds := nm.defsender.(*definitionsmocks.Sender)
+	mds.On("UpdateIdentity", nm.ctx, existing, mock.MatchedBy(func(update *core.IdentityUpdate) bool {
+		return assert.Equal(t, core.SupportedCompressionTypes(), update.Updates.Profile[core.CompressionProfileKey])
+	}), signerRef, false).Return(nil)
+
+	mmp := nm.multiparty.(*multipartymocks.Manager)
+	mmp.On("LocalNode").Return(multiparty.LocalNode{Name: "node1"})
+
+	node, err := nm.RegisterNode(nm.ctx, false)
+	assert.NoError(t, err)
+	assert.Equal(t, existing.ID, node.ID)
+
+	mim.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+	mds.AssertExpectations(t)
+}
+
+func TestRegisterNodeGetLocalNodeFail(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetRootOrg", nm.ctx).Return(testOrg("org1"), nil)
+	mim.On("GetLocalNode", nm.ctx).Return(nil, fmt.Errorf("pop"))
+
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("GetEndpointInfo", nm.ctx, "node1").Return(fftypes.JSONObject{}, nil)
+
+	mmp := nm.multiparty.(*multipartymocks.Manager)
+	mmp.On("LocalNode").Return(multiparty.LocalNode{Name: "node1"})
+
+	_, err := nm.RegisterNode(nm.ctx, false)
+	assert.Regexp(t, "pop", err)
+}
+
 func TestRegisterNodeMissingName(t *testing.T) {
 
 	nm, cancel := newTestNetworkmap(t)
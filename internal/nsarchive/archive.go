@@ -0,0 +1,378 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nsarchive exports all of the records of a namespace, through the database plugin, to a portable
+// archive - and restores such an archive into an empty namespace, for example on a fresh database.
+//
+// The archive is a stream of JSON entries, one per line:
+//   - a header, containing the archive version and the namespace
+//   - the records of each collection, with every record of a collection contiguous in the stream
+//   - a summary, with the number of records and a hash of the records in each collection
+//
+// The hash of a collection is the sum (modulo 2^256) of the SHA-256 hashes of its records, so that it does
+// not depend on the order in which the records are read - and the restored records can be verified against
+// the database. Fields that are assigned by the database when a record is restored are excluded.
+//
+// Local database sequences are not preserved, but records that are processed in sequence order (such
+// as events and messages) are restored in their original order, and subscription offsets are mapped to
+// the new event sequences.
+package nsarchive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+const (
+	archiveVersion = 1
+	pageSize       = 100
+)
+
+type archiveHeader struct {
+	Version   int             `json:"version"`
+	Namespace string          `json:"namespace"`
+	Created   *fftypes.FFTime `json:"created"`
+}
+
+type archiveEntry struct {
+	Header     *archiveHeader                `json:"header,omitempty"`
+	Collection string                        `json:"collection,omitempty"`
+	Record     json.RawMessage               `json:"record,omitempty"`
+	Summary    *core.NamespaceArchiveSummary `json:"summary,omitempty"`
+}
+
+type eventSequence struct {
+	id       *fftypes.UUID
+	old, new int64
+}
+
+type archive struct {
+	namespace      string
+	database       database.Plugin
+	eventSequences []*eventSequence
+	eventsMapped   bool
+}
+
+type collectionHash struct {
+	count int64
+	sum   fftypes.Bytes32
+}
+
+func newCollectionHash() *collectionHash {
+	return &collectionHash{}
+}
+
+func (ch *collectionHash) add(record []byte) {
+	ch.count++
+	h := sha256.Sum256(record)
+	carry := 0
+	for i := len(h) - 1; i >= 0; i-- {
+		v := int(ch.sum[i]) + int(h[i]) + carry
+		ch.sum[i], carry = byte(v), v>>8
+	}
+}
+
+func (ch *collectionHash) summary(name string) *core.NamespaceArchiveCollection {
+	h := ch.sum
+	return &core.NamespaceArchiveCollection{Name: name, Count: ch.count, Hash: &h}
+}
+
+// page calls the supplied function with each page of records in the collection
+func (a *archive) page(ctx context.Context, c *collection, fn func(records []interface{}) error) error {
+	for skip := 0; ; skip += pageSize {
+		filter := c.queryFactory.NewFilter(ctx).And()
+		filter.Skip(uint64(skip)).Limit(pageSize)
+		if c.sort != "" {
+			filter.Sort(c.sort)
+		}
+		records, _, err := c.query(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if len(records) > 0 {
+			if err := fn(records); err != nil {
+				return err
+			}
+		}
+		if len(records) < pageSize {
+			return nil
+		}
+	}
+}
+
+// hashDatabase reads back every record of the collection from the database, and hashes it
+func (a *archive) hashDatabase(ctx context.Context, c *collection) (*collectionHash, error) {
+	ch := newCollectionHash()
+	err := a.page(ctx, c, func(records []interface{}) error {
+		for _, r := range records {
+			b, err := json.Marshal(r)
+			if err == nil {
+				b, err = c.hashable(ctx, b)
+			}
+			if err != nil {
+				return err
+			}
+			ch.add(b)
+		}
+		return nil
+	})
+	return ch, err
+}
+
+// Export writes every record in the namespace to the supplied writer. The namespace should not be
+// processing new work while the export is in progress.
+func Export(ctx context.Context, ns string, di database.Plugin, w io.Writer) error {
+	a := &archive{namespace: ns, database: di}
+	encoder := json.NewEncoder(w)
+	summary := &core.NamespaceArchiveSummary{
+		Namespace: ns,
+		Created:   fftypes.Now(),
+	}
+	if err := encoder.Encode(&archiveEntry{
+		Header: &archiveHeader{Version: archiveVersion, Namespace: ns, Created: summary.Created},
+	}); err != nil {
+		return err
+	}
+	for _, c := range a.collections() {
+		ch := newCollectionHash()
+		err := a.page(ctx, c, func(records []interface{}) error {
+			for _, r := range records {
+				b, err := json.Marshal(r)
+				var h []byte
+				if err == nil {
+					h, err = c.hashable(ctx, b)
+				}
+				if err == nil {
+					ch.add(h)
+					err = encoder.Encode(&archiveEntry{Collection: c.name, Record: b})
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		log.L(ctx).Infof("Exported %d %s records from namespace '%s'", ch.count, c.name, ns)
+		summary.Collections = append(summary.Collections, ch.summary(c.name))
+	}
+	return encoder.Encode(&archiveEntry{Summary: summary})
+}
+
+// Import restores an archive written by Export into the namespace, which must not contain any records.
+// Once all records are restored, the record counts and hashes in the archive summary are verified against
+// the archive content, and against the records read back from the database.
+//
+// The whole import runs in a single database transaction, so that a failed import leaves the namespace
+// empty, and can simply be retried.
+func Import(ctx context.Context, ns string, di database.Plugin, r io.Reader) (summary *core.NamespaceArchiveSummary, err error) {
+	a := &archive{namespace: ns, database: di}
+	err = di.RunAsGroup(ctx, func(ctx context.Context) (err error) {
+		summary, err = a.restore(ctx, r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Imported namespace '%s' from archive created %s", ns, summary.Created)
+	return summary, nil
+}
+
+func (a *archive) restore(ctx context.Context, r io.Reader) (*core.NamespaceArchiveSummary, error) {
+	collections := a.collections()
+	byName := make(map[string]*collection, len(collections))
+	for _, c := range collections {
+		if err := a.checkEmpty(ctx, c); err != nil {
+			return nil, err
+		}
+		byName[c.name] = c
+	}
+
+	decoder := json.NewDecoder(r)
+	entryIndex := 0
+	readEntry := func() (*archiveEntry, error) {
+		entryIndex++
+		var entry archiveEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveInvalid, entryIndex, err)
+		}
+		return &entry, nil
+	}
+
+	entry, err := readEntry()
+	if err != nil {
+		return nil, err
+	}
+	if entry.Header == nil || entry.Header.Version != archiveVersion {
+		return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveInvalid, entryIndex, "missing or unsupported header")
+	}
+	if entry.Header.Namespace != a.namespace {
+		return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveWrongNamespace, entry.Header.Namespace, a.namespace)
+	}
+
+	hashes := make(map[string]*collectionHash)
+	var current *collection
+	var batch []json.RawMessage
+	flush := func() (err error) {
+		if len(batch) > 0 && current.restore != nil {
+			err = current.restore(ctx, batch)
+		}
+		batch = nil
+		return err
+	}
+	for {
+		if entry, err = readEntry(); err != nil {
+			return nil, err
+		}
+		if entry.Summary != nil {
+			break
+		}
+		c := byName[entry.Collection]
+		if c == nil || entry.Record == nil {
+			return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveInvalid, entryIndex, "invalid record entry")
+		}
+		if c != current {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			if hashes[c.name] != nil {
+				return nil, i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveInvalid, entryIndex, "collection records are not contiguous")
+			}
+			current = c
+			hashes[c.name] = newCollectionHash()
+		}
+		h, err := c.hashable(ctx, entry.Record)
+		if err != nil {
+			return nil, err
+		}
+		hashes[c.name].add(h)
+		if batch = append(batch, entry.Record); len(batch) >= pageSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if err := a.verify(ctx, entry.Summary, collections, hashes); err != nil {
+		return nil, err
+	}
+	return entry.Summary, nil
+}
+
+func (a *archive) checkEmpty(ctx context.Context, c *collection) error {
+	filter := c.queryFactory.NewFilter(ctx).And()
+	filter.Limit(1)
+	records, _, err := c.query(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if len(records) > 0 {
+		return i18n.NewError(ctx, coremsgs.MsgNamespaceNotEmpty, a.namespace, c.name)
+	}
+	return nil
+}
+
+func (a *archive) verify(ctx context.Context, summary *core.NamespaceArchiveSummary, collections []*collection, hashes map[string]*collectionHash) error {
+	expected := make(map[string]*core.NamespaceArchiveCollection, len(summary.Collections))
+	for _, sc := range summary.Collections {
+		expected[sc.Name] = sc
+	}
+	for name := range hashes {
+		if expected[name] == nil {
+			return i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveVerifyFailed, name, nil, name)
+		}
+	}
+	for _, c := range collections {
+		sc := expected[c.name]
+		if sc == nil {
+			return i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveVerifyFailed, c.name, c.name, nil)
+		}
+		ch := hashes[c.name]
+		if ch == nil {
+			ch = newCollectionHash()
+		}
+		actual := ch.summary(c.name)
+		if actual.Count != sc.Count {
+			return i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveVerifyFailed, c.name+" record count", sc.Count, actual.Count)
+		}
+		if !actual.Hash.Equals(sc.Hash) {
+			return i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveVerifyFailed, c.name+" hash", sc.Hash, actual.Hash)
+		}
+		dbHash, err := a.hashDatabase(ctx, c)
+		if err != nil {
+			return err
+		}
+		restored := dbHash.summary(c.name)
+		if restored.Count != sc.Count {
+			return i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveVerifyFailed, c.name+" database count", sc.Count, restored.Count)
+		}
+		if !restored.Hash.Equals(sc.Hash) {
+			return i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveVerifyFailed, c.name+" database hash", sc.Hash, restored.Hash)
+		}
+	}
+	return nil
+}
+
+// mapEventSequence returns the new sequence of the last restored event, at or before the supplied
+// sequence in the original database - or -1 if no restored event precedes the supplied sequence
+func (a *archive) mapEventSequence(ctx context.Context, old int64) (int64, error) {
+	if !a.eventsMapped {
+		var events *collection
+		for _, c := range a.collections() {
+			if c.name == "events" {
+				events = c
+			}
+		}
+		i := 0
+		err := a.page(ctx, events, func(records []interface{}) error {
+			for _, r := range records {
+				event := r.(*core.Event)
+				if i >= len(a.eventSequences) || !a.eventSequences[i].id.Equals(event.ID) {
+					return i18n.NewError(ctx, coremsgs.MsgNamespaceArchiveVerifyFailed, "events sequence", i, event.ID)
+				}
+				a.eventSequences[i].new = event.Sequence
+				i++
+			}
+			return nil
+		})
+		if err != nil {
+			return -1, err
+		}
+		a.eventsMapped = true
+	}
+	i := sort.Search(len(a.eventSequences), func(i int) bool {
+		return a.eventSequences[i].old > old
+	})
+	if i == 0 {
+		return -1, nil
+	}
+	return a.eventSequences[i-1].new, nil
+}
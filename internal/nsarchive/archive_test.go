@@ -0,0 +1,576 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsarchive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testRecords struct {
+	subID   *fftypes.UUID
+	eventID *fftypes.UUID
+}
+
+func newTestDB() *databasemocks.Plugin {
+	mdi := &databasemocks.Plugin{}
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	return mdi
+}
+
+func mockEmptyNamespace(mdi *databasemocks.Plugin) {
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{}, nil, nil).Once()
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFI{}, nil, nil).Once()
+	mdi.On("GetFFIMethods", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIMethod{}, nil, nil).Once()
+	mdi.On("GetFFIEvents", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIEvent{}, nil, nil).Once()
+	mdi.On("GetFFIErrors", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIError{}, nil, nil).Once()
+	mdi.On("GetContractAPIs", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractAPI{}, nil, nil).Once()
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{}, nil, nil).Once()
+	mdi.On("GetIdentities", mock.Anything, "ns1", mock.Anything).Return([]*core.Identity{}, nil, nil).Once()
+	mdi.On("GetVerifiers", mock.Anything, "ns1", mock.Anything).Return([]*core.Verifier{}, nil, nil).Once()
+	mdi.On("GetGroups", mock.Anything, "ns1", mock.Anything).Return([]*core.Group{}, nil, nil).Once()
+	mdi.On("GetTransactions", mock.Anything, "ns1", mock.Anything).Return([]*core.Transaction{}, nil, nil).Once()
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{}, nil, nil).Once()
+	mdi.On("GetBlobs", mock.Anything, "ns1", mock.Anything).Return([]*core.Blob{}, nil, nil).Once()
+	mdi.On("GetData", mock.Anything, "ns1", mock.Anything).Return(core.DataArray{}, nil, nil).Once()
+	mdi.On("GetBatches", mock.Anything, "ns1", mock.Anything).Return([]*core.BatchPersisted{}, nil, nil).Once()
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return([]*core.Message{}, nil, nil).Once()
+	mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{}, nil, nil).Once()
+	mdi.On("GetNextPins", mock.Anything, "ns1", mock.Anything).Return([]*core.NextPin{}, nil, nil).Once()
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.BlockchainEvent{}, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.Event{}, nil, nil).Once()
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{}, nil, nil).Twice()
+	mdi.On("GetTokenPools", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenPool{}, nil, nil).Once()
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenTransfer{}, nil, nil).Once()
+	mdi.On("GetTokenApprovals", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenApproval{}, nil, nil).Once()
+	mdi.On("GetTokenBalances", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenBalance{}, nil, nil).Once()
+}
+
+func mockPopulatedNamespace(mdi *databasemocks.Plugin, tr *testRecords, eventSequence int64) {
+	one := int64(1)
+	res := &ffapi.FilterResult{TotalCount: &one}
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{{Name: "dt1"}}, res, nil)
+	mdi.On("GetFFIs", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFI{{Name: "ffi1"}}, res, nil)
+	mdi.On("GetFFIMethods", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIMethod{{Name: "method1"}}, res, nil)
+	mdi.On("GetFFIEvents", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIEvent{{Pathname: "event1"}}, res, nil)
+	mdi.On("GetFFIErrors", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.FFIError{{Pathname: "error1"}}, res, nil)
+	mdi.On("GetContractAPIs", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractAPI{{Name: "api1"}}, res, nil)
+	mdi.On("GetContractListeners", mock.Anything, "ns1", mock.Anything).Return([]*core.ContractListener{{Name: "listener1"}}, res, nil)
+	mdi.On("GetIdentities", mock.Anything, "ns1", mock.Anything).Return([]*core.Identity{{}}, res, nil)
+	mdi.On("GetVerifiers", mock.Anything, "ns1", mock.Anything).Return([]*core.Verifier{{}}, res, nil)
+	mdi.On("GetGroups", mock.Anything, "ns1", mock.Anything).Return([]*core.Group{{}}, res, nil)
+	mdi.On("GetTransactions", mock.Anything, "ns1", mock.Anything).Return([]*core.Transaction{{}}, res, nil)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{{}}, res, nil)
+	mdi.On("GetBlobs", mock.Anything, "ns1", mock.Anything).Return([]*core.Blob{{}}, res, nil)
+	mdi.On("GetData", mock.Anything, "ns1", mock.Anything).Return(core.DataArray{{}}, res, nil)
+	mdi.On("GetBatches", mock.Anything, "ns1", mock.Anything).Return([]*core.BatchPersisted{{}}, res, nil)
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return([]*core.Message{{}}, res, nil)
+	mdi.On("GetPins", mock.Anything, "ns1", mock.Anything).Return([]*core.Pin{{}}, res, nil)
+	mdi.On("GetNextPins", mock.Anything, "ns1", mock.Anything).Return([]*core.NextPin{{}}, res, nil)
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.BlockchainEvent{{}}, res, nil)
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.Event{{ID: tr.eventID, Sequence: eventSequence}}, res, nil)
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{{SubscriptionRef: core.SubscriptionRef{ID: tr.subID}}}, res, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, tr.subID.String()).Return(&core.Offset{
+		Type: core.OffsetTypeSubscription, Name: tr.subID.String(), Current: eventSequence,
+	}, nil)
+	mdi.On("GetTokenPools", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenPool{{PluginData: "pd1"}}, res, nil)
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenTransfer{{}}, res, nil)
+	mdi.On("GetTokenApprovals", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenApproval{{}}, res, nil)
+	mdi.On("GetTokenBalances", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenBalance{{}}, res, nil)
+}
+
+func mockRestore(mdi *databasemocks.Plugin, err error) {
+	mdi.On("UpsertDatatype", mock.Anything, mock.Anything, false).Return(err).Maybe()
+	mdi.On("UpsertFFI", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(err).Maybe()
+	mdi.On("UpsertFFIMethod", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("UpsertFFIEvent", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("UpsertFFIError", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("UpsertContractAPI", mock.Anything, mock.Anything, database.UpsertOptimizationNew).Return(err).Maybe()
+	mdi.On("InsertContractListener", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("UpsertIdentity", mock.Anything, mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("UpsertVerifier", mock.Anything, mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("UpsertGroup", mock.Anything, mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("InsertTransactions", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("InsertOperations", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("InsertBlobs", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("InsertDataArray", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("InsertOrGetBatch", mock.Anything, mock.Anything).Return(nil, err).Maybe()
+	mdi.On("InsertMessages", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("InsertPins", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("InsertNextPin", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("InsertBlockchainEvents", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("InsertEvents", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("UpsertSubscription", mock.Anything, mock.Anything, false).Return(err).Maybe()
+	mdi.On("UpsertOffset", mock.Anything, mock.Anything, true).Return(err).Maybe()
+	mdi.On("InsertOrGetTokenPool", mock.Anything, mock.Anything).Return(nil, err).Maybe()
+	mdi.On("InsertOrGetTokenTransfer", mock.Anything, mock.Anything).Return(nil, err).Maybe()
+	mdi.On("UpdateTokenBalances", mock.Anything, mock.Anything).Return(err).Maybe()
+	mdi.On("UpsertTokenApproval", mock.Anything, mock.Anything).Return(err).Maybe()
+}
+
+func testSummary(records map[string][]string) *core.NamespaceArchiveSummary {
+	summary := &core.NamespaceArchiveSummary{Namespace: "ns1", Created: fftypes.Now()}
+	for _, c := range (&archive{}).collections() {
+		ch := newCollectionHash()
+		for _, r := range records[c.name] {
+			h, _ := c.hashable(context.Background(), []byte(r))
+			ch.add(h)
+		}
+		summary.Collections = append(summary.Collections, ch.summary(c.name))
+	}
+	return summary
+}
+
+func testArchive(t *testing.T, header string, records [][2]string, summary *core.NamespaceArchiveSummary) *bytes.Buffer {
+	b := bytes.NewBufferString(header + "\n")
+	for _, r := range records {
+		b.WriteString(fmt.Sprintf(`{"collection":"%s","record":%s}`+"\n", r[0], r[1]))
+	}
+	if summary != nil {
+		sb, err := json.Marshal(&archiveEntry{Summary: summary})
+		assert.NoError(t, err)
+		b.Write(sb)
+	}
+	return b
+}
+
+const testHeader = `{"header":{"version":1,"namespace":"ns1"}}`
+
+type errWriter struct {
+	writes int
+}
+
+func (w *errWriter) Write(b []byte) (int, error) {
+	if w.writes <= 0 {
+		return 0, fmt.Errorf("pop")
+	}
+	w.writes--
+	return len(b), nil
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	tr := &testRecords{subID: fftypes.NewUUID(), eventID: fftypes.NewUUID()}
+
+	mdi1 := newTestDB()
+	mockPopulatedNamespace(mdi1, tr, 5)
+	var b bytes.Buffer
+	err := Export(context.Background(), "ns1", mdi1, &b)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	assert.Len(t, lines, 28)
+	assert.Regexp(t, `"pluginData":"pd1"`, b.String())
+
+	mdi2 := newTestDB()
+	mockEmptyNamespace(mdi2)
+	mockPopulatedNamespace(mdi2, tr, 10)
+	mockRestore(mdi2, nil)
+	mdi2.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(o *core.Offset) bool {
+		return o.Current == 10
+	}), true).Return(nil)
+	mdi2.On("InsertOrGetTokenPool", mock.Anything, mock.MatchedBy(func(p *core.TokenPool) bool {
+		return p.PluginData == "pd1"
+	})).Return(nil, nil)
+	summary, err := Import(context.Background(), "ns1", mdi2, &b)
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", summary.Namespace)
+	assert.Len(t, summary.Collections, 26)
+	for _, c := range summary.Collections {
+		assert.Equal(t, int64(1), c.Count, c.Name)
+	}
+
+	mdi1.AssertExpectations(t)
+	mdi2.AssertExpectations(t)
+}
+
+func TestExportFailHeader(t *testing.T) {
+	mdi := newTestDB()
+	err := Export(context.Background(), "ns1", mdi, &errWriter{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportFailQuery(t *testing.T) {
+	mdi := newTestDB()
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := Export(context.Background(), "ns1", mdi, &bytes.Buffer{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportFailRecord(t *testing.T) {
+	mdi := newTestDB()
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{{}}, nil, nil)
+	err := Export(context.Background(), "ns1", mdi, &errWriter{writes: 1})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestExportFailMarshal(t *testing.T) {
+	mdi := newTestDB()
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{
+		{Value: fftypes.JSONAnyPtr("!json")},
+	}, nil, nil)
+	err := Export(context.Background(), "ns1", mdi, &bytes.Buffer{})
+	assert.Regexp(t, "json", err)
+}
+
+func TestExportPaging(t *testing.T) {
+	mdi := newTestDB()
+	page := make([]*core.Datatype, pageSize)
+	for i := range page {
+		page[i] = &core.Datatype{}
+	}
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return(page, nil, nil).Once()
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := Export(context.Background(), "ns1", mdi, &bytes.Buffer{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportNotEmpty(t *testing.T) {
+	mdi := newTestDB()
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{{}}, nil, nil)
+	_, err := Import(context.Background(), "ns1", mdi, &bytes.Buffer{})
+	assert.Regexp(t, "FF10481.*datatypes", err)
+}
+
+func TestImportCheckEmptyFail(t *testing.T) {
+	mdi := newTestDB()
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := Import(context.Background(), "ns1", mdi, &bytes.Buffer{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportBadHeader(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	_, err := Import(context.Background(), "ns1", mdi, bytes.NewBufferString("!json"))
+	assert.Regexp(t, "FF10482.*1", err)
+}
+
+func TestImportMissingHeader(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	_, err := Import(context.Background(), "ns1", mdi, bytes.NewBufferString(`{"header":{"version":2}}`))
+	assert.Regexp(t, "FF10482.*header", err)
+}
+
+func TestImportWrongNamespace(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	_, err := Import(context.Background(), "ns1", mdi, bytes.NewBufferString(`{"header":{"version":1,"namespace":"ns2"}}`))
+	assert.Regexp(t, "FF10484", err)
+}
+
+func TestImportMissingSummary(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, nil, nil))
+	assert.Regexp(t, "FF10482.*2.*EOF", err)
+}
+
+func TestImportUnknownCollection(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{{"wrong", "{}"}}, nil))
+	assert.Regexp(t, "FF10482.*2", err)
+}
+
+func TestImportCollectionNotContiguous(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	mockRestore(mdi, nil)
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{
+		{"datatypes", "{}"},
+		{"groups", "{}"},
+		{"datatypes", "{}"},
+	}, nil))
+	assert.Regexp(t, "FF10482.*4", err)
+}
+
+func TestImportFailFlushOnSwitch(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	mockRestore(mdi, fmt.Errorf("pop"))
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{
+		{"datatypes", "{}"},
+		{"groups", "{}"},
+	}, nil))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportFailFlushFullBatch(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	mockRestore(mdi, fmt.Errorf("pop"))
+	records := make([][2]string, pageSize)
+	for i := range records {
+		records[i] = [2]string{"transactions", "{}"}
+	}
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, records, nil))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportRestoreFailures(t *testing.T) {
+	for _, c := range (&archive{}).collections() {
+		if c.restore == nil {
+			continue
+		}
+		mdi := newTestDB()
+		mockEmptyNamespace(mdi)
+		mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.Event{}, nil, nil).Maybe()
+		mockRestore(mdi, fmt.Errorf("pop"))
+		_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{{c.name, "{}"}}, testSummary(nil)))
+		assert.EqualError(t, err, "pop", c.name)
+	}
+}
+
+func TestImportBadRecords(t *testing.T) {
+	for _, c := range (&archive{}).collections() {
+		if c.restore == nil {
+			continue
+		}
+		records := []string{`"!record"`}
+		if len(c.localFields) > 0 {
+			// Records are first read as objects, to exclude the local fields from the hash
+			records = append(records, `{"id":false,"localId":false,"name":false,"hash":false}`)
+		}
+		for _, record := range records {
+			mdi := newTestDB()
+			mockEmptyNamespace(mdi)
+			_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{{c.name, record}}, testSummary(nil)))
+			assert.Regexp(t, "FF10483", err, c.name)
+		}
+	}
+}
+
+func TestImportUpdateBalancesFail(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	mdi.On("InsertOrGetTokenTransfer", mock.Anything, mock.Anything).Return(nil, nil)
+	mdi.On("UpdateTokenBalances", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{{"tokentransfers", "{}"}}, testSummary(nil)))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportOffsetMapFail(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{{"offsets", "{}"}}, testSummary(nil)))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportVerifyMissingCollection(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	summary := testSummary(nil)
+	summary.Collections = summary.Collections[1:]
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, nil, summary))
+	assert.Regexp(t, "FF10485.*datatypes", err)
+}
+
+func TestImportVerifyUnexpectedCollection(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	mockRestore(mdi, nil)
+	summary := testSummary(nil)
+	summary.Collections = summary.Collections[1:]
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{{"datatypes", "{}"}}, summary))
+	assert.Regexp(t, "FF10485.*datatypes", err)
+}
+
+func TestImportVerifyCountMismatch(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	summary := testSummary(nil)
+	summary.Collections[0].Count = 1
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, nil, summary))
+	assert.Regexp(t, "FF10485.*datatypes record count", err)
+}
+
+func TestImportVerifyHashMismatch(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	mockRestore(mdi, nil)
+	summary := testSummary(map[string][]string{"datatypes": {`{"different":true}`}})
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{{"datatypes", "{}"}}, summary))
+	assert.Regexp(t, "FF10485.*datatypes hash", err)
+}
+
+func TestImportVerifyDatabaseCountMismatch(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	mockRestore(mdi, nil)
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{}, nil, nil)
+	summary := testSummary(map[string][]string{"datatypes": {"{}"}})
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{{"datatypes", "{}"}}, summary))
+	assert.Regexp(t, "FF10485.*datatypes database count", err)
+}
+
+func TestImportVerifyDatabaseCountFail(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, nil, testSummary(nil)))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestImportVerifyDatabaseHashMismatch(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	mockRestore(mdi, nil)
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{{Name: "changed"}}, nil, nil)
+	summary := testSummary(map[string][]string{"datatypes": {"{}"}})
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{{"datatypes", "{}"}}, summary))
+	assert.Regexp(t, "FF10485.*datatypes database hash", err)
+}
+
+func TestImportVerifyDatabaseMarshalFail(t *testing.T) {
+	mdi := newTestDB()
+	mockEmptyNamespace(mdi)
+	mockRestore(mdi, nil)
+	mdi.On("GetDatatypes", mock.Anything, "ns1", mock.Anything).Return([]*core.Datatype{
+		{Value: fftypes.JSONAnyPtr("!json")},
+	}, nil, nil)
+	summary := testSummary(map[string][]string{"datatypes": {"{}"}})
+	_, err := Import(context.Background(), "ns1", mdi, testArchive(t, testHeader, [][2]string{{"datatypes", "{}"}}, summary))
+	assert.Regexp(t, "json", err)
+}
+
+func TestHashOffsetsFail(t *testing.T) {
+	mdi := newTestDB()
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{
+		{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}},
+	}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	a := &archive{namespace: "ns1", database: mdi}
+	for _, c := range a.collections() {
+		if c.name == "offsets" {
+			_, err := a.hashDatabase(context.Background(), c)
+			assert.EqualError(t, err, "pop")
+		}
+	}
+}
+
+func TestQueryOffsetsFail(t *testing.T) {
+	mdi := newTestDB()
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	a := &archive{namespace: "ns1", database: mdi}
+	for _, c := range a.collections() {
+		if c.name == "offsets" {
+			_, err := a.hashDatabase(context.Background(), c)
+			assert.EqualError(t, err, "pop")
+		}
+	}
+}
+
+func TestQueryOffsetsPaging(t *testing.T) {
+	mdi := newTestDB()
+	subs := make([]*core.Subscription, pageSize)
+	for i := range subs {
+		subs[i] = &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}}
+	}
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return(subs, nil, nil).Once()
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, mock.Anything).Return(&core.Offset{}, nil)
+	a := &archive{namespace: "ns1", database: mdi}
+	for _, c := range a.collections() {
+		if c.name == "offsets" {
+			ch, err := a.hashDatabase(context.Background(), c)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(pageSize), ch.count)
+		}
+	}
+	mdi.AssertExpectations(t)
+}
+
+func TestCollectionHashOrderIndependent(t *testing.T) {
+	ch1 := newCollectionHash()
+	ch1.add([]byte(`{"a":1}`))
+	ch1.add([]byte(`{"b":2}`))
+	ch2 := newCollectionHash()
+	ch2.add([]byte(`{"b":2}`))
+	ch2.add([]byte(`{"a":1}`))
+	assert.Equal(t, ch1.summary("c").Hash, ch2.summary("c").Hash)
+
+	ch3 := newCollectionHash()
+	ch3.add([]byte(`{"a":1}`))
+	ch3.add([]byte(`{"a":1}`))
+	assert.NotEqual(t, ch1.summary("c").Hash, ch3.summary("c").Hash)
+}
+
+func TestHashableLocalFields(t *testing.T) {
+	c := &collection{name: "events", localFields: []string{"sequence"}}
+	h1, err := c.hashable(context.Background(), []byte(`{"id":"e1","sequence":1}`))
+	assert.NoError(t, err)
+	h2, err := c.hashable(context.Background(), []byte(`{"sequence":10,"id":"e1"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":"e1"}`, string(h1))
+	assert.Equal(t, h1, h2)
+}
+
+func TestMapEventSequence(t *testing.T) {
+	mdi := newTestDB()
+	ids := []*fftypes.UUID{fftypes.NewUUID(), fftypes.NewUUID()}
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.Event{
+		{ID: ids[0], Sequence: 100},
+		{ID: ids[1], Sequence: 101},
+	}, nil, nil).Once()
+	a := &archive{namespace: "ns1", database: mdi, eventSequences: []*eventSequence{
+		{id: ids[0], old: 10},
+		{id: ids[1], old: 20},
+	}}
+
+	seq, err := a.mapEventSequence(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), seq)
+
+	seq, err = a.mapEventSequence(context.Background(), 15)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), seq)
+
+	seq, err = a.mapEventSequence(context.Background(), 20)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), seq)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestMapEventSequenceMismatch(t *testing.T) {
+	mdi := newTestDB()
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.Event{
+		{ID: fftypes.NewUUID(), Sequence: 100},
+	}, nil, nil).Once()
+	a := &archive{namespace: "ns1", database: mdi, eventSequences: []*eventSequence{
+		{id: fftypes.NewUUID(), old: 10},
+	}}
+
+	_, err := a.mapEventSequence(context.Background(), 5)
+	assert.Regexp(t, "FF10485.*events sequence", err)
+}
@@ -0,0 +1,721 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nsarchive
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// collection describes how one type of record is read from the database on export, and written back on import.
+type collection struct {
+	name string
+	// query returns a page of records from the database - the sort must be stable across pages,
+	// and records with a local sequence are returned in the order they need to be restored
+	query        func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error)
+	queryFactory ffapi.QueryFactory
+	sort         string
+	// restore writes a batch of records from the archive to the database. Where this is nil, the
+	// records are derived from other collections during the import, and are only verified by count.
+	restore func(ctx context.Context, records []json.RawMessage) error
+	// localFields are assigned by the database when a record is restored, so are excluded from the hash
+	localFields []string
+}
+
+// archivedTokenPool includes the plugin data of the token pool, which is not returned on the API
+type archivedTokenPool struct {
+	*core.TokenPool
+	PluginData string `json:"pluginData,omitempty"`
+}
+
+func unmarshalRecord(ctx context.Context, collection string, raw json.RawMessage, record interface{}) error {
+	if err := json.Unmarshal(raw, record); err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgNamespaceArchiveInvalidRecord, collection)
+	}
+	return nil
+}
+
+// hashable returns the content of a record that is included in the hash of the collection
+func (c *collection) hashable(ctx context.Context, raw json.RawMessage) ([]byte, error) {
+	if len(c.localFields) == 0 {
+		return raw, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := unmarshalRecord(ctx, c.name, raw, &fields); err != nil {
+		return nil, err
+	}
+	for _, f := range c.localFields {
+		delete(fields, f)
+	}
+	return json.Marshal(fields)
+}
+
+// collections returns the collections of the namespace, in the order they are exported and restored.
+// Records that refer to other records come after the records they refer to.
+func (a *archive) collections() []*collection {
+	ns := a.namespace
+	di := a.database
+	return []*collection{
+		{
+			name:         "datatypes",
+			queryFactory: database.DatatypeQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetDatatypes(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.Datatype
+					if err := unmarshalRecord(ctx, "datatypes", raw, &r); err != nil {
+						return err
+					}
+					if err := di.UpsertDatatype(ctx, &r, false); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "ffis",
+			queryFactory: database.FFIQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetFFIs(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r fftypes.FFI
+					if err := unmarshalRecord(ctx, "ffis", raw, &r); err != nil {
+						return err
+					}
+					if err := di.UpsertFFI(ctx, &r, database.UpsertOptimizationNew); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "ffimethods",
+			queryFactory: database.FFIMethodQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetFFIMethods(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r fftypes.FFIMethod
+					if err := unmarshalRecord(ctx, "ffimethods", raw, &r); err != nil {
+						return err
+					}
+					if err := di.UpsertFFIMethod(ctx, &r); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "ffievents",
+			queryFactory: database.FFIEventQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetFFIEvents(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r fftypes.FFIEvent
+					if err := unmarshalRecord(ctx, "ffievents", raw, &r); err != nil {
+						return err
+					}
+					if err := di.UpsertFFIEvent(ctx, &r); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "ffierrors",
+			queryFactory: database.FFIErrorQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetFFIErrors(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r fftypes.FFIError
+					if err := unmarshalRecord(ctx, "ffierrors", raw, &r); err != nil {
+						return err
+					}
+					if err := di.UpsertFFIError(ctx, &r); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "contractapis",
+			queryFactory: database.ContractAPIQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetContractAPIs(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.ContractAPI
+					if err := unmarshalRecord(ctx, "contractapis", raw, &r); err != nil {
+						return err
+					}
+					if err := di.UpsertContractAPI(ctx, &r, database.UpsertOptimizationNew); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "contractlisteners",
+			queryFactory: database.ContractListenerQueryFactory,
+			localFields:  []string{"created"},
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetContractListeners(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.ContractListener
+					if err := unmarshalRecord(ctx, "contractlisteners", raw, &r); err != nil {
+						return err
+					}
+					if err := di.InsertContractListener(ctx, &r); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "identities",
+			queryFactory: database.IdentityQueryFactory,
+			localFields:  []string{"created", "updated"},
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetIdentities(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.Identity
+					if err := unmarshalRecord(ctx, "identities", raw, &r); err != nil {
+						return err
+					}
+					if err := di.UpsertIdentity(ctx, &r, database.UpsertOptimizationNew); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "verifiers",
+			queryFactory: database.VerifierQueryFactory,
+			localFields:  []string{"created"},
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetVerifiers(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.Verifier
+					if err := unmarshalRecord(ctx, "verifiers", raw, &r); err != nil {
+						return err
+					}
+					if err := di.UpsertVerifier(ctx, &r, database.UpsertOptimizationNew); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "groups",
+			queryFactory: database.GroupQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetGroups(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.Group
+					if err := unmarshalRecord(ctx, "groups", raw, &r); err != nil {
+						return err
+					}
+					if err := di.UpsertGroup(ctx, &r, database.UpsertOptimizationNew); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "transactions",
+			queryFactory: database.TransactionQueryFactory,
+			localFields:  []string{"created"},
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetTransactions(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				txns := make([]*core.Transaction, len(records))
+				for i, raw := range records {
+					txns[i] = &core.Transaction{}
+					if err := unmarshalRecord(ctx, "transactions", raw, txns[i]); err != nil {
+						return err
+					}
+				}
+				return di.InsertTransactions(ctx, txns)
+			},
+		},
+		{
+			name:         "operations",
+			queryFactory: database.OperationQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetOperations(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				ops := make([]*core.Operation, len(records))
+				for i, raw := range records {
+					ops[i] = &core.Operation{}
+					if err := unmarshalRecord(ctx, "operations", raw, ops[i]); err != nil {
+						return err
+					}
+				}
+				return di.InsertOperations(ctx, ops)
+			},
+		},
+		{
+			name:         "blobs",
+			queryFactory: database.BlobQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetBlobs(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				blobs := make([]*core.Blob, len(records))
+				for i, raw := range records {
+					blobs[i] = &core.Blob{}
+					if err := unmarshalRecord(ctx, "blobs", raw, blobs[i]); err != nil {
+						return err
+					}
+				}
+				return di.InsertBlobs(ctx, blobs)
+			},
+		},
+		{
+			name:         "data",
+			queryFactory: database.DataQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetData(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				data := make(core.DataArray, len(records))
+				for i, raw := range records {
+					data[i] = &core.Data{}
+					if err := unmarshalRecord(ctx, "data", raw, data[i]); err != nil {
+						return err
+					}
+				}
+				return di.InsertDataArray(ctx, data)
+			},
+		},
+		{
+			name:         "batches",
+			queryFactory: database.BatchQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetBatches(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.BatchPersisted
+					if err := unmarshalRecord(ctx, "batches", raw, &r); err != nil {
+						return err
+					}
+					if _, err := di.InsertOrGetBatch(ctx, &r); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "messages",
+			queryFactory: database.MessageQueryFactory,
+			sort:         "sequence",
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetMessages(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				msgs := make([]*core.Message, len(records))
+				for i, raw := range records {
+					msgs[i] = &core.Message{}
+					if err := unmarshalRecord(ctx, "messages", raw, msgs[i]); err != nil {
+						return err
+					}
+				}
+				return di.InsertMessages(ctx, msgs)
+			},
+		},
+		{
+			name:         "pins",
+			queryFactory: database.PinQueryFactory,
+			sort:         "sequence",
+			localFields:  []string{"sequence"},
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetPins(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				pins := make([]*core.Pin, len(records))
+				for i, raw := range records {
+					pins[i] = &core.Pin{}
+					if err := unmarshalRecord(ctx, "pins", raw, pins[i]); err != nil {
+						return err
+					}
+				}
+				return di.InsertPins(ctx, pins)
+			},
+		},
+		{
+			name:         "nextpins",
+			queryFactory: database.NextPinQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetNextPins(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.NextPin
+					if err := unmarshalRecord(ctx, "nextpins", raw, &r); err != nil {
+						return err
+					}
+					if err := di.InsertNextPin(ctx, &r); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "blockchainevents",
+			queryFactory: database.BlockchainEventQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetBlockchainEvents(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				events := make([]*core.BlockchainEvent, len(records))
+				for i, raw := range records {
+					events[i] = &core.BlockchainEvent{}
+					if err := unmarshalRecord(ctx, "blockchainevents", raw, events[i]); err != nil {
+						return err
+					}
+				}
+				return di.InsertBlockchainEvents(ctx, events)
+			},
+		},
+		{
+			name:         "events",
+			queryFactory: database.EventQueryFactory,
+			sort:         "sequence",
+			localFields:  []string{"sequence"},
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetEvents(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				// The events are inserted immediately, rather than on commit, so the offsets can be mapped
+				events := make([]*core.Event, len(records))
+				for i, raw := range records {
+					events[i] = &core.Event{}
+					if err := unmarshalRecord(ctx, "events", raw, events[i]); err != nil {
+						return err
+					}
+					a.eventSequences = append(a.eventSequences, &eventSequence{id: events[i].ID, old: events[i].Sequence})
+				}
+				return di.InsertEvents(ctx, events)
+			},
+		},
+		{
+			name:         "subscriptions",
+			queryFactory: database.SubscriptionQueryFactory,
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetSubscriptions(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.Subscription
+					if err := unmarshalRecord(ctx, "subscriptions", raw, &r); err != nil {
+						return err
+					}
+					if err := di.UpsertSubscription(ctx, &r, false); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			// The offsets table is not namespaced, so the offsets of the subscriptions in the namespace are
+			// exported. There are few subscriptions in a namespace, so these are loaded in a single page.
+			// The event sequences are local to the database, so are mapped to the new event sequences on import.
+			name:         "offsets",
+			queryFactory: database.SubscriptionQueryFactory,
+			localFields:  []string{"current"},
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				fi, err := filter.Finalize()
+				if err != nil || fi.Skip > 0 {
+					return nil, nil, err
+				}
+				subs, _, err := di.GetSubscriptions(ctx, ns, database.SubscriptionQueryFactory.NewFilter(ctx).And())
+				if err != nil {
+					return nil, nil, err
+				}
+				out := make([]interface{}, 0, len(subs))
+				for _, sub := range subs {
+					offset, err := di.GetOffset(ctx, core.OffsetTypeSubscription, sub.ID.String())
+					if err != nil {
+						return nil, nil, err
+					}
+					if offset != nil {
+						out = append(out, offset)
+					}
+				}
+				return out, &ffapi.FilterResult{}, nil
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.Offset
+					if err := unmarshalRecord(ctx, "offsets", raw, &r); err != nil {
+						return err
+					}
+					current, err := a.mapEventSequence(ctx, r.Current)
+					if err != nil {
+						return err
+					}
+					r.Current = current
+					if err := di.UpsertOffset(ctx, &r, true); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "tokenpools",
+			queryFactory: database.TokenPoolQueryFactory,
+			localFields:  []string{"created"},
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetTokenPools(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = &archivedTokenPool{TokenPool: r, PluginData: r.PluginData}
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					r := &archivedTokenPool{TokenPool: &core.TokenPool{}}
+					if err := unmarshalRecord(ctx, "tokenpools", raw, r); err != nil {
+						return err
+					}
+					r.TokenPool.PluginData = r.PluginData
+					if _, err := di.InsertOrGetTokenPool(ctx, r.TokenPool); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			// Transfers are restored in the order they were created, as the balances (and the balance
+			// history) are rebuilt from the transfers as they are restored
+			name:         "tokentransfers",
+			queryFactory: database.TokenTransferQueryFactory,
+			sort:         "created",
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetTokenTransfers(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.TokenTransfer
+					if err := unmarshalRecord(ctx, "tokentransfers", raw, &r); err != nil {
+						return err
+					}
+					existing, err := di.InsertOrGetTokenTransfer(ctx, &r)
+					if err == nil && existing == nil {
+						err = di.UpdateTokenBalances(ctx, &r)
+					}
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "tokenapprovals",
+			queryFactory: database.TokenApprovalQueryFactory,
+			localFields:  []string{"created"},
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetTokenApprovals(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+			restore: func(ctx context.Context, records []json.RawMessage) error {
+				for _, raw := range records {
+					var r core.TokenApproval
+					if err := unmarshalRecord(ctx, "tokenapprovals", raw, &r); err != nil {
+						return err
+					}
+					if err := di.UpsertTokenApproval(ctx, &r); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name:         "tokenbalances",
+			queryFactory: database.TokenBalanceQueryFactory,
+			localFields:  []string{"updated"},
+			query: func(ctx context.Context, filter ffapi.AndFilter) ([]interface{}, *ffapi.FilterResult, error) {
+				records, res, err := di.GetTokenBalances(ctx, ns, filter)
+				out := make([]interface{}, len(records))
+				for i, r := range records {
+					out[i] = r
+				}
+				return out, res, err
+			},
+		},
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"database/sql/driver"
 	"fmt"
+	"sync"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -28,6 +29,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/internal/txcommon"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 )
@@ -68,16 +70,22 @@ func ErrTernary(err error, ifErr, ifNoError core.OpPhase) core.OpPhase {
 }
 
 type operationsManager struct {
-	ctx       context.Context
-	namespace string
-	database  database.Plugin
-	handlers  map[core.OpType]OperationHandler
-	txHelper  txcommon.Helper
-	updater   *operationUpdater
-	cache     cache.CInterface
+	ctx          context.Context
+	namespace    string
+	database     database.Plugin
+	blockchain   blockchain.Plugin
+	handlers     map[core.OpType]OperationHandler
+	txHelper     txcommon.Helper
+	updater      *operationUpdater
+	cache        cache.CInterface
+	conf         Config
+	policyCtx    context.Context
+	policyCancel context.CancelFunc
+	policyDone   chan struct{}
+	startOnce    sync.Once
 }
 
-func NewOperationsManager(ctx context.Context, ns string, di database.Plugin, txHelper txcommon.Helper, cacheManager cache.Manager) (Manager, error) {
+func NewOperationsManager(ctx context.Context, ns string, di database.Plugin, bi blockchain.Plugin, txHelper txcommon.Helper, cacheManager cache.Manager, conf Config) (Manager, error) {
 	if di == nil || txHelper == nil {
 		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "OperationsManager")
 	}
@@ -96,12 +104,16 @@ func NewOperationsManager(ctx context.Context, ns string, di database.Plugin, tx
 	}
 
 	om := &operationsManager{
-		ctx:       ctx,
-		namespace: ns,
-		database:  di,
-		txHelper:  txHelper,
-		handlers:  make(map[core.OpType]OperationHandler),
+		ctx:        ctx,
+		namespace:  ns,
+		database:   di,
+		blockchain: bi,
+		txHelper:   txHelper,
+		handlers:   make(map[core.OpType]OperationHandler),
+		conf:       conf,
+		policyDone: make(chan struct{}),
 	}
+	om.policyCtx, om.policyCancel = context.WithCancel(log.WithLogField(ctx, "role", "operation-policy"))
 	om.updater = newOperationUpdater(ctx, om, di, txHelper)
 	om.cache = cache
 	return om, nil
@@ -231,6 +243,13 @@ func (om *operationsManager) findLatestRetry(ctx context.Context, opID *fftypes.
 }
 
 func (om *operationsManager) RetryOperation(ctx context.Context, opID *fftypes.UUID) (op *core.Operation, err error) {
+	return om.retryOperation(ctx, opID, nil)
+}
+
+// retryOperation creates and runs a new operation to retry the latest in the retry chain of the given operation.
+// When called by the retry policy a decision is passed, which is recorded on both operations. In that case
+// the given operation must be the latest in the chain.
+func (om *operationsManager) retryOperation(ctx context.Context, opID *fftypes.UUID, decision *core.OperationPolicyDecision) (op *core.Operation, err error) {
 	var po *core.PreparedOperation
 	var idempotencyKey core.IdempotencyKey
 	err = om.database.RunAsGroup(ctx, func(ctx context.Context) error {
@@ -238,6 +257,9 @@ func (om *operationsManager) RetryOperation(ctx context.Context, opID *fftypes.U
 		if err != nil {
 			return err
 		}
+		if decision != nil && !parent.ID.Equals(opID) {
+			return i18n.NewError(ctx, coremsgs.MsgOperationAlreadyRetried, opID)
+		}
 		// Deep copy the operation so the parent ID will not get overwritten
 		op = parent.DeepCopy()
 
@@ -256,6 +278,10 @@ func (om *operationsManager) RetryOperation(ctx context.Context, opID *fftypes.U
 		op.Output = nil
 		op.Created = fftypes.Now()
 		op.Updated = op.Created
+		if decision != nil {
+			decision.Retry = op.ID
+			op.Policy = append(op.Policy, decision)
+		}
 		if err = om.database.InsertOperation(ctx, op); err != nil {
 			return err
 		}
@@ -264,6 +290,10 @@ func (om *operationsManager) RetryOperation(ctx context.Context, opID *fftypes.U
 		// Update the old operation to point to the new one
 		update := database.OperationQueryFactory.NewUpdate(ctx).Set("retry", op.ID)
 		om.updateCachedOperation(opID, "", nil, nil, op.ID)
+		if decision != nil {
+			update.Set("policy", policyValue(op.Policy))
+			om.updateCachedOperationPolicy(opID, op.Policy)
+		}
 		if _, err := om.database.UpdateOperation(ctx, om.namespace, opID, nil, update); err != nil {
 			return err
 		}
@@ -294,11 +324,25 @@ func (om *operationsManager) SubmitOperationUpdate(update *core.OperationUpdate)
 }
 
 func (om *operationsManager) Start() error {
-	om.updater.start()
+	om.startOnce.Do(func() {
+		om.updater.start()
+		if len(om.conf.Policies) > 0 {
+			log.L(om.ctx).Infof("Operation policies enabled for %d operation types checkInterval=%s", len(om.conf.Policies), om.conf.CheckInterval)
+			go om.policyLoop()
+		} else {
+			close(om.policyDone)
+		}
+	})
 	return nil
 }
 
 func (om *operationsManager) WaitStop() {
+	om.policyCancel()
+	om.startOnce.Do(func() {
+		// Start was never called, so there is no policy loop to wait for
+		close(om.policyDone)
+	})
+	<-om.policyDone
 	om.updater.close()
 }
 
@@ -367,3 +411,11 @@ func (om *operationsManager) updateCachedOperation(id *fftypes.UUID, status core
 		om.cacheOperation(val)
 	}
 }
+
+func (om *operationsManager) updateCachedOperationPolicy(id *fftypes.UUID, policy core.OperationPolicyLog) {
+	if cachedValue := om.cache.Get(id.String()); cachedValue != nil {
+		val := cachedValue.(*core.Operation)
+		val.Policy = policy
+		om.cacheOperation(val)
+	}
+}
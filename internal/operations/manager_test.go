@@ -28,6 +28,7 @@ import (
 	"github.com/hyperledger/firefly/internal/cache"
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/txcommon"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/cachemocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
@@ -95,7 +96,7 @@ func newTestOperations(t *testing.T) (*operationsManager, func()) {
 	}
 
 	ns := "ns1"
-	om, err := NewOperationsManager(ctx, ns, mdi, txHelper, cmi)
+	om, err := NewOperationsManager(ctx, ns, mdi, &blockchainmocks.Plugin{}, txHelper, cmi, Config{})
 	assert.NoError(t, err)
 	cmi.AssertCalled(t, "GetCache", cache.NewCacheConfig(
 		ctx,
@@ -107,7 +108,7 @@ func newTestOperations(t *testing.T) (*operationsManager, func()) {
 }
 
 func TestInitFail(t *testing.T) {
-	_, err := NewOperationsManager(context.Background(), "ns1", nil, nil, nil)
+	_, err := NewOperationsManager(context.Background(), "ns1", nil, nil, nil, nil, Config{})
 	assert.Regexp(t, "FF10128", err)
 }
 
@@ -127,7 +128,7 @@ func TestCacheInitFail(t *testing.T) {
 	ns := "ns1"
 	ecmi := &cachemocks.Manager{}
 	ecmi.On("GetCache", mock.Anything).Return(nil, cacheInitError)
-	_, err := NewOperationsManager(ctx, ns, mdi, txHelper, ecmi)
+	_, err := NewOperationsManager(ctx, ns, mdi, nil, txHelper, ecmi, Config{})
 	assert.Equal(t, cacheInitError, err)
 }
 
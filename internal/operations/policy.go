@@ -0,0 +1,303 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+const (
+	// TimeoutActionFail marks a timed out operation as failed
+	TimeoutActionFail = "fail"
+	// TimeoutActionQuery asks the blockchain connector for the current status of a timed out operation
+	TimeoutActionQuery = "query"
+)
+
+const policyPageSize = 100
+
+// Config is the operation policy configuration for a namespace
+type Config struct {
+	CheckInterval time.Duration
+	Policies      map[core.OpType]*Policy
+}
+
+// Policy is the timeout and automatic retry policy for a single operation type
+type Policy struct {
+	Timeout       time.Duration
+	TimeoutAction string
+	Retry         RetryPolicy
+}
+
+// RetryPolicy controls automatic retry of failed operations, with exponential backoff between attempts
+type RetryPolicy struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Factor       float64
+	Errors       []*regexp.Regexp
+}
+
+func (rp *RetryPolicy) eligible(errMsg string) bool {
+	if len(rp.Errors) == 0 {
+		return true
+	}
+	for _, re := range rp.Errors {
+		if re.MatchString(errMsg) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rp *RetryPolicy) delay(attempts int) time.Duration {
+	delay := float64(rp.InitialDelay) * math.Pow(rp.Factor, float64(attempts))
+	if delay > float64(rp.MaxDelay) {
+		return rp.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+func (om *operationsManager) policyLoop() {
+	defer close(om.policyDone)
+	ticker := time.NewTicker(om.conf.CheckInterval)
+	defer ticker.Stop()
+	for {
+		om.enforcePolicies(om.policyCtx)
+		select {
+		case <-ticker.C:
+		case <-om.policyCtx.Done():
+			log.L(om.policyCtx).Debugf("Operation policy enforcer exiting")
+			return
+		}
+	}
+}
+
+func (om *operationsManager) enforcePolicies(ctx context.Context) {
+	for opType, policy := range om.conf.Policies {
+		if policy.Timeout > 0 {
+			if err := om.enforceTimeouts(ctx, opType, policy); err != nil {
+				log.L(ctx).Errorf("Operation timeout check for '%s' failed (will retry in %s): %s", opType, om.conf.CheckInterval, err)
+			}
+		}
+		if policy.Retry.MaxAttempts > 0 {
+			if err := om.enforceRetries(ctx, opType, policy); err != nil {
+				log.L(ctx).Errorf("Operation retry check for '%s' failed (will retry in %s): %s", opType, om.conf.CheckInterval, err)
+			}
+		}
+	}
+}
+
+// forEachOperation pages through the operations matching the filter, oldest update first. Each page starts
+// after the last operation of the previous page, rather than at an offset, because fn updates operations
+// in ways that remove them from the filter.
+func (om *operationsManager) forEachOperation(ctx context.Context, filter func(fb ffapi.FilterBuilder) ffapi.Filter, fn func(op *core.Operation) error) error {
+	var afterUpdated *fftypes.FFTime
+	var afterID *fftypes.UUID
+	for {
+		fb := database.OperationQueryFactory.NewFilter(ctx)
+		f := filter(fb)
+		if afterID != nil {
+			f = fb.And(f, fb.Or(
+				fb.Gt("updated", afterUpdated),
+				fb.And(fb.Eq("updated", afterUpdated), fb.Gt("id", afterID)),
+			))
+		}
+		ops, _, err := om.database.GetOperations(ctx, om.namespace, f.Sort("updated").Sort("id").Limit(policyPageSize))
+		if err != nil {
+			return err
+		}
+		if len(ops) > 0 {
+			afterUpdated, afterID = ops[len(ops)-1].Updated, ops[len(ops)-1].ID
+		}
+		for _, op := range ops {
+			if err := fn(op); err != nil {
+				return err
+			}
+		}
+		if len(ops) < policyPageSize {
+			return nil
+		}
+	}
+}
+
+func (om *operationsManager) enforceTimeouts(ctx context.Context, opType core.OpType, policy *Policy) error {
+	cutoff := fftypes.FFTime(time.Now().Add(-policy.Timeout))
+	return om.forEachOperation(ctx, func(fb ffapi.FilterBuilder) ffapi.Filter {
+		return fb.And(
+			fb.Eq("type", opType),
+			fb.Eq("status", core.OpStatusPending),
+			fb.Eq("retry", nil),
+			fb.Lt("updated", &cutoff),
+		)
+	}, func(op *core.Operation) error {
+		reason := i18n.NewError(ctx, coremsgs.MsgOperationTimedOut, policy.Timeout).Error()
+		if policy.TimeoutAction == TimeoutActionQuery {
+			return om.queryTimedOutOperation(ctx, op, reason)
+		}
+		return om.failTimedOutOperation(ctx, op, reason)
+	})
+}
+
+func (om *operationsManager) failTimedOutOperation(ctx context.Context, op *core.Operation, reason string) error {
+	recorded, err := om.recordPolicyDecision(ctx, op, &core.OperationPolicyDecision{
+		Action: core.OpPolicyActionTimeoutFailed,
+		Reason: reason,
+	})
+	if err != nil || !recorded {
+		return err
+	}
+	log.L(ctx).Infof("Operation %s of type %s timed out, and is being marked failed", op.ID, op.Type)
+	om.SubmitOperationUpdate(&core.OperationUpdate{
+		NamespacedOpID: op.Namespace + ":" + op.ID.String(),
+		Plugin:         op.Plugin,
+		Status:         core.OpStatusFailed,
+		ErrorMessage:   reason,
+	})
+	return nil
+}
+
+func (om *operationsManager) queryTimedOutOperation(ctx context.Context, op *core.Operation, reason string) error {
+	if om.blockchain == nil {
+		log.L(ctx).Warnf("Unable to query status of timed out operation %s, as there is no blockchain plugin", op.ID)
+		return nil
+	}
+	// Recording the decision moves the updated time forwards, so we query at most once per timeout period
+	recorded, err := om.recordPolicyDecision(ctx, op, &core.OperationPolicyDecision{
+		Action: core.OpPolicyActionTimeoutQueried,
+		Reason: reason,
+	})
+	if err != nil || !recorded {
+		return err
+	}
+	log.L(ctx).Infof("Operation %s of type %s timed out, and its status is being queried", op.ID, op.Type)
+	// Any receipt found for a completed transaction is delivered back to us as an operation update,
+	// so there is nothing to do with the detail that is returned
+	if _, err := om.blockchain.GetTransactionStatus(ctx, op); err != nil {
+		log.L(ctx).Warnf("Failed to query status of timed out operation %s: %s", op.ID, err)
+	}
+	return nil
+}
+
+func (om *operationsManager) enforceRetries(ctx context.Context, opType core.OpType, policy *Policy) error {
+	// Only consider operations that failed recently enough that they could still be due a retry,
+	// so that failures from before the policy was configured are left alone
+	cutoff := fftypes.FFTime(time.Now().Add(-(policy.Retry.MaxDelay + 2*om.conf.CheckInterval)))
+	return om.forEachOperation(ctx, func(fb ffapi.FilterBuilder) ffapi.Filter {
+		return fb.And(
+			fb.Eq("type", opType),
+			fb.Eq("status", core.OpStatusFailed),
+			fb.Eq("retry", nil),
+			fb.Gt("updated", &cutoff),
+		)
+	}, func(op *core.Operation) error {
+		return om.retryFailedOperation(ctx, op, &policy.Retry)
+	})
+}
+
+func (om *operationsManager) retryFailedOperation(ctx context.Context, op *core.Operation, policy *RetryPolicy) error {
+	if last := op.Policy.Last(); last != nil &&
+		(last.Action == core.OpPolicyActionRetryExhausted || last.Action == core.OpPolicyActionRetryNotEligible) {
+		return nil
+	}
+
+	if !policy.eligible(op.Error) {
+		_, err := om.recordPolicyDecision(ctx, op, &core.OperationPolicyDecision{
+			Action: core.OpPolicyActionRetryNotEligible,
+			Reason: op.Error,
+		})
+		return err
+	}
+
+	attempts := op.Policy.Count(core.OpPolicyActionRetried)
+	if attempts >= policy.MaxAttempts {
+		_, err := om.recordPolicyDecision(ctx, op, &core.OperationPolicyDecision{
+			Action: core.OpPolicyActionRetryExhausted,
+			Count:  attempts,
+			Reason: op.Error,
+		})
+		return err
+	}
+
+	if time.Since(*op.Updated.Time()) < policy.delay(attempts) {
+		return nil
+	}
+
+	log.L(ctx).Infof("Automatically retrying failed operation %s of type %s (attempt %d of %d)", op.ID, op.Type, attempts+1, policy.MaxAttempts)
+	if _, err := om.retryOperation(ctx, op.ID, &core.OperationPolicyDecision{
+		Action: core.OpPolicyActionRetried,
+		Time:   fftypes.Now(),
+		Count:  attempts + 1,
+		Reason: op.Error,
+	}); err != nil {
+		// The retry is recorded before it is run, so a failure to submit is just a failure of the new operation
+		log.L(ctx).Warnf("Automatic retry of operation %s failed: %s", op.ID, err)
+	}
+	return nil
+}
+
+// recordPolicyDecision appends the decision to the policy log of the operation, as long as the operation
+// has not been updated since it was read. Consecutive decisions with the same action are merged.
+func (om *operationsManager) recordPolicyDecision(ctx context.Context, op *core.Operation, decision *core.OperationPolicyDecision) (bool, error) {
+	now := fftypes.Now()
+	policy := make(core.OperationPolicyLog, len(op.Policy), len(op.Policy)+1)
+	copy(policy, op.Policy)
+	if last := policy.Last(); last != nil && last.Action == decision.Action {
+		merged := *last
+		merged.Time = now
+		merged.Count++
+		merged.Reason = decision.Reason
+		policy[len(policy)-1] = &merged
+	} else {
+		decision.Time = now
+		if decision.Count == 0 {
+			decision.Count = 1
+		}
+		policy = append(policy, decision)
+	}
+
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	update := database.OperationQueryFactory.NewUpdate(ctx).Set("policy", policyValue(policy))
+	updated, err := om.database.UpdateOperation(ctx, om.namespace, op.ID, fb.And(
+		fb.Eq("status", op.Status),
+		fb.Eq("updated", op.Updated),
+	), update)
+	if err != nil {
+		return false, err
+	}
+	if !updated {
+		log.L(ctx).Debugf("Operation %s was updated while applying policy action %s", op.ID, decision.Action)
+		return false, nil
+	}
+	om.updateCachedOperationPolicy(op.ID, policy)
+	return true, nil
+}
+
+func policyValue(policy core.OperationPolicyLog) interface{} {
+	v, _ := policy.Value() // cannot fail to serialize
+	return v
+}
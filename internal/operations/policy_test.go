@@ -0,0 +1,389 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/cache"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestPolicyOperation(opType core.OpType, status core.OpStatus, updatedAgo time.Duration) *core.Operation {
+	updated := fftypes.FFTime(time.Now().Add(-updatedAgo))
+	return &core.Operation{
+		ID:          fftypes.NewUUID(),
+		Namespace:   "ns1",
+		Plugin:      "blockchain",
+		Transaction: fftypes.NewUUID(),
+		Type:        opType,
+		Status:      status,
+		Updated:     &updated,
+	}
+}
+
+func policyMatcher(t *testing.T, action core.OpPolicyAction, count int) interface{} {
+	return mock.MatchedBy(func(update ffapi.Update) bool {
+		info, err := update.Finalize()
+		assert.NoError(t, err)
+		for _, so := range info.SetOperations {
+			if so.Field == "policy" {
+				v, _ := so.Value.Value()
+				var policy core.OperationPolicyLog
+				assert.NoError(t, policy.Scan(v))
+				return policy.Last().Action == action && policy.Last().Count == count
+			}
+		}
+		return false
+	})
+}
+
+func TestRetryPolicyEligible(t *testing.T) {
+	rp := &RetryPolicy{}
+	assert.True(t, rp.eligible("anything"))
+
+	rp.Errors = []*regexp.Regexp{regexp.MustCompile("(?i)timeout"), regexp.MustCompile("nonce")}
+	assert.True(t, rp.eligible("Request TIMEOUT"))
+	assert.True(t, rp.eligible("nonce too low"))
+	assert.False(t, rp.eligible("reverted"))
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	rp := &RetryPolicy{
+		InitialDelay: time.Second,
+		MaxDelay:     10 * time.Second,
+		Factor:       2.0,
+	}
+	assert.Equal(t, time.Second, rp.delay(0))
+	assert.Equal(t, 4*time.Second, rp.delay(2))
+	assert.Equal(t, 10*time.Second, rp.delay(4))
+}
+
+func TestPolicyLoopStartStop(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	om.conf = Config{
+		CheckInterval: time.Hour,
+		Policies: map[core.OpType]*Policy{
+			core.OpTypeBlockchainInvoke: {
+				Timeout:       time.Minute,
+				TimeoutAction: TimeoutActionQuery,
+				Retry:         RetryPolicy{MaxAttempts: 1, MaxDelay: time.Minute},
+			},
+		},
+	}
+
+	checked := make(chan struct{}, 2)
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		checked <- struct{}{}
+	})
+
+	err := om.Start()
+	assert.NoError(t, err)
+	<-checked
+	<-checked
+	// Starting again does not start another loop
+	err = om.Start()
+	assert.NoError(t, err)
+	om.WaitStop()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPolicyLoopNoPoliciesStartStop(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	err := om.Start()
+	assert.NoError(t, err)
+	err = om.Start()
+	assert.NoError(t, err)
+	om.WaitStop()
+}
+
+func TestPolicyLoopWaitStopNotStarted(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	om.conf.Policies = map[core.OpType]*Policy{
+		core.OpTypeBlockchainInvoke: {Timeout: time.Minute},
+	}
+
+	om.WaitStop()
+}
+
+func TestEnforcePoliciesNoop(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	om.conf.Policies = map[core.OpType]*Policy{
+		core.OpTypeBlockchainInvoke: {},
+	}
+
+	om.enforcePolicies(context.Background())
+}
+
+func TestEnforceTimeoutsFail(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	policy := &Policy{Timeout: time.Minute, TimeoutAction: TimeoutActionFail}
+
+	op := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusPending, time.Hour)
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{op}, nil, nil).Once()
+	mdi.On("UpdateOperation", mock.Anything, "ns1", op.ID, mock.Anything, policyMatcher(t, core.OpPolicyActionTimeoutFailed, 1)).Return(true, nil)
+
+	updates := make(chan *core.OperationUpdate, 1)
+	om.updater.workQueues = []chan *core.OperationUpdate{updates}
+
+	err := om.enforceTimeouts(context.Background(), core.OpTypeBlockchainInvoke, policy)
+	assert.NoError(t, err)
+
+	update := <-updates
+	assert.Equal(t, "ns1:"+op.ID.String(), update.NamespacedOpID)
+	assert.Equal(t, core.OpStatusFailed, update.Status)
+	assert.Regexp(t, "FF10522.*1m0s", update.ErrorMessage)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEnforceTimeoutsFailUpdatedConcurrently(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	policy := &Policy{Timeout: time.Minute, TimeoutAction: TimeoutActionFail}
+
+	op := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusPending, time.Hour)
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{op}, nil, nil).Once()
+	mdi.On("UpdateOperation", mock.Anything, "ns1", op.ID, mock.Anything, mock.Anything).Return(false, nil)
+
+	err := om.enforceTimeouts(context.Background(), core.OpTypeBlockchainInvoke, policy)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEnforceTimeoutsFailUpdateError(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	policy := &Policy{Timeout: time.Minute, TimeoutAction: TimeoutActionFail}
+
+	op := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusPending, time.Hour)
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{op}, nil, nil).Once()
+	mdi.On("UpdateOperation", mock.Anything, "ns1", op.ID, mock.Anything, mock.Anything).Return(false, fmt.Errorf("pop"))
+
+	err := om.enforceTimeouts(context.Background(), core.OpTypeBlockchainInvoke, policy)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEnforceTimeoutsQueryPaged(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	policy := &Policy{Timeout: time.Minute, TimeoutAction: TimeoutActionQuery}
+
+	page := make([]*core.Operation, policyPageSize)
+	for i := range page {
+		page[i] = newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusPending, time.Hour)
+	}
+	queried := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusPending, time.Hour)
+	queried.Policy = core.OperationPolicyLog{
+		{Action: core.OpPolicyActionTimeoutQueried, Time: queried.Updated, Count: 1},
+	}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	last := page[policyPageSize-1]
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return(page, nil, nil).Once()
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		// The next page starts after the last operation of the previous page, not at an offset
+		ff, _ := f.Finalize()
+		return ff.Skip == 0 && strings.Contains(ff.String(), "id >> '"+last.ID.String()+"'")
+	})).Return([]*core.Operation{queried}, nil, nil).Once()
+	mdi.On("UpdateOperation", mock.Anything, "ns1", mock.Anything, mock.Anything, policyMatcher(t, core.OpPolicyActionTimeoutQueried, 1)).Return(true, nil).Times(policyPageSize)
+	mdi.On("UpdateOperation", mock.Anything, "ns1", queried.ID, mock.Anything, policyMatcher(t, core.OpPolicyActionTimeoutQueried, 2)).Return(true, nil).Once()
+	mbi := om.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("GetTransactionStatus", mock.Anything, mock.Anything).Return(nil, nil).Times(policyPageSize)
+	mbi.On("GetTransactionStatus", mock.Anything, queried).Return(nil, fmt.Errorf("pop")).Once()
+
+	err := om.enforceTimeouts(context.Background(), core.OpTypeBlockchainInvoke, policy)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestEnforceTimeoutsQueryNoBlockchain(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	om.blockchain = nil
+	policy := &Policy{Timeout: time.Minute, TimeoutAction: TimeoutActionQuery}
+
+	op := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusPending, time.Hour)
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{op}, nil, nil).Once()
+
+	err := om.enforceTimeouts(context.Background(), core.OpTypeBlockchainInvoke, policy)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEnforceTimeoutsQueryUpdateError(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	policy := &Policy{Timeout: time.Minute, TimeoutAction: TimeoutActionQuery}
+
+	op := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusPending, time.Hour)
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{op}, nil, nil).Once()
+	mdi.On("UpdateOperation", mock.Anything, "ns1", op.ID, mock.Anything, mock.Anything).Return(false, fmt.Errorf("pop"))
+
+	err := om.enforceTimeouts(context.Background(), core.OpTypeBlockchainInvoke, policy)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEnforceRetriesRecordsOutcomes(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	policy := &Policy{
+		Retry: RetryPolicy{
+			MaxAttempts:  2,
+			InitialDelay: time.Minute,
+			MaxDelay:     time.Hour,
+			Factor:       2.0,
+			Errors:       []*regexp.Regexp{regexp.MustCompile("retryable")},
+		},
+	}
+
+	alreadyExhausted := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusFailed, time.Hour)
+	alreadyExhausted.Policy = core.OperationPolicyLog{{Action: core.OpPolicyActionRetryExhausted}}
+	notEligible := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusFailed, time.Hour)
+	notEligible.Error = "reverted"
+	exhausted := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusFailed, time.Hour)
+	exhausted.Error = "retryable"
+	exhausted.Policy = core.OperationPolicyLog{{Action: core.OpPolicyActionRetried}, {Action: core.OpPolicyActionRetried}}
+	notDue := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusFailed, time.Minute)
+	notDue.Error = "retryable"
+	notDue.Policy = core.OperationPolicyLog{{Action: core.OpPolicyActionRetried}}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{
+		alreadyExhausted, notEligible, exhausted, notDue,
+	}, nil, nil).Once()
+	mdi.On("UpdateOperation", mock.Anything, "ns1", notEligible.ID, mock.Anything, policyMatcher(t, core.OpPolicyActionRetryNotEligible, 1)).Return(true, nil)
+	mdi.On("UpdateOperation", mock.Anything, "ns1", exhausted.ID, mock.Anything, policyMatcher(t, core.OpPolicyActionRetryExhausted, 2)).Return(true, nil)
+
+	err := om.enforceRetries(context.Background(), core.OpTypeBlockchainInvoke, policy)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEnforceRetriesQueryFail(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	policy := &Policy{Retry: RetryPolicy{MaxAttempts: 1}}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := om.enforceRetries(context.Background(), core.OpTypeBlockchainInvoke, policy)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEnforceRetriesRetry(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	policy := &Policy{
+		Retry: RetryPolicy{
+			MaxAttempts:  3,
+			InitialDelay: time.Second,
+			MaxDelay:     time.Minute,
+			Factor:       2.0,
+		},
+	}
+
+	ctx := context.Background()
+	op := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusFailed, time.Hour)
+	op.Error = "pop"
+	op.Policy = core.OperationPolicyLog{{Action: core.OpPolicyActionRetried, Count: 1}}
+	om.cache = cache.NewUmanagedCache(ctx, 100, 10*time.Minute)
+	om.cacheOperation(op)
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{op}, nil, nil).Once()
+	mdi.On("GetTransactionByID", mock.Anything, "ns1", op.Transaction).Return(nil, nil)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(newOp *core.Operation) bool {
+		return len(newOp.Policy) == 2 &&
+			newOp.Policy[1].Action == core.OpPolicyActionRetried &&
+			newOp.Policy[1].Count == 2 &&
+			newOp.Policy[1].Reason == "pop" &&
+			newOp.Policy[1].Retry.Equals(newOp.ID)
+	})).Return(nil)
+	mdi.On("UpdateOperation", mock.Anything, "ns1", op.ID, mock.Anything, policyMatcher(t, core.OpPolicyActionRetried, 2)).Return(true, nil)
+	om.RegisterHandler(ctx, &mockHandler{Prepared: &core.PreparedOperation{ID: op.ID, Type: op.Type}}, []core.OpType{core.OpTypeBlockchainInvoke})
+
+	err := om.enforceRetries(ctx, core.OpTypeBlockchainInvoke, policy)
+	assert.NoError(t, err)
+
+	cached := om.getCachedOperation(op.ID)
+	assert.NotNil(t, cached.Retry)
+	assert.Len(t, cached.Policy, 2)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEnforceRetriesAlreadyRetried(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+	policy := &Policy{
+		Retry: RetryPolicy{MaxAttempts: 1, MaxDelay: time.Minute},
+	}
+
+	ctx := context.Background()
+	op := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusFailed, time.Hour)
+	retry := newTestPolicyOperation(core.OpTypeBlockchainInvoke, core.OpStatusPending, 0)
+	cachedOp := *op
+	cachedOp.Retry = retry.ID
+	om.cache = cache.NewUmanagedCache(ctx, 100, 10*time.Minute)
+	om.cacheOperation(&cachedOp)
+	om.cacheOperation(retry)
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{op}, nil, nil).Once()
+
+	err := om.enforceRetries(ctx, core.OpTypeBlockchainInvoke, policy)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
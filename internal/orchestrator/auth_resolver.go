@@ -0,0 +1,84 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// authResolver looks up the resources referred to by a request in the namespace, for the auth plugin
+type authResolver struct {
+	or *orchestrator
+}
+
+func (ar *authResolver) ResolveTokenPool(ctx context.Context, nameOrID string) (*core.TokenPool, error) {
+	ns := ar.or.namespace.Name
+	if nameOrID == "" {
+		// As for token transfers and approvals, the pool can be omitted if there is only one
+		fb := database.TokenPoolQueryFactory.NewFilter(ctx)
+		pools, _, err := ar.or.database().GetTokenPools(ctx, ns, fb.And().Limit(2))
+		if err != nil || len(pools) != 1 {
+			return nil, err
+		}
+		return pools[0], nil
+	}
+	if id, err := fftypes.ParseUUID(ctx, nameOrID); err == nil {
+		return ar.or.database().GetTokenPoolByID(ctx, ns, id)
+	}
+	return ar.or.database().GetTokenPool(ctx, ns, nameOrID)
+}
+
+func (ar *authResolver) ResolveContractAPI(ctx context.Context, nameOrID string) (*core.ContractAPI, error) {
+	ns := ar.or.namespace.Name
+	if id, err := fftypes.ParseUUID(ctx, nameOrID); err == nil {
+		return ar.or.database().GetContractAPIByID(ctx, ns, id)
+	}
+	return ar.or.database().GetContractAPIByName(ctx, ns, nameOrID)
+}
+
+func (ar *authResolver) ResolveContractAPIForCall(ctx context.Context, call *core.ContractCallRequest) (*core.ContractAPI, error) {
+	if call.Interface == nil || call.Location == nil || ar.or.blockchain() == nil {
+		return nil, nil
+	}
+	// Contract APIs are stored with a normalized location, so the location of the call is normalized to match
+	location, err := ar.or.blockchain().NormalizeContractLocation(ctx, blockchain.NormalizeCall, call.Location)
+	if err != nil {
+		return nil, nil // the call will be rejected when it is processed
+	}
+	fb := database.ContractAPIQueryFactory.NewFilter(ctx)
+	apis, _, err := ar.or.database().GetContractAPIs(ctx, ar.or.namespace.Name, fb.And(fb.Eq("interface", call.Interface)))
+	if err != nil {
+		return nil, err
+	}
+	var match *core.ContractAPI
+	for _, api := range apis {
+		if api.Location != nil && reflect.DeepEqual(api.Location.JSONObject(), location.JSONObject()) {
+			if match != nil {
+				// The call cannot be attributed to a single contract API
+				return nil, nil
+			}
+			match = api
+		}
+	}
+	return match, nil
+}
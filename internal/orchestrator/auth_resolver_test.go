@@ -0,0 +1,166 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResolveTokenPoolByName(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	pool := &core.TokenPool{ID: fftypes.NewUUID(), Name: "pool1"}
+	or.mdi.On("GetTokenPool", context.Background(), "ns", "pool1").Return(pool, nil)
+	res, err := (&authResolver{or: &or.orchestrator}).ResolveTokenPool(context.Background(), "pool1")
+	assert.NoError(t, err)
+	assert.Equal(t, pool, res)
+}
+
+func TestResolveTokenPoolByID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	pool := &core.TokenPool{ID: fftypes.NewUUID(), Name: "pool1"}
+	or.mdi.On("GetTokenPoolByID", context.Background(), "ns", pool.ID).Return(pool, nil)
+	res, err := (&authResolver{or: &or.orchestrator}).ResolveTokenPool(context.Background(), pool.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, pool, res)
+}
+
+func TestResolveTokenPoolDefault(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	pool := &core.TokenPool{ID: fftypes.NewUUID(), Name: "pool1"}
+	or.mdi.On("GetTokenPools", context.Background(), "ns", mock.Anything).Return([]*core.TokenPool{pool}, nil, nil)
+	res, err := (&authResolver{or: &or.orchestrator}).ResolveTokenPool(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, pool, res)
+}
+
+func TestResolveTokenPoolDefaultMultiple(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetTokenPools", context.Background(), "ns", mock.Anything).Return([]*core.TokenPool{{}, {}}, nil, nil)
+	res, err := (&authResolver{or: &or.orchestrator}).ResolveTokenPool(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestResolveTokenPoolDefaultFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetTokenPools", context.Background(), "ns", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := (&authResolver{or: &or.orchestrator}).ResolveTokenPool(context.Background(), "")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResolveContractAPIByName(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	api := &core.ContractAPI{ID: fftypes.NewUUID(), Name: "api1"}
+	or.mdi.On("GetContractAPIByName", context.Background(), "ns", "api1").Return(api, nil)
+	res, err := (&authResolver{or: &or.orchestrator}).ResolveContractAPI(context.Background(), "api1")
+	assert.NoError(t, err)
+	assert.Equal(t, api, res)
+}
+
+func TestResolveContractAPIByID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	api := &core.ContractAPI{ID: fftypes.NewUUID(), Name: "api1"}
+	or.mdi.On("GetContractAPIByID", context.Background(), "ns", api.ID).Return(api, nil)
+	res, err := (&authResolver{or: &or.orchestrator}).ResolveContractAPI(context.Background(), api.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, api, res)
+}
+
+func TestResolveContractAPIForCall(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	ctx := context.Background()
+	location := fftypes.JSONAnyPtr(`{"address":"0x12345"}`)
+	call := &core.ContractCallRequest{
+		Interface: fftypes.NewUUID(),
+		Location:  fftypes.JSONAnyPtr(`{"address":"12345"}`),
+	}
+	api := &core.ContractAPI{ID: fftypes.NewUUID(), Name: "api1", Location: location}
+	or.mbi.On("NormalizeContractLocation", ctx, blockchain.NormalizeCall, call.Location).Return(location, nil)
+	or.mdi.On("GetContractAPIs", ctx, "ns", mock.Anything).Return([]*core.ContractAPI{
+		{ID: fftypes.NewUUID(), Name: "api2", Location: fftypes.JSONAnyPtr(`{"address":"0x67890"}`)},
+		{ID: fftypes.NewUUID(), Name: "api3"},
+		api,
+	}, nil, nil)
+	res, err := (&authResolver{or: &or.orchestrator}).ResolveContractAPIForCall(ctx, call)
+	assert.NoError(t, err)
+	assert.Equal(t, api, res)
+}
+
+func TestResolveContractAPIForCallAmbiguous(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	ctx := context.Background()
+	location := fftypes.JSONAnyPtr(`{"address":"0x12345"}`)
+	call := &core.ContractCallRequest{Interface: fftypes.NewUUID(), Location: location}
+	or.mbi.On("NormalizeContractLocation", ctx, blockchain.NormalizeCall, call.Location).Return(location, nil)
+	or.mdi.On("GetContractAPIs", ctx, "ns", mock.Anything).Return([]*core.ContractAPI{
+		{ID: fftypes.NewUUID(), Name: "api1", Location: location},
+		{ID: fftypes.NewUUID(), Name: "api2", Location: location},
+	}, nil, nil)
+	res, err := (&authResolver{or: &or.orchestrator}).ResolveContractAPIForCall(ctx, call)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestResolveContractAPIForCallQueryFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	ctx := context.Background()
+	location := fftypes.JSONAnyPtr(`{"address":"0x12345"}`)
+	call := &core.ContractCallRequest{Interface: fftypes.NewUUID(), Location: location}
+	or.mbi.On("NormalizeContractLocation", ctx, blockchain.NormalizeCall, call.Location).Return(location, nil)
+	or.mdi.On("GetContractAPIs", ctx, "ns", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := (&authResolver{or: &or.orchestrator}).ResolveContractAPIForCall(ctx, call)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResolveContractAPIForCallBadLocation(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	ctx := context.Background()
+	call := &core.ContractCallRequest{Interface: fftypes.NewUUID(), Location: fftypes.JSONAnyPtr(`{}`)}
+	or.mbi.On("NormalizeContractLocation", ctx, blockchain.NormalizeCall, call.Location).Return(nil, fmt.Errorf("pop"))
+	res, err := (&authResolver{or: &or.orchestrator}).ResolveContractAPIForCall(ctx, call)
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestResolveContractAPIForCallNoInterface(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	res, err := (&authResolver{or: &or.orchestrator}).ResolveContractAPIForCall(context.Background(), &core.ContractCallRequest{
+		Location: fftypes.JSONAnyPtr(`{}`),
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
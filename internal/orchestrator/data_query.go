@@ -19,10 +19,14 @@ package orchestrator
 import (
 	"context"
 	"database/sql/driver"
+	"strconv"
+	"strings"
 
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/coremsgs"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -120,6 +124,28 @@ func (or *orchestrator) GetDatatypeByName(ctx context.Context, name, version str
 	return or.database().GetDatatypeByName(ctx, or.namespace.Name, name, version)
 }
 
+func (or *orchestrator) DiffDatatypes(ctx context.Context, name, fromVersion, toVersion string) (*core.DatatypeDiff, error) {
+	from, err := or.GetDatatypeByName(ctx, name, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := or.GetDatatypeByName(ctx, name, toVersion)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDatatypeVersionNotFound, &core.DatatypeRef{Name: name, Version: fromVersion})
+	}
+	if to == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDatatypeVersionNotFound, &core.DatatypeRef{Name: name, Version: toVersion})
+	}
+	return or.data.DiffDatatypes(ctx, from, to)
+}
+
+func (or *orchestrator) CheckDatatypeData(ctx context.Context, proposed *core.Datatype) (*core.DatatypeDataCheck, error) {
+	return or.data.CheckDatatypeData(ctx, proposed)
+}
+
 func (or *orchestrator) GetOperationByID(ctx context.Context, id string) (*core.Operation, error) {
 	u, err := fftypes.ParseUUID(ctx, id)
 	if err != nil {
@@ -192,6 +218,63 @@ func (or *orchestrator) GetTransactions(ctx context.Context, filter ffapi.AndFil
 	return or.database().GetTransactions(ctx, or.namespace.Name, filter)
 }
 
+func (or *orchestrator) GetTransactionsByBlockchainID(ctx context.Context, blockchainID string, filter ffapi.AndFilter) ([]*core.Transaction, *ffapi.FilterResult, error) {
+	return or.database().GetTransactionsByBlockchainID(ctx, or.namespace.Name, blockchainID, filter)
+}
+
+func (or *orchestrator) GetBlockchainTransaction(ctx context.Context, blockchainID string) (*core.BlockchainTransaction, error) {
+	// Transaction IDs are indexed in lower case, so the events must be matched on the same value
+	blockchainID = strings.ToLower(blockchainID)
+	txns, _, err := or.database().GetTransactionsByBlockchainID(ctx, or.namespace.Name, blockchainID, database.TransactionQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		return nil, err
+	}
+	efb := database.BlockchainEventQueryFactory.NewFilter(ctx)
+	events, _, err := or.database().GetBlockchainEvents(ctx, or.namespace.Name, efb.And(efb.Eq("tx.blockchainid", blockchainID)))
+	if err != nil {
+		return nil, err
+	}
+	if len(txns) == 0 && len(events) == 0 {
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
+	}
+
+	result := &core.BlockchainTransaction{
+		BlockchainID:     blockchainID,
+		Transactions:     make([]*core.TransactionWithDetail, 0, len(txns)),
+		BlockchainEvents: events,
+	}
+	for _, tx := range txns {
+		detail, err := or.getTransactionDetail(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+		result.Transactions = append(result.Transactions, detail)
+	}
+	return result, nil
+}
+
+func (or *orchestrator) getTransactionDetail(ctx context.Context, tx *core.Transaction) (detail *core.TransactionWithDetail, err error) {
+	detail = &core.TransactionWithDetail{Transaction: *tx}
+
+	ofb := database.OperationQueryFactory.NewFilter(ctx)
+	if detail.Operations, _, err = or.database().GetOperations(ctx, or.namespace.Name, ofb.And(ofb.Eq("tx", tx.ID))); err != nil {
+		return nil, err
+	}
+	mfb := database.MessageQueryFactory.NewFilter(ctx)
+	if detail.Messages, _, err = or.database().GetMessages(ctx, or.namespace.Name, mfb.And(mfb.Eq("txid", tx.ID))); err != nil {
+		return nil, err
+	}
+	tfb := database.TokenTransferQueryFactory.NewFilter(ctx)
+	if detail.TokenTransfers, _, err = or.database().GetTokenTransfers(ctx, or.namespace.Name, tfb.And(tfb.Eq("tx.id", tx.ID))); err != nil {
+		return nil, err
+	}
+	afb := database.TokenApprovalQueryFactory.NewFilter(ctx)
+	if detail.TokenApprovals, _, err = or.database().GetTokenApprovals(ctx, or.namespace.Name, afb.And(afb.Eq("tx.id", tx.ID))); err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
 func (or *orchestrator) GetMessages(ctx context.Context, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
 	return or.database().GetMessages(ctx, or.namespace.Name, filter)
 }
@@ -201,6 +284,22 @@ func (or *orchestrator) GetMessagesWithData(ctx context.Context, filter ffapi.An
 	if err != nil {
 		return nil, nil, err
 	}
+	return or.fetchMessagesData(ctx, msgs, fr)
+}
+
+func (or *orchestrator) GetMessagesByDataValue(ctx context.Context, matches []*core.JSONPathMatch, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
+	return or.database().GetMessagesByDataValue(ctx, or.namespace.Name, matches, filter)
+}
+
+func (or *orchestrator) GetMessagesByDataValueWithData(ctx context.Context, matches []*core.JSONPathMatch, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error) {
+	msgs, fr, err := or.database().GetMessagesByDataValue(ctx, or.namespace.Name, matches, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return or.fetchMessagesData(ctx, msgs, fr)
+}
+
+func (or *orchestrator) fetchMessagesData(ctx context.Context, msgs []*core.Message, fr *ffapi.FilterResult) (_ []*core.MessageInOut, _ *ffapi.FilterResult, err error) {
 	msgsData := make([]*core.MessageInOut, len(msgs))
 	for i, msg := range msgs {
 		if msgsData[i], err = or.fetchMessageData(ctx, msg); err != nil {
@@ -255,6 +354,66 @@ func (or *orchestrator) GetMessageEvents(ctx context.Context, id string, filter
 	return or.database().GetEvents(ctx, or.namespace.Name, filter)
 }
 
+// getMessageThreadIDs walks the replies to a message, following the cid of each message back to the one it
+// replies to, and returns the IDs of the message and all the replies to the requested depth
+func (or *orchestrator) getMessageThreadIDs(ctx context.Context, id string, depthStr string) ([]driver.Value, error) {
+	maxDepth := config.GetInt(coreconfig.MessageThreadMaxDepth)
+	depth := maxDepth
+	if depthStr != "" {
+		var err error
+		depth, err = strconv.Atoi(depthStr)
+		if err != nil || depth < 0 || depth > maxDepth {
+			return nil, i18n.NewError(ctx, coremsgs.MsgInvalidMessageThreadDepth, depthStr, maxDepth)
+		}
+	}
+	root, err := or.getMessageByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	maxSize := config.GetInt(coreconfig.MessageThreadMaxSize)
+	threadIDs := []driver.Value{root.Header.ID}
+	visited := map[fftypes.UUID]bool{*root.Header.ID: true}
+	frontier := []driver.Value{root.Header.ID}
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		fb := database.MessageQueryFactory.NewFilterLimit(ctx, uint64(maxSize-len(threadIDs)+1))
+		replies, err := or.database().GetMessageIDs(ctx, or.namespace.Name, fb.And(fb.In("cid", frontier)).Sort("sequence"))
+		if err != nil {
+			return nil, err
+		}
+		frontier = make([]driver.Value, 0, len(replies))
+		for _, reply := range replies {
+			replyID := reply.ID
+			if !visited[replyID] {
+				visited[replyID] = true
+				frontier = append(frontier, &replyID)
+			}
+		}
+		threadIDs = append(threadIDs, frontier...)
+		if len(threadIDs) > maxSize {
+			return nil, i18n.NewError(ctx, coremsgs.MsgMessageThreadTooLarge, maxSize)
+		}
+	}
+	return threadIDs, nil
+}
+
+func (or *orchestrator) GetMessageThread(ctx context.Context, id string, depth string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
+	threadIDs, err := or.getMessageThreadIDs(ctx, id, depth)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter = filter.Condition(filter.Builder().In("id", threadIDs))
+	return or.database().GetMessages(ctx, or.namespace.Name, filter)
+}
+
+func (or *orchestrator) GetMessageThreadWithData(ctx context.Context, id string, depth string, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error) {
+	msgs, fr, err := or.GetMessageThread(ctx, id, depth, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return or.fetchMessagesData(ctx, msgs, fr)
+}
+
 func (or *orchestrator) GetBatches(ctx context.Context, filter ffapi.AndFilter) ([]*core.BatchPersisted, *ffapi.FilterResult, error) {
 	return or.database().GetBatches(ctx, or.namespace.Name, filter)
 }
@@ -263,6 +422,10 @@ func (or *orchestrator) GetData(ctx context.Context, filter ffapi.AndFilter) (co
 	return or.database().GetData(ctx, or.namespace.Name, filter)
 }
 
+func (or *orchestrator) GetDataByValue(ctx context.Context, matches []*core.JSONPathMatch, filter ffapi.AndFilter) (core.DataArray, *ffapi.FilterResult, error) {
+	return or.database().GetDataByValue(ctx, or.namespace.Name, matches, filter)
+}
+
 func (or *orchestrator) GetDataSubPaths(ctx context.Context, path string) ([]string, error) {
 	return or.database().GetDataSubPaths(ctx, or.namespace.Name, path)
 }
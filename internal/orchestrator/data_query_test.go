@@ -21,8 +21,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly-common/pkg/config"
 	"github.com/hyperledger/firefly-common/pkg/ffapi"
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/stretchr/testify/assert"
@@ -78,6 +80,88 @@ func TestGetTransactions(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetTransactionsByBlockchainID(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetTransactionsByBlockchainID", mock.Anything, "ns", "0x12345", mock.Anything).Return([]*core.Transaction{}, nil, nil)
+	fb := database.TransactionQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetTransactionsByBlockchainID(context.Background(), "0x12345", fb.And())
+	assert.NoError(t, err)
+}
+
+func TestGetBlockchainTransaction(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	tx := &core.Transaction{ID: fftypes.NewUUID(), BlockchainIDs: fftypes.FFStringArray{"0xabcde"}}
+	op := &core.Operation{ID: fftypes.NewUUID(), Transaction: tx.ID}
+	msg := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	transfer := &core.TokenTransfer{LocalID: fftypes.NewUUID()}
+	approval := &core.TokenApproval{LocalID: fftypes.NewUUID()}
+	event := &core.BlockchainEvent{ID: fftypes.NewUUID()}
+	or.mdi.On("GetTransactionsByBlockchainID", mock.Anything, "ns", "0xabcde", mock.Anything).Return([]*core.Transaction{tx}, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, "ns", mock.MatchedBy(func(f ffapi.AndFilter) bool {
+		info, _ := f.Finalize()
+		return info.String() == "( tx.blockchainid == '0xabcde' )"
+	})).Return([]*core.BlockchainEvent{event}, nil, nil)
+	or.mdi.On("GetOperations", mock.Anything, "ns", mock.Anything).Return([]*core.Operation{op}, nil, nil)
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{msg}, nil, nil)
+	or.mdi.On("GetTokenTransfers", mock.Anything, "ns", mock.Anything).Return([]*core.TokenTransfer{transfer}, nil, nil)
+	or.mdi.On("GetTokenApprovals", mock.Anything, "ns", mock.Anything).Return([]*core.TokenApproval{approval}, nil, nil)
+
+	result, err := or.GetBlockchainTransaction(context.Background(), "0xABCDE")
+	assert.NoError(t, err)
+	assert.Equal(t, "0xabcde", result.BlockchainID)
+	assert.Len(t, result.Transactions, 1)
+	assert.Equal(t, *tx.ID, *result.Transactions[0].ID)
+	assert.Equal(t, []*core.Operation{op}, result.Transactions[0].Operations)
+	assert.Equal(t, []*core.Message{msg}, result.Transactions[0].Messages)
+	assert.Equal(t, []*core.TokenTransfer{transfer}, result.Transactions[0].TokenTransfers)
+	assert.Equal(t, []*core.TokenApproval{approval}, result.Transactions[0].TokenApprovals)
+	assert.Equal(t, []*core.BlockchainEvent{event}, result.BlockchainEvents)
+}
+
+func TestGetBlockchainTransactionNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetTransactionsByBlockchainID", mock.Anything, "ns", "0x12345", mock.Anything).Return([]*core.Transaction{}, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, "ns", mock.Anything).Return([]*core.BlockchainEvent{}, nil, nil)
+	_, err := or.GetBlockchainTransaction(context.Background(), "0x12345")
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetBlockchainTransactionFailTransactions(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetTransactionsByBlockchainID", mock.Anything, "ns", "0x12345", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetBlockchainTransaction(context.Background(), "0x12345")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBlockchainTransactionFailEvents(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetTransactionsByBlockchainID", mock.Anything, "ns", "0x12345", mock.Anything).Return([]*core.Transaction{}, nil, nil)
+	or.mdi.On("GetBlockchainEvents", mock.Anything, "ns", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetBlockchainTransaction(context.Background(), "0x12345")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBlockchainTransactionFailDetail(t *testing.T) {
+	for _, failing := range []string{"GetOperations", "GetMessages", "GetTokenTransfers", "GetTokenApprovals"} {
+		or := newTestOrchestrator()
+		tx := &core.Transaction{ID: fftypes.NewUUID()}
+		or.mdi.On("GetTransactionsByBlockchainID", mock.Anything, "ns", "0x12345", mock.Anything).Return([]*core.Transaction{tx}, nil, nil)
+		or.mdi.On("GetBlockchainEvents", mock.Anything, "ns", mock.Anything).Return([]*core.BlockchainEvent{}, nil, nil)
+		or.mdi.On(failing, mock.Anything, "ns", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+		or.mdi.On("GetOperations", mock.Anything, "ns", mock.Anything).Return([]*core.Operation{}, nil, nil).Maybe()
+		or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{}, nil, nil).Maybe()
+		or.mdi.On("GetTokenTransfers", mock.Anything, "ns", mock.Anything).Return([]*core.TokenTransfer{}, nil, nil).Maybe()
+		_, err := or.GetBlockchainTransaction(context.Background(), "0x12345")
+		assert.EqualError(t, err, "pop", failing)
+		or.cleanup(t)
+	}
+}
+
 func TestGetMessageByIDBadID(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -229,6 +313,54 @@ func TestGetMessagesWithDataFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestGetMessagesByDataValue(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	matches := []*core.JSONPathMatch{{Path: "order.id", Value: "123"}}
+	or.mdi.On("GetMessagesByDataValue", mock.Anything, "ns", matches, mock.Anything).Return([]*core.Message{}, nil, nil)
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetMessagesByDataValue(context.Background(), matches, fb.And())
+	assert.NoError(t, err)
+}
+
+func TestGetMessagesByDataValueWithDataOk(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	matches := []*core.JSONPathMatch{{Path: "order.id", Value: "123"}}
+	msg := &core.Message{
+		Header: core.MessageHeader{
+			ID: fftypes.NewUUID(),
+		},
+		Data: core.DataRefs{},
+	}
+	or.mdi.On("GetMessagesByDataValue", mock.Anything, "ns", matches, mock.Anything).Return([]*core.Message{msg}, nil, nil)
+	or.mdm.On("GetMessageDataCached", mock.Anything, mock.Anything).Return(core.DataArray{}, true, nil)
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	msgs, _, err := or.GetMessagesByDataValueWithData(context.Background(), matches, fb.And())
+	assert.NoError(t, err)
+	assert.Equal(t, msg.Header.ID, msgs[0].Header.ID)
+}
+
+func TestGetMessagesByDataValueWithDataFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	matches := []*core.JSONPathMatch{{Path: "order.id", Value: "123"}}
+	or.mdi.On("GetMessagesByDataValue", mock.Anything, "ns", matches, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetMessagesByDataValueWithData(context.Background(), matches, fb.And())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetDataByValue(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	matches := []*core.JSONPathMatch{{Path: "order.id", Value: "123"}}
+	or.mdi.On("GetDataByValue", mock.Anything, "ns", matches, mock.Anything).Return(core.DataArray{}, nil, nil)
+	fb := database.DataQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetDataByValue(context.Background(), matches, fb.And())
+	assert.NoError(t, err)
+}
+
 func TestGetMessagesForData(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -248,6 +380,113 @@ func TestGetMessagesForDataBadID(t *testing.T) {
 	assert.Regexp(t, "FF00138", err)
 }
 
+func TestGetMessageThread(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	root := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	reply1 := fftypes.NewUUID()
+	reply2 := fftypes.NewUUID()
+	reply3 := fftypes.NewUUID()
+	or.mdi.On("GetMessageByID", mock.Anything, "ns", root.Header.ID).Return(root, nil)
+	or.mdi.On("GetMessageIDs", mock.Anything, "ns", mock.MatchedBy(func(filter ffapi.Filter) bool {
+		fi, _ := filter.Finalize()
+		return fi.String() == fmt.Sprintf("( cid IN ['%s'] ) sort=sequence limit=1000", root.Header.ID)
+	})).Return([]*core.IDAndSequence{{ID: *reply1}, {ID: *reply2}}, nil).Once()
+	or.mdi.On("GetMessageIDs", mock.Anything, "ns", mock.MatchedBy(func(filter ffapi.Filter) bool {
+		fi, _ := filter.Finalize()
+		return fi.String() == fmt.Sprintf("( cid IN ['%s','%s'] ) sort=sequence limit=998", reply1, reply2)
+	})).Return([]*core.IDAndSequence{{ID: *reply3}, {ID: *reply1}}, nil).Once()
+	or.mdi.On("GetMessageIDs", mock.Anything, "ns", mock.Anything).Return([]*core.IDAndSequence{}, nil).Once()
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.MatchedBy(func(filter ffapi.Filter) bool {
+		fi, _ := filter.Finalize()
+		return fi.String() == fmt.Sprintf("( state == 'confirmed' ) && ( id IN ['%s','%s','%s','%s'] )", root.Header.ID, reply1, reply2, reply3)
+	})).Return([]*core.Message{}, nil, nil)
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	_, _, err := or.GetMessageThread(context.Background(), root.Header.ID.String(), "", fb.And(fb.Eq("state", "confirmed")))
+	assert.NoError(t, err)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageThreadDepth(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	root := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	or.mdi.On("GetMessageByID", mock.Anything, "ns", root.Header.ID).Return(root, nil)
+	or.mdi.On("GetMessageIDs", mock.Anything, "ns", mock.Anything).Return([]*core.IDAndSequence{{ID: *fftypes.NewUUID()}}, nil).Once()
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{}, nil, nil)
+	f := database.MessageQueryFactory.NewFilter(context.Background()).And()
+	_, _, err := or.GetMessageThread(context.Background(), root.Header.ID.String(), "1", f)
+	assert.NoError(t, err)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetMessageThreadBadDepth(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	f := database.MessageQueryFactory.NewFilter(context.Background()).And()
+	_, _, err := or.GetMessageThread(context.Background(), fftypes.NewUUID().String(), "33", f)
+	assert.Regexp(t, "FF10499", err)
+	_, _, err = or.GetMessageThread(context.Background(), fftypes.NewUUID().String(), "-1", f)
+	assert.Regexp(t, "FF10499", err)
+	_, _, err = or.GetMessageThread(context.Background(), fftypes.NewUUID().String(), "one", f)
+	assert.Regexp(t, "FF10499", err)
+}
+
+func TestGetMessageThreadNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetMessageByID", mock.Anything, "ns", mock.Anything).Return(nil, nil)
+	f := database.MessageQueryFactory.NewFilter(context.Background()).And()
+	_, _, err := or.GetMessageThread(context.Background(), fftypes.NewUUID().String(), "", f)
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetMessageThreadGetIDsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	root := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	or.mdi.On("GetMessageByID", mock.Anything, "ns", root.Header.ID).Return(root, nil)
+	or.mdi.On("GetMessageIDs", mock.Anything, "ns", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	f := database.MessageQueryFactory.NewFilter(context.Background()).And()
+	_, _, err := or.GetMessageThread(context.Background(), root.Header.ID.String(), "", f)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageThreadTooLarge(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	config.Set(coreconfig.MessageThreadMaxSize, 2)
+	root := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	or.mdi.On("GetMessageByID", mock.Anything, "ns", root.Header.ID).Return(root, nil)
+	or.mdi.On("GetMessageIDs", mock.Anything, "ns", mock.Anything).Return([]*core.IDAndSequence{
+		{ID: *fftypes.NewUUID()}, {ID: *fftypes.NewUUID()},
+	}, nil)
+	f := database.MessageQueryFactory.NewFilter(context.Background()).And()
+	_, _, err := or.GetMessageThread(context.Background(), root.Header.ID.String(), "", f)
+	assert.Regexp(t, "FF10500", err)
+}
+
+func TestGetMessageThreadWithData(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	root := &core.Message{Header: core.MessageHeader{ID: fftypes.NewUUID()}}
+	or.mdi.On("GetMessageByID", mock.Anything, "ns", root.Header.ID).Return(root, nil)
+	or.mdi.On("GetMessages", mock.Anything, "ns", mock.Anything).Return([]*core.Message{root}, nil, nil)
+	or.mdm.On("GetMessageDataCached", mock.Anything, root).Return(core.DataArray{}, true, nil)
+	f := database.MessageQueryFactory.NewFilter(context.Background()).And()
+	msgs, _, err := or.GetMessageThreadWithData(context.Background(), root.Header.ID.String(), "0", f)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+}
+
+func TestGetMessageThreadWithDataFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	f := database.MessageQueryFactory.NewFilter(context.Background()).And()
+	_, _, err := or.GetMessageThreadWithData(context.Background(), "!bad", "", f)
+	assert.Regexp(t, "FF00138", err)
+}
+
 func TestGetMessageTransactionOk(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -860,3 +1099,63 @@ func TestGetEventsInSequenceWithReferencesWhenEnrichEventsFails(t *testing.T) {
 	_, _, err := or.GetEventsWithReferencesInSequenceRange(context.Background(), f, 0, 100)
 	assert.EqualError(t, err, "Oops...")
 }
+
+func TestDiffDatatypes(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	dt1 := &core.Datatype{Name: "dt", Version: "1"}
+	dt2 := &core.Datatype{Name: "dt", Version: "2"}
+	or.mdi.On("GetDatatypeByName", context.Background(), "ns", "dt", "1").Return(dt1, nil)
+	or.mdi.On("GetDatatypeByName", context.Background(), "ns", "dt", "2").Return(dt2, nil)
+	or.mdm.On("DiffDatatypes", context.Background(), dt1, dt2).Return(&core.DatatypeDiff{
+		Compatibility: core.DatatypeCompatibilityFull,
+	}, nil)
+	diff, err := or.DiffDatatypes(context.Background(), "dt", "1", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, core.DatatypeCompatibilityFull, diff.Compatibility)
+}
+
+func TestDiffDatatypesFromFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetDatatypeByName", context.Background(), "ns", "dt", "1").Return(nil, fmt.Errorf("pop"))
+	_, err := or.DiffDatatypes(context.Background(), "dt", "1", "2")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDiffDatatypesToFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetDatatypeByName", context.Background(), "ns", "dt", "1").Return(&core.Datatype{}, nil)
+	or.mdi.On("GetDatatypeByName", context.Background(), "ns", "dt", "2").Return(nil, fmt.Errorf("pop"))
+	_, err := or.DiffDatatypes(context.Background(), "dt", "1", "2")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDiffDatatypesFromNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetDatatypeByName", context.Background(), "ns", "dt", "1").Return(nil, nil)
+	or.mdi.On("GetDatatypeByName", context.Background(), "ns", "dt", "2").Return(&core.Datatype{}, nil)
+	_, err := or.DiffDatatypes(context.Background(), "dt", "1", "2")
+	assert.Regexp(t, "FF10496.*dt/1", err)
+}
+
+func TestDiffDatatypesToNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.mdi.On("GetDatatypeByName", context.Background(), "ns", "dt", "1").Return(&core.Datatype{}, nil)
+	or.mdi.On("GetDatatypeByName", context.Background(), "ns", "dt", "2").Return(nil, nil)
+	_, err := or.DiffDatatypes(context.Background(), "dt", "1", "2")
+	assert.Regexp(t, "FF10496.*dt/2", err)
+}
+
+func TestCheckDatatypeData(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	dt := &core.Datatype{Name: "dt", Version: "2"}
+	or.mdm.On("CheckDatatypeData", context.Background(), dt).Return(&core.DatatypeDataCheck{Compatible: true}, nil)
+	check, err := or.CheckDatatypeData(context.Background(), dt)
+	assert.NoError(t, err)
+	assert.True(t, check.Compatible)
+}
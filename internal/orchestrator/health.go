@@ -0,0 +1,94 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+type healthCheck func(ctx context.Context, namespace string) (*core.EventStreamHealth, error)
+
+type pluginProbe struct {
+	key    string
+	status *core.NamespaceStatusPlugin
+	check  healthCheck
+}
+
+type pluginHealthError struct {
+	message string
+	time    *fftypes.FFTime
+}
+
+func (or *orchestrator) GetPluginHealth(ctx context.Context) core.NamespaceStatusPlugins {
+	return or.getPlugins(ctx)
+}
+
+// probePlugins runs the health check of every plugin in parallel, each bounded by the configured timeout,
+// and records the result against the status entry of the plugin
+func (or *orchestrator) probePlugins(ctx context.Context, probes []*pluginProbe) {
+	timeout := config.GetDuration(coreconfig.HealthTimeout)
+	maxIdle := config.GetDuration(coreconfig.HealthEventStreamMaxIdle)
+	var wg sync.WaitGroup
+	for _, p := range probes {
+		wg.Add(1)
+		go func(p *pluginProbe) {
+			defer wg.Done()
+			p.status.Health = or.probePlugin(ctx, p, timeout, maxIdle)
+		}(p)
+	}
+	wg.Wait()
+}
+
+func (or *orchestrator) probePlugin(ctx context.Context, p *pluginProbe, timeout, maxIdle time.Duration) *core.PluginHealth {
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	streamHealth, err := p.check(probeCtx, or.namespace.Name)
+	health := &core.PluginHealth{
+		Healthy:     err == nil,
+		Latency:     fftypes.FFDuration(time.Since(start)),
+		Checked:     fftypes.Now(),
+		EventStream: streamHealth,
+	}
+	if streamHealth != nil {
+		if maxIdle > 0 && streamHealth.Connected && streamHealth.LastEvent != nil {
+			streamHealth.Lagging = time.Since(*streamHealth.LastEvent.Time()) > maxIdle
+		}
+		health.Healthy = health.Healthy && streamHealth.Connected && !streamHealth.Lagging
+	}
+
+	or.healthLock.Lock()
+	defer or.healthLock.Unlock()
+	if err != nil {
+		log.L(ctx).Warnf("Health probe failed for plugin '%s': %s", p.key, err)
+		or.healthErrors[p.key] = &pluginHealthError{message: err.Error(), time: health.Checked}
+	}
+	if lastError, ok := or.healthErrors[p.key]; ok {
+		health.LastError = lastError.message
+		health.LastErrorTime = lastError.time
+	}
+	return health
+}
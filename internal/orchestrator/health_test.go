@@ -0,0 +1,98 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetPluginHealthErrorRemembered(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	coreconfig.Reset()
+
+	mdi := &databasemocks.Plugin{}
+	mdi.On("Name").Return("mock-di")
+	mdi.On("CheckHealth", mock.Anything, "ns").Return(nil, fmt.Errorf("pop")).Once()
+	mdi.On("CheckHealth", mock.Anything, "ns").Return(nil, nil).Once()
+	or.plugins.Database = DatabasePlugin{Name: "database0", Plugin: mdi}
+	or.mem.On("GetPlugins").Return(mockEventPlugins)
+
+	plugins := or.GetPluginHealth(or.ctx)
+	health := plugins.Database[0].Health
+	assert.False(t, health.Healthy)
+	assert.Equal(t, "pop", health.LastError)
+	assert.NotNil(t, health.LastErrorTime)
+	assert.NotNil(t, health.Checked)
+
+	plugins = or.GetPluginHealth(or.ctx)
+	health = plugins.Database[0].Health
+	assert.True(t, health.Healthy)
+	assert.Equal(t, "pop", health.LastError)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetPluginHealthEventStream(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	coreconfig.Reset()
+	config.Set(coreconfig.HealthEventStreamMaxIdle, "1m")
+
+	lastHour := fftypes.FFTime(time.Now().Add(-1 * time.Hour))
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("Name").Return("mock-bi")
+	mbi.On("CheckHealth", mock.Anything, "ns").Return(&core.EventStreamHealth{
+		Connected: false,
+	}, nil).Once()
+	mbi.On("CheckHealth", mock.Anything, "ns").Return(&core.EventStreamHealth{
+		Connected: true,
+		LastEvent: &lastHour,
+	}, nil).Once()
+	mbi.On("CheckHealth", mock.Anything, "ns").Return(&core.EventStreamHealth{
+		Connected: true,
+		LastEvent: fftypes.Now(),
+	}, nil).Once()
+	or.plugins.Blockchain = BlockchainPlugin{Name: "blockchain0", Plugin: mbi}
+	or.mem.On("GetPlugins").Return(mockEventPlugins)
+
+	health := or.GetPluginHealth(or.ctx).Blockchain[0].Health
+	assert.False(t, health.Healthy)
+	assert.False(t, health.EventStream.Connected)
+	assert.Empty(t, health.LastError)
+
+	health = or.GetPluginHealth(or.ctx).Blockchain[0].Health
+	assert.False(t, health.Healthy)
+	assert.True(t, health.EventStream.Lagging)
+
+	health = or.GetPluginHealth(or.ctx).Blockchain[0].Health
+	assert.True(t, health.Healthy)
+	assert.False(t, health.EventStream.Lagging)
+
+	mbi.AssertExpectations(t)
+}
@@ -0,0 +1,33 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"io"
+
+	"github.com/hyperledger/firefly/internal/nsarchive"
+	"github.com/hyperledger/firefly/pkg/core"
+)
+
+func (or *orchestrator) ExportNamespace(ctx context.Context, w io.Writer) error {
+	return nsarchive.Export(ctx, or.namespace.Name, or.database(), w)
+}
+
+func (or *orchestrator) ImportNamespace(ctx context.Context, r io.Reader) (*core.NamespaceArchiveSummary, error) {
+	return nsarchive.Import(ctx, or.namespace.Name, or.database(), r)
+}
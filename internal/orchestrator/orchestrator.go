@@ -18,6 +18,7 @@ package orchestrator
 
 import (
 	"context"
+	"io"
 	"sync"
 
 	"github.com/hyperledger/firefly-common/pkg/auth"
@@ -26,6 +27,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/assets"
+	"github.com/hyperledger/firefly/internal/audit"
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/broadcast"
 	"github.com/hyperledger/firefly/internal/cache"
@@ -40,6 +42,8 @@ import (
 	"github.com/hyperledger/firefly/internal/networkmap"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/retention"
+	"github.com/hyperledger/firefly/internal/scheduler"
 	"github.com/hyperledger/firefly/internal/shareddownload"
 	"github.com/hyperledger/firefly/internal/syncasync"
 	"github.com/hyperledger/firefly/internal/txcommon"
@@ -65,6 +69,7 @@ type Orchestrator interface {
 	BatchManager() batch.Manager                // only for multiparty
 	Broadcast() broadcast.Manager               // only for multiparty
 	PrivateMessaging() privatemessaging.Manager // only for multiparty
+	Scheduler() scheduler.Manager               // only for multiparty
 	Assets() assets.Manager
 	DefinitionSender() definitions.Sender
 	Contracts() contracts.Manager
@@ -73,10 +78,12 @@ type Orchestrator interface {
 	NetworkMap() networkmap.Manager
 	Operations() operations.Manager
 	Identity() identity.Manager
+	Audit() audit.Manager
 
 	// Status
 	GetStatus(ctx context.Context) (*core.NamespaceStatus, error)
 	GetMultipartyStatus(ctx context.Context) (*core.NamespaceMultipartyStatus, error)
+	GetPluginHealth(ctx context.Context) core.NamespaceStatusPlugins
 
 	// Subscription management
 	GetSubscriptions(ctx context.Context, filter ffapi.AndFilter) ([]*core.Subscription, *ffapi.FilterResult, error)
@@ -94,21 +101,30 @@ type Orchestrator interface {
 	GetTransactionBlockchainEvents(ctx context.Context, id string) ([]*core.BlockchainEvent, *ffapi.FilterResult, error)
 	GetTransactionStatus(ctx context.Context, id string) (*core.TransactionStatus, error)
 	GetTransactions(ctx context.Context, filter ffapi.AndFilter) ([]*core.Transaction, *ffapi.FilterResult, error)
+	GetTransactionsByBlockchainID(ctx context.Context, blockchainID string, filter ffapi.AndFilter) ([]*core.Transaction, *ffapi.FilterResult, error)
+	GetBlockchainTransaction(ctx context.Context, blockchainID string) (*core.BlockchainTransaction, error)
 	GetMessageByID(ctx context.Context, id string) (*core.Message, error)
 	GetMessageByIDWithData(ctx context.Context, id string) (*core.MessageInOut, error)
 	GetMessages(ctx context.Context, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)
 	GetMessagesWithData(ctx context.Context, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error)
+	GetMessagesByDataValue(ctx context.Context, matches []*core.JSONPathMatch, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)
+	GetMessagesByDataValueWithData(ctx context.Context, matches []*core.JSONPathMatch, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error)
 	GetMessageTransaction(ctx context.Context, id string) (*core.Transaction, error)
 	GetMessageEvents(ctx context.Context, id string, filter ffapi.AndFilter) ([]*core.Event, *ffapi.FilterResult, error)
 	GetMessageData(ctx context.Context, id string) (core.DataArray, error)
 	GetMessagesForData(ctx context.Context, dataID string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)
+	GetMessageThread(ctx context.Context, id string, depth string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)
+	GetMessageThreadWithData(ctx context.Context, id string, depth string, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error)
 	GetBatchByID(ctx context.Context, id string) (*core.BatchPersisted, error)
 	GetBatches(ctx context.Context, filter ffapi.AndFilter) ([]*core.BatchPersisted, *ffapi.FilterResult, error)
 	GetDataByID(ctx context.Context, id string) (*core.Data, error)
 	GetData(ctx context.Context, filter ffapi.AndFilter) (core.DataArray, *ffapi.FilterResult, error)
+	GetDataByValue(ctx context.Context, matches []*core.JSONPathMatch, filter ffapi.AndFilter) (core.DataArray, *ffapi.FilterResult, error)
 	GetDataSubPaths(ctx context.Context, path string) ([]string, error)
 	GetDatatypeByID(ctx context.Context, id string) (*core.Datatype, error)
 	GetDatatypeByName(ctx context.Context, name, version string) (*core.Datatype, error)
+	DiffDatatypes(ctx context.Context, name, fromVersion, toVersion string) (*core.DatatypeDiff, error)
+	CheckDatatypeData(ctx context.Context, proposed *core.Datatype) (*core.DatatypeDataCheck, error)
 	GetDatatypes(ctx context.Context, filter ffapi.AndFilter) ([]*core.Datatype, *ffapi.FilterResult, error)
 	GetOperationByID(ctx context.Context, id string) (*core.Operation, error)
 	GetOperationByIDWithStatus(ctx context.Context, id string) (*core.OperationWithDetail, error)
@@ -123,6 +139,10 @@ type Orchestrator interface {
 	GetNextPins(ctx context.Context, filter ffapi.AndFilter) ([]*core.NextPin, *ffapi.FilterResult, error)
 	RewindPins(ctx context.Context, rewind *core.PinRewind) (*core.PinRewind, error)
 
+	// Namespace archive
+	ExportNamespace(ctx context.Context, w io.Writer) error
+	ImportNamespace(ctx context.Context, r io.Reader) (*core.NamespaceArchiveSummary, error)
+
 	// Charts
 	GetChartHistogram(ctx context.Context, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*core.ChartHistogram, error)
 
@@ -188,6 +208,8 @@ type Config struct {
 	Multiparty                  multiparty.Config
 	TokenBroadcastNames         map[string]string
 	MaxHistoricalEventScanLimit int
+	Retention                   retention.Config
+	Operations                  operations.Config
 }
 
 type orchestrator struct {
@@ -201,6 +223,7 @@ type orchestrator struct {
 	plugins                 *Plugins
 	multiparty              multiparty.Manager       // only for multiparty
 	batch                   batch.Manager            // only for multiparty
+	scheduler               scheduler.Manager        // only for multiparty
 	broadcast               broadcast.Manager        // only for multiparty
 	messaging               privatemessaging.Manager // only for multiparty
 	sharedDownload          shareddownload.Manager   // only for multiparty
@@ -219,6 +242,10 @@ type orchestrator struct {
 	operations              operations.Manager
 	txHelper                txcommon.Helper
 	txWriter                txwriter.Writer
+	retention               retention.Manager
+	audit                   audit.Manager
+	healthLock              sync.Mutex
+	healthErrors            map[string]*pluginHealthError
 }
 
 func NewOrchestrator(ns *core.Namespace, config Config, plugins *Plugins, metrics metrics.Manager, cacheManager cache.Manager) Orchestrator {
@@ -228,6 +255,7 @@ func NewOrchestrator(ns *core.Namespace, config Config, plugins *Plugins, metric
 		plugins:      plugins,
 		metrics:      metrics,
 		cacheManager: cacheManager,
+		healthErrors: make(map[string]*pluginHealthError),
 	}
 	or.bc.o = or
 	return or
@@ -284,6 +312,7 @@ func (or *orchestrator) Start() (err error) {
 	if or.config.Multiparty.Enabled {
 		err = or.batch.Start()
 		if err == nil {
+			or.scheduler.Start()
 			err = or.broadcast.Start()
 		}
 		if err == nil {
@@ -302,6 +331,9 @@ func (or *orchestrator) Start() (err error) {
 	if err == nil {
 		err = or.assets.Start()
 	}
+	if err == nil {
+		or.retention.Start()
+	}
 
 	or.started = true
 	return err
@@ -321,6 +353,10 @@ func (or *orchestrator) WaitStop() {
 			log.L(or.ctx).Errorf("Error purging namespace '%s' from tokens plugin '%s': %s", or.namespace.Name, t.Name, err.Error())
 		}
 	}
+	if or.scheduler != nil {
+		or.scheduler.WaitStop()
+		or.scheduler = nil
+	}
 	if or.batch != nil {
 		or.batch.WaitStop()
 		or.batch = nil
@@ -348,6 +384,10 @@ func (or *orchestrator) WaitStop() {
 	if or.txWriter != nil {
 		or.txWriter.Close()
 	}
+	if or.retention != nil {
+		or.retention.WaitStop()
+		or.retention = nil
+	}
 	or.startedLock.Lock()
 	defer or.startedLock.Unlock()
 	or.started = false
@@ -367,6 +407,10 @@ func (or *orchestrator) PrivateMessaging() privatemessaging.Manager {
 	return or.messaging
 }
 
+func (or *orchestrator) Scheduler() scheduler.Manager {
+	return or.scheduler
+}
+
 func (or *orchestrator) DefinitionSender() definitions.Sender {
 	return or.defsender
 }
@@ -403,6 +447,10 @@ func (or *orchestrator) MultiParty() multiparty.Manager {
 	return or.multiparty
 }
 
+func (or *orchestrator) Audit() audit.Manager {
+	return or.audit
+}
+
 func (or *orchestrator) Identity() identity.Manager {
 	return or.identity
 }
@@ -467,6 +515,12 @@ func (or *orchestrator) initMultiPartyComponents(ctx context.Context) (err error
 		}
 	}
 
+	if or.scheduler == nil {
+		if or.scheduler, err = scheduler.NewScheduler(ctx, or.namespace.Name, or.database(), or.data, or.batch); err != nil {
+			return err
+		}
+	}
+
 	if or.messaging == nil {
 		if or.messaging, err = privatemessaging.NewPrivateMessaging(ctx, or.namespace, or.database(), or.dataexchange(), or.blockchain(), or.identity, or.batch, or.data, or.syncasync, or.multiparty, or.metrics, or.operations, or.cacheManager); err != nil {
 			return err
@@ -484,7 +538,7 @@ func (or *orchestrator) initManagers(ctx context.Context) (err error) {
 	}
 
 	if or.operations == nil {
-		if or.operations, err = operations.NewOperationsManager(ctx, or.namespace.Name, or.database(), or.txHelper, or.cacheManager); err != nil {
+		if or.operations, err = operations.NewOperationsManager(ctx, or.namespace.Name, or.database(), or.blockchain(), or.txHelper, or.cacheManager, or.config.Operations); err != nil {
 			return err
 		}
 	}
@@ -493,6 +547,18 @@ func (or *orchestrator) initManagers(ctx context.Context) (err error) {
 		or.txWriter = txwriter.NewTransactionWriter(ctx, or.namespace.Name, or.database(), or.txHelper, or.operations)
 	}
 
+	if or.retention == nil {
+		if or.retention, err = retention.NewRetentionManager(ctx, or.namespace.Name, or.database(), or.data, or.config.Retention); err != nil {
+			return err
+		}
+	}
+
+	if or.audit == nil {
+		if or.audit, err = audit.NewAuditManager(ctx, or.namespace.Name, or.database()); err != nil {
+			return err
+		}
+	}
+
 	if or.config.Multiparty.Enabled {
 		if or.multiparty == nil {
 			or.multiparty, err = multiparty.NewMultipartyManager(or.ctx, or.namespace, or.config.Multiparty, or.database(), or.blockchain(), or.operations, or.metrics, or.txHelper)
@@ -618,7 +684,9 @@ func (or *orchestrator) SubmitNetworkAction(ctx context.Context, action *core.Ne
 func (or *orchestrator) Authorize(ctx context.Context, authReq *fftypes.AuthReq) error {
 	authReq.Namespace = or.namespace.Name
 	if or.plugins.Auth.Plugin != nil {
-		return or.plugins.Auth.Plugin.Authorize(ctx, authReq)
+		info := core.GetAuthRequestInfo(ctx)
+		info.Resolver = &authResolver{or: or}
+		return or.plugins.Auth.Plugin.Authorize(core.WithAuthRequestInfo(ctx, info), authReq)
 	}
 	return nil
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/hyperledger/firefly/internal/coreconfig"
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/mocks/assetmocks"
+	"github.com/hyperledger/firefly/mocks/auditmocks"
 	"github.com/hyperledger/firefly/mocks/batchmocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/broadcastmocks"
@@ -46,6 +47,8 @@ import (
 	"github.com/hyperledger/firefly/mocks/networkmapmocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/mocks/retentionmocks"
+	"github.com/hyperledger/firefly/mocks/schedulermocks"
 	"github.com/hyperledger/firefly/mocks/shareddownloadmocks"
 	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
 	"github.com/hyperledger/firefly/mocks/spieventsmocks"
@@ -89,6 +92,9 @@ type testOrchestrator struct {
 	mmp *multipartymocks.Manager
 	mds *definitionsmocks.Sender
 	mtw *txwritermocks.Writer
+	mrm *retentionmocks.Manager
+	mau *auditmocks.Manager
+	msm *schedulermocks.Manager
 }
 
 func (tor *testOrchestrator) cleanup(t *testing.T) {
@@ -115,6 +121,8 @@ func (tor *testOrchestrator) cleanup(t *testing.T) {
 	tor.mae.AssertExpectations(t)
 	tor.mdh.AssertExpectations(t)
 	tor.mmp.AssertExpectations(t)
+	tor.mrm.AssertExpectations(t)
+	tor.msm.AssertExpectations(t)
 }
 
 func newTestOrchestrator() *testOrchestrator {
@@ -122,9 +130,10 @@ func newTestOrchestrator() *testOrchestrator {
 	ctx, cancel := context.WithCancel(context.Background())
 	tor := &testOrchestrator{
 		orchestrator: orchestrator{
-			ctx:       ctx,
-			cancelCtx: cancel,
-			namespace: &core.Namespace{Name: "ns", NetworkName: "ns"},
+			ctx:          ctx,
+			cancelCtx:    cancel,
+			namespace:    &core.Namespace{Name: "ns", NetworkName: "ns"},
+			healthErrors: make(map[string]*pluginHealthError),
 		},
 		mdi: &databasemocks.Plugin{},
 		mdm: &datamocks.Manager{},
@@ -151,6 +160,9 @@ func newTestOrchestrator() *testOrchestrator {
 		mmp: &multipartymocks.Manager{},
 		mds: &definitionsmocks.Sender{},
 		mtw: &txwritermocks.Writer{},
+		mrm: &retentionmocks.Manager{},
+		mau: &auditmocks.Manager{},
+		msm: &schedulermocks.Manager{},
 	}
 	tor.orchestrator.multiparty = tor.mmp
 	tor.orchestrator.data = tor.mdm
@@ -168,6 +180,9 @@ func newTestOrchestrator() *testOrchestrator {
 	tor.orchestrator.sharedDownload = tor.msd
 	tor.orchestrator.txHelper = tor.mth
 	tor.orchestrator.txWriter = tor.mtw
+	tor.orchestrator.retention = tor.mrm
+	tor.orchestrator.audit = tor.mau
+	tor.orchestrator.scheduler = tor.msm
 	tor.orchestrator.defhandler = tor.mdh
 	tor.orchestrator.defsender = tor.mds
 	tor.orchestrator.config.Multiparty.Enabled = true
@@ -201,6 +216,11 @@ func newTestOrchestrator() *testOrchestrator {
 	tor.mcm.On("Name").Return("mock-cm").Maybe()
 	tor.mmi.On("Name").Return("mock-mm").Maybe()
 	tor.mmp.On("Name").Return("mock-mp").Maybe()
+	tor.mdi.On("CheckHealth", mock.Anything, "ns").Return(nil, nil).Maybe()
+	tor.mps.On("CheckHealth", mock.Anything, "ns").Return(nil, nil).Maybe()
+	tor.mbi.On("CheckHealth", mock.Anything, "ns").Return(&core.EventStreamHealth{Connected: true}, nil).Maybe()
+	tor.mdx.On("CheckHealth", mock.Anything, "ns").Return(&core.EventStreamHealth{Connected: true}, nil).Maybe()
+	tor.mti.On("CheckHealth", mock.Anything, "ns").Return(&core.EventStreamHealth{Connected: true}, nil).Maybe()
 	tor.mem.On("ResolveTransportAndCapabilities", mock.Anything, mock.Anything).Return("websockets", &events.Capabilities{}, nil).Maybe()
 	tor.mds.On("Init", mock.Anything).Maybe()
 	tor.cmi.On("GetCache", mock.Anything).Return(cache.NewUmanagedCache(tor.ctx, 100, 5*time.Minute), nil).Maybe()
@@ -247,6 +267,7 @@ func TestInitOK(t *testing.T) {
 	assert.Equal(t, or.mba, or.BatchManager())
 	assert.Equal(t, or.mbm, or.Broadcast())
 	assert.Equal(t, or.mpm, or.PrivateMessaging())
+	assert.Equal(t, or.msm, or.Scheduler())
 	assert.Equal(t, or.mds, or.DefinitionSender())
 	assert.Equal(t, or.mem, or.Events())
 	assert.Equal(t, or.mam, or.Assets())
@@ -256,6 +277,7 @@ func TestInitOK(t *testing.T) {
 	assert.Equal(t, or.mnm, or.NetworkMap())
 	assert.Equal(t, or.mmp, or.MultiParty())
 	assert.Equal(t, or.identity, or.Identity())
+	assert.Equal(t, or.mau, or.Audit())
 }
 
 func TestCacheInitFail(t *testing.T) {
@@ -378,6 +400,17 @@ func TestInitBatchComponentFail(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitSchedulerComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.plugins.Database.Plugin = nil
+	or.scheduler = nil
+	or.mbi.On("StartNamespace", mock.Anything, "ns").Return(nil)
+	or.mmp.On("ConfigureContract", mock.Anything, mock.Anything).Return(nil)
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
 func TestInitBroadcastComponentFail(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -473,6 +506,24 @@ func TestInitTXWriter(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestInitRetentionComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.plugins.Database.Plugin = nil
+	or.retention = nil
+	err := or.initManagers(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestInitAuditComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	defer or.cleanup(t)
+	or.plugins.Database.Plugin = nil
+	or.audit = nil
+	err := or.initManagers(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
 func TestStartStopOk(t *testing.T) {
 	coreconfig.Reset()
 	or := newTestOrchestrator()
@@ -485,6 +536,8 @@ func TestStartStopOk(t *testing.T) {
 	or.mom.On("Start").Return(nil)
 	or.mtw.On("Start").Return()
 	or.mam.On("Start").Return(nil)
+	or.mrm.On("Start").Return()
+	or.msm.On("Start").Return()
 	or.mba.On("WaitStop").Return(nil)
 	or.mbm.On("WaitStop").Return(nil)
 	or.mdm.On("WaitStop").Return(nil)
@@ -492,6 +545,8 @@ func TestStartStopOk(t *testing.T) {
 	or.mom.On("WaitStop").Return(nil)
 	or.mem.On("WaitStop").Return(nil)
 	or.mtw.On("Close").Return(nil)
+	or.mrm.On("WaitStop").Return()
+	or.msm.On("WaitStop").Return()
 	or.mbi.On("StopNamespace", mock.Anything, "ns").Return(nil)
 	or.mti.On("StopNamespace", mock.Anything, "ns").Return(nil)
 	err := or.Start()
@@ -508,6 +563,8 @@ func TestStartStopOk(t *testing.T) {
 	or.mom.On("Start").Return(nil)
 	or.mtw.On("Start").Return()
 	or.mam.On("Start").Return(nil)
+	or.mrm.On("Start").Return()
+	or.msm.On("Start").Return()
 	or.mba.On("WaitStop").Return(nil)
 	or.mbm.On("WaitStop").Return(nil)
 	or.mdm.On("WaitStop").Return(nil)
@@ -515,6 +572,8 @@ func TestStartStopOk(t *testing.T) {
 	or.mom.On("WaitStop").Return(nil)
 	or.mem.On("WaitStop").Return(nil)
 	or.mtw.On("Close").Return(nil)
+	or.mrm.On("WaitStop").Return()
+	or.msm.On("WaitStop").Return()
 	or.mbi.On("StopNamespace", mock.Anything, "ns").Return(fmt.Errorf("pop"))
 	or.mti.On("StopNamespace", mock.Anything, "ns").Return(fmt.Errorf("pop"))
 	err = or.Start()
@@ -602,10 +661,13 @@ func TestNetworkActionNonMultiparty(t *testing.T) {
 func TestAuthorize(t *testing.T) {
 	or := newTestOrchestrator()
 	auth := &authmocks.Plugin{}
-	auth.On("Authorize", mock.Anything, mock.Anything).Return(nil)
+	auth.On("Authorize", mock.MatchedBy(func(ctx context.Context) bool {
+		return core.GetAuthRequestInfo(ctx).Resolver != nil
+	}), mock.Anything).Return(nil)
 	or.plugins.Auth.Plugin = auth
 	err := or.Authorize(context.Background(), &fftypes.AuthReq{})
 	assert.NoError(t, err)
+	auth.AssertExpectations(t)
 }
 
 func TestAuthorizeNoPlugin(t *testing.T) {
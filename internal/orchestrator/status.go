@@ -28,48 +28,45 @@ import (
 	"github.com/hyperledger/firefly/pkg/database"
 )
 
-func (or *orchestrator) getPlugins() core.NamespaceStatusPlugins {
+func (or *orchestrator) getPlugins(ctx context.Context) core.NamespaceStatusPlugins {
+	var probes []*pluginProbe
+	addPlugin := func(category, name, pluginType string, check healthCheck) *core.NamespaceStatusPlugin {
+		status := &core.NamespaceStatusPlugin{
+			Name:       name,
+			PluginType: pluginType,
+		}
+		probes = append(probes, &pluginProbe{key: category + "/" + name, status: status, check: check})
+		return status
+	}
+
 	// Plugins can have more than one name, so they must be iterated over
 	tokensArray := make([]*core.NamespaceStatusPlugin, 0)
 	for _, plugin := range or.plugins.Tokens {
-		tokensArray = append(tokensArray, &core.NamespaceStatusPlugin{
-			Name:       plugin.Name,
-			PluginType: plugin.Plugin.Name(),
-		})
+		tokensArray = append(tokensArray, addPlugin("tokens", plugin.Name, plugin.Plugin.Name(), plugin.Plugin.CheckHealth))
 	}
 
 	blockchainsArray := make([]*core.NamespaceStatusPlugin, 0)
 	if or.plugins.Blockchain.Plugin != nil {
-		blockchainsArray = append(blockchainsArray, &core.NamespaceStatusPlugin{
-			Name:       or.plugins.Blockchain.Name,
-			PluginType: or.plugins.Blockchain.Plugin.Name(),
-		})
+		blockchainsArray = append(blockchainsArray, addPlugin("blockchain", or.plugins.Blockchain.Name, or.plugins.Blockchain.Plugin.Name(), or.plugins.Blockchain.Plugin.CheckHealth))
 	}
 
 	databasesArray := make([]*core.NamespaceStatusPlugin, 0)
 	if or.plugins.Database.Plugin != nil {
-		databasesArray = append(databasesArray, &core.NamespaceStatusPlugin{
-			Name:       or.plugins.Database.Name,
-			PluginType: or.plugins.Database.Plugin.Name(),
-		})
+		databasesArray = append(databasesArray, addPlugin("database", or.plugins.Database.Name, or.plugins.Database.Plugin.Name(), or.plugins.Database.Plugin.CheckHealth))
 	}
 
 	sharedstorageArray := make([]*core.NamespaceStatusPlugin, 0)
 	if or.plugins.SharedStorage.Plugin != nil {
-		sharedstorageArray = append(sharedstorageArray, &core.NamespaceStatusPlugin{
-			Name:       or.plugins.SharedStorage.Name,
-			PluginType: or.plugins.SharedStorage.Plugin.Name(),
-		})
+		sharedstorageArray = append(sharedstorageArray, addPlugin("sharedstorage", or.plugins.SharedStorage.Name, or.plugins.SharedStorage.Plugin.Name(), or.plugins.SharedStorage.Plugin.CheckHealth))
 	}
 
 	dataexchangeArray := make([]*core.NamespaceStatusPlugin, 0)
 	if or.plugins.DataExchange.Plugin != nil {
-		dataexchangeArray = append(dataexchangeArray, &core.NamespaceStatusPlugin{
-			Name:       or.plugins.DataExchange.Name,
-			PluginType: or.plugins.DataExchange.Plugin.Name(),
-		})
+		dataexchangeArray = append(dataexchangeArray, addPlugin("dataexchange", or.plugins.DataExchange.Name, or.plugins.DataExchange.Plugin.Name(), or.plugins.DataExchange.Plugin.CheckHealth))
 	}
 
+	or.probePlugins(ctx, probes)
+
 	return core.NamespaceStatusPlugins{
 		Blockchain:    blockchainsArray,
 		Database:      databasesArray,
@@ -85,7 +82,7 @@ func (or *orchestrator) GetStatus(ctx context.Context) (status *core.NamespaceSt
 
 	status = &core.NamespaceStatus{
 		Namespace: or.namespace,
-		Plugins:   or.getPlugins(),
+		Plugins:   or.getPlugins(ctx),
 		Multiparty: core.NamespaceStatusMultiparty{
 			Enabled: or.config.Multiparty.Enabled,
 		},
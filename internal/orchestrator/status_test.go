@@ -75,6 +75,23 @@ var (
 	}
 )
 
+func assertPlugins(t *testing.T, plugins core.NamespaceStatusPlugins) {
+	for _, list := range [][]*core.NamespaceStatusPlugin{
+		plugins.Blockchain, plugins.Database, plugins.DataExchange, plugins.SharedStorage, plugins.Tokens,
+	} {
+		for _, p := range list {
+			assert.True(t, p.Health.Healthy)
+			p.Health = nil
+		}
+	}
+	assert.ElementsMatch(t, pluginsResult.Blockchain, plugins.Blockchain)
+	assert.ElementsMatch(t, pluginsResult.Database, plugins.Database)
+	assert.ElementsMatch(t, pluginsResult.DataExchange, plugins.DataExchange)
+	assert.ElementsMatch(t, pluginsResult.Events, plugins.Events)
+	assert.ElementsMatch(t, pluginsResult.SharedStorage, plugins.SharedStorage)
+	assert.ElementsMatch(t, pluginsResult.Tokens, plugins.Tokens)
+}
+
 func TestGetStatusRegistered(t *testing.T) {
 	or := newTestOrchestrator()
 	defer or.cleanup(t)
@@ -128,12 +145,7 @@ func TestGetStatusRegistered(t *testing.T) {
 	assert.Equal(t, "0x12345", status.Org.Verifiers[0].Value)
 
 	// Plugins
-	assert.ElementsMatch(t, pluginsResult.Blockchain, status.Plugins.Blockchain)
-	assert.ElementsMatch(t, pluginsResult.Database, status.Plugins.Database)
-	assert.ElementsMatch(t, pluginsResult.DataExchange, status.Plugins.DataExchange)
-	assert.ElementsMatch(t, pluginsResult.Events, status.Plugins.Events)
-	assert.ElementsMatch(t, pluginsResult.SharedStorage, status.Plugins.SharedStorage)
-	assert.ElementsMatch(t, pluginsResult.Tokens, status.Plugins.Tokens)
+	assertPlugins(t, status.Plugins)
 
 }
 
@@ -287,12 +299,7 @@ func TestGetStatusOrgOnlyRegistered(t *testing.T) {
 	assert.False(t, status.Node.Registered)
 
 	// Plugins
-	assert.ElementsMatch(t, pluginsResult.Blockchain, status.Plugins.Blockchain)
-	assert.ElementsMatch(t, pluginsResult.Database, status.Plugins.Database)
-	assert.ElementsMatch(t, pluginsResult.DataExchange, status.Plugins.DataExchange)
-	assert.ElementsMatch(t, pluginsResult.Events, status.Plugins.Events)
-	assert.ElementsMatch(t, pluginsResult.SharedStorage, status.Plugins.SharedStorage)
-	assert.ElementsMatch(t, pluginsResult.Tokens, status.Plugins.Tokens)
+	assertPlugins(t, status.Plugins)
 }
 
 func TestGetStatusNodeError(t *testing.T) {
@@ -56,6 +56,42 @@ func (pm *privateMessaging) SendMessage(ctx context.Context, in *core.MessageInO
 	return &in.Message, err
 }
 
+// SendMessages prepares each message individually, then writes all the messages that are valid
+// in a single database transaction. A result is returned for each message, so that failures of
+// individual messages do not reject the whole request.
+func (pm *privateMessaging) SendMessages(ctx context.Context, in []*core.MessageInOut) ([]*core.MessageBulkResult, error) {
+	if len(in) > pm.maxBulkMessages {
+		return nil, i18n.NewError(ctx, coremsgs.MsgBulkMessagesTooMany, len(in), pm.maxBulkMessages)
+	}
+
+	results := make([]*core.MessageBulkResult, len(in))
+	prepared := make([]*data.NewMessage, 0, len(in))
+	preparedResults := make([]*core.MessageBulkResult, 0, len(in))
+	for i, msgIn := range in {
+		results[i] = &core.MessageBulkResult{}
+		msgIn.Header.Type = core.MessageTypePrivate
+		message := pm.NewMessage(msgIn).(*messageSender)
+		if err := message.Prepare(ctx); err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		prepared = append(prepared, message.msg)
+		preparedResults = append(preparedResults, results[i])
+	}
+
+	if len(prepared) > 0 {
+		for i, err := range pm.data.WriteNewMessages(ctx, prepared) {
+			if err != nil {
+				preparedResults[i].Error = err.Error()
+			} else {
+				preparedResults[i].Message = &prepared[i].Message.Message
+			}
+		}
+	}
+	log.L(ctx).Infof("Sent bulk private send of %d messages (%d prepared)", len(in), len(prepared))
+	return results, nil
+}
+
 func (pm *privateMessaging) RequestReply(ctx context.Context, in *core.MessageInOut) (*core.MessageInOut, error) {
 	if in.Header.Tag == "" {
 		return nil, i18n.NewError(ctx, coremsgs.MsgRequestReplyTagRequired)
@@ -104,6 +140,10 @@ func (s *messageSender) setDefaults() {
 	msg.Header.Namespace = s.mgr.namespace.NetworkName
 	msg.LocalNamespace = s.mgr.namespace.Name
 	msg.State = core.MessageStateReady
+	if msg.ScheduledAfter(fftypes.Now()) {
+		// Held back from the batch manager, until released by the scheduler
+		msg.State = core.MessageStateStaged
+	}
 	if msg.Header.Type == "" {
 		msg.Header.Type = core.MessageTypePrivate
 	}
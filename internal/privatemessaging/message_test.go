@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly/internal/batch"
@@ -171,6 +172,43 @@ func TestSendUnpinnedMessageE2EOk(t *testing.T) {
 
 }
 
+func TestSendScheduledMessage(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessagingWithMetrics(t)
+	defer cancel()
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, mock.Anything).Return(nil)
+
+	groupID := fftypes.NewRandB32()
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ResolveInlineData", pm.ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", pm.ctx, mock.Anything).Return(nil).Once()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, "ns1", groupID).Return(&core.Group{Hash: groupID}, nil)
+
+	scheduled := fftypes.FFTime(time.Now().Add(1 * time.Hour))
+	msg, err := pm.SendMessage(pm.ctx, &core.MessageInOut{
+		Message: core.Message{
+			Header: core.MessageHeader{
+				Group: groupID,
+			},
+			Scheduled: &scheduled,
+		},
+		InlineData: core.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"some": "data"}`)},
+		},
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, core.MessageStateStaged, msg.State)
+
+	mdm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+
+}
+
 func TestSendMessageBadGroup(t *testing.T) {
 
 	pm, cancel := newTestPrivateMessaging(t)
@@ -861,3 +899,77 @@ func TestNewMessageContractInvoke(t *testing.T) {
 	assert.NotNil(t, sender)
 
 }
+
+func TestSendMessagesBulk(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, mock.Anything).Return(nil)
+
+	groupID := fftypes.NewRandB32()
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ResolveInlineData", pm.ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessages", pm.ctx, mock.MatchedBy(func(newMsgs []*data.NewMessage) bool {
+		return len(newMsgs) == 2
+	})).Return([]error{nil, fmt.Errorf("pop")})
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, "ns1", groupID).Return(&core.Group{Hash: groupID}, nil)
+
+	results, err := pm.SendMessages(pm.ctx, []*core.MessageInOut{
+		{
+			Message:    core.Message{Header: core.MessageHeader{Group: groupID}},
+			InlineData: core.InlineData{{Value: fftypes.JSONAnyPtr(`{"some": "data"}`)}},
+		},
+		{
+			InlineData: core.InlineData{{Value: fftypes.JSONAnyPtr(`{"some": "data"}`)}},
+			Group:      &core.InputGroup{},
+		},
+		{
+			Message:    core.Message{Header: core.MessageHeader{Group: groupID}},
+			InlineData: core.InlineData{{Value: fftypes.JSONAnyPtr(`{"some": "data"}`)}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Empty(t, results[0].Error)
+	assert.Equal(t, core.MessageTypePrivate, results[0].Message.Header.Type)
+	assert.Nil(t, results[1].Message)
+	assert.Regexp(t, "FF00115", results[1].Error)
+	assert.Nil(t, results[2].Message)
+	assert.Regexp(t, "pop", results[2].Error)
+
+	mdm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+
+}
+
+func TestSendMessagesBulkNonePrepared(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	results, err := pm.SendMessages(pm.ctx, []*core.MessageInOut{{}})
+	assert.NoError(t, err)
+	assert.Regexp(t, "pop", results[0].Error)
+
+	mim.AssertExpectations(t)
+
+}
+
+func TestSendMessagesBulkTooMany(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	pm.maxBulkMessages = 1
+
+	_, err := pm.SendMessages(pm.ctx, []*core.MessageInOut{{}, {}})
+	assert.Regexp(t, "FF10512", err)
+
+}
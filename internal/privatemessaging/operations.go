@@ -156,6 +156,10 @@ func (pm *privateMessaging) RunOperation(ctx context.Context, op *core.PreparedO
 		if err != nil {
 			return nil, core.OpPhaseInitializing, i18n.WrapError(ctx, err, coremsgs.MsgSerializationFailed)
 		}
+		payload, err = core.CompressPayload(ctx, pm.compressionFor(data.Node), payload)
+		if err != nil {
+			return nil, core.OpPhaseInitializing, err
+		}
 		return nil, core.OpPhaseInitializing, pm.exchange.SendMessage(ctx, op.NamespacedIDString(), data.Node.Profile, localNode.Profile, payload)
 
 	default:
@@ -163,6 +167,19 @@ func (pm *privateMessaging) RunOperation(ctx context.Context, op *core.PreparedO
 	}
 }
 
+// compressionFor returns the configured compression if the recipient node advertises support for it,
+// so that batches sent to older nodes are not compressed
+func (pm *privateMessaging) compressionFor(node *core.Identity) core.CompressionType {
+	if pm.compression != core.CompressionTypeNone {
+		for _, supported := range node.Profile.GetStringArray(core.CompressionProfileKey) {
+			if supported == pm.compression.String() {
+				return pm.compression
+			}
+		}
+	}
+	return core.CompressionTypeNone
+}
+
 func (pm *privateMessaging) OnOperationUpdate(ctx context.Context, op *core.Operation, update *core.OperationUpdate) error {
 	return nil
 }
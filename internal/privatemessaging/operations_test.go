@@ -17,6 +17,7 @@ package privatemessaging
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -698,3 +699,105 @@ func TestRetrieveBSendBlobInputs(t *testing.T) {
 	n, h, d, err = retrieveSendBlobInputs(context.Background(), op)
 	assert.Regexp(t, "FF00138", err)
 }
+
+func runCompressedBatchSend(t *testing.T, pm *privateMessaging, nodeProfile fftypes.JSONObject) ([]byte, error) {
+	op := &core.Operation{ID: fftypes.NewUUID(), Namespace: "ns1"}
+	node := &core.Identity{
+		IdentityBase: core.IdentityBase{
+			ID: fftypes.NewUUID(),
+		},
+		IdentityProfile: core.IdentityProfile{
+			Profile: nodeProfile,
+		},
+	}
+	localNode := &core.Identity{
+		IdentityBase: core.IdentityBase{
+			ID: fftypes.NewUUID(),
+		},
+		IdentityProfile: core.IdentityProfile{
+			Profile: fftypes.JSONObject{
+				"id": "local1",
+			},
+		},
+	}
+	transport := &core.TransportWrapper{
+		Group: &core.Group{Hash: fftypes.NewRandB32()},
+		Batch: &core.Batch{
+			BatchHeader: core.BatchHeader{ID: fftypes.NewUUID()},
+		},
+	}
+
+	var sent []byte
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetLocalNode", context.Background()).Return(localNode, nil)
+	mdx := pm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("SendMessage", context.Background(), "ns1:"+op.ID.String(), node.Profile, localNode.Profile, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		sent = args[4].([]byte)
+	}).Maybe()
+
+	_, phase, err := pm.RunOperation(context.Background(), opSendBatch(op, node, transport))
+	assert.Equal(t, core.OpPhaseInitializing, phase)
+	if err == nil {
+		payload, err := core.DecompressPayload(context.Background(), sent, 1024)
+		assert.NoError(t, err)
+		var received *core.TransportWrapper
+		err = json.Unmarshal(payload, &received)
+		assert.NoError(t, err)
+		assert.Equal(t, transport.Batch.ID, received.Batch.ID)
+	}
+
+	mim.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+	return sent, err
+}
+
+func TestRunOperationBatchSendCompressed(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	pm.compression = core.CompressionTypeGzip
+
+	// Profiles read back from the database contain generic arrays
+	sent, err := runCompressedBatchSend(t, pm, fftypes.JSONObject{
+		"id":                       "peer1",
+		core.CompressionProfileKey: []interface{}{"gzip", "zstd"},
+	})
+	assert.NoError(t, err)
+	assert.True(t, core.IsCompressedPayload(sent))
+}
+
+func TestRunOperationBatchSendCompressionNotSupportedByPeer(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	pm.compression = core.CompressionTypeZstd
+
+	sent, err := runCompressedBatchSend(t, pm, fftypes.JSONObject{
+		"id":                       "peer1",
+		core.CompressionProfileKey: []interface{}{"gzip"},
+	})
+	assert.NoError(t, err)
+	assert.False(t, core.IsCompressedPayload(sent))
+}
+
+func TestRunOperationBatchSendCompressionDisabled(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	sent, err := runCompressedBatchSend(t, pm, fftypes.JSONObject{
+		"id":                       "peer1",
+		core.CompressionProfileKey: []interface{}{"gzip", "zstd"},
+	})
+	assert.NoError(t, err)
+	assert.False(t, core.IsCompressedPayload(sent))
+}
+
+func TestRunOperationBatchSendCompressFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	pm.compression = "lz4"
+
+	_, err := runCompressedBatchSend(t, pm, fftypes.JSONObject{
+		"id":                       "peer1",
+		core.CompressionProfileKey: []interface{}{"lz4"},
+	})
+	assert.Regexp(t, "FF10504", err)
+}
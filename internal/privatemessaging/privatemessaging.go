@@ -50,6 +50,7 @@ type Manager interface {
 
 	NewMessage(msg *core.MessageInOut) syncasync.Sender
 	SendMessage(ctx context.Context, in *core.MessageInOut, waitConfirm bool) (out *core.Message, err error)
+	SendMessages(ctx context.Context, in []*core.MessageInOut) ([]*core.MessageBulkResult, error)
 	RequestReply(ctx context.Context, request *core.MessageInOut) (reply *core.MessageInOut, err error)
 
 	// From operations.OperationHandler
@@ -71,6 +72,8 @@ type privateMessaging struct {
 	multiparty            multiparty.Manager
 	retry                 retry.Retry
 	maxBatchPayloadLength int64
+	maxBulkMessages       int
+	compression           core.CompressionType
 	metrics               metrics.Manager
 	operations            operations.Manager
 	orgFirstNodes         map[string]*core.Identity
@@ -86,6 +89,10 @@ func NewPrivateMessaging(ctx context.Context, ns *core.Namespace, di database.Pl
 	if di == nil || im == nil || dx == nil || bi == nil || ba == nil || dm == nil || mm == nil || om == nil || mult == nil {
 		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "PrivateMessaging")
 	}
+	compression, err := fftypes.FFEnumParseString(ctx, "compressiontype", config.GetString(coreconfig.PrivateMessagingBatchCompression))
+	if err != nil {
+		return nil, err
+	}
 
 	pm := &privateMessaging{
 		ctx:        ctx,
@@ -109,6 +116,8 @@ func NewPrivateMessaging(ctx context.Context, ns *core.Namespace, di database.Pl
 			Factor:       config.GetFloat64(coreconfig.PrivateMessagingRetryFactor),
 		},
 		maxBatchPayloadLength: config.GetByteSize(coreconfig.PrivateMessagingBatchPayloadLimit),
+		maxBulkMessages:       config.GetInt(coreconfig.MessageBulkMaxMessages),
+		compression:           compression,
 		metrics:               mm,
 		operations:            om,
 		orgFirstNodes:         make(map[string]*core.Identity),
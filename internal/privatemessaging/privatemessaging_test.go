@@ -125,6 +125,18 @@ func TestCacheInitFail(t *testing.T) {
 	assert.Equal(t, cacheInitError, err)
 }
 
+func TestInitBadCompression(t *testing.T) {
+	coreconfig.Reset()
+	config.Set(coreconfig.PrivateMessagingBatchCompression, "lz4")
+
+	ns := &core.Namespace{Name: "ns1", NetworkName: "ns1"}
+	_, err := NewPrivateMessaging(context.Background(), ns,
+		&databasemocks.Plugin{}, &dataexchangemocks.Plugin{}, &blockchainmocks.Plugin{}, &identitymanagermocks.Manager{},
+		&batchmocks.Manager{}, &datamocks.Manager{}, &syncasyncmocks.Bridge{}, &multipartymocks.Manager{},
+		&metricsmocks.Manager{}, &operationmocks.Manager{}, &cachemocks.Manager{})
+	assert.Regexp(t, "FF00172", err)
+}
+
 func mockRunAsGroupPassthrough(mdi *databasemocks.Plugin) {
 	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
 	rag.RunFn = func(a mock.Arguments) {
@@ -0,0 +1,83 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// archiver writes records as JSON lines to a file per collection, per retention pass:
+//
+//	<archiveDirectory>/<namespace>/<collection>-<timestamp>.jsonl
+//
+// The file is only created when the first records are written, and each batch is synced
+// to disk before the records are deleted from the database.
+type archiver struct {
+	filename string
+	file     *os.File
+}
+
+func (rm *retentionManager) newArchiver(collection string) *archiver {
+	if rm.conf.ArchiveDirectory == "" {
+		return &archiver{}
+	}
+	return &archiver{
+		filename: filepath.Join(rm.conf.ArchiveDirectory, rm.namespace,
+			fmt.Sprintf("%s-%s.jsonl", collection, time.Now().UTC().Format("20060102T150405.000000000Z"))),
+	}
+}
+
+func (a *archiver) write(ctx context.Context, records []interface{}) (err error) {
+	if a.filename == "" {
+		return nil
+	}
+	if a.file == nil {
+		if err = os.MkdirAll(filepath.Dir(a.filename), 0750); err == nil {
+			a.file, err = os.OpenFile(a.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+		}
+		if err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgRetentionArchiveFailed, len(records), a.filename)
+		}
+	}
+	encoder := json.NewEncoder(a.file)
+	for _, r := range records {
+		if err = encoder.Encode(r); err != nil {
+			return i18n.WrapError(ctx, err, coremsgs.MsgRetentionArchiveFailed, len(records), a.filename)
+		}
+	}
+	if err = a.file.Sync(); err != nil {
+		return i18n.WrapError(ctx, err, coremsgs.MsgRetentionArchiveFailed, len(records), a.filename)
+	}
+	return nil
+}
+
+func (a *archiver) close(ctx context.Context) {
+	if a.file != nil {
+		if err := a.file.Close(); err != nil {
+			log.L(ctx).Warnf("Failed to close archive file '%s': %s", a.filename, err)
+		}
+	}
+}
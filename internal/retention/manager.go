@@ -0,0 +1,469 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"context"
+	"database/sql/driver"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// Manager periodically purges records that have passed the retention age of the namespace,
+// optionally archiving them to file before deletion.
+type Manager interface {
+	Start()
+	WaitStop()
+}
+
+type Config struct {
+	MaxAge           time.Duration
+	Interval         time.Duration
+	BatchSize        int
+	ArchiveDirectory string
+}
+
+type retentionManager struct {
+	ctx       context.Context
+	cancelCtx context.CancelFunc
+	namespace string
+	database  database.Plugin
+	data      data.Manager
+	conf      Config
+	startOnce sync.Once
+	done      chan struct{}
+}
+
+func NewRetentionManager(ctx context.Context, ns string, di database.Plugin, dm data.Manager, conf Config) (Manager, error) {
+	if di == nil || dm == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "RetentionManager")
+	}
+	rm := &retentionManager{
+		namespace: ns,
+		database:  di,
+		data:      dm,
+		conf:      conf,
+		done:      make(chan struct{}),
+	}
+	rm.ctx, rm.cancelCtx = context.WithCancel(log.WithLogField(ctx, "role", "retention"))
+	return rm, nil
+}
+
+func (rm *retentionManager) Start() {
+	rm.startOnce.Do(func() {
+		if rm.conf.MaxAge <= 0 {
+			close(rm.done)
+			return
+		}
+		log.L(rm.ctx).Infof("Retention enabled maxAge=%s interval=%s archive='%s'", rm.conf.MaxAge, rm.conf.Interval, rm.conf.ArchiveDirectory)
+		go rm.pruneLoop()
+	})
+}
+
+func (rm *retentionManager) WaitStop() {
+	rm.cancelCtx()
+	rm.startOnce.Do(func() {
+		// Start was never called, so there is no loop to wait for
+		close(rm.done)
+	})
+	<-rm.done
+}
+
+func (rm *retentionManager) pruneLoop() {
+	defer close(rm.done)
+	ticker := time.NewTicker(rm.conf.Interval)
+	defer ticker.Stop()
+	for {
+		if err := rm.prune(rm.ctx); err != nil {
+			log.L(rm.ctx).Errorf("Retention pass failed (will retry in %s): %s", rm.conf.Interval, err)
+		}
+		select {
+		case <-ticker.C:
+		case <-rm.ctx.Done():
+			log.L(rm.ctx).Debugf("Retention manager exiting")
+			return
+		}
+	}
+}
+
+// prune performs a single retention pass over all collections. Events go first, as they
+// refer to the other records. Blockchain events that token transfers, approvals or balance
+// changes still refer to are kept, as those records are never removed. Transactions go last,
+// so that only transactions with no remaining records referring to them are removed.
+func (rm *retentionManager) prune(ctx context.Context) error {
+	cutoff := fftypes.FFTime(time.Now().Add(-rm.conf.MaxAge))
+	for _, pass := range []func(context.Context, *fftypes.FFTime) error{
+		rm.pruneEvents,
+		rm.pruneOperations,
+		rm.pruneMessages,
+		rm.pruneBlockchainEvents,
+		rm.pruneTransactions,
+	} {
+		if err := pass(ctx, &cutoff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneCollection repeatedly loads a batch of records, archives them, and deletes them - until
+// a batch is returned that is smaller than the batch size. The load function returns
+// the records to archive/delete, along with the number of records it chose to retain.
+func (rm *retentionManager) pruneCollection(ctx context.Context, collection string,
+	load func(ctx context.Context, skip int) (records []interface{}, ids []*fftypes.UUID, loaded int, err error),
+	del func(ctx context.Context, namespace string, ids []*fftypes.UUID) error,
+) error {
+	archive := rm.newArchiver(collection)
+	defer archive.close(ctx)
+
+	total, retained := 0, 0
+	for {
+		records, ids, loaded, err := load(ctx, retained)
+		if err != nil {
+			return err
+		}
+		retained += loaded - len(ids)
+		if len(ids) > 0 {
+			if err := archive.write(ctx, records); err != nil {
+				return err
+			}
+			if err := del(ctx, rm.namespace, ids); err != nil {
+				return err
+			}
+			total += len(ids)
+		}
+		if loaded < rm.conf.BatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+	if total > 0 {
+		log.L(ctx).Infof("Purged %d %s records older than retention age %s", total, collection, rm.conf.MaxAge)
+	}
+	return nil
+}
+
+func (rm *retentionManager) limitFilter(filter ffapi.Filter, skip int) ffapi.Filter {
+	return filter.Skip(uint64(skip)).Limit(uint64(rm.conf.BatchSize))
+}
+
+// eventSequenceLimit returns the highest event sequence that has been delivered to all durable
+// subscriptions in the namespace, so that events are never removed ahead of a subscription.
+func (rm *retentionManager) eventSequenceLimit(ctx context.Context) (int64, error) {
+	subs, _, err := rm.database.GetSubscriptions(ctx, rm.namespace, database.SubscriptionQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		return -1, err
+	}
+	var limit int64 = math.MaxInt64
+	for _, sub := range subs {
+		offset, err := rm.database.GetOffset(ctx, core.OffsetTypeSubscription, sub.ID.String())
+		if err != nil {
+			return -1, err
+		}
+		if offset == nil {
+			// Subscription has not yet established its position in the event stream
+			return -1, nil
+		}
+		if offset.Current < limit {
+			limit = offset.Current
+		}
+	}
+	return limit, nil
+}
+
+func (rm *retentionManager) pruneEvents(ctx context.Context, cutoff *fftypes.FFTime) error {
+	limit, err := rm.eventSequenceLimit(ctx)
+	if err != nil {
+		return err
+	}
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Lt("created", cutoff),
+		fb.Lte("sequence", limit),
+	).Sort("sequence")
+	return rm.pruneCollection(ctx, string(database.CollectionEvents),
+		func(ctx context.Context, skip int) ([]interface{}, []*fftypes.UUID, int, error) {
+			events, _, err := rm.database.GetEvents(ctx, rm.namespace, rm.limitFilter(filter, skip))
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			records := make([]interface{}, len(events))
+			ids := make([]*fftypes.UUID, len(events))
+			for i, e := range events {
+				records[i], ids[i] = e, e.ID
+			}
+			return records, ids, len(events), nil
+		}, rm.database.DeleteEvents)
+}
+
+func (rm *retentionManager) pruneOperations(ctx context.Context, cutoff *fftypes.FFTime) error {
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Lt("updated", cutoff),
+		fb.In("status", []driver.Value{core.OpStatusSucceeded, core.OpStatusFailed}),
+	).Sort("created")
+	return rm.pruneCollection(ctx, string(database.CollectionOperations),
+		func(ctx context.Context, skip int) ([]interface{}, []*fftypes.UUID, int, error) {
+			ops, _, err := rm.database.GetOperations(ctx, rm.namespace, rm.limitFilter(filter, skip))
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			records := make([]interface{}, len(ops))
+			ids := make([]*fftypes.UUID, len(ops))
+			for i, op := range ops {
+				records[i], ids[i] = op, op.ID
+			}
+			return records, ids, len(ops), nil
+		}, rm.database.DeleteOperations)
+}
+
+func (rm *retentionManager) pruneMessages(ctx context.Context, cutoff *fftypes.FFTime) error {
+	// Only messages that have been confirmed or rejected are removed. These have been fully processed
+	// by the aggregator, so pin sequencing is not affected.
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Lt("confirmed", cutoff),
+		fb.In("state", []driver.Value{core.MessageStateConfirmed, core.MessageStateRejected}),
+	).Sort("sequence")
+	dataArchive := rm.newArchiver(string(database.CollectionData))
+	defer dataArchive.close(ctx)
+	var dataRefs core.DataRefs
+	return rm.pruneCollection(ctx, string(database.CollectionMessages),
+		func(ctx context.Context, skip int) ([]interface{}, []*fftypes.UUID, int, error) {
+			msgs, _, err := rm.database.GetMessages(ctx, rm.namespace, rm.limitFilter(filter, skip))
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			records := make([]interface{}, len(msgs))
+			ids := make([]*fftypes.UUID, len(msgs))
+			dataRefs = dataRefs[:0]
+			for i, msg := range msgs {
+				records[i], ids[i] = msg, msg.Header.ID
+				dataRefs = append(dataRefs, msg.Data...)
+			}
+			return records, ids, len(msgs), nil
+		},
+		func(ctx context.Context, namespace string, ids []*fftypes.UUID) error {
+			if err := rm.database.DeleteMessages(ctx, namespace, ids); err != nil {
+				return err
+			}
+			return rm.pruneUnreferencedData(ctx, dataArchive, dataRefs)
+		})
+}
+
+// pruneUnreferencedData removes the data of deleted messages, once no other message refers to it.
+// Data is deleted through the data manager, so any blobs and offloaded values go with it.
+func (rm *retentionManager) pruneUnreferencedData(ctx context.Context, archive *archiver, refs core.DataRefs) error {
+	checked := make(map[fftypes.UUID]bool)
+	for _, ref := range refs {
+		if ref.ID == nil || checked[*ref.ID] {
+			continue
+		}
+		checked[*ref.ID] = true
+		msgs, _, err := rm.database.GetMessagesForData(ctx, rm.namespace, ref.ID, database.MessageQueryFactory.NewFilterLimit(ctx, 1).And())
+		if err != nil {
+			return err
+		}
+		if len(msgs) > 0 {
+			continue
+		}
+		d, err := rm.database.GetDataByID(ctx, rm.namespace, ref.ID, true)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			continue
+		}
+		if err := archive.write(ctx, []interface{}{d}); err != nil {
+			return err
+		}
+		if err := rm.data.DeleteData(ctx, d.ID.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rm *retentionManager) pruneTransactions(ctx context.Context, cutoff *fftypes.FFTime) error {
+	fb := database.TransactionQueryFactory.NewFilter(ctx)
+	filter := fb.And(fb.Lt("created", cutoff)).Sort("created")
+	return rm.pruneCollection(ctx, string(database.CollectionTransactions),
+		func(ctx context.Context, skip int) ([]interface{}, []*fftypes.UUID, int, error) {
+			txns, _, err := rm.database.GetTransactions(ctx, rm.namespace, rm.limitFilter(filter, skip))
+			if err != nil || len(txns) == 0 {
+				return nil, nil, len(txns), err
+			}
+			inUse, err := rm.transactionsInUse(ctx, txns)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			records := make([]interface{}, 0, len(txns))
+			ids := make([]*fftypes.UUID, 0, len(txns))
+			for _, tx := range txns {
+				if !inUse[*tx.ID] {
+					records = append(records, tx)
+					ids = append(ids, tx.ID)
+				}
+			}
+			return records, ids, len(txns), nil
+		}, rm.database.DeleteTransactions)
+}
+
+// transactionsInUse returns the set of transactions that are still referred to by another record
+func (rm *retentionManager) transactionsInUse(ctx context.Context, txns []*core.Transaction) (map[fftypes.UUID]bool, error) {
+	txIDs := make([]driver.Value, len(txns))
+	for i, tx := range txns {
+		txIDs[i] = tx.ID
+	}
+	inUse := make(map[fftypes.UUID]bool)
+	markInUse := func(id *fftypes.UUID) {
+		if id != nil {
+			inUse[*id] = true
+		}
+	}
+
+	ofb := database.OperationQueryFactory.NewFilter(ctx)
+	ops, _, err := rm.database.GetOperations(ctx, rm.namespace, ofb.And(ofb.In("tx", txIDs)))
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		markInUse(op.Transaction)
+	}
+
+	mfb := database.MessageQueryFactory.NewFilter(ctx)
+	msgs, _, err := rm.database.GetMessages(ctx, rm.namespace, mfb.And(mfb.In("txid", txIDs)))
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range msgs {
+		markInUse(msg.TransactionID)
+	}
+
+	tfb := database.TokenTransferQueryFactory.NewFilter(ctx)
+	transfers, _, err := rm.database.GetTokenTransfers(ctx, rm.namespace, tfb.And(tfb.In("tx.id", txIDs)))
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range transfers {
+		markInUse(transfer.TX.ID)
+	}
+
+	afb := database.TokenApprovalQueryFactory.NewFilter(ctx)
+	approvals, _, err := rm.database.GetTokenApprovals(ctx, rm.namespace, afb.And(afb.In("tx.id", txIDs)))
+	if err != nil {
+		return nil, err
+	}
+	for _, approval := range approvals {
+		markInUse(approval.TX.ID)
+	}
+
+	pfb := database.TokenPoolQueryFactory.NewFilter(ctx)
+	pools, _, err := rm.database.GetTokenPools(ctx, rm.namespace, pfb.And(pfb.In("tx.id", txIDs)))
+	if err != nil {
+		return nil, err
+	}
+	for _, pool := range pools {
+		markInUse(pool.TX.ID)
+	}
+
+	bfb := database.BlockchainEventQueryFactory.NewFilter(ctx)
+	events, _, err := rm.database.GetBlockchainEvents(ctx, rm.namespace, bfb.And(bfb.In("tx.id", txIDs)))
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		markInUse(event.TX.ID)
+	}
+	return inUse, nil
+}
+
+func (rm *retentionManager) pruneBlockchainEvents(ctx context.Context, cutoff *fftypes.FFTime) error {
+	fb := database.BlockchainEventQueryFactory.NewFilter(ctx)
+	filter := fb.And(fb.Lt("timestamp", cutoff)).Sort("timestamp")
+	return rm.pruneCollection(ctx, string(database.CollectionBlockchainEvents),
+		func(ctx context.Context, skip int) ([]interface{}, []*fftypes.UUID, int, error) {
+			events, _, err := rm.database.GetBlockchainEvents(ctx, rm.namespace, rm.limitFilter(filter, skip))
+			if err != nil || len(events) == 0 {
+				return nil, nil, len(events), err
+			}
+			inUse, err := rm.blockchainEventsInUse(ctx, events)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			records := make([]interface{}, 0, len(events))
+			ids := make([]*fftypes.UUID, 0, len(events))
+			for _, e := range events {
+				if !inUse[*e.ID] {
+					records = append(records, e)
+					ids = append(ids, e.ID)
+				}
+			}
+			return records, ids, len(events), nil
+		}, rm.database.DeleteBlockchainEvents)
+}
+
+// blockchainEventsInUse returns the set of blockchain events that are still referred to by a token record
+func (rm *retentionManager) blockchainEventsInUse(ctx context.Context, events []*core.BlockchainEvent) (map[fftypes.UUID]bool, error) {
+	eventIDs := make([]driver.Value, len(events))
+	for i, e := range events {
+		eventIDs[i] = e.ID
+	}
+	inUse := make(map[fftypes.UUID]bool)
+	markInUse := func(id *fftypes.UUID) {
+		if id != nil {
+			inUse[*id] = true
+		}
+	}
+
+	tfb := database.TokenTransferQueryFactory.NewFilter(ctx)
+	transfers, _, err := rm.database.GetTokenTransfers(ctx, rm.namespace, tfb.And(tfb.In("blockchainevent", eventIDs)))
+	if err != nil {
+		return nil, err
+	}
+	for _, transfer := range transfers {
+		markInUse(transfer.BlockchainEvent)
+	}
+
+	afb := database.TokenApprovalQueryFactory.NewFilter(ctx)
+	approvals, _, err := rm.database.GetTokenApprovals(ctx, rm.namespace, afb.And(afb.In("blockchainevent", eventIDs)))
+	if err != nil {
+		return nil, err
+	}
+	for _, approval := range approvals {
+		markInUse(approval.BlockchainEvent)
+	}
+
+	cfb := database.TokenBalanceChangeQueryFactory.NewFilter(ctx)
+	changes, _, err := rm.database.GetTokenBalanceHistory(ctx, rm.namespace, cfb.And(cfb.In("blockchainevent", eventIDs)))
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range changes {
+		markInUse(change.BlockchainEvent)
+	}
+	return inUse, nil
+}
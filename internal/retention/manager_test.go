@@ -0,0 +1,428 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retention
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRetentionManager(t *testing.T, conf Config) (*retentionManager, *databasemocks.Plugin, func()) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	rm, err := NewRetentionManager(context.Background(), "ns1", mdi, mdm, conf)
+	assert.NoError(t, err)
+	return rm.(*retentionManager), mdi, func() {
+		rm.(*retentionManager).cancelCtx()
+		mdi.AssertExpectations(t)
+		mdm.AssertExpectations(t)
+	}
+}
+
+// filterOn matches a query filter on the given field
+func filterOn(field string) interface{} {
+	return mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return strings.Contains(fi.String(), field+" IN")
+	})
+}
+
+func mockBlockchainEventsNotInUse(mdi *databasemocks.Plugin) {
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", filterOn("blockchainevent")).Return([]*core.TokenTransfer{}, nil, nil)
+	mdi.On("GetTokenApprovals", mock.Anything, "ns1", filterOn("blockchainevent")).Return([]*core.TokenApproval{}, nil, nil)
+	mdi.On("GetTokenBalanceHistory", mock.Anything, "ns1", filterOn("blockchainevent")).Return([]*core.TokenBalanceChange{}, nil, nil)
+}
+
+func testConfig() Config {
+	return Config{
+		MaxAge:    24 * time.Hour,
+		Interval:  time.Hour,
+		BatchSize: 2,
+	}
+}
+
+func TestNewRetentionManagerMissingDeps(t *testing.T) {
+	_, err := NewRetentionManager(context.Background(), "ns1", nil, nil, testConfig())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestStartDisabled(t *testing.T) {
+	rm, _, cleanup := newTestRetentionManager(t, Config{})
+	defer cleanup()
+	rm.Start()
+	rm.WaitStop()
+}
+
+func TestWaitStopNotStarted(t *testing.T) {
+	rm, _, cleanup := newTestRetentionManager(t, testConfig())
+	defer cleanup()
+	rm.WaitStop()
+	rm.Start()
+}
+
+func TestStartStop(t *testing.T) {
+	rm, mdi, cleanup := newTestRetentionManager(t, testConfig())
+	defer cleanup()
+	passed := make(chan struct{})
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		close(passed)
+	}).Once()
+	rm.Start()
+	rm.Start()
+	<-passed
+	rm.WaitStop()
+}
+
+func TestPruneAllCollections(t *testing.T) {
+	rm, mdi, cleanup := newTestRetentionManager(t, testConfig())
+	defer cleanup()
+
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}}
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(&core.Offset{Current: 100}, nil)
+
+	events := []*core.Event{{ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}}
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 2 && strings.Contains(fi.String(), "( sequence <= 100 )")
+	})).Return(events, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.Event{}, nil, nil).Once()
+	mdi.On("DeleteEvents", mock.Anything, "ns1", []*fftypes.UUID{events[0].ID, events[1].ID}).Return(nil)
+
+	ops := []*core.Operation{{ID: fftypes.NewUUID()}}
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 2
+	})).Return(ops, nil, nil).Once()
+	mdi.On("DeleteOperations", mock.Anything, "ns1", []*fftypes.UUID{ops[0].ID}).Return(nil)
+
+	// The message has three data items - one shared with another message, one already gone, and one to delete
+	data := []*core.Data{{ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}}
+	msgs := []*core.Message{{
+		Header: core.MessageHeader{ID: fftypes.NewUUID()},
+		Data:   core.DataRefs{{ID: data[0].ID}, {ID: data[1].ID}, {ID: data[2].ID}, {ID: data[2].ID}, {}},
+	}}
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 2
+	})).Return(msgs, nil, nil).Once()
+	mdi.On("DeleteMessages", mock.Anything, "ns1", []*fftypes.UUID{msgs[0].Header.ID}).Return(nil)
+	mdi.On("GetMessagesForData", mock.Anything, "ns1", data[0].ID, mock.Anything).Return([]*core.Message{{}}, nil, nil)
+	mdi.On("GetMessagesForData", mock.Anything, "ns1", data[1].ID, mock.Anything).Return([]*core.Message{}, nil, nil)
+	mdi.On("GetMessagesForData", mock.Anything, "ns1", data[2].ID, mock.Anything).Return([]*core.Message{}, nil, nil).Once()
+	mdi.On("GetDataByID", mock.Anything, "ns1", data[1].ID, true).Return(nil, nil)
+	mdi.On("GetDataByID", mock.Anything, "ns1", data[2].ID, true).Return(data[2], nil).Once()
+	mdm := rm.data.(*datamocks.Manager)
+	mdm.On("DeleteData", mock.Anything, data[2].ID.String()).Return(nil).Once()
+
+	// Six transactions - five are still referred to by another record
+	txns := []*core.Transaction{{ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()},
+		{ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}}
+	mdi.On("GetTransactions", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Skip == 0
+	})).Return(txns[0:2], nil, nil).Once()
+	mdi.On("GetTransactions", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Skip == 1
+	})).Return(txns[2:4], nil, nil).Once()
+	mdi.On("GetTransactions", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Skip == 3
+	})).Return(txns[4:6], nil, nil).Once()
+	mdi.On("GetTransactions", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Skip == 5
+	})).Return(txns[6:], nil, nil).Once()
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 0
+	})).Return([]*core.Operation{{Transaction: txns[0].ID}}, nil, nil)
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 0
+	})).Return([]*core.Message{{TransactionID: txns[2].ID}}, nil, nil)
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", filterOn("tx.id")).Return([]*core.TokenTransfer{{TX: core.TransactionRef{ID: txns[3].ID}}}, nil, nil)
+	mdi.On("GetTokenApprovals", mock.Anything, "ns1", filterOn("tx.id")).Return([]*core.TokenApproval{{TX: core.TransactionRef{ID: txns[4].ID}}}, nil, nil)
+	mdi.On("GetTokenPools", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenPool{{TX: core.TransactionRef{ID: txns[5].ID}}, {}}, nil, nil)
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 0
+	})).Return([]*core.BlockchainEvent{{TX: core.BlockchainTransactionRef{ID: txns[6].ID}}}, nil, nil)
+	mdi.On("DeleteTransactions", mock.Anything, "ns1", []*fftypes.UUID{txns[1].ID}).Return(nil)
+
+	// Four blockchain events - three are still referred to by a token record
+	bes := []*core.BlockchainEvent{{ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}}
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 2 && fi.Skip == 0
+	})).Return(bes[0:2], nil, nil).Once()
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 2 && fi.Skip == 2
+	})).Return(bes[2:], nil, nil).Once()
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 2 && fi.Skip == 3
+	})).Return([]*core.BlockchainEvent{}, nil, nil).Once()
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", filterOn("blockchainevent")).Return([]*core.TokenTransfer{{BlockchainEvent: bes[0].ID}}, nil, nil)
+	mdi.On("GetTokenApprovals", mock.Anything, "ns1", filterOn("blockchainevent")).Return([]*core.TokenApproval{{BlockchainEvent: bes[1].ID}}, nil, nil)
+	mdi.On("GetTokenBalanceHistory", mock.Anything, "ns1", filterOn("blockchainevent")).Return([]*core.TokenBalanceChange{{BlockchainEvent: bes[2].ID}, {}}, nil, nil)
+	mdi.On("DeleteBlockchainEvents", mock.Anything, "ns1", []*fftypes.UUID{bes[3].ID}).Return(nil)
+
+	err := rm.prune(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestPruneEventsSubscriptionNoOffset(t *testing.T) {
+	rm, mdi, cleanup := newTestRetentionManager(t, testConfig())
+	defer cleanup()
+
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}}
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(nil, nil)
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.MatchedBy(func(f ffapi.Filter) bool {
+		fi, _ := f.Finalize()
+		return strings.Contains(fi.String(), "( sequence <= -1 )")
+	})).Return([]*core.Event{}, nil, nil)
+
+	err := rm.pruneEvents(context.Background(), fftypes.Now())
+	assert.NoError(t, err)
+}
+
+func TestPruneEventsGetOffsetFail(t *testing.T) {
+	rm, mdi, cleanup := newTestRetentionManager(t, testConfig())
+	defer cleanup()
+
+	sub := &core.Subscription{SubscriptionRef: core.SubscriptionRef{ID: fftypes.NewUUID()}}
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{sub}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, core.OffsetTypeSubscription, sub.ID.String()).Return(nil, fmt.Errorf("pop"))
+
+	err := rm.pruneEvents(context.Background(), fftypes.Now())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneGetFail(t *testing.T) {
+	rm, mdi, cleanup := newTestRetentionManager(t, testConfig())
+	defer cleanup()
+	ctx := context.Background()
+
+	mdi.On("GetSubscriptions", mock.Anything, "ns1", mock.Anything).Return([]*core.Subscription{}, nil, nil)
+	mdi.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	mdi.On("GetTransactions", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	assert.EqualError(t, rm.prune(ctx), "pop")
+	assert.EqualError(t, rm.pruneOperations(ctx, fftypes.Now()), "pop")
+	assert.EqualError(t, rm.pruneMessages(ctx, fftypes.Now()), "pop")
+	assert.EqualError(t, rm.pruneTransactions(ctx, fftypes.Now()), "pop")
+	assert.EqualError(t, rm.pruneBlockchainEvents(ctx, fftypes.Now()), "pop")
+}
+
+func TestPruneTransactionsInUseFail(t *testing.T) {
+	rm, mdi, cleanup := newTestRetentionManager(t, testConfig())
+	defer cleanup()
+	ctx := context.Background()
+
+	txns := []*core.Transaction{{ID: fftypes.NewUUID()}}
+	mdi.On("GetTransactions", mock.Anything, "ns1", mock.Anything).Return(txns, nil, nil)
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	err := rm.pruneTransactions(ctx, fftypes.Now())
+	assert.EqualError(t, err, "pop")
+
+	mdi.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*core.Operation{}, nil, nil)
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	err = rm.pruneTransactions(ctx, fftypes.Now())
+	assert.EqualError(t, err, "pop")
+
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return([]*core.Message{}, nil, nil)
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	err = rm.pruneTransactions(ctx, fftypes.Now())
+	assert.EqualError(t, err, "pop")
+
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenTransfer{}, nil, nil)
+	mdi.On("GetTokenApprovals", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	err = rm.pruneTransactions(ctx, fftypes.Now())
+	assert.EqualError(t, err, "pop")
+
+	mdi.On("GetTokenApprovals", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenApproval{}, nil, nil)
+	mdi.On("GetTokenPools", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	err = rm.pruneTransactions(ctx, fftypes.Now())
+	assert.EqualError(t, err, "pop")
+
+	mdi.On("GetTokenPools", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenPool{}, nil, nil)
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	err = rm.pruneTransactions(ctx, fftypes.Now())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneBlockchainEventsInUseFail(t *testing.T) {
+	rm, mdi, cleanup := newTestRetentionManager(t, testConfig())
+	defer cleanup()
+	ctx := context.Background()
+
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.BlockchainEvent{{ID: fftypes.NewUUID()}}, nil, nil)
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	err := rm.pruneBlockchainEvents(ctx, fftypes.Now())
+	assert.EqualError(t, err, "pop")
+
+	mdi.On("GetTokenTransfers", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenTransfer{}, nil, nil)
+	mdi.On("GetTokenApprovals", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	err = rm.pruneBlockchainEvents(ctx, fftypes.Now())
+	assert.EqualError(t, err, "pop")
+
+	mdi.On("GetTokenApprovals", mock.Anything, "ns1", mock.Anything).Return([]*core.TokenApproval{}, nil, nil)
+	mdi.On("GetTokenBalanceHistory", mock.Anything, "ns1", mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	err = rm.pruneBlockchainEvents(ctx, fftypes.Now())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneMessagesDeleteFail(t *testing.T) {
+	rm, mdi, cleanup := newTestRetentionManager(t, testConfig())
+	defer cleanup()
+
+	mdi.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return([]*core.Message{{Header: core.MessageHeader{ID: fftypes.NewUUID()}}}, nil, nil)
+	mdi.On("DeleteMessages", mock.Anything, "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+	err := rm.pruneMessages(context.Background(), fftypes.Now())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneUnreferencedDataFail(t *testing.T) {
+	conf := testConfig()
+	conf.ArchiveDirectory = t.TempDir()
+	rm, mdi, cleanup := newTestRetentionManager(t, conf)
+	defer cleanup()
+	ctx := context.Background()
+	mdm := rm.data.(*datamocks.Manager)
+	d := &core.Data{ID: fftypes.NewUUID()}
+	refs := core.DataRefs{{ID: d.ID}}
+	archive := rm.newArchiver("data")
+	defer archive.close(ctx)
+
+	mdi.On("GetMessagesForData", mock.Anything, "ns1", d.ID, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	assert.EqualError(t, rm.pruneUnreferencedData(ctx, archive, refs), "pop")
+
+	mdi.On("GetMessagesForData", mock.Anything, "ns1", d.ID, mock.Anything).Return([]*core.Message{}, nil, nil)
+	mdi.On("GetDataByID", mock.Anything, "ns1", d.ID, true).Return(nil, fmt.Errorf("pop")).Once()
+	assert.EqualError(t, rm.pruneUnreferencedData(ctx, archive, refs), "pop")
+
+	mdi.On("GetDataByID", mock.Anything, "ns1", d.ID, true).Return(d, nil)
+	mdm.On("DeleteData", mock.Anything, d.ID.String()).Return(fmt.Errorf("pop")).Once()
+	assert.EqualError(t, rm.pruneUnreferencedData(ctx, archive, refs), "pop")
+
+	blockFile := filepath.Join(conf.ArchiveDirectory, "blocked")
+	assert.NoError(t, os.WriteFile(blockFile, []byte{}, 0640))
+	blocked := &archiver{filename: filepath.Join(blockFile, "data.jsonl")}
+	assert.Regexp(t, "FF10480", rm.pruneUnreferencedData(ctx, blocked, refs))
+}
+
+func TestPruneDeleteFail(t *testing.T) {
+	rm, mdi, cleanup := newTestRetentionManager(t, testConfig())
+	defer cleanup()
+
+	mockBlockchainEventsNotInUse(mdi)
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.BlockchainEvent{{ID: fftypes.NewUUID()}}, nil, nil)
+	mdi.On("DeleteBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+	err := rm.pruneBlockchainEvents(context.Background(), fftypes.Now())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPruneWithArchive(t *testing.T) {
+	conf := testConfig()
+	conf.ArchiveDirectory = t.TempDir()
+	rm, mdi, cleanup := newTestRetentionManager(t, conf)
+	defer cleanup()
+
+	events := []*core.BlockchainEvent{{ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}, {ID: fftypes.NewUUID()}}
+	mockBlockchainEventsNotInUse(mdi)
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return(events[0:2], nil, nil).Once()
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return(events[2:], nil, nil).Once()
+	mdi.On("DeleteBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return(nil).Twice()
+	err := rm.pruneBlockchainEvents(context.Background(), fftypes.Now())
+	assert.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(conf.ArchiveDirectory, "ns1", "blockchainevents-*.jsonl"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	f, err := os.Open(files[0])
+	assert.NoError(t, err)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	var archived []*core.BlockchainEvent
+	for scanner.Scan() {
+		var e core.BlockchainEvent
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		archived = append(archived, &e)
+	}
+	assert.Len(t, archived, 3)
+	assert.Equal(t, events[2].ID, archived[2].ID)
+}
+
+func TestPruneArchiveFail(t *testing.T) {
+	conf := testConfig()
+	conf.ArchiveDirectory = t.TempDir()
+	rm, mdi, cleanup := newTestRetentionManager(t, conf)
+	defer cleanup()
+
+	// Block the namespace directory with a file
+	err := os.WriteFile(filepath.Join(conf.ArchiveDirectory, "ns1"), []byte{}, 0640)
+	assert.NoError(t, err)
+
+	mockBlockchainEventsNotInUse(mdi)
+	mdi.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return([]*core.BlockchainEvent{{ID: fftypes.NewUUID()}}, nil, nil)
+	err = rm.pruneBlockchainEvents(context.Background(), fftypes.Now())
+	assert.Regexp(t, "FF10480", err)
+}
+
+func TestArchiveWriteFail(t *testing.T) {
+	a := &archiver{filename: filepath.Join(t.TempDir(), "test.jsonl")}
+	err := a.write(context.Background(), []interface{}{map[string]string{"a": "b"}})
+	assert.NoError(t, err)
+
+	a.file.Close()
+	err = a.write(context.Background(), []interface{}{map[string]string{"a": "b"}})
+	assert.Regexp(t, "FF10480", err)
+
+	a.close(context.Background())
+}
+
+func TestArchiveSyncFail(t *testing.T) {
+	// fsync is not supported on character devices
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	a := &archiver{filename: os.DevNull, file: f}
+	defer a.close(context.Background())
+	err = a.write(context.Background(), []interface{}{})
+	assert.Regexp(t, "FF10480", err)
+}
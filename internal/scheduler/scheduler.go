@@ -0,0 +1,196 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly-common/pkg/log"
+	"github.com/hyperledger/firefly/internal/batch"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/hyperledger/firefly/pkg/database"
+)
+
+// Manager releases messages that were submitted with a scheduled send time, once that time
+// has passed. Scheduled messages are held in the database in the staged state, so nothing is
+// lost over a restart - the next pass after startup releases any messages that became due.
+type Manager interface {
+	Start()
+	WaitStop()
+	CancelMessage(ctx context.Context, id string) (*core.Message, error)
+}
+
+type scheduler struct {
+	ctx       context.Context
+	cancelCtx context.CancelFunc
+	namespace string
+	database  database.Plugin
+	data      data.Manager
+	batch     batch.Manager
+	interval  time.Duration
+	batchSize int
+	startOnce sync.Once
+	done      chan struct{}
+}
+
+func NewScheduler(ctx context.Context, ns string, di database.Plugin, dm data.Manager, bm batch.Manager) (Manager, error) {
+	if di == nil || dm == nil || bm == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInitializationNilDepError, "Scheduler")
+	}
+	sm := &scheduler{
+		namespace: ns,
+		database:  di,
+		data:      dm,
+		batch:     bm,
+		interval:  config.GetDuration(coreconfig.MessageSchedulerInterval),
+		batchSize: config.GetInt(coreconfig.MessageSchedulerBatchSize),
+		done:      make(chan struct{}),
+	}
+	sm.ctx, sm.cancelCtx = context.WithCancel(log.WithLogField(ctx, "role", "scheduler"))
+	return sm, nil
+}
+
+func (sm *scheduler) Start() {
+	sm.startOnce.Do(func() {
+		go sm.releaseLoop()
+	})
+}
+
+func (sm *scheduler) WaitStop() {
+	sm.cancelCtx()
+	sm.startOnce.Do(func() {
+		// Start was never called, so there is no loop to wait for
+		close(sm.done)
+	})
+	<-sm.done
+}
+
+func (sm *scheduler) releaseLoop() {
+	defer close(sm.done)
+	ticker := time.NewTicker(sm.interval)
+	defer ticker.Stop()
+	for {
+		if err := sm.releaseDue(sm.ctx); err != nil {
+			log.L(sm.ctx).Errorf("Failed to release scheduled messages (will retry in %s): %s", sm.interval, err)
+		}
+		select {
+		case <-ticker.C:
+		case <-sm.ctx.Done():
+			log.L(sm.ctx).Debugf("Scheduler exiting")
+			return
+		}
+	}
+}
+
+// releaseDue moves all staged messages with a scheduled time that has passed into the ready
+// state, a page at a time, and notifies the batch manager so that it rewinds to pick them up.
+func (sm *scheduler) releaseDue(ctx context.Context) error {
+	for {
+		now := fftypes.Now()
+		fb := database.MessageQueryFactory.NewFilterLimit(ctx, uint64(sm.batchSize))
+		due, err := sm.database.GetMessageIDs(ctx, sm.namespace, fb.And(
+			fb.Eq("state", core.MessageStateStaged),
+			fb.Lte("scheduled", now),
+		).Sort("sequence"))
+		if err != nil || len(due) == 0 {
+			return err
+		}
+
+		ids := make([]driver.Value, len(due))
+		for i, entry := range due {
+			ids[i] = &entry.ID
+		}
+		// The state condition ensures we never release a message that has just been cancelled
+		fb = database.MessageQueryFactory.NewFilter(ctx)
+		update := database.MessageQueryFactory.NewUpdate(ctx).Set("state", core.MessageStateReady)
+		if _, err := sm.database.UpdateMessages(ctx, sm.namespace, fb.And(
+			fb.In("id", ids),
+			fb.Eq("state", core.MessageStateStaged),
+		), update); err != nil {
+			return err
+		}
+		for _, entry := range due {
+			sm.data.UpdateMessageStateIfCached(ctx, &entry.ID, core.MessageStateReady, nil, "")
+		}
+		log.L(ctx).Infof("Released %d scheduled messages for sending", len(due))
+
+		// The entries are in sequence order, so the first one is as far as the batch manager needs to rewind
+		select {
+		case sm.batch.NewMessages() <- due[0].Sequence:
+		case <-ctx.Done():
+			return nil
+		}
+		if len(due) < sm.batchSize {
+			return nil
+		}
+	}
+}
+
+// CancelMessage cancels a message that is waiting to be sent at its scheduled time.
+// Once released to the batch manager, a message can no longer be cancelled.
+func (sm *scheduler) CancelMessage(ctx context.Context, id string) (*core.Message, error) {
+	msgID, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := sm.database.GetMessageByID(ctx, sm.namespace, msgID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
+	}
+	if msg.State != core.MessageStateStaged || msg.Scheduled == nil {
+		return nil, i18n.NewError(ctx, coremsgs.MsgMessageNotScheduled, msg.Header.ID, msg.State)
+	}
+
+	now := fftypes.Now()
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	update := database.MessageQueryFactory.NewUpdate(ctx).
+		Set("state", core.MessageStateCancelled).
+		Set("confirmed", now)
+	if _, err := sm.database.UpdateMessages(ctx, sm.namespace, fb.And(
+		fb.Eq("id", msgID),
+		fb.Eq("state", core.MessageStateStaged),
+	), update); err != nil {
+		return nil, err
+	}
+
+	// Read back the message, to check we did not race with the message being released
+	msg, err = sm.database.GetMessageByID(ctx, sm.namespace, msgID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil {
+		return nil, i18n.NewError(ctx, coremsgs.Msg404NotFound)
+	}
+	if msg.State != core.MessageStateCancelled {
+		return nil, i18n.NewError(ctx, coremsgs.MsgMessageNotScheduled, msg.Header.ID, msg.State)
+	}
+	sm.data.UpdateMessageStateIfCached(ctx, msgID, msg.State, msg.Confirmed, "")
+	log.L(ctx).Infof("Cancelled scheduled message %s", msgID)
+	return msg, nil
+}
@@ -0,0 +1,297 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/config"
+	"github.com/hyperledger/firefly-common/pkg/ffapi"
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly/internal/coreconfig"
+	"github.com/hyperledger/firefly/mocks/batchmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testScheduler struct {
+	*scheduler
+	mdi *databasemocks.Plugin
+	mdm *datamocks.Manager
+	mbm *batchmocks.Manager
+}
+
+func newTestScheduler(t *testing.T) (*testScheduler, func()) {
+	coreconfig.Reset()
+	config.Set(coreconfig.MessageSchedulerBatchSize, 2)
+	ts := &testScheduler{
+		mdi: &databasemocks.Plugin{},
+		mdm: &datamocks.Manager{},
+		mbm: &batchmocks.Manager{},
+	}
+	sm, err := NewScheduler(context.Background(), "ns1", ts.mdi, ts.mdm, ts.mbm)
+	assert.NoError(t, err)
+	ts.scheduler = sm.(*scheduler)
+	return ts, func() {
+		ts.cancelCtx()
+		ts.mdi.AssertExpectations(t)
+		ts.mdm.AssertExpectations(t)
+		ts.mbm.AssertExpectations(t)
+	}
+}
+
+func filterString(s string) interface{} {
+	return mock.MatchedBy(func(filter ffapi.Filter) bool {
+		fi, _ := filter.Finalize()
+		return fi.String() == s
+	})
+}
+
+func TestNewSchedulerMissingDeps(t *testing.T) {
+	_, err := NewScheduler(context.Background(), "ns1", nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestStartStop(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+	passed := make(chan struct{})
+	ts.mdi.On("GetMessageIDs", mock.Anything, "ns1", mock.Anything).Return(nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		close(passed)
+	}).Once()
+	ts.Start()
+	<-passed
+	ts.Start() // no-op
+	ts.WaitStop()
+}
+
+func TestWaitStopNotStarted(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+	ts.WaitStop()
+	ts.Start() // no-op after stop
+	ts.mdi.AssertNotCalled(t, "GetMessageIDs", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReleaseDuePages(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	id1, id2, id3 := fftypes.NewUUID(), fftypes.NewUUID(), fftypes.NewUUID()
+	ts.mdi.On("GetMessageIDs", mock.Anything, "ns1", mock.Anything).Return([]*core.IDAndSequence{
+		{ID: *id1, Sequence: 10},
+		{ID: *id2, Sequence: 11},
+	}, nil).Once()
+	ts.mdi.On("GetMessageIDs", mock.Anything, "ns1", mock.Anything).Return([]*core.IDAndSequence{
+		{ID: *id3, Sequence: 12},
+	}, nil).Once()
+	ts.mdi.On("UpdateMessages", mock.Anything, "ns1",
+		filterString(fmt.Sprintf("( id IN ['%s','%s'] ) && ( state == 'staged' )", id1, id2)), mock.Anything).Return(int64(1), nil).Once()
+	ts.mdi.On("UpdateMessages", mock.Anything, "ns1",
+		filterString(fmt.Sprintf("( id IN ['%s'] ) && ( state == 'staged' )", id3)), mock.Anything).Return(int64(1), nil).Once()
+	ts.mdm.On("UpdateMessageStateIfCached", mock.Anything, mock.Anything, core.MessageStateReady, (*fftypes.FFTime)(nil), "").Return().Times(3)
+	newMessages := make(chan int64, 2)
+	ts.mbm.On("NewMessages").Return((chan<- int64)(newMessages))
+
+	err := ts.releaseDue(ts.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), <-newMessages)
+	assert.Equal(t, int64(12), <-newMessages)
+}
+
+func TestReleaseDueNone(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	ts.mdi.On("GetMessageIDs", mock.Anything, "ns1", mock.Anything).Return([]*core.IDAndSequence{}, nil)
+
+	err := ts.releaseDue(ts.ctx)
+	assert.NoError(t, err)
+}
+
+func TestReleaseDueUpdateFail(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	ts.mdi.On("GetMessageIDs", mock.Anything, "ns1", mock.Anything).Return([]*core.IDAndSequence{
+		{ID: *fftypes.NewUUID(), Sequence: 10},
+	}, nil)
+	ts.mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(int64(0), fmt.Errorf("pop"))
+
+	err := ts.releaseDue(ts.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestReleaseDueClosed(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	ts.mdi.On("GetMessageIDs", mock.Anything, "ns1", mock.Anything).Return([]*core.IDAndSequence{
+		{ID: *fftypes.NewUUID(), Sequence: 10},
+	}, nil)
+	ts.mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
+	ts.mdm.On("UpdateMessageStateIfCached", mock.Anything, mock.Anything, core.MessageStateReady, (*fftypes.FFTime)(nil), "").Return()
+	ts.mbm.On("NewMessages").Return((chan<- int64)(make(chan int64)))
+
+	ts.cancelCtx()
+	err := ts.releaseDue(ts.ctx)
+	assert.NoError(t, err)
+}
+
+func TestCancelMessageOk(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	msgID := fftypes.NewUUID()
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(&core.Message{
+		Header:    core.MessageHeader{ID: msgID},
+		State:     core.MessageStateStaged,
+		Scheduled: fftypes.Now(),
+	}, nil).Once()
+	ts.mdi.On("UpdateMessages", mock.Anything, "ns1",
+		filterString(fmt.Sprintf("( id == '%s' ) && ( state == 'staged' )", msgID)), mock.Anything).Return(int64(1), nil)
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(&core.Message{
+		Header:    core.MessageHeader{ID: msgID},
+		State:     core.MessageStateCancelled,
+		Scheduled: fftypes.Now(),
+		Confirmed: fftypes.Now(),
+	}, nil).Once()
+	ts.mdm.On("UpdateMessageStateIfCached", mock.Anything, msgID, core.MessageStateCancelled, mock.Anything, "").Return()
+
+	msg, err := ts.CancelMessage(context.Background(), msgID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, core.MessageStateCancelled, msg.State)
+}
+
+func TestCancelMessageBadID(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	_, err := ts.CancelMessage(context.Background(), "bad")
+	assert.Regexp(t, "FF00138", err)
+}
+
+func TestCancelMessageGetFail(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	msgID := fftypes.NewUUID()
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := ts.CancelMessage(context.Background(), msgID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCancelMessageNotFound(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	msgID := fftypes.NewUUID()
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(nil, nil)
+
+	_, err := ts.CancelMessage(context.Background(), msgID.String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestCancelMessageNotScheduled(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	msgID := fftypes.NewUUID()
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(&core.Message{
+		Header: core.MessageHeader{ID: msgID},
+		State:  core.MessageStateStaged,
+	}, nil)
+
+	_, err := ts.CancelMessage(context.Background(), msgID.String())
+	assert.Regexp(t, "FF10503", err)
+}
+
+func TestCancelMessageUpdateFail(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	msgID := fftypes.NewUUID()
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(&core.Message{
+		Header:    core.MessageHeader{ID: msgID},
+		State:     core.MessageStateStaged,
+		Scheduled: fftypes.Now(),
+	}, nil)
+	ts.mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(int64(0), fmt.Errorf("pop"))
+
+	_, err := ts.CancelMessage(context.Background(), msgID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCancelMessageRereadFail(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	msgID := fftypes.NewUUID()
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(&core.Message{
+		Header:    core.MessageHeader{ID: msgID},
+		State:     core.MessageStateStaged,
+		Scheduled: fftypes.Now(),
+	}, nil).Once()
+	ts.mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(nil, fmt.Errorf("pop")).Once()
+
+	_, err := ts.CancelMessage(context.Background(), msgID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCancelMessageRereadNotFound(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	msgID := fftypes.NewUUID()
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(&core.Message{
+		Header:    core.MessageHeader{ID: msgID},
+		State:     core.MessageStateStaged,
+		Scheduled: fftypes.Now(),
+	}, nil).Once()
+	ts.mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(nil, nil).Once()
+
+	_, err := ts.CancelMessage(context.Background(), msgID.String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestCancelMessageRacedWithRelease(t *testing.T) {
+	ts, cleanup := newTestScheduler(t)
+	defer cleanup()
+
+	msgID := fftypes.NewUUID()
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(&core.Message{
+		Header:    core.MessageHeader{ID: msgID},
+		State:     core.MessageStateStaged,
+		Scheduled: fftypes.Now(),
+	}, nil).Once()
+	ts.mdi.On("UpdateMessages", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(int64(1), nil)
+	ts.mdi.On("GetMessageByID", mock.Anything, "ns1", msgID).Return(&core.Message{
+		Header: core.MessageHeader{ID: msgID},
+		State:  core.MessageStateReady,
+	}, nil).Once()
+
+	_, err := ts.CancelMessage(context.Background(), msgID.String())
+	assert.Regexp(t, "FF10503.*ready", err)
+}
@@ -129,6 +129,12 @@ func (dm *downloadManager) downloadBatch(ctx context.Context, data downloadBatch
 		return nil, core.OpPhasePending, i18n.WrapError(ctx, err, coremsgs.MsgDownloadBatchMaxBytes, data.PayloadRef)
 	}
 
+	// Batches might be compressed by the sender, and the same limit applies once decompressed
+	batchBytes, err = core.DecompressPayload(ctx, batchBytes, maxReadLimit-1)
+	if err != nil {
+		return nil, core.OpPhasePending, err
+	}
+
 	// Parse and store the batch
 	batchID, err := dm.callbacks.SharedStorageBatchDownloaded(data.PayloadRef, batchBytes)
 	if err != nil {
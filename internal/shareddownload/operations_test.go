@@ -29,6 +29,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/shareddownloadmocks"
 	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -108,6 +109,54 @@ func TestDownloadBatchDownloadCallbackFailed(t *testing.T) {
 	mci.AssertExpectations(t)
 }
 
+func TestDownloadBatchDownloadCompressed(t *testing.T) {
+
+	dm, cancel := newTestDownloadManager(t)
+	defer cancel()
+
+	batchID := fftypes.NewUUID()
+	compressed, err := core.CompressPayload(dm.ctx, core.CompressionTypeZstd, []byte("some batch data"))
+	assert.NoError(t, err)
+	reader := ioutil.NopCloser(bytes.NewReader(compressed))
+
+	mss := dm.sharedstorage.(*sharedstoragemocks.Plugin)
+	mss.On("DownloadData", mock.Anything, "ref1").Return(reader, nil)
+
+	mci := dm.callbacks.(*shareddownloadmocks.Callbacks)
+	mci.On("SharedStorageBatchDownloaded", "ref1", []byte("some batch data")).Return(batchID, nil)
+
+	outputs, phase, err := dm.downloadBatch(dm.ctx, downloadBatchData{
+		PayloadRef: "ref1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, core.OpPhaseComplete, phase)
+	assert.Equal(t, batchID, outputs["batch"])
+
+	mss.AssertExpectations(t)
+	mci.AssertExpectations(t)
+}
+
+func TestDownloadBatchDownloadCompressedMaxedOut(t *testing.T) {
+
+	dm, cancel := newTestDownloadManager(t)
+	defer cancel()
+
+	dm.broadcastBatchPayloadLimit = 1
+	compressed, err := core.CompressPayload(dm.ctx, core.CompressionTypeGzip, make([]byte, 4096))
+	assert.NoError(t, err)
+	reader := ioutil.NopCloser(bytes.NewReader(compressed))
+
+	mss := dm.sharedstorage.(*sharedstoragemocks.Plugin)
+	mss.On("DownloadData", mock.Anything, "ref1").Return(reader, nil)
+
+	_, _, err = dm.downloadBatch(dm.ctx, downloadBatchData{
+		PayloadRef: "ref1",
+	})
+	assert.Regexp(t, "FF10506", err)
+
+	mss.AssertExpectations(t)
+}
+
 func TestDownloadBlobDownloadDataReadFail(t *testing.T) {
 
 	dm, cancel := newTestDownloadManager(t)
@@ -29,6 +29,7 @@ import (
 	"github.com/hyperledger/firefly-common/pkg/i18n"
 	"github.com/hyperledger/firefly-common/pkg/log"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/sharedstorage"
 )
 
@@ -80,6 +81,16 @@ func (i *IPFS) Capabilities() *sharedstorage.Capabilities {
 	return i.capabilities
 }
 
+func (i *IPFS) CheckHealth(ctx context.Context, _ string) (*core.EventStreamHealth, error) {
+	res, err := i.apiClient.R().
+		SetContext(ctx).
+		Post("/api/v0/version")
+	if err != nil || !res.IsSuccess() {
+		return nil, ffresty.WrapRestErr(ctx, res, err, coremsgs.MsgIPFSRESTErr)
+	}
+	return nil, nil
+}
+
 func (i *IPFS) UploadData(ctx context.Context, data io.Reader) (string, error) {
 	var ipfsResponse ipfsUploadResponse
 	res, err := i.apiClient.R().
@@ -104,6 +104,52 @@ func TestInit(t *testing.T) {
 	assert.NotNil(t, i.Capabilities())
 }
 
+func TestIPFSCheckHealth(t *testing.T) {
+	i := &IPFS{}
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	resetConf()
+	utConfig.SubSection(IPFSConfAPISubconf).Set(ffresty.HTTPConfigURL, "http://localhost:12345")
+	utConfig.SubSection(IPFSConfGatewaySubconf).Set(ffresty.HTTPConfigURL, "http://localhost:12345")
+	utConfig.SubSection(IPFSConfAPISubconf).Set(ffresty.HTTPCustomClient, mockedClient)
+
+	err := i.Init(context.Background(), utConfig)
+	assert.NoError(t, err)
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/api/v0/version",
+		httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{"Version": "0.20.0"}))
+
+	streamHealth, err := i.CheckHealth(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.Nil(t, streamHealth)
+}
+
+func TestIPFSCheckHealthFail(t *testing.T) {
+	i := &IPFS{}
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	resetConf()
+	utConfig.SubSection(IPFSConfAPISubconf).Set(ffresty.HTTPConfigURL, "http://localhost:12345")
+	utConfig.SubSection(IPFSConfGatewaySubconf).Set(ffresty.HTTPConfigURL, "http://localhost:12345")
+	utConfig.SubSection(IPFSConfAPISubconf).Set(ffresty.HTTPCustomClient, mockedClient)
+
+	err := i.Init(context.Background(), utConfig)
+	assert.NoError(t, err)
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/api/v0/version",
+		httpmock.NewJsonResponderOrPanic(500, map[string]interface{}{"error": "pop"}))
+
+	streamHealth, err := i.CheckHealth(context.Background(), "ns1")
+	assert.Regexp(t, "FF10136", err)
+	assert.Nil(t, streamHealth)
+}
+
 func TestIPFSUploadSuccess(t *testing.T) {
 	i := &IPFS{}
 
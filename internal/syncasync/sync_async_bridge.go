@@ -518,7 +518,7 @@ func (sa *syncAsyncBridge) eventCallback(event *core.EventDelivery) error {
 	case core.EventTypeMessageConfirmed:
 		return sa.handleMessageConfirmedEvent(event)
 
-	case core.EventTypeMessageRejected:
+	case core.EventTypeMessageRejected, core.EventTypeMessageExpired:
 		return sa.handleMessageRejectedEvent(event)
 
 	case core.EventTypeIdentityConfirmed:
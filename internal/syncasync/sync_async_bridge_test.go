@@ -192,6 +192,52 @@ func TestAwaitConfirmationRejected(t *testing.T) {
 	assert.Regexp(t, "FF10269", err)
 }
 
+func TestAwaitConfirmationExpired(t *testing.T) {
+
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	requestID := fftypes.NewUUID()
+	dataID := fftypes.NewUUID()
+
+	mse := sa.sysevents.(*systemeventmocks.EventInterface)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	mdi := sa.database.(*databasemocks.Plugin)
+	gmid := mdi.On("GetMessageByID", sa.ctx, "ns1", mock.Anything)
+	gmid.RunFn = func(a mock.Arguments) {
+		msgSent := &core.Message{}
+		msgSent.Header.ID = requestID
+		msgSent.Confirmed = fftypes.Now()
+		msgSent.State = core.MessageStateConfirmed
+		gmid.ReturnArguments = mock.Arguments{
+			msgSent, nil,
+		}
+	}
+
+	mdm := sa.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", sa.ctx, mock.Anything).Return(core.DataArray{
+		{ID: dataID, Value: fftypes.JSONAnyPtr(`"response data"`)},
+	}, true, nil)
+
+	_, err := sa.WaitForMessage(sa.ctx, requestID, func(ctx context.Context) error {
+		go func() {
+			sa.eventCallback(&core.EventDelivery{
+				EnrichedEvent: core.EnrichedEvent{
+					Event: core.Event{
+						ID:        fftypes.NewUUID(),
+						Type:      core.EventTypeMessageExpired,
+						Reference: requestID,
+						Namespace: "ns1",
+					},
+				},
+			})
+		}()
+		return nil
+	})
+	assert.Regexp(t, "FF10269", err)
+}
+
 func TestRequestReplyTimeout(t *testing.T) {
 
 	sa, cancel := newTestSyncAsyncBridge(t)
@@ -34,6 +34,7 @@ import (
 	"github.com/hyperledger/firefly-signer/pkg/abi"
 	"github.com/hyperledger/firefly-signer/pkg/ffi2abi"
 	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/hyperledger/firefly/internal/health"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/core"
 	"github.com/hyperledger/firefly/pkg/tokens"
@@ -59,6 +60,8 @@ type FFTokens struct {
 	configuredName  string
 	client          *resty.Client
 	wsconn          map[string]wsclient.WSClient
+	streamHealth    map[string]*health.StreamTracker
+	healthLock      sync.Mutex
 	wsConfig        *wsclient.WSConfig
 	retry           *retry.Retry
 	poolsToActivate map[string][]*core.TokenPool
@@ -317,6 +320,7 @@ func (ft *FFTokens) Init(ctx context.Context, cancelCtx context.CancelFunc, name
 	}
 
 	ft.wsconn = make(map[string]wsclient.WSClient)
+	ft.streamHealth = make(map[string]*health.StreamTracker)
 
 	ft.retry = &retry.Retry{
 		InitialDelay: config.GetDuration(FFTEventRetryInitialDelay),
@@ -343,14 +347,16 @@ func (ft *FFTokens) sendWSStartMsg(ctx context.Context, w wsclient.WSClient, nam
 
 func (ft *FFTokens) StartNamespace(ctx context.Context, namespace string, activePools []*core.TokenPool) (err error) {
 	if ft.wsconn[namespace] == nil {
-		ft.wsconn[namespace], err = wsclient.New(ctx, ft.wsConfig, nil, func(ctx context.Context, w wsclient.WSClient) error {
+		streamHealth := health.NewStreamTracker()
+		ft.wsconn[namespace], err = wsclient.New(ctx, ft.wsConfig, streamHealth.BeforeConnect(nil), streamHealth.AfterConnect(func(ctx context.Context, w wsclient.WSClient) error {
 			// On connect send start namespace message
 			// Will occur on reconnect as well
 			return ft.sendWSStartMsg(ctx, w, namespace)
-		})
+		}))
 		if err != nil {
 			return err
 		}
+		ft.setStreamHealth(namespace, streamHealth)
 	}
 
 	// Keep the list of pools we need to ensure are active
@@ -376,6 +382,7 @@ func (ft *FFTokens) StopNamespace(ctx context.Context, namespace string) error {
 		wsconn.Close()
 	}
 	delete(ft.wsconn, namespace)
+	ft.setStreamHealth(namespace, nil)
 
 	return nil
 }
@@ -404,6 +411,34 @@ func (ft *FFTokens) Capabilities() *tokens.Capabilities {
 	return ft.capabilities
 }
 
+func (ft *FFTokens) CheckHealth(ctx context.Context, namespace string) (*core.EventStreamHealth, error) {
+	var errRes tokenError
+	res, err := ft.client.R().SetContext(ctx).
+		SetError(&errRes).
+		Get("/api/v1/health/readiness")
+	if err != nil || !res.IsSuccess() {
+		return ft.getStreamHealth(namespace).Status(), wrapError(ctx, &errRes, res, err)
+	}
+	return ft.getStreamHealth(namespace).Status(), nil
+}
+
+// getStreamHealth looks up the stream health of a namespace under healthLock
+func (ft *FFTokens) getStreamHealth(namespace string) *health.StreamTracker {
+	ft.healthLock.Lock()
+	defer ft.healthLock.Unlock()
+	return ft.streamHealth[namespace]
+}
+
+func (ft *FFTokens) setStreamHealth(namespace string, streamHealth *health.StreamTracker) {
+	ft.healthLock.Lock()
+	defer ft.healthLock.Unlock()
+	if streamHealth == nil {
+		delete(ft.streamHealth, namespace)
+	} else {
+		ft.streamHealth[namespace] = streamHealth
+	}
+}
+
 func (ft *FFTokens) handleReceipt(ctx context.Context, data fftypes.JSONObject) {
 	l := log.L(ctx)
 
@@ -736,6 +771,7 @@ func (ft *FFTokens) handleTokenPoolActivated(ctx context.Context, data fftypes.J
 
 func (ft *FFTokens) eventLoop(namespace string) {
 	wsconn := ft.wsconn[namespace]
+	streamHealth := ft.getStreamHealth(namespace)
 	defer wsconn.Close()
 	l := log.L(ft.ctx).WithField("role", "event-loop")
 	ctx := log.WithLogger(ft.ctx, l)
@@ -750,6 +786,7 @@ func (ft *FFTokens) eventLoop(namespace string) {
 				ft.cancelCtx()
 				return
 			}
+			streamHealth.EventReceived()
 			if err := ft.handleMessageRetry(ctx, namespace, msgBytes); err != nil {
 				l.Errorf("Event loop exiting (%s). Terminating server!", err)
 				ft.cancelCtx()
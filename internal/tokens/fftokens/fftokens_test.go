@@ -138,6 +138,36 @@ func TestStopNamespace(t *testing.T) {
 	assert.Nil(t, h.wsconn["ns1"])
 }
 
+func TestCheckHealth(t *testing.T) {
+	h, toServer, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/health/readiness", httpURL),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{}))
+
+	err := h.StartNamespace(context.Background(), "ns1", []*core.TokenPool{})
+	assert.NoError(t, err)
+	<-toServer
+
+	streamHealth, err := h.CheckHealth(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.NotNil(t, streamHealth)
+}
+
+func TestCheckHealthFail(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/health/readiness", httpURL),
+		httpmock.NewJsonResponderOrPanic(503, fftypes.JSONObject{
+			"error": "Service Unavailable",
+		}))
+
+	streamHealth, err := h.CheckHealth(context.Background(), "ns1")
+	assert.Regexp(t, "FF10274.*Service Unavailable", err)
+	assert.Nil(t, streamHealth)
+}
+
 func TestInitBadTLS(t *testing.T) {
 	coreconfig.Reset()
 	h := &FFTokens{}
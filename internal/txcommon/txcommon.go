@@ -323,7 +323,7 @@ func (t *transactionHelper) InsertOrGetBlockchainEvent(ctx context.Context, even
 		return existing, nil
 	}
 	t.addBlockchainEventToCache(event)
-	return nil, nil
+	return nil, t.addBlockchainEventTXs(ctx, event)
 }
 
 func (t *transactionHelper) InsertNewBlockchainEvents(ctx context.Context, events []*core.BlockchainEvent) (inserted []*core.BlockchainEvent, err error) {
@@ -335,6 +335,9 @@ func (t *transactionHelper) InsertNewBlockchainEvents(ctx context.Context, event
 	})
 	if err == nil {
 		// happy path worked - all new events
+		if err := t.addBlockchainEventTXs(ctx, events...); err != nil {
+			return nil, err
+		}
 		return events, nil
 	}
 
@@ -371,9 +374,32 @@ func (t *transactionHelper) InsertNewBlockchainEvents(ctx context.Context, event
 		}
 	}
 
+	if err := t.addBlockchainEventTXs(ctx, inserted...); err != nil {
+		return nil, err
+	}
 	return inserted, nil
 }
 
+// addBlockchainEventTXs records the blockchain transaction of each new event against the FireFly
+// transaction it belongs to, so the FireFly transaction can be found from the blockchain transaction
+func (t *transactionHelper) addBlockchainEventTXs(ctx context.Context, events ...*core.BlockchainEvent) error {
+	for _, event := range events {
+		if event.TX.ID == nil || event.TX.BlockchainID == "" {
+			continue
+		}
+		tx, err := t.GetTransactionByIDCached(ctx, event.TX.ID)
+		if err != nil {
+			return err
+		}
+		if tx != nil {
+			if err := t.AddBlockchainTX(ctx, tx, event.TX.BlockchainID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (t *transactionHelper) FindOperationInTransaction(ctx context.Context, tx *fftypes.UUID, opType core.OpType) (*core.Operation, error) {
 	fb := database.OperationQueryFactory.NewFilter(ctx)
 	filter := fb.And(
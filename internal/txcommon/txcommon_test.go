@@ -595,6 +595,98 @@ func TestInsertGetBlockchainEventCached(t *testing.T) {
 
 }
 
+func TestInsertGetBlockchainEventAddsBlockchainTX(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	txID := fftypes.NewUUID()
+	chainEvent := &core.BlockchainEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		TX: core.BlockchainTransactionRef{
+			ID:           txID,
+			BlockchainID: "0x12345",
+		},
+	}
+	txHelper.mdi.On("InsertOrGetBlockchainEvent", ctx, chainEvent).Return(nil, nil)
+	txHelper.mdi.On("GetTransactionByID", ctx, "ns1", txID).Return(&core.Transaction{ID: txID}, nil)
+	txHelper.mdi.On("UpdateTransaction", ctx, "ns1", txID, mock.Anything).Return(nil)
+
+	_, err := txHelper.InsertOrGetBlockchainEvent(ctx, chainEvent)
+	assert.NoError(t, err)
+
+	tx, err := txHelper.GetTransactionByIDCached(ctx, txID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.FFStringArray{"0x12345"}, tx.BlockchainIDs)
+
+}
+
+func TestInsertNewBlockchainEventsAddBlockchainTXFail(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	txID := fftypes.NewUUID()
+	chainEvent := &core.BlockchainEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		TX: core.BlockchainTransactionRef{
+			ID:           txID,
+			BlockchainID: "0x12345",
+		},
+	}
+	txHelper.mdi.On("InsertBlockchainEvents", ctx, []*core.BlockchainEvent{chainEvent}, mock.Anything).Return(nil)
+	txHelper.mdi.On("GetTransactionByID", ctx, "ns1", txID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := txHelper.InsertNewBlockchainEvents(ctx, []*core.BlockchainEvent{chainEvent})
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestInsertNewBlockchainEventsFallbackAddBlockchainTXFail(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	txID := fftypes.NewUUID()
+	chainEvent := &core.BlockchainEvent{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		TX: core.BlockchainTransactionRef{
+			ID:           txID,
+			BlockchainID: "0x12345",
+		},
+	}
+	txHelper.mdi.On("InsertBlockchainEvents", ctx, []*core.BlockchainEvent{chainEvent}, mock.Anything).Return(fmt.Errorf("optimization bypass"))
+	txHelper.mdi.On("InsertOrGetBlockchainEvent", ctx, chainEvent).Return(nil, nil)
+	txHelper.mdi.On("GetTransactionByID", ctx, "ns1", txID).Return(&core.Transaction{ID: txID}, nil)
+	txHelper.mdi.On("UpdateTransaction", ctx, "ns1", txID, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := txHelper.InsertNewBlockchainEvents(ctx, []*core.BlockchainEvent{chainEvent})
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestAddBlockchainEventTXsNotFound(t *testing.T) {
+
+	txHelper, _, _ := NewTestTransactionHelper()
+	defer txHelper.cleanup(t)
+	ctx := context.Background()
+
+	txID := fftypes.NewUUID()
+	txHelper.mdi.On("GetTransactionByID", ctx, "ns1", txID).Return(nil, nil)
+
+	err := txHelper.addBlockchainEventTXs(ctx, &core.BlockchainEvent{
+		TX: core.BlockchainTransactionRef{ID: txID, BlockchainID: "0x12345"},
+	})
+	assert.NoError(t, err)
+
+}
+
 func TestInsertGetBlockchainEventDuplicate(t *testing.T) {
 
 	mdi := &databasemocks.Plugin{}
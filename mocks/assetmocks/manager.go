@@ -233,6 +233,45 @@ func (_m *Manager) GetTokenApprovals(ctx context.Context, filter ffapi.AndFilter
 	return r0, r1, r2
 }
 
+// GetTokenBalanceHistory provides a mock function with given fields: ctx, filter
+func (_m *Manager) GetTokenBalanceHistory(ctx context.Context, filter ffapi.AndFilter) ([]*core.TokenBalanceChange, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokenBalanceHistory")
+	}
+
+	var r0 []*core.TokenBalanceChange
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) ([]*core.TokenBalanceChange, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) []*core.TokenBalanceChange); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.TokenBalanceChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetTokenBalances provides a mock function with given fields: ctx, filter
 func (_m *Manager) GetTokenBalances(ctx context.Context, filter ffapi.AndFilter) ([]*core.TokenBalance, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -272,6 +311,45 @@ func (_m *Manager) GetTokenBalances(ctx context.Context, filter ffapi.AndFilter)
 	return r0, r1, r2
 }
 
+// GetTokenBalancesAsOf provides a mock function with given fields: ctx, asOf, filter
+func (_m *Manager) GetTokenBalancesAsOf(ctx context.Context, asOf *core.TokenBalancePointInTime, filter ffapi.AndFilter) ([]*core.TokenBalance, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, asOf, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokenBalancesAsOf")
+	}
+
+	var r0 []*core.TokenBalance
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.TokenBalancePointInTime, ffapi.AndFilter) ([]*core.TokenBalance, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, asOf, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.TokenBalancePointInTime, ffapi.AndFilter) []*core.TokenBalance); ok {
+		r0 = rf(ctx, asOf, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.TokenBalance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.TokenBalancePointInTime, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, asOf, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, *core.TokenBalancePointInTime, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, asOf, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetTokenConnectors provides a mock function with given fields: ctx
 func (_m *Manager) GetTokenConnectors(ctx context.Context) []*core.TokenConnector {
 	ret := _m.Called(ctx)
@@ -0,0 +1,138 @@
+// Code generated by mockery v2.40.2. DO NOT EDIT.
+
+package auditmocks
+
+import (
+	context "context"
+
+	ffapi "github.com/hyperledger/firefly-common/pkg/ffapi"
+	core "github.com/hyperledger/firefly/pkg/core"
+
+	io "io"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Manager is an autogenerated mock type for the Manager type
+type Manager struct {
+	mock.Mock
+}
+
+// ExportAuditRecords provides a mock function with given fields: ctx, w
+func (_m *Manager) ExportAuditRecords(ctx context.Context, w io.Writer) error {
+	ret := _m.Called(ctx, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportAuditRecords")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Writer) error); ok {
+		r0 = rf(ctx, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAuditRecords provides a mock function with given fields: ctx, filter
+func (_m *Manager) GetAuditRecords(ctx context.Context, filter ffapi.AndFilter) ([]*core.AuditRecord, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuditRecords")
+	}
+
+	var r0 []*core.AuditRecord
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) ([]*core.AuditRecord, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ffapi.AndFilter) []*core.AuditRecord); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.AuditRecord)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// RecordRequest provides a mock function with given fields: ctx, record
+func (_m *Manager) RecordRequest(ctx context.Context, record *core.AuditRecord) error {
+	ret := _m.Called(ctx, record)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordRequest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.AuditRecord) error); ok {
+		r0 = rf(ctx, record)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// VerifyAuditRecords provides a mock function with given fields: ctx
+func (_m *Manager) VerifyAuditRecords(ctx context.Context) (*core.AuditVerification, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyAuditRecords")
+	}
+
+	var r0 *core.AuditVerification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*core.AuditVerification, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *core.AuditVerification); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.AuditVerification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewManager creates a new instance of Manager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewManager(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Manager {
+	mock := &Manager{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
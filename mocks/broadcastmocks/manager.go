@@ -48,6 +48,36 @@ func (_m *Manager) BroadcastMessage(ctx context.Context, in *core.MessageInOut,
 	return r0, r1
 }
 
+// BroadcastMessages provides a mock function with given fields: ctx, in
+func (_m *Manager) BroadcastMessages(ctx context.Context, in []*core.MessageInOut) ([]*core.MessageBulkResult, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BroadcastMessages")
+	}
+
+	var r0 []*core.MessageBulkResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.MessageInOut) ([]*core.MessageBulkResult, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.MessageInOut) []*core.MessageBulkResult); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.MessageBulkResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*core.MessageInOut) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Name provides a mock function with given fields:
 func (_m *Manager) Name() string {
 	ret := _m.Called()
@@ -0,0 +1,119 @@
+// Code generated by mockery v2.40.2. DO NOT EDIT.
+
+package coremocks
+
+import (
+	context "context"
+
+	core "github.com/hyperledger/firefly/pkg/core"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AuthResourceResolver is an autogenerated mock type for the AuthResourceResolver type
+type AuthResourceResolver struct {
+	mock.Mock
+}
+
+// ResolveContractAPI provides a mock function with given fields: ctx, nameOrID
+func (_m *AuthResourceResolver) ResolveContractAPI(ctx context.Context, nameOrID string) (*core.ContractAPI, error) {
+	ret := _m.Called(ctx, nameOrID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveContractAPI")
+	}
+
+	var r0 *core.ContractAPI
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.ContractAPI, error)); ok {
+		return rf(ctx, nameOrID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.ContractAPI); ok {
+		r0 = rf(ctx, nameOrID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.ContractAPI)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, nameOrID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolveContractAPIForCall provides a mock function with given fields: ctx, call
+func (_m *AuthResourceResolver) ResolveContractAPIForCall(ctx context.Context, call *core.ContractCallRequest) (*core.ContractAPI, error) {
+	ret := _m.Called(ctx, call)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveContractAPIForCall")
+	}
+
+	var r0 *core.ContractAPI
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.ContractCallRequest) (*core.ContractAPI, error)); ok {
+		return rf(ctx, call)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.ContractCallRequest) *core.ContractAPI); ok {
+		r0 = rf(ctx, call)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.ContractAPI)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.ContractCallRequest) error); ok {
+		r1 = rf(ctx, call)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolveTokenPool provides a mock function with given fields: ctx, nameOrID
+func (_m *AuthResourceResolver) ResolveTokenPool(ctx context.Context, nameOrID string) (*core.TokenPool, error) {
+	ret := _m.Called(ctx, nameOrID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveTokenPool")
+	}
+
+	var r0 *core.TokenPool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.TokenPool, error)); ok {
+		return rf(ctx, nameOrID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.TokenPool); ok {
+		r0 = rf(ctx, nameOrID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.TokenPool)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, nameOrID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAuthResourceResolver creates a new instance of AuthResourceResolver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuthResourceResolver(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AuthResourceResolver {
+	mock := &AuthResourceResolver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -43,6 +43,36 @@ func (_m *Plugin) Capabilities() *database.Capabilities {
 	return r0
 }
 
+// CheckHealth provides a mock function with given fields: ctx, namespace
+func (_m *Plugin) CheckHealth(ctx context.Context, namespace string) (*core.EventStreamHealth, error) {
+	ret := _m.Called(ctx, namespace)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckHealth")
+	}
+
+	var r0 *core.EventStreamHealth
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.EventStreamHealth, error)); ok {
+		return rf(ctx, namespace)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.EventStreamHealth); ok {
+		r0 = rf(ctx, namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.EventStreamHealth)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteBlob provides a mock function with given fields: ctx, sequence
 func (_m *Plugin) DeleteBlob(ctx context.Context, sequence int64) error {
 	ret := _m.Called(ctx, sequence)
@@ -61,6 +91,24 @@ func (_m *Plugin) DeleteBlob(ctx context.Context, sequence int64) error {
 	return r0
 }
 
+// DeleteBlockchainEvents provides a mock function with given fields: ctx, namespace, ids
+func (_m *Plugin) DeleteBlockchainEvents(ctx context.Context, namespace string, ids []*fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteBlockchainEvents")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteContractAPI provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) DeleteContractAPI(ctx context.Context, namespace string, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, namespace, id)
@@ -115,6 +163,24 @@ func (_m *Plugin) DeleteData(ctx context.Context, namespace string, id *fftypes.
 	return r0
 }
 
+// DeleteEvents provides a mock function with given fields: ctx, namespace, ids
+func (_m *Plugin) DeleteEvents(ctx context.Context, namespace string, ids []*fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteEvents")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteFFI provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) DeleteFFI(ctx context.Context, namespace string, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, namespace, id)
@@ -133,6 +199,24 @@ func (_m *Plugin) DeleteFFI(ctx context.Context, namespace string, id *fftypes.U
 	return r0
 }
 
+// DeleteMessages provides a mock function with given fields: ctx, namespace, ids
+func (_m *Plugin) DeleteMessages(ctx context.Context, namespace string, ids []*fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMessages")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteNonce provides a mock function with given fields: ctx, hash
 func (_m *Plugin) DeleteNonce(ctx context.Context, hash *fftypes.Bytes32) error {
 	ret := _m.Called(ctx, hash)
@@ -169,6 +253,24 @@ func (_m *Plugin) DeleteOffset(ctx context.Context, t fftypes.FFEnum, name strin
 	return r0
 }
 
+// DeleteOperations provides a mock function with given fields: ctx, namespace, ids
+func (_m *Plugin) DeleteOperations(ctx context.Context, namespace string, ids []*fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteOperations")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteSubscriptionByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) DeleteSubscriptionByID(ctx context.Context, namespace string, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, namespace, id)
@@ -259,6 +361,63 @@ func (_m *Plugin) DeleteTokenTransfers(ctx context.Context, namespace string, po
 	return r0
 }
 
+// DeleteTransactions provides a mock function with given fields: ctx, namespace, ids
+func (_m *Plugin) DeleteTransactions(ctx context.Context, namespace string, ids []*fftypes.UUID) error {
+	ret := _m.Called(ctx, namespace, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTransactions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.UUID) error); ok {
+		r0 = rf(ctx, namespace, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAuditRecords provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetAuditRecords(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.AuditRecord, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuditRecords")
+	}
+
+	var r0 []*core.AuditRecord
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.AuditRecord, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.AuditRecord); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.AuditRecord)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetBatchByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) GetBatchByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.BatchPersisted, error) {
 	ret := _m.Called(ctx, namespace, id)
@@ -883,6 +1042,45 @@ func (_m *Plugin) GetDataByID(ctx context.Context, namespace string, id *fftypes
 	return r0, r1
 }
 
+// GetDataByValue provides a mock function with given fields: ctx, namespace, matches, filter
+func (_m *Plugin) GetDataByValue(ctx context.Context, namespace string, matches []*core.JSONPathMatch, filter ffapi.Filter) (core.DataArray, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, matches, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDataByValue")
+	}
+
+	var r0 core.DataArray
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*core.JSONPathMatch, ffapi.Filter) (core.DataArray, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, matches, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*core.JSONPathMatch, ffapi.Filter) core.DataArray); ok {
+		r0 = rf(ctx, namespace, matches, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(core.DataArray)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []*core.JSONPathMatch, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, matches, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, []*core.JSONPathMatch, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, matches, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetDataRefs provides a mock function with given fields: ctx, namespace, filter
 func (_m *Plugin) GetDataRefs(ctx context.Context, namespace string, filter ffapi.Filter) (core.DataRefs, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, namespace, filter)
@@ -1051,6 +1249,36 @@ func (_m *Plugin) GetDatatypes(ctx context.Context, namespace string, filter ffa
 	return r0, r1, r2
 }
 
+// GetDynamicNamespaces provides a mock function with given fields: ctx
+func (_m *Plugin) GetDynamicNamespaces(ctx context.Context) ([]*core.Namespace, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDynamicNamespaces")
+	}
+
+	var r0 []*core.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*core.Namespace, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*core.Namespace); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetEventByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) GetEventByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.Event, error) {
 	ret := _m.Called(ctx, namespace, id)
@@ -1663,6 +1891,36 @@ func (_m *Plugin) GetIdentityByName(ctx context.Context, iType fftypes.FFEnum, n
 	return r0, r1
 }
 
+// GetLastAuditRecord provides a mock function with given fields: ctx, namespace
+func (_m *Plugin) GetLastAuditRecord(ctx context.Context, namespace string) (*core.AuditRecord, error) {
+	ret := _m.Called(ctx, namespace)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLastAuditRecord")
+	}
+
+	var r0 *core.AuditRecord
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.AuditRecord, error)); ok {
+		return rf(ctx, namespace)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.AuditRecord); ok {
+		r0 = rf(ctx, namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.AuditRecord)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMessageByID provides a mock function with given fields: ctx, namespace, id
 func (_m *Plugin) GetMessageByID(ctx context.Context, namespace string, id *fftypes.UUID) (*core.Message, error) {
 	ret := _m.Called(ctx, namespace, id)
@@ -1762,6 +2020,45 @@ func (_m *Plugin) GetMessages(ctx context.Context, namespace string, filter ffap
 	return r0, r1, r2
 }
 
+// GetMessagesByDataValue provides a mock function with given fields: ctx, namespace, matches, filter
+func (_m *Plugin) GetMessagesByDataValue(ctx context.Context, namespace string, matches []*core.JSONPathMatch, filter ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, matches, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessagesByDataValue")
+	}
+
+	var r0 []*core.Message
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*core.JSONPathMatch, ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, matches, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*core.JSONPathMatch, ffapi.Filter) []*core.Message); ok {
+		r0 = rf(ctx, namespace, matches, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []*core.JSONPathMatch, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, matches, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, []*core.JSONPathMatch, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, matches, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetMessagesForData provides a mock function with given fields: ctx, namespace, dataID, filter
 func (_m *Plugin) GetMessagesForData(ctx context.Context, namespace string, dataID *fftypes.UUID, filter ffapi.Filter) ([]*core.Message, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, namespace, dataID, filter)
@@ -2452,6 +2749,45 @@ func (_m *Plugin) GetTokenBalance(ctx context.Context, namespace string, poolID
 	return r0, r1
 }
 
+// GetTokenBalanceHistory provides a mock function with given fields: ctx, namespace, filter
+func (_m *Plugin) GetTokenBalanceHistory(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.TokenBalanceChange, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokenBalanceHistory")
+	}
+
+	var r0 []*core.TokenBalanceChange
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) ([]*core.TokenBalanceChange, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter) []*core.TokenBalanceChange); ok {
+		r0 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.TokenBalanceChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetTokenBalances provides a mock function with given fields: ctx, namespace, filter
 func (_m *Plugin) GetTokenBalances(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.TokenBalance, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, namespace, filter)
@@ -2491,6 +2827,45 @@ func (_m *Plugin) GetTokenBalances(ctx context.Context, namespace string, filter
 	return r0, r1, r2
 }
 
+// GetTokenBalancesAsOf provides a mock function with given fields: ctx, namespace, asOf, filter
+func (_m *Plugin) GetTokenBalancesAsOf(ctx context.Context, namespace string, asOf *core.TokenBalancePointInTime, filter ffapi.Filter) ([]*core.TokenBalance, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, asOf, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTokenBalancesAsOf")
+	}
+
+	var r0 []*core.TokenBalance
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.TokenBalancePointInTime, ffapi.Filter) ([]*core.TokenBalance, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, asOf, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.TokenBalancePointInTime, ffapi.Filter) []*core.TokenBalance); ok {
+		r0 = rf(ctx, namespace, asOf, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.TokenBalance)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *core.TokenBalancePointInTime, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, asOf, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, *core.TokenBalancePointInTime, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, asOf, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetTokenPool provides a mock function with given fields: ctx, namespace, name
 func (_m *Plugin) GetTokenPool(ctx context.Context, namespace string, name string) (*core.TokenPool, error) {
 	ret := _m.Called(ctx, namespace, name)
@@ -2788,6 +3163,45 @@ func (_m *Plugin) GetTransactions(ctx context.Context, namespace string, filter
 	return r0, r1, r2
 }
 
+// GetTransactionsByBlockchainID provides a mock function with given fields: ctx, namespace, blockchainID, filter
+func (_m *Plugin) GetTransactionsByBlockchainID(ctx context.Context, namespace string, blockchainID string, filter ffapi.Filter) ([]*core.Transaction, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, namespace, blockchainID, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransactionsByBlockchainID")
+	}
+
+	var r0 []*core.Transaction
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, ffapi.Filter) ([]*core.Transaction, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, namespace, blockchainID, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, ffapi.Filter) []*core.Transaction); ok {
+		r0 = rf(ctx, namespace, blockchainID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, ffapi.Filter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, namespace, blockchainID, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, ffapi.Filter) error); ok {
+		r2 = rf(ctx, namespace, blockchainID, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetVerifierByHash provides a mock function with given fields: ctx, namespace, hash
 func (_m *Plugin) GetVerifierByHash(ctx context.Context, namespace string, hash *fftypes.Bytes32) (*core.Verifier, error) {
 	ret := _m.Called(ctx, namespace, hash)
@@ -2910,6 +3324,24 @@ func (_m *Plugin) InitConfig(_a0 config.Section) {
 	_m.Called(_a0)
 }
 
+// InsertAuditRecord provides a mock function with given fields: ctx, record
+func (_m *Plugin) InsertAuditRecord(ctx context.Context, record *core.AuditRecord) error {
+	ret := _m.Called(ctx, record)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertAuditRecord")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.AuditRecord) error); ok {
+		r0 = rf(ctx, record)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertBlob provides a mock function with given fields: ctx, blob
 func (_m *Plugin) InsertBlob(ctx context.Context, blob *core.Blob) error {
 	ret := _m.Called(ctx, blob)
@@ -3025,6 +3457,24 @@ func (_m *Plugin) InsertEvent(ctx context.Context, data *core.Event) error {
 	return r0
 }
 
+// InsertEvents provides a mock function with given fields: ctx, events
+func (_m *Plugin) InsertEvents(ctx context.Context, events []*core.Event) error {
+	ret := _m.Called(ctx, events)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InsertEvents")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.Event) error); ok {
+		r0 = rf(ctx, events)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertMessages provides a mock function with given fields: ctx, messages, hooks
 func (_m *Plugin) InsertMessages(ctx context.Context, messages []*core.Message, hooks ...database.PostCompletionHook) error {
 	_va := make([]interface{}, len(hooks))
@@ -3502,21 +3952,31 @@ func (_m *Plugin) UpdateMessage(ctx context.Context, namespace string, id *fftyp
 }
 
 // UpdateMessages provides a mock function with given fields: ctx, namespace, filter, update
-func (_m *Plugin) UpdateMessages(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) error {
+func (_m *Plugin) UpdateMessages(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) (int64, error) {
 	ret := _m.Called(ctx, namespace, filter, update)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdateMessages")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, ffapi.Update) error); ok {
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, ffapi.Update) (int64, error)); ok {
+		return rf(ctx, namespace, filter, update)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.Filter, ffapi.Update) int64); ok {
 		r0 = rf(ctx, namespace, filter, update)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(int64)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.Filter, ffapi.Update) error); ok {
+		r1 = rf(ctx, namespace, filter, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // UpdateNextPin provides a mock function with given fields: ctx, namespace, sequence, update
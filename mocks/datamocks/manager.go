@@ -58,6 +58,36 @@ func (_m *Manager) CheckDatatype(ctx context.Context, datatype *core.Datatype) e
 	return r0
 }
 
+// CheckDatatypeData provides a mock function with given fields: ctx, proposed
+func (_m *Manager) CheckDatatypeData(ctx context.Context, proposed *core.Datatype) (*core.DatatypeDataCheck, error) {
+	ret := _m.Called(ctx, proposed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckDatatypeData")
+	}
+
+	var r0 *core.DatatypeDataCheck
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Datatype) (*core.DatatypeDataCheck, error)); ok {
+		return rf(ctx, proposed)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Datatype) *core.DatatypeDataCheck); ok {
+		r0 = rf(ctx, proposed)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DatatypeDataCheck)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.Datatype) error); ok {
+		r1 = rf(ctx, proposed)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteData provides a mock function with given fields: ctx, dataID
 func (_m *Manager) DeleteData(ctx context.Context, dataID string) error {
 	ret := _m.Called(ctx, dataID)
@@ -76,6 +106,71 @@ func (_m *Manager) DeleteData(ctx context.Context, dataID string) error {
 	return r0
 }
 
+// DeleteLargeValues provides a mock function with given fields: ctx, _a1
+func (_m *Manager) DeleteLargeValues(ctx context.Context, _a1 core.DataArray) {
+	_m.Called(ctx, _a1)
+}
+
+// DiffDatatypes provides a mock function with given fields: ctx, from, to
+func (_m *Manager) DiffDatatypes(ctx context.Context, from *core.Datatype, to *core.Datatype) (*core.DatatypeDiff, error) {
+	ret := _m.Called(ctx, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DiffDatatypes")
+	}
+
+	var r0 *core.DatatypeDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Datatype, *core.Datatype) (*core.DatatypeDiff, error)); ok {
+		return rf(ctx, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Datatype, *core.Datatype) *core.DatatypeDiff); ok {
+		r0 = rf(ctx, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DatatypeDiff)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.Datatype, *core.Datatype) error); ok {
+		r1 = rf(ctx, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DiffLatestDatatype provides a mock function with given fields: ctx, datatype
+func (_m *Manager) DiffLatestDatatype(ctx context.Context, datatype *core.Datatype) (*core.DatatypeDiff, error) {
+	ret := _m.Called(ctx, datatype)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DiffLatestDatatype")
+	}
+
+	var r0 *core.DatatypeDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Datatype) (*core.DatatypeDiff, error)); ok {
+		return rf(ctx, datatype)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Datatype) *core.DatatypeDiff); ok {
+		r0 = rf(ctx, datatype)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DatatypeDiff)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.Datatype) error); ok {
+		r1 = rf(ctx, datatype)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DownloadBlob provides a mock function with given fields: ctx, dataID
 func (_m *Manager) DownloadBlob(ctx context.Context, dataID string) (*core.Blob, io.ReadCloser, error) {
 	ret := _m.Called(ctx, dataID)
@@ -115,6 +210,36 @@ func (_m *Manager) DownloadBlob(ctx context.Context, dataID string) (*core.Blob,
 	return r0, r1, r2
 }
 
+// DownloadLargeValue provides a mock function with given fields: ctx, d
+func (_m *Manager) DownloadLargeValue(ctx context.Context, d *core.Data) (io.ReadCloser, error) {
+	ret := _m.Called(ctx, d)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DownloadLargeValue")
+	}
+
+	var r0 io.ReadCloser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Data) (io.ReadCloser, error)); ok {
+		return rf(ctx, d)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Data) io.ReadCloser); ok {
+		r0 = rf(ctx, d)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.Data) error); ok {
+		r1 = rf(ctx, d)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMessageDataCached provides a mock function with given fields: ctx, msg, options
 func (_m *Manager) GetMessageDataCached(ctx context.Context, msg *core.Message, options ...data.CacheReadOption) (core.DataArray, bool, error) {
 	_va := make([]interface{}, len(options))
@@ -242,6 +367,66 @@ func (_m *Manager) HydrateBatch(ctx context.Context, persistedBatch *core.BatchP
 	return r0, r1
 }
 
+// LoadLargeValues provides a mock function with given fields: ctx, _a1
+func (_m *Manager) LoadLargeValues(ctx context.Context, _a1 core.DataArray) (core.DataArray, error) {
+	ret := _m.Called(ctx, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoadLargeValues")
+	}
+
+	var r0 core.DataArray
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.DataArray) (core.DataArray, error)); ok {
+		return rf(ctx, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, core.DataArray) core.DataArray); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(core.DataArray)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, core.DataArray) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OffloadLargeValues provides a mock function with given fields: ctx, _a1
+func (_m *Manager) OffloadLargeValues(ctx context.Context, _a1 core.DataArray) (core.DataArray, error) {
+	ret := _m.Called(ctx, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OffloadLargeValues")
+	}
+
+	var r0 core.DataArray
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.DataArray) (core.DataArray, error)); ok {
+		return rf(ctx, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, core.DataArray) core.DataArray); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(core.DataArray)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, core.DataArray) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PeekMessageCache provides a mock function with given fields: ctx, id, options
 func (_m *Manager) PeekMessageCache(ctx context.Context, id *fftypes.UUID, options ...data.CacheReadOption) (*core.Message, core.DataArray) {
 	_va := make([]interface{}, len(options))
@@ -281,6 +466,36 @@ func (_m *Manager) PeekMessageCache(ctx context.Context, id *fftypes.UUID, optio
 	return r0, r1
 }
 
+// RedactData provides a mock function with given fields: ctx, dataID, input
+func (_m *Manager) RedactData(ctx context.Context, dataID string, input *core.DataRedactInput) (*core.Data, error) {
+	ret := _m.Called(ctx, dataID, input)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RedactData")
+	}
+
+	var r0 *core.Data
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.DataRedactInput) (*core.Data, error)); ok {
+		return rf(ctx, dataID, input)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.DataRedactInput) *core.Data); ok {
+		r0 = rf(ctx, dataID, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Data)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *core.DataRedactInput) error); ok {
+		r1 = rf(ctx, dataID, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ResolveInlineData provides a mock function with given fields: ctx, msg
 func (_m *Manager) ResolveInlineData(ctx context.Context, msg *data.NewMessage) error {
 	ret := _m.Called(ctx, msg)
@@ -430,6 +645,26 @@ func (_m *Manager) WriteNewMessage(ctx context.Context, newMsg *data.NewMessage)
 	return r0
 }
 
+// WriteNewMessages provides a mock function with given fields: ctx, newMsgs
+func (_m *Manager) WriteNewMessages(ctx context.Context, newMsgs []*data.NewMessage) []error {
+	ret := _m.Called(ctx, newMsgs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteNewMessages")
+	}
+
+	var r0 []error
+	if rf, ok := ret.Get(0).(func(context.Context, []*data.NewMessage) []error); ok {
+		r0 = rf(ctx, newMsgs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	return r0
+}
+
 // NewManager creates a new instance of Manager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewManager(t interface {
@@ -5,9 +5,12 @@ package namespacemocks
 import (
 	context "context"
 
-	fftypes "github.com/hyperledger/firefly-common/pkg/fftypes"
+	audit "github.com/hyperledger/firefly/internal/audit"
+
 	core "github.com/hyperledger/firefly/pkg/core"
 
+	fftypes "github.com/hyperledger/firefly-common/pkg/fftypes"
+
 	mock "github.com/stretchr/testify/mock"
 
 	orchestrator "github.com/hyperledger/firefly/internal/orchestrator"
@@ -38,6 +41,72 @@ func (_m *Manager) Authorize(ctx context.Context, authReq *fftypes.AuthReq) erro
 	return r0
 }
 
+// AuthorizeGlobal provides a mock function with given fields: ctx, authReq
+func (_m *Manager) AuthorizeGlobal(ctx context.Context, authReq *fftypes.AuthReq) error {
+	ret := _m.Called(ctx, authReq)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthorizeGlobal")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.AuthReq) error); ok {
+		r0 = rf(ctx, authReq)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateNamespace provides a mock function with given fields: ctx, def
+func (_m *Manager) CreateNamespace(ctx context.Context, def *core.NamespaceDefinition) (*core.Namespace, error) {
+	ret := _m.Called(ctx, def)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateNamespace")
+	}
+
+	var r0 *core.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.NamespaceDefinition) (*core.Namespace, error)); ok {
+		return rf(ctx, def)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.NamespaceDefinition) *core.Namespace); ok {
+		r0 = rf(ctx, def)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.NamespaceDefinition) error); ok {
+		r1 = rf(ctx, def)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteNamespace provides a mock function with given fields: ctx, name
+func (_m *Manager) DeleteNamespace(ctx context.Context, name string) error {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteNamespace")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetNamespaces provides a mock function with given fields: ctx, includeInitializing
 func (_m *Manager) GetNamespaces(ctx context.Context, includeInitializing bool) ([]*core.NamespaceWithInitStatus, error) {
 	ret := _m.Called(ctx, includeInitializing)
@@ -98,6 +167,36 @@ func (_m *Manager) GetOperationByNamespacedID(ctx context.Context, nsOpID string
 	return r0, r1
 }
 
+// GetReadiness provides a mock function with given fields: ctx
+func (_m *Manager) GetReadiness(ctx context.Context) (*core.NodeReadiness, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReadiness")
+	}
+
+	var r0 *core.NodeReadiness
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*core.NodeReadiness, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *core.NodeReadiness); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.NodeReadiness)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Init provides a mock function with given fields: ctx, cancelCtx, reset, reloadConfig
 func (_m *Manager) Init(ctx context.Context, cancelCtx context.CancelFunc, reset chan bool, reloadConfig func() error) error {
 	ret := _m.Called(ctx, cancelCtx, reset, reloadConfig)
@@ -136,6 +235,36 @@ func (_m *Manager) MustOrchestrator(ns string) orchestrator.Orchestrator {
 	return r0
 }
 
+// NodeAudit provides a mock function with given fields: ctx
+func (_m *Manager) NodeAudit(ctx context.Context) (audit.Manager, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NodeAudit")
+	}
+
+	var r0 audit.Manager
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (audit.Manager, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) audit.Manager); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(audit.Manager)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Orchestrator provides a mock function with given fields: ctx, ns, includeInitializing
 func (_m *Manager) Orchestrator(ctx context.Context, ns string, includeInitializing bool) (orchestrator.Orchestrator, error) {
 	ret := _m.Called(ctx, ns, includeInitializing)
@@ -240,6 +369,66 @@ func (_m *Manager) Start() error {
 	return r0
 }
 
+// StopNamespace provides a mock function with given fields: ctx, name
+func (_m *Manager) StopNamespace(ctx context.Context, name string) (*core.Namespace, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StopNamespace")
+	}
+
+	var r0 *core.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.Namespace, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.Namespace); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateNamespace provides a mock function with given fields: ctx, name, def
+func (_m *Manager) UpdateNamespace(ctx context.Context, name string, def *core.NamespaceDefinition) (*core.Namespace, error) {
+	ret := _m.Called(ctx, name, def)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateNamespace")
+	}
+
+	var r0 *core.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.NamespaceDefinition) (*core.Namespace, error)); ok {
+		return rf(ctx, name, def)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *core.NamespaceDefinition) *core.Namespace); ok {
+		r0 = rf(ctx, name, def)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *core.NamespaceDefinition) error); ok {
+		r1 = rf(ctx, name, def)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WaitStop provides a mock function with given fields:
 func (_m *Manager) WaitStop() {
 	_m.Called()
@@ -4,6 +4,8 @@ package orchestratormocks
 
 import (
 	assets "github.com/hyperledger/firefly/internal/assets"
+	audit "github.com/hyperledger/firefly/internal/audit"
+
 	batch "github.com/hyperledger/firefly/internal/batch"
 
 	broadcast "github.com/hyperledger/firefly/internal/broadcast"
@@ -28,6 +30,8 @@ import (
 
 	identity "github.com/hyperledger/firefly/internal/identity"
 
+	io "io"
+
 	mock "github.com/stretchr/testify/mock"
 
 	multiparty "github.com/hyperledger/firefly/internal/multiparty"
@@ -37,6 +41,8 @@ import (
 	operations "github.com/hyperledger/firefly/internal/operations"
 
 	privatemessaging "github.com/hyperledger/firefly/internal/privatemessaging"
+
+	scheduler "github.com/hyperledger/firefly/internal/scheduler"
 )
 
 // Orchestrator is an autogenerated mock type for the Orchestrator type
@@ -64,6 +70,26 @@ func (_m *Orchestrator) Assets() assets.Manager {
 	return r0
 }
 
+// Audit provides a mock function with given fields:
+func (_m *Orchestrator) Audit() audit.Manager {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Audit")
+	}
+
+	var r0 audit.Manager
+	if rf, ok := ret.Get(0).(func() audit.Manager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(audit.Manager)
+		}
+	}
+
+	return r0
+}
+
 // Authorize provides a mock function with given fields: ctx, authReq
 func (_m *Orchestrator) Authorize(ctx context.Context, authReq *fftypes.AuthReq) error {
 	ret := _m.Called(ctx, authReq)
@@ -122,6 +148,36 @@ func (_m *Orchestrator) Broadcast() broadcast.Manager {
 	return r0
 }
 
+// CheckDatatypeData provides a mock function with given fields: ctx, proposed
+func (_m *Orchestrator) CheckDatatypeData(ctx context.Context, proposed *core.Datatype) (*core.DatatypeDataCheck, error) {
+	ret := _m.Called(ctx, proposed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckDatatypeData")
+	}
+
+	var r0 *core.DatatypeDataCheck
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Datatype) (*core.DatatypeDataCheck, error)); ok {
+		return rf(ctx, proposed)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *core.Datatype) *core.DatatypeDataCheck); ok {
+		r0 = rf(ctx, proposed)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DatatypeDataCheck)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *core.Datatype) error); ok {
+		r1 = rf(ctx, proposed)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Contracts provides a mock function with given fields:
 func (_m *Orchestrator) Contracts() contracts.Manager {
 	ret := _m.Called()
@@ -260,6 +316,36 @@ func (_m *Orchestrator) DeleteSubscription(ctx context.Context, id string) error
 	return r0
 }
 
+// DiffDatatypes provides a mock function with given fields: ctx, name, fromVersion, toVersion
+func (_m *Orchestrator) DiffDatatypes(ctx context.Context, name string, fromVersion string, toVersion string) (*core.DatatypeDiff, error) {
+	ret := _m.Called(ctx, name, fromVersion, toVersion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DiffDatatypes")
+	}
+
+	var r0 *core.DatatypeDiff
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*core.DatatypeDiff, error)); ok {
+		return rf(ctx, name, fromVersion, toVersion)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *core.DatatypeDiff); ok {
+		r0 = rf(ctx, name, fromVersion, toVersion)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.DatatypeDiff)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, name, fromVersion, toVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Events provides a mock function with given fields:
 func (_m *Orchestrator) Events() events.EventManager {
 	ret := _m.Called()
@@ -280,6 +366,24 @@ func (_m *Orchestrator) Events() events.EventManager {
 	return r0
 }
 
+// ExportNamespace provides a mock function with given fields: ctx, w
+func (_m *Orchestrator) ExportNamespace(ctx context.Context, w io.Writer) error {
+	ret := _m.Called(ctx, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportNamespace")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Writer) error); ok {
+		r0 = rf(ctx, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetBatchByID provides a mock function with given fields: ctx, id
 func (_m *Orchestrator) GetBatchByID(ctx context.Context, id string) (*core.BatchPersisted, error) {
 	ret := _m.Called(ctx, id)
@@ -418,6 +522,36 @@ func (_m *Orchestrator) GetBlockchainEvents(ctx context.Context, filter ffapi.An
 	return r0, r1, r2
 }
 
+// GetBlockchainTransaction provides a mock function with given fields: ctx, blockchainID
+func (_m *Orchestrator) GetBlockchainTransaction(ctx context.Context, blockchainID string) (*core.BlockchainTransaction, error) {
+	ret := _m.Called(ctx, blockchainID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockchainTransaction")
+	}
+
+	var r0 *core.BlockchainTransaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.BlockchainTransaction, error)); ok {
+		return rf(ctx, blockchainID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.BlockchainTransaction); ok {
+		r0 = rf(ctx, blockchainID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.BlockchainTransaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, blockchainID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetChartHistogram provides a mock function with given fields: ctx, startTime, endTime, buckets, tableName
 func (_m *Orchestrator) GetChartHistogram(ctx context.Context, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*core.ChartHistogram, error) {
 	ret := _m.Called(ctx, startTime, endTime, buckets, tableName)
@@ -517,6 +651,45 @@ func (_m *Orchestrator) GetDataByID(ctx context.Context, id string) (*core.Data,
 	return r0, r1
 }
 
+// GetDataByValue provides a mock function with given fields: ctx, matches, filter
+func (_m *Orchestrator) GetDataByValue(ctx context.Context, matches []*core.JSONPathMatch, filter ffapi.AndFilter) (core.DataArray, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, matches, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDataByValue")
+	}
+
+	var r0 core.DataArray
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) (core.DataArray, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, matches, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) core.DataArray); ok {
+		r0 = rf(ctx, matches, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(core.DataArray)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, matches, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, matches, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetDataSubPaths provides a mock function with given fields: ctx, path
 func (_m *Orchestrator) GetDataSubPaths(ctx context.Context, path string) ([]string, error) {
 	ret := _m.Called(ctx, path)
@@ -913,6 +1086,84 @@ func (_m *Orchestrator) GetMessageEvents(ctx context.Context, id string, filter
 	return r0, r1, r2
 }
 
+// GetMessageThread provides a mock function with given fields: ctx, id, depth, filter
+func (_m *Orchestrator) GetMessageThread(ctx context.Context, id string, depth string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, id, depth, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessageThread")
+	}
+
+	var r0 []*core.Message
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, id, depth, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, ffapi.AndFilter) []*core.Message); ok {
+		r0 = rf(ctx, id, depth, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, id, depth, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, id, depth, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessageThreadWithData provides a mock function with given fields: ctx, id, depth, filter
+func (_m *Orchestrator) GetMessageThreadWithData(ctx context.Context, id string, depth string, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, id, depth, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessageThreadWithData")
+	}
+
+	var r0 []*core.MessageInOut
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, id, depth, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, ffapi.AndFilter) []*core.MessageInOut); ok {
+		r0 = rf(ctx, id, depth, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.MessageInOut)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, id, depth, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, id, depth, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetMessageTransaction provides a mock function with given fields: ctx, id
 func (_m *Orchestrator) GetMessageTransaction(ctx context.Context, id string) (*core.Transaction, error) {
 	ret := _m.Called(ctx, id)
@@ -982,6 +1233,84 @@ func (_m *Orchestrator) GetMessages(ctx context.Context, filter ffapi.AndFilter)
 	return r0, r1, r2
 }
 
+// GetMessagesByDataValue provides a mock function with given fields: ctx, matches, filter
+func (_m *Orchestrator) GetMessagesByDataValue(ctx context.Context, matches []*core.JSONPathMatch, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, matches, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessagesByDataValue")
+	}
+
+	var r0 []*core.Message
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, matches, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) []*core.Message); ok {
+		r0 = rf(ctx, matches, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, matches, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, matches, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessagesByDataValueWithData provides a mock function with given fields: ctx, matches, filter
+func (_m *Orchestrator) GetMessagesByDataValueWithData(ctx context.Context, matches []*core.JSONPathMatch, filter ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, matches, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMessagesByDataValueWithData")
+	}
+
+	var r0 []*core.MessageInOut
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) ([]*core.MessageInOut, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, matches, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) []*core.MessageInOut); ok {
+		r0 = rf(ctx, matches, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.MessageInOut)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, matches, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []*core.JSONPathMatch, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, matches, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetMessagesForData provides a mock function with given fields: ctx, dataID, filter
 func (_m *Orchestrator) GetMessagesForData(ctx context.Context, dataID string, filter ffapi.AndFilter) ([]*core.Message, *ffapi.FilterResult, error) {
 	ret := _m.Called(ctx, dataID, filter)
@@ -1287,6 +1616,24 @@ func (_m *Orchestrator) GetPins(ctx context.Context, filter ffapi.AndFilter) ([]
 	return r0, r1, r2
 }
 
+// GetPluginHealth provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetPluginHealth(ctx context.Context) core.NamespaceStatusPlugins {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPluginHealth")
+	}
+
+	var r0 core.NamespaceStatusPlugins
+	if rf, ok := ret.Get(0).(func(context.Context) core.NamespaceStatusPlugins); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(core.NamespaceStatusPlugins)
+	}
+
+	return r0
+}
+
 // GetStatus provides a mock function with given fields: ctx
 func (_m *Orchestrator) GetStatus(ctx context.Context) (*core.NamespaceStatus, error) {
 	ret := _m.Called(ctx)
@@ -1632,6 +1979,45 @@ func (_m *Orchestrator) GetTransactions(ctx context.Context, filter ffapi.AndFil
 	return r0, r1, r2
 }
 
+// GetTransactionsByBlockchainID provides a mock function with given fields: ctx, blockchainID, filter
+func (_m *Orchestrator) GetTransactionsByBlockchainID(ctx context.Context, blockchainID string, filter ffapi.AndFilter) ([]*core.Transaction, *ffapi.FilterResult, error) {
+	ret := _m.Called(ctx, blockchainID, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransactionsByBlockchainID")
+	}
+
+	var r0 []*core.Transaction
+	var r1 *ffapi.FilterResult
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) ([]*core.Transaction, *ffapi.FilterResult, error)); ok {
+		return rf(ctx, blockchainID, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, ffapi.AndFilter) []*core.Transaction); ok {
+		r0 = rf(ctx, blockchainID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, ffapi.AndFilter) *ffapi.FilterResult); ok {
+		r1 = rf(ctx, blockchainID, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*ffapi.FilterResult)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, ffapi.AndFilter) error); ok {
+		r2 = rf(ctx, blockchainID, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Identity provides a mock function with given fields:
 func (_m *Orchestrator) Identity() identity.Manager {
 	ret := _m.Called()
@@ -1652,6 +2038,36 @@ func (_m *Orchestrator) Identity() identity.Manager {
 	return r0
 }
 
+// ImportNamespace provides a mock function with given fields: ctx, r
+func (_m *Orchestrator) ImportNamespace(ctx context.Context, r io.Reader) (*core.NamespaceArchiveSummary, error) {
+	ret := _m.Called(ctx, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportNamespace")
+	}
+
+	var r0 *core.NamespaceArchiveSummary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader) (*core.NamespaceArchiveSummary, error)); ok {
+		return rf(ctx, r)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader) *core.NamespaceArchiveSummary); ok {
+		r0 = rf(ctx, r)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.NamespaceArchiveSummary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, io.Reader) error); ok {
+		r1 = rf(ctx, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Init provides a mock function with given fields:
 func (_m *Orchestrator) Init() error {
 	ret := _m.Called()
@@ -1815,6 +2231,26 @@ func (_m *Orchestrator) RewindPins(ctx context.Context, rewind *core.PinRewind)
 	return r0, r1
 }
 
+// Scheduler provides a mock function with given fields:
+func (_m *Orchestrator) Scheduler() scheduler.Manager {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Scheduler")
+	}
+
+	var r0 scheduler.Manager
+	if rf, ok := ret.Get(0).(func() scheduler.Manager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(scheduler.Manager)
+		}
+	}
+
+	return r0
+}
+
 // Start provides a mock function with given fields:
 func (_m *Orchestrator) Start() error {
 	ret := _m.Called()
@@ -312,6 +312,36 @@ func (_m *Manager) SendMessage(ctx context.Context, in *core.MessageInOut, waitC
 	return r0, r1
 }
 
+// SendMessages provides a mock function with given fields: ctx, in
+func (_m *Manager) SendMessages(ctx context.Context, in []*core.MessageInOut) ([]*core.MessageBulkResult, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendMessages")
+	}
+
+	var r0 []*core.MessageBulkResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.MessageInOut) ([]*core.MessageBulkResult, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []*core.MessageInOut) []*core.MessageBulkResult); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*core.MessageBulkResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []*core.MessageInOut) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewManager creates a new instance of Manager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewManager(t interface {
@@ -87,6 +87,36 @@ func (_m *Plugin) Capabilities() *tokens.Capabilities {
 	return r0
 }
 
+// CheckHealth provides a mock function with given fields: ctx, namespace
+func (_m *Plugin) CheckHealth(ctx context.Context, namespace string) (*core.EventStreamHealth, error) {
+	ret := _m.Called(ctx, namespace)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckHealth")
+	}
+
+	var r0 *core.EventStreamHealth
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*core.EventStreamHealth, error)); ok {
+		return rf(ctx, namespace)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *core.EventStreamHealth); ok {
+		r0 = rf(ctx, namespace)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.EventStreamHealth)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, namespace)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CheckInterface provides a mock function with given fields: ctx, pool, methods
 func (_m *Plugin) CheckInterface(ctx context.Context, pool *core.TokenPool, methods []*fftypes.FFIMethod) (*fftypes.JSONAny, error) {
 	ret := _m.Called(ctx, pool, methods)
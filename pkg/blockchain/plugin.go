@@ -62,6 +62,10 @@ type Plugin interface {
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
 
+	// CheckHealth probes the blockchain connector, returning an error if it cannot be reached,
+	// along with the state of the event stream the namespace listens on for blockchain events.
+	CheckHealth(ctx context.Context, namespace string) (*core.EventStreamHealth, error)
+
 	// VerifierType returns the verifier (key) type that is used by this blockchain
 	VerifierType() core.VerifierType
 
@@ -0,0 +1,75 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+)
+
+// AuditRecord is an append-only record of a mutating API request, including who made it,
+// what it targeted, and its outcome. Each record includes the hash of the record before it
+// in the namespace, forming a hash chain that makes any later modification or removal detectable.
+type AuditRecord struct {
+	ID             *fftypes.UUID      `ffstruct:"AuditRecord" json:"id"`
+	Namespace      string             `ffstruct:"AuditRecord" json:"namespace"`
+	Principal      string             `ffstruct:"AuditRecord" json:"principal,omitempty"`
+	Client         string             `ffstruct:"AuditRecord" json:"client,omitempty"`
+	Method         string             `ffstruct:"AuditRecord" json:"method"`
+	Route          string             `ffstruct:"AuditRecord" json:"route,omitempty"`
+	Path           string             `ffstruct:"AuditRecord" json:"path"`
+	Params         fftypes.JSONObject `ffstruct:"AuditRecord" json:"params,omitempty"`
+	InputHash      *fftypes.Bytes32   `ffstruct:"AuditRecord" json:"inputHash,omitempty"`
+	RequestID      string             `ffstruct:"AuditRecord" json:"requestId,omitempty"`
+	IdempotencyKey IdempotencyKey     `ffstruct:"AuditRecord" json:"idempotencyKey,omitempty"`
+	Transaction    *fftypes.UUID      `ffstruct:"AuditRecord" json:"tx,omitempty"`
+	Operation      *fftypes.UUID      `ffstruct:"AuditRecord" json:"operation,omitempty"`
+	Status         int                `ffstruct:"AuditRecord" json:"status"`
+	Error          string             `ffstruct:"AuditRecord" json:"error,omitempty"`
+	Created        *fftypes.FFTime    `ffstruct:"AuditRecord" json:"created"`
+	PreviousHash   *fftypes.Bytes32   `ffstruct:"AuditRecord" json:"previousHash,omitempty"`
+	Hash           *fftypes.Bytes32   `ffstruct:"AuditRecord" json:"hash"`
+	Sequence       int64              `ffstruct:"AuditRecord" json:"sequence"`
+}
+
+// AuditVerification is the result of checking the hash chain of the audit records in a namespace
+type AuditVerification struct {
+	Records      int64            `ffstruct:"AuditVerification" json:"records"`
+	Valid        bool             `ffstruct:"AuditVerification" json:"valid"`
+	LastHash     *fftypes.Bytes32 `ffstruct:"AuditVerification" json:"lastHash,omitempty"`
+	FirstInvalid int64            `ffstruct:"AuditVerification" json:"firstInvalid,omitempty"`
+	Error        string           `ffstruct:"AuditVerification" json:"error,omitempty"`
+}
+
+// CalcHash calculates the hash of the record, which covers every field except the hash itself
+// and the local database sequence
+func (ar *AuditRecord) CalcHash() *fftypes.Bytes32 {
+	hashed := *ar
+	hashed.Hash = nil
+	hashed.Sequence = 0
+	b, _ := json.Marshal(&hashed)
+	var b32 fftypes.Bytes32 = sha256.Sum256(b)
+	return &b32
+}
+
+// Seal links the record to the previous record in the chain (nil for the first record), and sets its hash
+func (ar *AuditRecord) Seal(previousHash *fftypes.Bytes32) {
+	ar.PreviousHash = previousHash
+	ar.Hash = ar.CalcHash()
+}
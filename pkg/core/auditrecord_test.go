@@ -0,0 +1,50 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRecordSeal(t *testing.T) {
+	record := &AuditRecord{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Method:    "POST",
+		Path:      "/api/v1/namespaces/ns1/messages/broadcast",
+		Status:    202,
+		Created:   fftypes.Now(),
+	}
+	previous := fftypes.NewRandB32()
+	record.Seal(previous)
+	assert.Equal(t, previous, record.PreviousHash)
+	hash := *record.Hash
+
+	// The hash does not depend on the local sequence
+	record.Sequence = 12345
+	assert.Equal(t, hash, *record.CalcHash())
+
+	// Any change to the content, or to the link, changes the hash
+	record.Status = 200
+	assert.NotEqual(t, hash, *record.CalcHash())
+	record.Status = 202
+	record.PreviousHash = nil
+	assert.NotEqual(t, hash, *record.CalcHash())
+}
@@ -1,4 +1,4 @@
-// Copyright © 2022 Kaleido, Inc.
+// Copyright © 2024 Kaleido, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -18,6 +18,7 @@ package core
 
 import (
 	"context"
+	"crypto/tls"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 )
@@ -25,3 +26,40 @@ import (
 type Authorizer interface {
 	Authorize(ctx context.Context, authReq *fftypes.AuthReq) error
 }
+
+// AuthRequestInfo carries the details of a request that are not part of fftypes.AuthReq,
+// for auth plugins that make decisions based on them. Plugins that identify the principal
+// making the request return it in Principal.
+type AuthRequestInfo struct {
+	TLS       *tls.ConnectionState // the TLS state of the connection, including any verified client certificate chains
+	Input     interface{}          // the parsed JSON input of the request, when it has one
+	Principal string               // the principal authorized by the auth plugin, as type:name
+	Resolver  AuthResourceResolver // looks up the resources the request refers to, for plugins that authorize by resource
+}
+
+// AuthResourceResolver looks up the resources a request refers to, so auth plugins can match them by their
+// canonical name and ID however the request referred to them. Each returns nil if there is no match.
+type AuthResourceResolver interface {
+	// ResolveTokenPool finds a token pool by name or ID, or the only pool in the namespace if nameOrID is empty
+	ResolveTokenPool(ctx context.Context, nameOrID string) (*TokenPool, error)
+	// ResolveContractAPI finds a contract API by name or ID
+	ResolveContractAPI(ctx context.Context, nameOrID string) (*ContractAPI, error)
+	// ResolveContractAPIForCall finds the contract API for the interface and location of a direct contract call,
+	// as long as exactly one contract API matches
+	ResolveContractAPIForCall(ctx context.Context, call *ContractCallRequest) (*ContractAPI, error)
+}
+
+type authRequestInfoKey struct{}
+
+// WithAuthRequestInfo returns a context that makes the supplied request info available to auth plugins
+func WithAuthRequestInfo(ctx context.Context, info *AuthRequestInfo) context.Context {
+	return context.WithValue(ctx, authRequestInfoKey{}, info)
+}
+
+// GetAuthRequestInfo returns the request info set with WithAuthRequestInfo, or an empty info if none was set
+func GetAuthRequestInfo(ctx context.Context) *AuthRequestInfo {
+	if info, ok := ctx.Value(authRequestInfoKey{}).(*AuthRequestInfo); ok && info != nil {
+		return info
+	}
+	return &AuthRequestInfo{}
+}
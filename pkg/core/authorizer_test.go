@@ -0,0 +1,33 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthRequestInfo(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, &AuthRequestInfo{}, GetAuthRequestInfo(ctx))
+
+	info := &AuthRequestInfo{TLS: &tls.ConnectionState{}, Input: "input"}
+	assert.Equal(t, info, GetAuthRequestInfo(WithAuthRequestInfo(ctx, info)))
+}
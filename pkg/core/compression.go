@@ -0,0 +1,126 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionType is the algorithm used to compress a serialized batch payload on the wire
+type CompressionType = fftypes.FFEnum
+
+var (
+	// CompressionTypeNone sends the serialized payload as-is, which all nodes can read
+	CompressionTypeNone = fftypes.FFEnumValue("compressiontype", "none")
+	// CompressionTypeGzip compresses the serialized payload with gzip
+	CompressionTypeGzip = fftypes.FFEnumValue("compressiontype", "gzip")
+	// CompressionTypeZstd compresses the serialized payload with zstd
+	CompressionTypeZstd = fftypes.FFEnumValue("compressiontype", "zstd")
+)
+
+// CompressionProfileKey is the key in a node identity profile, under which the node advertises the compression types it can read
+const CompressionProfileKey = "compression"
+
+// CompressedPayload is the envelope for a compressed payload. The string "payload" field deliberately
+// clashes with the object "payload" field of a batch, and the envelope has no "batch" field of a transport
+// wrapper, so nodes that do not support compression reject it as invalid rather than misinterpreting it.
+type CompressedPayload struct {
+	Compression CompressionType `json:"compression"`
+	Payload     []byte          `json:"payload"`
+}
+
+var compressedPayloadPrefix = []byte(`{"compression":`)
+
+// SupportedCompressionTypes returns the compression types this node can read, for advertising to other nodes
+func SupportedCompressionTypes() []string {
+	return []string{CompressionTypeGzip.String(), CompressionTypeZstd.String()}
+}
+
+// CompressPayload wraps a serialized payload in a compressed envelope.
+// The payload is returned unchanged for CompressionTypeNone.
+func CompressPayload(ctx context.Context, compression CompressionType, payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch compression {
+	case "", CompressionTypeNone:
+		return payload, nil
+	case CompressionTypeGzip:
+		w = gzip.NewWriter(&buf)
+	case CompressionTypeZstd:
+		w, _ = zstd.NewWriter(&buf) // only errors on invalid options
+	default:
+		return nil, i18n.NewError(ctx, coremsgs.MsgCompressionFailed, compression, "unsupported")
+	}
+	// Writes to an in-memory buffer cannot fail
+	_, _ = w.Write(payload)
+	_ = w.Close()
+	return json.Marshal(&CompressedPayload{
+		Compression: compression,
+		Payload:     buf.Bytes(),
+	})
+}
+
+// IsCompressedPayload checks whether a payload is a compressed envelope
+func IsCompressedPayload(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), compressedPayloadPrefix)
+}
+
+// DecompressPayload unwraps a compressed envelope, returning the serialized payload.
+// Payloads that are not compressed are returned unchanged, so uncompressed payloads from
+// older nodes continue to be accepted. The decompressed size is capped at maxSize bytes.
+func DecompressPayload(ctx context.Context, data []byte, maxSize int64) ([]byte, error) {
+	if !IsCompressedPayload(data) {
+		return data, nil
+	}
+	var cp CompressedPayload
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDecompressionFailed, "unknown", err)
+	}
+	var r io.Reader
+	switch cp.Compression {
+	case CompressionTypeGzip:
+		gr, err := gzip.NewReader(bytes.NewReader(cp.Payload))
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, coremsgs.MsgDecompressionFailed, cp.Compression, err)
+		}
+		defer gr.Close()
+		r = gr
+	case CompressionTypeZstd:
+		zr, _ := zstd.NewReader(bytes.NewReader(cp.Payload)) // only errors on invalid options
+		defer zr.Close()
+		r = zr
+	default:
+		return nil, i18n.NewError(ctx, coremsgs.MsgDecompressionFailed, cp.Compression, "unsupported")
+	}
+	payload, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, coremsgs.MsgDecompressionFailed, cp.Compression, err)
+	}
+	if int64(len(payload)) > maxSize {
+		return nil, i18n.NewError(ctx, coremsgs.MsgDecompressedPayloadTooLarge, maxSize)
+	}
+	return payload, nil
+}
@@ -0,0 +1,115 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly-common/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressPayloadRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	batch := &Batch{
+		BatchHeader: BatchHeader{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		Hash:        fftypes.NewRandB32(),
+	}
+	plain, err := json.Marshal(batch)
+	assert.NoError(t, err)
+
+	for _, compression := range []CompressionType{CompressionTypeGzip, CompressionTypeZstd} {
+		compressed, err := CompressPayload(ctx, compression, plain)
+		assert.NoError(t, err)
+		assert.True(t, IsCompressedPayload(compressed))
+
+		// Older nodes reject the payload rather than misinterpreting it
+		var oldBatch *Batch
+		err = json.Unmarshal(compressed, &oldBatch)
+		assert.Error(t, err)
+		var oldWrapper *TransportWrapper
+		err = json.Unmarshal(compressed, &oldWrapper)
+		assert.NoError(t, err)
+		assert.Nil(t, oldWrapper.Batch)
+
+		decompressed, err := DecompressPayload(ctx, compressed, 1024)
+		assert.NoError(t, err)
+		assert.Equal(t, plain, decompressed)
+
+		var parsed *Batch
+		err = json.Unmarshal(decompressed, &parsed)
+		assert.NoError(t, err)
+		assert.Equal(t, batch.Hash, parsed.Hash)
+	}
+}
+
+func TestCompressPayloadNone(t *testing.T) {
+	ctx := context.Background()
+	plain := []byte(`{"id":"12345"}`)
+
+	compressed, err := CompressPayload(ctx, CompressionTypeNone, plain)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, compressed)
+
+	compressed, err = CompressPayload(ctx, "", plain)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, compressed)
+
+	decompressed, err := DecompressPayload(ctx, plain, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, plain, decompressed)
+}
+
+func TestSupportedCompressionTypes(t *testing.T) {
+	assert.Equal(t, []string{"gzip", "zstd"}, SupportedCompressionTypes())
+}
+
+func TestCompressPayloadUnsupported(t *testing.T) {
+	_, err := CompressPayload(context.Background(), "lz4", []byte("{}"))
+	assert.Regexp(t, "FF10504", err)
+}
+
+func TestDecompressPayloadTooLarge(t *testing.T) {
+	ctx := context.Background()
+	compressed, err := CompressPayload(ctx, CompressionTypeGzip, []byte(strings.Repeat("a", 1025)))
+	assert.NoError(t, err)
+	_, err = DecompressPayload(ctx, compressed, 1024)
+	assert.Regexp(t, "FF10506", err)
+}
+
+func TestDecompressPayloadBadEnvelope(t *testing.T) {
+	_, err := DecompressPayload(context.Background(), []byte(`{"compression":false}`), 1024)
+	assert.Regexp(t, "FF10505", err)
+}
+
+func TestDecompressPayloadUnsupported(t *testing.T) {
+	_, err := DecompressPayload(context.Background(), []byte(`{"compression":"lz4","payload":""}`), 1024)
+	assert.Regexp(t, "FF10505", err)
+}
+
+func TestDecompressPayloadBadGzipHeader(t *testing.T) {
+	_, err := DecompressPayload(context.Background(), []byte(`{"compression":"gzip","payload":"AAAA"}`), 1024)
+	assert.Regexp(t, "FF10505", err)
+}
+
+func TestDecompressPayloadCorruptZstd(t *testing.T) {
+	_, err := DecompressPayload(context.Background(), []byte(`{"compression":"zstd","payload":"AAAA"}`), 1024)
+	assert.Regexp(t, "FF10505", err)
+}
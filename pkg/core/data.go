@@ -52,10 +52,37 @@ type Data struct {
 	Value     *fftypes.JSONAny `ffstruct:"Data" json:"value"`
 	Public    string           `ffstruct:"Data" json:"public,omitempty"`
 	Blob      *BlobRef         `ffstruct:"Data" json:"blob,omitempty"`
+	Redacted  *DataRedaction   `ffstruct:"Data" json:"redacted,omitempty"`
+	ValueRef  *DataValueRef    `ffstruct:"Data" json:"valueRef,omitempty"`
 
 	ValueSize int64 `json:"-"` // Used internally for message size calculation, without full payload retrieval
 }
 
+// DataValueRef is set in place of the value, when a large JSON value is held in the blob store
+// rather than in the database. The value can be streamed from the data value API.
+type DataValueRef struct {
+	Size       int64  `ffstruct:"DataValueRef" json:"size"`
+	PayloadRef string `json:"-"` // The data exchange reference, used internally to retrieve the value
+}
+
+// DataRedactedValue is the tombstone that replaces the value of redacted data.
+// The hash of the data is retained, so batches and pins containing the data still verify.
+var DataRedactedValue = fftypes.JSONAnyPtr(`{"redacted":true}`)
+
+// DataRedaction records who redacted a data item, when, and why
+type DataRedaction struct {
+	By      string          `ffstruct:"DataRedaction" json:"by,omitempty"`
+	Author  string          `ffstruct:"DataRedaction" json:"author,omitempty"`
+	Reason  string          `ffstruct:"DataRedaction" json:"reason,omitempty"`
+	Created *fftypes.FFTime `ffstruct:"DataRedaction" json:"created"`
+}
+
+// DataRedactInput is the request to redact a data item
+type DataRedactInput struct {
+	Author string `ffstruct:"DataRedactInput" json:"author,omitempty"`
+	Reason string `ffstruct:"DataRedactInput" json:"reason,omitempty"`
+}
+
 func (br *BlobRef) BatchBlobRef(batchType BatchType) *BlobRef {
 	if br == nil {
 		return nil
@@ -132,7 +159,8 @@ func (da DataArray) Refs() DataRefs {
 
 func CheckValidatorType(ctx context.Context, validator ValidatorType) error {
 	switch validator {
-	case ValidatorTypeJSON, ValidatorTypeNone, ValidatorTypeSystemDefinition:
+	case ValidatorTypeJSON, ValidatorTypeNone, ValidatorTypeSystemDefinition,
+		ValidatorTypeXML, ValidatorTypeProtobuf, ValidatorTypeAvro:
 		return nil
 	default:
 		return i18n.NewError(ctx, i18n.MsgUnknownValidatorType, validator)
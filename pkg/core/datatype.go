@@ -18,6 +18,7 @@ package core
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
@@ -32,23 +33,123 @@ var (
 	ValidatorTypeNone = fftypes.FFEnumValue("validatortype", "none")
 	// ValidatorTypeSystemDefinition is the validator type for system definitions
 	ValidatorTypeSystemDefinition = fftypes.FFEnumValue("validatortype", "definition")
+	// ValidatorTypeXML is the validator type for XML Schema (XSD) validation of XML documents
+	ValidatorTypeXML = fftypes.FFEnumValue("validatortype", "xml")
+	// ValidatorTypeProtobuf is the validator type for validation against a Protocol Buffers message definition
+	ValidatorTypeProtobuf = fftypes.FFEnumValue("validatortype", "protobuf")
+	// ValidatorTypeAvro is the validator type for validation against an Apache Avro schema
+	ValidatorTypeAvro = fftypes.FFEnumValue("validatortype", "avro")
 )
 
+type DatatypeCompatibility = fftypes.FFEnum
+
+var (
+	// DatatypeCompatibilityNone means a new version of the datatype is not checked against the previous version
+	DatatypeCompatibilityNone = fftypes.FFEnumValue("datatypecompatibility", "none")
+	// DatatypeCompatibilityBackward means all data valid against the previous version must be valid against the new version
+	DatatypeCompatibilityBackward = fftypes.FFEnumValue("datatypecompatibility", "backward")
+	// DatatypeCompatibilityForward means all data valid against the new version must be valid against the previous version
+	DatatypeCompatibilityForward = fftypes.FFEnumValue("datatypecompatibility", "forward")
+	// DatatypeCompatibilityFull means the new version must be both backward and forward compatible with the previous version
+	DatatypeCompatibilityFull = fftypes.FFEnumValue("datatypecompatibility", "full")
+)
+
+// ProtobufSchema is the value of a datatype with the protobuf validator
+type ProtobufSchema struct {
+	Schema  string `ffstruct:"ProtobufSchema" json:"schema"`
+	Message string `ffstruct:"ProtobufSchema" json:"message"`
+}
+
 // Datatype is the structure defining a data definition, such as a JSON schema
 type Datatype struct {
-	ID        *fftypes.UUID    `ffstruct:"Datatype" json:"id,omitempty" ffexcludeinput:"true"`
-	Message   *fftypes.UUID    `ffstruct:"Datatype" json:"message,omitempty" ffexcludeinput:"true"`
-	Validator ValidatorType    `ffstruct:"Datatype" json:"validator" ffenum:"validatortype"`
-	Namespace string           `ffstruct:"Datatype" json:"namespace,omitempty" ffexcludeinput:"true"`
-	Name      string           `ffstruct:"Datatype" json:"name,omitempty"`
-	Version   string           `ffstruct:"Datatype" json:"version,omitempty"`
-	Hash      *fftypes.Bytes32 `ffstruct:"Datatype" json:"hash,omitempty" ffexcludeinput:"true"`
-	Created   *fftypes.FFTime  `ffstruct:"Datatype" json:"created,omitempty" ffexcludeinput:"true"`
-	Value     *fftypes.JSONAny `ffstruct:"Datatype" json:"value,omitempty"`
+	ID            *fftypes.UUID         `ffstruct:"Datatype" json:"id,omitempty" ffexcludeinput:"true"`
+	Message       *fftypes.UUID         `ffstruct:"Datatype" json:"message,omitempty" ffexcludeinput:"true"`
+	Validator     ValidatorType         `ffstruct:"Datatype" json:"validator" ffenum:"validatortype"`
+	Namespace     string                `ffstruct:"Datatype" json:"namespace,omitempty" ffexcludeinput:"true"`
+	Name          string                `ffstruct:"Datatype" json:"name,omitempty"`
+	Version       string                `ffstruct:"Datatype" json:"version,omitempty"`
+	Hash          *fftypes.Bytes32      `ffstruct:"Datatype" json:"hash,omitempty" ffexcludeinput:"true"`
+	Created       *fftypes.FFTime       `ffstruct:"Datatype" json:"created,omitempty" ffexcludeinput:"true"`
+	Value         *fftypes.JSONAny      `ffstruct:"Datatype" json:"value,omitempty"`
+	IndexPaths    fftypes.FFStringArray `ffstruct:"Datatype" json:"indexPaths,omitempty"`
+	Compatibility DatatypeCompatibility `ffstruct:"Datatype" json:"compatibility,omitempty" ffenum:"datatypecompatibility"`
+}
+
+// DatatypeChange is a single difference between two versions of a datatype, with the compatibility it retains
+type DatatypeChange struct {
+	Path          string                `ffstruct:"DatatypeChange" json:"path"`
+	Description   string                `ffstruct:"DatatypeChange" json:"description"`
+	Compatibility DatatypeCompatibility `ffstruct:"DatatypeChange" json:"compatibility" ffenum:"datatypecompatibility"`
+}
+
+// DatatypeDiff is the set of differences between two versions of a datatype
+type DatatypeDiff struct {
+	From          *DatatypeRef          `ffstruct:"DatatypeDiff" json:"from"`
+	To            *DatatypeRef          `ffstruct:"DatatypeDiff" json:"to"`
+	Compatibility DatatypeCompatibility `ffstruct:"DatatypeDiff" json:"compatibility" ffenum:"datatypecompatibility"`
+	Changes       []*DatatypeChange     `ffstruct:"DatatypeDiff" json:"changes"`
+}
+
+// DatatypeDataCheck is the result of validating the stored data of a datatype against a proposed new version
+type DatatypeDataCheck struct {
+	Diff       *DatatypeDiff          `ffstruct:"DatatypeDataCheck" json:"diff,omitempty"`
+	Compatible bool                   `ffstruct:"DatatypeDataCheck" json:"compatible"`
+	Checked    int64                  `ffstruct:"DatatypeDataCheck" json:"checked"`
+	Invalid    int64                  `ffstruct:"DatatypeDataCheck" json:"invalid"`
+	Failures   []*DatatypeDataFailure `ffstruct:"DatatypeDataCheck" json:"failures"`
+}
+
+// DatatypeDataFailure is an item of stored data that is not valid against a proposed new version of its datatype
+type DatatypeDataFailure struct {
+	Data     *fftypes.UUID `ffstruct:"DatatypeDataFailure" json:"data"`
+	Datatype *DatatypeRef  `ffstruct:"DatatypeDataFailure" json:"datatype,omitempty"`
+	Error    string        `ffstruct:"DatatypeDataFailure" json:"error"`
+}
+
+// AddChange records a change, and reduces the overall compatibility of the diff to what the change retains
+func (dd *DatatypeDiff) AddChange(path string, compatibility DatatypeCompatibility, description string) {
+	dd.Changes = append(dd.Changes, &DatatypeChange{Path: path, Description: description, Compatibility: compatibility})
+	switch {
+	case compatibility == DatatypeCompatibilityFull:
+	case dd.Compatibility == DatatypeCompatibilityFull:
+		dd.Compatibility = compatibility
+	case dd.Compatibility != compatibility:
+		dd.Compatibility = DatatypeCompatibilityNone
+	}
+}
+
+// Satisfies returns true if the changes in the diff retain the required compatibility
+func (dd *DatatypeDiff) Satisfies(required DatatypeCompatibility) bool {
+	switch required {
+	case "", DatatypeCompatibilityNone:
+		return true
+	case DatatypeCompatibilityFull:
+		return dd.Compatibility == DatatypeCompatibilityFull
+	default:
+		return dd.Compatibility == DatatypeCompatibilityFull || dd.Compatibility == required
+	}
+}
+
+// Incompatibilities returns the changes that do not retain the required compatibility
+func (dd *DatatypeDiff) Incompatibilities(required DatatypeCompatibility) []*DatatypeChange {
+	var changes []*DatatypeChange
+	for _, c := range dd.Changes {
+		single := &DatatypeDiff{Compatibility: c.Compatibility}
+		if !single.Satisfies(required) {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+func (dc *DatatypeChange) String() string {
+	return fmt.Sprintf("%s: %s (%s)", dc.Path, dc.Description, dc.Compatibility)
 }
 
 func (dt *Datatype) Validate(ctx context.Context, existing bool) (err error) {
-	if dt.Validator != ValidatorTypeJSON {
+	switch dt.Validator {
+	case ValidatorTypeJSON, ValidatorTypeXML, ValidatorTypeProtobuf, ValidatorTypeAvro:
+	default:
 		return i18n.NewError(ctx, i18n.MsgUnknownFieldValue, "validator", dt.Validator)
 	}
 	if err = fftypes.ValidateFFNameFieldNoUUID(ctx, dt.Name, "name"); err != nil {
@@ -60,6 +161,16 @@ func (dt *Datatype) Validate(ctx context.Context, existing bool) (err error) {
 	if dt.Value == nil || len(*dt.Value) == 0 {
 		return i18n.NewError(ctx, i18n.MsgMissingRequiredField, "value")
 	}
+	switch dt.Compatibility {
+	case "", DatatypeCompatibilityNone, DatatypeCompatibilityBackward, DatatypeCompatibilityForward, DatatypeCompatibilityFull:
+	default:
+		return i18n.NewError(ctx, i18n.MsgUnknownFieldValue, "compatibility", dt.Compatibility)
+	}
+	for _, path := range dt.IndexPaths {
+		if err = ValidateJSONPath(ctx, path); err != nil {
+			return err
+		}
+	}
 	if existing {
 		if dt.ID == nil {
 			return i18n.NewError(ctx, i18n.MsgNilID)
@@ -63,6 +63,16 @@ func TestDatatypeValidation(t *testing.T) {
 	}
 	assert.NoError(t, dt.Validate(context.Background(), false))
 
+	dt.Compatibility = DatatypeCompatibility("sideways")
+	assert.Regexp(t, "FF00111.*compatibility", dt.Validate(context.Background(), false))
+	dt.Compatibility = DatatypeCompatibilityBackward
+	assert.NoError(t, dt.Validate(context.Background(), false))
+
+	dt.IndexPaths = fftypes.FFStringArray{"order.id", "order.$bad"}
+	assert.Regexp(t, "FF10487.*order.\\$bad", dt.Validate(context.Background(), false))
+	dt.IndexPaths = fftypes.FFStringArray{"order.id"}
+	assert.NoError(t, dt.Validate(context.Background(), false))
+
 	assert.Regexp(t, "FF00114", dt.Validate(context.Background(), true))
 
 	dt.ID = fftypes.NewUUID()
@@ -74,3 +84,34 @@ func TestDatatypeValidation(t *testing.T) {
 	def.SetBroadcastMessage(fftypes.NewUUID())
 	assert.NotNil(t, dt.Message)
 }
+
+func TestDatatypeDiffCompatibility(t *testing.T) {
+
+	diff := &DatatypeDiff{Compatibility: DatatypeCompatibilityFull}
+	assert.True(t, diff.Satisfies(DatatypeCompatibilityFull))
+	assert.True(t, diff.Satisfies(DatatypeCompatibilityBackward))
+	assert.True(t, diff.Satisfies(""))
+
+	diff.AddChange("$.a", DatatypeCompatibilityFull, "description changed")
+	assert.Equal(t, DatatypeCompatibilityFull, diff.Compatibility)
+
+	diff.AddChange("$.b", DatatypeCompatibilityBackward, "property added")
+	assert.Equal(t, DatatypeCompatibilityBackward, diff.Compatibility)
+	assert.False(t, diff.Satisfies(DatatypeCompatibilityFull))
+	assert.True(t, diff.Satisfies(DatatypeCompatibilityBackward))
+	assert.False(t, diff.Satisfies(DatatypeCompatibilityForward))
+
+	diff.AddChange("$.c", DatatypeCompatibilityBackward, "property added")
+	assert.Equal(t, DatatypeCompatibilityBackward, diff.Compatibility)
+
+	diff.AddChange("$.d", DatatypeCompatibilityForward, "property required")
+	assert.Equal(t, DatatypeCompatibilityNone, diff.Compatibility)
+	assert.False(t, diff.Satisfies(DatatypeCompatibilityBackward))
+	assert.True(t, diff.Satisfies(DatatypeCompatibilityNone))
+
+	incompatible := diff.Incompatibilities(DatatypeCompatibilityBackward)
+	assert.Len(t, incompatible, 1)
+	assert.Equal(t, "$.d: property required (forward)", incompatible[0].String())
+	assert.Len(t, diff.Incompatibilities(DatatypeCompatibilityFull), 3)
+	assert.Empty(t, diff.Incompatibilities(DatatypeCompatibilityNone))
+}
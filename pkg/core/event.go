@@ -29,6 +29,10 @@ var (
 	EventTypeMessageConfirmed = fftypes.FFEnumValue("eventtype", "message_confirmed")
 	// EventTypeMessageRejected occurs if a message is received and confirmed from a sequencing perspective, but is rejected as invalid (mismatch to schema, or duplicate system broadcast)
 	EventTypeMessageRejected = fftypes.FFEnumValue("eventtype", "message_rejected")
+	// EventTypeMessageExpired occurs if a message with an expiry is not confirmed before that time, and is cancelled
+	EventTypeMessageExpired = fftypes.FFEnumValue("eventtype", "message_expired")
+	// EventTypeDataRedacted occurs when the value and any blob of a private data item are removed from this node, leaving only its hash
+	EventTypeDataRedacted = fftypes.FFEnumValue("eventtype", "data_redacted")
 	// EventTypeDatatypeConfirmed occurs when a new datatype is ready for use (on the namespace of the datatype)
 	EventTypeDatatypeConfirmed = fftypes.FFEnumValue("eventtype", "datatype_confirmed")
 	// EventTypeIdentityConfirmed occurs when a new identity has been confirmed, as as result of a signed claim broadcast, and any associated claim verification
@@ -82,6 +86,7 @@ type EnrichedEvent struct {
 	BlockchainEvent   *BlockchainEvent `ffstruct:"EnrichedEvent" json:"blockchainEvent,omitempty"`
 	ContractAPI       *ContractAPI     `ffstruct:"EnrichedEvent" json:"contractAPI,omitempty"`
 	ContractInterface *fftypes.FFI     `ffstruct:"EnrichedEvent" json:"contractInterface,omitempty"`
+	Data              *Data            `ffstruct:"EnrichedEvent" json:"data,omitempty"`
 	Datatype          *Datatype        `ffstruct:"EnrichedEvent" json:"datatype,omitempty"`
 	Identity          *Identity        `ffstruct:"EnrichedEvent" json:"identity,omitempty"`
 	Message           *Message         `ffstruct:"EnrichedEvent" json:"message,omitempty"`
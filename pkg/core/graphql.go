@@ -0,0 +1,46 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// GraphQLRequest is a read-only GraphQL query against the data in a namespace
+type GraphQLRequest struct {
+	Query         string             `ffstruct:"GraphQLRequest" json:"query"`
+	OperationName string             `ffstruct:"GraphQLRequest" json:"operationName,omitempty"`
+	Variables     fftypes.JSONObject `ffstruct:"GraphQLRequest" json:"variables,omitempty"`
+}
+
+// GraphQLResponse is the result of a GraphQL query. Data is unset if the query could not be executed at all,
+// and is otherwise returned alongside any errors for individual fields (which are returned as null).
+type GraphQLResponse struct {
+	Data   interface{}     `ffstruct:"GraphQLResponse" json:"data,omitempty"`
+	Errors []*GraphQLError `ffstruct:"GraphQLResponse" json:"errors,omitempty"`
+}
+
+// GraphQLError is an error in a GraphQL query, or in resolving one of its fields
+type GraphQLError struct {
+	Message   string             `ffstruct:"GraphQLError" json:"message"`
+	Locations []*GraphQLLocation `ffstruct:"GraphQLError" json:"locations,omitempty"`
+	Path      []interface{}      `ffstruct:"GraphQLError" json:"path,omitempty"`
+}
+
+// GraphQLLocation is a position in a GraphQL query document
+type GraphQLLocation struct {
+	Line   int `ffstruct:"GraphQLLocation" json:"line"`
+	Column int `ffstruct:"GraphQLLocation" json:"column"`
+}
@@ -0,0 +1,86 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
+)
+
+// The segments of a JSON path are embedded into SQL expressions (including index definitions)
+// by the database plugins, so are restricted to a safe set of characters
+var jsonPathSegmentRegex = regexp.MustCompile(`^[a-zA-Z0-9_]{1,64}$`)
+
+const jsonPathMaxSegments = 16
+
+// JSONPathMatch matches data where the JSON value contains the supplied value, at a dot separated path of field names
+type JSONPathMatch struct {
+	Path  string
+	Value string
+}
+
+// ParseJSONPathMatch parses a match in the format "path=value", such as "order.id=123"
+func ParseJSONPathMatch(ctx context.Context, s string) (*JSONPathMatch, error) {
+	path, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return nil, i18n.NewError(ctx, coremsgs.MsgInvalidJSONPathMatch, s)
+	}
+	if err := ValidateJSONPath(ctx, path); err != nil {
+		return nil, err
+	}
+	return &JSONPathMatch{Path: path, Value: value}, nil
+}
+
+// ValidateJSONPath checks a path is a dot separated list of field names
+func ValidateJSONPath(ctx context.Context, path string) error {
+	segments := strings.Split(path, ".")
+	if len(segments) > jsonPathMaxSegments {
+		return i18n.NewError(ctx, coremsgs.MsgInvalidJSONPath, path)
+	}
+	for _, segment := range segments {
+		if !jsonPathSegmentRegex.MatchString(segment) {
+			return i18n.NewError(ctx, coremsgs.MsgInvalidJSONPath, path)
+		}
+	}
+	return nil
+}
+
+// Segments returns the field names in the path
+func (m *JSONPathMatch) Segments() []string {
+	return strings.Split(m.Path, ".")
+}
+
+// Candidates returns the JSON values that the match could refer to. As the value is supplied as text,
+// "123" might refer to either the number 123 or the string "123". A value that is not valid JSON, or is
+// a JSON object or array, is always treated as a string.
+func (m *JSONPathMatch) Candidates() []interface{} {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(m.Value), &parsed); err == nil {
+		switch parsed.(type) {
+		case string:
+			return []interface{}{parsed}
+		case float64, bool:
+			return []interface{}{parsed, m.Value}
+		}
+	}
+	return []interface{}{m.Value}
+}
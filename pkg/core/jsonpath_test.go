@@ -0,0 +1,55 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSONPathMatch(t *testing.T) {
+	m, err := ParseJSONPathMatch(context.Background(), "order.id=123=456")
+	assert.NoError(t, err)
+	assert.Equal(t, "order.id", m.Path)
+	assert.Equal(t, "123=456", m.Value)
+	assert.Equal(t, []string{"order", "id"}, m.Segments())
+
+	_, err = ParseJSONPathMatch(context.Background(), "order.id")
+	assert.Regexp(t, "FF10488", err)
+
+	_, err = ParseJSONPathMatch(context.Background(), "order..id=1")
+	assert.Regexp(t, "FF10487", err)
+}
+
+func TestValidateJSONPath(t *testing.T) {
+	assert.NoError(t, ValidateJSONPath(context.Background(), "a.b_c.D1"))
+	assert.Regexp(t, "FF10487", ValidateJSONPath(context.Background(), "a'b"))
+	assert.Regexp(t, "FF10487", ValidateJSONPath(context.Background(), ""))
+	assert.Regexp(t, "FF10487", ValidateJSONPath(context.Background(), strings.Repeat("a.", 16)+"a"))
+}
+
+func TestJSONPathMatchCandidates(t *testing.T) {
+	assert.Equal(t, []interface{}{float64(123), "123"}, (&JSONPathMatch{Value: "123"}).Candidates())
+	assert.Equal(t, []interface{}{true, "true"}, (&JSONPathMatch{Value: "true"}).Candidates())
+	assert.Equal(t, []interface{}{"123"}, (&JSONPathMatch{Value: `"123"`}).Candidates())
+	assert.Equal(t, []interface{}{"abc"}, (&JSONPathMatch{Value: "abc"}).Candidates())
+	assert.Equal(t, []interface{}{"null"}, (&JSONPathMatch{Value: "null"}).Candidates())
+	assert.Equal(t, []interface{}{`{"a":1}`}, (&JSONPathMatch{Value: `{"a":1}`}).Candidates())
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/hyperledger/firefly-common/pkg/i18n"
+	"github.com/hyperledger/firefly/internal/coremsgs"
 )
 
 const (
@@ -72,6 +73,18 @@ var (
 	MessageStateCancelled = fftypes.FFEnumValue("messagestate", "cancelled")
 )
 
+// MessagePriority is the local priority with which a message is batched and dispatched
+type MessagePriority = fftypes.FFEnum
+
+var (
+	// MessagePriorityHigh is for urgent messages, which are batched separately and pinned first when the blockchain is applying back-pressure
+	MessagePriorityHigh = fftypes.FFEnumValue("messagepriority", "high")
+	// MessagePriorityNormal is the default priority
+	MessagePriorityNormal = fftypes.FFEnumValue("messagepriority", "normal")
+	// MessagePriorityLow is for bulk messages, which give way to all other messages
+	MessagePriorityLow = fftypes.FFEnumValue("messagepriority", "low")
+)
+
 // MessageHeader contains all fields that contribute to the hash
 // The order of the serialization mut not change, once released
 type MessageHeader struct {
@@ -87,6 +100,7 @@ type MessageHeader struct {
 	Tag       string                `ffstruct:"MessageHeader" json:"tag,omitempty"`
 	DataHash  *fftypes.Bytes32      `ffstruct:"MessageHeader" json:"datahash,omitempty" ffexcludeinput:"true"`
 	TxParent  *TransactionRef       `ffstruct:"MessageHeader" json:"txparent,omitempty" ffexcludeinput:"true"`
+	Expiry    *fftypes.FFTime       `ffstruct:"MessageHeader" json:"expiry,omitempty"`
 }
 
 // Message is the envelope by which coordinated data exchange can happen between parties in the network
@@ -104,6 +118,8 @@ type Message struct {
 	Data           DataRefs              `ffstruct:"Message" json:"data" ffexcludeinput:"true"`
 	Pins           fftypes.FFStringArray `ffstruct:"Message" json:"pins,omitempty" ffexcludeinput:"true"`
 	IdempotencyKey IdempotencyKey        `ffstruct:"Message" json:"idempotencyKey,omitempty"`
+	Scheduled      *fftypes.FFTime       `ffstruct:"Message" json:"scheduled,omitempty"`
+	Priority       MessagePriority       `ffstruct:"Message" json:"priority,omitempty" ffenum:"messagepriority"`
 	Sequence       int64                 `ffstruct:"Message" json:"-"` // Local database sequence used internally for batch assembly
 }
 
@@ -129,7 +145,13 @@ func (m *Message) BatchMessage() *Message {
 type MessageInOut struct {
 	Message
 	InlineData InlineData  `ffstruct:"MessageInOut" json:"data,omitempty"`
-	Group      *InputGroup `ffstruct:"MessageInOut" json:"group,omitempty" ffexclude:"postNewMessageBroadcast"`
+	Group      *InputGroup `ffstruct:"MessageInOut" json:"group,omitempty" ffexclude:"postNewMessageBroadcast,postNewMessageBroadcastBulk"`
+}
+
+// MessageBulkResult is the result for one message submitted in a bulk request, in the same order as the request
+type MessageBulkResult struct {
+	Message *Message `ffstruct:"MessageBulkResult" json:"message,omitempty"`
+	Error   string   `ffstruct:"MessageBulkResult" json:"error,omitempty"`
 }
 
 // InputGroup declares a group in-line for automatic resolution, without having to define a group up-front
@@ -164,6 +186,16 @@ func (h *MessageHeader) Hash() *fftypes.Bytes32 {
 	return &b32
 }
 
+// Expired returns true if the message has an expiry, and it is not after the supplied time
+func (h *MessageHeader) Expired(at *fftypes.FFTime) bool {
+	return h.Expiry != nil && at != nil && !h.Expiry.Time().After(*at.Time())
+}
+
+// ScheduledAfter returns true if the message has a scheduled send time, and it is after the supplied time
+func (m *Message) ScheduledAfter(at *fftypes.FFTime) bool {
+	return m.Scheduled != nil && at != nil && m.Scheduled.Time().After(*at.Time())
+}
+
 func (m *MessageInOut) SetInlineData(data []*Data) {
 	m.InlineData = make(InlineData, len(data))
 	for i, d := range data {
@@ -211,6 +243,18 @@ func (m *Message) Seal(ctx context.Context) (err error) {
 	if m.Header.TxType == "" {
 		m.Header.TxType = TransactionTypeBatchPin
 	}
+	if m.Priority == "" {
+		m.Priority = MessagePriorityNormal
+	}
+	if m.Priority, err = fftypes.FFEnumParseString(ctx, "messagepriority", m.Priority.String()); err != nil {
+		return err
+	}
+	if m.Header.Expired(m.Header.Created) {
+		return i18n.NewError(ctx, coremsgs.MsgMessageExpiryInPast, m.Header.Expiry)
+	}
+	if m.Header.Expired(m.Scheduled) {
+		return i18n.NewError(ctx, coremsgs.MsgMessageExpiryBeforeScheduled, m.Header.Expiry, m.Scheduled)
+	}
 	err = m.VerifyFields(ctx)
 	if err == nil {
 		m.Header.DataHash = m.Data.Hash()
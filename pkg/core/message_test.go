@@ -21,6 +21,7 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -66,6 +67,71 @@ func TestSealBadTagString(t *testing.T) {
 	assert.Regexp(t, `FF00140.*header.tag`, err)
 }
 
+func TestSealExpiryInPast(t *testing.T) {
+	expiry := fftypes.FFTime(time.Now().Add(-1 * time.Minute))
+	msg := Message{
+		Header: MessageHeader{
+			Expiry: &expiry,
+		},
+	}
+	err := msg.Seal(context.Background())
+	assert.Regexp(t, "FF10497", err)
+}
+
+func TestSealPriority(t *testing.T) {
+	msg := Message{}
+	err := msg.Seal(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, MessagePriorityNormal, msg.Priority)
+
+	msg = Message{Priority: "HIGH"}
+	err = msg.Seal(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, MessagePriorityHigh, msg.Priority)
+
+	msg = Message{Priority: "urgent"}
+	err = msg.Seal(context.Background())
+	assert.Regexp(t, "FF00172", err)
+}
+
+func TestSealExpiryBeforeScheduled(t *testing.T) {
+	now := fftypes.Now()
+	scheduled := fftypes.FFTime(now.Time().Add(2 * time.Minute))
+	expiry := fftypes.FFTime(now.Time().Add(1 * time.Minute))
+	msg := Message{
+		Header: MessageHeader{
+			Expiry: &expiry,
+		},
+		Scheduled: &scheduled,
+	}
+	err := msg.Seal(context.Background())
+	assert.Regexp(t, "FF10501", err)
+}
+
+func TestMessageScheduledAfter(t *testing.T) {
+	now := fftypes.Now()
+	m := Message{}
+	assert.False(t, m.ScheduledAfter(now))
+	m.Scheduled = now
+	assert.False(t, m.ScheduledAfter(now))
+	assert.False(t, m.ScheduledAfter(nil))
+	before := fftypes.FFTime(now.Time().Add(-1 * time.Second))
+	assert.True(t, m.ScheduledAfter(&before))
+}
+
+func TestMessageExpired(t *testing.T) {
+	now := fftypes.Now()
+	h := MessageHeader{}
+	assert.False(t, h.Expired(now))
+	h.Expiry = now
+	assert.True(t, h.Expired(now))
+	assert.False(t, h.Expired(nil))
+	before := fftypes.FFTime(now.Time().Add(-1 * time.Second))
+	assert.False(t, h.Expired(&before))
+	after := fftypes.FFTime(now.Time().Add(1 * time.Second))
+	assert.True(t, h.Expired(&after))
+}
+
 func TestVerifyTXType(t *testing.T) {
 	msg := Message{
 		Header: MessageHeader{
@@ -35,12 +35,49 @@ type Namespace struct {
 	Created     *fftypes.FFTime        `ffstruct:"Namespace" json:"created" ffexcludeinput:"true"`
 	Contracts   *MultipartyContracts   `ffstruct:"Namespace" json:"-"`
 	TLSConfigs  map[string]*tls.Config `ffstruct:"Namespace" json:"-" ffexcludeinput:"true"`
+	Definition  *NamespaceDefinition   `ffstruct:"Namespace" json:"definition,omitempty" ffexcludeinput:"true"`
+}
+
+// NamespaceDefinition is the definition of a namespace created at runtime through the admin API, rather than
+// being defined in the config file. It can only reference plugins that are already configured on the node.
+type NamespaceDefinition struct {
+	Name                  string                         `ffstruct:"NamespaceDefinition" json:"name" ffexclude:"spiPutNamespace"`
+	Description           string                         `ffstruct:"NamespaceDefinition" json:"description,omitempty"`
+	Plugins               []string                       `ffstruct:"NamespaceDefinition" json:"plugins,omitempty"`
+	DefaultKey            string                         `ffstruct:"NamespaceDefinition" json:"defaultKey,omitempty"`
+	AssetKeyNormalization string                         `ffstruct:"NamespaceDefinition" json:"assetKeyNormalization,omitempty"`
+	Multiparty            *NamespaceDefinitionMultiparty `ffstruct:"NamespaceDefinition" json:"multiparty,omitempty"`
+	Stopped               bool                           `ffstruct:"NamespaceDefinition" json:"stopped,omitempty" ffexcludeinput:"true"`
+}
+
+// NamespaceDefinitionMultiparty is the multiparty section of a namespace definition
+type NamespaceDefinitionMultiparty struct {
+	Enabled          bool                           `ffstruct:"NamespaceDefinitionMultiparty" json:"enabled"`
+	NetworkNamespace string                         `ffstruct:"NamespaceDefinitionMultiparty" json:"networkNamespace,omitempty"`
+	Org              NamespaceDefinitionMember      `ffstruct:"NamespaceDefinitionMultiparty" json:"org"`
+	Node             NamespaceDefinitionMember      `ffstruct:"NamespaceDefinitionMultiparty" json:"node"`
+	Contract         []*NamespaceDefinitionContract `ffstruct:"NamespaceDefinitionMultiparty" json:"contract,omitempty"`
+}
+
+// NamespaceDefinitionMember is the local org or node within a multiparty namespace definition
+type NamespaceDefinitionMember struct {
+	Name        string `ffstruct:"NamespaceDefinitionMember" json:"name,omitempty"`
+	Key         string `ffstruct:"NamespaceDefinitionMember" json:"key,omitempty"`
+	Description string `ffstruct:"NamespaceDefinitionMember" json:"description,omitempty"`
+}
+
+// NamespaceDefinitionContract is a FireFly multiparty contract within a namespace definition
+type NamespaceDefinitionContract struct {
+	Location   *fftypes.JSONAny `ffstruct:"NamespaceDefinitionContract" json:"location,omitempty"`
+	FirstEvent string           `ffstruct:"NamespaceDefinitionContract" json:"firstEvent,omitempty"`
+	Options    *fftypes.JSONAny `ffstruct:"NamespaceDefinitionContract" json:"options,omitempty"`
 }
 
 type NamespaceWithInitStatus struct {
 	*Namespace
 	Initializing        bool   `ffstruct:"NamespaceWithInitStatus" json:"initializing,omitempty"`
 	InitializationError string `ffstruct:"NamespaceWithInitStatus" json:"initializationError,omitempty"`
+	Stopped             bool   `ffstruct:"NamespaceWithInitStatus" json:"stopped,omitempty"`
 }
 
 // MultipartyContracts represent the currently active and any terminated FireFly multiparty contract(s)
@@ -115,3 +152,23 @@ func (fc *MultipartyContracts) Scan(src interface{}) error {
 func (fc MultipartyContracts) Value() (driver.Value, error) {
 	return json.Marshal(fc)
 }
+
+// Scan implements sql.Scanner
+func (nd *NamespaceDefinition) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case []byte:
+		if len(src) == 0 {
+			return nil
+		}
+		return json.Unmarshal(src, nd)
+	case string:
+		return nd.Scan([]byte(src))
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgTypeRestoreFailed, src, nd)
+	}
+}
+
+// Value implements sql.Valuer
+func (nd NamespaceDefinition) Value() (driver.Value, error) {
+	return json.Marshal(&nd)
+}
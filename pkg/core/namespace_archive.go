@@ -0,0 +1,34 @@
+// Copyright © 2024 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "github.com/hyperledger/firefly-common/pkg/fftypes"
+
+// NamespaceArchiveSummary is the manifest written at the end of a namespace export, and returned on import
+// once the contents of the archive have been restored and verified
+type NamespaceArchiveSummary struct {
+	Namespace   string                        `ffstruct:"NamespaceArchiveSummary" json:"namespace"`
+	Created     *fftypes.FFTime               `ffstruct:"NamespaceArchiveSummary" json:"created"`
+	Collections []*NamespaceArchiveCollection `ffstruct:"NamespaceArchiveSummary" json:"collections"`
+}
+
+// NamespaceArchiveCollection is the number of records, and the hash of those records, for one collection in a namespace archive
+type NamespaceArchiveCollection struct {
+	Name  string           `ffstruct:"NamespaceArchiveCollection" json:"name"`
+	Count int64            `ffstruct:"NamespaceArchiveCollection" json:"count"`
+	Hash  *fftypes.Bytes32 `ffstruct:"NamespaceArchiveCollection" json:"hash"`
+}
@@ -69,8 +69,41 @@ type NamespaceStatusPlugins struct {
 
 // NamespaceStatusPlugin is information about a plugin
 type NamespaceStatusPlugin struct {
-	Name       string `ffstruct:"NamespaceStatusPlugin" json:"name,omitempty"`
-	PluginType string `ffstruct:"NamespaceStatusPlugin" json:"pluginType"`
+	Name       string        `ffstruct:"NamespaceStatusPlugin" json:"name,omitempty"`
+	PluginType string        `ffstruct:"NamespaceStatusPlugin" json:"pluginType"`
+	Health     *PluginHealth `ffstruct:"NamespaceStatusPlugin" json:"health,omitempty"`
+}
+
+// PluginHealth is the result of probing the service behind a plugin, such as a blockchain connector or database
+type PluginHealth struct {
+	Healthy       bool               `ffstruct:"PluginHealth" json:"healthy"`
+	Latency       fftypes.FFDuration `ffstruct:"PluginHealth" json:"latency"`
+	Checked       *fftypes.FFTime    `ffstruct:"PluginHealth" json:"checked"`
+	LastError     string             `ffstruct:"PluginHealth" json:"lastError,omitempty"`
+	LastErrorTime *fftypes.FFTime    `ffstruct:"PluginHealth" json:"lastErrorTime,omitempty"`
+	EventStream   *EventStreamHealth `ffstruct:"PluginHealth" json:"eventStream,omitempty"`
+}
+
+// EventStreamHealth is the state of the websocket event stream from a connector to a plugin
+type EventStreamHealth struct {
+	Connected bool            `ffstruct:"EventStreamHealth" json:"connected"`
+	Lagging   bool            `ffstruct:"EventStreamHealth" json:"lagging,omitempty"`
+	LastEvent *fftypes.FFTime `ffstruct:"EventStreamHealth" json:"lastEvent,omitempty"`
+}
+
+// NodeReadiness is the readiness of the node to process work, across all namespaces
+type NodeReadiness struct {
+	Ready      bool                  `ffstruct:"NodeReadiness" json:"ready"`
+	Namespaces []*NamespaceReadiness `ffstruct:"NodeReadiness" json:"namespaces"`
+}
+
+// NamespaceReadiness is the readiness of an individual namespace
+type NamespaceReadiness struct {
+	Name                string   `ffstruct:"NamespaceReadiness" json:"name"`
+	Ready               bool     `ffstruct:"NamespaceReadiness" json:"ready"`
+	Initializing        bool     `ffstruct:"NamespaceReadiness" json:"initializing,omitempty"`
+	InitializationError string   `ffstruct:"NamespaceReadiness" json:"initializationError,omitempty"`
+	UnhealthyPlugins    []string `ffstruct:"NamespaceReadiness" json:"unhealthyPlugins,omitempty"`
 }
 
 // NamespaceStatusMultiparty is information about multiparty mode and any associated multiparty contracts
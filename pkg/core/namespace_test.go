@@ -74,3 +74,35 @@ func TestMultipartyContractsDatabaseSerialization(t *testing.T) {
 	err = contracts2.Scan(false)
 	assert.Regexp(t, "FF00105", err)
 }
+
+func TestNamespaceDefinitionDatabaseSerialization(t *testing.T) {
+	def1 := &NamespaceDefinition{
+		Name:    "ns1",
+		Plugins: []string{"postgres", "ethereum"},
+		Multiparty: &NamespaceDefinitionMultiparty{
+			Enabled: true,
+			Org:     NamespaceDefinitionMember{Name: "org1"},
+		},
+		Stopped: true,
+	}
+
+	// Verify it serializes as bytes to the database
+	val1, err := def1.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"ns1","plugins":["postgres","ethereum"],"multiparty":{"enabled":true,"org":{"name":"org1"},"node":{}},"stopped":true}`, string(val1.([]byte)))
+
+	// Verify it restores ok
+	def2 := &NamespaceDefinition{}
+	err = def2.Scan(val1)
+	assert.NoError(t, err)
+	assert.Equal(t, *def1, *def2)
+
+	// Verify it ignores a blank string
+	err = def2.Scan("")
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", def2.Name)
+
+	// Out of luck with anything else
+	err = def2.Scan(false)
+	assert.Regexp(t, "FF00105", err)
+}
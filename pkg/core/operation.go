@@ -18,6 +18,8 @@ package core
 
 import (
 	"context"
+	"database/sql/driver"
+	"encoding/json"
 	"strings"
 
 	"github.com/hyperledger/firefly-common/pkg/fftypes"
@@ -108,6 +110,13 @@ func (op *Operation) DeepCopy() *Operation {
 	if op.Output != nil {
 		cop.Output = deepCopyMap(op.Output)
 	}
+	if op.Policy != nil {
+		cop.Policy = make(OperationPolicyLog, len(op.Policy))
+		for i, d := range op.Policy {
+			dCopy := *d
+			cop.Policy[i] = &dCopy
+		}
+	}
 	return cop
 }
 
@@ -196,6 +205,82 @@ type Operation struct {
 	Created     *fftypes.FFTime    `ffstruct:"Operation" json:"created,omitempty" ffexcludeinput:"true"`
 	Updated     *fftypes.FFTime    `ffstruct:"Operation" json:"updated,omitempty" ffexcludeinput:"true"`
 	Retry       *fftypes.UUID      `ffstruct:"Operation" json:"retry,omitempty" ffexcludeinput:"true"`
+	Policy      OperationPolicyLog `ffstruct:"Operation" json:"policy,omitempty" ffexcludeinput:"true"`
+}
+
+// OpPolicyAction is an action taken automatically on an operation, by the timeout and retry policy for its type
+type OpPolicyAction = fftypes.FFEnum
+
+var (
+	// OpPolicyActionTimeoutFailed the operation received no update within the timeout, and was marked failed
+	OpPolicyActionTimeoutFailed = fftypes.FFEnumValue("oppolicyaction", "timeout_failed")
+	// OpPolicyActionTimeoutQueried the operation received no update within the timeout, and its status was queried from the connector
+	OpPolicyActionTimeoutQueried = fftypes.FFEnumValue("oppolicyaction", "timeout_queried")
+	// OpPolicyActionRetried the operation failed, and was retried
+	OpPolicyActionRetried = fftypes.FFEnumValue("oppolicyaction", "retried")
+	// OpPolicyActionRetryNotEligible the operation failed with an error that the policy does not retry
+	OpPolicyActionRetryNotEligible = fftypes.FFEnumValue("oppolicyaction", "retry_not_eligible")
+	// OpPolicyActionRetryExhausted the operation failed, and has already been retried the maximum number of times
+	OpPolicyActionRetryExhausted = fftypes.FFEnumValue("oppolicyaction", "retry_exhausted")
+)
+
+// OperationPolicyDecision records a single action taken by the policy for the operation type
+type OperationPolicyDecision struct {
+	Action OpPolicyAction  `ffstruct:"OperationPolicyDecision" json:"action" ffenum:"oppolicyaction"`
+	Time   *fftypes.FFTime `ffstruct:"OperationPolicyDecision" json:"time"`
+	Count  int             `ffstruct:"OperationPolicyDecision" json:"count,omitempty"`
+	Reason string          `ffstruct:"OperationPolicyDecision" json:"reason,omitempty"`
+	Retry  *fftypes.UUID   `ffstruct:"OperationPolicyDecision" json:"retry,omitempty"`
+}
+
+// OperationPolicyLog is the history of policy decisions for an operation, including those inherited from
+// the operations it retries
+type OperationPolicyLog []*OperationPolicyDecision
+
+// Last returns the most recent decision, or nil
+func (pl OperationPolicyLog) Last() *OperationPolicyDecision {
+	if len(pl) == 0 {
+		return nil
+	}
+	return pl[len(pl)-1]
+}
+
+// Count returns the number of decisions with the given action
+func (pl OperationPolicyLog) Count(action OpPolicyAction) int {
+	count := 0
+	for _, d := range pl {
+		if d.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Scan implements sql.Scanner
+func (pl *OperationPolicyLog) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		*pl = nil
+		return nil
+	case []byte:
+		if len(src) == 0 {
+			*pl = nil
+			return nil
+		}
+		return json.Unmarshal(src, pl)
+	case string:
+		return pl.Scan([]byte(src))
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgTypeRestoreFailed, src, pl)
+	}
+}
+
+// Value implements sql.Valuer
+func (pl OperationPolicyLog) Value() (driver.Value, error) {
+	if len(pl) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(pl)
 }
 
 // OperationUpdateDTO is the subset of fields on an operation that are mutable, via the SPI
@@ -112,6 +112,9 @@ func TestOperationDeepCopy(t *testing.T) {
 		Created:     fftypes.Now(),
 		Updated:     fftypes.Now(),
 		Retry:       fftypes.NewUUID(),
+		Policy: OperationPolicyLog{
+			{Action: OpPolicyActionRetried, Time: fftypes.Now(), Retry: fftypes.NewUUID()},
+		},
 	}
 
 	copyOp := op.DeepCopy()
@@ -130,6 +133,7 @@ func TestOperationDeepCopy(t *testing.T) {
 	assert.Equal(t, op.Created, copyOp.Created)
 	assert.Equal(t, op.Updated, copyOp.Updated)
 	assert.Equal(t, op.Retry, copyOp.Retry)
+	assert.Equal(t, op.Policy, copyOp.Policy)
 
 	// Modify the original and ensure the copy is not modified
 	*op.ID = *fftypes.NewUUID()
@@ -146,6 +150,7 @@ func TestOperationDeepCopy(t *testing.T) {
 	assert.NotSame(t, copyOp.Retry, op.Retry)
 	assert.NotSame(t, copyOp.Input, op.Input)
 	assert.NotSame(t, copyOp.Output, op.Output)
+	assert.NotSame(t, copyOp.Policy[0], op.Policy[0])
 
 	// showcasing that the shallow copy is a shallow copy and the copied object value changed as well the pointer has the same address as the original
 	assert.Equal(t, shallowCopy.ID, op.ID)
@@ -153,7 +158,47 @@ func TestOperationDeepCopy(t *testing.T) {
 
 	// Ensure no new fields are added to the Operation struct
 	// If a new field is added, this test will fail and the DeepCopy function should be updated
-	assert.Equal(t, 12, reflect.TypeOf(Operation{}).NumField())
+	assert.Equal(t, 13, reflect.TypeOf(Operation{}).NumField())
+}
+
+func TestOperationPolicyLog(t *testing.T) {
+	var pl OperationPolicyLog
+	assert.Nil(t, pl.Last())
+	v, err := pl.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	pl = OperationPolicyLog{
+		{Action: OpPolicyActionTimeoutFailed},
+		{Action: OpPolicyActionRetried},
+		{Action: OpPolicyActionRetried},
+	}
+	assert.Equal(t, OpPolicyActionRetried, pl.Last().Action)
+	assert.Equal(t, 2, pl.Count(OpPolicyActionRetried))
+
+	v, err = pl.Value()
+	assert.NoError(t, err)
+
+	var pl2 OperationPolicyLog
+	err = pl2.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, pl, pl2)
+
+	err = pl2.Scan(string(v.([]byte)))
+	assert.NoError(t, err)
+	assert.Equal(t, pl, pl2)
+
+	err = pl2.Scan([]byte{})
+	assert.NoError(t, err)
+	assert.Nil(t, pl2)
+
+	pl2 = pl
+	err = pl2.Scan(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, pl2)
+
+	err = pl2.Scan(12345)
+	assert.Regexp(t, "FF00105", err)
 }
 func TestParseNamespacedOpID(t *testing.T) {
 
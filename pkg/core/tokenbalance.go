@@ -29,6 +29,32 @@ type TokenBalance struct {
 	Updated    *fftypes.FFTime  `ffstruct:"TokenBalance" json:"updated,omitempty"`
 }
 
+// TokenBalanceChange is a historical record of a single change to a token balance,
+// written each time a confirmed transfer is applied to an account
+type TokenBalanceChange struct {
+	Pool            *fftypes.UUID    `ffstruct:"TokenBalanceChange" json:"pool,omitempty"`
+	TokenIndex      string           `ffstruct:"TokenBalanceChange" json:"tokenIndex,omitempty"`
+	URI             string           `ffstruct:"TokenBalanceChange" json:"uri,omitempty"`
+	Connector       string           `ffstruct:"TokenBalanceChange" json:"connector,omitempty"`
+	Namespace       string           `ffstruct:"TokenBalanceChange" json:"namespace,omitempty"`
+	Key             string           `ffstruct:"TokenBalanceChange" json:"key,omitempty"`
+	Amount          fftypes.FFBigInt `ffstruct:"TokenBalanceChange" json:"amount"`
+	Debit           bool             `ffstruct:"TokenBalanceChange" json:"debit"`
+	Balance         fftypes.FFBigInt `ffstruct:"TokenBalanceChange" json:"balance"`
+	Transfer        *fftypes.UUID    `ffstruct:"TokenBalanceChange" json:"transfer,omitempty"`
+	ProtocolID      string           `ffstruct:"TokenBalanceChange" json:"protocolId,omitempty"`
+	BlockchainEvent *fftypes.UUID    `ffstruct:"TokenBalanceChange" json:"blockchainEvent,omitempty"`
+	Created         *fftypes.FFTime  `ffstruct:"TokenBalanceChange" json:"created,omitempty"`
+	Timestamp       *fftypes.FFTime  `ffstruct:"TokenBalanceChange" json:"timestamp,omitempty"`
+}
+
+// TokenBalancePointInTime selects the point in history at which to reconstruct token balances.
+// Either or both of the fields can be set - only changes at or before all of the set bounds are included.
+type TokenBalancePointInTime struct {
+	Timestamp  *fftypes.FFTime
+	ProtocolID string
+}
+
 func TokenBalanceIdentifier(pool *fftypes.UUID, tokenIndex, identity string) string {
 	return pool.String() + ":" + tokenIndex + ":" + identity
 }
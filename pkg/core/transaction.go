@@ -71,6 +71,22 @@ type Transaction struct {
 	BlockchainIDs  fftypes.FFStringArray `ffstruct:"Transaction" json:"blockchainIds,omitempty"`
 }
 
+// TransactionWithDetail is a transaction, together with the operations and other records that are part of it
+type TransactionWithDetail struct {
+	Transaction
+	Operations     []*Operation     `ffstruct:"TransactionWithDetail" json:"operations"`
+	Messages       []*Message       `ffstruct:"TransactionWithDetail" json:"messages"`
+	TokenTransfers []*TokenTransfer `ffstruct:"TransactionWithDetail" json:"tokenTransfers"`
+	TokenApprovals []*TokenApproval `ffstruct:"TransactionWithDetail" json:"tokenApprovals"`
+}
+
+// BlockchainTransaction is everything recorded in a namespace about a single blockchain transaction
+type BlockchainTransaction struct {
+	BlockchainID     string                   `ffstruct:"BlockchainTransaction" json:"blockchainId"`
+	Transactions     []*TransactionWithDetail `ffstruct:"BlockchainTransaction" json:"transactions"`
+	BlockchainEvents []*BlockchainEvent       `ffstruct:"BlockchainTransaction" json:"blockchainEvents"`
+}
+
 type TransactionStatusType string
 
 var (
@@ -65,6 +65,10 @@ type Plugin interface {
 
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
+
+	// CheckHealth pings the database, returning an error if it cannot be reached.
+	// The database has no event stream, so the returned stream health is always nil.
+	CheckHealth(ctx context.Context, namespace string) (*core.EventStreamHealth, error)
 }
 
 type iNamespaceCollection interface {
@@ -73,6 +77,9 @@ type iNamespaceCollection interface {
 
 	// GetNamespace - Get an namespace by name
 	GetNamespace(ctx context.Context, name string) (namespace *core.Namespace, err error)
+
+	// GetDynamicNamespaces - Get all namespaces that were defined at runtime through the admin API
+	GetDynamicNamespaces(ctx context.Context) (namespaces []*core.Namespace, err error)
 }
 
 type iMessageCollection interface {
@@ -91,8 +98,8 @@ type iMessageCollection interface {
 	// A new event is raised for the message, with the new sequence number - as if it was brand new.
 	ReplaceMessage(ctx context.Context, message *core.Message) (err error)
 
-	// UpdateMessages - Update messages
-	UpdateMessages(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) (err error)
+	// UpdateMessages - Update messages, returning the number of messages updated
+	UpdateMessages(ctx context.Context, namespace string, filter ffapi.Filter, update ffapi.Update) (updated int64, err error)
 
 	// GetMessageByID - Get a message by ID
 	GetMessageByID(ctx context.Context, namespace string, id *fftypes.UUID) (message *core.Message, err error)
@@ -106,11 +113,17 @@ type iMessageCollection interface {
 	// GetMessagesForData - List messages where there is a data reference to the specified ID
 	GetMessagesForData(ctx context.Context, namespace string, dataID *fftypes.UUID, filter ffapi.Filter) (message []*core.Message, res *ffapi.FilterResult, err error)
 
+	// GetMessagesByDataValue - List messages that reference data with JSON values matching all of the JSON path matches
+	GetMessagesByDataValue(ctx context.Context, namespace string, matches []*core.JSONPathMatch, filter ffapi.Filter) (message []*core.Message, res *ffapi.FilterResult, err error)
+
 	// GetBatchIDsForMessages - an optimized query to retrieve any non-null batch IDs for a list of message IDs
 	GetBatchIDsForMessages(ctx context.Context, namespace string, msgIDs []*fftypes.UUID) (batchIDs []*fftypes.UUID, err error)
 
 	// GetBatchIDsForDataAttachments - an optimized query to retrieve any non-null batch IDs for a list of data IDs that might be attached to messages in batches
 	GetBatchIDsForDataAttachments(ctx context.Context, namespace string, dataIDs []*fftypes.UUID) (batchIDs []*fftypes.UUID, err error)
+
+	// DeleteMessages - Delete a set of messages by ID, along with their data references (but not the data itself)
+	DeleteMessages(ctx context.Context, namespace string, ids []*fftypes.UUID) (err error)
 }
 
 type iDataCollection interface {
@@ -131,6 +144,9 @@ type iDataCollection interface {
 	// GetData - Get data
 	GetData(ctx context.Context, namespace string, filter ffapi.Filter) (message core.DataArray, res *ffapi.FilterResult, err error)
 
+	// GetDataByValue - Get data with JSON values matching all of the JSON path matches
+	GetDataByValue(ctx context.Context, namespace string, matches []*core.JSONPathMatch, filter ffapi.Filter) (message core.DataArray, res *ffapi.FilterResult, err error)
+
 	// GetDataSubPaths - returns unique paths that have files in them, under the specified path.
 	// Requires DB specific processing of the blob.path field.
 	GetDataSubPaths(ctx context.Context, namespace, path string) (subPaths []string, err error)
@@ -171,6 +187,12 @@ type iTransactionCollection interface {
 
 	// GetTransactions - Get transactions
 	GetTransactions(ctx context.Context, namespace string, filter ffapi.Filter) (txn []*core.Transaction, res *ffapi.FilterResult, err error)
+
+	// GetTransactionsByBlockchainID - Get the transactions that include a blockchain transaction, using an indexed lookup
+	GetTransactionsByBlockchainID(ctx context.Context, namespace, blockchainID string, filter ffapi.Filter) (txn []*core.Transaction, res *ffapi.FilterResult, err error)
+
+	// DeleteTransactions - Delete a set of transactions by ID
+	DeleteTransactions(ctx context.Context, namespace string, ids []*fftypes.UUID) (err error)
 }
 
 type iDatatypeCollection interface {
@@ -233,6 +255,9 @@ type iOperationCollection interface {
 
 	// GetOperations - Get operation
 	GetOperations(ctx context.Context, namespace string, filter ffapi.Filter) (operation []*core.Operation, res *ffapi.FilterResult, err error)
+
+	// DeleteOperations - Delete a set of operations by ID
+	DeleteOperations(ctx context.Context, namespace string, ids []*fftypes.UUID) (err error)
 }
 
 type iSubscriptionCollection interface {
@@ -263,6 +288,10 @@ type iEventCollection interface {
 	//               to hold an exclusive table lock.
 	InsertEvent(ctx context.Context, data *core.Event) (err error)
 
+	// InsertEvents - Insert a set of events, in order. Unlike InsertEvent the sequences are allocated immediately,
+	//                rather than when the transaction commits, with the namespace lock held until the commit.
+	InsertEvents(ctx context.Context, events []*core.Event) (err error)
+
 	// GetEventByID - Get a event by ID
 	GetEventByID(ctx context.Context, namespace string, id *fftypes.UUID) (message *core.Event, err error)
 
@@ -271,6 +300,9 @@ type iEventCollection interface {
 
 	// GetEventsInSequenceRange - Get a range of events between 2 sequence values
 	GetEventsInSequenceRange(ctx context.Context, namespace string, filter ffapi.Filter, startSequence int, endSequence int) (message []*core.Event, res *ffapi.FilterResult, err error)
+
+	// DeleteEvents - Delete a set of events by ID
+	DeleteEvents(ctx context.Context, namespace string, ids []*fftypes.UUID) (err error)
 }
 
 type iIdentitiesCollection interface {
@@ -400,8 +432,14 @@ type iTokenBalanceCollection interface {
 	// GetTokenAccountPools - Get the list of pools referenced by a given account
 	GetTokenAccountPools(ctx context.Context, namespace, key string, filter ffapi.Filter) ([]*core.TokenAccountPool, *ffapi.FilterResult, error)
 
-	// DeleteTokenBalances - Delete token balances from a particular pool
+	// DeleteTokenBalances - Delete token balances (and their history) from a particular pool
 	DeleteTokenBalances(ctx context.Context, namespace string, poolID *fftypes.UUID) error
+
+	// GetTokenBalanceHistory - Get the recorded changes to token balances
+	GetTokenBalanceHistory(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.TokenBalanceChange, *ffapi.FilterResult, error)
+
+	// GetTokenBalancesAsOf - Get token balances as they were at a point in time, reconstructed from the balance history
+	GetTokenBalancesAsOf(ctx context.Context, namespace string, asOf *core.TokenBalancePointInTime, filter ffapi.Filter) ([]*core.TokenBalance, *ffapi.FilterResult, error)
 }
 
 type iTokenTransferCollection interface {
@@ -563,6 +601,20 @@ type iBlockchainEventCollection interface {
 
 	// GetBlockchainEvents - get blockchain events
 	GetBlockchainEvents(ctx context.Context, namespace string, filter ffapi.Filter) ([]*core.BlockchainEvent, *ffapi.FilterResult, error)
+
+	// DeleteBlockchainEvents - Delete a set of blockchain events by ID
+	DeleteBlockchainEvents(ctx context.Context, namespace string, ids []*fftypes.UUID) (err error)
+}
+
+type iAuditRecordCollection interface {
+	// InsertAuditRecord - Append a record to the audit log. Audit records are never updated or deleted
+	InsertAuditRecord(ctx context.Context, record *core.AuditRecord) (err error)
+
+	// GetLastAuditRecord - Get the most recent audit record in a namespace, or nil if there are none
+	GetLastAuditRecord(ctx context.Context, namespace string) (record *core.AuditRecord, err error)
+
+	// GetAuditRecords - Get audit records
+	GetAuditRecords(ctx context.Context, namespace string, filter ffapi.Filter) (records []*core.AuditRecord, res *ffapi.FilterResult, err error)
 }
 
 // PersistenceInterface are the operations that must be implemented by a database interface plugin.
@@ -634,6 +686,7 @@ type PersistenceInterface interface {
 	iContractListenerCollection
 	iBlockchainEventCollection
 	iChartCollection
+	iAuditRecordCollection
 }
 
 // CollectionName represents all collections
@@ -706,6 +759,21 @@ const (
 	CollectionTokenBalances OtherCollection = "tokenbalances"
 )
 
+type readReplicaContextKey struct{}
+
+// WithReadReplica marks a context as tolerant of reading from a read replica of the database,
+// which might lag the primary. Plugins that support a read replica only route non-transactional
+// reads to it for contexts marked in this way (such as the handling of API queries).
+func WithReadReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readReplicaContextKey{}, true)
+}
+
+// ReadReplicaAllowed returns true if the context has been marked with WithReadReplica
+func ReadReplicaAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(readReplicaContextKey{}).(bool)
+	return allowed
+}
+
 // PostCompletionHook is a closure/function that will be called after a successful insertion.
 // This includes where the insert is nested in a RunAsGroup, and the database is transactional.
 // These hooks are useful when triggering code that relies on the inserted database object being available.
@@ -765,6 +833,9 @@ var MessageQueryFactory = &ffapi.QueryFields{
 	"txid":           &ffapi.UUIDField{},
 	"txparent.type":  &ffapi.StringField{},
 	"txparent.id":    &ffapi.UUIDField{},
+	"expiry":         &ffapi.TimeField{},
+	"scheduled":      &ffapi.TimeField{},
+	"priority":       &ffapi.StringField{},
 }
 
 // BatchQueryFactory filter fields for batches
@@ -807,6 +878,9 @@ var DataQueryFactory = &ffapi.QueryFields{
 	"created":          &ffapi.TimeField{},
 	"value":            &ffapi.JSONField{},
 	"public":           &ffapi.StringField{},
+	"redacted.created": &ffapi.TimeField{},
+	"redacted.author":  &ffapi.StringField{},
+	"redacted.by":      &ffapi.StringField{},
 }
 
 // DatatypeQueryFactory filter fields for data definitions
@@ -839,6 +913,7 @@ var OperationQueryFactory = &ffapi.QueryFields{
 	"created": &ffapi.TimeField{},
 	"updated": &ffapi.TimeField{},
 	"retry":   &ffapi.UUIDField{},
+	"policy":  &ffapi.JSONField{},
 }
 
 // SubscriptionQueryFactory filter fields for data subscriptions
@@ -864,6 +939,24 @@ var EventQueryFactory = &ffapi.QueryFields{
 	"created":    &ffapi.TimeField{},
 }
 
+// AuditRecordQueryFactory filter fields for audit records
+var AuditRecordQueryFactory = &ffapi.QueryFields{
+	"id":             &ffapi.UUIDField{},
+	"principal":      &ffapi.StringField{},
+	"client":         &ffapi.StringField{},
+	"method":         &ffapi.StringField{},
+	"route":          &ffapi.StringField{},
+	"path":           &ffapi.StringField{},
+	"requestid":      &ffapi.StringField{},
+	"idempotencykey": &ffapi.StringField{},
+	"tx":             &ffapi.UUIDField{},
+	"operation":      &ffapi.UUIDField{},
+	"status":         &ffapi.Int64Field{},
+	"hash":           &ffapi.Bytes32Field{},
+	"sequence":       &ffapi.Int64Field{},
+	"created":        &ffapi.TimeField{},
+}
+
 // PinQueryFactory filter fields for parked contexts
 var PinQueryFactory = &ffapi.QueryFields{
 	"sequence":   &ffapi.Int64Field{},
@@ -964,6 +1057,23 @@ var TokenBalanceQueryFactory = &ffapi.QueryFields{
 	"updated":    &ffapi.TimeField{},
 }
 
+// TokenBalanceChangeQueryFactory filter fields for token balance history
+var TokenBalanceChangeQueryFactory = &ffapi.QueryFields{
+	"pool":            &ffapi.UUIDField{},
+	"tokenindex":      &ffapi.StringField{},
+	"uri":             &ffapi.StringField{},
+	"connector":       &ffapi.StringField{},
+	"key":             &ffapi.StringField{},
+	"amount":          &ffapi.BigIntField{},
+	"debit":           &ffapi.BoolField{},
+	"balance":         &ffapi.BigIntField{},
+	"transfer":        &ffapi.UUIDField{},
+	"protocolid":      &ffapi.StringField{},
+	"blockchainevent": &ffapi.UUIDField{},
+	"created":         &ffapi.TimeField{},
+	"timestamp":       &ffapi.TimeField{},
+}
+
 // TokenAccountQueryFactory filter fields for token accounts
 var TokenAccountQueryFactory = &ffapi.QueryFields{
 	"key":     &ffapi.StringField{},
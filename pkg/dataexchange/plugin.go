@@ -76,6 +76,10 @@ type Plugin interface {
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
 
+	// CheckHealth queries the identity of the data exchange, returning an error if it cannot be reached,
+	// along with the state of the websocket it delivers messages and blob events over.
+	CheckHealth(ctx context.Context, namespace string) (*core.EventStreamHealth, error)
+
 	// GetEndpointInfo returns the information about the local endpoint
 	GetEndpointInfo(ctx context.Context, nodeName string) (peer fftypes.JSONObject, err error)
 
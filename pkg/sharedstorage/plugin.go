@@ -41,6 +41,10 @@ type Plugin interface {
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
 
+	// CheckHealth checks the shared storage API responds, returning an error if it cannot be reached.
+	// Shared storage has no event stream, so the returned stream health is always nil.
+	CheckHealth(ctx context.Context, namespace string) (*core.EventStreamHealth, error)
+
 	// UploadData publishes data to the Shared Storage, and returns a payload reference ID
 	UploadData(ctx context.Context, data io.Reader) (payloadRef string, err error)
 
@@ -52,6 +52,10 @@ type Plugin interface {
 	// Capabilities returns capabilities - not called until after Init
 	Capabilities() *Capabilities
 
+	// CheckHealth probes the token connector, returning an error if it cannot be reached,
+	// along with the state of the event stream the namespace listens on for token events.
+	CheckHealth(ctx context.Context, namespace string) (*core.EventStreamHealth, error)
+
 	// ConnectorName returns the configured connector name (plugin instance)
 	ConnectorName() string
 